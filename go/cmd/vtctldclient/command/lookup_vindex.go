@@ -0,0 +1,179 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// LookupVindex is the parent command for LookupVindex sub commands.
+	LookupVindex = &cobra.Command{
+		Use:                   "LookupVindex --keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to creating, backfilling and externalizing lookup vindexes.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"lookupvindex"},
+		Args:                  cobra.ExactArgs(1),
+	}
+
+	// LookupVindexCreate makes a LookupVindexCreate gRPC call to a vtctld.
+	LookupVindexCreate = &cobra.Command{
+		Use:   "create --spec <json>",
+		Short: "Create a write-only lookup vindex, and the VReplication workflow which backfills its lookup table.",
+		Long: `Create a write-only lookup vindex, and the VReplication workflow which backfills its lookup table.
+
+The spec is a keyspace VSchema in JSON, holding the lookup vindex and the table
+and column it is created on. The vindex is not used to route queries until it
+is externalized, once the status command reports its backfill as complete.`,
+		Example:               `vtctldclient --server localhost:15999 lookupvindex --keyspace customer create --spec '{"sharded": true, "vindexes": {"corder_lookup": {"type": "consistent_lookup_unique", "params": {"table": "customer.corder_lookup", "from": "sku", "to": "keyspace_id"}, "owner": "corder"}}, "tables": {"corder": {"column_vindexes": [{"column": "sku", "name": "corder_lookup"}]}}}'`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandLookupVindexCreate,
+	}
+
+	// LookupVindexExternalize makes a LookupVindexExternalize gRPC call to a vtctld.
+	LookupVindexExternalize = &cobra.Command{
+		Use:                   "externalize <vindex>",
+		Short:                 "Make a lookup vindex whose backfill is complete readable, deleting its workflow if the vindex has an owner.",
+		Example:               `vtctldclient --server localhost:15999 lookupvindex --keyspace customer externalize corder_lookup`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Externalize"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandLookupVindexExternalize,
+	}
+
+	// LookupVindexStatus makes a LookupVindexStatus gRPC call to a vtctld.
+	LookupVindexStatus = &cobra.Command{
+		Use:                   "status <vindex>",
+		Short:                 "Show the backfill progress of a lookup vindex for each of its source shards.",
+		Example:               `vtctldclient --server localhost:15999 lookupvindex --keyspace customer status corder_lookup`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Status", "progress", "Progress"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandLookupVindexStatus,
+	}
+)
+
+var (
+	lookupVindexOptions = struct {
+		Keyspace string
+	}{}
+	lookupVindexCreateOptions = struct {
+		Spec                       string
+		Cells                      []string
+		TabletTypes                []topodatapb.TabletType
+		ContinueAfterCopyWithOwner bool
+	}{}
+)
+
+func commandLookupVindexCreate(cmd *cobra.Command, args []string) error {
+	var spec vschemapb.Keyspace
+	if err := json2.Unmarshal([]byte(lookupVindexCreateOptions.Spec), &spec); err != nil {
+		return fmt.Errorf("invalid vindex spec: %w", err)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.LookupVindexCreate(commandCtx, &vtctldatapb.LookupVindexCreateRequest{
+		Keyspace:                   lookupVindexOptions.Keyspace,
+		Vindex:                     &spec,
+		Cells:                      lookupVindexCreateOptions.Cells,
+		TabletTypes:                lookupVindexCreateOptions.TabletTypes,
+		ContinueAfterCopyWithOwner: lookupVindexCreateOptions.ContinueAfterCopyWithOwner,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandLookupVindexExternalize(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.LookupVindexExternalize(commandCtx, &vtctldatapb.LookupVindexExternalizeRequest{
+		Keyspace: lookupVindexOptions.Keyspace,
+		Name:     cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandLookupVindexStatus(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.LookupVindexStatus(commandCtx, &vtctldatapb.LookupVindexStatusRequest{
+		Keyspace: lookupVindexOptions.Keyspace,
+		Name:     cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	LookupVindex.PersistentFlags().StringVar(&lookupVindexOptions.Keyspace, "keyspace", "", "The keyspace of the table the lookup vindex is created on (required).")
+	LookupVindex.MarkPersistentFlagRequired("keyspace")
+
+	LookupVindexCreate.Flags().StringVar(&lookupVindexCreateOptions.Spec, "spec", "", "The lookup vindex and the table column it is created on, as a keyspace VSchema in JSON (required).")
+	LookupVindexCreate.MarkFlagRequired("spec")
+	LookupVindexCreate.Flags().StringSliceVarP(&lookupVindexCreateOptions.Cells, "cells", "c", nil, "Cells and/or CellAliases to copy table data from.")
+	LookupVindexCreate.Flags().Var((*topoproto.TabletTypeListFlag)(&lookupVindexCreateOptions.TabletTypes), "tablet-types", "Source tablet types to replicate table data from (e.g. PRIMARY,REPLICA,RDONLY).")
+	LookupVindexCreate.Flags().BoolVar(&lookupVindexCreateOptions.ContinueAfterCopyWithOwner, "continue-after-copy-with-owner", false, "Keep replicating changes into the lookup table after the backfill, even though the vindex has an owner.")
+	LookupVindex.AddCommand(LookupVindexCreate)
+	LookupVindex.AddCommand(LookupVindexExternalize)
+	LookupVindex.AddCommand(LookupVindexStatus)
+
+	Root.AddCommand(LookupVindex)
+}
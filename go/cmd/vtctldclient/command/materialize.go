@@ -0,0 +1,141 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// Materialize is the parent command for Materialize sub commands.
+	Materialize = &cobra.Command{
+		Use:                   "Materialize --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to materializing query results from a source keyspace into a target keyspace.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"materialize"},
+		Args:                  cobra.ExactArgs(1),
+	}
+
+	// MaterializeCreate makes a MaterializeCreate gRPC call to a vtctld.
+	MaterializeCreate = &cobra.Command{
+		Use:   "create",
+		Short: "Create and run a Materialize VReplication workflow.",
+		Long: `Create and run a Materialize VReplication workflow.
+
+The table settings are a JSON list of objects with a target_table, the
+source_expression selecting its rows from the source keyspace, and the
+create_ddl of the target table, or "copy" to copy the source table's schema.`,
+		Example:               `vtctldclient --server localhost:15999 materialize --workflow product_sales --target-keyspace commerce create --source-keyspace commerce --table-settings '[{"target_table": "sales_by_sku", "source_expression": "select sku, count(*) as orders, sum(price) as revenue from corder group by sku", "create_ddl": "create table sales_by_sku (sku varbinary(128) not null primary key, orders bigint, revenue bigint)"}]'`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if _, ok := binlogdatapb.OnDDLAction_value[strings.ToUpper(materializeCreateOptions.OnDDL)]; !ok {
+				return fmt.Errorf("invalid on-ddl value: %s", materializeCreateOptions.OnDDL)
+			}
+			materializeCreateOptions.OnDDL = strings.ToUpper(materializeCreateOptions.OnDDL)
+			return nil
+		},
+		RunE: commandMaterializeCreate,
+	}
+)
+
+var (
+	materializeOptions = struct {
+		Workflow       string
+		TargetKeyspace string
+	}{}
+	materializeCreateOptions = struct {
+		SourceKeyspace     string
+		TableSettings      string
+		Cells              []string
+		TabletTypes        []topodatapb.TabletType
+		OnDDL              string
+		DeferSecondaryKeys bool
+		StopAfterCopy      bool
+	}{}
+)
+
+func commandMaterializeCreate(cmd *cobra.Command, args []string) error {
+	var tableSettings []*vtctldatapb.TableMaterializeSettings
+	if err := json2.Unmarshal([]byte(materializeCreateOptions.TableSettings), &tableSettings); err != nil {
+		return fmt.Errorf("invalid table settings: %w", err)
+	}
+	if len(tableSettings) == 0 {
+		return fmt.Errorf("no table settings specified")
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.MaterializeCreate(commandCtx, &vtctldatapb.MaterializeCreateRequest{
+		Settings: &vtctldatapb.MaterializeSettings{
+			Workflow:              materializeOptions.Workflow,
+			SourceKeyspace:        materializeCreateOptions.SourceKeyspace,
+			TargetKeyspace:        materializeOptions.TargetKeyspace,
+			TableSettings:         tableSettings,
+			Cell:                  strings.Join(materializeCreateOptions.Cells, ","),
+			TabletTypes:           strings.Join(topoproto.MakeStringTypeList(materializeCreateOptions.TabletTypes), ","),
+			MaterializationIntent: vtctldatapb.MaterializationIntent_CUSTOM,
+			OnDdl:                 materializeCreateOptions.OnDDL,
+			DeferSecondaryKeys:    materializeCreateOptions.DeferSecondaryKeys,
+			StopAfterCopy:         materializeCreateOptions.StopAfterCopy,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	Materialize.PersistentFlags().StringVarP(&materializeOptions.Workflow, "workflow", "w", "", "The workflow you want to perform the command on (required).")
+	Materialize.MarkPersistentFlagRequired("workflow")
+	Materialize.PersistentFlags().StringVar(&materializeOptions.TargetKeyspace, "target-keyspace", "", "Target keyspace for this workflow (required).")
+	Materialize.MarkPersistentFlagRequired("target-keyspace")
+
+	MaterializeCreate.Flags().StringVar(&materializeCreateOptions.SourceKeyspace, "source-keyspace", "", "Keyspace where the tables queried in the source expressions live (required).")
+	MaterializeCreate.MarkFlagRequired("source-keyspace")
+	MaterializeCreate.Flags().StringVar(&materializeCreateOptions.TableSettings, "table-settings", "", "A JSON list of the tables to materialize (required).")
+	MaterializeCreate.MarkFlagRequired("table-settings")
+	MaterializeCreate.Flags().StringSliceVarP(&materializeCreateOptions.Cells, "cells", "c", nil, "Cells and/or CellAliases to copy table data from.")
+	MaterializeCreate.Flags().Var((*topoproto.TabletTypeListFlag)(&materializeCreateOptions.TabletTypes), "tablet-types", "Source tablet types to replicate table data from (e.g. PRIMARY,REPLICA,RDONLY).")
+	MaterializeCreate.Flags().StringVar(&materializeCreateOptions.OnDDL, "on-ddl", "IGNORE", "What to do when DDL is encountered in the VReplication stream. Possible values are IGNORE, STOP, EXEC, and EXEC_IGNORE.")
+	MaterializeCreate.Flags().BoolVar(&materializeCreateOptions.DeferSecondaryKeys, "defer-secondary-keys", false, "Defer secondary index creation for a table until after it has been copied.")
+	MaterializeCreate.Flags().BoolVar(&materializeCreateOptions.StopAfterCopy, "stop-after-copy", false, "Stop the workflow after it's finished copying the existing rows and before it starts replicating changes.")
+	Materialize.AddCommand(MaterializeCreate)
+
+	Root.AddCommand(Materialize)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// Migrate is the parent command for Migrate sub commands.
+	Migrate = &cobra.Command{
+		Use:                   "Migrate --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Migrate is used to import data from an external cluster into the current cluster.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"migrate"},
+		Args:                  cobra.ExactArgs(1),
+	}
+
+	// MigrateCreate makes a MigrateCreate gRPC call to a vtctld.
+	MigrateCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create and optionally run a Migrate VReplication workflow.",
+		Example:               `vtctldclient --server localhost:15999 migrate --workflow import --target-keyspace customer create --source-keyspace commerce --mount-name ext1 --tablet-types replica`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !migrateCreateOptions.AllTables && len(migrateCreateOptions.IncludeTables) == 0 {
+				return fmt.Errorf("you must specify either --all-tables or --include-tables")
+			}
+			if migrateCreateOptions.AllTables && len(migrateCreateOptions.IncludeTables) > 0 {
+				return fmt.Errorf("you cannot specify both --all-tables and --include-tables")
+			}
+			return validateCreateOptions(cmd, args)
+		},
+		RunE: commandMigrateCreate,
+	}
+)
+
+var migrateCreateOptions = struct {
+	MountName       string
+	SourceKeyspace  string
+	AllTables       bool
+	IncludeTables   []string
+	ExcludeTables   []string
+	SourceTimeZone  string
+	DropForeignKeys bool
+}{}
+
+func commandMigrateCreate(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.MigrateCreate(commandCtx, &vtctldatapb.MigrateCreateRequest{
+		Workflow:           vreplicationOptions.Workflow,
+		TargetKeyspace:     vreplicationOptions.TargetKeyspace,
+		SourceKeyspace:     migrateCreateOptions.SourceKeyspace,
+		MountName:          migrateCreateOptions.MountName,
+		AllTables:          migrateCreateOptions.AllTables,
+		IncludeTables:      migrateCreateOptions.IncludeTables,
+		ExcludeTables:      migrateCreateOptions.ExcludeTables,
+		SourceTimeZone:     migrateCreateOptions.SourceTimeZone,
+		DropForeignKeys:    migrateCreateOptions.DropForeignKeys,
+		Cells:              vreplicationCreateOptions.Cells,
+		TabletTypes:        vreplicationCreateOptions.TabletTypes,
+		OnDdl:              vreplicationCreateOptions.OnDDL,
+		DeferSecondaryKeys: vreplicationCreateOptions.DeferSecondaryKeys,
+		AutoStart:          vreplicationCreateOptions.AutoStart,
+		StopAfterCopy:      vreplicationCreateOptions.StopAfterCopy,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	Migrate.PersistentFlags().StringVarP(&vreplicationOptions.Workflow, "workflow", "w", "", "The workflow you want to perform the command on (required).")
+	Migrate.MarkPersistentFlagRequired("workflow")
+	Migrate.PersistentFlags().StringVar(&vreplicationOptions.TargetKeyspace, "target-keyspace", "", "Target keyspace for this workflow (required).")
+	Migrate.MarkPersistentFlagRequired("target-keyspace")
+
+	MigrateCreate.Flags().StringVar(&migrateCreateOptions.MountName, "mount-name", "", "Name of the mounted external cluster to migrate the tables from (required).")
+	MigrateCreate.MarkFlagRequired("mount-name")
+	MigrateCreate.Flags().StringVar(&migrateCreateOptions.SourceKeyspace, "source-keyspace", "", "Keyspace of the external cluster where the tables are being migrated from (required).")
+	MigrateCreate.MarkFlagRequired("source-keyspace")
+	MigrateCreate.Flags().BoolVar(&migrateCreateOptions.AllTables, "all-tables", false, "Copy all tables from the source.")
+	MigrateCreate.Flags().StringSliceVar(&migrateCreateOptions.IncludeTables, "include-tables", nil, "Source tables to copy.")
+	MigrateCreate.Flags().StringSliceVar(&migrateCreateOptions.ExcludeTables, "exclude-tables", nil, "Source tables to exclude from copying.")
+	MigrateCreate.Flags().StringVar(&migrateCreateOptions.SourceTimeZone, "source-time-zone", "", "Specifying this causes any DATETIME fields to be converted from the given time zone into UTC.")
+	MigrateCreate.Flags().BoolVar(&migrateCreateOptions.DropForeignKeys, "drop-foreign-keys", false, "If true, tables in the target keyspace will be created without foreign keys.")
+	addCreateFlags(MigrateCreate)
+	Migrate.AddCommand(MigrateCreate)
+
+	// A Migrate workflow never switches traffic, as the source is not part of
+	// this cluster; it is only ever completed or cancelled.
+	Migrate.AddCommand(newCompleteCommand("Migrate"))
+	Migrate.AddCommand(newCancelCommand("Migrate"))
+	Migrate.AddCommand(newStatusCommand("Migrate"))
+	Root.AddCommand(Migrate)
+}
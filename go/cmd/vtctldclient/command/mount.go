@@ -0,0 +1,184 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// Mount is the parent command for Mount sub commands.
+	Mount = &cobra.Command{
+		Use:                   "Mount [command] [command-flags]",
+		Short:                 "Mount is used to link an external Vitess cluster in order to migrate data from it.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"mount"},
+		Args:                  cobra.ExactArgs(1),
+	}
+
+	// MountRegister makes a MountRegister gRPC call to a vtctld.
+	MountRegister = &cobra.Command{
+		Use:                   "register --topo-type <type> --topo-server <server> --topo-root <root> <name>",
+		Short:                 "Register an external Vitess cluster.",
+		Example:               `vtctldclient --server localhost:15999 mount register --topo-type etcd2 --topo-server localhost:12379 --topo-root /vitess/global ext1`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Register"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandMountRegister,
+	}
+
+	// MountUnregister makes a MountUnregister gRPC call to a vtctld.
+	MountUnregister = &cobra.Command{
+		Use:                   "unregister <name>",
+		Short:                 "Unregister a previously mounted external Vitess cluster.",
+		Example:               `vtctldclient --server localhost:15999 mount unregister ext1`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Unregister"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandMountUnregister,
+	}
+
+	// MountShow makes a MountShow gRPC call to a vtctld.
+	MountShow = &cobra.Command{
+		Use:                   "show <name>",
+		Short:                 "Show attributes of a previously mounted external Vitess cluster.",
+		Example:               `vtctldclient --server localhost:15999 mount show ext1`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Show"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandMountShow,
+	}
+
+	// MountList makes a MountList gRPC call to a vtctld.
+	MountList = &cobra.Command{
+		Use:                   "list",
+		Short:                 "List all mounted external Vitess clusters.",
+		Example:               `vtctldclient --server localhost:15999 mount list`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"List"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandMountList,
+	}
+)
+
+var mountRegisterOptions = struct {
+	TopoType   string
+	TopoServer string
+	TopoRoot   string
+}{}
+
+func commandMountRegister(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.MountRegister(commandCtx, &vtctldatapb.MountRegisterRequest{
+		Name:       cmd.Flags().Arg(0),
+		TopoType:   mountRegisterOptions.TopoType,
+		TopoServer: mountRegisterOptions.TopoServer,
+		TopoRoot:   mountRegisterOptions.TopoRoot,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandMountUnregister(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.MountUnregister(commandCtx, &vtctldatapb.MountUnregisterRequest{
+		Name: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandMountShow(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.MountShow(commandCtx, &vtctldatapb.MountShowRequest{
+		Name: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandMountList(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.MountList(commandCtx, &vtctldatapb.MountListRequest{})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	MountRegister.Flags().StringVar(&mountRegisterOptions.TopoType, "topo-type", "", "Topo server implementation to use (required).")
+	MountRegister.MarkFlagRequired("topo-type")
+	MountRegister.Flags().StringVar(&mountRegisterOptions.TopoServer, "topo-server", "", "Topo server address (required).")
+	MountRegister.MarkFlagRequired("topo-server")
+	MountRegister.Flags().StringVar(&mountRegisterOptions.TopoRoot, "topo-root", "", "Topo server root path (required).")
+	MountRegister.MarkFlagRequired("topo-root")
+	Mount.AddCommand(MountRegister)
+	Mount.AddCommand(MountUnregister)
+	Mount.AddCommand(MountShow)
+	Mount.AddCommand(MountList)
+
+	Root.AddCommand(Mount)
+}
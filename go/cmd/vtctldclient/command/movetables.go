@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// MoveTables is the parent command for MoveTables sub commands.
+	MoveTables = &cobra.Command{
+		Use:                   "MoveTables --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to moving tables from a source keyspace to a target keyspace.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"movetables"},
+		Args:                  cobra.ExactArgs(1),
+	}
+
+	// MoveTablesCreate makes a MoveTablesCreate gRPC call to a vtctld.
+	MoveTablesCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create and optionally run a MoveTables VReplication workflow.",
+		Example:               `vtctldclient --server localhost:15999 movetables --workflow commerce2customer --target-keyspace customer create --source-keyspace commerce --cells zone1 --cells zone2 --tablet-types replica`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if !moveTablesCreateOptions.AllTables && len(moveTablesCreateOptions.IncludeTables) == 0 {
+				return fmt.Errorf("you must specify either --all-tables or --include-tables")
+			}
+			if moveTablesCreateOptions.AllTables && len(moveTablesCreateOptions.IncludeTables) > 0 {
+				return fmt.Errorf("you cannot specify both --all-tables and --include-tables")
+			}
+			return validateCreateOptions(cmd, args)
+		},
+		RunE: commandMoveTablesCreate,
+	}
+)
+
+var moveTablesCreateOptions = struct {
+	SourceKeyspace      string
+	SourceShards        []string
+	ExternalClusterName string
+	AllTables           bool
+	IncludeTables       []string
+	ExcludeTables       []string
+	SourceTimeZone      string
+	DropForeignKeys     bool
+}{}
+
+func commandMoveTablesCreate(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.MoveTablesCreateRequest{
+		Workflow:            vreplicationOptions.Workflow,
+		TargetKeyspace:      vreplicationOptions.TargetKeyspace,
+		SourceKeyspace:      moveTablesCreateOptions.SourceKeyspace,
+		SourceShards:        moveTablesCreateOptions.SourceShards,
+		ExternalClusterName: moveTablesCreateOptions.ExternalClusterName,
+		AllTables:           moveTablesCreateOptions.AllTables,
+		IncludeTables:       moveTablesCreateOptions.IncludeTables,
+		ExcludeTables:       moveTablesCreateOptions.ExcludeTables,
+		SourceTimeZone:      moveTablesCreateOptions.SourceTimeZone,
+		DropForeignKeys:     moveTablesCreateOptions.DropForeignKeys,
+		Cells:               vreplicationCreateOptions.Cells,
+		TabletTypes:         vreplicationCreateOptions.TabletTypes,
+		OnDdl:               vreplicationCreateOptions.OnDDL,
+		DeferSecondaryKeys:  vreplicationCreateOptions.DeferSecondaryKeys,
+		AutoStart:           vreplicationCreateOptions.AutoStart,
+		StopAfterCopy:       vreplicationCreateOptions.StopAfterCopy,
+	}
+
+	resp, err := client.MoveTablesCreate(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	MoveTablesCreate.Flags().StringVar(&moveTablesCreateOptions.SourceKeyspace, "source-keyspace", "", "Keyspace where the tables are being moved from (required).")
+	MoveTablesCreate.MarkFlagRequired("source-keyspace")
+	MoveTablesCreate.Flags().StringSliceVar(&moveTablesCreateOptions.SourceShards, "source-shards", nil, "Source shards to copy data from when performing a partial MoveTables (experimental).")
+	MoveTablesCreate.Flags().StringVar(&moveTablesCreateOptions.ExternalClusterName, "external-cluster-name", "", "The name of the mounted external cluster that the tables are being moved from.")
+	MoveTablesCreate.Flags().BoolVar(&moveTablesCreateOptions.AllTables, "all-tables", false, "Copy all tables from the source.")
+	MoveTablesCreate.Flags().StringSliceVar(&moveTablesCreateOptions.IncludeTables, "include-tables", nil, "Source tables to copy.")
+	MoveTablesCreate.Flags().StringSliceVar(&moveTablesCreateOptions.ExcludeTables, "exclude-tables", nil, "Source tables to exclude from copying.")
+	MoveTablesCreate.Flags().StringVar(&moveTablesCreateOptions.SourceTimeZone, "source-time-zone", "", "Specifying this causes any DATETIME fields to be converted from the given time zone into UTC.")
+	MoveTablesCreate.Flags().BoolVar(&moveTablesCreateOptions.DropForeignKeys, "drop-foreign-keys", false, "If true, tables in the target keyspace will be created without foreign keys.")
+	addCreateFlags(MoveTablesCreate)
+	MoveTables.AddCommand(MoveTablesCreate)
+
+	addVReplicationCommands(MoveTables, "MoveTables")
+	Root.AddCommand(MoveTables)
+}
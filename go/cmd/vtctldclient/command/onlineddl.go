@@ -0,0 +1,331 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// OnlineDDL is the parent command for OnlineDDL sub commands.
+	OnlineDDL = &cobra.Command{
+		Use:                   "OnlineDDL <cmd> <keyspace> [args]",
+		Short:                 "Operates on online DDL (schema migrations).",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"onlineddl"},
+		Args:                  cobra.MinimumNArgs(2),
+	}
+
+	// OnlineDDLCancel makes a CancelSchemaMigration gRPC call to a vtctld.
+	OnlineDDLCancel = &cobra.Command{
+		Use:                   "cancel <keyspace> <uuid|all>",
+		Short:                 "Cancel one or all migrations, terminating any running ones as needed.",
+		Example:               "OnlineDDL cancel test_keyspace 82fa54ac_e83e_11ea_96b7_f875a4d24e90",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandOnlineDDLCancel,
+	}
+
+	// OnlineDDLCleanup makes a CleanupSchemaMigration gRPC call to a vtctld.
+	OnlineDDLCleanup = &cobra.Command{
+		Use:                   "cleanup <keyspace> <uuid>",
+		Short:                 "Mark a given schema migration ready for artifact cleanup.",
+		Example:               "OnlineDDL cleanup test_keyspace 82fa54ac_e83e_11ea_96b7_f875a4d24e90",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandOnlineDDLCleanup,
+	}
+
+	// OnlineDDLComplete makes a CompleteSchemaMigration gRPC call to a vtctld.
+	OnlineDDLComplete = &cobra.Command{
+		Use:                   "complete <keyspace> <uuid|all>",
+		Short:                 "Complete one or all migrations executed with --postpone-completion.",
+		Example:               "OnlineDDL complete test_keyspace 82fa54ac_e83e_11ea_96b7_f875a4d24e90",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandOnlineDDLComplete,
+	}
+
+	// OnlineDDLLaunch makes a LaunchSchemaMigration gRPC call to a vtctld.
+	OnlineDDLLaunch = &cobra.Command{
+		Use:                   "launch <keyspace> <uuid|all>",
+		Short:                 "Launch one or all migrations executed with --postpone-launch.",
+		Example:               "OnlineDDL launch test_keyspace 82fa54ac_e83e_11ea_96b7_f875a4d24e90",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandOnlineDDLLaunch,
+	}
+
+	// OnlineDDLRetry makes a RetrySchemaMigration gRPC call to a vtctld.
+	OnlineDDLRetry = &cobra.Command{
+		Use:                   "retry <keyspace> <uuid>",
+		Short:                 "Mark a given schema migration for retry.",
+		Example:               "OnlineDDL retry test_keyspace 82fa54ac_e83e_11ea_96b7_f875a4d24e90",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(2),
+		RunE:                  commandOnlineDDLRetry,
+	}
+
+	// OnlineDDLShow makes a GetSchemaMigrations gRPC call to a vtctld.
+	OnlineDDLShow = &cobra.Command{
+		Use:   "show <keyspace> [<uuid>|<migration_context>|all|recent|<status>]",
+		Short: "Display information about online DDL operations.",
+		Example: `OnlineDDL show test_keyspace 82fa54ac_e83e_11ea_96b7_f875a4d24e90
+OnlineDDL show test_keyspace all
+OnlineDDL show --order descending test_keyspace all
+OnlineDDL show --limit 10 test_keyspace all
+OnlineDDL show --skip 5 --limit 10 test_keyspace all
+OnlineDDL show test_keyspace running
+OnlineDDL show test_keyspace complete
+OnlineDDL show test_keyspace failed`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.RangeArgs(1, 2),
+		RunE:                  commandOnlineDDLShow,
+	}
+)
+
+// onlineDDLActionArgs returns the keyspace and UUID positional arguments of
+// an OnlineDDL action command, validating the UUID. The special UUID "all" is
+// only accepted when allowAll is set.
+func onlineDDLActionArgs(cmd *cobra.Command, allowAll bool) (keyspace string, uuid string, err error) {
+	keyspace, uuid = cmd.Flags().Arg(0), cmd.Flags().Arg(1)
+	switch {
+	case strings.ToLower(uuid) == "all":
+		if !allowAll {
+			return "", "", fmt.Errorf("%s does not support 'all'", cmd.Name())
+		}
+	case !schema.IsOnlineDDLUUID(uuid):
+		return "", "", fmt.Errorf("%s is not a valid UUID", uuid)
+	}
+
+	return keyspace, uuid, nil
+}
+
+func commandOnlineDDLCancel(cmd *cobra.Command, args []string) error {
+	keyspace, uuid, err := onlineDDLActionArgs(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.CancelSchemaMigration(commandCtx, &vtctldatapb.CancelSchemaMigrationRequest{
+		Keyspace: keyspace,
+		Uuid:     uuid,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandOnlineDDLCleanup(cmd *cobra.Command, args []string) error {
+	keyspace, uuid, err := onlineDDLActionArgs(cmd, false)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.CleanupSchemaMigration(commandCtx, &vtctldatapb.CleanupSchemaMigrationRequest{
+		Keyspace: keyspace,
+		Uuid:     uuid,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandOnlineDDLComplete(cmd *cobra.Command, args []string) error {
+	keyspace, uuid, err := onlineDDLActionArgs(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.CompleteSchemaMigration(commandCtx, &vtctldatapb.CompleteSchemaMigrationRequest{
+		Keyspace: keyspace,
+		Uuid:     uuid,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandOnlineDDLLaunch(cmd *cobra.Command, args []string) error {
+	keyspace, uuid, err := onlineDDLActionArgs(cmd, true)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.LaunchSchemaMigration(commandCtx, &vtctldatapb.LaunchSchemaMigrationRequest{
+		Keyspace: keyspace,
+		Uuid:     uuid,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandOnlineDDLRetry(cmd *cobra.Command, args []string) error {
+	keyspace, uuid, err := onlineDDLActionArgs(cmd, false)
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.RetrySchemaMigration(commandCtx, &vtctldatapb.RetrySchemaMigrationRequest{
+		Keyspace: keyspace,
+		Uuid:     uuid,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var onlineDDLShowArgs = struct {
+	OrderBy string
+	Limit   uint64
+	Skip    uint64
+}{
+	OrderBy: "ascending",
+}
+
+func commandOnlineDDLShow(cmd *cobra.Command, args []string) error {
+	var order vtctldatapb.QueryOrdering
+	switch strings.ToLower(onlineDDLShowArgs.OrderBy) {
+	case "desc", "descending":
+		order = vtctldatapb.QueryOrdering_DESCENDING
+	case "asc", "ascending":
+		order = vtctldatapb.QueryOrdering_ASCENDING
+	case "":
+		order = vtctldatapb.QueryOrdering_NONE
+	default:
+		return fmt.Errorf("invalid ordering %s (choices are 'asc', 'ascending', 'desc', 'descending')", onlineDDLShowArgs.OrderBy)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.GetSchemaMigrationsRequest{
+		Keyspace: cmd.Flags().Arg(0),
+		Order:    order,
+		Limit:    onlineDDLShowArgs.Limit,
+		Skip:     onlineDDLShowArgs.Skip,
+	}
+
+	switch arg := cmd.Flags().Arg(1); arg {
+	case "", "all":
+	case "recent":
+		req.Recent = protoutil.DurationToProto(7 * 24 * time.Hour)
+	default:
+		if status, err := schematools.ParseSchemaMigrationStatus(arg); err == nil {
+			req.Status = status
+		} else if schema.IsOnlineDDLUUID(arg) {
+			req.Uuid = arg
+		} else {
+			req.MigrationContext = arg
+		}
+	}
+
+	resp, err := client.GetSchemaMigrations(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	OnlineDDL.AddCommand(OnlineDDLCancel)
+	OnlineDDL.AddCommand(OnlineDDLCleanup)
+	OnlineDDL.AddCommand(OnlineDDLComplete)
+	OnlineDDL.AddCommand(OnlineDDLLaunch)
+	OnlineDDL.AddCommand(OnlineDDLRetry)
+
+	OnlineDDLShow.Flags().StringVar(&onlineDDLShowArgs.OrderBy, "order", "ascending", "Sort the results by `id` property of the Schema migration.")
+	OnlineDDLShow.Flags().Uint64Var(&onlineDDLShowArgs.Limit, "limit", 0, "Limit number of rows returned in output.")
+	OnlineDDLShow.Flags().Uint64Var(&onlineDDLShowArgs.Skip, "skip", 0, "Skip specified number of rows returned in output.")
+	OnlineDDL.AddCommand(OnlineDDLShow)
+
+	Root.AddCommand(OnlineDDL)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// Reshard is the parent command for Reshard sub commands.
+	Reshard = &cobra.Command{
+		Use:                   "Reshard --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to resharding a keyspace.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"reshard"},
+		Args:                  cobra.ExactArgs(1),
+	}
+
+	// ReshardCreate makes a ReshardCreate gRPC call to a vtctld.
+	ReshardCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create and optionally run a Reshard VReplication workflow.",
+		Example:               `vtctldclient --server localhost:15999 reshard --workflow cust2cust --target-keyspace customer create --source-shards="0" --target-shards="-80,80-" --cells zone1 --cells zone2 --tablet-types replica`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		PreRunE:               validateCreateOptions,
+		RunE:                  commandReshardCreate,
+	}
+)
+
+var reshardCreateOptions = struct {
+	SourceShards   []string
+	TargetShards   []string
+	SkipSchemaCopy bool
+}{}
+
+func commandReshardCreate(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	req := &vtctldatapb.ReshardCreateRequest{
+		Workflow:           vreplicationOptions.Workflow,
+		Keyspace:           vreplicationOptions.TargetKeyspace,
+		SourceShards:       reshardCreateOptions.SourceShards,
+		TargetShards:       reshardCreateOptions.TargetShards,
+		SkipSchemaCopy:     reshardCreateOptions.SkipSchemaCopy,
+		Cells:              vreplicationCreateOptions.Cells,
+		TabletTypes:        vreplicationCreateOptions.TabletTypes,
+		OnDdl:              vreplicationCreateOptions.OnDDL,
+		DeferSecondaryKeys: vreplicationCreateOptions.DeferSecondaryKeys,
+		AutoStart:          vreplicationCreateOptions.AutoStart,
+		StopAfterCopy:      vreplicationCreateOptions.StopAfterCopy,
+	}
+
+	resp, err := client.ReshardCreate(commandCtx, req)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	ReshardCreate.Flags().StringSliceVar(&reshardCreateOptions.SourceShards, "source-shards", nil, "Source shards (required).")
+	ReshardCreate.MarkFlagRequired("source-shards")
+	ReshardCreate.Flags().StringSliceVar(&reshardCreateOptions.TargetShards, "target-shards", nil, "Target shards (required).")
+	ReshardCreate.MarkFlagRequired("target-shards")
+	ReshardCreate.Flags().BoolVar(&reshardCreateOptions.SkipSchemaCopy, "skip-schema-copy", false, "Skip copying the schema from the source shards to the target shards.")
+	addCreateFlags(ReshardCreate)
+	Reshard.AddCommand(ReshardCreate)
+
+	addVReplicationCommands(Reshard, "Reshard")
+	Root.AddCommand(Reshard)
+}
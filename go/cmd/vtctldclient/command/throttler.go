@@ -27,7 +27,7 @@ import (
 var (
 	// UpdateThrottlerConfig makes a UpdateThrottlerConfig gRPC call to a vtctld.
 	UpdateThrottlerConfig = &cobra.Command{
-		Use:                   "UpdateThrottlerConfig [--enable|--disable] [--threshold=<float64>] [--custom-query=<query>] [--check-as-check-self|--check-as-check-shard] <keyspace>",
+		Use:                   "UpdateThrottlerConfig [--enable|--disable] [--threshold=<float64>] [--custom-query=<query>] [--check-as-check-self|--check-as-check-shard] [--metric-name=<name> [--metric-threshold=<float64>] [--metric-query=<query>] [--remove-metric]] [--app-name=<name> [--app-checked-metrics=<names>]] <keyspace>",
 		Short:                 "Update the tablet throttler configuration for all tablets in the given keyspace (across all cells)",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
@@ -57,5 +57,11 @@ func init() {
 	UpdateThrottlerConfig.Flags().StringVar(&updateThrottlerConfigOptions.CustomQuery, "custom-query", "", "custom throttler check query")
 	UpdateThrottlerConfig.Flags().BoolVar(&updateThrottlerConfigOptions.CheckAsCheckSelf, "check-as-check-self", false, "/throttler/check requests behave as is /throttler/check-self was called")
 	UpdateThrottlerConfig.Flags().BoolVar(&updateThrottlerConfigOptions.CheckAsCheckShard, "check-as-check-shard", false, "use standard behavior for /throttler/check requests")
+	UpdateThrottlerConfig.Flags().StringVar(&updateThrottlerConfigOptions.MetricName, "metric-name", "", "name of a metric, other than the default 'lag' metric, to add, update or remove. Built-in metrics: 'threads_running', 'history_list_length'")
+	UpdateThrottlerConfig.Flags().Float64Var(&updateThrottlerConfigOptions.MetricThreshold, "metric-threshold", 0, "threshold for the --metric-name metric. Required for a new metric, kept when omitted for an existing one")
+	UpdateThrottlerConfig.Flags().StringVar(&updateThrottlerConfigOptions.MetricQuery, "metric-query", "", "query for the --metric-name metric. Optional for built-in metrics")
+	UpdateThrottlerConfig.Flags().BoolVar(&updateThrottlerConfigOptions.RemoveMetric, "remove-metric", false, "remove the --metric-name metric")
+	UpdateThrottlerConfig.Flags().StringVar(&updateThrottlerConfigOptions.AppName, "app-name", "", "name of an app whose checked metrics are set by --app-checked-metrics")
+	UpdateThrottlerConfig.Flags().StringSliceVar(&updateThrottlerConfigOptions.AppCheckedMetrics, "app-checked-metrics", nil, "comma separated metrics that --app-name is checked against. Empty resets the app to the default 'lag' metric")
 	Root.AddCommand(UpdateThrottlerConfig)
 }
@@ -0,0 +1,136 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// ConcludeTransaction makes a ConcludeTransaction gRPC call to a vtctld.
+	ConcludeTransaction = &cobra.Command{
+		Use:   "ConcludeTransaction <dtid>",
+		Short: "Resolves an unresolved distributed (2PC) transaction.",
+		Long: `Resolves an unresolved distributed (2PC) transaction.
+
+If a decision to commit was recorded for the transaction, it is committed on all
+its participants. Otherwise it is rolled back on all its participants.`,
+		Example:               "ConcludeTransaction commerce:-80:1591281734034931000",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandConcludeTransaction,
+	}
+	// GetUnresolvedTransactions makes a GetUnresolvedTransactions gRPC call to a vtctld.
+	GetUnresolvedTransactions = &cobra.Command{
+		Use:                   "GetUnresolvedTransactions [--abandon-age <duration>] <keyspace>",
+		Short:                 "Lists the unresolved distributed (2PC) transactions coordinated by the shards of the given keyspace, along with their participants.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandGetUnresolvedTransactions,
+	}
+)
+
+func commandConcludeTransaction(cmd *cobra.Command, args []string) error {
+	dtid := cmd.Flags().Arg(0)
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ConcludeTransaction(commandCtx, &vtctldatapb.ConcludeTransactionRequest{
+		Dtid: dtid,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var getUnresolvedTransactionsOptions = struct {
+	AbandonAge time.Duration
+}{}
+
+// unresolvedTransaction is the human-readable form of an unresolved
+// distributed transaction printed by GetUnresolvedTransactions.
+type unresolvedTransaction struct {
+	Dtid         string    `json:"dtid"`
+	State        string    `json:"state"`
+	Created      time.Time `json:"created"`
+	Age          string    `json:"age"`
+	Participants []string  `json:"participants"`
+}
+
+func commandGetUnresolvedTransactions(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetUnresolvedTransactions(commandCtx, &vtctldatapb.GetUnresolvedTransactionsRequest{
+		Keyspace:   keyspace,
+		AbandonAge: protoutil.DurationToProto(getUnresolvedTransactionsOptions.AbandonAge),
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	transactions := make([]*unresolvedTransaction, 0, len(resp.Transactions))
+	for _, tx := range resp.Transactions {
+		created := time.Unix(0, tx.TimeCreated)
+		utx := &unresolvedTransaction{
+			Dtid:         tx.Dtid,
+			State:        tx.State.String(),
+			Created:      created.UTC(),
+			Age:          now.Sub(created).Round(time.Second).String(),
+			Participants: make([]string, 0, len(tx.Participants)),
+		}
+		for _, participant := range tx.Participants {
+			utx.Participants = append(utx.Participants, participant.Keyspace+"/"+participant.Shard)
+		}
+		transactions = append(transactions, utx)
+	}
+
+	data, err := cli.MarshalJSON(transactions)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	Root.AddCommand(ConcludeTransaction)
+
+	GetUnresolvedTransactions.Flags().DurationVar(&getUnresolvedTransactionsOptions.AbandonAge, "abandon-age", 0, "Only list the transactions older than this. Younger transactions are likely still being committed.")
+	Root.AddCommand(GetUnresolvedTransactions)
+}
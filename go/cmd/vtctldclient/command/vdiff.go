@@ -0,0 +1,276 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// VDiff is the parent command for VDiff sub commands.
+	VDiff = &cobra.Command{
+		Use:                   "VDiff --workflow <workflow> --target-keyspace <keyspace> [command] [command-flags]",
+		Short:                 "Perform commands related to diffing tables involved in a VReplication workflow between the source and target.",
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"vdiff"},
+		Args:                  cobra.ExactArgs(1),
+	}
+
+	// VDiffCreate makes a VDiffCreate gRPC call to a vtctld.
+	VDiffCreate = &cobra.Command{
+		Use:                   "create",
+		Short:                 "Create and run a VDiff to compare the tables involved in a VReplication workflow between the source and target.",
+		Example:               `vtctldclient --server localhost:15999 vdiff --workflow commerce2customer --target-keyspace customer create --tables customer,corder --tablet-types rdonly,replica`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Create"},
+		Args:                  cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if vdiffCreateOptions.Limit <= 0 {
+				return fmt.Errorf("invalid --limit value (%d), maximum number of rows to compare needs to be greater than 0", vdiffCreateOptions.Limit)
+			}
+			return nil
+		},
+		RunE: commandVDiffCreate,
+	}
+
+	// VDiffDelete makes a VDiffDelete gRPC call to a vtctld.
+	VDiffDelete = &cobra.Command{
+		Use:                   "delete <uuid|all>",
+		Short:                 "Delete the VDiffs for a VReplication workflow.",
+		Example:               `vtctldclient --server localhost:15999 vdiff --workflow commerce2customer --target-keyspace customer delete a037a9e2-5628-11ee-8c99-0242ac120002`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Delete"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandVDiffDelete,
+	}
+
+	// VDiffResume makes a VDiffResume gRPC call to a vtctld.
+	VDiffResume = &cobra.Command{
+		Use:                   "resume <uuid>",
+		Short:                 "Resume a VDiff.",
+		Example:               `vtctldclient --server localhost:15999 vdiff --workflow commerce2customer --target-keyspace customer resume a037a9e2-5628-11ee-8c99-0242ac120002`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Resume"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandVDiffResume,
+	}
+
+	// VDiffShow makes a VDiffShow gRPC call to a vtctld.
+	VDiffShow = &cobra.Command{
+		Use:                   "show <uuid|last|all>",
+		Short:                 "Show the status and results of a VDiff, or list all of the VDiffs for a VReplication workflow.",
+		Example:               `vtctldclient --server localhost:15999 vdiff --workflow commerce2customer --target-keyspace customer show last`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Show"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandVDiffShow,
+	}
+
+	// VDiffStop makes a VDiffStop gRPC call to a vtctld.
+	VDiffStop = &cobra.Command{
+		Use:                   "stop <uuid>",
+		Short:                 "Stop a running VDiff.",
+		Example:               `vtctldclient --server localhost:15999 vdiff --workflow commerce2customer --target-keyspace customer stop a037a9e2-5628-11ee-8c99-0242ac120002`,
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Stop"},
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandVDiffStop,
+	}
+)
+
+var vdiffCreateOptions = struct {
+	UUID                        string
+	SourceCells                 []string
+	TargetCells                 []string
+	TabletTypes                 []topodatapb.TabletType
+	Tables                      []string
+	Limit                       int64
+	FilteredReplicationWaitTime time.Duration
+	DebugQuery                  bool
+	OnlyPKs                     bool
+	UpdateTableStats            bool
+	MaxExtraRowsToCompare       int64
+	AutoRetry                   bool
+}{}
+
+func commandVDiffCreate(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffCreate(commandCtx, &vtctldatapb.VDiffCreateRequest{
+		Workflow:                    vreplicationOptions.Workflow,
+		TargetKeyspace:              vreplicationOptions.TargetKeyspace,
+		Uuid:                        vdiffCreateOptions.UUID,
+		SourceCells:                 vdiffCreateOptions.SourceCells,
+		TargetCells:                 vdiffCreateOptions.TargetCells,
+		TabletTypes:                 vdiffCreateOptions.TabletTypes,
+		Tables:                      vdiffCreateOptions.Tables,
+		Limit:                       vdiffCreateOptions.Limit,
+		FilteredReplicationWaitTime: protoutil.DurationToProto(vdiffCreateOptions.FilteredReplicationWaitTime),
+		DebugQuery:                  vdiffCreateOptions.DebugQuery,
+		OnlyPKs:                     vdiffCreateOptions.OnlyPKs,
+		UpdateTableStats:            vdiffCreateOptions.UpdateTableStats,
+		MaxExtraRowsToCompare:       vdiffCreateOptions.MaxExtraRowsToCompare,
+		AutoRetry:                   vdiffCreateOptions.AutoRetry,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandVDiffDelete(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffDelete(commandCtx, &vtctldatapb.VDiffDeleteRequest{
+		Workflow:       vreplicationOptions.Workflow,
+		TargetKeyspace: vreplicationOptions.TargetKeyspace,
+		Arg:            cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandVDiffResume(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffResume(commandCtx, &vtctldatapb.VDiffResumeRequest{
+		Workflow:       vreplicationOptions.Workflow,
+		TargetKeyspace: vreplicationOptions.TargetKeyspace,
+		Uuid:           cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+var vdiffShowOptions = struct {
+	Verbose bool
+}{}
+
+func commandVDiffShow(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffShow(commandCtx, &vtctldatapb.VDiffShowRequest{
+		Workflow:       vreplicationOptions.Workflow,
+		TargetKeyspace: vreplicationOptions.TargetKeyspace,
+		Arg:            cmd.Flags().Arg(0),
+		Verbose:        vdiffShowOptions.Verbose,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandVDiffStop(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.VDiffStop(commandCtx, &vtctldatapb.VDiffStopRequest{
+		Workflow:       vreplicationOptions.Workflow,
+		TargetKeyspace: vreplicationOptions.TargetKeyspace,
+		Uuid:           cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func init() {
+	VDiff.PersistentFlags().StringVarP(&vreplicationOptions.Workflow, "workflow", "w", "", "The workflow you want to perform the command on (required).")
+	VDiff.MarkPersistentFlagRequired("workflow")
+	VDiff.PersistentFlags().StringVar(&vreplicationOptions.TargetKeyspace, "target-keyspace", "", "Target keyspace for this workflow (required).")
+	VDiff.MarkPersistentFlagRequired("target-keyspace")
+
+	VDiffCreate.Flags().StringVar(&vdiffCreateOptions.UUID, "uuid", "", "Provide a UUID to use for the VDiff; one is generated if not set.")
+	VDiffCreate.Flags().StringSliceVar(&vdiffCreateOptions.SourceCells, "source-cells", nil, "The source cell(s) to compare from; default is any available cell.")
+	VDiffCreate.Flags().StringSliceVar(&vdiffCreateOptions.TargetCells, "target-cells", nil, "The target cell(s) to compare with; default is any available cell.")
+	VDiffCreate.Flags().Var((*topoproto.TabletTypeListFlag)(&vdiffCreateOptions.TabletTypes), "tablet-types", "Tablet types to use on the source (PRIMARY is always used on the target); default is RDONLY, REPLICA and PRIMARY, in that order.")
+	VDiffCreate.Flags().StringSliceVar(&vdiffCreateOptions.Tables, "tables", nil, "Only run the VDiff for these tables in the workflow.")
+	VDiffCreate.Flags().Int64Var(&vdiffCreateOptions.Limit, "limit", math.MaxInt64, "Max rows to stop comparing after.")
+	VDiffCreate.Flags().DurationVar(&vdiffCreateOptions.FilteredReplicationWaitTime, "filtered-replication-wait-time", 30*time.Second, "Specifies the maximum time to wait, in seconds, for replication to catch up when syncing tablet streams.")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.DebugQuery, "debug-query", false, "Adds a MySQL query to the report that can be used for further debugging.")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.OnlyPKs, "only-pks", false, "When reporting missing rows, only show primary keys in the report.")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.UpdateTableStats, "update-table-stats", false, "Update the table statistics, using ANALYZE TABLE, on each table involved in the VDiff during initialization. This will ensure that progress estimates are as accurate as possible -- but it does involve locks and can potentially impact query processing on the target keyspace.")
+	VDiffCreate.Flags().Int64Var(&vdiffCreateOptions.MaxExtraRowsToCompare, "max-extra-rows-to-compare", 1000, "If there are collation differences between the source and target, you can have rows that are identical but simply returned in a different order from MySQL. We will do a second pass to compare the rows for any actual differences in this case and this flag allows you to control the resources used for this operation.")
+	VDiffCreate.Flags().BoolVar(&vdiffCreateOptions.AutoRetry, "auto-retry", true, "Should this VDiff automatically retry and continue in case of recoverable errors.")
+	VDiff.AddCommand(VDiffCreate)
+
+	VDiff.AddCommand(VDiffDelete)
+	VDiff.AddCommand(VDiffResume)
+
+	VDiffShow.Flags().BoolVar(&vdiffShowOptions.Verbose, "verbose", false, "Include the per-shard table reports even when no mismatches were found.")
+	VDiff.AddCommand(VDiffShow)
+
+	VDiff.AddCommand(VDiffStop)
+
+	Root.AddCommand(VDiff)
+}
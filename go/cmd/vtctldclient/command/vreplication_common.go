@@ -0,0 +1,337 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+// The commands in this file are shared by the MoveTables and Reshard parent
+// commands. Cobra commands can only have a single parent, so each parent gets
+// its own instance of every command, built by the functions below.
+
+var (
+	// vreplicationOptions are the persistent flags of the MoveTables and
+	// Reshard parent commands.
+	vreplicationOptions = struct {
+		Workflow       string
+		TargetKeyspace string
+	}{}
+	vreplicationCreateOptions = struct {
+		Cells              []string
+		TabletTypes        []topodatapb.TabletType
+		OnDDL              string
+		DeferSecondaryKeys bool
+		AutoStart          bool
+		StopAfterCopy      bool
+	}{}
+	vreplicationSwitchTrafficOptions = struct {
+		Cells                    []string
+		TabletTypes              []topodatapb.TabletType
+		MaxReplicationLagAllowed time.Duration
+		EnableReverseReplication bool
+		Timeout                  time.Duration
+		DryRun                   bool
+	}{}
+	vreplicationCompleteOptions = struct {
+		KeepData         bool
+		KeepRoutingRules bool
+		RenameTables     bool
+		DryRun           bool
+	}{}
+	vreplicationCancelOptions = struct {
+		KeepData         bool
+		KeepRoutingRules bool
+		DryRun           bool
+	}{}
+)
+
+// validateCreateOptions validates the flags that are common to the MoveTables
+// and Reshard create commands.
+func validateCreateOptions(cmd *cobra.Command, args []string) error {
+	if _, ok := binlogdatapb.OnDDLAction_value[strings.ToUpper(vreplicationCreateOptions.OnDDL)]; !ok {
+		return fmt.Errorf("invalid on-ddl value: %s", vreplicationCreateOptions.OnDDL)
+	}
+	vreplicationCreateOptions.OnDDL = strings.ToUpper(vreplicationCreateOptions.OnDDL)
+	return nil
+}
+
+// addCreateFlags adds the flags that are common to the MoveTables and Reshard
+// create commands.
+func addCreateFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVarP(&vreplicationCreateOptions.Cells, "cells", "c", nil, "Cells and/or CellAliases to copy table data from.")
+	cmd.Flags().Var((*topoproto.TabletTypeListFlag)(&vreplicationCreateOptions.TabletTypes), "tablet-types", "Source tablet types to replicate table data from (e.g. PRIMARY,REPLICA,RDONLY).")
+	cmd.Flags().StringVar(&vreplicationCreateOptions.OnDDL, "on-ddl", "IGNORE", "What to do when DDL is encountered in the VReplication stream. Possible values are IGNORE, STOP, EXEC, and EXEC_IGNORE.")
+	cmd.Flags().BoolVar(&vreplicationCreateOptions.DeferSecondaryKeys, "defer-secondary-keys", false, "Defer secondary index creation for a table until after it has been copied.")
+	cmd.Flags().BoolVar(&vreplicationCreateOptions.AutoStart, "auto-start", true, "Start the workflow after creating it.")
+	cmd.Flags().BoolVar(&vreplicationCreateOptions.StopAfterCopy, "stop-after-copy", false, "Stop the workflow after it's finished copying the existing rows and before it starts replicating changes.")
+}
+
+// newSwitchTrafficCommand returns a command that makes a WorkflowSwitchTraffic
+// gRPC call to a vtctld for the given workflow type.
+func newSwitchTrafficCommand(workflowType string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "switchtraffic",
+		Short:                 fmt.Sprintf("Switch traffic for a %s VReplication workflow.", workflowType),
+		Example:               fmt.Sprintf(`vtctldclient --server localhost:15999 %s --workflow commerce2customer --target-keyspace customer switchtraffic --tablet-types "replica,rdonly"`, workflowType),
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"SwitchTraffic"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowSwitchTraffic,
+	}
+	addSwitchTrafficFlags(cmd, true)
+	return cmd
+}
+
+// newReverseTrafficCommand returns a command that makes a
+// WorkflowReverseTraffic gRPC call to a vtctld for the given workflow type.
+func newReverseTrafficCommand(workflowType string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "reversetraffic",
+		Short:                 fmt.Sprintf("Reverse traffic for a %s VReplication workflow.", workflowType),
+		Example:               fmt.Sprintf(`vtctldclient --server localhost:15999 %s --workflow commerce2customer --target-keyspace customer reversetraffic`, workflowType),
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"ReverseTraffic"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowReverseTraffic,
+	}
+	addSwitchTrafficFlags(cmd, false)
+	return cmd
+}
+
+func addSwitchTrafficFlags(cmd *cobra.Command, forward bool) {
+	verb := "switch"
+	if !forward {
+		verb = "reverse"
+	}
+	cmd.Flags().StringSliceVarP(&vreplicationSwitchTrafficOptions.Cells, "cells", "c", nil, fmt.Sprintf("Cells and/or CellAliases to %s traffic in.", verb))
+	cmd.Flags().Var((*topoproto.TabletTypeListFlag)(&vreplicationSwitchTrafficOptions.TabletTypes), "tablet-types", fmt.Sprintf("Tablet types to %s traffic for. Defaults to all of PRIMARY, REPLICA and RDONLY.", verb))
+	cmd.Flags().DurationVar(&vreplicationSwitchTrafficOptions.MaxReplicationLagAllowed, "max-replication-lag-allowed", 30*time.Second, "Allow traffic to be switched only if VReplication lag is below this.")
+	cmd.Flags().BoolVar(&vreplicationSwitchTrafficOptions.EnableReverseReplication, "enable-reverse-replication", true, "Setup replication going back to the original source keyspace to support rolling back the traffic cutover.")
+	cmd.Flags().DurationVar(&vreplicationSwitchTrafficOptions.Timeout, "timeout", 30*time.Second, "Specifies the maximum time to wait, in seconds, for VReplication to catch up on primary tablets. The traffic switch will be cancelled on timeout.")
+	cmd.Flags().BoolVar(&vreplicationSwitchTrafficOptions.DryRun, "dry-run", false, "Print the actions that would be taken and report any known errors that would have occurred.")
+}
+
+func commandWorkflowSwitchTraffic(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.WorkflowSwitchTraffic(commandCtx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace:                 vreplicationOptions.TargetKeyspace,
+		Workflow:                 vreplicationOptions.Workflow,
+		Cells:                    vreplicationSwitchTrafficOptions.Cells,
+		TabletTypes:              vreplicationSwitchTrafficOptions.TabletTypes,
+		MaxReplicationLagAllowed: protoutil.DurationToProto(vreplicationSwitchTrafficOptions.MaxReplicationLagAllowed),
+		EnableReverseReplication: vreplicationSwitchTrafficOptions.EnableReverseReplication,
+		Timeout:                  protoutil.DurationToProto(vreplicationSwitchTrafficOptions.Timeout),
+		DryRun:                   vreplicationSwitchTrafficOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+func commandWorkflowReverseTraffic(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.WorkflowReverseTraffic(commandCtx, &vtctldatapb.WorkflowReverseTrafficRequest{
+		Keyspace:                 vreplicationOptions.TargetKeyspace,
+		Workflow:                 vreplicationOptions.Workflow,
+		Cells:                    vreplicationSwitchTrafficOptions.Cells,
+		TabletTypes:              vreplicationSwitchTrafficOptions.TabletTypes,
+		MaxReplicationLagAllowed: protoutil.DurationToProto(vreplicationSwitchTrafficOptions.MaxReplicationLagAllowed),
+		EnableReverseReplication: vreplicationSwitchTrafficOptions.EnableReverseReplication,
+		Timeout:                  protoutil.DurationToProto(vreplicationSwitchTrafficOptions.Timeout),
+		DryRun:                   vreplicationSwitchTrafficOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+// newCompleteCommand returns a command that makes a WorkflowComplete gRPC call
+// to a vtctld for the given workflow type.
+func newCompleteCommand(workflowType string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "complete",
+		Short:                 fmt.Sprintf("Complete a %s VReplication workflow.", workflowType),
+		Example:               fmt.Sprintf(`vtctldclient --server localhost:15999 %s --workflow commerce2customer --target-keyspace customer complete`, workflowType),
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Complete"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowComplete,
+	}
+	cmd.Flags().BoolVar(&vreplicationCompleteOptions.KeepData, "keep-data", false, "Keep the original source table data that was copied by the workflow.")
+	cmd.Flags().BoolVar(&vreplicationCompleteOptions.KeepRoutingRules, "keep-routing-rules", false, "Keep the routing rules created for the workflow.")
+	cmd.Flags().BoolVar(&vreplicationCompleteOptions.RenameTables, "rename-tables", false, "Keep the original source table data that was copied by the workflow, but rename each table to '_<tablename>_old'.")
+	cmd.Flags().BoolVar(&vreplicationCompleteOptions.DryRun, "dry-run", false, "Print the actions that would be taken and report any known errors that would have occurred.")
+	return cmd
+}
+
+func commandWorkflowComplete(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.WorkflowComplete(commandCtx, &vtctldatapb.WorkflowCompleteRequest{
+		Keyspace:         vreplicationOptions.TargetKeyspace,
+		Workflow:         vreplicationOptions.Workflow,
+		KeepData:         vreplicationCompleteOptions.KeepData,
+		KeepRoutingRules: vreplicationCompleteOptions.KeepRoutingRules,
+		RenameTables:     vreplicationCompleteOptions.RenameTables,
+		DryRun:           vreplicationCompleteOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+// newCancelCommand returns a command that makes a WorkflowCancel gRPC call to
+// a vtctld for the given workflow type.
+func newCancelCommand(workflowType string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:                   "cancel",
+		Short:                 fmt.Sprintf("Cancel a %s VReplication workflow.", workflowType),
+		Example:               fmt.Sprintf(`vtctldclient --server localhost:15999 %s --workflow commerce2customer --target-keyspace customer cancel`, workflowType),
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Cancel"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowCancel,
+	}
+	cmd.Flags().BoolVar(&vreplicationCancelOptions.KeepData, "keep-data", false, "Keep the partially copied table data from the workflow in the target keyspace.")
+	cmd.Flags().BoolVar(&vreplicationCancelOptions.KeepRoutingRules, "keep-routing-rules", false, "Keep the routing rules created for the workflow.")
+	cmd.Flags().BoolVar(&vreplicationCancelOptions.DryRun, "dry-run", false, "Print the actions that would be taken and report any known errors that would have occurred.")
+	return cmd
+}
+
+func commandWorkflowCancel(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.WorkflowCancel(commandCtx, &vtctldatapb.WorkflowCancelRequest{
+		Keyspace:         vreplicationOptions.TargetKeyspace,
+		Workflow:         vreplicationOptions.Workflow,
+		KeepData:         vreplicationCancelOptions.KeepData,
+		KeepRoutingRules: vreplicationCancelOptions.KeepRoutingRules,
+		DryRun:           vreplicationCancelOptions.DryRun,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+// newStatusCommand returns a command that makes a WorkflowStatus gRPC call to
+// a vtctld for the given workflow type.
+func newStatusCommand(workflowType string) *cobra.Command {
+	return &cobra.Command{
+		Use:                   "status",
+		Short:                 fmt.Sprintf("Show the current status for a %s VReplication workflow.", workflowType),
+		Example:               fmt.Sprintf(`vtctldclient --server localhost:15999 %s --workflow commerce2customer --target-keyspace customer status`, workflowType),
+		DisableFlagsInUseLine: true,
+		Aliases:               []string{"Status", "progress", "Progress"},
+		Args:                  cobra.NoArgs,
+		RunE:                  commandWorkflowStatus,
+	}
+}
+
+func commandWorkflowStatus(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.WorkflowStatus(commandCtx, &vtctldatapb.WorkflowStatusRequest{
+		Keyspace: vreplicationOptions.TargetKeyspace,
+		Workflow: vreplicationOptions.Workflow,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Sort the streams of each shard for deterministic output.
+	for _, shardStreams := range resp.ShardStreams {
+		sort.Slice(shardStreams.Streams, func(i, j int) bool {
+			return shardStreams.Streams[i].Id < shardStreams.Streams[j].Id
+		})
+	}
+
+	data, err := cli.MarshalJSON(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
+// addVReplicationCommands registers the persistent flags and the commands
+// that are shared by the MoveTables and Reshard parent commands.
+func addVReplicationCommands(parent *cobra.Command, workflowType string) {
+	parent.PersistentFlags().StringVarP(&vreplicationOptions.Workflow, "workflow", "w", "", "The workflow you want to perform the command on (required).")
+	parent.MarkPersistentFlagRequired("workflow")
+	parent.PersistentFlags().StringVar(&vreplicationOptions.TargetKeyspace, "target-keyspace", "", "Target keyspace for this workflow (required).")
+	parent.MarkPersistentFlagRequired("target-keyspace")
+
+	parent.AddCommand(newSwitchTrafficCommand(workflowType))
+	parent.AddCommand(newReverseTrafficCommand(workflowType))
+	parent.AddCommand(newCompleteCommand(workflowType))
+	parent.AddCommand(newCancelCommand(workflowType))
+	parent.AddCommand(newStatusCommand(workflowType))
+}
@@ -93,6 +93,9 @@ func transformArgsForPflag(fs *pflag.FlagSet, args []string) (result []string) {
 func main() {
 	servenv.RegisterDefaultFlags()
 	servenv.RegisterFlags()
+	servenv.RegisterGRPCServerFlags()
+	servenv.RegisterGRPCServerAuthFlags()
+	servenv.RegisterServiceMapFlag()
 
 	var configFile string
 	servenv.OnParseFor("vtorc", func(fs *pflag.FlagSet) {
@@ -173,7 +173,7 @@ func Connect(ctx context.Context, params *ConnParams) (*Conn, error) {
 // Ping implements mysql ping command.
 func (c *Conn) Ping() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComPing
 
@@ -228,6 +228,17 @@ func (c *Conn) clientHandshake(params *ConnParams) error {
 		c.Capabilities = capabilities & (CapabilityClientDeprecateEOF)
 	}
 
+	// Ask for protocol compression if the server supports the requested
+	// algorithm. The connection stays uncompressed otherwise.
+	compression, err := CompressionCapabilities([]string{params.Compression})
+	if err != nil {
+		return NewSQLError(CRUnknownError, SSUnknownSQLState, "%v", err)
+	}
+	c.Capabilities |= capabilities & compression
+	if c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0 && !validZstdCompressionLevel(params.zstdCompressionLevel()) {
+		return NewSQLError(CRUnknownError, SSUnknownSQLState, "invalid zstd compression level: %v", params.ZstdCompressionLevel)
+	}
+
 	charset, err := collations.Local().ParseConnectionCharset(params.Charset)
 	if err != nil {
 		return err
@@ -310,6 +321,12 @@ func (c *Conn) clientHandshake(params *ConnParams) error {
 		return err
 	}
 
+	// We are authenticated, switch to the compressed protocol if
+	// it was negotiated.
+	if err := c.enableCompression(params.zstdCompressionLevel()); err != nil {
+		return NewSQLError(CRServerHandshakeErr, SSUnknownSQLState, "%v", err)
+	}
+
 	// If the server didn't support DbName in its handshake, set
 	// it now. This is what the 'mysql' client does.
 	if capabilities&CapabilityClientConnectWithDB == 0 && params.DbName != "" {
@@ -482,6 +499,8 @@ func (c *Conn) writeSSLRequest(capabilities uint32, characterSet uint8, params *
 		// If the server supported
 		// CapabilityClientSessionTrack, we also support it.
 		c.Capabilities&CapabilityClientSessionTrack |
+		// Negotiated protocol compression.
+		c.Capabilities&(CapabilityClientCompress|CapabilityClientZstdCompressionAlgorithm) |
 		// Pass-through ClientFoundRows flag.
 		CapabilityClientFoundRows&uint32(params.Flags)
 
@@ -541,7 +560,9 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 		CapabilityClientFoundRows&uint32(params.Flags) |
 		// If the server supported
 		// CapabilityClientSessionTrack, we also support it.
-		c.Capabilities&CapabilityClientSessionTrack
+		c.Capabilities&CapabilityClientSessionTrack |
+		// Negotiated protocol compression.
+		c.Capabilities&(CapabilityClientCompress|CapabilityClientZstdCompressionAlgorithm)
 
 	// FIXME(alainjobart) add multi statement.
 
@@ -568,6 +589,11 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 		length++
 	}
 
+	// zstd compression level.
+	if capabilityFlags&CapabilityClientZstdCompressionAlgorithm != 0 {
+		length++
+	}
+
 	data, pos := c.startEphemeralPacketWithHeader(length)
 
 	// Client capability flags.
@@ -604,6 +630,11 @@ func (c *Conn) writeHandshakeResponse41(capabilities uint32, scrambledPassword [
 	// Assume native client during response
 	pos = writeNullString(data, pos, string(c.authPluginName))
 
+	// zstd compression level, only if zstd compression was negotiated.
+	if capabilityFlags&CapabilityClientZstdCompressionAlgorithm != 0 {
+		pos = writeByte(data, pos, byte(params.zstdCompressionLevel()))
+	}
+
 	// Sanity-check the length.
 	if pos != len(data) {
 		return NewSQLError(CRMalformedPacket, SSUnknownSQLState, "writeHandshakeResponse41: only packed %v bytes, out of %v allocated", pos, len(data))
@@ -155,8 +155,9 @@ type Conn struct {
 	// the client and the server, and currently in use.
 	// It is set during the initial handshake.
 	//
-	// It is only used for CapabilityClientDeprecateEOF
-	// and CapabilityClientFoundRows.
+	// It is only used for CapabilityClientDeprecateEOF,
+	// CapabilityClientFoundRows and the protocol compression
+	// capabilities.
 	Capabilities uint32
 
 	// closed is set to true when Close() is called on the connection.
@@ -190,6 +191,21 @@ type Conn struct {
 	// Packet encoding variables.
 	sequence uint8
 
+	// compression is the compressed packet framing, once protocol
+	// compression has been negotiated during the handshake. It is nil
+	// for uncompressed connections.
+	compression *compressedConn
+
+	// zstdCompressionLevel is the zstd compression level requested by
+	// the client in its handshake response. It is only used on the
+	// server side.
+	zstdCompressionLevel int
+
+	// queryAttributes are the query attributes sent by the client with
+	// the last COM_QUERY or COM_STMT_EXECUTE, if it negotiated
+	// CLIENT_QUERY_ATTRIBUTES. It is only used on the server side.
+	queryAttributes map[string]sqltypes.Value
+
 	// ExpectSemiSyncIndicator is applicable when the connection is used for replication (ComBinlogDump).
 	// When 'true', events are assumed to be padded with 2-byte semi-sync information
 	// See https://dev.mysql.com/doc/internals/en/semi-sync-binlog-event.html
@@ -313,7 +329,11 @@ func (c *Conn) startWriterBuffering() {
 	defer c.bufMu.Unlock()
 
 	c.bufferedWriter = writersPool.Get().(*bufio.Writer)
-	c.bufferedWriter.Reset(c.conn)
+	if c.compression != nil {
+		c.bufferedWriter.Reset(c.compression)
+	} else {
+		c.bufferedWriter.Reset(c.conn)
+	}
 }
 
 // endWriterBuffering must be called to terminate startWriteBuffering.
@@ -357,6 +377,9 @@ func (c *Conn) getWriter() (w io.Writer, unget func()) {
 		}
 	}
 	c.bufMu.Unlock()
+	if c.compression != nil {
+		return c.compression, func() {}
+	}
 	return c.conn, func() {}
 }
 
@@ -383,9 +406,19 @@ func (c *Conn) stopFlushTimer() {
 	}
 }
 
-// getReader returns reader for connection. It can be *bufio.Reader or net.Conn
-// depending on which buffer size was passed to newServerConn.
+// getReader returns reader for connection. It reads through the compressed
+// packet framing if compression was negotiated, and from getRawReader otherwise.
 func (c *Conn) getReader() io.Reader {
+	if c.compression != nil {
+		return c.compression
+	}
+	return c.getRawReader()
+}
+
+// getRawReader returns the reader for the network connection. It can be
+// *bufio.Reader or net.Conn depending on which buffer size was passed to
+// newServerConn.
+func (c *Conn) getRawReader() io.Reader {
 	if c.bufferedReader != nil {
 		return c.bufferedReader
 	}
@@ -412,12 +445,14 @@ func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 		return 0, vterrors.Wrapf(err, "io.ReadFull(header size) failed")
 	}
 
+	// With protocol compression, the sequence is checked on the
+	// compressed packets only, as MySQL does.
 	sequence := uint8(c.header[3])
-	if sequence != c.sequence {
+	if sequence != c.sequence && c.compression == nil {
 		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
 	}
 
-	c.sequence++
+	c.sequence = sequence + 1
 
 	return int(uint32(c.header[0]) | uint32(c.header[1])<<8 | uint32(c.header[2])<<16), nil
 }
@@ -711,13 +746,60 @@ func (c *Conn) recycleWritePacket() {
 	c.currentEphemeralPolicy = ephemeralUnused
 }
 
+// resetSequence resets the packet sequence, and the compressed packet
+// sequence if compression is in use. It needs to be called at the start
+// of every new command.
+func (c *Conn) resetSequence() {
+	c.sequence = 0
+	if c.compression != nil {
+		c.compression.sequence = 0
+	}
+}
+
+// enableCompression switches the connection to the compressed packet
+// framing, if compression was negotiated in the handshake. Both sides
+// switch once the handshake OK packet has been exchanged.
+func (c *Conn) enableCompression(zstdLevel int) error {
+	var algorithm string
+	switch {
+	case c.Capabilities&CapabilityClientCompress != 0:
+		algorithm = CompressionZlib
+	case c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0:
+		algorithm = CompressionZstd
+	default:
+		return nil
+	}
+	compression, err := newCompressedConn(c, algorithm, zstdLevel)
+	if err != nil {
+		return err
+	}
+	c.compression = compression
+	return nil
+}
+
+// CompressionAlgorithm returns the protocol compression algorithm used by
+// the connection, or CompressionUncompressed.
+func (c *Conn) CompressionAlgorithm() string {
+	if c.compression == nil {
+		return CompressionUncompressed
+	}
+	return c.compression.algorithm
+}
+
+// QueryAttributes returns the query attributes sent by the client with
+// the command being executed, keyed by name. It returns nil if the
+// client did not send any.
+func (c *Conn) QueryAttributes() map[string]sqltypes.Value {
+	return c.queryAttributes
+}
+
 // writeComQuit writes a Quit message for the server, to indicate we
 // want to close the connection.
 // Client -> Server.
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) writeComQuit() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComQuit
@@ -923,7 +1005,7 @@ func (c *Conn) writeEOFPacket(flags uint16, warnings uint16) error {
 // handleNextCommand is called in the server loop to process
 // incoming packets.
 func (c *Conn) handleNextCommand(handler Handler) bool {
-	c.sequence = 0
+	c.resetSequence()
 	data, err := c.readEphemeralPacket()
 	if err != nil {
 		// Don't log EOF errors. They cause too much spam.
@@ -1360,11 +1442,13 @@ func (c *Conn) handleComQuery(handler Handler, data []byte) (kontinue bool) {
 	}()
 
 	queryStart := time.Now()
-	query := c.parseComQuery(data)
+	query, err := c.parseComQuery(data)
 	c.recycleReadPacket()
+	if err != nil {
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
 
 	var queries []string
-	var err error
 	if c.Capabilities&CapabilityClientMultiStatements != 0 {
 		queries, err = splitStatementFunction(query)
 		if err != nil {
@@ -1710,3 +1794,9 @@ func (c *Conn) IsMarkedForClose() bool {
 func GetTestConn() *Conn {
 	return newConn(testConn{})
 }
+
+// SetTestQueryAttributes sets the query attributes of the command being
+// executed, for testing purpose only.
+func (c *Conn) SetTestQueryAttributes(attributes map[string]sqltypes.Value) {
+	c.queryAttributes = attributes
+}
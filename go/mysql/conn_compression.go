@@ -0,0 +1,351 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// Protocol compression algorithms, named after the values of MySQL's
+// protocol_compression_algorithms system variable.
+// See https://dev.mysql.com/doc/refman/8.0/en/connection-compression-control.html
+const (
+	CompressionZlib         = "zlib"
+	CompressionZstd         = "zstd"
+	CompressionUncompressed = "uncompressed"
+)
+
+const (
+	// DefaultZstdCompressionLevel is the zstd compression level used
+	// when none is specified. It is the MySQL default.
+	DefaultZstdCompressionLevel = 3
+
+	// compressedPacketHeaderSize is the size of the header of a compressed
+	// packet: 3 bytes for the length of the compressed payload, 1 byte for
+	// the compressed sequence and 3 bytes for the length of the payload
+	// once uncompressed, or 0 if the payload is not compressed.
+	compressedPacketHeaderSize = 7
+
+	// minCompressLength is the size under which payloads are sent
+	// uncompressed. It is MIN_COMPRESS_LENGTH in MySQL.
+	minCompressLength = 50
+
+	// maxRetainedCompressionBuffer is the largest buffer a compressed
+	// connection keeps around between packets.
+	maxRetainedCompressionBuffer = 4 * connBufferSize
+)
+
+// CompressionCapabilities returns the capability flags that advertise
+// support for the given protocol compression algorithms.
+func CompressionCapabilities(algorithms []string) (uint32, error) {
+	var capabilities uint32
+	for _, algorithm := range algorithms {
+		switch algorithm {
+		case CompressionZlib:
+			capabilities |= CapabilityClientCompress
+		case CompressionZstd:
+			capabilities |= CapabilityClientZstdCompressionAlgorithm
+		case CompressionUncompressed, "":
+		default:
+			return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown protocol compression algorithm: %s", algorithm)
+		}
+	}
+	return capabilities, nil
+}
+
+// validZstdCompressionLevel returns true if level can be negotiated for
+// zstd protocol compression.
+func validZstdCompressionLevel(level int) bool {
+	return level >= 1 && level <= 22
+}
+
+// packetCodec compresses and decompresses the payload of compressed packets.
+type packetCodec interface {
+	// compress appends the compressed form of src to dst.
+	compress(dst, src []byte) ([]byte, error)
+	// decompress appends the size bytes obtained by decompressing src to dst.
+	decompress(dst, src []byte, size int) ([]byte, error)
+}
+
+type zlibCodec struct {
+	writer *zlib.Writer
+	reader io.ReadCloser
+}
+
+func (z *zlibCodec) compress(dst, src []byte) ([]byte, error) {
+	buf := bytes.NewBuffer(dst)
+	if z.writer == nil {
+		z.writer = zlib.NewWriter(buf)
+	} else {
+		z.writer.Reset(buf)
+	}
+	if _, err := z.writer.Write(src); err != nil {
+		return nil, err
+	}
+	if err := z.writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (z *zlibCodec) decompress(dst, src []byte, size int) ([]byte, error) {
+	var err error
+	if z.reader == nil {
+		z.reader, err = zlib.NewReader(bytes.NewReader(src))
+	} else {
+		err = z.reader.(zlib.Resetter).Reset(bytes.NewReader(src), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	start := len(dst)
+	dst = growBuffer(dst, size)
+	if _, err := io.ReadFull(z.reader, dst[start:]); err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// zstdEncoders holds one shared encoder per compression level. Encoders
+// are safe for concurrent use through EncodeAll, and are expensive to
+// create, so connections using the same level share them.
+var zstdEncoders struct {
+	sync.Mutex
+	byLevel map[int]*zstd.Encoder
+}
+
+func zstdEncoderForLevel(level int) (*zstd.Encoder, error) {
+	zstdEncoders.Lock()
+	defer zstdEncoders.Unlock()
+
+	if enc, ok := zstdEncoders.byLevel[level]; ok {
+		return enc, nil
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	if err != nil {
+		return nil, err
+	}
+	if zstdEncoders.byLevel == nil {
+		zstdEncoders.byLevel = make(map[int]*zstd.Encoder)
+	}
+	zstdEncoders.byLevel[level] = enc
+	return enc, nil
+}
+
+type zstdCodec struct {
+	encoder *zstd.Encoder
+}
+
+func (z *zstdCodec) compress(dst, src []byte) ([]byte, error) {
+	return z.encoder.EncodeAll(src, dst), nil
+}
+
+func (z *zstdCodec) decompress(dst, src []byte, size int) ([]byte, error) {
+	start := len(dst)
+	dst, err := zstdDecoder.DecodeAll(src, dst)
+	if err != nil {
+		return nil, err
+	}
+	if len(dst)-start != size {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "zstd: decompressed %v bytes, expected %v", len(dst)-start, size)
+	}
+	return dst, nil
+}
+
+// growBuffer extends buf by n bytes, reallocating it if needed.
+func growBuffer(buf []byte, n int) []byte {
+	if cap(buf)-len(buf) < n {
+		grown := make([]byte, len(buf), len(buf)+n)
+		copy(grown, buf)
+		buf = grown
+	}
+	return buf[:len(buf)+n]
+}
+
+// compressedConn implements the compressed packet framing on top of
+// the network connection of a Conn. Once compression has been negotiated
+// all the packets are read from and written to it, as a stream of bytes:
+// a regular packet may span several compressed packets, and a compressed
+// packet may contain several regular packets.
+//
+// compressedConn is not safe for concurrent use. Writes are either done
+// by the goroutine owning the Conn, or through the bufferedWriter which
+// is protected by bufMu.
+type compressedConn struct {
+	c         *Conn
+	algorithm string
+	codec     packetCodec
+
+	// sequence is the sequence number of the compressed packets. It is
+	// shared between reads and writes, and reset with the sequence of
+	// the regular packets at the start of every command.
+	sequence uint8
+
+	header  [compressedPacketHeaderSize]byte
+	payload []byte
+
+	// data holds the uncompressed content of the last compressed
+	// packet read, and pos how much of it was consumed already.
+	data []byte
+	pos  int
+
+	// out is the scratch buffer used to build compressed packets.
+	out []byte
+}
+
+// newCompressedConn returns the compressed framing for the given
+// algorithm. level is only used by zstd.
+func newCompressedConn(c *Conn, algorithm string, level int) (*compressedConn, error) {
+	cc := &compressedConn{c: c, algorithm: algorithm}
+	switch algorithm {
+	case CompressionZlib:
+		cc.codec = &zlibCodec{}
+	case CompressionZstd:
+		if !validZstdCompressionLevel(level) {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid zstd compression level: %v", level)
+		}
+		enc, err := zstdEncoderForLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		cc.codec = &zstdCodec{encoder: enc}
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown protocol compression algorithm: %s", algorithm)
+	}
+	return cc, nil
+}
+
+// Read implements io.Reader, returning the uncompressed content of
+// the compressed packets read from the connection.
+func (cc *compressedConn) Read(p []byte) (int, error) {
+	for cc.pos == len(cc.data) {
+		if err := cc.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, cc.data[cc.pos:])
+	cc.pos += n
+	return n, nil
+}
+
+func (cc *compressedConn) readCompressedPacket() error {
+	r := cc.c.getRawReader()
+
+	// io.EOF is propagated as is, see readHeaderFrom.
+	if _, err := io.ReadFull(r, cc.header[:]); err != nil {
+		return err
+	}
+	length := int(uint32(cc.header[0]) | uint32(cc.header[1])<<8 | uint32(cc.header[2])<<16)
+	sequence := cc.header[3]
+	uncompressedLength := int(uint32(cc.header[4]) | uint32(cc.header[5])<<8 | uint32(cc.header[6])<<16)
+
+	if sequence != cc.sequence {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid compressed sequence, expected %v got %v", cc.sequence, sequence)
+	}
+	cc.sequence++
+
+	if cap(cc.payload) > maxRetainedCompressionBuffer {
+		cc.payload = nil
+	}
+	cc.payload = growBuffer(cc.payload[:0], length)
+	if _, err := io.ReadFull(r, cc.payload); err != nil {
+		return vterrors.Wrapf(err, "io.ReadFull(compressed packet body of length %v) failed", length)
+	}
+
+	if cap(cc.data) > maxRetainedCompressionBuffer {
+		cc.data = nil
+	}
+	cc.pos = 0
+	if uncompressedLength == 0 {
+		// The payload was sent uncompressed.
+		cc.data = append(cc.data[:0], cc.payload...)
+		return nil
+	}
+	data, err := cc.codec.decompress(cc.data[:0], cc.payload, uncompressedLength)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot decompress %s packet", cc.algorithm)
+	}
+	cc.data = data
+	return nil
+}
+
+// Write implements io.Writer. The data is sent in as many compressed
+// packets as needed, directly to the network connection.
+func (cc *compressedConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > MaxPacketSize {
+			chunk = chunk[:MaxPacketSize]
+		}
+		if err := cc.writeCompressedPacket(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+func (cc *compressedConn) writeCompressedPacket(chunk []byte) error {
+	if cap(cc.out) > maxRetainedCompressionBuffer {
+		cc.out = nil
+	}
+	out := append(cc.out[:0], make([]byte, compressedPacketHeaderSize)...)
+
+	uncompressedLength := 0
+	if len(chunk) >= minCompressLength {
+		compressed, err := cc.codec.compress(out, chunk)
+		if err != nil {
+			return vterrors.Wrapf(err, "cannot compress %s packet", cc.algorithm)
+		}
+		// Only keep the compressed payload if it is actually smaller.
+		if len(compressed)-compressedPacketHeaderSize < len(chunk) {
+			out = compressed
+			uncompressedLength = len(chunk)
+		}
+	}
+	if uncompressedLength == 0 {
+		out = append(out[:compressedPacketHeaderSize], chunk...)
+	}
+	cc.out = out
+
+	length := len(out) - compressedPacketHeaderSize
+	out[0] = byte(length)
+	out[1] = byte(length >> 8)
+	out[2] = byte(length >> 16)
+	out[3] = cc.sequence
+	out[4] = byte(uncompressedLength)
+	out[5] = byte(uncompressedLength >> 8)
+	out[6] = byte(uncompressedLength >> 16)
+
+	if n, err := cc.c.conn.Write(out); err != nil {
+		return vterrors.Wrapf(err, "Write(compressed packet) failed")
+	} else if n != len(out) {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Write(compressed packet) returned a short write: %v < %v", n, len(out))
+	}
+	cc.sequence++
+	return nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"context"
+	crypto_rand "crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestCompressionCapabilities(t *testing.T) {
+	capabilities, err := CompressionCapabilities([]string{CompressionZlib, CompressionZstd, CompressionUncompressed})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(CapabilityClientCompress|CapabilityClientZstdCompressionAlgorithm), capabilities)
+
+	capabilities, err = CompressionCapabilities(nil)
+	require.NoError(t, err)
+	assert.Zero(t, capabilities)
+
+	_, err = CompressionCapabilities([]string{"lz4"})
+	assert.EqualError(t, err, "unknown protocol compression algorithm: lz4")
+}
+
+func TestCompressedPackets(t *testing.T) {
+	for _, tcase := range []struct {
+		algorithm  string
+		capability uint32
+	}{
+		{CompressionZlib, CapabilityClientCompress},
+		{CompressionZstd, CapabilityClientZstdCompressionAlgorithm},
+	} {
+		t.Run(tcase.algorithm, func(t *testing.T) {
+			listener, sConn, cConn := createSocketPair(t)
+			defer func() {
+				listener.Close()
+				sConn.Close()
+				cConn.Close()
+			}()
+
+			for _, c := range []*Conn{sConn, cConn} {
+				c.Capabilities |= tcase.capability
+				require.NoError(t, c.enableCompression(DefaultZstdCompressionLevel))
+				assert.Equal(t, tcase.algorithm, c.CompressionAlgorithm())
+			}
+
+			random := make([]byte, 100000)
+			_, err := crypto_rand.Read(random)
+			require.NoError(t, err)
+
+			for _, data := range [][]byte{
+				// Small enough to be sent uncompressed.
+				{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+				// 0 length packet.
+				{},
+				// Does not compress, sent uncompressed.
+				random,
+				// Under the limit, still one packet.
+				make([]byte, MaxPacketSize-1),
+				// Exactly the limit, two packets.
+				make([]byte, MaxPacketSize),
+				// Over the limit, two packets.
+				make([]byte, MaxPacketSize+1000),
+			} {
+				if len(data) > 1 {
+					data[0] = 0xab
+					data[len(data)-1] = 0xef
+				}
+				verifyPacketCommsSpecific(t, cConn, data, useWritePacket, sConn.ReadPacket)
+				verifyPacketCommsSpecific(t, cConn, data, useWriteEphemeralPacketBuffered, sConn.ReadPacket)
+				verifyPacketCommsSpecific(t, cConn, data, useWriteEphemeralPacketDirect, sConn.ReadPacket)
+
+				verifyPacketCommsSpecific(t, cConn, data, useWriteEphemeralPacketBuffered, sConn.readEphemeralPacket)
+				sConn.recycleReadPacket()
+			}
+
+			// A new command resets the compressed sequence on both sides.
+			sConn.resetSequence()
+			cConn.resetSequence()
+			verifyPacketCommsSpecific(t, cConn, random, useWritePacket, sConn.ReadPacket)
+		})
+	}
+}
+
+// compressionHandler reports the compression negotiated by the server
+// side of every new connection.
+type compressionHandler struct {
+	testHandler
+	ready chan *compressedConn
+}
+
+func (h *compressionHandler) ConnectionReady(c *Conn) {
+	h.ready <- c.compression
+}
+
+func TestCompressionServer(t *testing.T) {
+	th := &compressionHandler{ready: make(chan *compressedConn, 1)}
+	th.result = &sqltypes.Result{
+		Fields: []*querypb.Field{{
+			Name: "name",
+			Type: querypb.Type_VARCHAR,
+		}},
+	}
+	for i := 0; i < 10000; i++ {
+		th.result.Rows = append(th.result.Rows, []sqltypes.Value{
+			sqltypes.NewVarChar(fmt.Sprintf("this is a fairly repetitive row number %d", i)),
+		})
+	}
+
+	newListener := func(t *testing.T, algorithms []string) (string, int) {
+		l, err := NewListener("tcp", "127.0.0.1:", NewAuthServerNone(), th, 0, 0, false, false, 0)
+		require.NoError(t, err)
+		t.Cleanup(l.Close)
+		require.NoError(t, l.SetCompressionAlgorithms(algorithms))
+		go l.Accept()
+		return getHostPort(t, l.Addr())
+	}
+
+	for _, tcase := range []struct {
+		name       string
+		server     []string
+		client     string
+		level      int
+		compressed string
+	}{{
+		name:       "uncompressed",
+		server:     []string{CompressionZlib, CompressionZstd},
+		compressed: CompressionUncompressed,
+	}, {
+		name:       "zlib",
+		server:     []string{CompressionZlib, CompressionZstd},
+		client:     CompressionZlib,
+		compressed: CompressionZlib,
+	}, {
+		name:       "zstd",
+		server:     []string{CompressionZlib, CompressionZstd},
+		client:     CompressionZstd,
+		level:      19,
+		compressed: CompressionZstd,
+	}, {
+		name:       "server does not support zstd",
+		server:     []string{CompressionZlib},
+		client:     CompressionZstd,
+		compressed: CompressionUncompressed,
+	}, {
+		name:       "server does not support compression",
+		client:     CompressionZlib,
+		compressed: CompressionUncompressed,
+	}} {
+		t.Run(tcase.name, func(t *testing.T) {
+			host, port := newListener(t, tcase.server)
+			params := &ConnParams{
+				Host:                 host,
+				Port:                 port,
+				Compression:          tcase.client,
+				ZstdCompressionLevel: tcase.level,
+			}
+			c, err := Connect(context.Background(), params)
+			require.NoError(t, err)
+			defer c.Close()
+
+			assert.Equal(t, tcase.compressed, c.CompressionAlgorithm())
+			if compression := <-th.ready; compression != nil {
+				assert.Equal(t, tcase.compressed, compression.algorithm)
+			} else {
+				assert.Equal(t, CompressionUncompressed, tcase.compressed)
+			}
+
+			// Run a few commands, to cover the sequence resets.
+			for i := 0; i < 3; i++ {
+				result, err := c.ExecuteFetch("select rows", 100000, true)
+				require.NoError(t, err)
+				assert.True(t, th.result.Equal(result), "unexpected result")
+				require.NoError(t, c.Ping())
+			}
+		})
+	}
+
+	host, port := newListener(t, []string{CompressionZstd})
+	_, err := Connect(context.Background(), &ConnParams{Host: host, Port: port, Compression: "lz4"})
+	assert.ErrorContains(t, err, "unknown protocol compression algorithm: lz4")
+
+	_, err = Connect(context.Background(), &ConnParams{Host: host, Port: port, Compression: CompressionZstd, ZstdCompressionLevel: 30})
+	assert.ErrorContains(t, err, "invalid zstd compression level: 30")
+}
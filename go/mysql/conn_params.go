@@ -57,6 +57,17 @@ type ConnParams struct {
 	// for informative purposes. It has no programmatic value. Returning this field is
 	// disabled by default.
 	EnableQueryInfo bool
+
+	// Compression is the protocol compression algorithm to use: one of
+	// CompressionZlib or CompressionZstd. Compression is only used if the
+	// server supports the algorithm, the connection is uncompressed otherwise.
+	// Leaving it empty, or setting it to CompressionUncompressed, disables
+	// compression.
+	Compression string `json:"compression,omitempty"`
+
+	// ZstdCompressionLevel is the compression level to use with zstd,
+	// from 1 to 22. It defaults to DefaultZstdCompressionLevel.
+	ZstdCompressionLevel int `json:"zstd_compression_level,omitempty"`
 }
 
 // EnableSSL will set the right flag on the parameters.
@@ -82,6 +93,15 @@ func (cp *ConnParams) EnableClientFoundRows() {
 	cp.Flags |= CapabilityClientFoundRows
 }
 
+// zstdCompressionLevel returns the zstd compression level to use,
+// applying the default if none is set.
+func (cp *ConnParams) zstdCompressionLevel() int {
+	if cp.ZstdCompressionLevel == 0 {
+		return DefaultZstdCompressionLevel
+	}
+	return cp.ZstdCompressionLevel
+}
+
 // SslRequired returns whether the connection parameters
 // define that SSL is a requirement. If SslMode is set, it uses
 // that to determine this, if it's not set it falls back to
@@ -83,8 +83,10 @@ const (
 	// CLIENT_NO_SCHEMA 1 << 4
 	// Do not permit database.table.column. We do permit it.
 
-	// CLIENT_COMPRESS 1 << 5
-	// We do not support compression. CPU is usually our bottleneck.
+	// CapabilityClientCompress is CLIENT_COMPRESS.
+	// Use zlib protocol compression. Only negotiated when
+	// compression is explicitly enabled, as CPU is usually our bottleneck.
+	CapabilityClientCompress = 1 << 5
 
 	// CLIENT_ODBC 1 << 6
 	// No special behavior since 3.22.
@@ -155,6 +157,28 @@ const (
 	// CapabilityClientDeprecateEOF is CLIENT_DEPRECATE_EOF
 	// Expects an OK (instead of EOF) after the resultset rows of a Text Resultset.
 	CapabilityClientDeprecateEOF = 1 << 24
+
+	// CLIENT_OPTIONAL_RESULTSET_METADATA 1 << 25
+	// The client can handle optional metadata information in the resultset.
+	// Not yet supported.
+
+	// CapabilityClientZstdCompressionAlgorithm is CLIENT_ZSTD_COMPRESSION_ALGORITHM.
+	// Use zstd protocol compression, at the level sent by the client
+	// in the handshake response.
+	CapabilityClientZstdCompressionAlgorithm = 1 << 26
+
+	// CapabilityClientQueryAttributes is CLIENT_QUERY_ATTRIBUTES.
+	// The client can send query attributes with COM_QUERY and
+	// COM_STMT_EXECUTE.
+	CapabilityClientQueryAttributes = 1 << 27
+)
+
+// Cursor type flags of COM_STMT_EXECUTE.
+const (
+	// cursorParameterCountAvailable is PARAMETER_COUNT_AVAILABLE.
+	// The parameter count is sent even if the statement has no
+	// parameters, to carry query attributes.
+	cursorParameterCountAvailable = 0x08
 )
 
 // Status flags. They are returned by the server in a few cases.
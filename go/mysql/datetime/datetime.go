@@ -19,6 +19,9 @@ package datetime
 import (
 	"time"
 
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+
 	"vitess.io/vitess/go/mysql/decimal"
 	"vitess.io/vitess/go/vt/vthash"
 )
@@ -427,6 +430,14 @@ func (t Time) toSeconds() int {
 	return tsecs
 }
 
+func (t Time) microseconds() int64 {
+	usecs := int64(t.Hour()*secondsPerHour+t.Minute()*secondsPerMinute+t.Second())*1e6 + int64(t.Nanosecond()/1000)
+	if t.Neg() {
+		return -usecs
+	}
+	return usecs
+}
+
 func (d Date) ToStdTime(loc *time.Location) (out time.Time) {
 	return time.Date(d.Year(), time.Month(d.Month()), d.Day(), 0, 0, 0, 0, loc)
 }
@@ -491,6 +502,19 @@ func (d Date) AddInterval(itv *Interval) (Date, bool) {
 	return dt.Date, ok
 }
 
+// LastDay returns the date for the last day of the month of d.
+func (d Date) LastDay() Date {
+	d.day = uint8(daysIn(time.Month(d.month), int(d.year)))
+	return d
+}
+
+// DayNumber returns the absolute day number for d, as calculated by MySQL.
+// Day numbers are only meaningful when compared to each other, e.g. to
+// find the number of days between two dates.
+func (d Date) DayNumber() int {
+	return mysqlDayNumber(d.Year(), d.Month(), d.Day())
+}
+
 func (dt DateTime) FormatInt64() int64 {
 	d := dt.Round(0)
 	return d.Date.FormatInt64()*1000000 + d.Time.FormatInt64()
@@ -527,6 +551,59 @@ func (dt DateTime) AddInterval(itv *Interval, stradd bool) (DateTime, uint8, boo
 	return dt, itv.precision(stradd), ok
 }
 
+// TimestampDiff returns the difference between dt2 and dt1 in the given unit,
+// following the semantics of MySQL's TIMESTAMPDIFF: the result is truncated
+// towards zero, and month-based units only count whole months.
+// It returns an error for the units TIMESTAMPDIFF doesn't support.
+func TimestampDiff(dt1, dt2 DateTime, unit IntervalType) (int64, error) {
+	diff := dt2.microseconds() - dt1.microseconds()
+
+	sign := int64(1)
+	if diff < 0 {
+		sign = -1
+		diff = -diff
+		dt1, dt2 = dt2, dt1
+	}
+
+	switch unit {
+	case IntervalYear:
+		return sign * monthsBetween(dt1, dt2) / 12, nil
+	case IntervalQuarter:
+		return sign * monthsBetween(dt1, dt2) / 3, nil
+	case IntervalMonth:
+		return sign * monthsBetween(dt1, dt2), nil
+	case IntervalWeek:
+		return sign * diff / (secondsPerDay * 1e6) / 7, nil
+	case IntervalDay:
+		return sign * diff / (secondsPerDay * 1e6), nil
+	case IntervalHour:
+		return sign * diff / (secondsPerHour * 1e6), nil
+	case IntervalMinute:
+		return sign * diff / (secondsPerMinute * 1e6), nil
+	case IntervalSecond:
+		return sign * diff / 1e6, nil
+	case IntervalMicrosecond:
+		return sign * diff, nil
+	default:
+		return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unsupported TIMESTAMPDIFF unit: %s", unit.ToString())
+	}
+}
+
+// monthsBetween returns the number of whole months between beg and end,
+// where beg must not be after end.
+func monthsBetween(beg, end DateTime) int64 {
+	yearBeg, monthBeg, dayBeg := beg.Date.Year(), beg.Date.Month(), beg.Date.Day()
+	yearEnd, monthEnd, dayEnd := end.Date.Year(), end.Date.Month(), end.Date.Day()
+
+	months := 12*(yearEnd-yearBeg) + (monthEnd - monthBeg)
+	if dayEnd < dayBeg {
+		months--
+	} else if dayEnd == dayBeg && end.Time.microseconds() < beg.Time.microseconds() {
+		months--
+	}
+	return int64(months)
+}
+
 func (dt DateTime) Round(p int) (r DateTime) {
 	if dt.Time.nanosecond == 0 {
 		return dt
@@ -552,6 +629,10 @@ func (dt DateTime) Round(p int) (r DateTime) {
 	return r
 }
 
+func (dt DateTime) microseconds() int64 {
+	return int64(dt.Date.DayNumber())*secondsPerDay*1e6 + dt.Time.microseconds()
+}
+
 func (dt DateTime) toSeconds() int {
 	return (dt.Date.Day()-1)*secondsPerDay + dt.Time.toSeconds()
 }
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package datetime
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimestampDiff(t *testing.T) {
+	testCases := []struct {
+		from, to string
+		unit     IntervalType
+		want     int64
+	}{
+		{"2003-02-01 00:00:00", "2003-05-01 12:05:55", IntervalMonth, 3},
+		{"2003-02-01 00:00:00", "2003-05-01 12:05:55", IntervalQuarter, 1},
+		{"2002-05-01 00:00:00", "2001-01-01 00:00:00", IntervalYear, -1},
+		{"2002-05-01 00:00:00", "2001-01-01 00:00:00", IntervalMonth, -16},
+		{"2003-02-01 00:00:00", "2003-05-01 12:05:55", IntervalMinute, 128885},
+		{"2003-01-31 00:00:00", "2003-02-28 00:00:00", IntervalMonth, 0},
+		{"2003-01-31 10:00:00", "2003-02-28 09:00:00", IntervalDay, 27},
+		{"2003-01-15 10:00:00", "2003-02-15 09:59:59.999999", IntervalMonth, 0},
+		{"2003-01-15 10:00:00", "2003-02-15 10:00:00", IntervalMonth, 1},
+		{"2000-02-29 00:00:00", "2001-02-28 00:00:00", IntervalYear, 0},
+		{"2000-02-29 00:00:00", "2004-02-29 00:00:00", IntervalYear, 4},
+		{"2020-01-01 00:00:00", "2020-01-29 00:00:00", IntervalWeek, 4},
+		{"2020-01-01 00:00:00", "2020-01-01 00:00:01.5", IntervalMicrosecond, 1500000},
+		{"2020-01-01 00:00:01.5", "2020-01-01 00:00:00", IntervalSecond, -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.from+"/"+tc.to+"/"+tc.unit.ToString(), func(t *testing.T) {
+			from, _, ok := ParseDateTime(tc.from, -1)
+			require.True(t, ok)
+			to, _, ok := ParseDateTime(tc.to, -1)
+			require.True(t, ok)
+			got, err := TimestampDiff(from, to, tc.unit)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	from, _, _ := ParseDateTime("2020-01-01 00:00:00", -1)
+	_, err := TimestampDiff(from, from, IntervalDayHour)
+	assert.ErrorContains(t, err, "unsupported TIMESTAMPDIFF unit: day_hour")
+}
+
+func TestLastDay(t *testing.T) {
+	testCases := []struct {
+		date, want string
+	}{
+		{"2003-02-05", "2003-02-28"},
+		{"2004-02-05", "2004-02-29"},
+		{"2000-02-01", "2000-02-29"},
+		{"1900-02-01", "1900-02-28"},
+		{"2004-01-01", "2004-01-31"},
+		{"2003-04-30", "2003-04-30"},
+	}
+
+	for _, tc := range testCases {
+		d, ok := ParseDate(tc.date)
+		require.True(t, ok)
+		assert.Equal(t, tc.want, string(d.LastDay().Format()))
+	}
+}
+
+func TestStrftimeParse(t *testing.T) {
+	testCases := []struct {
+		format, input string
+		want          string
+		ok            bool
+	}{
+		{"%M %d,%Y", "May 1,2013", "2013-05-01 00:00:00", true},
+		{"%b %D %Y", "Feb 3rd 2013", "2013-02-03 00:00:00", true},
+		{"%W, %d/%m/%Y", "Friday, 10/05/2013", "2013-05-10 00:00:00", true},
+		{"%Y-%m-%d %r", "2013-05-10 11:22:33 PM", "2013-05-10 23:22:33", true},
+		{"%Y-%m-%d %T", "2013-05-10 11:22:33", "2013-05-10 11:22:33", true},
+		{"%H:%i:%s.%f", "11:22:33.5", "0000-01-01 11:22:33.500000", true},
+		{"abc %Y", "abc 2013", "2013-01-01 00:00:00", true},
+		{"abc %Y", "abd 2013", "", false},
+		{"%X %V", "2013 10", "", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format+"/"+tc.input, func(t *testing.T) {
+			f, err := New(tc.format)
+			require.NoError(t, err)
+			dt, l, ok := f.ParseLenient(tc.input, -1)
+			require.Equal(t, tc.ok, ok)
+			if ok {
+				assert.Equal(t, tc.want, string(dt.Format(uint8(l))))
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ limitations under the License.
 package datetime
 
 import (
+	"strings"
 	"time"
 )
 
@@ -48,6 +49,31 @@ var shortDayNames = []string{
 	"Sat",
 }
 
+var longDayNames = []string{
+	"Sunday",
+	"Monday",
+	"Tuesday",
+	"Wednesday",
+	"Thursday",
+	"Friday",
+	"Saturday",
+}
+
+var longMonthNames = []string{
+	"January",
+	"February",
+	"March",
+	"April",
+	"May",
+	"June",
+	"July",
+	"August",
+	"September",
+	"October",
+	"November",
+	"December",
+}
+
 var shortMonthNames = []string{
 	"Jan",
 	"Feb",
@@ -81,7 +107,9 @@ func (fmtMonthNameShort) format(dst []byte, t DateTime, prec uint8) []byte {
 }
 
 func (fmtMonthNameShort) parse(tp *timeparts, b string) (out string, ok bool) {
-	tp.month, out, ok = lookup(shortMonthNames, b)
+	var month int
+	month, out, ok = lookup(shortMonthNames, b)
+	tp.month = month + 1
 	return
 }
 
@@ -128,9 +156,19 @@ func (fmtMonthDaySuffix) format(dst []byte, t DateTime, prec uint8) []byte {
 	}
 }
 
-func (d fmtMonthDaySuffix) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (d fmtMonthDaySuffix) parse(tp *timeparts, b string) (string, bool) {
+	day, out, ok := getnum(b, false)
+	if !ok {
+		return "", false
+	}
+	tp.day = day
+	if len(out) >= 2 {
+		switch suffix := out[:2]; {
+		case match(suffix, "st"), match(suffix, "nd"), match(suffix, "rd"), match(suffix, "th"):
+			out = out[2:]
+		}
+	}
+	return out, true
 }
 
 type fmtDay struct {
@@ -169,9 +207,20 @@ func (fmtMicroseconds) format(dst []byte, t DateTime, prec uint8) []byte {
 	return appendNsec(dst, t.Time.Nanosecond(), 6)
 }
 
-func (f fmtMicroseconds) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (f fmtMicroseconds) parse(tp *timeparts, b string) (string, bool) {
+	n := 0
+	for ; n < 6 && isDigit(b, n); n++ {
+	}
+	if n == 0 {
+		return "", false
+	}
+	usec, out, ok := getnuml(b, n)
+	for i := n; i < 9; i++ {
+		usec *= 10
+	}
+	tp.nsec = usec
+	tp.prec = 6
+	return out, ok
 }
 
 type fmtHour24 struct {
@@ -253,9 +302,17 @@ type fmtZeroYearDay struct{}
 func (fmtZeroYearDay) format(dst []byte, t DateTime, prec uint8) []byte {
 	return appendInt(dst, t.Date.Yearday(), 3)
 }
-func (j fmtZeroYearDay) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (j fmtZeroYearDay) parse(tp *timeparts, b string) (string, bool) {
+	n := 0
+	for ; n < 3 && isDigit(b, n); n++ {
+	}
+	if n == 0 {
+		return "", false
+	}
+	var out string
+	var ok bool
+	tp.yday, out, ok = getnuml(b, n)
+	return out, ok
 }
 
 type fmtMonthName struct{}
@@ -264,9 +321,10 @@ func (fmtMonthName) format(dst []byte, t DateTime, prec uint8) []byte {
 	return append(dst, time.Month(t.Date.Month()).String()...)
 }
 
-func (m fmtMonthName) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (m fmtMonthName) parse(tp *timeparts, b string) (string, bool) {
+	month, out, ok := lookup(longMonthNames, b)
+	tp.month = month + 1
+	return out, ok
 }
 
 type fmtAMorPM struct{}
@@ -278,9 +336,19 @@ func (fmtAMorPM) format(dst []byte, t DateTime, prec uint8) []byte {
 	return append(dst, "PM"...)
 }
 
-func (p fmtAMorPM) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (p fmtAMorPM) parse(tp *timeparts, b string) (string, bool) {
+	if len(b) < 2 {
+		return "", false
+	}
+	switch {
+	case match(b[:2], "AM"):
+		tp.amset = true
+	case match(b[:2], "PM"):
+		tp.pmset = true
+	default:
+		return "", false
+	}
+	return b[2:], true
 }
 
 type fmtFullTime12 struct{}
@@ -296,9 +364,15 @@ func (fmtFullTime12) format(dst []byte, t DateTime, prec uint8) []byte {
 	return dst
 }
 
-func (r fmtFullTime12) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (r fmtFullTime12) parse(tp *timeparts, b string) (string, bool) {
+	out, ok := parseClock(tp, b, fmtHour12{false})
+	if !ok {
+		return "", false
+	}
+	for len(out) > 0 && isSpace(out[0]) {
+		out = out[1:]
+	}
+	return (fmtAMorPM{}).parse(tp, out)
 }
 
 type fmtSecond struct {
@@ -351,9 +425,8 @@ func (fmtFullTime24) format(dst []byte, t DateTime, prec uint8) []byte {
 	return dst
 }
 
-func (t2 fmtFullTime24) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (t2 fmtFullTime24) parse(tp *timeparts, b string) (string, bool) {
+	return parseClock(tp, b, fmtHour24{false})
 }
 
 type fmtWeek0 struct{}
@@ -366,9 +439,9 @@ func (fmtWeek0) format(dst []byte, t DateTime, prec uint8) []byte {
 	return appendInt(dst, week, 2)
 }
 
-func (u fmtWeek0) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (u fmtWeek0) parse(tp *timeparts, b string) (string, bool) {
+	// week based dates cannot be parsed
+	return "", false
 }
 
 type fmtWeek1 struct{}
@@ -381,9 +454,9 @@ func (fmtWeek1) format(dst []byte, t DateTime, prec uint8) []byte {
 	return appendInt(dst, week, 2)
 }
 
-func (u fmtWeek1) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (u fmtWeek1) parse(tp *timeparts, b string) (string, bool) {
+	// week based dates cannot be parsed
+	return "", false
 }
 
 type fmtWeek2 struct{}
@@ -393,9 +466,9 @@ func (fmtWeek2) format(dst []byte, t DateTime, prec uint8) []byte {
 	return appendInt(dst, week, 2)
 }
 
-func (v fmtWeek2) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (v fmtWeek2) parse(tp *timeparts, b string) (string, bool) {
+	// week based dates cannot be parsed
+	return "", false
 }
 
 type fmtWeek3 struct{}
@@ -405,9 +478,9 @@ func (fmtWeek3) format(dst []byte, t DateTime, prec uint8) []byte {
 	return appendInt(dst, week, 2)
 }
 
-func (v fmtWeek3) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (v fmtWeek3) parse(tp *timeparts, b string) (string, bool) {
+	// week based dates cannot be parsed
+	return "", false
 }
 
 type fmtWeekdayName struct{}
@@ -415,9 +488,9 @@ type fmtWeekdayName struct{}
 func (fmtWeekdayName) format(dst []byte, t DateTime, prec uint8) []byte {
 	return append(dst, t.Date.Weekday().String()...)
 }
-func (w fmtWeekdayName) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (w fmtWeekdayName) parse(tp *timeparts, b string) (string, bool) {
+	_, out, ok := lookup(longDayNames, b)
+	return out, ok
 }
 
 type fmtWeekday struct{}
@@ -425,9 +498,11 @@ type fmtWeekday struct{}
 func (fmtWeekday) format(dst []byte, t DateTime, prec uint8) []byte {
 	return appendInt(dst, int(t.Date.Weekday()), 0)
 }
-func (w fmtWeekday) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (w fmtWeekday) parse(tp *timeparts, b string) (string, bool) {
+	if !isDigit(b, 0) || b[0] > '6' {
+		return "", false
+	}
+	return b[1:], true
 }
 
 type fmtYearForWeek2 struct{}
@@ -436,9 +511,9 @@ func (fmtYearForWeek2) format(dst []byte, t DateTime, prec uint8) []byte {
 	year, _ := t.Date.SundayWeek()
 	return appendInt(dst, year, 4)
 }
-func (x fmtYearForWeek2) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (x fmtYearForWeek2) parse(tp *timeparts, b string) (string, bool) {
+	// week based dates cannot be parsed
+	return "", false
 }
 
 type fmtYearForWeek3 struct{}
@@ -447,9 +522,9 @@ func (fmtYearForWeek3) format(dst []byte, t DateTime, prec uint8) []byte {
 	year, _ := t.Date.ISOWeek()
 	return appendInt(dst, year, 4)
 }
-func (x fmtYearForWeek3) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (x fmtYearForWeek3) parse(tp *timeparts, b string) (string, bool) {
+	// week based dates cannot be parsed
+	return "", false
 }
 
 type fmtYearLong struct{}
@@ -503,9 +578,11 @@ type fmtVerbatim struct {
 	s string
 }
 
-func (v *fmtVerbatim) parse(t *timeparts, bytes string) (string, bool) {
-	//TODO implement me
-	panic("implement me")
+func (v *fmtVerbatim) parse(tp *timeparts, b string) (string, bool) {
+	if !strings.HasPrefix(b, v.s) {
+		return "", false
+	}
+	return b[len(v.s):], true
 }
 
 func (v *fmtVerbatim) format(dst []byte, t DateTime, prec uint8) []byte {
@@ -555,6 +632,18 @@ func (s fmtTimeSeparator) parse(_ *timeparts, b string) (string, bool) {
 	return "", false
 }
 
+// parseClock parses a hh:mm:ss time of day, as used by the %r and %T specifiers.
+func parseClock(tp *timeparts, b string, hour parser) (string, bool) {
+	parts := []parser{hour, fmtSeparator(':'), fmtMin{false}, fmtSeparator(':'), fmtSecond{false, false}}
+	var ok bool
+	for _, p := range parts {
+		if b, ok = p.parse(tp, b); !ok {
+			return "", false
+		}
+	}
+	return b, true
+}
+
 func isSpace(b byte) bool {
 	switch b {
 	case ' ', '\t', '\n', '\v', '\r':
@@ -93,6 +93,24 @@ func (f *Strftime) Pattern() string {
 	return f.pattern
 }
 
+// Parts reports whether the pattern contains date specifiers, time
+// specifiers, and fractional seconds. This is used by MySQL to decide
+// the return type of STR_TO_DATE.
+func (f *Strftime) Parts() (date, time, frac bool) {
+	for _, w := range f.compiled {
+		switch w.(type) {
+		case fmtMicroseconds:
+			time, frac = true, true
+		case fmtHour24, fmtHour12, fmtMin, fmtSecond, fmtAMorPM, fmtFullTime12, fmtFullTime24:
+			time = true
+		case *fmtVerbatim, fmtSeparator, fmtTimeSeparator:
+		default:
+			date = true
+		}
+	}
+	return
+}
+
 func (f *Strftime) Format(dt DateTime, prec uint8) []byte {
 	return f.format(make([]byte, 0, len(f.pattern)+10), dt, prec)
 }
@@ -142,3 +160,34 @@ func (f *Strftime) Parse(s string, prec int) (DateTime, int, bool) {
 	t, s, l, ok := f.parse(s, prec)
 	return t, l, ok && len(s) == 0
 }
+
+// ParseLenient is like Parse, but the zero-padded numeric specifiers also
+// accept values without padding, like MySQL's STR_TO_DATE does.
+func (f *Strftime) ParseLenient(s string, prec int) (DateTime, int, bool) {
+	lenient := &Strftime{pattern: f.pattern, compiled: make([]Spec, 0, len(f.compiled))}
+	for _, spec := range f.compiled {
+		switch spec := spec.(type) {
+		case fmtDay:
+			spec.zero = false
+			lenient.compiled = append(lenient.compiled, spec)
+		case fmtMonth:
+			spec.zero = false
+			lenient.compiled = append(lenient.compiled, spec)
+		case fmtHour12:
+			spec.zero = false
+			lenient.compiled = append(lenient.compiled, spec)
+		case fmtHour24:
+			spec.zero = false
+			lenient.compiled = append(lenient.compiled, spec)
+		case fmtMin:
+			spec.zero = false
+			lenient.compiled = append(lenient.compiled, spec)
+		case fmtSecond:
+			spec.zero = false
+			lenient.compiled = append(lenient.compiled, spec)
+		default:
+			lenient.compiled = append(lenient.compiled, spec)
+		}
+	}
+	return lenient.Parse(s, prec)
+}
@@ -105,6 +105,11 @@ func (jp *Path) String() string {
 	return b.String()
 }
 
+// IsRoot returns whether the path points to the whole document, i.e. it is "$".
+func (jp *Path) IsRoot() bool {
+	return jp.kind == jpDocumentRoot && jp.next == nil
+}
+
 func (jp *Path) ContainsWildcards() bool {
 	for jp != nil {
 		switch jp.kind {
@@ -218,20 +223,20 @@ func (jp *Path) Match(doc *Value, wrap bool, match func(value *Value)) {
 	m.value(jp, doc)
 }
 
-func (jp *Path) transform(v *Value, t func(pp *Path, vv *Value)) {
+func (jp *Path) transform(v *Value, set func(*Value), t func(pp *Path, vv *Value, set func(*Value))) {
 	if v == nil {
 		return
 	}
 	if jp.next == nil {
-		t(jp, v)
+		t(jp, v, set)
 		return
 	}
 	switch jp.kind {
 	case jpDocumentRoot:
-		jp.next.transform(v, t)
+		jp.next.transform(v, set, t)
 	case jpMember:
 		if obj, ok := v.Object(); ok {
-			jp.next.transform(obj.Get(jp.name), t)
+			jp.next.transform(obj.Get(jp.name), func(nv *Value) { obj.Set(jp.name, nv, Set) }, t)
 		}
 	case jpArrayLocation:
 		if ary, ok := v.Array(); ok {
@@ -240,7 +245,7 @@ func (jp *Path) transform(v *Value, t func(pp *Path, vv *Value)) {
 				panic("range in transformation path expression")
 			}
 			if from >= 0 && from < len(ary) {
-				jp.next.transform(ary[from], t)
+				jp.next.transform(ary[from], func(nv *Value) { v.SetArrayItem(from, nv, Set) }, t)
 			}
 		} else if jp.offset0 == 0 || jp.offset0 == -1 {
 			/*
@@ -248,7 +253,7 @@ func (jp *Path) transform(v *Value, t func(pp *Path, vv *Value)) {
 				the result of the evaluation is the same as if the value had been
 				wrapped in a single-element array:
 			*/
-			jp.next.transform(v, t)
+			jp.next.transform(v, set, t)
 		}
 	case jpMemberAny, jpArrayLocationAny, jpAny:
 		panic("wildcard in transformation path expression")
@@ -264,13 +269,21 @@ const (
 	Remove
 )
 
-func ApplyTransform(t Transformation, doc *Value, paths []*Path, values []*Value) error {
+// ApplyTransform applies the transformation to all the given paths of doc, in order,
+// and returns the resulting document. The document is modified in place, but the
+// returned value must be used instead of doc, as the transformation may replace
+// the root of the document.
+func ApplyTransform(t Transformation, doc *Value, paths []*Path, values []*Value) (*Value, error) {
 	if t != Remove && len(paths) != len(values) {
 		panic("missing Values for transformation")
 	}
 	for i, p := range paths {
-		transform := func(pp *Path, vv *Value) {
+		transform := func(pp *Path, vv *Value, set func(*Value)) {
 			switch pp.kind {
+			case jpDocumentRoot:
+				if t == Set || t == Replace {
+					set(values[i])
+				}
 			case jpArrayLocation:
 				if ary, ok := vv.Array(); ok {
 					from, to := pp.arrayOffsets(ary)
@@ -282,6 +295,17 @@ func ApplyTransform(t Transformation, doc *Value, paths []*Path, values []*Value
 					} else {
 						vv.SetArrayItem(from, values[i], t)
 					}
+				} else if t != Remove {
+					// a value that is not an array behaves as if it had been wrapped
+					// in a single-element array, which is materialized when appending to it
+					from, to := pp.arrayOffsets([]*Value{vv})
+					switch {
+					case from != to || from < 0:
+					case from == 0 && (t == Set || t == Replace):
+						set(values[i])
+					case from > 0 && (t == Set || t == Insert):
+						set(NewArray([]*Value{vv, values[i]}))
+					}
 				}
 			case jpMember:
 				if obj, ok := vv.Object(); ok {
@@ -293,9 +317,9 @@ func ApplyTransform(t Transformation, doc *Value, paths []*Path, values []*Value
 				}
 			}
 		}
-		p.transform(doc, transform)
+		p.transform(doc, func(nv *Value) { doc = nv }, transform)
 	}
-	return nil
+	return doc, nil
 }
 
 func MatchPath(rawJSON, rawPath []byte, match func(value *Value)) error {
@@ -172,6 +172,41 @@ func TestTransformations(t *testing.T) {
 			Paths:    []string{`$[2]`, `$[1].b[1]`, `$[1].b[1]`},
 			Expected: `["a", {"b": [true]}]`,
 		},
+		{
+			T:        Set,
+			Document: Document1,
+			Paths:    []string{`$[2][5]`, `$[1].c`},
+			Values:   []string{"30", "null"},
+			Expected: `["a", {"b": [true, false], "c": null}, [10, 20, 30]]`,
+		},
+		{
+			T:        Replace,
+			Document: Document1,
+			Paths:    []string{`$[2][5]`, `$[1].c`},
+			Values:   []string{"30", "null"},
+			Expected: Document1,
+		},
+		{
+			T:        Set,
+			Document: Document1,
+			Paths:    []string{`$[0][0]`, `$[1][1]`, `$[1][0].b[1][2]`},
+			Values:   []string{`"x"`, "1", "2"},
+			Expected: `["x", [{"b": [true, [false, 2]]}, 1], [10, 20]]`,
+		},
+		{
+			T:        Insert,
+			Document: `false`,
+			Paths:    []string{`$[0]`, `$[1]`},
+			Values:   []string{"1", "2"},
+			Expected: `[false, 2]`,
+		},
+		{
+			T:        Replace,
+			Document: `false`,
+			Paths:    []string{`$`, `$[1]`},
+			Values:   []string{"1", "2"},
+			Expected: `1`,
+		},
 	}
 
 	for _, tc := range cases {
@@ -187,7 +222,7 @@ func TestTransformations(t *testing.T) {
 			values = append(values, json(t, v))
 		}
 
-		err := ApplyTransform(tc.T, doc, paths, values)
+		doc, err := ApplyTransform(tc.T, doc, paths, values)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -71,18 +71,18 @@ func (v *Value) SetArrayItem(idx int, value *Value, t Transformation) {
 	if v == nil || v.t != TypeArray || idx < 0 {
 		return
 	}
-	switch t {
-	case Insert:
-		if idx < len(v.a) {
-			return
-		}
-		fallthrough
-	case Set:
-		for idx >= len(v.a) {
-			v.a = append(v.a, ValueNull)
+	if value == nil {
+		value = ValueNull
+	}
+	if idx >= len(v.a) {
+		// MySQL quirk: setting a position past the end of the array does not
+		// pad it with nulls; the new value is appended to the array instead.
+		if t == Set || t == Insert {
+			v.a = append(v.a, value)
 		}
+		return
 	}
-	if idx < len(v.a) {
+	if t == Set || t == Replace {
 		v.a[idx] = value
 	}
 }
@@ -96,3 +96,31 @@ func (v *Value) DelArrayItem(n int) {
 	}
 	v.a = append(v.a[:n], v.a[n+1:]...)
 }
+
+// Clone returns a deep copy of v that can be modified without affecting v.
+func (v *Value) Clone() *Value {
+	if v == nil {
+		return nil
+	}
+	switch v.t {
+	case TypeObject:
+		kvs := make([]kv, 0, len(v.o.kvs))
+		for _, kv := range v.o.kvs {
+			kv.v = kv.v.Clone()
+			kvs = append(kvs, kv)
+		}
+		return &Value{o: Object{kvs: kvs}, t: TypeObject}
+	case TypeArray:
+		a := make([]*Value, 0, len(v.a))
+		for _, item := range v.a {
+			a = append(a, item.Clone())
+		}
+		return &Value{a: a, t: TypeArray}
+	case TypeNull, TypeBoolean:
+		// null, true and false are singletons and are compared by identity
+		return v
+	default:
+		clone := *v
+		return &clone
+	}
+}
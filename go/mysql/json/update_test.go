@@ -106,3 +106,18 @@ func TestValueDelSet(t *testing.T) {
 		t.Fatalf("unexpected number of items left in the array; got %d; want %d", len(a), 2)
 	}
 }
+
+func TestValueClone(t *testing.T) {
+	v := MustParse(`{"a": [1, {"b": "c"}], "d": null, "e": true}`)
+	c := v.Clone()
+
+	o, _ := v.Object()
+	o.Del("d")
+	a, _ := o.Get("a").Array()
+	ab, _ := a[1].Object()
+	ab.Set("b", MustParse(`"x"`), Set)
+
+	if got, want := string(c.MarshalTo(nil)), `{"a": [1, {"b": "c"}], "d": null, "e": true}`; got != want {
+		t.Fatalf("clone was modified by changes to the original value\nwant: %s\ngot:  %s", want, got)
+	}
+}
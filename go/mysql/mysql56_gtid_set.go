@@ -194,6 +194,30 @@ func (set Mysql56GTIDSet) Last() string {
 	return buf.String()
 }
 
+// GTIDs returns every individual GTID in the set, sorted by SID and sequence number.
+func (set Mysql56GTIDSet) GTIDs() []Mysql56GTID {
+	var gtids []Mysql56GTID
+	for _, sid := range set.SIDs() {
+		for _, iv := range set[sid] {
+			for seq := iv.start; seq <= iv.end; seq++ {
+				gtids = append(gtids, Mysql56GTID{Server: sid, Sequence: seq})
+			}
+		}
+	}
+	return gtids
+}
+
+// Count returns the number of individual GTIDs in the set, without expanding its intervals.
+func (set Mysql56GTIDSet) Count() int64 {
+	var count int64
+	for _, intervals := range set {
+		for _, iv := range intervals {
+			count += iv.end - iv.start + 1
+		}
+	}
+	return count
+}
+
 // Flavor implements GTIDSet.
 func (Mysql56GTIDSet) Flavor() string { return Mysql56FlavorID }
 
@@ -40,7 +40,7 @@ import (
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) WriteComQuery(query string) error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(len(query) + 1)
 	data[pos] = ComQuery
@@ -529,8 +529,86 @@ func (c *Conn) readComQueryResponse() (int, *PacketOK, error) {
 // Server side methods.
 //
 
-func (c *Conn) parseComQuery(data []byte) string {
-	return string(data[1:])
+func (c *Conn) parseComQuery(data []byte) (string, error) {
+	c.queryAttributes = nil
+	if c.Capabilities&CapabilityClientQueryAttributes == 0 {
+		return string(data[1:]), nil
+	}
+	attributes, pos, err := c.parseQueryAttributes(data, 1)
+	if err != nil {
+		return "", err
+	}
+	c.queryAttributes = attributes
+	return string(data[pos:]), nil
+}
+
+// parseQueryAttributes parses the query attributes a client that
+// negotiated CLIENT_QUERY_ATTRIBUTES sends in front of the text of a
+// COM_QUERY. It returns the position of the query text.
+func (c *Conn) parseQueryAttributes(data []byte, pos int) (map[string]sqltypes.Value, int, error) {
+	count, pos, ok := readLenEncInt(data, pos)
+	if !ok {
+		return nil, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter count failed")
+	}
+	// The parameter set count is always 1.
+	_, pos, ok = readLenEncInt(data, pos)
+	if !ok {
+		return nil, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter set count failed")
+	}
+	if count == 0 {
+		return nil, pos, nil
+	}
+	if count > uint64(len(data)) {
+		return nil, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "invalid parameter count: %v", count)
+	}
+
+	bitMap, pos, ok := readBytes(data, pos, (int(count)+7)/8)
+	if !ok {
+		return nil, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading NULL-bitmap failed")
+	}
+	newParamsBoundFlag, pos, ok := readByte(data, pos)
+	if !ok || newParamsBoundFlag != 0x01 {
+		return nil, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter types failed")
+	}
+
+	names := make([]string, count)
+	types := make([]querypb.Type, count)
+	for i := range names {
+		var err error
+		names[i], types[i], pos, err = readNamedParameterType(data, pos)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	attributes := make(map[string]sqltypes.Value, count)
+	for i, name := range names {
+		var val sqltypes.Value
+		if (bitMap[i/8] & (1 << uint(i%8))) > 0 {
+			val, pos, ok = c.parseStmtArgs(nil, sqltypes.Null, pos)
+		} else {
+			val, pos, ok = c.parseStmtArgs(data, types[i], pos)
+		}
+		if !ok {
+			return nil, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "decoding query attribute value failed: %v", types[i])
+		}
+		attributes[name] = val
+	}
+	return attributes, pos, nil
+}
+
+// readNamedParameterType reads the type, flags and name of a parameter
+// sent with CLIENT_QUERY_ATTRIBUTES.
+func readNamedParameterType(data []byte, pos int) (string, querypb.Type, int, error) {
+	valType, pos, err := readParameterType(data, pos)
+	if err != nil {
+		return "", 0, 0, err
+	}
+	name, pos, ok := readLenEncString(data, pos)
+	if !ok {
+		return "", 0, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter name failed")
+	}
+	return name, valType, pos, nil
 }
 
 func (c *Conn) parseComSetOption(data []byte) (uint16, bool) {
@@ -572,36 +650,59 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "iteration count is not equal to 1")
 	}
 
-	if prepare.ParamsCount > 0 {
-		bitMap, pos, ok = readBytes(payload, pos, (int(prepare.ParamsCount)+7)/8)
+	// With CLIENT_QUERY_ATTRIBUTES, the parameter count is sent, and the
+	// query attributes are appended as named parameters after the ones of
+	// the statement.
+	c.queryAttributes = nil
+	queryAttributes := c.Capabilities&CapabilityClientQueryAttributes != 0
+	paramsCount := int(prepare.ParamsCount)
+	if queryAttributes && (paramsCount > 0 || cursorType&cursorParameterCountAvailable != 0) {
+		count, newPos, ok := readLenEncInt(payload, pos)
+		if !ok {
+			return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter count failed")
+		}
+		if count < uint64(paramsCount) || count > uint64(len(payload)) {
+			return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "invalid parameter count: %v", count)
+		}
+		pos = newPos
+		paramsCount = int(count)
+	}
+
+	if paramsCount > 0 {
+		bitMap, pos, ok = readBytes(payload, pos, (paramsCount+7)/8)
 		if !ok {
 			return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading NULL-bitmap failed")
 		}
 	}
 
+	var attributeNames []string
+	var attributeTypes []querypb.Type
 	newParamsBoundFlag, pos, ok := readByte(payload, pos)
 	if ok && newParamsBoundFlag == 0x01 {
-		var mysqlType, flags byte
-		for i := uint16(0); i < prepare.ParamsCount; i++ {
-			mysqlType, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter type failed")
-			}
-
-			flags, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter flags failed")
+		for i := 0; i < paramsCount; i++ {
+			var name string
+			var valType querypb.Type
+			var err error
+			if queryAttributes {
+				name, valType, pos, err = readNamedParameterType(payload, pos)
+			} else {
+				valType, pos, err = readParameterType(payload, pos)
 			}
-
-			// convert MySQL type to internal type.
-			valType, err := sqltypes.MySQLToType(int64(mysqlType), int64(flags))
 			if err != nil {
-				return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+				return stmtID, 0, err
 			}
 
-			prepare.ParamsType[i] = int32(valType)
+			if i < int(prepare.ParamsCount) {
+				prepare.ParamsType[i] = int32(valType)
+			} else {
+				attributeNames = append(attributeNames, name)
+				attributeTypes = append(attributeTypes, valType)
+			}
 		}
 	}
+	if len(attributeNames) != paramsCount-int(prepare.ParamsCount) {
+		return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "query attributes sent without their types")
+	}
 
 	for i := 0; i < len(prepare.ParamsType); i++ {
 		var val sqltypes.Value
@@ -624,9 +725,46 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		prepare.BindVars[parameterID] = sqltypes.ValueBindVariable(val)
 	}
 
+	if len(attributeNames) > 0 {
+		c.queryAttributes = make(map[string]sqltypes.Value, len(attributeNames))
+	}
+	for i, name := range attributeNames {
+		var val sqltypes.Value
+		bit := int(prepare.ParamsCount) + i
+		if (bitMap[bit/8] & (1 << uint(bit%8))) > 0 {
+			val, pos, ok = c.parseStmtArgs(nil, sqltypes.Null, pos)
+		} else {
+			val, pos, ok = c.parseStmtArgs(payload, attributeTypes[i], pos)
+		}
+		if !ok {
+			return stmtID, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "decoding query attribute value failed: %v", attributeTypes[i])
+		}
+		c.queryAttributes[name] = val
+	}
+
 	return stmtID, cursorType, nil
 }
 
+// readParameterType reads the type and flags of a parameter of
+// COM_STMT_EXECUTE.
+func readParameterType(data []byte, pos int) (querypb.Type, int, error) {
+	mysqlType, pos, ok := readByte(data, pos)
+	if !ok {
+		return 0, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter type failed")
+	}
+	flags, pos, ok := readByte(data, pos)
+	if !ok {
+		return 0, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "reading parameter flags failed")
+	}
+
+	// convert MySQL type to internal type.
+	valType, err := sqltypes.MySQLToType(int64(mysqlType), int64(flags))
+	if err != nil {
+		return 0, 0, NewSQLError(CRMalformedPacket, SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+	}
+	return valType, pos, nil
+}
+
 func (c *Conn) parseStmtArgs(data []byte, typ querypb.Type, pos int) (sqltypes.Value, int, bool) {
 	switch typ {
 	case sqltypes.Null:
@@ -325,6 +325,124 @@ func TestComStmtExecuteUpdStmt(t *testing.T) {
 	assert.EqualValues(t, querypb.Type_CHAR, prepData.ParamsType[28], "got: %s", querypb.Type(prepData.ParamsType[28]))
 }
 
+func TestComQueryAttributes(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	// Without CLIENT_QUERY_ATTRIBUTES, the whole payload is the query.
+	query, err := sConn.parseComQuery(append([]byte{ComQuery}, "select 1"...))
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Nil(t, sConn.QueryAttributes())
+
+	sConn.Capabilities |= CapabilityClientQueryAttributes
+
+	// No attributes.
+	query, err = sConn.parseComQuery(append([]byte{ComQuery, 0x00, 0x01}, "select 1"...))
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Nil(t, sConn.QueryAttributes())
+
+	data := []byte{
+		ComQuery,
+		// parameter count, parameter set count
+		0x03, 0x01,
+		// NULL-bitmap, the third attribute is NULL
+		0x04,
+		// new params bound flag
+		0x01,
+		// VAR_STRING 'workload_name'
+		0xfd, 0x00, 0x0d, 'w', 'o', 'r', 'k', 'l', 'o', 'a', 'd', '_', 'n', 'a', 'm', 'e',
+		// LONGLONG 'n'
+		0x08, 0x00, 0x01, 'n',
+		// NULL 'empty'
+		0x06, 0x00, 0x05, 'e', 'm', 'p', 't', 'y',
+		// values
+		0x04, 'o', 'l', 'a', 'p',
+		0x2a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	}
+	query, err = sConn.parseComQuery(append(data, "select 1"...))
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Equal(t, map[string]sqltypes.Value{
+		"workload_name": sqltypes.NewVarBinary("olap"),
+		"n":             sqltypes.NewInt64(42),
+		"empty":         sqltypes.NULL,
+	}, sConn.QueryAttributes())
+
+	// Truncated packet.
+	_, err = sConn.parseComQuery(data[:len(data)-4])
+	assert.ErrorContains(t, err, "decoding query attribute value failed")
+}
+
+func TestComStmtExecuteQueryAttributes(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+	sConn.Capabilities |= CapabilityClientQueryAttributes
+
+	prepareDataMap := map[uint32]*PrepareData{
+		1: {
+			StatementID: 1,
+			ParamsCount: 1,
+			ParamsType:  make([]int32, 1),
+			BindVars:    map[string]*querypb.BindVariable{},
+		},
+		2: {
+			StatementID: 2,
+			BindVars:    map[string]*querypb.BindVariable{},
+		},
+	}
+
+	data := []byte{
+		ComStmtExecute,
+		// statement ID, cursor type, iteration count
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00,
+		// parameter count
+		0x02,
+		// NULL-bitmap, new params bound flag
+		0x00, 0x01,
+		// LONGLONG, the parameter of the statement
+		0x08, 0x00, 0x00,
+		// VAR_STRING 'tag'
+		0xfd, 0x00, 0x03, 't', 'a', 'g',
+		// values
+		0x0a, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x01, 'x',
+	}
+	stmtID, _, err := sConn.parseComStmtExecute(prepareDataMap, data)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, stmtID)
+	assert.Equal(t, map[string]*querypb.BindVariable{"v1": sqltypes.Int64BindVariable(10)}, prepareDataMap[1].BindVars)
+	assert.Equal(t, map[string]sqltypes.Value{"tag": sqltypes.NewVarBinary("x")}, sConn.QueryAttributes())
+
+	// A statement without parameters only sends the parameter count
+	// with PARAMETER_COUNT_AVAILABLE.
+	data = []byte{
+		ComStmtExecute,
+		0x02, 0x00, 0x00, 0x00, cursorParameterCountAvailable, 0x01, 0x00, 0x00, 0x00,
+		0x01,
+		0x01, 0x01,
+		0xfd, 0x00, 0x03, 't', 'a', 'g',
+	}
+	stmtID, _, err = sConn.parseComStmtExecute(prepareDataMap, data)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, stmtID)
+	assert.Equal(t, map[string]sqltypes.Value{"tag": sqltypes.NULL}, sConn.QueryAttributes())
+
+	data = []byte{ComStmtExecute, 0x02, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00}
+	_, _, err = sConn.parseComStmtExecute(prepareDataMap, data)
+	require.NoError(t, err)
+	assert.Nil(t, sConn.QueryAttributes())
+}
+
 func TestComStmtClose(t *testing.T) {
 	listener, sConn, cConn := createSocketPair(t)
 	defer func() {
@@ -81,7 +81,7 @@ from information_schema.columns
 where table_schema = database()`
 
 	// fetchColumns are the columns we fetch
-	fetchColumns = "table_name, column_name, data_type, collation_name"
+	fetchColumns = "table_name, column_name, data_type, collation_name, column_key"
 
 	// FetchUpdatedTables queries fetches all information about updated tables
 	FetchUpdatedTables = `select  ` + fetchColumns + `
@@ -203,6 +203,10 @@ type Listener struct {
 	// RequireSecureTransport configures the server to reject connections from insecure clients
 	RequireSecureTransport bool
 
+	// compressionCapabilities are the protocol compression capabilities
+	// advertised to clients. See SetCompressionAlgorithms.
+	compressionCapabilities uint32
+
 	// PreHandleFunc is called for each incoming connection, immediately after
 	// accepting a new connection. By default it's no-op. Useful for custom
 	// connection inspection or TLS termination. The returned connection is
@@ -300,6 +304,18 @@ func NewListenerWithConfig(cfg ListenerConfig) (*Listener, error) {
 	}, nil
 }
 
+// SetCompressionAlgorithms sets the protocol compression algorithms the
+// server accepts, in addition to uncompressed connections. Clients choose
+// which one to use, if any. It must be called before Accept.
+func (l *Listener) SetCompressionAlgorithms(algorithms []string) error {
+	capabilities, err := CompressionCapabilities(algorithms)
+	if err != nil {
+		return err
+	}
+	l.compressionCapabilities = capabilities
+	return nil
+}
+
 // Addr returns the listener address.
 func (l *Listener) Addr() net.Addr {
 	return l.listener.Addr()
@@ -372,7 +388,7 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 	defer connCount.Add(-1)
 
 	// First build and send the server handshake packet.
-	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, l.TLSConfig.Load() != nil)
+	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, l.TLSConfig.Load() != nil, l.compressionCapabilities)
 	if err != nil {
 		if err != io.EOF {
 			log.Errorf("Cannot send HandshakeV10 packet to %s: %v", c, err)
@@ -515,6 +531,13 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 		return
 	}
 
+	// The client switches to the compressed protocol after the OK
+	// packet, if it was negotiated.
+	if err := c.enableCompression(c.zstdCompressionLevel); err != nil {
+		log.Errorf("Cannot enable protocol compression for %s: %v", c, err)
+		return
+	}
+
 	// Record how long we took to establish the connection
 	timings.Record(connectTimingKey, acceptTime)
 
@@ -553,7 +576,7 @@ func (l *Listener) Shutdown() {
 
 // writeHandshakeV10 writes the Initial Handshake Packet, server side.
 // It returns the salt data.
-func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, enableTLS bool) ([]byte, error) {
+func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, enableTLS bool, compression uint32) ([]byte, error) {
 	capabilities := CapabilityClientLongPassword |
 		CapabilityClientFoundRows |
 		CapabilityClientLongFlag |
@@ -566,10 +589,12 @@ func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, en
 		CapabilityClientPluginAuth |
 		CapabilityClientPluginAuthLenencClientData |
 		CapabilityClientDeprecateEOF |
-		CapabilityClientConnAttr
+		CapabilityClientConnAttr |
+		CapabilityClientQueryAttributes
 	if enableTLS {
 		capabilities |= CapabilityClientSSL
 	}
+	capabilities |= int(compression)
 
 	// Grab the default auth method. This can only be either
 	// mysql_native_password or caching_sha2_password. Both
@@ -687,7 +712,7 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 	// later in the protocol. If we re-received the handshake packet
 	// after SSL negotiation, do not overwrite capabilities.
 	if firstTime {
-		c.Capabilities = clientFlags & (CapabilityClientDeprecateEOF | CapabilityClientFoundRows)
+		c.Capabilities = clientFlags & (CapabilityClientDeprecateEOF | CapabilityClientFoundRows | CapabilityClientQueryAttributes)
 	}
 
 	// set connection capability for executing multi statements
@@ -787,9 +812,28 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 
 	// Decode connection attributes send by the client
 	if clientFlags&CapabilityClientConnAttr != 0 {
-		if _, _, err := parseConnAttrs(data, pos); err != nil {
+		if _, end, err := parseConnAttrs(data, pos); err != nil {
 			log.Warningf("Decode connection attributes send by the client: %v", err)
+		} else {
+			pos = end
+		}
+	}
+
+	// Protocol compression. zlib takes precedence if the client
+	// asked for both, as in MySQL.
+	switch {
+	case clientFlags&l.compressionCapabilities&CapabilityClientCompress != 0:
+		c.Capabilities |= CapabilityClientCompress
+	case clientFlags&l.compressionCapabilities&CapabilityClientZstdCompressionAlgorithm != 0:
+		level, _, ok := readByte(data, pos)
+		if !ok {
+			return "", "", nil, vterrors.Errorf(vtrpc.Code_INTERNAL, "parseClientHandshakePacket: can't read zstd compression level")
+		}
+		if !validZstdCompressionLevel(int(level)) {
+			return "", "", nil, vterrors.Errorf(vtrpc.Code_INTERNAL, "parseClientHandshakePacket: invalid zstd compression level: %v", level)
 		}
+		c.Capabilities |= CapabilityClientZstdCompressionAlgorithm
+		c.zstdCompressionLevel = int(level)
 	}
 
 	return username, AuthMethodDescription(authMethod), authResponse, nil
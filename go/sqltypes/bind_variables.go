@@ -45,6 +45,13 @@ func ValueToProto(v Value) *querypb.Value {
 	return &querypb.Value{Type: v.typ, Value: v.val}
 }
 
+// TupleToProto converts a list of values to a single *querypb.Value of type TUPLE.
+// It is used for the elements of a TUPLE bind variable that are tuples themselves,
+// like the ones of a "(a, b) in ::list" condition.
+func TupleToProto(values []Value) *querypb.Value {
+	return &querypb.Value{Type: querypb.Type_TUPLE, Value: encodeTuple(values)}
+}
+
 // ProtoToValue converts a *querypb.Value to a Value.
 func ProtoToValue(v *querypb.Value) Value {
 	return MakeTrusted(v.Type, v.Value)
@@ -346,7 +353,10 @@ func ValidateBindVariable(bv *querypb.BindVariable) error {
 		}
 		for _, val := range bv.Values {
 			if val.Type == querypb.Type_TUPLE {
-				return errors.New("tuple not allowed inside another tuple")
+				if err := validateTuple(val.Value); err != nil {
+					return err
+				}
+				continue
 			}
 			if err := ValidateBindVariable(&querypb.BindVariable{Type: val.Type, Value: val.Value}); err != nil {
 				return err
@@ -360,6 +370,29 @@ func ValidateBindVariable(bv *querypb.BindVariable) error {
 	return err
 }
 
+// validateTuple returns an error if the encoded tuple is empty, or if one of
+// its values is invalid. Tuples can't be nested more than once.
+func validateTuple(encoded []byte) error {
+	if len(encoded) == 0 {
+		return errors.New("empty tuple is not allowed")
+	}
+	var err error
+	decodeErr := MakeTrusted(querypb.Type_TUPLE, encoded).ForEachValue(func(v Value) {
+		if err != nil {
+			return
+		}
+		if v.Type() == querypb.Type_TUPLE {
+			err = errors.New("tuple not allowed inside a tuple value")
+			return
+		}
+		_, err = NewValue(v.Type(), v.Raw())
+	})
+	if decodeErr != nil {
+		return decodeErr
+	}
+	return err
+}
+
 // BindVariableToValue converts a bind var into a Value.
 func BindVariableToValue(bv *querypb.BindVariable) (Value, error) {
 	if bv.Type == querypb.Type_TUPLE {
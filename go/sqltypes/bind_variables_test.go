@@ -551,7 +551,30 @@ func TestValidateBindVariable(t *testing.T) {
 				Type: querypb.Type_TUPLE,
 			}},
 		},
-		err: "tuple not allowed inside another tuple",
+		err: "empty tuple is not allowed",
+	}, {
+		in: &querypb.BindVariable{
+			Type:   querypb.Type_TUPLE,
+			Values: []*querypb.Value{TupleToProto([]Value{NewInt64(1), NewVarChar("a")})},
+		},
+	}, {
+		in: &querypb.BindVariable{
+			Type:   querypb.Type_TUPLE,
+			Values: []*querypb.Value{TupleToProto([]Value{NewInt64(1), MakeTrusted(querypb.Type_INT64, []byte("a"))})},
+		},
+		err: "invalid syntax",
+	}, {
+		in: &querypb.BindVariable{
+			Type:   querypb.Type_TUPLE,
+			Values: []*querypb.Value{TupleToProto([]Value{ProtoToValue(TupleToProto([]Value{NewInt64(1)}))})},
+		},
+		err: "tuple not allowed inside a tuple value",
+	}, {
+		in: &querypb.BindVariable{
+			Type:   querypb.Type_TUPLE,
+			Values: []*querypb.Value{{Type: querypb.Type_TUPLE, Value: []byte{byte(querypb.Type_INT64 & 0x7f)}}},
+		},
+		err: "corrupted tuple value",
 	}}
 	for _, tcase := range testcases {
 		err := ValidateBindVariable(tcase.in)
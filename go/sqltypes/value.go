@@ -19,6 +19,7 @@ package sqltypes
 
 import (
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -47,6 +48,8 @@ var (
 
 	// ErrIncompatibleTypeCast indicates a casting problem
 	ErrIncompatibleTypeCast = errors.New("Cannot convert value to desired type")
+
+	errBadTuple = errors.New("corrupted tuple value")
 )
 
 type (
@@ -384,6 +387,17 @@ func (v Value) EncodeSQL(b BinWriter) {
 	switch {
 	case v.typ == Null:
 		b.Write(NullBytes)
+	case v.typ == Tuple:
+		b.Write([]byte{'('})
+		i := 0
+		_ = v.ForEachValue(func(bv Value) {
+			if i > 0 {
+				b.Write([]byte(", "))
+			}
+			bv.EncodeSQL(b)
+			i++
+		})
+		b.Write([]byte{')'})
 	case v.IsQuoted():
 		encodeBytesSQL(v.val, b)
 	case v.typ == Bit:
@@ -399,6 +413,17 @@ func (v Value) EncodeSQLStringBuilder(b *strings.Builder) {
 	switch {
 	case v.typ == Null:
 		b.Write(NullBytes)
+	case v.typ == Tuple:
+		b.Write([]byte{'('})
+		i := 0
+		_ = v.ForEachValue(func(bv Value) {
+			if i > 0 {
+				b.Write([]byte(", "))
+			}
+			bv.EncodeSQLStringBuilder(b)
+			i++
+		})
+		b.Write([]byte{')'})
 	case v.IsQuoted():
 		encodeBytesSQLStringBuilder(v.val, b)
 	case v.typ == Bit:
@@ -414,6 +439,17 @@ func (v Value) EncodeSQLBytes2(b *bytes2.Buffer) {
 	switch {
 	case v.typ == Null:
 		b.Write(NullBytes)
+	case v.typ == Tuple:
+		b.Write([]byte{'('})
+		i := 0
+		_ = v.ForEachValue(func(bv Value) {
+			if i > 0 {
+				b.Write([]byte(", "))
+			}
+			bv.EncodeSQLBytes2(b)
+			i++
+		})
+		b.Write([]byte{')'})
 	case v.IsQuoted():
 		encodeBytesSQLBytes2(v.val, b)
 	case v.typ == Bit:
@@ -428,6 +464,17 @@ func (v Value) EncodeASCII(b BinWriter) {
 	switch {
 	case v.typ == Null:
 		b.Write(NullBytes)
+	case v.typ == Tuple:
+		b.Write([]byte{'('})
+		i := 0
+		_ = v.ForEachValue(func(bv Value) {
+			if i > 0 {
+				b.Write([]byte(", "))
+			}
+			bv.EncodeASCII(b)
+			i++
+		})
+		b.Write([]byte{')'})
 	case v.IsQuoted() || v.typ == Bit:
 		encodeBytesASCII(v.val, b)
 	default:
@@ -435,6 +482,44 @@ func (v Value) EncodeASCII(b BinWriter) {
 	}
 }
 
+// ForEachValue calls the function for every value of a TUPLE value, as
+// encoded by TupleToProto.
+func (v Value) ForEachValue(fn func(Value)) error {
+	if v.typ != Tuple {
+		return fmt.Errorf("value of type %v is not a tuple", v.typ)
+	}
+	buf := v.val
+	for len(buf) > 0 {
+		typ, n := binary.Uvarint(buf)
+		if n <= 0 {
+			return errBadTuple
+		}
+		buf = buf[n:]
+		size, n := binary.Uvarint(buf)
+		if n <= 0 || uint64(len(buf)-n) < size {
+			return errBadTuple
+		}
+		buf = buf[n:]
+		fn(MakeTrusted(querypb.Type(typ), buf[:size]))
+		buf = buf[size:]
+	}
+	return nil
+}
+
+func encodeTuple(values []Value) []byte {
+	size := 0
+	for _, v := range values {
+		size += len(v.val) + 2*binary.MaxVarintLen64
+	}
+	buf := make([]byte, 0, size)
+	for _, v := range values {
+		buf = binary.AppendUvarint(buf, uint64(v.typ))
+		buf = binary.AppendUvarint(buf, uint64(len(v.val)))
+		buf = append(buf, v.val...)
+	}
+	return buf
+}
+
 // IsNull returns true if Value is null.
 func (v Value) IsNull() bool {
 	return v.typ == Null
@@ -461,6 +461,10 @@ func TestEncode(t *testing.T) {
 		in:       TestValue(Bit, "a"),
 		outSQL:   "b'01100001'",
 		outASCII: "'YQ=='",
+	}, {
+		in:       ProtoToValue(TupleToProto([]Value{TestValue(Int64, "1"), NULL, TestValue(VarChar, "foo")})),
+		outSQL:   "(1, null, 'foo')",
+		outASCII: "(1, null, 'Zm9v')",
 	}}
 	for _, tcase := range testcases {
 		buf := &bytes.Buffer{}
@@ -0,0 +1,5 @@
+create table twopc_account (
+    id bigint,
+    balance bigint,
+    primary key (id)
+) Engine=InnoDB;
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package twopc
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/test/endtoend/cluster"
+	"vitess.io/vitess/go/test/endtoend/utils"
+)
+
+// transfer moves one unit of balance from the account with id 1, which lives
+// in shard -80, to the account with id 6, which lives in shard 80-. It
+// returns whether the distributed transaction was committed.
+func transfer(ctx context.Context) bool {
+	conn, err := mysql.Connect(ctx, &vtParams)
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	for _, query := range []string{
+		"begin",
+		"update twopc_account set balance = balance - 1 where id = 1",
+		"update twopc_account set balance = balance + 1 where id = 6",
+		"commit",
+	} {
+		if _, err := conn.ExecuteFetch(query, 1000, false); err != nil {
+			_, _ = conn.ExecuteFetch("rollback", 1000, false)
+			return false
+		}
+	}
+	return true
+}
+
+// TestAtomicCommitSurvivesReparent runs distributed transactions spanning
+// both shards while their primaries are reparented back and forth, and
+// verifies that every transaction is applied on both shards or on neither.
+func TestAtomicCommitSurvivesReparent(t *testing.T) {
+	defer cluster.PanicHandler(t)
+
+	ctx := context.Background()
+	conn, err := mysql.Connect(ctx, &vtParams)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	utils.Exec(t, conn, "insert into twopc_account(id, balance) values(1, 0), (6, 0)")
+	defer utils.Exec(t, conn, "delete from twopc_account")
+
+	var (
+		wg        sync.WaitGroup
+		committed atomic.Int64
+		stop      = make(chan struct{})
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if transfer(ctx) {
+					committed.Add(1)
+				}
+			}
+		}()
+	}
+
+	// Reparent every shard to its replica and back, while commits are in flight.
+	for _, idx := range []int{1, 0} {
+		for _, shard := range clusterInstance.Keyspaces[0].Shards {
+			time.Sleep(500 * time.Millisecond)
+			err := clusterInstance.VtctldClientProcess.PlannedReparentShard(keyspaceName, shard.Name, shard.Vttablets[idx].Alias)
+			require.NoError(t, err)
+		}
+	}
+	time.Sleep(500 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+	require.NotZero(t, committed.Load(), "no transaction committed")
+
+	// Transactions interrupted by a reparent are resolved by the watchdog.
+	// Once they are, the redo logs are empty and the balances add up.
+	for _, shard := range clusterInstance.Keyspaces[0].Shards {
+		require.Eventually(t, func() bool {
+			qr, err := shard.PrimaryTablet().VttabletProcess.QueryTablet("select count(*) from _vt.redo_state", keyspaceName, false)
+			if err != nil {
+				return false
+			}
+			count, err := qr.Rows[0][0].ToInt64()
+			return err == nil && count == 0
+		}, 30*time.Second, time.Second, "unresolved prepared transactions in shard %s", shard.Name)
+	}
+	utils.AssertMatches(t, conn, "select sum(balance) from twopc_account", `[[DECIMAL(0)]]`)
+	qr := utils.Exec(t, conn, "select balance from twopc_account where id = 6")
+	balance, err := qr.Rows[0][0].ToInt64()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, balance, committed.Load())
+}
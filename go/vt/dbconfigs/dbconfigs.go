@@ -89,6 +89,8 @@ type DBConfigs struct {
 	ConnectTimeoutMilliseconds int           `json:"connectTimeoutMilliseconds,omitempty"`
 	DBName                     string        `json:"dbName,omitempty"`
 	EnableQueryInfo            bool          `json:"enableQueryInfo,omitempty"`
+	Compression                string        `json:"compression,omitempty"`
+	ZstdCompressionLevel       int           `json:"zstdCompressionLevel,omitempty"`
 
 	App          UserConfig `json:"app,omitempty"`
 	Dba          UserConfig `json:"dba,omitempty"`
@@ -145,6 +147,8 @@ func registerBaseFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&GlobalDBConfigs.ServerName, "db_server_name", "", "server name of the DB we are connecting to.")
 	fs.IntVar(&GlobalDBConfigs.ConnectTimeoutMilliseconds, "db_connect_timeout_ms", 0, "connection timeout to mysqld in milliseconds (0 for no timeout)")
 	fs.BoolVar(&GlobalDBConfigs.EnableQueryInfo, "db_conn_query_info", false, "enable parsing and processing of QUERY_OK info fields")
+	fs.StringVar(&GlobalDBConfigs.Compression, "db_compression", "", "Protocol compression algorithm to use with mysqld, if supported by the server. Options: zlib, zstd. Empty disables compression.")
+	fs.IntVar(&GlobalDBConfigs.ZstdCompressionLevel, "db_zstd_compression_level", 0, "zstd compression level to use with mysqld when db_compression is zstd, from 1 to 22 (0 for the default level of 3)")
 }
 
 // The flags will change the global singleton
@@ -350,6 +354,8 @@ func (dbcfgs *DBConfigs) InitWithSocket(defaultSocketFile string) {
 		}
 		cp.ConnectTimeoutMs = uint64(dbcfgs.ConnectTimeoutMilliseconds)
 		cp.EnableQueryInfo = dbcfgs.EnableQueryInfo
+		cp.Compression = dbcfgs.Compression
+		cp.ZstdCompressionLevel = dbcfgs.ZstdCompressionLevel
 
 		cp.Uname = uc.User
 		cp.Pass = uc.Password
@@ -325,6 +325,13 @@ func (fmd *FakeMysqlDaemon) ResetReplicationParameters(ctx context.Context) erro
 	})
 }
 
+// InjectEmptyTransactions is part of the MysqlDaemon interface.
+func (fmd *FakeMysqlDaemon) InjectEmptyTransactions(ctx context.Context, gtidSet string) error {
+	return fmd.ExecuteSuperQueryList(ctx, []string{
+		"FAKE INJECT EMPTY TRANSACTIONS " + gtidSet,
+	})
+}
+
 // GetBinlogInformation is part of the MysqlDaemon interface.
 func (fmd *FakeMysqlDaemon) GetBinlogInformation(ctx context.Context) (binlogFormat string, logEnabled bool, logReplicaUpdate bool, binlogRowImage string, err error) {
 	return "ROW", true, true, "FULL", fmd.ExecuteSuperQueryList(ctx, []string{
@@ -80,6 +80,7 @@ type MysqlDaemon interface {
 	SetReplicationPosition(ctx context.Context, pos mysql.Position) error
 	SetReplicationSource(ctx context.Context, host string, port int32, stopReplicationBefore bool, startReplicationAfter bool) error
 	WaitForReparentJournal(ctx context.Context, timeCreatedNS int64) error
+	InjectEmptyTransactions(ctx context.Context, gtidSet string) error
 
 	WaitSourcePos(context.Context, mysql.Position) error
 
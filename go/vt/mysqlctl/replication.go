@@ -493,6 +493,49 @@ func (mysqld *Mysqld) ResetReplicationParameters(ctx context.Context) error {
 	return mysqld.executeSuperQueryListConn(ctx, conn, cmds)
 }
 
+// injectEmptyTransactionsBatchSize is the number of empty transactions that
+// InjectEmptyTransactions commits per list of queries sent to MySQL.
+const injectEmptyTransactionsBatchSize = 100
+
+// InjectEmptyTransactions commits an empty transaction for every GTID of the given
+// MySQL 5.6 GTID set, so that the set becomes part of the executed GTIDs of this
+// host and, through its binary logs, of its replicas. The transactions are committed
+// in batches, so a large set doesn't end up in a single list of queries.
+func (mysqld *Mysqld) InjectEmptyTransactions(ctx context.Context, gtidSet string) error {
+	set, err := mysql.ParseMysql56GTIDSet(gtidSet)
+	if err != nil {
+		return err
+	}
+	gtids := set.GTIDs()
+	if len(gtids) == 0 {
+		return nil
+	}
+
+	conn, connErr := getPoolReconnect(ctx, mysqld.dbaPool)
+	if connErr != nil {
+		return connErr
+	}
+	defer conn.Recycle()
+
+	for len(gtids) > 0 {
+		batch := gtids
+		if len(batch) > injectEmptyTransactionsBatchSize {
+			batch = batch[:injectEmptyTransactionsBatchSize]
+		}
+		gtids = gtids[len(batch):]
+
+		cmds := make([]string, 0, 3*len(batch)+1)
+		for _, gtid := range batch {
+			cmds = append(cmds, fmt.Sprintf("SET GTID_NEXT = '%s'", gtid.String()), "BEGIN", "COMMIT")
+		}
+		cmds = append(cmds, "SET GTID_NEXT = 'AUTOMATIC'")
+		if err := mysqld.executeSuperQueryListConn(ctx, conn, cmds); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // +------+---------+---------------------+------+-------------+------+----------------------------------------------------------------+------------------+
 // | Id   | User    | Host                | db   | Command     | Time | State                                                          | Info             |
 // +------+---------+---------------------+------+-------------+------+----------------------------------------------------------------+------------------+
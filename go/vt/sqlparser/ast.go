@@ -2982,6 +2982,12 @@ type (
 		Arg Expr
 	}
 
+	// JSONArrayAgg represents JSON_ARRAYAGG()
+	// For more information, see https://dev.mysql.com/doc/refman/8.0/en/aggregate-functions.html#function_json-arrayagg
+	JSONArrayAgg struct {
+		Arg Expr
+	}
+
 	// RegexpInstrExpr represents REGEXP_INSTR()
 	// For more information, see https://dev.mysql.com/doc/refman/8.0/en/regexp.html#function_regexp-instr
 	RegexpInstrExpr struct {
@@ -3081,6 +3087,19 @@ type (
 	// LagLeadExprType is an enum to get types of LagLeadExpr.
 	LagLeadExprType int8
 
+	// AggregateWindowExpr stands for the aggregate functions COUNT, SUM, AVG, MIN and MAX
+	// when they are used as window functions with an OVER clause.
+	// Arg is nil for COUNT(*).
+	AggregateWindowExpr struct {
+		Type       AggregateWindowExprType
+		Distinct   bool
+		Arg        Expr
+		OverClause *OverClause
+	}
+
+	// AggregateWindowExprType is an enum to get types of AggregateWindowExpr.
+	AggregateWindowExprType int8
+
 	// ExtractValueExpr stands for EXTRACTVALUE() XML function
 	// Extract a value from an XML string using XPath notation
 	// For more details, postVisit https://dev.mysql.com/doc/refman/8.0/en/xml-functions.html#function_extractvalue
@@ -3210,6 +3229,7 @@ func (*FirstOrLastValueExpr) iExpr()               {}
 func (*NtileExpr) iExpr()                          {}
 func (*NTHValueExpr) iExpr()                       {}
 func (*LagLeadExpr) iExpr()                        {}
+func (*AggregateWindowExpr) iExpr()                {}
 func (*NamedWindow) iExpr()                        {}
 func (*ExtractValueExpr) iExpr()                   {}
 func (*UpdateXMLExpr) iExpr()                      {}
@@ -3224,6 +3244,7 @@ func (*CountStar) iExpr()                          {}
 func (*Count) iExpr()                              {}
 func (*GroupConcatExpr) iExpr()                    {}
 func (*AnyValue) iExpr()                           {}
+func (*JSONArrayAgg) iExpr()                       {}
 func (*BitAnd) iExpr()                             {}
 func (*BitOr) iExpr()                              {}
 func (*BitXor) iExpr()                             {}
@@ -3273,6 +3294,7 @@ func (*ConvertUsingExpr) iCallable()                   {}
 func (*MatchExpr) iCallable()                          {}
 func (*GroupConcatExpr) iCallable()                    {}
 func (*AnyValue) iCallable()                           {}
+func (*JSONArrayAgg) iCallable()                       {}
 func (*JSONSchemaValidFuncExpr) iCallable()            {}
 func (*JSONSchemaValidationReportFuncExpr) iCallable() {}
 func (*JSONPrettyExpr) iCallable()                     {}
@@ -3304,6 +3326,7 @@ func (*FirstOrLastValueExpr) iCallable()               {}
 func (*NtileExpr) iCallable()                          {}
 func (*NTHValueExpr) iCallable()                       {}
 func (*LagLeadExpr) iCallable()                        {}
+func (*AggregateWindowExpr) iCallable()                {}
 func (*NamedWindow) iCallable()                        {}
 func (*ExtractValueExpr) iCallable()                   {}
 func (*UpdateXMLExpr) iCallable()                      {}
@@ -3354,6 +3377,7 @@ func (varP *VarPop) GetArg() Expr               { return varP.Arg }
 func (varS *VarSamp) GetArg() Expr              { return varS.Arg }
 func (variance *Variance) GetArg() Expr         { return variance.Arg }
 func (av *AnyValue) GetArg() Expr               { return av.Arg }
+func (jaa *JSONArrayAgg) GetArg() Expr          { return jaa.Arg }
 
 func (sum *Sum) GetArgs() Exprs                   { return Exprs{sum.Arg} }
 func (min *Min) GetArgs() Exprs                   { return Exprs{min.Arg} }
@@ -3373,6 +3397,7 @@ func (varP *VarPop) GetArgs() Exprs               { return Exprs{varP.Arg} }
 func (varS *VarSamp) GetArgs() Exprs              { return Exprs{varS.Arg} }
 func (variance *Variance) GetArgs() Exprs         { return Exprs{variance.Arg} }
 func (av *AnyValue) GetArgs() Exprs               { return Exprs{av.Arg} }
+func (jaa *JSONArrayAgg) GetArgs() Exprs          { return Exprs{jaa.Arg} }
 
 func (sum *Sum) IsDistinct() bool                   { return sum.Distinct }
 func (min *Min) IsDistinct() bool                   { return min.Distinct }
@@ -3399,6 +3424,7 @@ func (*VarPop) AggrName() string          { return "var_pop" }
 func (*VarSamp) AggrName() string         { return "var_samp" }
 func (*Variance) AggrName() string        { return "variance" }
 func (*AnyValue) AggrName() string        { return "any_value" }
+func (*JSONArrayAgg) AggrName() string    { return "json_arrayagg" }
 
 // Exprs represents a list of value expressions.
 // It's not a valid expression because it's not parenthesized.
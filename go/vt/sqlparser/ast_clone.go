@@ -29,6 +29,8 @@ func CloneSQLNode(in SQLNode) SQLNode {
 		return CloneRefOfAddConstraintDefinition(in)
 	case *AddIndexDefinition:
 		return CloneRefOfAddIndexDefinition(in)
+	case *AggregateWindowExpr:
+		return CloneRefOfAggregateWindowExpr(in)
 	case AlgorithmValue:
 		return in
 	case *AliasedExpr:
@@ -233,6 +235,8 @@ func CloneSQLNode(in SQLNode) SQLNode {
 		return CloneRefOfIntroducerExpr(in)
 	case *IsExpr:
 		return CloneRefOfIsExpr(in)
+	case *JSONArrayAgg:
+		return CloneRefOfJSONArrayAgg(in)
 	case *JSONArrayExpr:
 		return CloneRefOfJSONArrayExpr(in)
 	case *JSONAttributesExpr:
@@ -594,6 +598,17 @@ func CloneRefOfAddIndexDefinition(n *AddIndexDefinition) *AddIndexDefinition {
 	return &out
 }
 
+// CloneRefOfAggregateWindowExpr creates a deep clone of the input.
+func CloneRefOfAggregateWindowExpr(n *AggregateWindowExpr) *AggregateWindowExpr {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.Arg = CloneExpr(n.Arg)
+	out.OverClause = CloneRefOfOverClause(n.OverClause)
+	return &out
+}
+
 // CloneRefOfAliasedExpr creates a deep clone of the input.
 func CloneRefOfAliasedExpr(n *AliasedExpr) *AliasedExpr {
 	if n == nil {
@@ -1690,6 +1705,16 @@ func CloneRefOfIsExpr(n *IsExpr) *IsExpr {
 	return &out
 }
 
+// CloneRefOfJSONArrayAgg creates a deep clone of the input.
+func CloneRefOfJSONArrayAgg(n *JSONArrayAgg) *JSONArrayAgg {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	out.Arg = CloneExpr(n.Arg)
+	return &out
+}
+
 // CloneRefOfJSONArrayExpr creates a deep clone of the input.
 func CloneRefOfJSONArrayExpr(n *JSONArrayExpr) *JSONArrayExpr {
 	if n == nil {
@@ -3446,6 +3471,8 @@ func CloneAggrFunc(in AggrFunc) AggrFunc {
 		return CloneRefOfCountStar(in)
 	case *GroupConcatExpr:
 		return CloneRefOfGroupConcatExpr(in)
+	case *JSONArrayAgg:
+		return CloneRefOfJSONArrayAgg(in)
 	case *Max:
 		return CloneRefOfMax(in)
 	case *Min:
@@ -3534,6 +3561,8 @@ func CloneCallable(in Callable) Callable {
 		return nil
 	}
 	switch in := in.(type) {
+	case *AggregateWindowExpr:
+		return CloneRefOfAggregateWindowExpr(in)
 	case *AnyValue:
 		return CloneRefOfAnyValue(in)
 	case *ArgumentLessWindowExpr:
@@ -3590,6 +3619,8 @@ func CloneCallable(in Callable) Callable {
 		return CloneRefOfIntervalDateExpr(in)
 	case *IntervalFuncExpr:
 		return CloneRefOfIntervalFuncExpr(in)
+	case *JSONArrayAgg:
+		return CloneRefOfJSONArrayAgg(in)
 	case *JSONArrayExpr:
 		return CloneRefOfJSONArrayExpr(in)
 	case *JSONAttributesExpr:
@@ -3798,6 +3829,8 @@ func CloneExpr(in Expr) Expr {
 		return nil
 	}
 	switch in := in.(type) {
+	case *AggregateWindowExpr:
+		return CloneRefOfAggregateWindowExpr(in)
 	case *AndExpr:
 		return CloneRefOfAndExpr(in)
 	case *AnyValue:
@@ -3892,6 +3925,8 @@ func CloneExpr(in Expr) Expr {
 		return CloneRefOfIntroducerExpr(in)
 	case *IsExpr:
 		return CloneRefOfIsExpr(in)
+	case *JSONArrayAgg:
+		return CloneRefOfJSONArrayAgg(in)
 	case *JSONArrayExpr:
 		return CloneRefOfJSONArrayExpr(in)
 	case *JSONAttributesExpr:
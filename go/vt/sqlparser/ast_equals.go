@@ -44,6 +44,12 @@ func (cmp *Comparator) SQLNode(inA, inB SQLNode) bool {
 			return false
 		}
 		return cmp.RefOfAddIndexDefinition(a, b)
+	case *AggregateWindowExpr:
+		b, ok := inB.(*AggregateWindowExpr)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfAggregateWindowExpr(a, b)
 	case AlgorithmValue:
 		b, ok := inB.(AlgorithmValue)
 		if !ok {
@@ -656,6 +662,12 @@ func (cmp *Comparator) SQLNode(inA, inB SQLNode) bool {
 			return false
 		}
 		return cmp.RefOfIsExpr(a, b)
+	case *JSONArrayAgg:
+		b, ok := inB.(*JSONArrayAgg)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfJSONArrayAgg(a, b)
 	case *JSONArrayExpr:
 		b, ok := inB.(*JSONArrayExpr)
 		if !ok {
@@ -1669,6 +1681,20 @@ func (cmp *Comparator) RefOfAddIndexDefinition(a, b *AddIndexDefinition) bool {
 	return cmp.RefOfIndexDefinition(a.IndexDefinition, b.IndexDefinition)
 }
 
+// RefOfAggregateWindowExpr does deep equals between the two objects.
+func (cmp *Comparator) RefOfAggregateWindowExpr(a, b *AggregateWindowExpr) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.Distinct == b.Distinct &&
+		a.Type == b.Type &&
+		cmp.Expr(a.Arg, b.Arg) &&
+		cmp.RefOfOverClause(a.OverClause, b.OverClause)
+}
+
 // RefOfAliasedExpr does deep equals between the two objects.
 func (cmp *Comparator) RefOfAliasedExpr(a, b *AliasedExpr) bool {
 	if a == b {
@@ -2967,6 +2993,17 @@ func (cmp *Comparator) RefOfIsExpr(a, b *IsExpr) bool {
 		a.Right == b.Right
 }
 
+// RefOfJSONArrayAgg does deep equals between the two objects.
+func (cmp *Comparator) RefOfJSONArrayAgg(a, b *JSONArrayAgg) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return cmp.Expr(a.Arg, b.Arg)
+}
+
 // RefOfJSONArrayExpr does deep equals between the two objects.
 func (cmp *Comparator) RefOfJSONArrayExpr(a, b *JSONArrayExpr) bool {
 	if a == b {
@@ -4999,6 +5036,12 @@ func (cmp *Comparator) AggrFunc(inA, inB AggrFunc) bool {
 			return false
 		}
 		return cmp.RefOfGroupConcatExpr(a, b)
+	case *JSONArrayAgg:
+		b, ok := inB.(*JSONArrayAgg)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfJSONArrayAgg(a, b)
 	case *Max:
 		b, ok := inB.(*Max)
 		if !ok {
@@ -5221,6 +5264,12 @@ func (cmp *Comparator) Callable(inA, inB Callable) bool {
 		return false
 	}
 	switch a := inA.(type) {
+	case *AggregateWindowExpr:
+		b, ok := inB.(*AggregateWindowExpr)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfAggregateWindowExpr(a, b)
 	case *AnyValue:
 		b, ok := inB.(*AnyValue)
 		if !ok {
@@ -5389,6 +5438,12 @@ func (cmp *Comparator) Callable(inA, inB Callable) bool {
 			return false
 		}
 		return cmp.RefOfIntervalFuncExpr(a, b)
+	case *JSONArrayAgg:
+		b, ok := inB.(*JSONArrayAgg)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfJSONArrayAgg(a, b)
 	case *JSONArrayExpr:
 		b, ok := inB.(*JSONArrayExpr)
 		if !ok {
@@ -5887,6 +5942,12 @@ func (cmp *Comparator) Expr(inA, inB Expr) bool {
 		return false
 	}
 	switch a := inA.(type) {
+	case *AggregateWindowExpr:
+		b, ok := inB.(*AggregateWindowExpr)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfAggregateWindowExpr(a, b)
 	case *AndExpr:
 		b, ok := inB.(*AndExpr)
 		if !ok {
@@ -6169,6 +6230,12 @@ func (cmp *Comparator) Expr(inA, inB Expr) bool {
 			return false
 		}
 		return cmp.RefOfIsExpr(a, b)
+	case *JSONArrayAgg:
+		b, ok := inB.(*JSONArrayAgg)
+		if !ok {
+			return false
+		}
+		return cmp.RefOfJSONArrayAgg(a, b)
 	case *JSONArrayExpr:
 		b, ok := inB.(*JSONArrayExpr)
 		if !ok {
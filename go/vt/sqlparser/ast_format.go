@@ -74,6 +74,9 @@ func (node *CommentOnly) Format(buf *TrackedBuffer) {
 
 // Format formats the node.
 func (node *Union) Format(buf *TrackedBuffer) {
+	if node.With != nil {
+		buf.astPrintf(node, "%v", node.With)
+	}
 	if requiresParen(node.Left) {
 		buf.astPrintf(node, "(%v)", node.Left)
 	} else {
@@ -1700,6 +1703,23 @@ func (node *LagLeadExpr) Format(buf *TrackedBuffer) {
 	}
 }
 
+// Format formats the node
+func (node *AggregateWindowExpr) Format(buf *TrackedBuffer) {
+	buf.astPrintf(node, "%s(", node.Type.ToString())
+	if node.Distinct {
+		buf.literal(DistinctStr)
+	}
+	if node.Arg == nil {
+		buf.WriteString("*")
+	} else {
+		buf.astPrintf(node, "%v", node.Arg)
+	}
+	buf.WriteString(")")
+	if node.OverClause != nil {
+		buf.astPrintf(node, " %v", node.OverClause)
+	}
+}
+
 // Format formats the node
 func (node *ExtractValueExpr) Format(buf *TrackedBuffer) {
 	buf.astPrintf(node, "extractvalue(%v, %v)", node.Fragment, node.XPathExpr)
@@ -2682,6 +2702,10 @@ func (node *AnyValue) Format(buf *TrackedBuffer) {
 	buf.astPrintf(node, "any_value(%v)", node.Arg)
 }
 
+func (node *JSONArrayAgg) Format(buf *TrackedBuffer) {
+	buf.astPrintf(node, "json_arrayagg(%v)", node.Arg)
+}
+
 func (node *Avg) Format(buf *TrackedBuffer) {
 	buf.WriteString("avg(")
 	if node.Distinct {
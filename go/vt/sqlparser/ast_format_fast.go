@@ -86,6 +86,9 @@ func (node *CommentOnly) formatFast(buf *TrackedBuffer) {
 
 // formatFast formats the node.
 func (node *Union) formatFast(buf *TrackedBuffer) {
+	if node.With != nil {
+		node.With.formatFast(buf)
+	}
 	if requiresParen(node.Left) {
 		buf.WriteByte('(')
 		node.Left.formatFast(buf)
@@ -2260,6 +2263,25 @@ func (node *LagLeadExpr) formatFast(buf *TrackedBuffer) {
 	}
 }
 
+// formatFast formats the node
+func (node *AggregateWindowExpr) formatFast(buf *TrackedBuffer) {
+	buf.WriteString(node.Type.ToString())
+	buf.WriteByte('(')
+	if node.Distinct {
+		buf.WriteString(DistinctStr)
+	}
+	if node.Arg == nil {
+		buf.WriteString("*")
+	} else {
+		buf.printExpr(node, node.Arg, true)
+	}
+	buf.WriteString(")")
+	if node.OverClause != nil {
+		buf.WriteByte(' ')
+		node.OverClause.formatFast(buf)
+	}
+}
+
 // formatFast formats the node
 func (node *ExtractValueExpr) formatFast(buf *TrackedBuffer) {
 	buf.WriteString("extractvalue(")
@@ -3542,6 +3564,12 @@ func (node *AnyValue) formatFast(buf *TrackedBuffer) {
 	buf.WriteByte(')')
 }
 
+func (node *JSONArrayAgg) formatFast(buf *TrackedBuffer) {
+	buf.WriteString("json_arrayagg(")
+	buf.printExpr(node, node.Arg, true)
+	buf.WriteByte(')')
+}
+
 func (node *Avg) formatFast(buf *TrackedBuffer) {
 	buf.WriteString("avg(")
 	if node.Distinct {
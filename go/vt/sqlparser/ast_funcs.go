@@ -1221,6 +1221,11 @@ func (node *Union) SetWith(with *With) {
 	node.With = with
 }
 
+// CTEs returns the common table expressions defined in the with clause
+func (node *With) CTEs() []*CommonTableExpr {
+	return node.ctes
+}
+
 // MakeDistinct implements the SelectStatement interface
 func (node *Union) MakeDistinct() {
 	node.Distinct = true
@@ -1684,6 +1689,24 @@ func (ty LagLeadExprType) ToString() string {
 	}
 }
 
+// ToString returns the type as a string
+func (ty AggregateWindowExprType) ToString() string {
+	switch ty {
+	case CountWindowExprType:
+		return CountWindowExprStr
+	case SumWindowExprType:
+		return SumWindowExprStr
+	case AvgWindowExprType:
+		return AvgWindowExprStr
+	case MinWindowExprType:
+		return MinWindowExprStr
+	case MaxWindowExprType:
+		return MaxWindowExprStr
+	default:
+		return "Unknown AggregateWindowExprType"
+	}
+}
+
 // ToString returns the type as a string
 func (ty JSONAttributeType) ToString() string {
 	switch ty {
@@ -312,6 +312,9 @@ const (
 
 	// UserDefinedVariableName is what we prepend bind var names for user defined variables
 	UserDefinedVariableName = "__vtudv"
+
+	// QueryAttributePrefix is what we prepend bind var names for query attributes
+	QueryAttributePrefix = "__vtqa_"
 )
 
 func (er *astRewriter) rewriteAliasedExpr(node *AliasedExpr) (*BindVarNeeds, error) {
@@ -28,6 +28,8 @@ func VisitSQLNode(in SQLNode, f Visit) error {
 		return VisitRefOfAddConstraintDefinition(in, f)
 	case *AddIndexDefinition:
 		return VisitRefOfAddIndexDefinition(in, f)
+	case *AggregateWindowExpr:
+		return VisitRefOfAggregateWindowExpr(in, f)
 	case AlgorithmValue:
 		return VisitAlgorithmValue(in, f)
 	case *AliasedExpr:
@@ -232,6 +234,8 @@ func VisitSQLNode(in SQLNode, f Visit) error {
 		return VisitRefOfIntroducerExpr(in, f)
 	case *IsExpr:
 		return VisitRefOfIsExpr(in, f)
+	case *JSONArrayAgg:
+		return VisitRefOfJSONArrayAgg(in, f)
 	case *JSONArrayExpr:
 		return VisitRefOfJSONArrayExpr(in, f)
 	case *JSONAttributesExpr:
@@ -602,6 +606,21 @@ func VisitRefOfAddIndexDefinition(in *AddIndexDefinition, f Visit) error {
 	}
 	return nil
 }
+func VisitRefOfAggregateWindowExpr(in *AggregateWindowExpr, f Visit) error {
+	if in == nil {
+		return nil
+	}
+	if cont, err := f(in); err != nil || !cont {
+		return err
+	}
+	if err := VisitExpr(in.Arg, f); err != nil {
+		return err
+	}
+	if err := VisitRefOfOverClause(in.OverClause, f); err != nil {
+		return err
+	}
+	return nil
+}
 func VisitRefOfAliasedExpr(in *AliasedExpr, f Visit) error {
 	if in == nil {
 		return nil
@@ -2084,6 +2103,18 @@ func VisitRefOfIsExpr(in *IsExpr, f Visit) error {
 	}
 	return nil
 }
+func VisitRefOfJSONArrayAgg(in *JSONArrayAgg, f Visit) error {
+	if in == nil {
+		return nil
+	}
+	if cont, err := f(in); err != nil || !cont {
+		return err
+	}
+	if err := VisitExpr(in.Arg, f); err != nil {
+		return err
+	}
+	return nil
+}
 func VisitRefOfJSONArrayExpr(in *JSONArrayExpr, f Visit) error {
 	if in == nil {
 		return nil
@@ -4417,6 +4448,8 @@ func VisitAggrFunc(in AggrFunc, f Visit) error {
 		return VisitRefOfCountStar(in, f)
 	case *GroupConcatExpr:
 		return VisitRefOfGroupConcatExpr(in, f)
+	case *JSONArrayAgg:
+		return VisitRefOfJSONArrayAgg(in, f)
 	case *Max:
 		return VisitRefOfMax(in, f)
 	case *Min:
@@ -4501,6 +4534,8 @@ func VisitCallable(in Callable, f Visit) error {
 		return nil
 	}
 	switch in := in.(type) {
+	case *AggregateWindowExpr:
+		return VisitRefOfAggregateWindowExpr(in, f)
 	case *AnyValue:
 		return VisitRefOfAnyValue(in, f)
 	case *ArgumentLessWindowExpr:
@@ -4557,6 +4592,8 @@ func VisitCallable(in Callable, f Visit) error {
 		return VisitRefOfIntervalDateExpr(in, f)
 	case *IntervalFuncExpr:
 		return VisitRefOfIntervalFuncExpr(in, f)
+	case *JSONArrayAgg:
+		return VisitRefOfJSONArrayAgg(in, f)
 	case *JSONArrayExpr:
 		return VisitRefOfJSONArrayExpr(in, f)
 	case *JSONAttributesExpr:
@@ -4753,6 +4790,8 @@ func VisitExpr(in Expr, f Visit) error {
 		return nil
 	}
 	switch in := in.(type) {
+	case *AggregateWindowExpr:
+		return VisitRefOfAggregateWindowExpr(in, f)
 	case *AndExpr:
 		return VisitRefOfAndExpr(in, f)
 	case *AnyValue:
@@ -4847,6 +4886,8 @@ func VisitExpr(in Expr, f Visit) error {
 		return VisitRefOfIntroducerExpr(in, f)
 	case *IsExpr:
 		return VisitRefOfIsExpr(in, f)
+	case *JSONArrayAgg:
+		return VisitRefOfJSONArrayAgg(in, f)
 	case *JSONArrayExpr:
 		return VisitRefOfJSONArrayExpr(in, f)
 	case *JSONAttributesExpr:
@@ -355,6 +355,13 @@ const (
 	LagExprStr  = "lag"
 	LeadExprStr = "lead"
 
+	// AggregateWindowExprType strings
+	CountWindowExprStr = "count"
+	SumWindowExprStr   = "sum"
+	AvgWindowExprStr   = "avg"
+	MinWindowExprStr   = "min"
+	MaxWindowExprStr   = "max"
+
 	// TrimFuncType strings
 	NormalTrimStr = "trim"
 	LTrimStr      = "ltrim"
@@ -576,6 +583,15 @@ const (
 	LeadExprType
 )
 
+// Constants for Enum Type - AggregateWindowExprType
+const (
+	CountWindowExprType AggregateWindowExprType = iota
+	SumWindowExprType
+	AvgWindowExprType
+	MinWindowExprType
+	MaxWindowExprType
+)
+
 // Constants for Enum Type - JSONAttributeType
 const (
 	DepthAttributeType JSONAttributeType = iota
@@ -356,6 +356,7 @@ var keywords = []keyword{
 	{"json_array", JSON_ARRAY},
 	{"json_array_append", JSON_ARRAY_APPEND},
 	{"json_array_insert", JSON_ARRAY_INSERT},
+	{"json_arrayagg", JSON_ARRAYAGG},
 	{"json_contains", JSON_CONTAINS},
 	{"json_contains_path", JSON_CONTAINS_PATH},
 	{"json_depth", JSON_DEPTH},
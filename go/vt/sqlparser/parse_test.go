@@ -499,6 +499,8 @@ var (
 	}, {
 		input:  "WITH topsales2003 AS (SELECT salesRepEmployeeNumber employeeNumber, SUM(quantityOrdered * priceEach) sales FROM orders INNER JOIN orderdetails USING (orderNumber) INNER JOIN customers USING (customerNumber) WHERE YEAR(shippedDate) = 2003 AND status = 'Shipped' GROUP BY salesRepEmployeeNumber ORDER BY sales DESC LIMIT 5)SELECT employeeNumber, firstName, lastName, sales FROM employees JOIN topsales2003 USING (employeeNumber)",
 		output: "with topsales2003 as (select salesRepEmployeeNumber as employeeNumber, sum(quantityOrdered * priceEach) as sales from orders join orderdetails using (orderNumber) join customers using (customerNumber) where YEAR(shippedDate) = 2003 and `status` = 'Shipped' group by salesRepEmployeeNumber order by sales desc limit 5) select employeeNumber, firstName, lastName, sales from employees join topsales2003 using (employeeNumber)",
+	}, {
+		input: "with x as (select id from t) select id from x union all select id from x",
 	}, {
 		input: "select 1 from t",
 	}, {
@@ -2643,6 +2645,9 @@ var (
 		output: "select `name`, group_concat(distinct id, score order by id desc separator ':' limit 10, 2) from t group by `name`",
 	}, {
 		input: "select foo, any_value(id) from tbl group by foo",
+	}, {
+		input:  "select foo, JSON_ARRAYAGG(bar) from tbl group by foo",
+		output: "select foo, json_arrayagg(bar) from tbl group by foo",
 	}, {
 		input: "select * from t partition (p0)",
 	}, {
@@ -3502,6 +3507,9 @@ var (
 	}, {
 		input:  "SELECT LAG(val, 10) OVER w, LEAD('val', null) OVER w, LEAD(val, 1, ASCII(1)) OVER w FROM numbers",
 		output: "select lag(val, 10) over w, lead('val', null) over w, lead(val, 1, ASCII(1)) over w from numbers",
+	}, {
+		input:  "SELECT SUM(val) OVER (PARTITION BY subject ORDER BY time), COUNT(*) OVER w, COUNT(val) OVER (), AVG(val) OVER w, MIN(val) OVER w, MAX(val) OVER w FROM observations",
+		output: "select sum(val) over ( partition by subject order by `time` asc), count(*) over w, count(val) over (), avg(val) over w, min(val) over w, max(val) over w from observations",
 	}, {
 		input:  "SELECT val, ROW_NUMBER() OVER (ORDER BY val) AS 'row_number' FROM numbers WINDOW w AS (ORDER BY val);",
 		output: "select val, row_number() over ( order by val asc) as `row_number` from numbers window w AS ( order by val asc)",
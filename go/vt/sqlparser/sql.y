@@ -351,7 +351,7 @@ func markBindVariable(yylex yyLexer, bvar string) {
 %token <str> JSON_ARRAY JSON_OBJECT JSON_QUOTE
 %token <str> JSON_DEPTH JSON_TYPE JSON_LENGTH JSON_VALID
 %token <str> JSON_ARRAY_APPEND JSON_ARRAY_INSERT JSON_INSERT JSON_MERGE JSON_MERGE_PATCH JSON_MERGE_PRESERVE JSON_REMOVE JSON_REPLACE JSON_SET JSON_UNQUOTE
-%token <str> COUNT AVG MAX MIN SUM GROUP_CONCAT BIT_AND BIT_OR BIT_XOR STD STDDEV STDDEV_POP STDDEV_SAMP VAR_POP VAR_SAMP VARIANCE ANY_VALUE
+%token <str> COUNT AVG MAX MIN SUM GROUP_CONCAT BIT_AND BIT_OR BIT_XOR STD STDDEV STDDEV_POP STDDEV_SAMP VAR_POP VAR_SAMP VARIANCE ANY_VALUE JSON_ARRAYAGG
 %token <str> REGEXP_INSTR REGEXP_LIKE REGEXP_REPLACE REGEXP_SUBSTR
 %token <str> ExtractValue UpdateXML
 %token <str> GET_LOCK RELEASE_LOCK RELEASE_ALL_LOCKS IS_FREE_LOCK IS_USED_LOCK
@@ -5617,6 +5617,7 @@ window_partition_clause_opt:
 
 sql_id_opt:
   {
+    $$ = IdentifierCI{}
   }
 | sql_id
   {
@@ -5941,6 +5942,34 @@ UTC_DATE func_paren_opt
   {
     $$ = &Avg{Distinct:$3, Arg:$4}
   }
+| COUNT openb '*' closeb over_clause
+  {
+    $$ = &AggregateWindowExpr{Type: CountWindowExprType, OverClause: $5}
+  }
+| COUNT openb distinct_opt expression_list closeb over_clause
+  {
+    if len($4) != 1 {
+      yylex.Error("count window function expects a single argument")
+      return 1
+    }
+    $$ = &AggregateWindowExpr{Type: CountWindowExprType, Distinct: $3, Arg: $4[0], OverClause: $6}
+  }
+| MAX openb distinct_opt expression closeb over_clause
+  {
+    $$ = &AggregateWindowExpr{Type: MaxWindowExprType, Distinct: $3, Arg: $4, OverClause: $6}
+  }
+| MIN openb distinct_opt expression closeb over_clause
+  {
+    $$ = &AggregateWindowExpr{Type: MinWindowExprType, Distinct: $3, Arg: $4, OverClause: $6}
+  }
+| SUM openb distinct_opt expression closeb over_clause
+  {
+    $$ = &AggregateWindowExpr{Type: SumWindowExprType, Distinct: $3, Arg: $4, OverClause: $6}
+  }
+| AVG openb distinct_opt expression closeb over_clause
+  {
+    $$ = &AggregateWindowExpr{Type: AvgWindowExprType, Distinct: $3, Arg: $4, OverClause: $6}
+  }
 | BIT_AND openb expression closeb
   {
     $$ = &BitAnd{Arg:$3}
@@ -5989,6 +6018,10 @@ UTC_DATE func_paren_opt
   {
     $$ = &AnyValue{Arg:$3}
   }
+| JSON_ARRAYAGG openb expression closeb
+  {
+    $$ = &JSONArrayAgg{Arg:$3}
+  }
 | TIMESTAMPADD openb timestampadd_interval ',' expression ',' expression closeb
   {
     $$ = &IntervalDateExpr{Syntax: IntervalDateExprTimestampadd, Date: $7, Interval: $5, Unit: $3}
@@ -8206,6 +8239,7 @@ non_reserved_keyword:
 | JSON_ARRAY %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_ARRAY_APPEND %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_ARRAY_INSERT %prec FUNCTION_CALL_NON_KEYWORD
+| JSON_ARRAYAGG %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_CONTAINS %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_CONTAINS_PATH %prec FUNCTION_CALL_NON_KEYWORD
 | JSON_DEPTH %prec FUNCTION_CALL_NON_KEYWORD
@@ -63,6 +63,92 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+func TestConcludeTransaction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		cfgs      []vtadmintestutil.TestClusterConfig
+		req       *vtadminpb.ConcludeTransactionRequest
+		expected  *vtctldatapb.ConcludeTransactionResponse
+		shouldErr bool
+	}{
+		{
+			name: "success",
+			cfgs: []vtadmintestutil.TestClusterConfig{
+				{
+					Cluster: &vtadminpb.Cluster{
+						Id:   "c1",
+						Name: "cluster1",
+					},
+					VtctldClient: &fakevtctldclient.VtctldClient{
+						ConcludeTransactionResults: map[string]error{
+							"ks:0:1234": nil,
+						},
+					},
+				},
+			},
+			req: &vtadminpb.ConcludeTransactionRequest{
+				ClusterId: "c1",
+				Dtid:      "ks:0:1234",
+			},
+			expected: &vtctldatapb.ConcludeTransactionResponse{},
+		},
+		{
+			name: "vtctld error",
+			cfgs: []vtadmintestutil.TestClusterConfig{
+				{
+					Cluster: &vtadminpb.Cluster{
+						Id:   "c1",
+						Name: "cluster1",
+					},
+					VtctldClient: &fakevtctldclient.VtctldClient{
+						ConcludeTransactionResults: map[string]error{
+							"ks:0:1234": assert.AnError,
+						},
+					},
+				},
+			},
+			req: &vtadminpb.ConcludeTransactionRequest{
+				ClusterId: "c1",
+				Dtid:      "ks:0:1234",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no such cluster",
+			cfgs: []vtadmintestutil.TestClusterConfig{},
+			req: &vtadminpb.ConcludeTransactionRequest{
+				ClusterId: "c1",
+				Dtid:      "ks:0:1234",
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			api := NewAPI(vtadmintestutil.BuildClusters(t, tt.cfgs...), Options{})
+
+			resp, err := api.ConcludeTransaction(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestFindSchema(t *testing.T) {
 	t.Parallel()
 
@@ -3761,6 +3847,118 @@ func TestGetTablets(t *testing.T) {
 	}
 }
 
+func TestGetUnresolvedTransactions(t *testing.T) {
+	t.Parallel()
+
+	transactions := []*querypb.TransactionMetadata{
+		{
+			Dtid:        "ks:-80:1234",
+			State:       querypb.TransactionState_PREPARE,
+			TimeCreated: 1000,
+			Participants: []*querypb.Target{
+				{Keyspace: "ks", Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		cfgs      []vtadmintestutil.TestClusterConfig
+		req       *vtadminpb.GetUnresolvedTransactionsRequest
+		expected  *vtctldatapb.GetUnresolvedTransactionsResponse
+		shouldErr bool
+	}{
+		{
+			name: "success",
+			cfgs: []vtadmintestutil.TestClusterConfig{
+				{
+					Cluster: &vtadminpb.Cluster{
+						Id:   "c1",
+						Name: "cluster1",
+					},
+					VtctldClient: &fakevtctldclient.VtctldClient{
+						GetUnresolvedTransactionsResults: map[string]struct {
+							Response *vtctldatapb.GetUnresolvedTransactionsResponse
+							Error    error
+						}{
+							"ks": {
+								Response: &vtctldatapb.GetUnresolvedTransactionsResponse{
+									Transactions: transactions,
+								},
+							},
+						},
+					},
+				},
+			},
+			req: &vtadminpb.GetUnresolvedTransactionsRequest{
+				ClusterId:  "c1",
+				Keyspace:   "ks",
+				AbandonAge: 60,
+			},
+			expected: &vtctldatapb.GetUnresolvedTransactionsResponse{
+				Transactions: transactions,
+			},
+		},
+		{
+			name: "vtctld error",
+			cfgs: []vtadmintestutil.TestClusterConfig{
+				{
+					Cluster: &vtadminpb.Cluster{
+						Id:   "c1",
+						Name: "cluster1",
+					},
+					VtctldClient: &fakevtctldclient.VtctldClient{
+						GetUnresolvedTransactionsResults: map[string]struct {
+							Response *vtctldatapb.GetUnresolvedTransactionsResponse
+							Error    error
+						}{
+							"ks": {
+								Error: assert.AnError,
+							},
+						},
+					},
+				},
+			},
+			req: &vtadminpb.GetUnresolvedTransactionsRequest{
+				ClusterId: "c1",
+				Keyspace:  "ks",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "no such cluster",
+			cfgs: []vtadmintestutil.TestClusterConfig{},
+			req: &vtadminpb.GetUnresolvedTransactionsRequest{
+				ClusterId: "c1",
+				Keyspace:  "ks",
+			},
+			shouldErr: true,
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			api := NewAPI(vtadmintestutil.BuildClusters(t, tt.cfgs...), Options{})
+
+			resp, err := api.GetUnresolvedTransactions(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestGetVSchema(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,56 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package http
+
+import (
+	"context"
+
+	vtadminpb "vitess.io/vitess/go/vt/proto/vtadmin"
+)
+
+// ConcludeTransaction implements the http wrapper for
+// PUT /transaction/{cluster_id}/{dtid}/conclude.
+func ConcludeTransaction(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := r.Vars()
+
+	result, err := api.server.ConcludeTransaction(ctx, &vtadminpb.ConcludeTransactionRequest{
+		ClusterId: vars["cluster_id"],
+		Dtid:      vars["dtid"],
+	})
+	return NewJSONResponse(result, err)
+}
+
+// GetUnresolvedTransactions implements the http wrapper for
+// GET /transactions/{cluster_id}/{keyspace}[?abandon_age=].
+//
+// Query params:
+// - abandon_age: uint32, in seconds
+func GetUnresolvedTransactions(ctx context.Context, r Request, api *API) *JSONResponse {
+	vars := r.Vars()
+
+	abandonAge, err := r.ParseQueryParamAsUint32("abandon_age", 0)
+	if err != nil {
+		return NewJSONResponse(nil, err)
+	}
+
+	result, err := api.server.GetUnresolvedTransactions(ctx, &vtadminpb.GetUnresolvedTransactionsRequest{
+		ClusterId:  vars["cluster_id"],
+		Keyspace:   vars["keyspace"],
+		AbandonAge: int64(abandonAge),
+	})
+	return NewJSONResponse(result, err)
+}
@@ -115,6 +115,7 @@ const (
 	/* misc resources */
 
 	BackupResource                   Resource = "Backup"
+	DistributedTransactionResource   Resource = "DistributedTransaction"
 	SchemaResource                   Resource = "Schema"
 	ShardReplicationPositionResource Resource = "ShardReplicationPosition"
 	WorkflowResource                 Resource = "Workflow"
@@ -38,6 +38,9 @@ import (
 type VtctldClient struct {
 	vtctldclient.VtctldClient
 
+	// Keyed by dtid.
+	ConcludeTransactionResults map[string]error
+
 	CreateKeyspaceShouldErr bool
 	CreateShardShouldErr    bool
 	DeleteKeyspaceShouldErr bool
@@ -85,6 +88,10 @@ type VtctldClient struct {
 		Response *vtctldatapb.GetSrvVSchemaResponse
 		Error    error
 	}
+	GetUnresolvedTransactionsResults map[string]struct {
+		Response *vtctldatapb.GetUnresolvedTransactionsResponse
+		Error    error
+	}
 	GetVSchemaResults map[string]struct {
 		Response *vtctldatapb.GetVSchemaResponse
 		Error    error
@@ -152,6 +159,23 @@ var _ vtctldclient.VtctldClient = (*VtctldClient)(nil)
 // Close is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) Close() error { return nil }
 
+// ConcludeTransaction is part of the vtctldclient.VtctldClient interface.
+func (fake *VtctldClient) ConcludeTransaction(ctx context.Context, req *vtctldatapb.ConcludeTransactionRequest, opts ...grpc.CallOption) (*vtctldatapb.ConcludeTransactionResponse, error) {
+	if fake.ConcludeTransactionResults == nil {
+		return nil, fmt.Errorf("%w: ConcludeTransactionResults not set on fake vtctldclient", assert.AnError)
+	}
+
+	if err, ok := fake.ConcludeTransactionResults[req.Dtid]; ok {
+		if err != nil {
+			return nil, err
+		}
+
+		return &vtctldatapb.ConcludeTransactionResponse{}, nil
+	}
+
+	return nil, fmt.Errorf("%w: no result set for %s", assert.AnError, req.Dtid)
+}
+
 // CreateKeyspace is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) CreateKeyspace(ctx context.Context, req *vtctldatapb.CreateKeyspaceRequest, opts ...grpc.CallOption) (*vtctldatapb.CreateKeyspaceResponse, error) {
 	if fake.CreateKeyspaceShouldErr {
@@ -403,6 +427,19 @@ func (fake *VtctldClient) GetSrvVSchemas(ctx context.Context, req *vtctldatapb.G
 	return resp, nil
 }
 
+// GetUnresolvedTransactions is part of the vtctldclient.VtctldClient interface.
+func (fake *VtctldClient) GetUnresolvedTransactions(ctx context.Context, req *vtctldatapb.GetUnresolvedTransactionsRequest, opts ...grpc.CallOption) (*vtctldatapb.GetUnresolvedTransactionsResponse, error) {
+	if fake.GetUnresolvedTransactionsResults == nil {
+		return nil, fmt.Errorf("%w: GetUnresolvedTransactionsResults not set on fake vtctldclient", assert.AnError)
+	}
+
+	if result, ok := fake.GetUnresolvedTransactionsResults[req.Keyspace]; ok {
+		return result.Response, result.Error
+	}
+
+	return nil, fmt.Errorf("%w: no result set for keyspace %s", assert.AnError, req.Keyspace)
+}
+
 // GetVSchema is part of the vtctldclient.VtctldClient interface.
 func (fake *VtctldClient) GetVSchema(ctx context.Context, req *vtctldatapb.GetVSchemaRequest, opts ...grpc.CallOption) (*vtctldatapb.GetVSchemaResponse, error) {
 	if fake.GetVSchemaResults == nil {
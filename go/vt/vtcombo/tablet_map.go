@@ -956,6 +956,10 @@ func (itmc *internalTabletManagerClient) ResetReplicationParameters(context.Cont
 	return fmt.Errorf("not implemented in vtcombo")
 }
 
+func (itmc *internalTabletManagerClient) InjectEmptyTransactions(context.Context, *topodatapb.Tablet, string) error {
+	return fmt.Errorf("not implemented in vtcombo")
+}
+
 func (itmc *internalTabletManagerClient) ReplicaWasRestarted(context.Context, *topodatapb.Tablet, *topodatapb.TabletAlias) error {
 	return fmt.Errorf("not implemented in vtcombo")
 }
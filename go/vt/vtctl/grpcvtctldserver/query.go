@@ -0,0 +1,301 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sidecardb"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	"vitess.io/vitess/go/vt/proto/vttime"
+)
+
+const (
+	alterSingleSchemaMigrationSQL = `alter vitess_migration %a `
+	alterAllSchemaMigrationSQL    = `alter vitess_migration %s all`
+	selectSchemaMigrationsSQL     = `select
+		*
+		from %s.schema_migrations where %s %s %s`
+	allMigrationsIndicator = "all"
+
+	selectUnresolvedTransactionsSQL = `select t.dtid, t.state, t.time_created, p.keyspace, p.shard
+	from %s.dt_state t
+	join %s.dt_participant p on t.dtid = p.dtid
+	where t.time_created < %d
+	order by t.dtid, p.id`
+)
+
+// alterSchemaMigrationQuery returns the ALTER VITESS_MIGRATION statement for
+// the given command (e.g. "cancel", "complete") and migration UUID. The
+// special UUID "all" applies the command to all eligible migrations, for the
+// commands that support it.
+func alterSchemaMigrationQuery(command, uuid string) (string, error) {
+	if strings.ToLower(uuid) == allMigrationsIndicator {
+		return fmt.Sprintf(alterAllSchemaMigrationSQL, command), nil
+	}
+	return sqlparser.ParseAndBind(alterSingleSchemaMigrationSQL+command, sqltypes.StringBindVariable(uuid))
+}
+
+// selectSchemaMigrationsQuery returns the query to select the rows of the
+// schema_migrations sidecar table matching the given condition.
+func selectSchemaMigrationsQuery(condition, order, skipLimit string) string {
+	return fmt.Sprintf(selectSchemaMigrationsSQL, sidecardb.GetIdentifier(), condition, order, skipLimit)
+}
+
+// rowToSchemaMigration converts a single row of the schema_migrations sidecar
+// table into a SchemaMigration protobuf message.
+func rowToSchemaMigration(row sqltypes.RowNamedValues) (sm *vtctldatapb.SchemaMigration, err error) {
+	sm = new(vtctldatapb.SchemaMigration)
+	sm.Uuid = row.AsString("migration_uuid", "")
+	sm.Keyspace = row.AsString("keyspace", "")
+	sm.Shard = row.AsString("shard", "")
+	sm.Schema = row.AsString("mysql_schema", "")
+	sm.Table = row.AsString("mysql_table", "")
+	sm.MigrationStatement = row.AsString("migration_statement", "")
+
+	sm.Strategy, err = schematools.ParseSchemaMigrationStrategy(row.AsString("strategy", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.Options = row.AsString("options", "")
+
+	sm.AddedAt, err = valueToVTTime(row.AsString("added_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.RequestedAt, err = valueToVTTime(row.AsString("requested_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.ReadyAt, err = valueToVTTime(row.AsString("ready_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.StartedAt, err = valueToVTTime(row.AsString("started_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.LivenessTimestamp, err = valueToVTTime(row.AsString("liveness_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.CompletedAt, err = valueToVTTime(row.AsString("completed_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.CleanedUpAt, err = valueToVTTime(row.AsString("cleanup_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.Status, err = schematools.ParseSchemaMigrationStatus(row.AsString("migration_status", "unknown"))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.LogPath = row.AsString("log_path", "")
+	sm.Artifacts = row.AsString("artifacts", "")
+	sm.Retries = row.AsUint64("retries", 0)
+
+	if alias := row.AsString("tablet", ""); alias != "" {
+		sm.Tablet, err = topoproto.ParseTabletAlias(alias)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sm.TabletFailure = row.AsBool("tablet_failure", false)
+	sm.Progress = float32(row.AsFloat64("progress", 0))
+	sm.MigrationContext = row.AsString("migration_context", "")
+	sm.DdlAction = row.AsString("ddl_action", "")
+	sm.Message = row.AsString("message", "")
+	sm.EtaSeconds = row.AsInt64("eta_seconds", -1)
+	sm.RowsCopied = row.AsUint64("rows_copied", 0)
+	sm.TableRows = row.AsInt64("table_rows", 0)
+	sm.AddedUniqueKeys = uint32(row.AsUint64("added_unique_keys", 0))
+	sm.RemovedUniqueKeys = uint32(row.AsUint64("removed_unique_keys", 0))
+	sm.LogFile = row.AsString("log_file", "")
+	sm.ArtifactRetention = protoutil.DurationToProto(time.Second * time.Duration(row.AsInt64("retain_artifacts_seconds", 0)))
+	sm.PostponeCompletion = row.AsBool("postpone_completion", false)
+	sm.RemovedUniqueKeyNames = row.AsString("removed_unique_key_names", "")
+	sm.DroppedNoDefaultColumnNames = row.AsString("dropped_no_default_column_names", "")
+	sm.ExpandedColumnNames = row.AsString("expanded_column_names", "")
+	sm.RevertibleNotes = row.AsString("revertible_notes", "")
+	sm.AllowConcurrent = row.AsBool("allow_concurrent", false)
+	sm.RevertedUuid = row.AsString("reverted_uuid", "")
+	sm.IsView = row.AsBool("is_view", false)
+	sm.ReadyToComplete = row.AsBool("ready_to_complete", false)
+	sm.VitessLivenessIndicator = row.AsInt64("vitess_liveness_indicator", 0)
+	sm.UserThrottleRatio = float32(row.AsFloat64("user_throttle_ratio", 0))
+	sm.SpecialPlan = row.AsString("special_plan", "")
+
+	sm.LastThrottledAt, err = valueToVTTime(row.AsString("last_throttled_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.ComponentThrottled = row.AsString("component_throttled", "")
+
+	sm.CancelledAt, err = valueToVTTime(row.AsString("cancelled_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.PostponeLaunch = row.AsBool("postpone_launch", false)
+	sm.Stage = row.AsString("stage", "")
+	sm.CutoverAttempts = uint32(row.AsUint64("cutover_attempts", 0))
+	sm.IsImmediateOperation = row.AsBool("is_immediate_operation", false)
+
+	sm.ReviewedAt, err = valueToVTTime(row.AsString("reviewed_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	sm.ReadyToCompleteAt, err = valueToVTTime(row.AsString("ready_to_complete_timestamp", ""))
+	if err != nil {
+		return nil, err
+	}
+
+	return sm, nil
+}
+
+// valueToVTTime converts a MySQL TIMESTAMP value into a vttime.Time. An empty
+// value, i.e. a NULL timestamp, results in a nil time.
+func valueToVTTime(s string) (*vttime.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	gotime, err := time.ParseInLocation(sqltypes.TimestampFormat, s, time.Local)
+	if err != nil {
+		return nil, err
+	}
+
+	return protoutil.TimeToProto(gotime), nil
+}
+
+// schemaMigrationsCondition returns the WHERE condition, the ORDER BY clause
+// and the LIMIT clause for selecting the schema migrations matching the
+// request.
+func schemaMigrationsCondition(req *vtctldatapb.GetSchemaMigrationsRequest) (condition, order, skipLimit string, err error) {
+	switch {
+	case req.Uuid != "":
+		if !schema.IsOnlineDDLUUID(req.Uuid) {
+			return "", "", "", fmt.Errorf("%s is not a valid UUID", req.Uuid)
+		}
+
+		condition, err = sqlparser.ParseAndBind("migration_uuid=%a", sqltypes.StringBindVariable(req.Uuid))
+		// A UUID identifies at most one migration, so ordering and paging
+		// do not apply.
+		return condition, "", "", err
+	case req.MigrationContext != "":
+		condition, err = sqlparser.ParseAndBind("migration_context=%a", sqltypes.StringBindVariable(req.MigrationContext))
+	case req.Status != vtctldatapb.SchemaMigration_UNKNOWN:
+		condition, err = sqlparser.ParseAndBind("migration_status=%a", sqltypes.StringBindVariable(strings.ToLower(req.Status.String())))
+	case req.Recent != nil:
+		var d time.Duration
+		d, _, err = protoutil.DurationFromProto(req.Recent)
+		if err != nil {
+			return "", "", "", err
+		}
+
+		condition = fmt.Sprintf("requested_timestamp > now() - interval %0.f second", d.Seconds())
+	default:
+		condition = "migration_uuid like '%'"
+	}
+	if err != nil {
+		return "", "", "", err
+	}
+
+	order = " order by `id` "
+	switch req.Order {
+	case vtctldatapb.QueryOrdering_DESCENDING:
+		order += "DESC"
+	default:
+		order += "ASC"
+	}
+
+	if req.Limit > 0 {
+		skipLimit = fmt.Sprintf("LIMIT %v,%v", req.Skip, req.Limit)
+	}
+
+	return condition, order, skipLimit, nil
+}
+
+// selectUnresolvedTransactionsQuery returns the query to select the
+// distributed transactions, along with their participants, that were created
+// before the given time and are still recorded in the dt_state sidecar table.
+func selectUnresolvedTransactionsQuery(createdBefore time.Time) string {
+	dbname := sidecardb.GetIdentifier()
+	return fmt.Sprintf(selectUnresolvedTransactionsSQL, dbname, dbname, createdBefore.UnixNano())
+}
+
+// rowsToTransactions converts the result of selectUnresolvedTransactionsQuery
+// into TransactionMetadata protobuf messages. The rows of a transaction are
+// expected to be contiguous, one per participant.
+func rowsToTransactions(qr *sqltypes.Result) ([]*querypb.TransactionMetadata, error) {
+	var (
+		transactions []*querypb.TransactionMetadata
+		cur          *querypb.TransactionMetadata
+	)
+	for _, row := range qr.Rows {
+		dtid := row[0].ToString()
+		if cur == nil || cur.Dtid != dtid {
+			state, err := row[1].ToInt64()
+			if err != nil {
+				return nil, fmt.Errorf("error parsing state for dtid %s: %w", dtid, err)
+			}
+
+			timeCreated, err := row[2].ToInt64()
+			if err != nil {
+				return nil, fmt.Errorf("error parsing time_created for dtid %s: %w", dtid, err)
+			}
+
+			cur = &querypb.TransactionMetadata{
+				Dtid:        dtid,
+				State:       querypb.TransactionState(state),
+				TimeCreated: timeCreated,
+			}
+			transactions = append(transactions, cur)
+		}
+
+		cur.Participants = append(cur.Participants, &querypb.Target{
+			Keyspace:   row[3].ToString(),
+			Shard:      row[4].ToString(),
+			TabletType: topodatapb.TabletType_PRIMARY,
+		})
+	}
+	return transactions, nil
+}
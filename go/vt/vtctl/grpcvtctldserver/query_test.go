@@ -0,0 +1,296 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtctldserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestAlterSchemaMigrationQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		command  string
+		uuid     string
+		expected string
+	}{
+		{
+			command:  "cancel",
+			uuid:     "all",
+			expected: "alter vitess_migration cancel all",
+		},
+		{
+			command:  "complete",
+			uuid:     "ALL",
+			expected: "alter vitess_migration complete all",
+		},
+		{
+			command:  "retry",
+			uuid:     "9748c3b7_7fdb_11eb_ac2c_f875a4d24e90",
+			expected: "alter vitess_migration '9748c3b7_7fdb_11eb_ac2c_f875a4d24e90' retry",
+		},
+		{
+			command:  "cleanup",
+			uuid:     "it's",
+			expected: `alter vitess_migration 'it\'s' cleanup`,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.command+" "+test.uuid, func(t *testing.T) {
+			t.Parallel()
+
+			query, err := alterSchemaMigrationQuery(test.command, test.uuid)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, query)
+		})
+	}
+}
+
+func TestSchemaMigrationsCondition(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.GetSchemaMigrationsRequest
+		condition string
+		order     string
+		skipLimit string
+		shouldErr bool
+	}{
+		{
+			name:      "all",
+			req:       &vtctldatapb.GetSchemaMigrationsRequest{},
+			condition: "migration_uuid like '%'",
+			order:     " order by `id` ASC",
+		},
+		{
+			name: "uuid ignores ordering and paging",
+			req: &vtctldatapb.GetSchemaMigrationsRequest{
+				Uuid:  "9748c3b7_7fdb_11eb_ac2c_f875a4d24e90",
+				Order: vtctldatapb.QueryOrdering_DESCENDING,
+				Limit: 10,
+			},
+			condition: "migration_uuid='9748c3b7_7fdb_11eb_ac2c_f875a4d24e90'",
+		},
+		{
+			name: "invalid uuid",
+			req: &vtctldatapb.GetSchemaMigrationsRequest{
+				Uuid: "not-a-uuid",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "migration context",
+			req: &vtctldatapb.GetSchemaMigrationsRequest{
+				MigrationContext: "vtctl:abc",
+				Order:            vtctldatapb.QueryOrdering_DESCENDING,
+			},
+			condition: "migration_context='vtctl:abc'",
+			order:     " order by `id` DESC",
+		},
+		{
+			name: "status",
+			req: &vtctldatapb.GetSchemaMigrationsRequest{
+				Status: vtctldatapb.SchemaMigration_RUNNING,
+				Limit:  5,
+				Skip:   10,
+			},
+			condition: "migration_status='running'",
+			order:     " order by `id` ASC",
+			skipLimit: "LIMIT 10,5",
+		},
+		{
+			name: "recent",
+			req: &vtctldatapb.GetSchemaMigrationsRequest{
+				Recent: protoutil.DurationToProto(time.Hour),
+			},
+			condition: "requested_timestamp > now() - interval 3600 second",
+			order:     " order by `id` ASC",
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			condition, order, skipLimit, err := schemaMigrationsCondition(test.req)
+			if test.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.condition, condition)
+			assert.Equal(t, test.order, order)
+			assert.Equal(t, test.skipLimit, skipLimit)
+		})
+	}
+}
+
+func TestRowToSchemaMigration(t *testing.T) {
+	t.Parallel()
+
+	fields := sqltypes.MakeTestFields(
+		"migration_uuid|keyspace|shard|mysql_schema|mysql_table|strategy|migration_status|tablet|retries|postpone_completion|retain_artifacts_seconds|requested_timestamp|completed_timestamp",
+		"varchar|varchar|varchar|varchar|varchar|varchar|varchar|varchar|int64|int64|int64|timestamp|timestamp",
+	)
+
+	tests := []struct {
+		name      string
+		row       string
+		expected  *vtctldatapb.SchemaMigration
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			row:  "abc|ks|-80|vt_ks|t1|gh-ost|running|zone1-0000000100|2|1|86400|2023-01-01 00:00:00|",
+			expected: &vtctldatapb.SchemaMigration{
+				Uuid:     "abc",
+				Keyspace: "ks",
+				Shard:    "-80",
+				Schema:   "vt_ks",
+				Table:    "t1",
+				Strategy: vtctldatapb.SchemaMigration_GHOST,
+				Status:   vtctldatapb.SchemaMigration_RUNNING,
+				Tablet: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+				Retries:            2,
+				PostponeCompletion: true,
+				ArtifactRetention:  protoutil.DurationToProto(24 * time.Hour),
+				RequestedAt:        protoutil.TimeToProto(time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local)),
+				EtaSeconds:         -1,
+			},
+		},
+		{
+			name:      "bad strategy",
+			row:       "abc|ks|-80|vt_ks|t1|not-a-strategy|running||0|0|0||",
+			shouldErr: true,
+		},
+		{
+			name:      "bad status",
+			row:       "abc|ks|-80|vt_ks|t1|vitess|not-a-status||0|0|0||",
+			shouldErr: true,
+		},
+		{
+			name:      "bad timestamp",
+			row:       "abc|ks|-80|vt_ks|t1|vitess|running||0|0|0|yesterday|",
+			shouldErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			qr := sqltypes.MakeTestResult(fields, test.row)
+			sm, err := rowToSchemaMigration(qr.Named().Row())
+			if test.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, test.expected, sm)
+		})
+	}
+}
+
+func TestRowsToTransactions(t *testing.T) {
+	t.Parallel()
+
+	fields := sqltypes.MakeTestFields(
+		"dtid|state|time_created|keyspace|shard",
+		"varchar|int64|int64|varchar|varchar",
+	)
+
+	tests := []struct {
+		name      string
+		rows      []string
+		expected  []*querypb.TransactionMetadata
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			rows: []string{
+				"ks:-80:1|1|100|ks|-80",
+				"ks:-80:1|1|100|ks|80-",
+				"ks:80-:2|2|200|ks|-80",
+			},
+			expected: []*querypb.TransactionMetadata{
+				{
+					Dtid:        "ks:-80:1",
+					State:       querypb.TransactionState_PREPARE,
+					TimeCreated: 100,
+					Participants: []*querypb.Target{
+						{Keyspace: "ks", Shard: "-80", TabletType: topodatapb.TabletType_PRIMARY},
+						{Keyspace: "ks", Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY},
+					},
+				},
+				{
+					Dtid:        "ks:80-:2",
+					State:       querypb.TransactionState_COMMIT,
+					TimeCreated: 200,
+					Participants: []*querypb.Target{
+						{Keyspace: "ks", Shard: "-80", TabletType: topodatapb.TabletType_PRIMARY},
+					},
+				},
+			},
+		},
+		{
+			name: "no rows",
+		},
+		{
+			name:      "bad state",
+			rows:      []string{"ks:-80:1|prepare|100|ks|-80"},
+			shouldErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			transactions, err := rowsToTransactions(sqltypes.MakeTestResult(fields, test.rows...))
+			if test.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, test.expected, transactions)
+		})
+	}
+}
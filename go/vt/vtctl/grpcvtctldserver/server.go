@@ -38,9 +38,12 @@ import (
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/dtids"
+	"vitess.io/vitess/go/vt/grpcclient"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -57,9 +60,14 @@ import (
 	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/topotools/events"
 	"vitess.io/vitess/go/vt/vtctl/reparentutil"
+	"vitess.io/vitess/go/vt/vtctl/reparentutil/promotionrule"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtctl/workflow"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/txresolver"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
@@ -290,9 +298,42 @@ func (s *VtctldServer) ApplySchema(ctx context.Context, req *vtctldatapb.ApplySc
 		return &vtctldatapb.ApplySchemaResponse{}, err
 	}
 
-	return &vtctldatapb.ApplySchemaResponse{
-		UuidList: execResult.UUIDs,
-	}, err
+	resp = &vtctldatapb.ApplySchemaResponse{
+		UuidList:            execResult.UUIDs,
+		RowsAffectedByShard: make(map[string]uint64, len(execResult.SuccessShards)),
+	}
+	for _, shard := range execResult.SuccessShards {
+		if shard.Result == nil {
+			continue
+		}
+		resp.RowsAffectedByShard[shard.Shard] = shard.Result.RowsAffected
+	}
+	return resp, err
+}
+
+// alterSchemaMigration runs an ALTER VITESS_MIGRATION statement with the given
+// command for the migration with the given UUID (or "all") on every shard of
+// the keyspace, and returns the number of affected rows on each shard.
+func (s *VtctldServer) alterSchemaMigration(ctx context.Context, keyspace, command, uuid string) (map[string]uint64, error) {
+	if uuid == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "UUID required")
+	}
+
+	query, err := alterSchemaMigrationQuery(command, uuid)
+	if err != nil {
+		return nil, vterrors.Wrapf(err, "failed to generate %s query for %s", command, uuid)
+	}
+
+	resp, err := s.ApplySchema(ctx, &vtctldatapb.ApplySchemaRequest{
+		Keyspace:      keyspace,
+		Sql:           []string{query},
+		SkipPreflight: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.RowsAffectedByShard, nil
 }
 
 // ApplyVSchema is part of the vtctlservicepb.VtctldServer interface.
@@ -496,6 +537,27 @@ func (s *VtctldServer) backupTablet(ctx context.Context, tablet *topodatapb.Tabl
 	}
 }
 
+// CancelSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) CancelSchemaMigration(ctx context.Context, req *vtctldatapb.CancelSchemaMigrationRequest) (resp *vtctldatapb.CancelSchemaMigrationResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.CancelSchemaMigration")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("uuid", req.Uuid)
+
+	rowsAffectedByShard, err := s.alterSchemaMigration(ctx, req.Keyspace, "cancel", req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.CancelSchemaMigrationResponse{
+		RowsAffectedByShard: rowsAffectedByShard,
+	}
+	return resp, nil
+}
+
 // ChangeTabletType is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.ChangeTabletTypeRequest) (resp *vtctldatapb.ChangeTabletTypeResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.ChangeTabletType")
@@ -573,6 +635,28 @@ func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.Ch
 	// Since we want to check the durability rules for the desired state and not before we make that change
 	expectedTablet := proto.Clone(tablet.Tablet).(*topodatapb.Tablet)
 	expectedTablet.Type = req.DbType
+
+	// A new replica must not give the primary a second tablet able to send semi-sync acks in a cell when the
+	// durability policy requires the acks to come from distinct cells.
+	if reparentutil.SemiSyncAckerCells(durability, shardPrimary.Tablet) > 0 && reparentutil.IsReplicaSemiSync(durability, shardPrimary.Tablet, expectedTablet) {
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, tablet.Keyspace, tablet.Shard)
+		if err != nil {
+			return nil, err
+		}
+
+		tablets := []*topodatapb.Tablet{expectedTablet}
+		for alias, ti := range tabletMap {
+			if alias != topoproto.TabletAliasString(req.TabletAlias) {
+				tablets = append(tablets, ti.Tablet)
+			}
+		}
+
+		if err = reparentutil.CheckSemiSyncAckerCells(durability, shardPrimary.Tablet, tablets); err != nil {
+			err = vterrors.Wrapf(err, "cannot change tablet %v to %v", topoproto.TabletAliasString(req.TabletAlias), req.DbType)
+			return nil, err
+		}
+	}
+
 	err = s.tmc.ChangeType(ctx, tablet.Tablet, req.DbType, reparentutil.IsReplicaSemiSync(durability, shardPrimary.Tablet, expectedTablet))
 	if err != nil {
 		return nil, err
@@ -594,6 +678,97 @@ func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.Ch
 	}, nil
 }
 
+// CleanupSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) CleanupSchemaMigration(ctx context.Context, req *vtctldatapb.CleanupSchemaMigrationRequest) (resp *vtctldatapb.CleanupSchemaMigrationResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.CleanupSchemaMigration")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("uuid", req.Uuid)
+
+	rowsAffectedByShard, err := s.alterSchemaMigration(ctx, req.Keyspace, "cleanup", req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.CleanupSchemaMigrationResponse{
+		RowsAffectedByShard: rowsAffectedByShard,
+	}
+	return resp, nil
+}
+
+// CompleteSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) CompleteSchemaMigration(ctx context.Context, req *vtctldatapb.CompleteSchemaMigrationRequest) (resp *vtctldatapb.CompleteSchemaMigrationResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.CompleteSchemaMigration")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("uuid", req.Uuid)
+
+	rowsAffectedByShard, err := s.alterSchemaMigration(ctx, req.Keyspace, "complete", req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.CompleteSchemaMigrationResponse{
+		RowsAffectedByShard: rowsAffectedByShard,
+	}
+	return resp, nil
+}
+
+// ConcludeTransaction is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ConcludeTransaction(ctx context.Context, req *vtctldatapb.ConcludeTransactionRequest) (resp *vtctldatapb.ConcludeTransactionResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ConcludeTransaction")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("dtid", req.Dtid)
+
+	if _, err = dtids.ShardSession(req.Dtid); err != nil {
+		return nil, err
+	}
+
+	if err = txresolver.Resolve(ctx, s.primaryQueryService(), req.Dtid); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.ConcludeTransactionResponse{}, nil
+}
+
+// primaryQueryService returns a QueryService that sends every request to the
+// current primary tablet of the request's target shard.
+func (s *VtctldServer) primaryQueryService() queryservice.QueryService {
+	return queryservice.Wrap(nil, func(ctx context.Context, target *querypb.Target, _ queryservice.QueryService, name string, _ bool, inner func(context.Context, *querypb.Target, queryservice.QueryService) (bool, error)) error {
+		si, err := s.ts.GetShard(ctx, target.Keyspace, target.Shard)
+		if err != nil {
+			return err
+		}
+
+		if !si.HasPrimary() {
+			return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "shard %s/%s has no primary", target.Keyspace, target.Shard)
+		}
+
+		ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+		if err != nil {
+			return err
+		}
+
+		conn, err := tabletconn.GetDialer()(ti.Tablet, grpcclient.FailFast(false))
+		if err != nil {
+			return err
+		}
+		defer conn.Close(ctx)
+
+		_, err = inner(ctx, target, conn)
+		return vterrors.Wrapf(err, "%s on %s", name, topoproto.TabletAliasString(ti.Alias))
+	})
+}
+
 // CreateKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CreateKeyspace(ctx context.Context, req *vtctldatapb.CreateKeyspaceRequest) (resp *vtctldatapb.CreateKeyspaceResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CreateKeyspace")
@@ -1445,6 +1620,86 @@ func (s *VtctldServer) GetSchema(ctx context.Context, req *vtctldatapb.GetSchema
 	}, nil
 }
 
+// GetSchemaMigrations is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetSchemaMigrations(ctx context.Context, req *vtctldatapb.GetSchemaMigrationsRequest) (resp *vtctldatapb.GetSchemaMigrationsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetSchemaMigrations")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("uuid", req.Uuid)
+	span.Annotate("migration_context", req.MigrationContext)
+	span.Annotate("status", req.Status.String())
+	span.Annotate("order", req.Order.String())
+	span.Annotate("limit", req.Limit)
+	span.Annotate("skip", req.Skip)
+
+	condition, order, skipLimit, err := schemaMigrationsCondition(req)
+	if err != nil {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "%v", err)
+		return nil, err
+	}
+	query := selectSchemaMigrationsQuery(condition, order, skipLimit)
+
+	tabletsResp, err := s.GetTablets(ctx, &vtctldatapb.GetTabletsRequest{
+		Keyspace:   req.Keyspace,
+		TabletType: topodatapb.TabletType_PRIMARY,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m          sync.Mutex
+		wg         sync.WaitGroup
+		rec        concurrency.AllErrorRecorder
+		migrations []*vtctldatapb.SchemaMigration
+	)
+
+	for _, tablet := range tabletsResp.Tablets {
+		wg.Add(1)
+		go func(tablet *topodatapb.Tablet) {
+			defer wg.Done()
+
+			qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+				Query:   []byte(query),
+				MaxRows: 10_000,
+			})
+			if err != nil {
+				rec.RecordError(vterrors.Wrapf(err, "failed to fetch schema migrations from %s", topoproto.TabletAliasString(tablet.Alias)))
+				return
+			}
+
+			shardMigrations := make([]*vtctldatapb.SchemaMigration, 0, len(qr.Rows))
+			for _, row := range sqltypes.Proto3ToResult(qr).Named().Rows {
+				sm, err := rowToSchemaMigration(row)
+				if err != nil {
+					rec.RecordError(vterrors.Wrapf(err, "failed to parse schema migration from %s", topoproto.TabletAliasString(tablet.Alias)))
+					return
+				}
+				shardMigrations = append(shardMigrations, sm)
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			migrations = append(migrations, shardMigrations...)
+		}(tablet)
+	}
+
+	wg.Wait()
+
+	if rec.HasErrors() {
+		err = rec.Error()
+		return nil, err
+	}
+
+	resp = &vtctldatapb.GetSchemaMigrationsResponse{
+		Migrations: migrations,
+	}
+	return resp, nil
+}
+
 // GetShard is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) GetShard(ctx context.Context, req *vtctldatapb.GetShardRequest) (resp *vtctldatapb.GetShardResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetShard")
@@ -1551,6 +1806,24 @@ func (s *VtctldServer) GetSrvKeyspaces(ctx context.Context, req *vtctldatapb.Get
 	}, nil
 }
 
+// validateThrottlerConfigMetrics checks that the named metrics of a throttler config can be collected, and
+// that apps are only checked against metrics that are collected.
+func validateThrottlerConfigMetrics(throttlerConfig *topodatapb.ThrottlerConfig) error {
+	for metricName, metric := range throttlerConfig.Metrics {
+		if _, ok := base.BuiltinMetricQueries[metricName]; !ok && metric.Query == "" {
+			return fmt.Errorf("metric %s is not a built-in metric and requires a query", metricName)
+		}
+	}
+	for appName, appMetrics := range throttlerConfig.AppCheckedMetrics {
+		for _, metricName := range appMetrics.Names {
+			if _, ok := throttlerConfig.Metrics[metricName]; !ok && metricName != base.LagMetricName {
+				return fmt.Errorf("app %s is checked against unknown metric %s", appName, metricName)
+			}
+		}
+	}
+	return nil
+}
+
 // UpdateThrottlerConfig updates throttler config for all cells
 func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldatapb.UpdateThrottlerConfigRequest) (resp *vtctldatapb.UpdateThrottlerConfigResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.UpdateThrottlerConfig")
@@ -1564,6 +1837,16 @@ func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldata
 	if req.CheckAsCheckSelf && req.CheckAsCheckShard {
 		return nil, fmt.Errorf("--check-as-check-self and --check-as-check-shard are mutually exclusive")
 	}
+	if req.MetricName == "" {
+		if req.MetricThreshold != 0 || req.MetricQuery != "" || req.RemoveMetric {
+			return nil, fmt.Errorf("--metric-threshold, --metric-query and --remove-metric require --metric-name")
+		}
+	} else if err := base.ValidateMetricName(req.MetricName); err != nil {
+		return nil, err
+	}
+	if req.AppName == "" && len(req.AppCheckedMetrics) > 0 {
+		return nil, fmt.Errorf("--app-checked-metrics requires --app-name")
+	}
 
 	update := func(throttlerConfig *topodatapb.ThrottlerConfig) *topodatapb.ThrottlerConfig {
 		if throttlerConfig == nil {
@@ -1591,6 +1874,41 @@ func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldata
 		if req.CheckAsCheckShard {
 			throttlerConfig.CheckAsCheckSelf = false
 		}
+		if req.MetricName != "" {
+			if req.RemoveMetric {
+				delete(throttlerConfig.Metrics, req.MetricName)
+			} else {
+				if throttlerConfig.Metrics == nil {
+					throttlerConfig.Metrics = make(map[string]*topodatapb.ThrottlerConfig_Metric)
+				}
+				metric := &topodatapb.ThrottlerConfig_Metric{
+					Threshold: req.MetricThreshold,
+					Query:     req.MetricQuery,
+				}
+				if existing, ok := throttlerConfig.Metrics[req.MetricName]; ok {
+					// keep the query or the threshold of the metric when only the other one is updated
+					if metric.Query == "" {
+						metric.Query = existing.Query
+					}
+					if metric.Threshold == 0 {
+						metric.Threshold = existing.Threshold
+					}
+				}
+				throttlerConfig.Metrics[req.MetricName] = metric
+			}
+		}
+		if req.AppName != "" {
+			if len(req.AppCheckedMetrics) == 0 {
+				delete(throttlerConfig.AppCheckedMetrics, req.AppName)
+			} else {
+				if throttlerConfig.AppCheckedMetrics == nil {
+					throttlerConfig.AppCheckedMetrics = make(map[string]*topodatapb.ThrottlerConfig_AppCheckedMetrics)
+				}
+				throttlerConfig.AppCheckedMetrics[req.AppName] = &topodatapb.ThrottlerConfig_AppCheckedMetrics{
+					Names: req.AppCheckedMetrics,
+				}
+			}
+		}
 		return throttlerConfig
 	}
 
@@ -1606,6 +1924,15 @@ func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldata
 	}
 
 	ki.ThrottlerConfig = update(ki.ThrottlerConfig)
+	if req.MetricName != "" && !req.RemoveMetric {
+		// a zero threshold throttles every check of the metric
+		if threshold := ki.ThrottlerConfig.Metrics[req.MetricName].Threshold; threshold <= 0 {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "metric %s requires a positive --metric-threshold, got %v", req.MetricName, threshold)
+		}
+	}
+	if err := validateThrottlerConfigMetrics(ki.ThrottlerConfig); err != nil {
+		return nil, err
+	}
 
 	err = s.ts.UpdateKeyspace(ctx, ki)
 	if err != nil {
@@ -1911,6 +2238,88 @@ func (s *VtctldServer) GetTopologyPath(ctx context.Context, req *vtctldatapb.Get
 	}, nil
 }
 
+// GetUnresolvedTransactions is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetUnresolvedTransactions(ctx context.Context, req *vtctldatapb.GetUnresolvedTransactionsRequest) (resp *vtctldatapb.GetUnresolvedTransactionsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetUnresolvedTransactions")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+
+	abandonAge, _, err := protoutil.DurationFromProto(req.AbandonAge)
+	if err != nil {
+		err = vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "%v", err)
+		return nil, err
+	}
+
+	span.Annotate("abandon_age", abandonAge.String())
+
+	query := selectUnresolvedTransactionsQuery(time.Now().Add(-abandonAge))
+
+	tabletsResp, err := s.GetTablets(ctx, &vtctldatapb.GetTabletsRequest{
+		Keyspace:   req.Keyspace,
+		TabletType: topodatapb.TabletType_PRIMARY,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m            sync.Mutex
+		wg           sync.WaitGroup
+		rec          concurrency.AllErrorRecorder
+		transactions []*querypb.TransactionMetadata
+	)
+
+	for _, tablet := range tabletsResp.Tablets {
+		wg.Add(1)
+		go func(tablet *topodatapb.Tablet) {
+			defer wg.Done()
+
+			qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+				Query:   []byte(query),
+				MaxRows: 10_000,
+			})
+			if err != nil {
+				rec.RecordError(vterrors.Wrapf(err, "failed to fetch unresolved transactions from %s", topoproto.TabletAliasString(tablet.Alias)))
+				return
+			}
+
+			shardTransactions, err := rowsToTransactions(sqltypes.Proto3ToResult(qr))
+			if err != nil {
+				rec.RecordError(vterrors.Wrapf(err, "failed to parse unresolved transactions from %s", topoproto.TabletAliasString(tablet.Alias)))
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			transactions = append(transactions, shardTransactions...)
+		}(tablet)
+	}
+
+	wg.Wait()
+
+	if rec.HasErrors() {
+		err = rec.Error()
+		return nil, err
+	}
+
+	// Oldest transactions first, as they are the most likely to need
+	// attention.
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].TimeCreated != transactions[j].TimeCreated {
+			return transactions[i].TimeCreated < transactions[j].TimeCreated
+		}
+		return transactions[i].Dtid < transactions[j].Dtid
+	})
+
+	resp = &vtctldatapb.GetUnresolvedTransactionsResponse{
+		Transactions: transactions,
+	}
+	return resp, nil
+}
+
 // GetVersion returns the version of a tablet from its debug vars
 func (s *VtctldServer) GetVersion(ctx context.Context, req *vtctldatapb.GetVersionRequest) (resp *vtctldatapb.GetVersionResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVersion")
@@ -2243,6 +2652,218 @@ func (s *VtctldServer) InitShardPrimaryLocked(
 	return nil
 }
 
+// LaunchSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) LaunchSchemaMigration(ctx context.Context, req *vtctldatapb.LaunchSchemaMigrationRequest) (resp *vtctldatapb.LaunchSchemaMigrationResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.LaunchSchemaMigration")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("uuid", req.Uuid)
+
+	rowsAffectedByShard, err := s.alterSchemaMigration(ctx, req.Keyspace, "launch", req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.LaunchSchemaMigrationResponse{
+		RowsAffectedByShard: rowsAffectedByShard,
+	}
+	return resp, nil
+}
+
+// LookupVindexCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) LookupVindexCreate(ctx context.Context, req *vtctldatapb.LookupVindexCreateRequest) (resp *vtctldatapb.LookupVindexCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.LookupVindexCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("continue_after_copy_with_owner", req.ContinueAfterCopyWithOwner)
+
+	resp, err = s.ws.LookupVindexCreate(ctx, req)
+	return resp, err
+}
+
+// LookupVindexExternalize is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) LookupVindexExternalize(ctx context.Context, req *vtctldatapb.LookupVindexExternalizeRequest) (resp *vtctldatapb.LookupVindexExternalizeResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.LookupVindexExternalize")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+
+	resp, err = s.ws.LookupVindexExternalize(ctx, req)
+	return resp, err
+}
+
+// LookupVindexStatus is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) LookupVindexStatus(ctx context.Context, req *vtctldatapb.LookupVindexStatusRequest) (resp *vtctldatapb.LookupVindexStatusResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.LookupVindexStatus")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+
+	resp, err = s.ws.LookupVindexStatus(ctx, req)
+	return resp, err
+}
+
+// MaterializeCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MaterializeCreate(ctx context.Context, req *vtctldatapb.MaterializeCreateRequest) (resp *vtctldatapb.MaterializeCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MaterializeCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	if req.Settings != nil {
+		span.Annotate("keyspace", req.Settings.TargetKeyspace)
+		span.Annotate("workflow", req.Settings.Workflow)
+		span.Annotate("source_keyspace", req.Settings.SourceKeyspace)
+	}
+
+	resp, err = s.ws.MaterializeCreate(ctx, req)
+	return resp, err
+}
+
+// MigrateCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MigrateCreate(ctx context.Context, req *vtctldatapb.MigrateCreateRequest) (resp *vtctldatapb.MigrateCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MigrateCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("source_keyspace", req.SourceKeyspace)
+	span.Annotate("mount_name", req.MountName)
+
+	resp, err = s.ws.MigrateCreate(ctx, req)
+	return resp, err
+}
+
+// MountList is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MountList(ctx context.Context, req *vtctldatapb.MountListRequest) (resp *vtctldatapb.MountListResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MountList")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	names, err := s.ts.GetExternalVitessClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.MountListResponse{Names: names}, nil
+}
+
+// MountRegister is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MountRegister(ctx context.Context, req *vtctldatapb.MountRegisterRequest) (resp *vtctldatapb.MountRegisterResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MountRegister")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+	span.Annotate("topo_type", req.TopoType)
+	span.Annotate("topo_server", req.TopoServer)
+	span.Annotate("topo_root", req.TopoRoot)
+
+	if req.Name == "" {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "the name of the external cluster must be provided")
+	}
+	vci, err := s.ts.GetExternalVitessCluster(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if vci != nil {
+		return nil, vterrors.Errorf(vtrpc.Code_ALREADY_EXISTS, "there is already a vitess cluster named %s", req.Name)
+	}
+	vc := &topodatapb.ExternalVitessCluster{
+		TopoConfig: &topodatapb.TopoConfig{
+			TopoType: req.TopoType,
+			Server:   req.TopoServer,
+			Root:     req.TopoRoot,
+		},
+	}
+	if err := s.ts.CreateExternalVitessCluster(ctx, req.Name, vc); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.MountRegisterResponse{}, nil
+}
+
+// MountShow is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MountShow(ctx context.Context, req *vtctldatapb.MountShowRequest) (resp *vtctldatapb.MountShowResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MountShow")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+
+	vci, err := s.ts.GetExternalVitessCluster(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if vci == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "there is no vitess cluster named %s", req.Name)
+	}
+	resp = &vtctldatapb.MountShowResponse{Name: req.Name}
+	if tc := vci.ExternalVitessCluster.GetTopoConfig(); tc != nil {
+		resp.TopoType = tc.TopoType
+		resp.TopoServer = tc.Server
+		resp.TopoRoot = tc.Root
+	}
+	return resp, nil
+}
+
+// MountUnregister is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MountUnregister(ctx context.Context, req *vtctldatapb.MountUnregisterRequest) (resp *vtctldatapb.MountUnregisterResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MountUnregister")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("name", req.Name)
+
+	vci, err := s.ts.GetExternalVitessCluster(ctx, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	if vci == nil {
+		return nil, vterrors.Errorf(vtrpc.Code_NOT_FOUND, "there is no vitess cluster named %s", req.Name)
+	}
+	if err := s.ts.DeleteExternalVitessCluster(ctx, req.Name); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.MountUnregisterResponse{}, nil
+}
+
+// MoveTablesCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) MoveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTablesCreateRequest) (resp *vtctldatapb.MoveTablesCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.MoveTablesCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("source_keyspace", req.SourceKeyspace)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("on_ddl", req.OnDdl)
+
+	resp, err = s.ws.MoveTablesCreate(ctx, req)
+	return resp, err
+}
+
 // PingTablet is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) PingTablet(ctx context.Context, req *vtctldatapb.PingTabletRequest) (resp *vtctldatapb.PingTabletResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.PingTablet")
@@ -2702,6 +3323,25 @@ func (s *VtctldServer) ReparentTablet(ctx context.Context, req *vtctldatapb.Repa
 	}, nil
 }
 
+// ReshardCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCreateRequest) (resp *vtctldatapb.ReshardCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ReshardCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("source_shards", req.SourceShards)
+	span.Annotate("target_shards", req.TargetShards)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("on_ddl", req.OnDdl)
+
+	resp, err = s.ws.ReshardCreate(ctx, req)
+	return resp, err
+}
+
 func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupRequest, stream vtctlservicepb.Vtctld_RestoreFromBackupServer) (err error) {
 	span, ctx := trace.NewSpan(stream.Context(), "VtctldServer.RestoreFromBackup")
 	defer span.Finish()
@@ -2778,6 +3418,27 @@ func (s *VtctldServer) RestoreFromBackup(req *vtctldatapb.RestoreFromBackupReque
 	}
 }
 
+// RetrySchemaMigration is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) RetrySchemaMigration(ctx context.Context, req *vtctldatapb.RetrySchemaMigrationRequest) (resp *vtctldatapb.RetrySchemaMigrationResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.RetrySchemaMigration")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("uuid", req.Uuid)
+
+	rowsAffectedByShard, err := s.alterSchemaMigration(ctx, req.Keyspace, "retry", req.Uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	resp = &vtctldatapb.RetrySchemaMigrationResponse{
+		RowsAffectedByShard: rowsAffectedByShard,
+	}
+	return resp, nil
+}
+
 // RunHealthCheck is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) RunHealthCheck(ctx context.Context, req *vtctldatapb.RunHealthCheckRequest) (resp *vtctldatapb.RunHealthCheckResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.RunHealthCheck")
@@ -2829,6 +3490,41 @@ func (s *VtctldServer) SetKeyspaceDurabilityPolicy(ctx context.Context, req *vtc
 		return nil, err
 	}
 
+	durability, err := reparentutil.GetDurabilityPolicy(req.DurabilityPolicy)
+	if err != nil {
+		return nil, err
+	}
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	// A policy requiring the semi-sync acks to come from distinct cells can't be set while a cell of a shard has
+	// several tablets able to send them for one of the tablets which could be promoted.
+	for _, shard := range shards {
+		tabletMap, err := s.ts.GetTabletMapForShard(ctx, req.Keyspace, shard)
+		if err != nil {
+			return nil, err
+		}
+
+		tablets := make([]*topodatapb.Tablet, 0, len(tabletMap))
+		for _, ti := range tabletMap {
+			tablets = append(tablets, ti.Tablet)
+		}
+
+		for _, tablet := range tablets {
+			if reparentutil.PromotionRule(durability, tablet) == promotionrule.MustNot {
+				continue
+			}
+
+			if err = reparentutil.CheckSemiSyncAckerCells(durability, tablet, tablets); err != nil {
+				err = vterrors.Wrapf(err, "cannot set durability policy <%v> on shard %v/%v", req.DurabilityPolicy, req.Keyspace, shard)
+				return nil, err
+			}
+		}
+	}
+
 	ki.DurabilityPolicy = req.DurabilityPolicy
 
 	err = s.ts.UpdateKeyspace(ctx, ki)
@@ -4342,6 +5038,168 @@ func (s *VtctldServer) ValidateVSchema(ctx context.Context, req *vtctldatapb.Val
 	return resp, err
 }
 
+// VDiffCreate is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffCreate(ctx context.Context, req *vtctldatapb.VDiffCreateRequest) (resp *vtctldatapb.VDiffCreateResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffCreate")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("uuid", req.Uuid)
+	span.Annotate("source_cells", req.SourceCells)
+	span.Annotate("target_cells", req.TargetCells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("tables", req.Tables)
+
+	resp, err = s.ws.VDiffCreate(ctx, req)
+	return resp, err
+}
+
+// VDiffDelete is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffDelete(ctx context.Context, req *vtctldatapb.VDiffDeleteRequest) (resp *vtctldatapb.VDiffDeleteResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffDelete")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("argument", req.Arg)
+
+	resp, err = s.ws.VDiffDelete(ctx, req)
+	return resp, err
+}
+
+// VDiffResume is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffResume(ctx context.Context, req *vtctldatapb.VDiffResumeRequest) (resp *vtctldatapb.VDiffResumeResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffResume")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("uuid", req.Uuid)
+
+	resp, err = s.ws.VDiffResume(ctx, req)
+	return resp, err
+}
+
+// VDiffShow is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffShow(ctx context.Context, req *vtctldatapb.VDiffShowRequest) (resp *vtctldatapb.VDiffShowResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffShow")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("argument", req.Arg)
+
+	resp, err = s.ws.VDiffShow(ctx, req)
+	return resp, err
+}
+
+// VDiffStop is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) VDiffStop(ctx context.Context, req *vtctldatapb.VDiffStopRequest) (resp *vtctldatapb.VDiffStopResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.VDiffStop")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("uuid", req.Uuid)
+
+	resp, err = s.ws.VDiffStop(ctx, req)
+	return resp, err
+}
+
+// WorkflowCancel is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowCancel(ctx context.Context, req *vtctldatapb.WorkflowCancelRequest) (resp *vtctldatapb.WorkflowCancelResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowCancel")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("keep_data", req.KeepData)
+	span.Annotate("keep_routing_rules", req.KeepRoutingRules)
+	span.Annotate("dry_run", req.DryRun)
+
+	resp, err = s.ws.WorkflowCancel(ctx, req)
+	return resp, err
+}
+
+// WorkflowComplete is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowComplete(ctx context.Context, req *vtctldatapb.WorkflowCompleteRequest) (resp *vtctldatapb.WorkflowCompleteResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowComplete")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("keep_data", req.KeepData)
+	span.Annotate("keep_routing_rules", req.KeepRoutingRules)
+	span.Annotate("rename_tables", req.RenameTables)
+	span.Annotate("dry_run", req.DryRun)
+
+	resp, err = s.ws.WorkflowComplete(ctx, req)
+	return resp, err
+}
+
+// WorkflowReverseTraffic is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowReverseTraffic(ctx context.Context, req *vtctldatapb.WorkflowReverseTrafficRequest) (resp *vtctldatapb.WorkflowReverseTrafficResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowReverseTraffic")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("dry_run", req.DryRun)
+
+	resp, err = s.ws.WorkflowReverseTraffic(ctx, req)
+	return resp, err
+}
+
+// WorkflowStatus is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowStatusRequest) (resp *vtctldatapb.WorkflowStatusResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowStatus")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+
+	resp, err = s.ws.WorkflowStatus(ctx, req)
+	return resp, err
+}
+
+// WorkflowSwitchTraffic is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.WorkflowSwitchTrafficRequest) (resp *vtctldatapb.WorkflowSwitchTrafficResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowSwitchTraffic")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("dry_run", req.DryRun)
+
+	resp, err = s.ws.WorkflowSwitchTraffic(ctx, req)
+	return resp, err
+}
+
 // WorkflowUpdate is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) WorkflowUpdate(ctx context.Context, req *vtctldatapb.WorkflowUpdateRequest) (resp *vtctldatapb.WorkflowUpdateResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.WorkflowUpdate")
@@ -44,6 +44,7 @@ import (
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/grpcvtctldserver/testutil"
 	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
+	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
@@ -56,6 +57,7 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/proto/vttime"
 )
 
@@ -1306,6 +1308,128 @@ func TestChangeTabletType(t *testing.T) {
 		})
 		assert.Error(t, err)
 	})
+
+	t.Run("cross cell quorum", func(t *testing.T) {
+		t.Parallel()
+
+		ctx := context.Background()
+		ts := memorytopo.NewServer("zone1", "zone2")
+		vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &testutil.TabletManagerClient{
+			TopoServer: ts,
+		}, func(ts *topo.Server) vtctlservicepb.VtctldServer { return NewVtctldServer(ts) })
+
+		testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+			Name: "ks",
+			Keyspace: &topodatapb.Keyspace{
+				DurabilityPolicy: "cross_cell_quorum:1",
+			},
+		})
+		testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+			AlsoSetShardPrimary: true,
+		}, &topodatapb.Tablet{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  100,
+			},
+			Keyspace: "ks",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_PRIMARY,
+		}, &topodatapb.Tablet{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  101,
+			},
+			Keyspace: "ks",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_RDONLY,
+		}, &topodatapb.Tablet{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone2",
+				Uid:  200,
+			},
+			Keyspace: "ks",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_REPLICA,
+		}, &topodatapb.Tablet{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone2",
+				Uid:  201,
+			},
+			Keyspace: "ks",
+			Shard:    "0",
+			Type:     topodatapb.TabletType_RDONLY,
+		})
+
+		// A second replica in the cell of the primary doesn't send semi-sync acks.
+		_, err := vtctld.ChangeTabletType(ctx, &vtctldatapb.ChangeTabletTypeRequest{
+			TabletAlias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  101,
+			},
+			DbType: topodatapb.TabletType_REPLICA,
+		})
+		assert.NoError(t, err)
+
+		_, err = vtctld.ChangeTabletType(ctx, &vtctldatapb.ChangeTabletTypeRequest{
+			TabletAlias: &topodatapb.TabletAlias{
+				Cell: "zone2",
+				Uid:  201,
+			},
+			DbType: topodatapb.TabletType_REPLICA,
+		})
+		assert.EqualError(t, err, "cannot change tablet zone2-0000000201 to REPLICA: cell zone2 has more than one tablet able to send semi-sync acks for zone1-0000000100, the acks could not come from distinct cells")
+
+		tablet, err := ts.GetTablet(ctx, &topodatapb.TabletAlias{
+			Cell: "zone2",
+			Uid:  201,
+		})
+		require.NoError(t, err)
+		assert.Equal(t, topodatapb.TabletType_RDONLY, tablet.Type)
+	})
+}
+
+func TestConcludeTransaction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		dtid string
+	}{
+		{
+			name: "invalid dtid",
+			dtid: "not-a-dtid",
+		},
+		{
+			name: "no such shard",
+			dtid: "testkeyspace:-80:1234",
+		},
+		{
+			name: "shard without primary",
+			dtid: "testkeyspace:80-:1234",
+		},
+	}
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+	testutil.AddShards(ctx, t, ts, &vtctldatapb.Shard{
+		Keyspace: "testkeyspace",
+		Name:     "80-",
+	})
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &testutil.TabletManagerClient{}, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := vtctld.ConcludeTransaction(ctx, &vtctldatapb.ConcludeTransactionRequest{
+				Dtid: tt.dtid,
+			})
+			assert.Error(t, err)
+		})
+	}
 }
 
 func TestCreateKeyspace(t *testing.T) {
@@ -4806,6 +4930,138 @@ func TestGetSchema(t *testing.T) {
 	}
 }
 
+func TestGetSchemaMigrations(t *testing.T) {
+	t.Parallel()
+
+	fields := sqltypes.MakeTestFields(
+		"migration_uuid|keyspace|shard|mysql_table|strategy|migration_status|tablet|requested_timestamp|completed_timestamp|retries",
+		"varchar|varchar|varchar|varchar|varchar|varchar|varchar|timestamp|timestamp|int64",
+	)
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  100,
+			},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  200,
+			},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  101,
+			},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_REPLICA,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		tmc       *testutil.TabletManagerClient
+		req       *vtctldatapb.GetSchemaMigrationsRequest
+		expected  []string
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields,
+							"9748c3b7_7fdb_11eb_ac2c_f875a4d24e90|testkeyspace|-80|t1|vitess|complete|zone1-0000000100|2023-01-01 00:00:00|2023-01-01 00:01:00|0",
+						)),
+					},
+					"zone1-0000000200": {
+						Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields,
+							"9748c3b7_7fdb_11eb_ac2c_f875a4d24e90|testkeyspace|80-|t1|vitess|running|zone1-0000000200|2023-01-01 00:00:00||1",
+						)),
+					},
+				},
+			},
+			req: &vtctldatapb.GetSchemaMigrationsRequest{
+				Keyspace: "testkeyspace",
+			},
+			expected: []string{"-80/COMPLETE", "80-/RUNNING"},
+		},
+		{
+			name: "invalid uuid",
+			tmc:  &testutil.TabletManagerClient{},
+			req: &vtctldatapb.GetSchemaMigrationsRequest{
+				Keyspace: "testkeyspace",
+				Uuid:     "not-a-uuid",
+			},
+			shouldErr: true,
+		},
+		{
+			name: "query error",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields)),
+					},
+					"zone1-0000000200": {
+						Error: assert.AnError,
+					},
+				},
+			},
+			req: &vtctldatapb.GetSchemaMigrationsRequest{
+				Keyspace: "testkeyspace",
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ts := memorytopo.NewServer("zone1")
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+				AlsoSetShardPrimary: true,
+			}, tablets...)
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+			resp, err := vtctld.GetSchemaMigrations(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var migrations []string
+			for _, sm := range resp.Migrations {
+				migrations = append(migrations, sm.Shard+"/"+sm.Status.String())
+				assert.Equal(t, vtctldatapb.SchemaMigration_VITESS, sm.Strategy)
+			}
+			sort.Strings(migrations)
+			assert.Equal(t, tt.expected, migrations)
+		})
+	}
+}
+
 func TestGetShard(t *testing.T) {
 	t.Parallel()
 
@@ -6048,6 +6304,119 @@ func TestGetTopologyPath(t *testing.T) {
 	}
 }
 
+func TestGetUnresolvedTransactions(t *testing.T) {
+	t.Parallel()
+
+	fields := sqltypes.MakeTestFields(
+		"dtid|state|time_created|keyspace|shard",
+		"varchar|int64|int64|varchar|varchar",
+	)
+	tablets := []*topodatapb.Tablet{
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  100,
+			},
+			Keyspace: "testkeyspace",
+			Shard:    "-80",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+		{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone1",
+				Uid:  200,
+			},
+			Keyspace: "testkeyspace",
+			Shard:    "80-",
+			Type:     topodatapb.TabletType_PRIMARY,
+		},
+	}
+
+	tests := []struct {
+		name      string
+		tmc       *testutil.TabletManagerClient
+		expected  []string
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields,
+							"testkeyspace:-80:2|2|200|testkeyspace|-80",
+							"testkeyspace:-80:2|2|200|testkeyspace|80-",
+						)),
+					},
+					"zone1-0000000200": {
+						Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields,
+							"testkeyspace:80-:1|1|100|testkeyspace|80-",
+							"testkeyspace:80-:1|1|100|testkeyspace|-80",
+						)),
+					},
+				},
+			},
+			expected: []string{
+				"testkeyspace:80-:1/PREPARE/2",
+				"testkeyspace:-80:2/COMMIT/2",
+			},
+		},
+		{
+			name: "query error",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields)),
+					},
+					"zone1-0000000200": {
+						Error: assert.AnError,
+					},
+				},
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			ts := memorytopo.NewServer("zone1")
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+				AlsoSetShardPrimary: true,
+			}, tablets...)
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+			resp, err := vtctld.GetUnresolvedTransactions(ctx, &vtctldatapb.GetUnresolvedTransactionsRequest{
+				Keyspace:   "testkeyspace",
+				AbandonAge: protoutil.DurationToProto(time.Minute),
+			})
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+
+			var transactions []string
+			for _, tx := range resp.Transactions {
+				transactions = append(transactions, fmt.Sprintf("%s/%s/%d", tx.Dtid, tx.State, len(tx.Participants)))
+			}
+			assert.Equal(t, tt.expected, transactions)
+		})
+	}
+}
+
 func TestGetVSchema(t *testing.T) {
 	t.Parallel()
 
@@ -6098,6 +6467,57 @@ func TestGetVSchema(t *testing.T) {
 	})
 }
 
+func TestMount(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	ts := memorytopo.NewServer("cell1")
+	vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+		return NewVtctldServer(ts)
+	})
+
+	listResp, err := vtctld.MountList(ctx, &vtctldatapb.MountListRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, listResp.Names)
+
+	_, err = vtctld.MountRegister(ctx, &vtctldatapb.MountRegisterRequest{
+		Name:       "ext1",
+		TopoType:   "etcd2",
+		TopoServer: "localhost:12379",
+		TopoRoot:   "/vitess/global",
+	})
+	require.NoError(t, err)
+
+	_, err = vtctld.MountRegister(ctx, &vtctldatapb.MountRegisterRequest{
+		Name:       "ext1",
+		TopoType:   "etcd2",
+		TopoServer: "localhost:12379",
+		TopoRoot:   "/vitess/global",
+	})
+	assert.ErrorContains(t, err, "there is already a vitess cluster named ext1")
+
+	showResp, err := vtctld.MountShow(ctx, &vtctldatapb.MountShowRequest{Name: "ext1"})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.MountShowResponse{
+		Name:       "ext1",
+		TopoType:   "etcd2",
+		TopoServer: "localhost:12379",
+		TopoRoot:   "/vitess/global",
+	}, showResp)
+
+	listResp, err = vtctld.MountList(ctx, &vtctldatapb.MountListRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ext1"}, listResp.Names)
+
+	_, err = vtctld.MountUnregister(ctx, &vtctldatapb.MountUnregisterRequest{Name: "ext1"})
+	require.NoError(t, err)
+
+	_, err = vtctld.MountShow(ctx, &vtctldatapb.MountShowRequest{Name: "ext1"})
+	assert.ErrorContains(t, err, "there is no vitess cluster named ext1")
+	_, err = vtctld.MountUnregister(ctx, &vtctldatapb.MountUnregisterRequest{Name: "ext1"})
+	assert.ErrorContains(t, err, "there is no vitess cluster named ext1")
+}
+
 func TestPingTablet(t *testing.T) {
 	t.Parallel()
 
@@ -8506,6 +8926,7 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 	tests := []struct {
 		name        string
 		keyspaces   []*vtctldatapb.Keyspace
+		tablets     []*topodatapb.Tablet
 		req         *vtctldatapb.SetKeyspaceDurabilityPolicyRequest
 		expected    *vtctldatapb.SetKeyspaceDurabilityPolicyResponse
 		expectedErr string
@@ -8532,6 +8953,103 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "cross cell quorum",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:         "ks1",
+				DurabilityPolicy: "cross_cell_quorum:3",
+			},
+			expected: &vtctldatapb.SetKeyspaceDurabilityPolicyResponse{
+				Keyspace: &topodatapb.Keyspace{
+					DurabilityPolicy: "cross_cell_quorum:3",
+				},
+			},
+		},
+		{
+			name: "cross cell quorum with one replica per cell",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+					Keyspace: "ks1",
+					Shard:    "-",
+					Type:     topodatapb.TabletType_PRIMARY,
+				},
+				{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+					Keyspace: "ks1",
+					Shard:    "-",
+					Type:     topodatapb.TabletType_RDONLY,
+				},
+				{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone2", Uid: 200},
+					Keyspace: "ks1",
+					Shard:    "-",
+					Type:     topodatapb.TabletType_REPLICA,
+				},
+				{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone2", Uid: 201},
+					Keyspace: "ks1",
+					Shard:    "-",
+					Type:     topodatapb.TabletType_RDONLY,
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:         "ks1",
+				DurabilityPolicy: "cross_cell_quorum:1",
+			},
+			expected: &vtctldatapb.SetKeyspaceDurabilityPolicyResponse{
+				Keyspace: &topodatapb.Keyspace{
+					DurabilityPolicy: "cross_cell_quorum:1",
+				},
+			},
+		},
+		{
+			name: "cross cell quorum with several replicas in one cell",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+					Keyspace: "ks1",
+					Shard:    "-",
+					Type:     topodatapb.TabletType_PRIMARY,
+				},
+				{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+					Keyspace: "ks1",
+					Shard:    "-",
+					Type:     topodatapb.TabletType_REPLICA,
+				},
+				{
+					Alias:    &topodatapb.TabletAlias{Cell: "zone2", Uid: 200},
+					Keyspace: "ks1",
+					Shard:    "-",
+					Type:     topodatapb.TabletType_REPLICA,
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:         "ks1",
+				DurabilityPolicy: "cross_cell_quorum:1",
+			},
+			// zone1 has two tablets able to ack for the replica of zone2
+			expectedErr: "cannot set durability policy <cross_cell_quorum:1> on shard ks1/-: cell zone1 has more than one tablet able to send semi-sync acks for zone2-0000000200, the acks could not come from distinct cells",
+		},
 		{
 			name: "keyspace not found",
 			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
@@ -8539,6 +9057,20 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 			},
 			expectedErr: "node doesn't exist: keyspaces/ks1",
 		},
+		{
+			name: "cross cell quorum without cells",
+			keyspaces: []*vtctldatapb.Keyspace{
+				{
+					Name:     "ks1",
+					Keyspace: &topodatapb.Keyspace{},
+				},
+			},
+			req: &vtctldatapb.SetKeyspaceDurabilityPolicyRequest{
+				Keyspace:         "ks1",
+				DurabilityPolicy: "cross_cell_quorum:0",
+			},
+			expectedErr: "durability policy <cross_cell_quorum:0> is not a valid policy. Please register it as a policy first",
+		},
 		{
 			name: "fail to update durability policy",
 			keyspaces: []*vtctldatapb.Keyspace{
@@ -8562,8 +9094,9 @@ func TestSetKeyspaceDurabilityPolicy(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			ts := memorytopo.NewServer("zone1")
+			ts := memorytopo.NewServer("zone1", "zone2")
 			testutil.AddKeyspaces(ctx, t, ts, tt.keyspaces...)
+			testutil.AddTablets(ctx, t, ts, nil, tt.tablets...)
 
 			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
 				return NewVtctldServer(ts)
@@ -10810,6 +11343,197 @@ func TestUpdateCellsAlias(t *testing.T) {
 	}
 }
 
+func TestUpdateThrottlerConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		config       *topodatapb.ThrottlerConfig
+		req          *vtctldatapb.UpdateThrottlerConfigRequest
+		expected     *topodatapb.ThrottlerConfig
+		expectedErr  string
+		expectedCode vtrpcpb.Code
+	}{
+		{
+			name: "add built-in metric",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricName:      "history_list_length",
+				MetricThreshold: 10000,
+			},
+			expected: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"history_list_length": {Threshold: 10000},
+				},
+			},
+		},
+		{
+			name: "update threshold of custom metric",
+			config: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"custom": {Threshold: 5, Query: "select 1"},
+				},
+			},
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricName:      "custom",
+				MetricThreshold: 7,
+			},
+			expected: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"custom": {Threshold: 7, Query: "select 1"},
+				},
+			},
+		},
+		{
+			name: "update query of custom metric",
+			config: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"custom": {Threshold: 5, Query: "select 1"},
+				},
+			},
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricName:  "custom",
+				MetricQuery: "select 2",
+			},
+			expected: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"custom": {Threshold: 5, Query: "select 2"},
+				},
+			},
+		},
+		{
+			name: "assign app metrics",
+			config: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"history_list_length": {Threshold: 10000},
+				},
+			},
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				AppName:           "online-ddl",
+				AppCheckedMetrics: []string{"lag", "history_list_length"},
+			},
+			expected: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"history_list_length": {Threshold: 10000},
+				},
+				AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_AppCheckedMetrics{
+					"online-ddl": {Names: []string{"lag", "history_list_length"}},
+				},
+			},
+		},
+		{
+			name: "reset app metrics",
+			config: &topodatapb.ThrottlerConfig{
+				AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_AppCheckedMetrics{
+					"online-ddl": {Names: []string{"lag"}},
+				},
+			},
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				AppName: "online-ddl",
+			},
+			expected: &topodatapb.ThrottlerConfig{
+				AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_AppCheckedMetrics{},
+			},
+		},
+		{
+			name: "new metric without threshold",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricName:  "custom",
+				MetricQuery: "select 1",
+			},
+			expectedErr:  "metric custom requires a positive --metric-threshold, got 0",
+			expectedCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "negative metric threshold",
+			config: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"custom": {Threshold: 5, Query: "select 1"},
+				},
+			},
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricName:      "custom",
+				MetricThreshold: -1,
+			},
+			expectedErr:  "metric custom requires a positive --metric-threshold, got -1",
+			expectedCode: vtrpcpb.Code_INVALID_ARGUMENT,
+		},
+		{
+			name: "custom metric without query",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricName:      "custom",
+				MetricThreshold: 7,
+			},
+			expectedErr: "metric custom is not a built-in metric and requires a query",
+		},
+		{
+			name: "lag metric",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricName:      "lag",
+				MetricThreshold: 7,
+			},
+			expectedErr: "the lag metric is configured by the throttler threshold and custom query",
+		},
+		{
+			name: "remove metric checked by an app",
+			config: &topodatapb.ThrottlerConfig{
+				Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+					"threads_running": {Threshold: 100},
+				},
+				AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_AppCheckedMetrics{
+					"vreplication": {Names: []string{"threads_running"}},
+				},
+			},
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricName:   "threads_running",
+				RemoveMetric: true,
+			},
+			expectedErr: "app vreplication is checked against unknown metric threads_running",
+		},
+		{
+			name: "metric threshold without metric name",
+			req: &vtctldatapb.UpdateThrottlerConfigRequest{
+				MetricThreshold: 7,
+			},
+			expectedErr: "--metric-threshold, --metric-query and --remove-metric require --metric-name",
+		},
+	}
+
+	ctx := context.Background()
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ts := memorytopo.NewServer("zone1")
+			testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+				Name: "ks",
+				Keyspace: &topodatapb.Keyspace{
+					ThrottlerConfig: tt.config,
+				},
+			})
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(ts)
+			})
+			tt.req.Keyspace = "ks"
+			_, err := vtctld.UpdateThrottlerConfig(ctx, tt.req)
+			if tt.expectedErr != "" {
+				assert.EqualError(t, err, tt.expectedErr)
+				if tt.expectedCode != vtrpcpb.Code_OK {
+					assert.Equal(t, tt.expectedCode, vterrors.Code(err))
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			ki, err := ts.GetKeyspace(ctx, "ks")
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, ki.ThrottlerConfig)
+		})
+	}
+}
+
 func TestValidate(t *testing.T) {
 	t.Parallel()
 
@@ -18,6 +18,8 @@ package reparentutil
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 
 	"vitess.io/vitess/go/vt/log"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
@@ -32,9 +34,16 @@ import (
 // register a NewDurabler function.
 type NewDurabler func() Durabler
 
+// A NewParameterizedDurabler is a function that creates a new Durabler based on
+// the parameter which follows the name of the durability policy, like the 3 of
+// cross_cell_quorum:3. It returns an error if the parameter is invalid.
+type NewParameterizedDurabler func(param string) (Durabler, error)
+
 var (
 	// durabilityPolicies is a map that stores the functions needed to create a new Durabler
 	durabilityPolicies = make(map[string]NewDurabler)
+	// parameterizedDurabilityPolicies is a map that stores the functions needed to create a new Durabler from a parameter
+	parameterizedDurabilityPolicies = make(map[string]NewParameterizedDurabler)
 )
 
 func init() {
@@ -48,6 +57,7 @@ func init() {
 	RegisterDurability("cross_cell", func() Durabler {
 		return &durabilityCrossCell{}
 	})
+	RegisterParameterizedDurability("cross_cell_quorum", newDurabilityCrossCellQuorum)
 	RegisterDurability("test", func() Durabler {
 		return &durabilityTest{}
 	})
@@ -64,6 +74,14 @@ type Durabler interface {
 	isReplicaSemiSync(primary, replica *topodatapb.Tablet) bool
 }
 
+// cellQuorumDurabler is implemented by the durability policies which require the semi-sync acks of a primary
+// to come from replicas in a number of distinct cells, instead of only counting the acks.
+type cellQuorumDurabler interface {
+	// semiSyncAckerCells represents the number of distinct cells, other than the cell of the given tablet, which must have
+	// a semi-sync acker if it were to become the PRIMARY instance
+	semiSyncAckerCells(*topodatapb.Tablet) int
+}
+
 func RegisterDurability(name string, newDurablerFunc NewDurabler) {
 	if durabilityPolicies[name] != nil {
 		log.Fatalf("durability policy %v already registered", name)
@@ -71,21 +89,34 @@ func RegisterDurability(name string, newDurablerFunc NewDurabler) {
 	durabilityPolicies[name] = newDurablerFunc
 }
 
+// RegisterParameterizedDurability registers a durability policy which is used as <name>:<param>.
+func RegisterParameterizedDurability(name string, newDurablerFunc NewParameterizedDurabler) {
+	if parameterizedDurabilityPolicies[name] != nil {
+		log.Fatalf("durability policy %v already registered", name)
+	}
+	parameterizedDurabilityPolicies[name] = newDurablerFunc
+}
+
 //=======================================================================
 
 // GetDurabilityPolicy is used to get a new durability policy from the registered policies
 func GetDurabilityPolicy(name string) (Durabler, error) {
-	newDurabilityCreationFunc, found := durabilityPolicies[name]
-	if !found {
-		return nil, fmt.Errorf("durability policy %v not found", name)
+	if newDurabilityCreationFunc, found := durabilityPolicies[name]; found {
+		return newDurabilityCreationFunc(), nil
+	}
+	if policy, param, ok := strings.Cut(name, ":"); ok {
+		if newDurabilityCreationFunc, found := parameterizedDurabilityPolicies[policy]; found {
+			return newDurabilityCreationFunc(param)
+		}
 	}
-	return newDurabilityCreationFunc(), nil
+	return nil, fmt.Errorf("durability policy %v not found", name)
 }
 
-// CheckDurabilityPolicyExists is used to check if the durability policy is part of the registered policies
+// CheckDurabilityPolicyExists is used to check if the durability policy is part of the registered policies,
+// with a valid parameter for the parameterized ones
 func CheckDurabilityPolicyExists(name string) bool {
-	_, found := durabilityPolicies[name]
-	return found
+	_, err := GetDurabilityPolicy(name)
+	return err == nil
 }
 
 // PromotionRule returns the promotion rule for the instance.
@@ -103,6 +134,15 @@ func SemiSyncAckers(durability Durabler, tablet *topodatapb.Tablet) int {
 	return durability.semiSyncAckers(tablet)
 }
 
+// SemiSyncAckerCells returns the number of distinct cells, other than the cell of the tablet,
+// in which replicas must acknowledge its transactions. 0 means that the cells don't matter.
+func SemiSyncAckerCells(durability Durabler, tablet *topodatapb.Tablet) int {
+	if cellQuorum, ok := durability.(cellQuorumDurabler); ok {
+		return cellQuorum.semiSyncAckerCells(tablet)
+	}
+	return 0
+}
+
 // IsReplicaSemiSync returns the replica semi-sync setting from the tablet record.
 // Prefer using this function if tablet record is available.
 func IsReplicaSemiSync(durability Durabler, primary, replica *topodatapb.Tablet) bool {
@@ -198,6 +238,56 @@ func (d *durabilityCrossCell) isReplicaSemiSync(primary, replica *topodatapb.Tab
 
 //=======================================================================
 
+// durabilityCrossCellQuorum is used as cross_cell_quorum:N. It requires N semi-sync acks from Primary and Replica type
+// servers in cells other than the cell of the primary, so that a transaction must be in N+1 cells for it to be acknowledged.
+// MySQL only counts the acks, so this only holds with at most one Primary or Replica type server per cell outside the
+// one of the primary: SetKeyspaceDurabilityPolicy, ChangeTabletType and the reparent operations refuse to set the policy,
+// add a Replica or promote a tablet otherwise (see CheckSemiSyncAckerCells). The reparent operations also only promote
+// a tablet when the tablets they reached are spread across N such cells, so that the new primary can make progress.
+// It returns NeutralPromoteRule for Primary and Replica tablet types, MustNotPromoteRule for everything else
+type durabilityCrossCellQuorum struct {
+	cells int
+}
+
+// newDurabilityCrossCellQuorum creates a durabilityCrossCellQuorum for the number of cells given as parameter
+func newDurabilityCrossCellQuorum(param string) (Durabler, error) {
+	cells, err := strconv.Atoi(param)
+	if err != nil || cells < 1 {
+		return nil, fmt.Errorf("durability policy cross_cell_quorum requires a positive number of cells, got %q", param)
+	}
+	return &durabilityCrossCellQuorum{cells: cells}, nil
+}
+
+// promotionRule implements the Durabler interface
+func (d *durabilityCrossCellQuorum) promotionRule(tablet *topodatapb.Tablet) promotionrule.CandidatePromotionRule {
+	switch tablet.Type {
+	case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA:
+		return promotionrule.Neutral
+	}
+	return promotionrule.MustNot
+}
+
+// semiSyncAckers implements the Durabler interface
+func (d *durabilityCrossCellQuorum) semiSyncAckers(tablet *topodatapb.Tablet) int {
+	return d.cells
+}
+
+// isReplicaSemiSync implements the Durabler interface
+func (d *durabilityCrossCellQuorum) isReplicaSemiSync(primary, replica *topodatapb.Tablet) bool {
+	switch replica.Type {
+	case topodatapb.TabletType_PRIMARY, topodatapb.TabletType_REPLICA:
+		return primary.Alias.Cell != replica.Alias.Cell
+	}
+	return false
+}
+
+// semiSyncAckerCells implements the cellQuorumDurabler interface
+func (d *durabilityCrossCellQuorum) semiSyncAckerCells(tablet *topodatapb.Tablet) int {
+	return d.cells
+}
+
+//=======================================================================
+
 // durabilityTest is like durabilityNone. It overrides the type for a specific tablet to prefer. It is only meant to be used for testing purposes!
 type durabilityTest struct{}
 
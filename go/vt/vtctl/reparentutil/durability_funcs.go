@@ -17,9 +17,12 @@ limitations under the License.
 package reparentutil
 
 import (
-	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/reparentutil/promotionrule"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 // SemiSyncAckersForPrimary returns the list of tablets which are capable of sending Semi-Sync Acks for the given primary tablet
@@ -54,7 +57,8 @@ func haveRevokedForTablet(durability Durabler, primaryEligible *topodatapb.Table
 	numOfSemiSyncAcksRequired := SemiSyncAckers(durability, primaryEligible)
 
 	// if we have reached enough semi-sync Acking tablets such that the primaryEligible cannot accept a write
-	// we have revoked from the tablet
+	// we have revoked from the tablet. MySQL only counts the Acks, so the cells of the tablets we haven't reached
+	// don't matter here: several of them in a single cell are enough for the primaryEligible to accept a write
 	return len(allSemiSyncAckers)-len(semiSyncAckersReached) < numOfSemiSyncAcksRequired
 }
 
@@ -87,7 +91,36 @@ func canEstablishForTablet(durability Durabler, primaryEligible *topodatapb.Tabl
 	// numOfSemiSyncAcksRequired is the number of semi sync Acks that the primaryEligible tablet requires
 	numOfSemiSyncAcksRequired := SemiSyncAckers(durability, primaryEligible)
 
-	// if we have reached enough semi-sync Acking tablets such that the primaryEligible can accept a write
-	// we can safely promote this tablet
-	return len(semiSyncAckersReached) >= numOfSemiSyncAcksRequired
+	// if we have reached enough semi-sync Acking tablets, spread across enough cells, such that the primaryEligible
+	// can accept a write we can safely promote this tablet
+	return len(semiSyncAckersReached) >= numOfSemiSyncAcksRequired &&
+		countCells(semiSyncAckersReached) >= SemiSyncAckerCells(durability, primaryEligible)
+}
+
+// CheckSemiSyncAckerCells returns an error if the durability policy requires the semi-sync acks of the given primary
+// eligible tablet to come from distinct cells, but a cell has several tablets capable of sending them. MySQL only counts
+// the acks, so that cell alone could acknowledge a write once the tablet is promoted.
+func CheckSemiSyncAckerCells(durability Durabler, primaryEligible *topodatapb.Tablet, allTablets []*topodatapb.Tablet) error {
+	if SemiSyncAckerCells(durability, primaryEligible) == 0 {
+		return nil
+	}
+
+	ackersPerCell := make(map[string]int)
+	for _, tablet := range SemiSyncAckersForPrimary(durability, primaryEligible, allTablets) {
+		ackersPerCell[tablet.Alias.Cell]++
+		if ackersPerCell[tablet.Alias.Cell] > 1 {
+			return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "cell %v has more than one tablet able to send semi-sync acks for %v, the acks could not come from distinct cells",
+				tablet.Alias.Cell, topoproto.TabletAliasString(primaryEligible.Alias))
+		}
+	}
+	return nil
+}
+
+// countCells returns the number of distinct cells of the given tablets
+func countCells(tablets []*topodatapb.Tablet) int {
+	cells := make(map[string]bool)
+	for _, tablet := range tablets {
+		cells[tablet.Alias.Cell] = true
+	}
+	return len(cells)
 }
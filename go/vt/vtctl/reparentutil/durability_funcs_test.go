@@ -61,6 +61,20 @@ var (
 		},
 		Type: topodatapb.TabletType_RDONLY,
 	}
+	replicaCrossCellTablet2 = &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone-2",
+			Uid:  4,
+		},
+		Type: topodatapb.TabletType_REPLICA,
+	}
+	replicaThirdCellTablet = &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "zone-3",
+			Uid:  2,
+		},
+		Type: topodatapb.TabletType_REPLICA,
+	}
 )
 
 func TestSemiSyncAckersForPrimary(t *testing.T) {
@@ -95,6 +109,19 @@ func TestSemiSyncAckersForPrimary(t *testing.T) {
 			primary:            primaryTablet,
 			allTablets:         []*topodatapb.Tablet{primaryTablet, replicaTablet, rdonlyTablet, replicaCrossCellTablet, rdonlyCrossCellTablet},
 			wantSemiSyncAckers: []*topodatapb.Tablet{replicaCrossCellTablet},
+		}, {
+			name:               "'cross_cell_quorum:2' durability policy",
+			durabilityPolicy:   "cross_cell_quorum:2",
+			primary:            primaryTablet,
+			allTablets:         []*topodatapb.Tablet{primaryTablet, replicaTablet, rdonlyTablet, replicaCrossCellTablet, rdonlyCrossCellTablet, replicaThirdCellTablet},
+			wantSemiSyncAckers: []*topodatapb.Tablet{replicaCrossCellTablet, replicaThirdCellTablet},
+		}, {
+			// MySQL only counts the acks, so both replicas of the other cell can acknowledge a transaction on their own
+			name:               "'cross_cell_quorum:2' durability policy - several replicas in one cell",
+			durabilityPolicy:   "cross_cell_quorum:2",
+			primary:            primaryTablet,
+			allTablets:         []*topodatapb.Tablet{primaryTablet, replicaTablet, replicaCrossCellTablet, replicaCrossCellTablet2},
+			wantSemiSyncAckers: []*topodatapb.Tablet{replicaCrossCellTablet, replicaCrossCellTablet2},
 		},
 	}
 	for _, tt := range tests {
@@ -193,6 +220,40 @@ func Test_haveRevokedForTablet(t *testing.T) {
 				primaryTablet,
 			},
 			revoked: true,
+		}, {
+			name:             "'cross_cell_quorum:2' durability policy - revoked",
+			durabilityPolicy: "cross_cell_quorum:2",
+			primaryEligible:  primaryTablet,
+			allTablets: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, replicaCrossCellTablet, replicaCrossCellTablet2, replicaThirdCellTablet,
+			},
+			tabletsReached: []*topodatapb.Tablet{
+				replicaCrossCellTablet2, replicaThirdCellTablet,
+			},
+			revoked: true,
+		}, {
+			// the replicas not reached are all in one cell, but they can still acknowledge a write on their own
+			name:             "'cross_cell_quorum:2' durability policy - several replicas in one cell not reached",
+			durabilityPolicy: "cross_cell_quorum:2",
+			primaryEligible:  primaryTablet,
+			allTablets: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, replicaCrossCellTablet, replicaCrossCellTablet2, replicaThirdCellTablet,
+			},
+			tabletsReached: []*topodatapb.Tablet{
+				replicaThirdCellTablet,
+			},
+			revoked: false,
+		}, {
+			name:             "'cross_cell_quorum:2' durability policy - not revoked",
+			durabilityPolicy: "cross_cell_quorum:2",
+			primaryEligible:  primaryTablet,
+			allTablets: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, replicaCrossCellTablet, replicaCrossCellTablet2, replicaThirdCellTablet,
+			},
+			tabletsReached: []*topodatapb.Tablet{
+				replicaTablet, replicaCrossCellTablet,
+			},
+			revoked: false,
 		},
 	}
 	for _, tt := range tests {
@@ -371,6 +432,30 @@ func Test_canEstablishForTablet(t *testing.T) {
 				primaryTablet, replicaCrossCellTablet,
 			},
 			canEstablish: true,
+		}, {
+			name:             "not established in enough cells",
+			durabilityPolicy: "cross_cell_quorum:2",
+			primaryEligible:  primaryTablet,
+			tabletsReached: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, replicaCrossCellTablet, replicaCrossCellTablet2,
+			},
+			canEstablish: false,
+		}, {
+			name:             "established",
+			durabilityPolicy: "cross_cell_quorum:2",
+			primaryEligible:  primaryTablet,
+			tabletsReached: []*topodatapb.Tablet{
+				primaryTablet, replicaCrossCellTablet, replicaThirdCellTablet,
+			},
+			canEstablish: true,
+		}, {
+			name:             "enough acks but not in enough cells",
+			durabilityPolicy: "cross_cell_quorum:3",
+			primaryEligible:  primaryTablet,
+			tabletsReached: []*topodatapb.Tablet{
+				primaryTablet, replicaCrossCellTablet, replicaCrossCellTablet2, replicaThirdCellTablet,
+			},
+			canEstablish: false,
 		},
 	}
 	for _, tt := range tests {
@@ -381,3 +466,64 @@ func Test_canEstablishForTablet(t *testing.T) {
 		})
 	}
 }
+
+func TestCheckSemiSyncAckerCells(t *testing.T) {
+	tests := []struct {
+		name             string
+		durabilityPolicy string
+		primaryEligible  *topodatapb.Tablet
+		allTablets       []*topodatapb.Tablet
+		wantErr          string
+	}{
+		{
+			name:             "no cell quorum",
+			durabilityPolicy: "semi_sync",
+			primaryEligible:  primaryTablet,
+			allTablets: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, replicaCrossCellTablet, replicaCrossCellTablet2,
+			},
+		}, {
+			name:             "one acker per cell",
+			durabilityPolicy: "cross_cell_quorum:2",
+			primaryEligible:  primaryTablet,
+			allTablets: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, rdonlyTablet, replicaCrossCellTablet, rdonlyCrossCellTablet, replicaThirdCellTablet,
+			},
+		}, {
+			name:             "several replicas in the cell of the primary",
+			durabilityPolicy: "cross_cell_quorum:1",
+			primaryEligible:  replicaTablet,
+			allTablets: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, replicaCrossCellTablet,
+			},
+		}, {
+			name:             "several replicas in one cell",
+			durabilityPolicy: "cross_cell_quorum:2",
+			primaryEligible:  primaryTablet,
+			allTablets: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, replicaCrossCellTablet, replicaCrossCellTablet2, replicaThirdCellTablet,
+			},
+			wantErr: "cell zone-2 has more than one tablet able to send semi-sync acks for zone-1-0000000001, the acks could not come from distinct cells",
+		}, {
+			name:             "former primary in the cell of a replica",
+			durabilityPolicy: "cross_cell_quorum:1",
+			primaryEligible:  replicaCrossCellTablet,
+			allTablets: []*topodatapb.Tablet{
+				primaryTablet, replicaTablet, replicaCrossCellTablet,
+			},
+			wantErr: "cell zone-1 has more than one tablet able to send semi-sync acks for zone-2-0000000002, the acks could not come from distinct cells",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			durability, err := GetDurabilityPolicy(tt.durabilityPolicy)
+			require.NoError(t, err)
+			err = CheckSemiSyncAckerCells(durability, tt.primaryEligible, tt.allTablets)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
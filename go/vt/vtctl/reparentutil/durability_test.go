@@ -215,6 +215,60 @@ func TestDurabilityCrossCell(t *testing.T) {
 	}))
 }
 
+func TestDurabilityCrossCellQuorum(t *testing.T) {
+	durability, err := GetDurabilityPolicy("cross_cell_quorum:2")
+	require.NoError(t, err)
+
+	primary := &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "cell1",
+			Uid:  100,
+		},
+		Type: topodatapb.TabletType_PRIMARY,
+	}
+	assert.Equal(t, promotionrule.Neutral, PromotionRule(durability, primary))
+	assert.Equal(t, promotionrule.MustNot, PromotionRule(durability, &topodatapb.Tablet{
+		Alias: &topodatapb.TabletAlias{
+			Cell: "cell1",
+			Uid:  101,
+		},
+		Type: topodatapb.TabletType_RDONLY,
+	}))
+	assert.Equal(t, 2, SemiSyncAckers(durability, primary))
+	assert.Equal(t, 2, SemiSyncAckerCells(durability, primary))
+	assert.Equal(t, false, IsReplicaSemiSync(durability, primary, &topodatapb.Tablet{
+		Type: topodatapb.TabletType_REPLICA,
+		Alias: &topodatapb.TabletAlias{
+			Cell: "cell1",
+		},
+	}))
+	assert.Equal(t, true, IsReplicaSemiSync(durability, primary, &topodatapb.Tablet{
+		Type: topodatapb.TabletType_REPLICA,
+		Alias: &topodatapb.TabletAlias{
+			Cell: "cell2",
+		},
+	}))
+
+	durability, err = GetDurabilityPolicy("cross_cell_quorum:5")
+	require.NoError(t, err)
+	assert.Equal(t, 5, SemiSyncAckers(durability, primary))
+	assert.Equal(t, 5, SemiSyncAckerCells(durability, primary))
+	assert.True(t, CheckDurabilityPolicyExists("cross_cell_quorum:5"))
+
+	for _, name := range []string{"cross_cell_quorum", "cross_cell_quorum:", "cross_cell_quorum:0", "cross_cell_quorum:-1", "cross_cell_quorum:two"} {
+		_, err = GetDurabilityPolicy(name)
+		assert.Error(t, err, name)
+		assert.False(t, CheckDurabilityPolicyExists(name), name)
+	}
+	_, err = GetDurabilityPolicy("cross_cell_quorum:two")
+	assert.EqualError(t, err, `durability policy cross_cell_quorum requires a positive number of cells, got "two"`)
+
+	// The other policies don't care about the cells of the ackers.
+	durability, err = GetDurabilityPolicy("cross_cell")
+	require.NoError(t, err)
+	assert.Equal(t, 0, SemiSyncAckerCells(durability, primary))
+}
+
 func TestError(t *testing.T) {
 	_, err := GetDurabilityPolicy("unknown")
 	assert.EqualError(t, err, "durability policy unknown not found")
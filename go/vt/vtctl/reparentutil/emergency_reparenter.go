@@ -234,10 +234,15 @@ func (erp *EmergencyReparenter) reparentShardLocked(ctx context.Context, ev *eve
 
 	// After finding the intermediate source, we want to filter the valid candidate list by the following criteria -
 	// 1. Only keep the tablets which can make progress after being promoted (have sufficient reachable semi-sync ackers)
+	//    and whose semi-sync ackers come from distinct cells if the durability policy requires it
 	// 2. Remove the tablets with the Must_not promote rule
 	// 3. Remove cross-cell tablets if PreventCrossCellPromotion is specified
 	// Our final primary candidate MUST belong to this list of valid candidates
-	validCandidateTablets, err = erp.filterValidCandidates(validCandidateTablets, stoppedReplicationSnapshot.reachableTablets, prevPrimary, opts)
+	var allTablets []*topodatapb.Tablet
+	for _, tabletInfo := range tabletMap {
+		allTablets = append(allTablets, tabletInfo.Tablet)
+	}
+	validCandidateTablets, err = erp.filterValidCandidates(validCandidateTablets, stoppedReplicationSnapshot.reachableTablets, allTablets, prevPrimary, opts)
 	if err != nil {
 		return err
 	}
@@ -720,7 +725,7 @@ func (erp *EmergencyReparenter) promoteNewPrimary(
 }
 
 // filterValidCandidates filters valid tablets, keeping only the ones which can successfully be promoted without any constraint failures and can make forward progress on being promoted
-func (erp *EmergencyReparenter) filterValidCandidates(validTablets []*topodatapb.Tablet, tabletsReachable []*topodatapb.Tablet, prevPrimary *topodatapb.Tablet, opts EmergencyReparentOptions) ([]*topodatapb.Tablet, error) {
+func (erp *EmergencyReparenter) filterValidCandidates(validTablets []*topodatapb.Tablet, tabletsReachable []*topodatapb.Tablet, allTablets []*topodatapb.Tablet, prevPrimary *topodatapb.Tablet, opts EmergencyReparentOptions) ([]*topodatapb.Tablet, error) {
 	var restrictedValidTablets []*topodatapb.Tablet
 	for _, tablet := range validTablets {
 		tabletAliasStr := topoproto.TabletAliasString(tablet.Alias)
@@ -748,6 +753,15 @@ func (erp *EmergencyReparenter) filterValidCandidates(validTablets []*topodatapb
 			}
 			continue
 		}
+		// Remove any tablet whose semi-sync acks would not come from distinct cells, counting the unreachable tablets
+		// as well since they start sending acks again once they are back
+		if err := CheckSemiSyncAckerCells(opts.durability, tablet, allTablets); err != nil {
+			erp.logger.Infof("Removing %s from list of valid candidates for promotion because %v", tabletAliasStr, err)
+			if opts.NewPrimaryAlias != nil && topoproto.TabletAliasEqual(opts.NewPrimaryAlias, tablet.Alias) {
+				return nil, vterrors.Errorf(vtrpc.Code_ABORTED, "proposed primary %s will not be able to reach the cell quorum on being promoted: %v", topoproto.TabletAliasString(opts.NewPrimaryAlias), err)
+			}
+			continue
+		}
 		restrictedValidTablets = append(restrictedValidTablets, tablet)
 	}
 	return restrictedValidTablets, nil
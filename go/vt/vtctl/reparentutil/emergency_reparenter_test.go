@@ -4357,6 +4357,13 @@ func TestEmergencyReparenter_filterValidCandidates(t *testing.T) {
 			},
 			Type: topodatapb.TabletType_RDONLY,
 		}
+		secondReplicaCrossCellTablet = &topodatapb.Tablet{
+			Alias: &topodatapb.TabletAlias{
+				Cell: "zone-2",
+				Uid:  4,
+			},
+			Type: topodatapb.TabletType_REPLICA,
+		}
 	)
 	allTablets := []*topodatapb.Tablet{primaryTablet, replicaTablet, rdonlyTablet, replicaCrossCellTablet, rdonlyCrossCellTablet}
 	tests := []struct {
@@ -4364,6 +4371,8 @@ func TestEmergencyReparenter_filterValidCandidates(t *testing.T) {
 		durability       string
 		validTablets     []*topodatapb.Tablet
 		tabletsReachable []*topodatapb.Tablet
+		// allTablets defaults to tabletsReachable
+		allTablets       []*topodatapb.Tablet
 		prevPrimary      *topodatapb.Tablet
 		opts             EmergencyReparentOptions
 		filteredTablets  []*topodatapb.Tablet
@@ -4395,6 +4404,26 @@ func TestEmergencyReparenter_filterValidCandidates(t *testing.T) {
 			validTablets:     []*topodatapb.Tablet{primaryTablet, replicaTablet},
 			tabletsReachable: []*topodatapb.Tablet{primaryTablet, replicaTablet, rdonlyTablet, rdonlyCrossCellTablet},
 			filteredTablets:  nil,
+		}, {
+			name:             "filter cross cell quorum",
+			durability:       "cross_cell_quorum:2",
+			validTablets:     allTablets,
+			tabletsReachable: append([]*topodatapb.Tablet{replicaThirdCellTablet}, allTablets...),
+			// zone-1 has two tablets able to ack for replicaCrossCellTablet
+			filteredTablets: []*topodatapb.Tablet{primaryTablet, replicaTablet},
+		}, {
+			name:             "filter cross cell quorum - several ackers in an unreachable cell",
+			durability:       "cross_cell_quorum:2",
+			validTablets:     []*topodatapb.Tablet{primaryTablet, replicaTablet, replicaCrossCellTablet},
+			tabletsReachable: append([]*topodatapb.Tablet{replicaThirdCellTablet}, allTablets...),
+			allTablets:       append([]*topodatapb.Tablet{replicaThirdCellTablet, secondReplicaCrossCellTablet}, allTablets...),
+			filteredTablets:  nil,
+		}, {
+			name:             "filter cross cell quorum - not enough cells reachable",
+			durability:       "cross_cell_quorum:2",
+			validTablets:     allTablets,
+			tabletsReachable: allTablets,
+			filteredTablets:  nil,
 		}, {
 			name:       "filter mixed",
 			durability: "cross_cell",
@@ -4438,6 +4467,15 @@ func TestEmergencyReparenter_filterValidCandidates(t *testing.T) {
 				NewPrimaryAlias: primaryTablet.Alias,
 			},
 			errShouldContain: "proposed primary zone-1-0000000001 will not be able to make forward progress on being promoted",
+		}, {
+			name:             "error - requested primary cannot reach the cell quorum",
+			durability:       "cross_cell_quorum:2",
+			validTablets:     allTablets,
+			tabletsReachable: append([]*topodatapb.Tablet{replicaThirdCellTablet, secondReplicaCrossCellTablet}, allTablets...),
+			opts: EmergencyReparentOptions{
+				NewPrimaryAlias: primaryTablet.Alias,
+			},
+			errShouldContain: "proposed primary zone-1-0000000001 will not be able to reach the cell quorum on being promoted",
 		},
 	}
 	for _, tt := range tests {
@@ -4447,7 +4485,11 @@ func TestEmergencyReparenter_filterValidCandidates(t *testing.T) {
 			tt.opts.durability = durability
 			logger := logutil.NewMemoryLogger()
 			erp := NewEmergencyReparenter(nil, nil, logger)
-			tabletList, err := erp.filterValidCandidates(tt.validTablets, tt.tabletsReachable, tt.prevPrimary, tt.opts)
+			allTablets := tt.allTablets
+			if allTablets == nil {
+				allTablets = tt.tabletsReachable
+			}
+			tabletList, err := erp.filterValidCandidates(tt.validTablets, tt.tabletsReachable, allTablets, tt.prevPrimary, tt.opts)
 			if tt.errShouldContain != "" {
 				require.Error(t, err)
 				require.Contains(t, err.Error(), tt.errShouldContain)
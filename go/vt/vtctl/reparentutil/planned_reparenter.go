@@ -198,6 +198,11 @@ func (pr *PlannedReparenter) preflightChecks(
 	if !canEstablishForTablet(opts.durability, newPrimaryTabletInfo.Tablet, tabletsReachable) {
 		return true, vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "primary-elect tablet %v won't be able to make forward progress on promotion", primaryElectAliasStr)
 	}
+	// The durability policy may also require the semi-sync acks of the primary-elect to come from distinct cells,
+	// which only holds if no cell has more than one tablet able to send them.
+	if err := CheckSemiSyncAckerCells(opts.durability, newPrimaryTabletInfo.Tablet, tabletsReachable); err != nil {
+		return true, vterrors.Wrapf(err, "primary-elect tablet %v can't reach the cell quorum on promotion", primaryElectAliasStr)
+	}
 
 	ev.NewPrimary = proto.Clone(newPrimaryTabletInfo.Tablet).(*topodatapb.Tablet)
 
@@ -18,6 +18,8 @@ package schematools
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"vitess.io/vitess/go/vt/topo"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -25,6 +27,7 @@ import (
 
 	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
@@ -43,3 +46,62 @@ func GetSchema(ctx context.Context, ts *topo.Server, tmc tmclient.TabletManagerC
 
 	return sd, nil
 }
+
+var schemaMigrationStatusName = map[string]vtctldatapb.SchemaMigration_Status{}
+
+func init() {
+	for name, value := range vtctldatapb.SchemaMigration_Status_value {
+		schemaMigrationStatusName[strings.ToLower(name)] = vtctldatapb.SchemaMigration_Status(value)
+	}
+}
+
+// ParseSchemaMigrationStrategy parses the given strategy into the underlying enum type.
+func ParseSchemaMigrationStrategy(name string) (vtctldatapb.SchemaMigration_Strategy, error) {
+	if name == "" {
+		// backward compatibility and to handle unspecified values
+		return vtctldatapb.SchemaMigration_DIRECT, nil
+	}
+
+	upperName := strings.ToUpper(name)
+	switch upperName {
+	case "GH-OST", "PT-OSC":
+		// more compatibility since the protobuf message names don't
+		// have the dash.
+		upperName = strings.ReplaceAll(upperName, "-", "")
+	default:
+	}
+
+	strategy, ok := vtctldatapb.SchemaMigration_Strategy_value[upperName]
+	if !ok {
+		return 0, fmt.Errorf("unknown schema migration strategy: '%v'", name)
+	}
+
+	return vtctldatapb.SchemaMigration_Strategy(strategy), nil
+}
+
+// ParseSchemaMigrationStatus parses the given status into the underlying enum type.
+func ParseSchemaMigrationStatus(name string) (vtctldatapb.SchemaMigration_Status, error) {
+	if status, ok := schemaMigrationStatusName[strings.ToLower(name)]; ok {
+		return status, nil
+	}
+
+	return 0, fmt.Errorf("unknown enum name for SchemaMigration_Status: %s", name)
+}
+
+// SchemaMigrationStrategyName returns the text-based form of the strategy, as
+// it appears in the _vt.schema_migrations table and ddl strategy flags.
+func SchemaMigrationStrategyName(strategy vtctldatapb.SchemaMigration_Strategy) string {
+	name, ok := vtctldatapb.SchemaMigration_Strategy_name[int32(strategy)]
+	if !ok {
+		return "unknown"
+	}
+
+	switch strategy {
+	case vtctldatapb.SchemaMigration_GHOST:
+		return "gh-ost"
+	case vtctldatapb.SchemaMigration_PTOSC:
+		return "pt-osc"
+	default:
+		return strings.ToLower(name)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schematools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestParseSchemaMigrationStrategy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		expected  vtctldatapb.SchemaMigration_Strategy
+		shouldErr bool
+	}{
+		{name: "", expected: vtctldatapb.SchemaMigration_DIRECT},
+		{name: "vitess", expected: vtctldatapb.SchemaMigration_VITESS},
+		{name: "online", expected: vtctldatapb.SchemaMigration_ONLINE},
+		{name: "gh-ost", expected: vtctldatapb.SchemaMigration_GHOST},
+		{name: "pt-osc", expected: vtctldatapb.SchemaMigration_PTOSC},
+		{name: "direct", expected: vtctldatapb.SchemaMigration_DIRECT},
+		{name: "mysql", expected: vtctldatapb.SchemaMigration_MYSQL},
+		{name: "unknown", shouldErr: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			strategy, err := ParseSchemaMigrationStrategy(test.name)
+			if test.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, strategy)
+		})
+	}
+}
+
+func TestParseSchemaMigrationStatus(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		expected  vtctldatapb.SchemaMigration_Status
+		shouldErr bool
+	}{
+		{name: "queued", expected: vtctldatapb.SchemaMigration_QUEUED},
+		{name: "RUNNING", expected: vtctldatapb.SchemaMigration_RUNNING},
+		{name: "complete", expected: vtctldatapb.SchemaMigration_COMPLETE},
+		{name: "notastatus", shouldErr: true},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			status, err := ParseSchemaMigrationStatus(test.name)
+			if test.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, status)
+		})
+	}
+}
+
+func TestSchemaMigrationStrategyName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		strategy vtctldatapb.SchemaMigration_Strategy
+		expected string
+	}{
+		{strategy: vtctldatapb.SchemaMigration_VITESS, expected: "vitess"},
+		{strategy: vtctldatapb.SchemaMigration_GHOST, expected: "gh-ost"},
+		{strategy: vtctldatapb.SchemaMigration_PTOSC, expected: "pt-osc"},
+		{strategy: vtctldatapb.SchemaMigration_DIRECT, expected: "direct"},
+		{strategy: vtctldatapb.SchemaMigration_MYSQL, expected: "mysql"},
+		{strategy: vtctldatapb.SchemaMigration_Strategy(-1), expected: "unknown"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.expected, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, test.expected, SchemaMigrationStrategyName(test.strategy))
+		})
+	}
+}
@@ -690,7 +690,7 @@ var commands = []commandGroup{
 			{
 				name:   "UpdateThrottlerConfig",
 				method: commandUpdateThrottlerConfig,
-				params: "[--enable|--disable] [--threshold=<float64>] [--custom-query=<query>] [--check-as-check-self|--check-as-check-shard] <keyspace>",
+				params: "[--enable|--disable] [--threshold=<float64>] [--custom-query=<query>] [--check-as-check-self|--check-as-check-shard] [--metric-name=<name> [--metric-threshold=<float64>] [--metric-query=<query>] [--remove-metric]] [--app-name=<name> [--app-checked-metrics=<names>]] <keyspace>",
 				help:   "Update the table throttler configuration for all cells and tablets of a given keyspace",
 			},
 			{
@@ -3518,6 +3518,12 @@ func commandUpdateThrottlerConfig(ctx context.Context, wr *wrangler.Wrangler, su
 	customQuery := subFlags.String("custom-query", "", "custom throttler check query")
 	checkAsCheckSelf := subFlags.Bool("check-as-check-self", false, "/throttler/check requests behave as is /throttler/check-self was called")
 	checkAsCheckShard := subFlags.Bool("check-as-check-shard", false, "use standard behavior for /throttler/check requests")
+	metricName := subFlags.String("metric-name", "", "name of a metric, other than the default 'lag' metric, to add, update or remove. Built-in metrics: 'threads_running', 'history_list_length'")
+	metricThreshold := subFlags.Float64("metric-threshold", 0, "threshold for the --metric-name metric. Required for a new metric, kept when omitted for an existing one")
+	metricQuery := subFlags.String("metric-query", "", "query for the --metric-name metric. Optional for built-in metrics")
+	removeMetric := subFlags.Bool("remove-metric", false, "remove the --metric-name metric")
+	appName := subFlags.String("app-name", "", "name of an app whose checked metrics are set by --app-checked-metrics")
+	appCheckedMetrics := subFlags.StringSlice("app-checked-metrics", nil, "comma separated metrics that --app-name is checked against. Empty resets the app to the default 'lag' metric")
 
 	if err := subFlags.Parse(args); err != nil {
 		return err
@@ -3544,6 +3550,12 @@ func commandUpdateThrottlerConfig(ctx context.Context, wr *wrangler.Wrangler, su
 		Threshold:         *threshold,
 		CheckAsCheckSelf:  *checkAsCheckSelf,
 		CheckAsCheckShard: *checkAsCheckShard,
+		MetricName:        *metricName,
+		MetricThreshold:   *metricThreshold,
+		MetricQuery:       *metricQuery,
+		RemoveMetric:      *removeMetric,
+		AppName:           *appName,
+		AppCheckedMetrics: *appCheckedMetrics,
 	})
 	return err
 }
@@ -0,0 +1,436 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+const (
+	testCell          = "zone1"
+	testWorkflow      = "wf1"
+	testPosition      = "MySQL56/9d10e6ec-07a0-11ee-ae73-8e53f4cf3083:1-100"
+	testSourceUIDBase = 100
+	testTargetUIDBase = 200
+)
+
+// testKeyspace is a keyspace of a testEnv, with one primary tablet per shard.
+type testKeyspace struct {
+	name   string
+	shards []string
+	vs     *vschemapb.Keyspace
+}
+
+// testEnv is a topo holding a source and a target keyspace, along with the
+// workflow server using it and the fake tablet manager client answering for
+// their tablets.
+type testEnv struct {
+	ws      *Server
+	ts      *topo.Server
+	factory *memorytopo.Factory
+	tmc     *testTMClient
+
+	sourceKeyspace *testKeyspace
+	targetKeyspace *testKeyspace
+
+	// tablets holds the primary tablet of each shard, keyed by
+	// keyspace/shard.
+	tablets map[string]*topodatapb.Tablet
+}
+
+// newTestEnv returns a testEnv for the given keyspaces. If both have the
+// same name, they are a single keyspace being resharded from the source
+// shards to the target shards.
+func newTestEnv(t *testing.T, sourceKeyspace, targetKeyspace *testKeyspace) *testEnv {
+	t.Helper()
+	ctx := context.Background()
+
+	env := &testEnv{
+		tmc:            newTestTMClient(),
+		sourceKeyspace: sourceKeyspace,
+		targetKeyspace: targetKeyspace,
+		tablets:        make(map[string]*topodatapb.Tablet),
+	}
+	env.ts, env.factory = memorytopo.NewServerAndFactory(testCell)
+	env.ws = NewServer(env.ts, env.tmc)
+
+	env.addKeyspace(t, ctx, sourceKeyspace, testSourceUIDBase)
+	if targetKeyspace.name != sourceKeyspace.name {
+		env.addKeyspace(t, ctx, targetKeyspace, testTargetUIDBase)
+	} else {
+		env.addShards(t, ctx, targetKeyspace, testTargetUIDBase)
+	}
+	require.NoError(t, env.ts.RebuildSrvVSchema(ctx, nil))
+	return env
+}
+
+func (env *testEnv) addKeyspace(t *testing.T, ctx context.Context, ks *testKeyspace, uidBase int) {
+	t.Helper()
+	require.NoError(t, env.ts.CreateKeyspace(ctx, ks.name, &topodatapb.Keyspace{}))
+	vs := ks.vs
+	if vs == nil {
+		vs = &vschemapb.Keyspace{}
+	}
+	require.NoError(t, env.ts.SaveVSchema(ctx, ks.name, vs))
+	env.addShards(t, ctx, ks, uidBase)
+}
+
+func (env *testEnv) addShards(t *testing.T, ctx context.Context, ks *testKeyspace, uidBase int) {
+	t.Helper()
+	for i, shard := range ks.shards {
+		require.NoError(t, env.ts.CreateShard(ctx, ks.name, shard))
+		tablet := &topodatapb.Tablet{
+			Alias:    &topodatapb.TabletAlias{Cell: testCell, Uid: uint32(uidBase + 10*i)},
+			Keyspace: ks.name,
+			Shard:    shard,
+			Type:     topodatapb.TabletType_PRIMARY,
+		}
+		require.NoError(t, env.ts.CreateTablet(ctx, tablet))
+		_, err := env.ts.UpdateShardFields(ctx, ks.name, shard, func(si *topo.ShardInfo) error {
+			si.PrimaryAlias = tablet.Alias
+			return nil
+		})
+		require.NoError(t, err)
+		env.tablets[ks.name+"/"+shard] = tablet
+	}
+	require.NoError(t, topotools.RebuildKeyspace(ctx, logutil.NewMemoryLogger(), env.ts, ks.name, []string{testCell}, false))
+}
+
+// testExternalTopoType is the topo implementation of the external clusters
+// mounted by mountSelf.
+const testExternalTopoType = "workflow-test"
+
+var (
+	registerExternalTopo sync.Once
+	externalTopo         = &testExternalTopoFactory{}
+)
+
+// testExternalTopoFactory is a topo.Factory serving the topo of the testEnv
+// which last mounted itself as an external cluster.
+type testExternalTopoFactory struct {
+	mu      sync.Mutex
+	factory *memorytopo.Factory
+}
+
+// HasGlobalReadOnlyCell is part of the topo.Factory interface.
+func (f *testExternalTopoFactory) HasGlobalReadOnlyCell(serverAddr, root string) bool {
+	return false
+}
+
+// Create is part of the topo.Factory interface.
+func (f *testExternalTopoFactory) Create(cell, serverAddr, root string) (topo.Conn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.factory.Create(cell, serverAddr, root)
+}
+
+// mountSelf mounts the topo of the env as an external cluster with the given
+// name, so that workflows can use the source keyspace as that of an external
+// cluster. Tests using it must not run in parallel.
+func (env *testEnv) mountSelf(t *testing.T, name string) {
+	t.Helper()
+	registerExternalTopo.Do(func() {
+		topo.RegisterFactory(testExternalTopoType, externalTopo)
+	})
+	externalTopo.mu.Lock()
+	externalTopo.factory = env.factory
+	externalTopo.mu.Unlock()
+	require.NoError(t, env.ts.CreateExternalVitessCluster(context.Background(), name, &topodatapb.ExternalVitessCluster{
+		TopoConfig: &topodatapb.TopoConfig{TopoType: testExternalTopoType},
+	}))
+}
+
+// sourceTablets returns the primary tablets of the source shards.
+func (env *testEnv) sourceTablets() []*topodatapb.Tablet {
+	return env.shardTablets(env.sourceKeyspace)
+}
+
+// targetTablets returns the primary tablets of the target shards.
+func (env *testEnv) targetTablets() []*topodatapb.Tablet {
+	return env.shardTablets(env.targetKeyspace)
+}
+
+func (env *testEnv) shardTablets(ks *testKeyspace) []*topodatapb.Tablet {
+	tablets := make([]*topodatapb.Tablet, 0, len(ks.shards))
+	for _, shard := range ks.shards {
+		tablets = append(tablets, env.tablets[ks.name+"/"+shard])
+	}
+	return tablets
+}
+
+// testQueryResult is the canned result of the queries matching re. If once
+// is set, it only answers the first of them.
+type testQueryResult struct {
+	re     *regexp.Regexp
+	result *querypb.QueryResult
+	err    error
+	once   bool
+}
+
+// testTMClient is a fake tablet manager client for the tablets of a testEnv.
+// It answers the queries it gets with the results registered for the tablet,
+// and records them so that tests can check what was executed where.
+type testTMClient struct {
+	tmclient.TabletManagerClient
+
+	mu sync.Mutex
+	// results holds the canned results of the queries run on each tablet,
+	// keyed by tablet uid. When several match a query, the last one added
+	// wins, which lets tests override earlier results as the workflow
+	// progresses.
+	results map[uint32][]*testQueryResult
+	// queries holds the queries run on each tablet, keyed by tablet uid.
+	queries map[uint32][]string
+	// schemas holds the schema of each keyspace.
+	schemas map[string]*tabletmanagerdatapb.SchemaDefinition
+}
+
+func newTestTMClient() *testTMClient {
+	return &testTMClient{
+		results: make(map[uint32][]*testQueryResult),
+		queries: make(map[uint32][]string),
+		schemas: make(map[string]*tabletmanagerdatapb.SchemaDefinition),
+	}
+}
+
+// addQuery registers the result of the given query on the tablet.
+func (tmc *testTMClient) addQuery(tablet *topodatapb.Tablet, query string, result *querypb.QueryResult) {
+	tmc.addQueryRE(tablet, "^"+regexp.QuoteMeta(query)+"$", result)
+}
+
+// addQueryOnce registers the result of the next run of the given query on
+// the tablet, which takes precedence over the other results of the query.
+func (tmc *testTMClient) addQueryOnce(tablet *topodatapb.Tablet, query string, result *querypb.QueryResult) {
+	tmc.add(tablet, &testQueryResult{re: regexp.MustCompile("^" + regexp.QuoteMeta(query) + "$"), result: result, once: true})
+}
+
+// addQueryRE registers the result of the queries matching the given regular
+// expression on the tablet.
+func (tmc *testTMClient) addQueryRE(tablet *topodatapb.Tablet, re string, result *querypb.QueryResult) {
+	tmc.add(tablet, &testQueryResult{re: regexp.MustCompile(re), result: result})
+}
+
+// addQueryError registers the error returned by the queries matching the
+// given regular expression on the tablet.
+func (tmc *testTMClient) addQueryError(tablet *topodatapb.Tablet, re string, err error) {
+	tmc.add(tablet, &testQueryResult{re: regexp.MustCompile(re), err: err})
+}
+
+func (tmc *testTMClient) add(tablet *topodatapb.Tablet, qr *testQueryResult) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	if qr.result == nil && qr.err == nil {
+		qr.result = &querypb.QueryResult{}
+	}
+	uid := tablet.Alias.Uid
+	tmc.results[uid] = append(tmc.results[uid], qr)
+}
+
+// executed returns the queries run on the tablet which match the given
+// regular expression.
+func (tmc *testTMClient) executed(tablet *topodatapb.Tablet, re string) []string {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	var queries []string
+	for _, query := range tmc.queries[tablet.Alias.Uid] {
+		if regexp.MustCompile(re).MatchString(query) {
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+func (tmc *testTMClient) exec(tablet *topodatapb.Tablet, query string) (*querypb.QueryResult, error) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	uid := tablet.Alias.Uid
+	tmc.queries[uid] = append(tmc.queries[uid], query)
+	results := tmc.results[uid]
+	for i, qr := range results {
+		if qr.once && qr.re.MatchString(query) {
+			tmc.results[uid] = append(results[:i:i], results[i+1:]...)
+			return qr.result, qr.err
+		}
+	}
+	for i := len(results) - 1; i >= 0; i-- {
+		if results[i].re.MatchString(query) {
+			return results[i].result, results[i].err
+		}
+	}
+	return nil, fmt.Errorf("no result on fake for query %q on tablet %d", query, uid)
+}
+
+func (tmc *testTMClient) VReplicationExec(ctx context.Context, tablet *topodatapb.Tablet, query string) (*querypb.QueryResult, error) {
+	return tmc.exec(tablet, query)
+}
+
+func (tmc *testTMClient) ExecuteFetchAsDba(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsDbaRequest) (*querypb.QueryResult, error) {
+	return tmc.exec(tablet, string(req.Query))
+}
+
+func (tmc *testTMClient) ExecuteFetchAsAllPrivs(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.ExecuteFetchAsAllPrivsRequest) (*querypb.QueryResult, error) {
+	return tmc.exec(tablet, string(req.Query))
+}
+
+func (tmc *testTMClient) ExecuteFetchAsApp(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsAppRequest) (*querypb.QueryResult, error) {
+	return tmc.exec(tablet, string(req.Query))
+}
+
+func (tmc *testTMClient) GetSchema(ctx context.Context, tablet *topodatapb.Tablet, req *tabletmanagerdatapb.GetSchemaRequest) (*tabletmanagerdatapb.SchemaDefinition, error) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	sd, ok := tmc.schemas[tablet.Keyspace]
+	if !ok {
+		return &tabletmanagerdatapb.SchemaDefinition{}, nil
+	}
+	return tmutils.FilterTables(sd, req.Tables, req.ExcludeTables, req.IncludeViews)
+}
+
+// ApplySchema records the schema change as a query run on the tablet.
+func (tmc *testTMClient) ApplySchema(ctx context.Context, tablet *topodatapb.Tablet, change *tmutils.SchemaChange) (*tabletmanagerdatapb.SchemaChangeResult, error) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	uid := tablet.Alias.Uid
+	tmc.queries[uid] = append(tmc.queries[uid], change.SQL)
+	return &tabletmanagerdatapb.SchemaChangeResult{}, nil
+}
+
+func (tmc *testTMClient) PrimaryPosition(ctx context.Context, tablet *topodatapb.Tablet) (string, error) {
+	return testPosition, nil
+}
+
+func (tmc *testTMClient) VReplicationWaitForPos(ctx context.Context, tablet *topodatapb.Tablet, id int32, pos string) error {
+	return nil
+}
+
+func (tmc *testTMClient) RefreshState(ctx context.Context, tablet *topodatapb.Tablet) error {
+	return nil
+}
+
+func (tmc *testTMClient) ResetSequences(ctx context.Context, tablet *topodatapb.Tablet, tables []string) error {
+	return nil
+}
+
+// setSchema sets the schema of the keyspace to the given CREATE TABLE
+// statements, keyed by table name.
+func (tmc *testTMClient) setSchema(keyspace string, tables map[string]string) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	sd := &tabletmanagerdatapb.SchemaDefinition{}
+	for name, ddl := range tables {
+		sd.TableDefinitions = append(sd.TableDefinitions, &tabletmanagerdatapb.TableDefinition{
+			Name:   name,
+			Schema: ddl,
+			Type:   tmutils.TableBaseTable,
+		})
+	}
+	tmc.schemas[keyspace] = sd
+}
+
+// setTable adds the table definition to the schema of the keyspace, replacing
+// any previous definition of the table. Unlike setSchema, it lets tests set
+// the fields of the table.
+func (tmc *testTMClient) setTable(keyspace string, td *tabletmanagerdatapb.TableDefinition) {
+	tmc.mu.Lock()
+	defer tmc.mu.Unlock()
+	sd, ok := tmc.schemas[keyspace]
+	if !ok {
+		sd = &tabletmanagerdatapb.SchemaDefinition{}
+		tmc.schemas[keyspace] = sd
+	}
+	for i, existing := range sd.TableDefinitions {
+		if existing.Name == td.Name {
+			sd.TableDefinitions[i] = td
+			return
+		}
+	}
+	sd.TableDefinitions = append(sd.TableDefinitions, td)
+}
+
+// streamsResult returns the result of the BuildTargets query for the streams
+// with the given ids and sources.
+func streamsResult(t *testing.T, workflowType binlogdatapb.VReplicationWorkflowType, message string, sources map[int32]*binlogdatapb.BinlogSource) *querypb.QueryResult {
+	t.Helper()
+	var rows []string
+	for id, bls := range sources {
+		source, err := prototext.Marshal(bls)
+		require.NoError(t, err)
+		rows = append(rows, fmt.Sprintf("%d|%s|%s|%s|%s|%d|%d|%d",
+			id, source, message, "", "", workflowType, binlogdatapb.VReplicationWorkflowSubType_None, 0))
+	}
+	return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+		"id|source|message|cell|tablet_types|workflow_type|workflow_sub_type|defer_secondary_keys",
+		"int64|varbinary|varchar|varchar|varchar|int64|int64|int64"),
+		rows...,
+	))
+}
+
+// workflowStreamsResult returns the result of the GetWorkflows query for the
+// streams of a workflow of the keyspace with the given ids and sources, which
+// are all up to date.
+func workflowStreamsResult(t *testing.T, keyspace, workflow string, workflowType binlogdatapb.VReplicationWorkflowType, state, message string, sources map[int32]*binlogdatapb.BinlogSource) *querypb.QueryResult {
+	t.Helper()
+	now := time.Now().Unix()
+	var rows []string
+	for id, bls := range sources {
+		source, err := prototext.Marshal(bls)
+		require.NoError(t, err)
+		rows = append(rows, fmt.Sprintf("%d|%s|%s|%s|%s|%d|%s|%s|%d|%d|%d|%s|%s|%d|%d",
+			id, workflow, source, testPosition, "", 0, state, dbName(keyspace), now, now, now, message, "", workflowType, binlogdatapb.VReplicationWorkflowSubType_None))
+	}
+	return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+		"id|workflow|source|pos|stop_pos|max_replication_lag|state|db_name|time_updated|transaction_timestamp|time_heartbeat|message|tags|workflow_type|workflow_sub_type",
+		"int64|varchar|varbinary|varbinary|varbinary|int64|varchar|varchar|int64|int64|int64|varchar|varchar|int64|int64"),
+		rows...,
+	))
+}
+
+// idsResult returns a result holding the given ids in an id column.
+func idsResult(ids ...int64) *querypb.QueryResult {
+	rows := make([]string, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, fmt.Sprintf("%d", id))
+	}
+	return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), rows...))
+}
+
+// dbName returns the database name of the tablets of the keyspace.
+func dbName(keyspace string) string {
+	return "vt_" + keyspace
+}
@@ -0,0 +1,807 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"google.golang.org/protobuf/encoding/prototext"
+	"google.golang.org/protobuf/proto"
+
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// lookupVindexWorkflowSuffix is appended to the name of a lookup table to
+	// name the workflow which backfills it.
+	lookupVindexWorkflowSuffix = "_vdx"
+
+	// stoppedAfterCopyMessage is the message vreplication sets on the streams
+	// it stops once their copy phase is done.
+	stoppedAfterCopyMessage = "Stopped after copy"
+
+	sqlSelectLookupVindexStreams = "select vr.id, vr.source, vr.state, vr.message, vr.rows_copied, count(cs.vrepl_id) from _vt.vreplication vr left join _vt.copy_state cs on cs.vrepl_id = vr.id where vr.workflow = %s and vr.db_name = %s group by vr.id, vr.source, vr.state, vr.message, vr.rows_copied"
+	sqlSelectTableRows           = "select table_rows from information_schema.tables where table_schema = %s and table_name = %s"
+)
+
+// MaterializeCreate is part of the vtctlservicepb.VtctldServer interface.
+// It creates and starts a Materialize workflow, which materializes the results
+// of the settings' source expressions into the target keyspace's tables.
+func (s *Server) MaterializeCreate(ctx context.Context, req *vtctldatapb.MaterializeCreateRequest) (*vtctldatapb.MaterializeCreateResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.MaterializeCreate")
+	defer span.Finish()
+
+	if req.Settings == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "materialize settings must be provided")
+	}
+
+	span.Annotate("keyspace", req.Settings.TargetKeyspace)
+	span.Annotate("workflow", req.Settings.Workflow)
+	span.Annotate("source_keyspace", req.Settings.SourceKeyspace)
+
+	if err := s.materialize(ctx, req.Settings); err != nil {
+		return nil, err
+	}
+	return &vtctldatapb.MaterializeCreateResponse{}, nil
+}
+
+// materialize performs the steps needed to materialize a list of tables based
+// on the materialization specs.
+func (s *Server) materialize(ctx context.Context, ms *vtctldatapb.MaterializeSettings) error {
+	mz, err := s.prepareMaterializerStreams(ctx, ms, workflowTypeForIntent(ms.MaterializationIntent))
+	if err != nil {
+		return err
+	}
+	return mz.startStreams(ctx)
+}
+
+// LookupVindexCreate is part of the vtctlservicepb.VtctldServer interface.
+// It adds the lookup vindex to the source keyspace's vschema, marked
+// write-only, and creates the workflow which backfills its lookup table. The
+// vindex is only used to route queries once it is externalized, which
+// LookupVindexExternalize does after the backfill has finished.
+func (s *Server) LookupVindexCreate(ctx context.Context, req *vtctldatapb.LookupVindexCreateRequest) (*vtctldatapb.LookupVindexCreateResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.LookupVindexCreate")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("continue_after_copy_with_owner", req.ContinueAfterCopyWithOwner)
+
+	if req.Vindex == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "vindex specs must be provided")
+	}
+
+	ms, sourceVSchema, targetVSchema, err := s.prepareCreateLookup(ctx, req.Keyspace, req.Vindex, req.ContinueAfterCopyWithOwner)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ts.SaveVSchema(ctx, ms.TargetKeyspace, targetVSchema); err != nil {
+		return nil, err
+	}
+	ms.Cell = strings.Join(req.Cells, ",")
+	ms.TabletTypes = strings.Join(topoproto.MakeStringTypeList(req.TabletTypes), ",")
+	if err := s.materialize(ctx, ms); err != nil {
+		return nil, err
+	}
+	if err := s.ts.SaveVSchema(ctx, req.Keyspace, sourceVSchema); err != nil {
+		return nil, err
+	}
+	if err := s.ts.RebuildSrvVSchema(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.LookupVindexCreateResponse{
+		Workflow: ms.Workflow,
+	}, nil
+}
+
+// prepareCreateLookup performs the preparatory steps for creating a lookup vindex.
+func (s *Server) prepareCreateLookup(ctx context.Context, keyspace string, specs *vschemapb.Keyspace, continueAfterCopyWithOwner bool) (ms *vtctldatapb.MaterializeSettings, sourceVSchema, targetVSchema *vschemapb.Keyspace, err error) {
+	// Important variables are pulled out here.
+	var (
+		// lookup vindex info
+		vindexName      string
+		vindex          *vschemapb.Vindex
+		targetKeyspace  string
+		targetTableName string
+		vindexFromCols  []string
+		vindexToCol     string
+
+		// source table info
+		sourceTableName string
+		// sourceTable is the supplied table info
+		sourceTable *vschemapb.Table
+		// sourceVSchemaTable is the table info present in the vschema
+		sourceVSchemaTable *vschemapb.Table
+		// sourceVindexColumns are computed from the input sourceTable
+		sourceVindexColumns []string
+
+		// target table info
+		createDDL        string
+		materializeQuery string
+	)
+
+	// Validate input vindex
+	if len(specs.Vindexes) != 1 {
+		return nil, nil, nil, fmt.Errorf("only one vindex must be specified in the specs: %v", specs.Vindexes)
+	}
+	for name, vi := range specs.Vindexes {
+		vindexName = name
+		vindex = vi
+	}
+	if !strings.Contains(vindex.Type, "lookup") {
+		return nil, nil, nil, fmt.Errorf("vindex %s is not a lookup type", vindex.Type)
+	}
+
+	targetKeyspace, targetTableName, err = sqlparser.ParseTable(vindex.Params["table"])
+	if err != nil || targetKeyspace == "" {
+		return nil, nil, nil, fmt.Errorf("vindex table name must be in the form <keyspace>.<table>. Got: %v", vindex.Params["table"])
+	}
+
+	vindexFromCols = strings.Split(vindex.Params["from"], ",")
+	if strings.Contains(vindex.Type, "unique") {
+		if len(vindexFromCols) != 1 {
+			return nil, nil, nil, fmt.Errorf("unique vindex 'from' should have only one column: %v", vindex)
+		}
+	} else {
+		if len(vindexFromCols) < 2 {
+			return nil, nil, nil, fmt.Errorf("non-unique vindex 'from' should have more than one column: %v", vindex)
+		}
+	}
+	vindexToCol = vindex.Params["to"]
+	// Make the vindex write_only. If one exists already in the vschema,
+	// it will need to match this vindex exactly, including the write_only setting.
+	vindex.Params["write_only"] = "true"
+	// See if we can create the vindex without errors.
+	if _, err := vindexes.CreateVindex(vindex.Type, vindexName, vindex.Params); err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Validate input table
+	if len(specs.Tables) != 1 {
+		return nil, nil, nil, fmt.Errorf("exactly one table must be specified in the specs: %v", specs.Tables)
+	}
+	// Loop executes once.
+	for k, ti := range specs.Tables {
+		if len(ti.ColumnVindexes) != 1 {
+			return nil, nil, nil, fmt.Errorf("exactly one ColumnVindex must be specified for the table: %v", specs.Tables)
+		}
+		sourceTableName = k
+		sourceTable = ti
+	}
+
+	// Validate input table and vindex consistency
+	if sourceTable.ColumnVindexes[0].Name != vindexName {
+		return nil, nil, nil, fmt.Errorf("ColumnVindex name must match vindex name: %s vs %s", sourceTable.ColumnVindexes[0].Name, vindexName)
+	}
+	if vindex.Owner != "" && vindex.Owner != sourceTableName {
+		return nil, nil, nil, fmt.Errorf("vindex owner must match table name: %v vs %v", vindex.Owner, sourceTableName)
+	}
+	if len(sourceTable.ColumnVindexes[0].Columns) != 0 {
+		sourceVindexColumns = sourceTable.ColumnVindexes[0].Columns
+	} else {
+		if sourceTable.ColumnVindexes[0].Column == "" {
+			return nil, nil, nil, fmt.Errorf("at least one column must be specified in ColumnVindexes: %v", sourceTable.ColumnVindexes)
+		}
+		sourceVindexColumns = []string{sourceTable.ColumnVindexes[0].Column}
+	}
+	if len(sourceVindexColumns) != len(vindexFromCols) {
+		return nil, nil, nil, fmt.Errorf("length of table columns differes from length of vindex columns: %v vs %v", sourceVindexColumns, vindexFromCols)
+	}
+
+	// Validate against source vschema
+	sourceVSchema, err = s.ts.GetVSchema(ctx, keyspace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if sourceVSchema.Vindexes == nil {
+		sourceVSchema.Vindexes = make(map[string]*vschemapb.Vindex)
+	}
+	// If source and target keyspaces are same, Make vschemas point to the same object.
+	if keyspace == targetKeyspace {
+		targetVSchema = sourceVSchema
+	} else {
+		targetVSchema, err = s.ts.GetVSchema(ctx, targetKeyspace)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if targetVSchema.Vindexes == nil {
+		targetVSchema.Vindexes = make(map[string]*vschemapb.Vindex)
+	}
+	if targetVSchema.Tables == nil {
+		targetVSchema.Tables = make(map[string]*vschemapb.Table)
+	}
+	if existing, ok := sourceVSchema.Vindexes[vindexName]; ok {
+		if !proto.Equal(existing, vindex) {
+			return nil, nil, nil, fmt.Errorf("a conflicting vindex named %s already exists in the source vschema", vindexName)
+		}
+	}
+	sourceVSchemaTable = sourceVSchema.Tables[sourceTableName]
+	if sourceVSchemaTable == nil {
+		if !schema.IsInternalOperationTableName(sourceTableName) {
+			return nil, nil, nil, fmt.Errorf("source table %s not found in vschema", sourceTableName)
+		}
+		sourceVSchemaTable = &vschemapb.Table{}
+		if sourceVSchema.Tables == nil {
+			sourceVSchema.Tables = make(map[string]*vschemapb.Table)
+		}
+		sourceVSchema.Tables[sourceTableName] = sourceVSchemaTable
+	}
+	for _, colVindex := range sourceVSchemaTable.ColumnVindexes {
+		// For a conflict, the vindex name and column should match.
+		if colVindex.Name != vindexName {
+			continue
+		}
+		colName := colVindex.Column
+		if len(colVindex.Columns) != 0 {
+			colName = colVindex.Columns[0]
+		}
+		if colName == sourceVindexColumns[0] {
+			return nil, nil, nil, fmt.Errorf("ColumnVindex for table %v already exists: %v, please remove it and try again", sourceTableName, colName)
+		}
+	}
+
+	// Validate against source schema
+	sourceShards, err := s.ts.GetServingShards(ctx, keyspace)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	onesource := sourceShards[0]
+	if onesource.PrimaryAlias == nil {
+		return nil, nil, nil, fmt.Errorf("source shard has no primary: %v", onesource.ShardName())
+	}
+	req := &tabletmanagerdatapb.GetSchemaRequest{Tables: []string{sourceTableName}}
+	tableSchema, err := schematools.GetSchema(ctx, s.ts, s.tmc, onesource.PrimaryAlias, req)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(tableSchema.TableDefinitions) != 1 {
+		return nil, nil, nil, fmt.Errorf("unexpected number of tables returned from schema: %v", tableSchema.TableDefinitions)
+	}
+
+	// Generate "create table" statement
+	lines := strings.Split(tableSchema.TableDefinitions[0].Schema, "\n")
+	if len(lines) < 3 {
+		// Unreachable
+		return nil, nil, nil, fmt.Errorf("schema looks incorrect: %s, expecting at least four lines", tableSchema.TableDefinitions[0].Schema)
+	}
+	var modified []string
+	modified = append(modified, strings.Replace(lines[0], sourceTableName, targetTableName, 1))
+	for i := range sourceVindexColumns {
+		line, err := generateColDef(lines, sourceVindexColumns[i], vindexFromCols[i])
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		modified = append(modified, line)
+	}
+
+	if vindex.Params["data_type"] == "" || strings.EqualFold(vindex.Type, "consistent_lookup_unique") || strings.EqualFold(vindex.Type, "consistent_lookup") {
+		modified = append(modified, fmt.Sprintf("  %s varbinary(128),", sqlescape.EscapeID(vindexToCol)))
+	} else {
+		modified = append(modified, fmt.Sprintf("  %s %s,", sqlescape.EscapeID(vindexToCol), sqlescape.EscapeID(vindex.Params["data_type"])))
+	}
+	buf := sqlparser.NewTrackedBuffer(nil)
+	fmt.Fprintf(buf, "  PRIMARY KEY (")
+	prefix := ""
+	for _, col := range vindexFromCols {
+		fmt.Fprintf(buf, "%s%s", prefix, sqlescape.EscapeID(col))
+		prefix = ", "
+	}
+	fmt.Fprintf(buf, ")")
+	modified = append(modified, buf.String())
+	modified = append(modified, ")")
+	createDDL = strings.Join(modified, "\n")
+
+	// Generate vreplication query
+	buf = sqlparser.NewTrackedBuffer(nil)
+	buf.Myprintf("select ")
+	for i := range vindexFromCols {
+		buf.Myprintf("%v as %v, ", sqlparser.NewIdentifierCI(sourceVindexColumns[i]), sqlparser.NewIdentifierCI(vindexFromCols[i]))
+	}
+	if strings.EqualFold(vindexToCol, "keyspace_id") || strings.EqualFold(vindex.Type, "consistent_lookup_unique") || strings.EqualFold(vindex.Type, "consistent_lookup") {
+		buf.Myprintf("keyspace_id() as %v ", sqlparser.NewIdentifierCI(vindexToCol))
+	} else {
+		buf.Myprintf("%v as %v ", sqlparser.NewIdentifierCI(vindexToCol), sqlparser.NewIdentifierCI(vindexToCol))
+	}
+	buf.Myprintf("from %v", sqlparser.NewIdentifierCS(sourceTableName))
+	if vindex.Owner != "" {
+		// Only backfill
+		buf.Myprintf(" group by ")
+		for i := range vindexFromCols {
+			buf.Myprintf("%v, ", sqlparser.NewIdentifierCI(vindexFromCols[i]))
+		}
+		buf.Myprintf("%v", sqlparser.NewIdentifierCI(vindexToCol))
+	}
+	materializeQuery = buf.String()
+
+	// Update targetVSchema
+	var targetTable *vschemapb.Table
+	if targetVSchema.Sharded {
+		// Choose a primary vindex type for target table based on source specs
+		var targetVindexType string
+		var targetVindex *vschemapb.Vindex
+		for _, field := range tableSchema.TableDefinitions[0].Fields {
+			if sourceVindexColumns[0] == field.Name {
+				targetVindexType, err = vindexes.ChooseVindexForType(field.Type)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+				targetVindex = &vschemapb.Vindex{
+					Type: targetVindexType,
+				}
+				break
+			}
+		}
+		if targetVindex == nil {
+			// Unreachable. We validated column names when generating the DDL.
+			return nil, nil, nil, fmt.Errorf("column %s not found in schema %v", sourceVindexColumns[0], tableSchema.TableDefinitions[0])
+		}
+		if existing, ok := targetVSchema.Vindexes[targetVindexType]; ok {
+			if !proto.Equal(existing, targetVindex) {
+				return nil, nil, nil, fmt.Errorf("a conflicting vindex named %v already exists in the target vschema", targetVindexType)
+			}
+		} else {
+			targetVSchema.Vindexes[targetVindexType] = targetVindex
+		}
+
+		targetTable = &vschemapb.Table{
+			ColumnVindexes: []*vschemapb.ColumnVindex{{
+				Column: vindexFromCols[0],
+				Name:   targetVindexType,
+			}},
+		}
+	} else {
+		targetTable = &vschemapb.Table{}
+	}
+	if existing, ok := targetVSchema.Tables[targetTableName]; ok {
+		if !proto.Equal(existing, targetTable) {
+			return nil, nil, nil, fmt.Errorf("a conflicting table named %v already exists in the target vschema", targetTableName)
+		}
+	} else {
+		targetVSchema.Tables[targetTableName] = targetTable
+	}
+
+	ms = &vtctldatapb.MaterializeSettings{
+		Workflow:              targetTableName + lookupVindexWorkflowSuffix,
+		MaterializationIntent: vtctldatapb.MaterializationIntent_CREATELOOKUPINDEX,
+		SourceKeyspace:        keyspace,
+		TargetKeyspace:        targetKeyspace,
+		StopAfterCopy:         vindex.Owner != "" && !continueAfterCopyWithOwner,
+		TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+			TargetTable:      targetTableName,
+			SourceExpression: materializeQuery,
+			CreateDdl:        createDDL,
+		}},
+	}
+
+	// Update sourceVSchema
+	sourceVSchema.Vindexes[vindexName] = vindex
+	sourceVSchemaTable.ColumnVindexes = append(sourceVSchemaTable.ColumnVindexes, sourceTable.ColumnVindexes[0])
+
+	return ms, sourceVSchema, targetVSchema, nil
+}
+
+func generateColDef(lines []string, sourceVindexCol, vindexFromCol string) (string, error) {
+	source := sqlescape.EscapeID(sourceVindexCol)
+	target := sqlescape.EscapeID(vindexFromCol)
+
+	for _, line := range lines[1:] {
+		if strings.Contains(line, source) {
+			line = strings.Replace(line, source, target, 1)
+			line = strings.Replace(line, " AUTO_INCREMENT", "", 1)
+			line = strings.Replace(line, " DEFAULT NULL", "", 1)
+			return line, nil
+		}
+	}
+	return "", fmt.Errorf("column %s not found in schema %v", sourceVindexCol, lines)
+}
+
+// lookupVindex holds what the lookup vindex RPCs need to know about a lookup
+// vindex and the workflow which backfills its lookup table.
+type lookupVindex struct {
+	keyspace      string
+	name          string
+	vschema       *vschemapb.Keyspace
+	vindex        *vschemapb.Vindex
+	tableKeyspace string
+	table         string
+	workflow      string
+	targetShards  []*topo.ShardInfo
+}
+
+// getLookupVindex reads the given lookup vindex from the keyspace's vschema,
+// along with the shards of its lookup table's keyspace.
+func (s *Server) getLookupVindex(ctx context.Context, keyspace, name string) (*lookupVindex, error) {
+	vschema, err := s.ts.GetVSchema(ctx, keyspace)
+	if err != nil {
+		return nil, err
+	}
+	vindex := vschema.Vindexes[name]
+	if vindex == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "vindex %s.%s not found in vschema", keyspace, name)
+	}
+
+	tableKeyspace, table, err := sqlparser.ParseTable(vindex.Params["table"])
+	if err != nil || tableKeyspace == "" {
+		return nil, fmt.Errorf("vindex table name must be in the form <keyspace>.<table>. Got: %v", vindex.Params["table"])
+	}
+	targetShards, err := s.ts.GetServingShards(ctx, tableKeyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lookupVindex{
+		keyspace:      keyspace,
+		name:          name,
+		vschema:       vschema,
+		vindex:        vindex,
+		tableKeyspace: tableKeyspace,
+		table:         table,
+		workflow:      table + lookupVindexWorkflowSuffix,
+		targetShards:  targetShards,
+	}, nil
+}
+
+// sourceTable returns the name of the table the lookup vindex is created on.
+func (lv *lookupVindex) sourceTable() string {
+	if lv.vindex.Owner != "" {
+		return lv.vindex.Owner
+	}
+	tables := make([]string, 0, len(lv.vschema.Tables))
+	for name := range lv.vschema.Tables {
+		tables = append(tables, name)
+	}
+	sort.Strings(tables)
+	for _, name := range tables {
+		for _, colVindex := range lv.vschema.Tables[name].ColumnVindexes {
+			if colVindex.Name == lv.name {
+				return name
+			}
+		}
+	}
+	return ""
+}
+
+// forAllLookupTargets runs f concurrently on the primary of every shard of the
+// lookup table's keyspace.
+func (s *Server) forAllLookupTargets(ctx context.Context, lv *lookupVindex, f func(*topo.TabletInfo) error) error {
+	var wg sync.WaitGroup
+	allErrors := &concurrency.AllErrorRecorder{}
+	for _, targetShard := range lv.targetShards {
+		wg.Add(1)
+		go func(targetShard *topo.ShardInfo) {
+			defer wg.Done()
+
+			if targetShard.PrimaryAlias == nil {
+				allErrors.RecordError(fmt.Errorf("shard %s/%s has no primary", targetShard.Keyspace(), targetShard.ShardName()))
+				return
+			}
+			targetPrimary, err := s.ts.GetTablet(ctx, targetShard.PrimaryAlias)
+			if err != nil {
+				allErrors.RecordError(err)
+				return
+			}
+			if err := f(targetPrimary); err != nil {
+				allErrors.RecordError(err)
+			}
+		}(targetShard)
+	}
+	wg.Wait()
+	return allErrors.AggrError(vterrors.Aggregate)
+}
+
+// lookupVindexStream is a stream of a lookup vindex's backfill workflow.
+type lookupVindexStream struct {
+	id          int64
+	targetShard string
+	source      *binlogdatapb.BinlogSource
+	state       string
+	message     string
+	rowsCopied  int64
+	copying     bool
+}
+
+// copyComplete returns whether the stream has finished its copy phase.
+func (st *lookupVindexStream) copyComplete() bool {
+	if st.copying {
+		return false
+	}
+	switch st.state {
+	case binlogplayer.BlpRunning:
+		return true
+	case binlogplayer.BlpStopped:
+		return strings.Contains(st.message, stoppedAfterCopyMessage)
+	}
+	return false
+}
+
+// getLookupVindexStreams returns the streams of the lookup vindex's backfill
+// workflow across all the shards of its lookup table's keyspace.
+func (s *Server) getLookupVindexStreams(ctx context.Context, lv *lookupVindex) ([]*lookupVindexStream, error) {
+	var (
+		m       sync.Mutex
+		streams []*lookupVindexStream
+	)
+	err := s.forAllLookupTargets(ctx, lv, func(targetPrimary *topo.TabletInfo) error {
+		query := fmt.Sprintf(sqlSelectLookupVindexStreams, encodeString(lv.workflow), encodeString(targetPrimary.DbName()))
+		p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, targetPrimary.Tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(query),
+			MaxRows: 1000,
+		})
+		if err != nil {
+			return err
+		}
+		qr := sqltypes.Proto3ToResult(p3qr)
+		shardStreams := make([]*lookupVindexStream, 0, len(qr.Rows))
+		for _, row := range qr.Rows {
+			id, err := evalengine.ToInt64(row[0])
+			if err != nil {
+				return err
+			}
+			var bls binlogdatapb.BinlogSource
+			sourceBytes, err := row[1].ToBytes()
+			if err != nil {
+				return err
+			}
+			if err := prototext.Unmarshal(sourceBytes, &bls); err != nil {
+				return err
+			}
+			rowsCopied, err := evalengine.ToInt64(row[4])
+			if err != nil {
+				return err
+			}
+			copyStates, err := evalengine.ToInt64(row[5])
+			if err != nil {
+				return err
+			}
+			shardStreams = append(shardStreams, &lookupVindexStream{
+				id:          id,
+				targetShard: targetPrimary.Shard,
+				source:      &bls,
+				state:       row[2].ToString(),
+				message:     row[3].ToString(),
+				rowsCopied:  rowsCopied,
+				copying:     copyStates > 0,
+			})
+		}
+
+		m.Lock()
+		defer m.Unlock()
+		streams = append(streams, shardStreams...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
+// LookupVindexStatus is part of the vtctlservicepb.VtctldServer interface.
+// It reports the backfill progress of a lookup vindex for each of its source
+// shards.
+func (s *Server) LookupVindexStatus(ctx context.Context, req *vtctldatapb.LookupVindexStatusRequest) (*vtctldatapb.LookupVindexStatusResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.LookupVindexStatus")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+
+	lv, err := s.getLookupVindex(ctx, req.Keyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	streams, err := s.getLookupVindexStreams(ctx, lv)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.LookupVindexStatusResponse{
+		Workflow:      lv.workflow,
+		TableKeyspace: lv.tableKeyspace,
+		Table:         lv.table,
+		WriteOnly:     lv.vindex.Params["write_only"] == "true",
+		SourceShards:  make(map[string]*vtctldatapb.LookupVindexStatusResponse_SourceShardProgress),
+	}
+	if len(streams) == 0 {
+		// The backfill workflow is gone, which only happens once the vindex
+		// has been externalized.
+		resp.BackfillComplete = !resp.WriteOnly
+		return resp, nil
+	}
+
+	sort.Slice(streams, func(i, j int) bool {
+		if streams[i].targetShard != streams[j].targetShard {
+			return streams[i].targetShard < streams[j].targetShard
+		}
+		return streams[i].id < streams[j].id
+	})
+	resp.BackfillComplete = true
+	for _, st := range streams {
+		progress, ok := resp.SourceShards[st.source.Shard]
+		if !ok {
+			progress = &vtctldatapb.LookupVindexStatusResponse_SourceShardProgress{
+				State:        st.state,
+				CopyComplete: true,
+			}
+			resp.SourceShards[st.source.Shard] = progress
+		}
+		if progress.State != st.state {
+			progress.State = "Mixed"
+		}
+		if !st.copyComplete() {
+			progress.CopyComplete = false
+			resp.BackfillComplete = false
+		}
+		progress.RowsCopied += st.rowsCopied
+		if st.message != "" {
+			progress.Messages = append(progress.Messages, fmt.Sprintf("%s/%s: %s", lv.tableKeyspace, st.targetShard, st.message))
+		}
+	}
+
+	if sourceTable := lv.sourceTable(); sourceTable != "" {
+		if err := s.getLookupVindexSourceRows(ctx, lv.keyspace, sourceTable, resp.SourceShards); err != nil {
+			return nil, err
+		}
+	}
+	for _, progress := range resp.SourceShards {
+		if progress.RowsTotal > 0 {
+			progress.RowsPercentage = float32(100.0 * float64(progress.RowsCopied) / float64(progress.RowsTotal))
+		}
+	}
+
+	return resp, nil
+}
+
+// getLookupVindexSourceRows sets the estimated number of rows of the source
+// table in each of the given source shards.
+func (s *Server) getLookupVindexSourceRows(ctx context.Context, keyspace, table string, progress map[string]*vtctldatapb.LookupVindexStatusResponse_SourceShardProgress) error {
+	var (
+		m         sync.Mutex
+		wg        sync.WaitGroup
+		allErrors = &concurrency.AllErrorRecorder{}
+	)
+	for shard := range progress {
+		wg.Add(1)
+		go func(shard string) {
+			defer wg.Done()
+
+			si, err := s.ts.GetShard(ctx, keyspace, shard)
+			if err != nil {
+				allErrors.RecordError(err)
+				return
+			}
+			if si.PrimaryAlias == nil {
+				allErrors.RecordError(fmt.Errorf("shard %s/%s has no primary", keyspace, shard))
+				return
+			}
+			sourcePrimary, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+			if err != nil {
+				allErrors.RecordError(err)
+				return
+			}
+			query := fmt.Sprintf(sqlSelectTableRows, encodeString(sourcePrimary.DbName()), encodeString(table))
+			p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, sourcePrimary.Tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+				Query:   []byte(query),
+				MaxRows: 1,
+			})
+			if err != nil {
+				allErrors.RecordError(err)
+				return
+			}
+			qr := sqltypes.Proto3ToResult(p3qr)
+			if len(qr.Rows) == 0 {
+				return
+			}
+			rows, err := evalengine.ToInt64(qr.Rows[0][0])
+			if err != nil {
+				allErrors.RecordError(err)
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			progress[shard].RowsTotal = rows
+		}(shard)
+	}
+	wg.Wait()
+	return allErrors.AggrError(vterrors.Aggregate)
+}
+
+// LookupVindexExternalize is part of the vtctlservicepb.VtctldServer interface.
+// It makes a lookup vindex whose backfill has finished usable for routing
+// queries, by removing its write_only param. If the vindex has an owner, the
+// backfill workflow is deleted as the owner keeps the lookup table up to date
+// from then on.
+func (s *Server) LookupVindexExternalize(ctx context.Context, req *vtctldatapb.LookupVindexExternalizeRequest) (*vtctldatapb.LookupVindexExternalizeResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.LookupVindexExternalize")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("name", req.Name)
+
+	lv, err := s.getLookupVindex(ctx, req.Keyspace, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	streams, err := s.getLookupVindexStreams(ctx, lv)
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range streams {
+		if lv.vindex.Owner == "" || !st.source.StopAfterCopy {
+			// If there's no owner or we've requested that the workflow NOT be stopped
+			// after the copy phase completes, then all streams need to be running.
+			if st.state != binlogplayer.BlpRunning || st.copying {
+				return nil, fmt.Errorf("stream %d for %v.%v is not in Running state: %v", st.id, lv.tableKeyspace, st.targetShard, st.state)
+			}
+		} else {
+			// If there is an owner, all streams need to be stopped after copy.
+			if st.state != binlogplayer.BlpStopped || !strings.Contains(st.message, stoppedAfterCopyMessage) {
+				return nil, fmt.Errorf("stream %d for %v.%v is not in Stopped after copy state: %v, %v", st.id, lv.tableKeyspace, st.targetShard, st.state, st.message)
+			}
+		}
+	}
+
+	resp := &vtctldatapb.LookupVindexExternalizeResponse{}
+	if lv.vindex.Owner != "" && len(streams) > 0 {
+		// If there is an owner, we have to delete the streams.
+		err := s.forAllLookupTargets(ctx, lv, func(targetPrimary *topo.TabletInfo) error {
+			query := fmt.Sprintf("delete from _vt.vreplication where db_name=%s and workflow=%s", encodeString(targetPrimary.DbName()), encodeString(lv.workflow))
+			_, err := s.tmc.VReplicationExec(ctx, targetPrimary.Tablet, query)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		resp.WorkflowDeleted = true
+	}
+
+	// Remove the write_only param and save the source vschema.
+	delete(lv.vindex.Params, "write_only")
+	if err := s.ts.SaveVSchema(ctx, lv.keyspace, lv.vschema); err != nil {
+		return nil, err
+	}
+	if err := s.ts.RebuildSrvVSchema(ctx, nil); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
@@ -0,0 +1,1326 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/prototext"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+func TestLookupVindexStreamCopyComplete(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		stream *lookupVindexStream
+		want   bool
+	}{
+		{
+			name:   "copying",
+			stream: &lookupVindexStream{state: "Running", copying: true},
+			want:   false,
+		},
+		{
+			name:   "running after copy",
+			stream: &lookupVindexStream{state: "Running"},
+			want:   true,
+		},
+		{
+			name:   "stopped after copy",
+			stream: &lookupVindexStream{state: "Stopped", message: "Stopped after copy."},
+			want:   true,
+		},
+		{
+			name:   "stopped before copy",
+			stream: &lookupVindexStream{state: "Stopped"},
+			want:   false,
+		},
+		{
+			name:   "not started",
+			stream: &lookupVindexStream{state: "Init"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.stream.copyComplete())
+		})
+	}
+}
+
+// newLookupVindexTestServer returns a Server on a topo with a customer
+// keyspace, sharded in two, in which the corder_lookup vindex owned by the
+// corder table is being backfilled.
+func newLookupVindexTestServer(t *testing.T, tmc *fakeTMC) *Server {
+	ctx := context.Background()
+	ts := memorytopo.NewServer("zone1")
+
+	require.NoError(t, ts.CreateKeyspace(ctx, "customer", &topodatapb.Keyspace{}))
+	for i, shard := range []string{"-80", "80-"} {
+		require.NoError(t, ts.CreateShard(ctx, "customer", shard))
+		alias := &topodatapb.TabletAlias{Cell: "zone1", Uid: uint32(100 * (i + 1))}
+		require.NoError(t, ts.CreateTablet(ctx, &topodatapb.Tablet{
+			Alias:    alias,
+			Keyspace: "customer",
+			Shard:    shard,
+			Type:     topodatapb.TabletType_PRIMARY,
+		}))
+		_, err := ts.UpdateShardFields(ctx, "customer", shard, func(si *topo.ShardInfo) error {
+			si.PrimaryAlias = alias
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	require.NoError(t, ts.SaveVSchema(ctx, "customer", &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+			"corder_lookup": {
+				Type: "consistent_lookup_unique",
+				Params: map[string]string{
+					"table":      "customer.corder_lookup",
+					"from":       "sku",
+					"to":         "keyspace_id",
+					"write_only": "true",
+				},
+				Owner: "corder",
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"corder": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{
+					{Column: "customer_id", Name: "hash"},
+					{Column: "sku", Name: "corder_lookup"},
+				},
+			},
+		},
+	}))
+
+	return NewServer(ts, tmc)
+}
+
+func lookupVindexStreamsResult(rows ...string) *querypb.QueryResult {
+	return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+		"id|source|state|message|rows_copied|count",
+		"int64|varbinary|varchar|varchar|int64|int64"),
+		rows...,
+	))
+}
+
+func lookupVindexSource(t *testing.T, shard string) string {
+	source, err := prototext.Marshal(&binlogdatapb.BinlogSource{
+		Keyspace:      "customer",
+		Shard:         shard,
+		StopAfterCopy: true,
+	})
+	require.NoError(t, err)
+	return string(source)
+}
+
+func TestLookupVindexStatus(t *testing.T) {
+	t.Parallel()
+
+	streamsQuery := fmt.Sprintf(sqlSelectLookupVindexStreams, "'corder_lookup_vdx'", "'vt_customer'")
+	rowsQuery := fmt.Sprintf(sqlSelectTableRows, "'vt_customer'", "'corder'")
+	tableRows := func(rows string) *querypb.QueryResult {
+		return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_rows", "int64"), rows))
+	}
+
+	tmc := &fakeTMC{
+		dbaQueriesByTablet: map[string]map[string]*querypb.QueryResult{
+			"zone1-0000000100": {
+				streamsQuery: lookupVindexStreamsResult(
+					fmt.Sprintf("1|%s|Stopped|Stopped after copy|10|0", lookupVindexSource(t, "-80")),
+					fmt.Sprintf("2|%s|Running||5|1", lookupVindexSource(t, "80-")),
+				),
+				rowsQuery: tableRows("20"),
+			},
+			"zone1-0000000200": {
+				streamsQuery: lookupVindexStreamsResult(
+					fmt.Sprintf("1|%s|Stopped|Stopped after copy|10|0", lookupVindexSource(t, "-80")),
+					fmt.Sprintf("2|%s|Stopped|Stopped after copy|8|0", lookupVindexSource(t, "80-")),
+				),
+				rowsQuery: tableRows("26"),
+			},
+		},
+	}
+	s := newLookupVindexTestServer(t, tmc)
+
+	resp, err := s.LookupVindexStatus(context.Background(), &vtctldatapb.LookupVindexStatusRequest{
+		Keyspace: "customer",
+		Name:     "corder_lookup",
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.LookupVindexStatusResponse{
+		Workflow:         "corder_lookup_vdx",
+		TableKeyspace:    "customer",
+		Table:            "corder_lookup",
+		WriteOnly:        true,
+		BackfillComplete: false,
+		SourceShards: map[string]*vtctldatapb.LookupVindexStatusResponse_SourceShardProgress{
+			"-80": {
+				State:          "Stopped",
+				CopyComplete:   true,
+				RowsCopied:     20,
+				RowsTotal:      20,
+				RowsPercentage: 100,
+				Messages:       []string{"customer/-80: Stopped after copy", "customer/80-: Stopped after copy"},
+			},
+			"80-": {
+				State:          "Mixed",
+				CopyComplete:   false,
+				RowsCopied:     13,
+				RowsTotal:      26,
+				RowsPercentage: 50,
+				Messages:       []string{"customer/80-: Stopped after copy"},
+			},
+		},
+	}, resp)
+
+	// The vindex cannot be externalized while its backfill is in progress.
+	_, err = s.LookupVindexExternalize(context.Background(), &vtctldatapb.LookupVindexExternalizeRequest{
+		Keyspace: "customer",
+		Name:     "corder_lookup",
+	})
+	assert.ErrorContains(t, err, "is not in Stopped after copy state")
+}
+
+// lookupTestSourceSchema is the schema of the t1 table of the sourceks
+// keyspace the lookup vindexes are created on.
+const lookupTestSourceSchema = "CREATE TABLE `t1` (\n" +
+	"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
+	"  `col2` int(11) DEFAULT NULL,\n" +
+	"  PRIMARY KEY (`id`)\n" +
+	") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1"
+
+// newCreateLookupTestEnv returns a testEnv with a sourceks keyspace, in which
+// the t1 table is sharded by its col1 column, and a targetks keyspace with the
+// given shards and an empty vschema.
+func newCreateLookupTestEnv(t *testing.T, targetShards ...string) *testEnv {
+	env := newTestEnv(t, &testKeyspace{
+		name:   "sourceks",
+		shards: []string{"0"},
+		vs: &vschemapb.Keyspace{
+			Sharded: true,
+			Vindexes: map[string]*vschemapb.Vindex{
+				"hash": {Type: "hash"},
+			},
+			Tables: map[string]*vschemapb.Table{
+				"t1": {
+					ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "col1"}},
+				},
+			},
+		},
+	}, &testKeyspace{
+		name:   "targetks",
+		shards: targetShards,
+	})
+	env.tmc.setTable("sourceks", lookupTestSourceTable(querypb.Type_INT64, lookupTestSourceSchema))
+	return env
+}
+
+// lookupTestSourceTable returns the definition of the t1 table, whose col2
+// column is of the given type.
+func lookupTestSourceTable(col2Type querypb.Type, schema string) *tabletmanagerdatapb.TableDefinition {
+	return &tabletmanagerdatapb.TableDefinition{
+		Name:   "t1",
+		Schema: schema,
+		Type:   tmutils.TableBaseTable,
+		Fields: []*querypb.Field{
+			{Name: "col1", Type: querypb.Type_INT64},
+			{Name: "col2", Type: col2Type},
+		},
+	}
+}
+
+// lookupUniqueSpecs returns the specs of a lookup_unique vindex named v on the
+// col2 column of t1, backed by the given lookup table. The vindex is owned by
+// t1 unless owner is false.
+func lookupUniqueSpecs(table string, owner bool) *vschemapb.Keyspace {
+	specs := &vschemapb.Keyspace{
+		Vindexes: map[string]*vschemapb.Vindex{
+			"v": {
+				Type: "lookup_unique",
+				Params: map[string]string{
+					"table": table,
+					"from":  "c1",
+					"to":    "c2",
+				},
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v", Column: "col2"}},
+			},
+		},
+	}
+	if owner {
+		specs.Vindexes["v"].Owner = "t1"
+	}
+	return specs
+}
+
+// lookupTestSourceVSchema returns the vschema of sourceks once the given
+// vindex has been added to it, on the col2 column of t1.
+func lookupTestSourceVSchema(vindex *vschemapb.Vindex) *vschemapb.Keyspace {
+	return &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+			"v":    vindex,
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{
+					{Name: "hash", Column: "col1"},
+					{Name: "v", Column: "col2"},
+				},
+			},
+		},
+	}
+}
+
+func TestLookupVindexCreate(t *testing.T) {
+	writeOnlyVindex := func(owner string) *vschemapb.Vindex {
+		return &vschemapb.Vindex{
+			Type: "lookup_unique",
+			Params: map[string]string{
+				"table":      "targetks.lkp",
+				"from":       "c1",
+				"to":         "c2",
+				"write_only": "true",
+			},
+			Owner: owner,
+		}
+	}
+	shardedTargetVSchema := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"lkp": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "c1", Name: "hash"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name                       string
+		targetShards               []string
+		targetVSchema              *vschemapb.Keyspace
+		owner                      bool
+		continueAfterCopyWithOwner bool
+		exists                     bool
+
+		wantErr           string
+		wantSourceVSchema *vschemapb.Keyspace
+		wantTargetVSchema *vschemapb.Keyspace
+		wantFilter        string
+		wantStopAfterCopy bool
+	}{
+		{
+			name:              "owned, sharded target",
+			targetShards:      []string{"-80", "80-"},
+			targetVSchema:     &vschemapb.Keyspace{Sharded: true},
+			owner:             true,
+			wantSourceVSchema: lookupTestSourceVSchema(writeOnlyVindex("t1")),
+			wantTargetVSchema: shardedTargetVSchema,
+			wantFilter:        "select col2 as c1, c2 as c2 from t1 where in_keyrange(col2, 'targetks.hash', '{{.keyrange}}') group by c1, c2",
+			wantStopAfterCopy: true,
+		},
+		{
+			name:                       "owned, continue after copy",
+			targetShards:               []string{"-80", "80-"},
+			targetVSchema:              &vschemapb.Keyspace{Sharded: true},
+			owner:                      true,
+			continueAfterCopyWithOwner: true,
+			wantSourceVSchema:          lookupTestSourceVSchema(writeOnlyVindex("t1")),
+			wantTargetVSchema:          shardedTargetVSchema,
+			wantFilter:                 "select col2 as c1, c2 as c2 from t1 where in_keyrange(col2, 'targetks.hash', '{{.keyrange}}') group by c1, c2",
+		},
+		{
+			name:              "not owned, unsharded target",
+			targetShards:      []string{"0"},
+			targetVSchema:     &vschemapb.Keyspace{},
+			wantSourceVSchema: lookupTestSourceVSchema(writeOnlyVindex("")),
+			wantTargetVSchema: &vschemapb.Keyspace{
+				Tables: map[string]*vschemapb.Table{
+					"lkp": {},
+				},
+			},
+			wantFilter: "select col2 as c1, c2 as c2 from t1",
+		},
+		{
+			name:          "existing workflow",
+			targetShards:  []string{"0"},
+			targetVSchema: &vschemapb.Keyspace{},
+			owner:         true,
+			exists:        true,
+			wantErr:       "workflow lkp_vdx already exists in keyspace targetks",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newCreateLookupTestEnv(t, tt.targetShards...)
+			require.NoError(t, env.ts.SaveVSchema(ctx, "targetks", tt.targetVSchema))
+			for _, tablet := range env.targetTablets() {
+				exists := "select 1 from _vt.vreplication where db_name='vt_targetks' and workflow='lkp_vdx'"
+				if tt.exists {
+					env.tmc.addQuery(tablet, exists, sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1")))
+				} else {
+					env.tmc.addQuery(tablet, exists, nil)
+				}
+				env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_targetks' and message='FROZEN' and workflow_sub_type != 1", nil)
+				env.tmc.addQueryRE(tablet, `^insert into _vt\.vreplication`, nil)
+				env.tmc.addQuery(tablet, "update _vt.vreplication set state='Running' where db_name='vt_targetks' and workflow='lkp_vdx'", nil)
+			}
+
+			resp, err := env.ws.LookupVindexCreate(ctx, &vtctldatapb.LookupVindexCreateRequest{
+				Keyspace:                   "sourceks",
+				Vindex:                     lookupUniqueSpecs("targetks.lkp", tt.owner),
+				Cells:                      []string{testCell},
+				TabletTypes:                []topodatapb.TabletType{topodatapb.TabletType_PRIMARY},
+				ContinueAfterCopyWithOwner: tt.continueAfterCopyWithOwner,
+			})
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				// The vindex is only added to the source vschema once its
+				// workflow has been created.
+				vs, err := env.ts.GetVSchema(ctx, "sourceks")
+				require.NoError(t, err)
+				utils.MustMatch(t, env.sourceKeyspace.vs, vs)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "lkp_vdx", resp.Workflow)
+
+			vs, err := env.ts.GetVSchema(ctx, "sourceks")
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.wantSourceVSchema, vs)
+			vs, err = env.ts.GetVSchema(ctx, "targetks")
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.wantTargetVSchema, vs)
+
+			// The serving vschema knows about the vindex, which is write-only
+			// until it is externalized.
+			srvVSchema, err := env.ts.GetSrvVSchema(ctx, testCell)
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.wantSourceVSchema, srvVSchema.Keyspaces["sourceks"])
+
+			for _, tablet := range env.targetTablets() {
+				assert.Equal(t, []string{"CREATE TABLE `lkp` (\n  `c1` int(11),\n  `c2` varbinary(128),\n  PRIMARY KEY (`c1`)\n)"},
+					env.tmc.executed(tablet, "^CREATE TABLE"), "tablet %d", tablet.Alias.Uid)
+
+				inserts := env.tmc.executed(tablet, `^insert into _vt\.vreplication`)
+				require.Len(t, inserts, 1, "tablet %d", tablet.Alias.Uid)
+				keyrange := tablet.Shard
+				if keyrange == "0" {
+					keyrange = "-"
+				}
+				filter := strings.ReplaceAll(tt.wantFilter, "{{.keyrange}}", keyrange)
+				assert.Contains(t, inserts[0], fmt.Sprintf(`filter:\"%s\"`, strings.ReplaceAll(filter, "'", `\'`)), "tablet %d", tablet.Alias.Uid)
+				if tt.wantStopAfterCopy {
+					assert.Contains(t, inserts[0], "stop_after_copy:true", "tablet %d", tablet.Alias.Uid)
+				} else {
+					assert.NotContains(t, inserts[0], "stop_after_copy", "tablet %d", tablet.Alias.Uid)
+				}
+				assert.Contains(t, inserts[0], fmt.Sprintf(", '%s', '%s', ", testCell, "primary"), "tablet %d", tablet.Alias.Uid)
+				assert.Contains(t, inserts[0], fmt.Sprintf(", 'vt_targetks', %d, ", binlogdatapb.VReplicationWorkflowType_CreateLookupIndex), "tablet %d", tablet.Alias.Uid)
+				assert.Len(t, env.tmc.executed(tablet, `^update _vt\.vreplication set state='Running'`), 1, "tablet %d", tablet.Alias.Uid)
+			}
+		})
+	}
+
+	t.Run("no vindex", func(t *testing.T) {
+		env := newCreateLookupTestEnv(t, "0")
+		_, err := env.ws.LookupVindexCreate(context.Background(), &vtctldatapb.LookupVindexCreateRequest{
+			Keyspace: "sourceks",
+		})
+		require.ErrorContains(t, err, "vindex specs must be provided")
+	})
+}
+
+func TestPrepareCreateLookupDDL(t *testing.T) {
+	tests := []struct {
+		name         string
+		specs        *vschemapb.Keyspace
+		sourceSchema string
+		want         string
+		wantErr      string
+	}{
+		{
+			name:  "unique lookup",
+			specs: lookupUniqueSpecs("targetks.lkp", true),
+			sourceSchema: "CREATE TABLE `t1` (\n" +
+				"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
+				"  `col2` int(11) DEFAULT NULL,\n" +
+				"  `col3` int(11) DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1",
+			want: "CREATE TABLE `lkp` (\n" +
+				"  `c1` int(11),\n" +
+				"  `c2` varbinary(128),\n" +
+				"  PRIMARY KEY (`c1`)\n" +
+				")",
+		},
+		{
+			name:  "unique lookup, also pk",
+			specs: lookupUniqueSpecs("targetks.lkp", true),
+			sourceSchema: "CREATE TABLE `t1` (\n" +
+				"  `col2` int(11) NOT NULL AUTO_INCREMENT,\n" +
+				"  `col1` int(11) DEFAULT NULL,\n" +
+				"  `col4` int(11) DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1",
+			want: "CREATE TABLE `lkp` (\n" +
+				"  `c1` int(11) NOT NULL,\n" +
+				"  `c2` varbinary(128),\n" +
+				"  PRIMARY KEY (`c1`)\n" +
+				")",
+		},
+		{
+			name: "non-unique lookup, also pk",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"v": {
+						Type: "lookup",
+						Params: map[string]string{
+							"table": "targetks.lkp",
+							"from":  "c1,c2",
+							"to":    "c3",
+						},
+						Owner: "t1",
+					},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v", Columns: []string{"col2", "col1"}}},
+					},
+				},
+			},
+			sourceSchema: "CREATE TABLE `t1` (\n" +
+				"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
+				"  `col2` int(11) NOT NULL,\n" +
+				"  `col3` int(11) DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1",
+			want: "CREATE TABLE `lkp` (\n" +
+				"  `c1` int(11) NOT NULL,\n" +
+				"  `c2` int(11) NOT NULL,\n" +
+				"  `c3` varbinary(128),\n" +
+				"  PRIMARY KEY (`c1`, `c2`)\n" +
+				")",
+		},
+		{
+			name: "column missing",
+			specs: &vschemapb.Keyspace{
+				Vindexes: lookupUniqueSpecs("targetks.lkp", true).Vindexes,
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v", Column: "nocol"}},
+					},
+				},
+			},
+			sourceSchema: "CREATE TABLE `t1` (\n" +
+				"  `col1` int(11) NOT NULL AUTO_INCREMENT,\n" +
+				"  `col2` int(11) NOT NULL,\n" +
+				"  `col3` int(11) DEFAULT NULL,\n" +
+				"  PRIMARY KEY (`id`)\n" +
+				") ENGINE=InnoDB AUTO_INCREMENT=3 DEFAULT CHARSET=latin1",
+			wantErr: "column nocol not found in schema",
+		},
+		{
+			name:    "no table in schema",
+			specs:   lookupUniqueSpecs("targetks.lkp", true),
+			wantErr: "unexpected number of tables returned from schema",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newCreateLookupTestEnv(t, "0")
+			if tt.sourceSchema != "" {
+				env.tmc.setTable("sourceks", lookupTestSourceTable(querypb.Type_INT64, tt.sourceSchema))
+			} else {
+				env.tmc.setSchema("sourceks", nil)
+			}
+
+			ms, _, _, err := env.ws.prepareCreateLookup(context.Background(), "sourceks", tt.specs, false)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Len(t, ms.TableSettings, 1)
+			assert.Equal(t, strings.Split(tt.want, "\n"), strings.Split(ms.TableSettings[0].CreateDdl, "\n"))
+		})
+	}
+}
+
+func TestPrepareCreateLookupSourceVSchema(t *testing.T) {
+	vindex := &vschemapb.Vindex{
+		Type: "lookup_unique",
+		Params: map[string]string{
+			"table":      "targetks.lkp",
+			"from":       "c1",
+			"to":         "c2",
+			"write_only": "true",
+		},
+		Owner: "t1",
+	}
+
+	tests := []struct {
+		name          string
+		sourceVSchema *vschemapb.Keyspace
+		want          *vschemapb.Keyspace
+	}{
+		{
+			name: "source vschema has no prior info",
+			sourceVSchema: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "col1"}},
+					},
+				},
+			},
+			want: lookupTestSourceVSchema(vindex),
+		},
+		{
+			name: "source vschema has the lookup vindex",
+			sourceVSchema: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+					"v":    vindex,
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "hash", Column: "col1"}},
+					},
+				},
+			},
+			want: lookupTestSourceVSchema(vindex),
+		},
+		{
+			name: "source vschema table has a different vindex on same column",
+			sourceVSchema: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+					"v":    vindex,
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{
+							{Name: "hash", Column: "col1"},
+							{Name: "hash", Column: "col2"},
+						},
+					},
+				},
+			},
+			want: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+					"v":    vindex,
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{
+							{Name: "hash", Column: "col1"},
+							{Name: "hash", Column: "col2"},
+							{Name: "v", Column: "col2"},
+						},
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newCreateLookupTestEnv(t, "0")
+			require.NoError(t, env.ts.SaveVSchema(ctx, "sourceks", tt.sourceVSchema))
+
+			_, got, _, err := env.ws.prepareCreateLookup(ctx, "sourceks", lookupUniqueSpecs("targetks.lkp", true), false)
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrepareCreateLookupTargetVSchema(t *testing.T) {
+	// withTable is a target vschema with a pre-existing table.
+	withTable := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t2": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "c1", Name: "hash"}},
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		targetTable     string
+		sourceFieldType querypb.Type
+		targetVSchema   *vschemapb.Keyspace
+		want            *vschemapb.Keyspace
+		wantErr         string
+	}{
+		{
+			name:            "sharded, int64, empty target",
+			targetTable:     "lkp",
+			sourceFieldType: querypb.Type_INT64,
+			targetVSchema:   &vschemapb.Keyspace{Sharded: true},
+			want: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"lkp": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "c1", Name: "hash"}},
+					},
+				},
+			},
+		},
+		{
+			name:            "sharded, varchar, empty target",
+			targetTable:     "lkp",
+			sourceFieldType: querypb.Type_VARCHAR,
+			targetVSchema:   &vschemapb.Keyspace{Sharded: true},
+			want: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"unicode_loose_md5": {Type: "unicode_loose_md5"},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"lkp": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "c1", Name: "unicode_loose_md5"}},
+					},
+				},
+			},
+		},
+		{
+			name:            "sharded, int64, good vindex",
+			targetTable:     "lkp",
+			sourceFieldType: querypb.Type_INT64,
+			targetVSchema: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+				},
+			},
+			want: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"lkp": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "c1", Name: "hash"}},
+					},
+				},
+			},
+		},
+		{
+			name:            "sharded, int64, bad vindex",
+			targetTable:     "lkp",
+			sourceFieldType: querypb.Type_INT64,
+			targetVSchema: &vschemapb.Keyspace{
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					// A misleading vindex name.
+					"hash": {Type: "unicode_loose_md5"},
+				},
+			},
+			wantErr: "a conflicting vindex named hash already exists in the target vschema",
+		},
+		{
+			name:            "sharded, int64, good table",
+			targetTable:     "t2",
+			sourceFieldType: querypb.Type_INT64,
+			targetVSchema:   withTable,
+			want:            withTable,
+		},
+		{
+			name:            "sharded, varchar, table mismatch",
+			targetTable:     "t2",
+			sourceFieldType: querypb.Type_VARCHAR,
+			targetVSchema:   withTable,
+			wantErr:         "a conflicting table named t2 already exists in the target vschema",
+		},
+		{
+			name:            "unsharded",
+			targetTable:     "lkp",
+			sourceFieldType: querypb.Type_INT64,
+			targetVSchema:   &vschemapb.Keyspace{},
+			want: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{},
+				Tables: map[string]*vschemapb.Table{
+					"lkp": {},
+				},
+			},
+		},
+		{
+			name:            "invalid column type",
+			targetTable:     "lkp",
+			sourceFieldType: querypb.Type_SET,
+			targetVSchema:   &vschemapb.Keyspace{Sharded: true},
+			wantErr:         "type SET is not recommended for a vindex",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newCreateLookupTestEnv(t, "0")
+			env.tmc.setTable("sourceks", lookupTestSourceTable(tt.sourceFieldType, lookupTestSourceSchema))
+			require.NoError(t, env.ts.SaveVSchema(ctx, "targetks", tt.targetVSchema))
+
+			_, _, got, err := env.ws.prepareCreateLookup(ctx, "sourceks", lookupUniqueSpecs("targetks."+tt.targetTable, true), false)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.want, got)
+		})
+	}
+}
+
+func TestPrepareCreateLookupSameKeyspace(t *testing.T) {
+	env := newCreateLookupTestEnv(t, "0")
+
+	// The lookup table is added to the source vschema, which is also the
+	// target one, along with the vindex.
+	specs := lookupUniqueSpecs("sourceks.lkp", true)
+	specs.Vindexes["v"].Params["to"] = "col2"
+	ms, sourceVSchema, targetVSchema, err := env.ws.prepareCreateLookup(context.Background(), "sourceks", specs, false)
+	require.NoError(t, err)
+	want := lookupTestSourceVSchema(&vschemapb.Vindex{
+		Type: "lookup_unique",
+		Params: map[string]string{
+			"table":      "sourceks.lkp",
+			"from":       "c1",
+			"to":         "col2",
+			"write_only": "true",
+		},
+		Owner: "t1",
+	})
+	want.Tables["lkp"] = &vschemapb.Table{
+		ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "c1", Name: "hash"}},
+	}
+	utils.MustMatch(t, want, sourceVSchema)
+	assert.Same(t, sourceVSchema, targetVSchema)
+	assert.Equal(t, "sourceks", ms.TargetKeyspace)
+	assert.Equal(t, "select col2 as c1, col2 as col2 from t1 group by c1, col2", ms.TableSettings[0].SourceExpression)
+}
+
+func TestPrepareCreateLookupStopAfterCopy(t *testing.T) {
+	tests := []struct {
+		name                       string
+		owner                      bool
+		continueAfterCopyWithOwner bool
+		want                       bool
+	}{
+		{
+			name:  "owned",
+			owner: true,
+			want:  true,
+		},
+		{
+			name:                       "owned, continue after copy",
+			owner:                      true,
+			continueAfterCopyWithOwner: true,
+			want:                       false,
+		},
+		{
+			name:  "not owned",
+			owner: false,
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newCreateLookupTestEnv(t, "0")
+			ms, _, _, err := env.ws.prepareCreateLookup(context.Background(), "sourceks", lookupUniqueSpecs("targetks.lkp", tt.owner), tt.continueAfterCopyWithOwner)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ms.StopAfterCopy)
+		})
+	}
+}
+
+func TestPrepareCreateLookupFailures(t *testing.T) {
+	unique := func() map[string]*vschemapb.Vindex {
+		return lookupUniqueSpecs("targetks.t", false).Vindexes
+	}
+
+	tests := []struct {
+		name    string
+		specs   *vschemapb.Keyspace
+		wantErr string
+	}{
+		{
+			name: "dup vindex",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"v1": {Type: "hash"},
+					"v2": {Type: "hash"},
+				},
+			},
+			wantErr: "only one vindex must be specified in the specs",
+		},
+		{
+			name: "not a lookup",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"v": {Type: "hash"},
+				},
+			},
+			wantErr: "vindex hash is not a lookup type",
+		},
+		{
+			name: "unqualified table",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"v": {
+						Type:   "lookup",
+						Params: map[string]string{"table": "t"},
+					},
+				},
+			},
+			wantErr: "vindex table name must be in the form <keyspace>.<table>",
+		},
+		{
+			name: "unique lookup should have only one from column",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"v": {
+						Type: "lookup_unique",
+						Params: map[string]string{
+							"table": "targetks.t",
+							"from":  "c1,c2",
+							"to":    "c3",
+						},
+					},
+				},
+			},
+			wantErr: "unique vindex 'from' should have only one column",
+		},
+		{
+			name: "non-unique lookup should have more than one column",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"v": {
+						Type: "lookup",
+						Params: map[string]string{
+							"table": "targetks.t",
+							"from":  "c1",
+							"to":    "c2",
+						},
+					},
+				},
+			},
+			wantErr: "non-unique vindex 'from' should have more than one column",
+		},
+		{
+			name: "vindex not found",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"v": {
+						Type: "lookup_noexist",
+						Params: map[string]string{
+							"table": "targetks.t",
+							"from":  "c1,c2",
+							"to":    "c2",
+						},
+					},
+				},
+			},
+			wantErr: `vindexType "lookup_noexist" not found`,
+		},
+		{
+			name: "only one table",
+			specs: &vschemapb.Keyspace{
+				Vindexes: unique(),
+			},
+			wantErr: "exactly one table must be specified in the specs",
+		},
+		{
+			name: "only one colvindex",
+			specs: &vschemapb.Keyspace{
+				Vindexes: unique(),
+				Tables: map[string]*vschemapb.Table{
+					"t1": {},
+				},
+			},
+			wantErr: "exactly one ColumnVindex must be specified for the table",
+		},
+		{
+			name: "vindex name must match",
+			specs: &vschemapb.Keyspace{
+				Vindexes: unique(),
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "other"}},
+					},
+				},
+			},
+			wantErr: "ColumnVindex name must match vindex name: other vs v",
+		},
+		{
+			name: "owner must match",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"v": {
+						Type: "lookup_unique",
+						Params: map[string]string{
+							"table": "targetks.t",
+							"from":  "c1",
+							"to":    "c2",
+						},
+						Owner: "otherTable",
+					},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v"}},
+					},
+				},
+			},
+			wantErr: "vindex owner must match table name: otherTable vs t1",
+		},
+		{
+			name: "no column",
+			specs: &vschemapb.Keyspace{
+				Vindexes: unique(),
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v"}},
+					},
+				},
+			},
+			wantErr: "at least one column must be specified in ColumnVindexes",
+		},
+		{
+			name: "columnvindex length mismatch",
+			specs: &vschemapb.Keyspace{
+				Vindexes: unique(),
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v", Columns: []string{"col1", "col2"}}},
+					},
+				},
+			},
+			wantErr: "length of table columns differes from length of vindex columns",
+		},
+		{
+			name: "vindex mismatches with what's in vschema",
+			specs: &vschemapb.Keyspace{
+				Vindexes: map[string]*vschemapb.Vindex{
+					"other": {
+						Type: "lookup_unique",
+						Params: map[string]string{
+							"table": "targetks.t",
+							"from":  "c1",
+							"to":    "c2",
+						},
+						Owner: "t1",
+					},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "other", Column: "col"}},
+					},
+				},
+			},
+			wantErr: "a conflicting vindex named other already exists in the source vschema",
+		},
+		{
+			name: "source table not in vschema",
+			specs: &vschemapb.Keyspace{
+				Vindexes: unique(),
+				Tables: map[string]*vschemapb.Table{
+					"other": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v", Column: "col"}},
+					},
+				},
+			},
+			wantErr: "source table other not found in vschema",
+		},
+		{
+			name: "colvindex already exists in vschema",
+			specs: &vschemapb.Keyspace{
+				Vindexes: unique(),
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v", Column: "c1"}},
+					},
+				},
+			},
+			wantErr: "ColumnVindex for table t1 already exists: c1",
+		},
+	}
+
+	env := newCreateLookupTestEnv(t, "0")
+	require.NoError(t, env.ts.SaveVSchema(context.Background(), "sourceks", &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"other": {Type: "hash"},
+			"v": {
+				Type: "lookup_unique",
+				Params: map[string]string{
+					"table":      "targetks.t",
+					"from":       "c1",
+					"to":         "c2",
+					"write_only": "true",
+				},
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Name: "v", Column: "c1"}},
+			},
+		},
+	}))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := env.ws.prepareCreateLookup(context.Background(), "sourceks", tt.specs, false)
+			require.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestMaterializeCreate(t *testing.T) {
+	ctx := context.Background()
+	env := newCreateLookupTestEnv(t, "-80", "80-")
+	require.NoError(t, env.ts.SaveVSchema(ctx, "targetks", &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "col2", Name: "hash"}},
+			},
+		},
+	}))
+	for _, tablet := range env.targetTablets() {
+		env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_targetks' and workflow='wf1'", nil)
+		env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_targetks' and message='FROZEN' and workflow_sub_type != 1", nil)
+		env.tmc.addQueryRE(tablet, `^insert into _vt\.vreplication`, nil)
+		env.tmc.addQuery(tablet, "update _vt.vreplication set state='Running' where db_name='vt_targetks' and workflow='wf1'", nil)
+	}
+
+	_, err := env.ws.MaterializeCreate(ctx, &vtctldatapb.MaterializeCreateRequest{})
+	require.ErrorContains(t, err, "materialize settings must be provided")
+
+	_, err = env.ws.MaterializeCreate(ctx, &vtctldatapb.MaterializeCreateRequest{
+		Settings: &vtctldatapb.MaterializeSettings{
+			Workflow:       testWorkflow,
+			SourceKeyspace: "sourceks",
+			TargetKeyspace: "targetks",
+			TableSettings: []*vtctldatapb.TableMaterializeSettings{{
+				TargetTable:      "t1",
+				SourceExpression: "select * from t1",
+				CreateDdl:        createDDLAsCopy,
+			}},
+		},
+	})
+	require.NoError(t, err)
+
+	for _, tablet := range env.targetTablets() {
+		// The target table is created as a copy of the source one, and the
+		// stream of each target shard only copies the rows it owns.
+		assert.Equal(t, []string{lookupTestSourceSchema}, env.tmc.executed(tablet, "^CREATE TABLE"), "tablet %d", tablet.Alias.Uid)
+		inserts := env.tmc.executed(tablet, `^insert into _vt\.vreplication`)
+		require.Len(t, inserts, 1, "tablet %d", tablet.Alias.Uid)
+		assert.Contains(t, inserts[0], fmt.Sprintf(`filter:\"select * from t1 where in_keyrange(col2, \'targetks.hash\', \'%s\')\"`, tablet.Shard), "tablet %d", tablet.Alias.Uid)
+		assert.Contains(t, inserts[0], fmt.Sprintf(", 'vt_targetks', %d, ", binlogdatapb.VReplicationWorkflowType_Materialize), "tablet %d", tablet.Alias.Uid)
+		assert.Len(t, env.tmc.executed(tablet, `^update _vt\.vreplication set state='Running'`), 1, "tablet %d", tablet.Alias.Uid)
+	}
+}
+
+func TestLookupVindexExternalize(t *testing.T) {
+	sourceVSchema := &vschemapb.Keyspace{
+		Sharded: true,
+		Vindexes: map[string]*vschemapb.Vindex{
+			"hash": {Type: "hash"},
+			"owned": {
+				Type: "lookup_unique",
+				Params: map[string]string{
+					"table":      "targetks.lkp",
+					"from":       "c1",
+					"to":         "c2",
+					"write_only": "true",
+				},
+				Owner: "t1",
+			},
+			"unowned": {
+				Type: "lookup_unique",
+				Params: map[string]string{
+					"table":      "targetks.lkp",
+					"from":       "c1",
+					"to":         "c2",
+					"write_only": "true",
+				},
+			},
+			"bad": {
+				Type: "lookup_unique",
+				Params: map[string]string{
+					"table": "unqualified",
+					"from":  "c1",
+					"to":    "c2",
+				},
+			},
+		},
+		Tables: map[string]*vschemapb.Table{
+			"t1": {
+				ColumnVindexes: []*vschemapb.ColumnVindex{
+					{Name: "hash", Column: "col1"},
+					{Name: "owned", Column: "col2"},
+				},
+			},
+		},
+	}
+	stopAfterCopySource := `keyspace:"sourceks" shard:"0" stop_after_copy:true`
+	keepRunningSource := `keyspace:"sourceks" shard:"0"`
+	stopped := lookupVindexStreamsResult("1|" + stopAfterCopySource + "|Stopped|Stopped after copy|10|0")
+	running := lookupVindexStreamsResult("1|" + keepRunningSource + "|Running||10|0")
+	copying := lookupVindexStreamsResult("1|" + keepRunningSource + "|Running||5|1")
+
+	tests := []struct {
+		name        string
+		vindex      string
+		streams     *querypb.QueryResult
+		wantDeleted bool
+		wantErr     string
+	}{
+		{
+			name:        "owned, stopped after copy",
+			vindex:      "owned",
+			streams:     stopped,
+			wantDeleted: true,
+		},
+		{
+			name:        "owned, running after copy",
+			vindex:      "owned",
+			streams:     running,
+			wantDeleted: true,
+		},
+		{
+			name:    "owned, copying",
+			vindex:  "owned",
+			streams: copying,
+			wantErr: "is not in Running state",
+		},
+		{
+			name:    "unowned, running",
+			vindex:  "unowned",
+			streams: running,
+		},
+		{
+			name:    "unowned, stopped",
+			vindex:  "unowned",
+			streams: stopped,
+			wantErr: "is not in Running state",
+		},
+		{
+			name:    "absent",
+			vindex:  "absent",
+			wantErr: "vindex sourceks.absent not found in vschema",
+		},
+		{
+			name:    "bad table",
+			vindex:  "bad",
+			wantErr: "vindex table name must be in the form <keyspace>.<table>. Got: unqualified",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newCreateLookupTestEnv(t, "-80", "80-")
+			require.NoError(t, env.ts.SaveVSchema(ctx, "sourceks", sourceVSchema))
+			deleteQuery := "delete from _vt.vreplication where db_name='vt_targetks' and workflow='lkp_vdx'"
+			for _, tablet := range env.targetTablets() {
+				env.tmc.addQuery(tablet, fmt.Sprintf(sqlSelectLookupVindexStreams, "'lkp_vdx'", "'vt_targetks'"), tt.streams)
+				env.tmc.addQuery(tablet, deleteQuery, nil)
+			}
+
+			resp, err := env.ws.LookupVindexExternalize(ctx, &vtctldatapb.LookupVindexExternalizeRequest{
+				Keyspace: "sourceks",
+				Name:     tt.vindex,
+			})
+			vs, verr := env.ts.GetVSchema(ctx, "sourceks")
+			require.NoError(t, verr)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				utils.MustMatch(t, sourceVSchema, vs)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDeleted, resp.WorkflowDeleted)
+			assert.NotContains(t, vs.Vindexes[tt.vindex].Params, "write_only")
+			for _, tablet := range env.targetTablets() {
+				deletes := env.tmc.executed(tablet, "^"+regexp.QuoteMeta(deleteQuery)+"$")
+				if tt.wantDeleted {
+					assert.Len(t, deletes, 1, "tablet %d", tablet.Alias.Uid)
+				} else {
+					assert.Empty(t, deletes, "tablet %d", tablet.Alias.Uid)
+				}
+			}
+
+			// The serving vschema now uses the vindex to route queries.
+			srvVSchema, err := env.ts.GetSrvVSchema(ctx, testCell)
+			require.NoError(t, err)
+			assert.NotContains(t, srvVSchema.Keyspaces["sourceks"].Vindexes[tt.vindex].Params, "write_only")
+		})
+	}
+}
@@ -0,0 +1,1004 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/binlog/binlogplayer"
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/key"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/mysqlctl/tmutils"
+	"vitess.io/vitess/go/vt/schema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtctl/schematools"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vreplication"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+type materializer struct {
+	ts            *topo.Server
+	sourceTs      *topo.Server
+	tmc           tmclient.TabletManagerClient
+	ms            *vtctldatapb.MaterializeSettings
+	targetVSchema *vindexes.KeyspaceSchema
+	sourceShards  []*topo.ShardInfo
+	targetShards  []*topo.ShardInfo
+	isPartial     bool
+	workflowType  binlogdatapb.VReplicationWorkflowType
+}
+
+const (
+	createDDLAsCopy                = "copy"
+	createDDLAsCopyDropConstraint  = "copy:drop_constraint"
+	createDDLAsCopyDropForeignKeys = "copy:drop_foreign_keys"
+)
+
+// addTablesToVSchema adds tables to an (unsharded) vschema if they are not already defined.
+// If copyVSchema is true then we copy over the vschema table definitions from the source,
+// otherwise we create empty ones.
+// For a migrate workflow we do not copy the vschema since the source keyspace is just a
+// proxy to import data into Vitess.
+func (s *Server) addTablesToVSchema(ctx context.Context, sourceKeyspace string, targetVSchema *vschemapb.Keyspace, tables []string, copyVSchema bool) error {
+	if targetVSchema.Tables == nil {
+		targetVSchema.Tables = make(map[string]*vschemapb.Table)
+	}
+	if copyVSchema {
+		srcVSchema, err := s.ts.GetVSchema(ctx, sourceKeyspace)
+		if err != nil {
+			return vterrors.Wrapf(err, "failed to get vschema for source keyspace %s", sourceKeyspace)
+		}
+		for _, table := range tables {
+			srcTable, sok := srcVSchema.Tables[table]
+			if _, tok := targetVSchema.Tables[table]; sok && !tok {
+				targetVSchema.Tables[table] = srcTable
+				// If going from sharded to unsharded, then we need to remove the
+				// column vindexes as they are not valid for unsharded tables.
+				if srcVSchema.Sharded {
+					targetVSchema.Tables[table].ColumnVindexes = nil
+				}
+			}
+		}
+	}
+	// Ensure that each table at least has an empty definition on the target.
+	for _, table := range tables {
+		if _, tok := targetVSchema.Tables[table]; !tok {
+			targetVSchema.Tables[table] = &vschemapb.Table{}
+		}
+	}
+	return nil
+}
+
+func shouldInclude(table string, excludes []string) bool {
+	// We filter out internal tables elsewhere when processing SchemaDefinition
+	// structures built from the GetSchema database related API calls. In this
+	// case, however, the table list comes from the user via the -tables flag
+	// so we need to filter out internal table names here in case a user has
+	// explicitly specified some.
+	// This could happen if there's some automated tooling that creates the list of
+	// tables to explicitly specify.
+	// But given that this should never be done in practice, we ignore the request.
+	if schema.IsInternalOperationTableName(table) {
+		return false
+	}
+	for _, t := range excludes {
+		if t == table {
+			return false
+		}
+	}
+	return true
+}
+
+// moveTablesCreate sets up the streams, routing rules and vschema needed to
+// move the tables in the request from the source keyspace to the target
+// keyspace, and returns the materializer used to create the streams.
+func (s *Server) moveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTablesCreateRequest, workflowType binlogdatapb.VReplicationWorkflowType) (*materializer, error) {
+	var (
+		tables       = req.IncludeTables
+		externalTopo *topo.Server
+		sourceTopo   = s.ts
+		err          error
+	)
+
+	// When the source is an external cluster mounted using the Mount command.
+	if req.ExternalClusterName != "" {
+		externalTopo, err = s.ts.OpenExternalVitessClusterServer(ctx, req.ExternalClusterName)
+		if err != nil {
+			return nil, err
+		}
+		sourceTopo = externalTopo
+		log.Infof("Successfully opened external topo: %+v", externalTopo)
+	}
+
+	vschema, err := s.ts.GetVSchema(ctx, req.TargetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	if vschema == nil {
+		return nil, fmt.Errorf("no vschema found for target keyspace %s", req.TargetKeyspace)
+	}
+
+	ksTables, err := s.getKeyspaceTables(ctx, req.SourceKeyspace, sourceTopo)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) > 0 {
+		if err := s.validateSourceTablesExist(ctx, req.SourceKeyspace, ksTables, tables); err != nil {
+			return nil, err
+		}
+	} else {
+		if req.AllTables {
+			tables = ksTables
+		} else {
+			return nil, fmt.Errorf("no tables to move")
+		}
+	}
+	if len(req.ExcludeTables) > 0 {
+		if err := s.validateSourceTablesExist(ctx, req.SourceKeyspace, ksTables, req.ExcludeTables); err != nil {
+			return nil, err
+		}
+	}
+	var tables2 []string
+	for _, t := range tables {
+		if shouldInclude(t, req.ExcludeTables) {
+			tables2 = append(tables2, t)
+		}
+	}
+	tables = tables2
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("no tables to move")
+	}
+	log.Infof("Found tables to move: %s", strings.Join(tables, ","))
+
+	if !vschema.Sharded {
+		if err := s.addTablesToVSchema(ctx, req.SourceKeyspace, vschema, tables, externalTopo == nil); err != nil {
+			return nil, err
+		}
+	}
+	if externalTopo == nil {
+		// Save routing rules before vschema. If we save vschema first, and routing rules
+		// fails to save, we may generate duplicate table errors.
+		rules, err := topotools.GetRoutingRules(ctx, s.ts)
+		if err != nil {
+			return nil, err
+		}
+		for _, table := range tables {
+			toSource := []string{req.SourceKeyspace + "." + table}
+			rules[table] = toSource
+			rules[table+"@replica"] = toSource
+			rules[table+"@rdonly"] = toSource
+			rules[req.TargetKeyspace+"."+table] = toSource
+			rules[req.TargetKeyspace+"."+table+"@replica"] = toSource
+			rules[req.TargetKeyspace+"."+table+"@rdonly"] = toSource
+			rules[req.SourceKeyspace+"."+table+"@replica"] = toSource
+			rules[req.SourceKeyspace+"."+table+"@rdonly"] = toSource
+		}
+		if err := topotools.SaveRoutingRules(ctx, s.ts, rules); err != nil {
+			return nil, err
+		}
+
+		// We added to the vschema.
+		if err := s.ts.SaveVSchema(ctx, req.TargetKeyspace, vschema); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.ts.RebuildSrvVSchema(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	ms := &vtctldatapb.MaterializeSettings{
+		Workflow:              req.Workflow,
+		MaterializationIntent: vtctldatapb.MaterializationIntent_MOVETABLES,
+		SourceKeyspace:        req.SourceKeyspace,
+		TargetKeyspace:        req.TargetKeyspace,
+		Cell:                  strings.Join(req.Cells, ","),
+		TabletTypes:           strings.Join(topoproto.MakeStringTypeList(req.TabletTypes), ","),
+		StopAfterCopy:         req.StopAfterCopy,
+		ExternalCluster:       req.ExternalClusterName,
+		SourceShards:          req.SourceShards,
+		OnDdl:                 req.OnDdl,
+		DeferSecondaryKeys:    req.DeferSecondaryKeys,
+	}
+	if req.SourceTimeZone != "" {
+		ms.SourceTimeZone = req.SourceTimeZone
+		ms.TargetTimeZone = "UTC"
+	}
+	createDDLMode := createDDLAsCopy
+	if req.DropForeignKeys {
+		createDDLMode = createDDLAsCopyDropForeignKeys
+	}
+
+	for _, table := range tables {
+		buf := sqlparser.NewTrackedBuffer(nil)
+		buf.Myprintf("select * from %v", sqlparser.NewIdentifierCS(table))
+		ms.TableSettings = append(ms.TableSettings, &vtctldatapb.TableMaterializeSettings{
+			TargetTable:      table,
+			SourceExpression: buf.String(),
+			CreateDdl:        createDDLMode,
+		})
+	}
+	mz, err := s.prepareMaterializerStreams(ctx, ms, workflowType)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.SourceTimeZone != "" {
+		if err := mz.checkTZConversion(ctx, req.SourceTimeZone); err != nil {
+			return nil, err
+		}
+	}
+
+	tabletShards, err := s.collectTargetStreams(ctx, mz)
+	if err != nil {
+		return nil, err
+	}
+
+	migrationID, err := getMigrationID(req.TargetKeyspace, tabletShards)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ExternalClusterName == "" {
+		exists, tablets, err := s.checkIfPreviousJournalExists(ctx, mz, migrationID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			s.Logger().Errorf("Found a previous journal entry for %d", migrationID)
+			msg := fmt.Sprintf("found an entry from a previous run for migration id %d in _vt.resharding_journal of tablets %s,",
+				migrationID, strings.Join(tablets, ","))
+			msg += fmt.Sprintf("please review and delete it before proceeding and restart the workflow using the Workflow %s.%s start",
+				req.Workflow, req.TargetKeyspace)
+			return nil, fmt.Errorf(msg)
+		}
+	}
+	if req.AutoStart {
+		if err := mz.startStreams(ctx); err != nil {
+			return nil, err
+		}
+	} else {
+		s.Logger().Infof("Streams will not be started since --auto-start is set to false")
+	}
+
+	return mz, nil
+}
+
+func (s *Server) validateSourceTablesExist(ctx context.Context, sourceKeyspace string, ksTables, tables []string) error {
+	// validate that tables provided are present in the source keyspace
+	var missingTables []string
+	for _, table := range tables {
+		if schema.IsInternalOperationTableName(table) {
+			continue
+		}
+		found := false
+
+		for _, ksTable := range ksTables {
+			if table == ksTable {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missingTables = append(missingTables, table)
+		}
+	}
+	if len(missingTables) > 0 {
+		return fmt.Errorf("table(s) not found in source keyspace %s: %s", sourceKeyspace, strings.Join(missingTables, ","))
+	}
+	return nil
+}
+
+func (s *Server) getKeyspaceTables(ctx context.Context, ks string, ts *topo.Server) ([]string, error) {
+	shards, err := ts.GetServingShards(ctx, ks)
+	if err != nil {
+		return nil, err
+	}
+	if len(shards) == 0 {
+		return nil, fmt.Errorf("keyspace %s has no shards", ks)
+	}
+	primary := shards[0].PrimaryAlias
+	if primary == nil {
+		return nil, fmt.Errorf("shard does not have a primary: %v", shards[0].ShardName())
+	}
+	allTables := []string{"/.*/"}
+
+	ti, err := ts.GetTablet(ctx, primary)
+	if err != nil {
+		return nil, err
+	}
+	req := &tabletmanagerdatapb.GetSchemaRequest{Tables: allTables}
+	schema, err := s.tmc.GetSchema(ctx, ti.Tablet, req)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("got table schemas from source primary %v.", primary)
+
+	var sourceTables []string
+	for _, td := range schema.TableDefinitions {
+		sourceTables = append(sourceTables, td.Name)
+	}
+	return sourceTables, nil
+}
+
+func (s *Server) checkIfPreviousJournalExists(ctx context.Context, mz *materializer, migrationID int64) (bool, []string, error) {
+	forAllSources := func(f func(*topo.ShardInfo) error) error {
+		var wg sync.WaitGroup
+		allErrors := &concurrency.AllErrorRecorder{}
+		for _, sourceShard := range mz.sourceShards {
+			wg.Add(1)
+			go func(sourceShard *topo.ShardInfo) {
+				defer wg.Done()
+
+				if err := f(sourceShard); err != nil {
+					allErrors.RecordError(err)
+				}
+			}(sourceShard)
+		}
+		wg.Wait()
+		return allErrors.AggrError(vterrors.Aggregate)
+	}
+
+	var (
+		mu      sync.Mutex
+		exists  bool
+		tablets []string
+	)
+
+	err := forAllSources(func(si *topo.ShardInfo) error {
+		tablet, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+		if err != nil {
+			return err
+		}
+		if tablet == nil {
+			return nil
+		}
+		_, exists, err = s.CheckReshardingJournalExistsOnTablet(ctx, tablet.Tablet, migrationID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			mu.Lock()
+			defer mu.Unlock()
+			tablets = append(tablets, tablet.AliasString())
+		}
+		return nil
+	})
+	return exists, tablets, err
+}
+
+// CreateLookupVindex creates a lookup vindex and sets up the backfill.
+
+func (s *Server) collectTargetStreams(ctx context.Context, mz *materializer) ([]string, error) {
+	var shardTablets []string
+	var mu sync.Mutex
+	err := mz.forAllTargets(func(target *topo.ShardInfo) error {
+		var qrproto *querypb.QueryResult
+		var id int64
+		var err error
+		targetPrimary, err := mz.ts.GetTablet(ctx, target.PrimaryAlias)
+		if err != nil {
+			return vterrors.Wrapf(err, "GetTablet(%v) failed", target.PrimaryAlias)
+		}
+		query := fmt.Sprintf("select id from _vt.vreplication where db_name=%s and workflow=%s", encodeString(targetPrimary.DbName()), encodeString(mz.ms.Workflow))
+		if qrproto, err = mz.tmc.VReplicationExec(ctx, targetPrimary.Tablet, query); err != nil {
+			return vterrors.Wrapf(err, "VReplicationExec(%v, %s)", targetPrimary.Tablet, query)
+		}
+		qr := sqltypes.Proto3ToResult(qrproto)
+		for i := 0; i < len(qr.Rows); i++ {
+			id, err = evalengine.ToInt64(qr.Rows[i][0])
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			shardTablets = append(shardTablets, fmt.Sprintf("%s:%d", target.ShardName(), id))
+			mu.Unlock()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return shardTablets, nil
+}
+
+// getMigrationID produces a reproducible hash based on the input parameters.
+func getMigrationID(targetKeyspace string, shardTablets []string) (int64, error) {
+	sort.Strings(shardTablets)
+	hasher := fnv.New64()
+	hasher.Write([]byte(targetKeyspace))
+	for _, str := range shardTablets {
+		hasher.Write([]byte(str))
+	}
+	// Convert to int64 after dropping the highest bit.
+	return int64(hasher.Sum64() & math.MaxInt64), nil
+}
+
+// createDefaultShardRoutingRules creates a reverse routing rule for
+// each shard in a new partial keyspace migration workflow that does
+// not already have an existing routing rule in place.
+func (s *Server) createDefaultShardRoutingRules(ctx context.Context, mz *materializer) error {
+	ms := mz.ms
+	srr, err := topotools.GetShardRoutingRules(ctx, s.ts)
+	if err != nil {
+		return err
+	}
+	allShards, err := mz.sourceTs.GetServingShards(ctx, ms.SourceKeyspace)
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, si := range allShards {
+		fromSource := fmt.Sprintf("%s.%s", ms.SourceKeyspace, si.ShardName())
+		fromTarget := fmt.Sprintf("%s.%s", ms.TargetKeyspace, si.ShardName())
+		if srr[fromSource] == "" && srr[fromTarget] == "" {
+			srr[fromTarget] = ms.SourceKeyspace
+			changed = true
+			s.Logger().Infof("Added default shard routing rule from %q to %q", fromTarget, fromSource)
+		}
+	}
+	if changed {
+		if err := topotools.SaveShardRoutingRules(ctx, s.ts, srr); err != nil {
+			return err
+		}
+		if err := s.ts.RebuildSrvVSchema(ctx, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// workflowTypeForIntent returns the type of the workflows materializing with
+// the given intent.
+func workflowTypeForIntent(intent vtctldatapb.MaterializationIntent) binlogdatapb.VReplicationWorkflowType {
+	switch intent {
+	case vtctldatapb.MaterializationIntent_MOVETABLES:
+		return binlogdatapb.VReplicationWorkflowType_MoveTables
+	case vtctldatapb.MaterializationIntent_CREATELOOKUPINDEX:
+		return binlogdatapb.VReplicationWorkflowType_CreateLookupIndex
+	default:
+		return binlogdatapb.VReplicationWorkflowType_Materialize
+	}
+}
+
+// prepareMaterializerStreams creates the streams of a workflow of the given
+// type, which materializes according to the given settings.
+func (s *Server) prepareMaterializerStreams(ctx context.Context, ms *vtctldatapb.MaterializeSettings, workflowType binlogdatapb.VReplicationWorkflowType) (*materializer, error) {
+	if err := s.validateNewWorkflow(ctx, ms.TargetKeyspace, ms.Workflow); err != nil {
+		return nil, err
+	}
+	mz, err := s.buildMaterializer(ctx, ms)
+	if err != nil {
+		return nil, err
+	}
+	mz.workflowType = workflowType
+	if mz.isPartial {
+		if err := s.createDefaultShardRoutingRules(ctx, mz); err != nil {
+			return nil, err
+		}
+	}
+	if err := mz.deploySchema(ctx); err != nil {
+		return nil, err
+	}
+	insertMap := make(map[string]string, len(mz.targetShards))
+	for _, targetShard := range mz.targetShards {
+		inserts, err := mz.generateInserts(ctx, targetShard)
+		if err != nil {
+			return nil, err
+		}
+		insertMap[targetShard.ShardName()] = inserts
+	}
+	if err := mz.createStreams(ctx, insertMap); err != nil {
+		return nil, err
+	}
+	return mz, nil
+}
+
+func (s *Server) buildMaterializer(ctx context.Context, ms *vtctldatapb.MaterializeSettings) (*materializer, error) {
+	vschema, err := s.ts.GetVSchema(ctx, ms.TargetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	targetVSchema, err := vindexes.BuildKeyspaceSchema(vschema, ms.TargetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	if targetVSchema.Keyspace.Sharded {
+		for _, ts := range ms.TableSettings {
+			if targetVSchema.Tables[ts.TargetTable] == nil {
+				return nil, fmt.Errorf("table %s not found in vschema for keyspace %s", ts.TargetTable, ms.TargetKeyspace)
+			}
+		}
+	}
+	sourceTs := s.ts
+	if ms.ExternalCluster != "" { // when the source is an external mysql cluster mounted using the Mount command
+		externalTopo, err := s.ts.OpenExternalVitessClusterServer(ctx, ms.ExternalCluster)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open external topo: %v", err)
+		}
+		sourceTs = externalTopo
+	}
+	isPartial := false
+	sourceShards, err := sourceTs.GetServingShards(ctx, ms.SourceKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(ms.SourceShards) > 0 {
+		isPartial = true
+		var sourceShards2 []*topo.ShardInfo
+		for _, shard := range sourceShards {
+			for _, shard2 := range ms.SourceShards {
+				if shard.ShardName() == shard2 {
+					sourceShards2 = append(sourceShards2, shard)
+					break
+				}
+			}
+		}
+		sourceShards = sourceShards2
+	}
+	if len(sourceShards) == 0 {
+		return nil, fmt.Errorf("no source shards specified for workflow %s ", ms.Workflow)
+	}
+
+	targetShards, err := s.ts.GetServingShards(ctx, ms.TargetKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	if len(ms.SourceShards) > 0 {
+		var targetShards2 []*topo.ShardInfo
+		for _, shard := range targetShards {
+			for _, shard2 := range ms.SourceShards {
+				if shard.ShardName() == shard2 {
+					targetShards2 = append(targetShards2, shard)
+					break
+				}
+			}
+		}
+		targetShards = targetShards2
+	}
+	if len(targetShards) == 0 {
+		return nil, fmt.Errorf("no target shards specified for workflow %s ", ms.Workflow)
+	}
+
+	return &materializer{
+		ts:            s.ts,
+		sourceTs:      sourceTs,
+		tmc:           s.tmc,
+		ms:            ms,
+		targetVSchema: targetVSchema,
+		sourceShards:  sourceShards,
+		targetShards:  targetShards,
+		isPartial:     isPartial,
+	}, nil
+}
+
+func (mz *materializer) getSourceTableDDLs(ctx context.Context) (map[string]string, error) {
+	sourceDDLs := make(map[string]string)
+	allTables := []string{"/.*/"}
+
+	sourcePrimary := mz.sourceShards[0].PrimaryAlias
+	if sourcePrimary == nil {
+		return nil, fmt.Errorf("source shard must have a primary for copying schema: %v", mz.sourceShards[0].ShardName())
+	}
+
+	ti, err := mz.sourceTs.GetTablet(ctx, sourcePrimary)
+	if err != nil {
+		return nil, err
+	}
+	req := &tabletmanagerdatapb.GetSchemaRequest{Tables: allTables}
+	sourceSchema, err := mz.tmc.GetSchema(ctx, ti.Tablet, req)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, td := range sourceSchema.TableDefinitions {
+		sourceDDLs[td.Name] = td.Schema
+	}
+	return sourceDDLs, nil
+}
+
+func (mz *materializer) deploySchema(ctx context.Context) error {
+	var sourceDDLs map[string]string
+	var mu sync.Mutex
+
+	return mz.forAllTargets(func(target *topo.ShardInfo) error {
+		allTables := []string{"/.*/"}
+
+		hasTargetTable := map[string]bool{}
+		req := &tabletmanagerdatapb.GetSchemaRequest{Tables: allTables}
+		targetSchema, err := schematools.GetSchema(ctx, mz.ts, mz.tmc, target.PrimaryAlias, req)
+		if err != nil {
+			return err
+		}
+
+		for _, td := range targetSchema.TableDefinitions {
+			hasTargetTable[td.Name] = true
+		}
+
+		targetTablet, err := mz.ts.GetTablet(ctx, target.PrimaryAlias)
+		if err != nil {
+			return err
+		}
+
+		var applyDDLs []string
+		for _, ts := range mz.ms.TableSettings {
+			if hasTargetTable[ts.TargetTable] {
+				// Table already exists.
+				continue
+			}
+			if ts.CreateDdl == "" {
+				return fmt.Errorf("target table %v does not exist and there is no create ddl defined", ts.TargetTable)
+			}
+
+			var err error
+			mu.Lock()
+			if len(sourceDDLs) == 0 {
+				//only get ddls for tables, once and lazily: if we need to copy the schema from source to target
+				//we copy schemas from primaries on the source keyspace
+				//and we have found use cases where user just has a replica (no primary) in the source keyspace
+				sourceDDLs, err = mz.getSourceTableDDLs(ctx)
+			}
+			mu.Unlock()
+			if err != nil {
+				log.Errorf("Error getting DDLs of source tables: %s", err.Error())
+				return err
+			}
+
+			createDDL := ts.CreateDdl
+			if createDDL == createDDLAsCopy || createDDL == createDDLAsCopyDropConstraint || createDDL == createDDLAsCopyDropForeignKeys {
+				if ts.SourceExpression != "" {
+					// Check for table if non-empty SourceExpression.
+					sourceTableName, err := sqlparser.TableFromStatement(ts.SourceExpression)
+					if err != nil {
+						return err
+					}
+					if sourceTableName.Name.String() != ts.TargetTable {
+						return fmt.Errorf("source and target table names must match for copying schema: %v vs %v", sqlparser.String(sourceTableName), ts.TargetTable)
+
+					}
+				}
+
+				ddl, ok := sourceDDLs[ts.TargetTable]
+				if !ok {
+					return fmt.Errorf("source table %v does not exist", ts.TargetTable)
+				}
+
+				if createDDL == createDDLAsCopyDropConstraint {
+					strippedDDL, err := stripTableConstraints(ddl)
+					if err != nil {
+						return err
+					}
+
+					ddl = strippedDDL
+				}
+
+				if createDDL == createDDLAsCopyDropForeignKeys {
+					strippedDDL, err := stripTableForeignKeys(ddl)
+					if err != nil {
+						return err
+					}
+
+					ddl = strippedDDL
+				}
+				createDDL = ddl
+			}
+
+			applyDDLs = append(applyDDLs, createDDL)
+		}
+
+		if len(applyDDLs) > 0 {
+			sql := strings.Join(applyDDLs, ";\n")
+
+			_, err = mz.tmc.ApplySchema(ctx, targetTablet.Tablet, &tmutils.SchemaChange{
+				SQL:              sql,
+				Force:            false,
+				AllowReplication: true,
+				SQLMode:          vreplication.SQLMode,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func stripTableForeignKeys(ddl string) (string, error) {
+
+	ast, err := sqlparser.ParseStrictDDL(ddl)
+	if err != nil {
+		return "", err
+	}
+
+	stripFKConstraints := func(cursor *sqlparser.Cursor) bool {
+		switch node := cursor.Node().(type) {
+		case sqlparser.DDLStatement:
+			if node.GetTableSpec() != nil {
+				var noFKConstraints []*sqlparser.ConstraintDefinition
+				for _, constraint := range node.GetTableSpec().Constraints {
+					if constraint.Details != nil {
+						if _, ok := constraint.Details.(*sqlparser.ForeignKeyDefinition); !ok {
+							noFKConstraints = append(noFKConstraints, constraint)
+						}
+					}
+				}
+				node.GetTableSpec().Constraints = noFKConstraints
+			}
+		}
+		return true
+	}
+
+	noFKConstraintAST := sqlparser.Rewrite(ast, stripFKConstraints, nil)
+	newDDL := sqlparser.String(noFKConstraintAST)
+	return newDDL, nil
+}
+
+func stripTableConstraints(ddl string) (string, error) {
+	ast, err := sqlparser.ParseStrictDDL(ddl)
+	if err != nil {
+		return "", err
+	}
+
+	stripConstraints := func(cursor *sqlparser.Cursor) bool {
+		switch node := cursor.Node().(type) {
+		case sqlparser.DDLStatement:
+			if node.GetTableSpec() != nil {
+				node.GetTableSpec().Constraints = nil
+			}
+		}
+		return true
+	}
+
+	noConstraintAST := sqlparser.Rewrite(ast, stripConstraints, nil)
+	newDDL := sqlparser.String(noConstraintAST)
+
+	return newDDL, nil
+}
+
+func (mz *materializer) generateInserts(ctx context.Context, targetShard *topo.ShardInfo) (string, error) {
+	ig := vreplication.NewInsertGenerator(binlogplayer.BlpStopped, "{{.dbname}}")
+
+	for _, sourceShard := range mz.sourceShards {
+		// Don't create streams from sources which won't contain data for the target shard.
+		// We only do it for MoveTables for now since this doesn't hold for materialize flows
+		// where the target's sharding key might differ from that of the source
+		if mz.ms.MaterializationIntent == vtctldatapb.MaterializationIntent_MOVETABLES &&
+			!key.KeyRangeIntersect(sourceShard.KeyRange, targetShard.KeyRange) {
+			continue
+		}
+		bls := &binlogdatapb.BinlogSource{
+			Keyspace:        mz.ms.SourceKeyspace,
+			Shard:           sourceShard.ShardName(),
+			Filter:          &binlogdatapb.Filter{},
+			StopAfterCopy:   mz.ms.StopAfterCopy,
+			ExternalCluster: mz.ms.ExternalCluster,
+			SourceTimeZone:  mz.ms.SourceTimeZone,
+			TargetTimeZone:  mz.ms.TargetTimeZone,
+			OnDdl:           binlogdatapb.OnDDLAction(binlogdatapb.OnDDLAction_value[mz.ms.OnDdl]),
+		}
+		for _, ts := range mz.ms.TableSettings {
+			rule := &binlogdatapb.Rule{
+				Match: ts.TargetTable,
+			}
+
+			if ts.SourceExpression == "" {
+				bls.Filter.Rules = append(bls.Filter.Rules, rule)
+				continue
+			}
+
+			// Validate non-empty query.
+			stmt, err := sqlparser.Parse(ts.SourceExpression)
+			if err != nil {
+				return "", err
+			}
+			sel, ok := stmt.(*sqlparser.Select)
+			if !ok {
+				return "", fmt.Errorf("unrecognized statement: %s", ts.SourceExpression)
+			}
+			filter := ts.SourceExpression
+			if mz.targetVSchema.Keyspace.Sharded && mz.targetVSchema.Tables[ts.TargetTable].Type != vindexes.TypeReference {
+				cv, err := vindexes.FindBestColVindex(mz.targetVSchema.Tables[ts.TargetTable])
+				if err != nil {
+					return "", err
+				}
+				mappedCols := make([]*sqlparser.ColName, 0, len(cv.Columns))
+				for _, col := range cv.Columns {
+					colName, err := matchColInSelect(col, sel)
+					if err != nil {
+						return "", err
+					}
+					mappedCols = append(mappedCols, colName)
+				}
+				subExprs := make(sqlparser.SelectExprs, 0, len(mappedCols)+2)
+				for _, mappedCol := range mappedCols {
+					subExprs = append(subExprs, &sqlparser.AliasedExpr{Expr: mappedCol})
+				}
+				vindexName := fmt.Sprintf("%s.%s", mz.ms.TargetKeyspace, cv.Name)
+				subExprs = append(subExprs, &sqlparser.AliasedExpr{Expr: sqlparser.NewStrLiteral(vindexName)})
+				subExprs = append(subExprs, &sqlparser.AliasedExpr{Expr: sqlparser.NewStrLiteral("{{.keyrange}}")})
+				inKeyRange := &sqlparser.FuncExpr{
+					Name:  sqlparser.NewIdentifierCI("in_keyrange"),
+					Exprs: subExprs,
+				}
+				if sel.Where != nil {
+					sel.Where = &sqlparser.Where{
+						Type: sqlparser.WhereClause,
+						Expr: &sqlparser.AndExpr{
+							Left:  inKeyRange,
+							Right: sel.Where.Expr,
+						},
+					}
+				} else {
+					sel.Where = &sqlparser.Where{
+						Type: sqlparser.WhereClause,
+						Expr: inKeyRange,
+					}
+				}
+
+				filter = sqlparser.String(sel)
+			}
+
+			rule.Filter = filter
+
+			bls.Filter.Rules = append(bls.Filter.Rules, rule)
+		}
+		workflowSubType := binlogdatapb.VReplicationWorkflowSubType_None
+		if mz.isPartial {
+			workflowSubType = binlogdatapb.VReplicationWorkflowSubType_Partial
+		}
+		ig.AddRow(mz.ms.Workflow, bls, "", mz.ms.Cell, mz.ms.TabletTypes,
+			mz.workflowType,
+			workflowSubType,
+			mz.ms.DeferSecondaryKeys,
+		)
+	}
+	return ig.String(), nil
+}
+
+func matchColInSelect(col sqlparser.IdentifierCI, sel *sqlparser.Select) (*sqlparser.ColName, error) {
+	for _, selExpr := range sel.SelectExprs {
+		switch selExpr := selExpr.(type) {
+		case *sqlparser.StarExpr:
+			return &sqlparser.ColName{Name: col}, nil
+		case *sqlparser.AliasedExpr:
+			match := selExpr.As
+			if match.IsEmpty() {
+				if colExpr, ok := selExpr.Expr.(*sqlparser.ColName); ok {
+					match = colExpr.Name
+				} else {
+					// Cannot match against a complex expression.
+					continue
+				}
+			}
+			if match.Equal(col) {
+				colExpr, ok := selExpr.Expr.(*sqlparser.ColName)
+				if !ok {
+					return nil, fmt.Errorf("vindex column cannot be a complex expression: %v", sqlparser.String(selExpr))
+				}
+				return colExpr, nil
+			}
+		default:
+			return nil, fmt.Errorf("unsupported select expression: %v", sqlparser.String(selExpr))
+		}
+	}
+	return nil, fmt.Errorf("could not find vindex column %v", sqlparser.String(col))
+}
+
+func (mz *materializer) createStreams(ctx context.Context, insertsMap map[string]string) error {
+	return mz.forAllTargets(func(target *topo.ShardInfo) error {
+		inserts := insertsMap[target.ShardName()]
+		targetPrimary, err := mz.ts.GetTablet(ctx, target.PrimaryAlias)
+		if err != nil {
+			return vterrors.Wrapf(err, "GetTablet(%v) failed", target.PrimaryAlias)
+		}
+		buf := &strings.Builder{}
+		t := template.Must(template.New("").Parse(inserts))
+		input := map[string]string{
+			"keyrange": key.KeyRangeString(target.KeyRange),
+			"dbname":   targetPrimary.DbName(),
+		}
+		if err := t.Execute(buf, input); err != nil {
+			return err
+		}
+		if _, err := mz.tmc.VReplicationExec(ctx, targetPrimary.Tablet, buf.String()); err != nil {
+			return err
+		}
+		return nil
+	})
+}
+
+func (mz *materializer) startStreams(ctx context.Context) error {
+	return mz.forAllTargets(func(target *topo.ShardInfo) error {
+		targetPrimary, err := mz.ts.GetTablet(ctx, target.PrimaryAlias)
+		if err != nil {
+			return vterrors.Wrapf(err, "GetTablet(%v) failed", target.PrimaryAlias)
+		}
+		query := fmt.Sprintf("update _vt.vreplication set state='Running' where db_name=%s and workflow=%s", encodeString(targetPrimary.DbName()), encodeString(mz.ms.Workflow))
+		if _, err := mz.tmc.VReplicationExec(ctx, targetPrimary.Tablet, query); err != nil {
+			return vterrors.Wrapf(err, "VReplicationExec(%v, %s)", targetPrimary.Tablet, query)
+		}
+		return nil
+	})
+}
+
+func (mz *materializer) forAllTargets(f func(*topo.ShardInfo) error) error {
+	var wg sync.WaitGroup
+	allErrors := &concurrency.AllErrorRecorder{}
+	for _, target := range mz.targetShards {
+		wg.Add(1)
+		go func(target *topo.ShardInfo) {
+			defer wg.Done()
+
+			if err := f(target); err != nil {
+				allErrors.RecordError(err)
+			}
+		}(target)
+	}
+	wg.Wait()
+	return allErrors.AggrError(vterrors.Aggregate)
+}
+
+// checkTZConversion is a light-weight consistency check to validate that, if a source time zone is specified to MoveTables,
+// that the current primary has the time zone loaded in order to run the convert_tz() function used by VReplication to do the
+// datetime conversions. We only check the current primaries on each shard and note here that it is possible a new primary
+// gets elected: in this case user will either see errors during vreplication or vdiff will report mismatches.
+func (mz *materializer) checkTZConversion(ctx context.Context, tz string) error {
+	err := mz.forAllTargets(func(target *topo.ShardInfo) error {
+		targetPrimary, err := mz.ts.GetTablet(ctx, target.PrimaryAlias)
+		if err != nil {
+			return vterrors.Wrapf(err, "GetTablet(%v) failed", target.PrimaryAlias)
+		}
+		testDateTime := "2006-01-02 15:04:05"
+		query := fmt.Sprintf("select convert_tz(%s, %s, 'UTC')", encodeString(testDateTime), encodeString(tz))
+		qrproto, err := mz.tmc.ExecuteFetchAsApp(ctx, targetPrimary.Tablet, false, &tabletmanagerdatapb.ExecuteFetchAsAppRequest{
+			Query:   []byte(query),
+			MaxRows: 1,
+		})
+		if err != nil {
+			return vterrors.Wrapf(err, "ExecuteFetchAsApp(%v, %s)", targetPrimary.Tablet, query)
+		}
+		qr := sqltypes.Proto3ToResult(qrproto)
+		if gotDate, err := time.Parse(testDateTime, qr.Rows[0][0].ToString()); err != nil {
+			return fmt.Errorf("unable to perform time_zone conversions from %s to UTC — result of the attempt was: %s. Either the specified source time zone is invalid or the time zone tables have not been loaded on the %s tablet",
+				tz, gotDate, targetPrimary.Alias)
+		}
+		return nil
+	})
+	return err
+}
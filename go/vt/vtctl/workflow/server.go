@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math"
+	"reflect"
 	"sort"
 	"strings"
 	"sync"
@@ -27,22 +29,29 @@ import (
 
 	"google.golang.org/protobuf/encoding/prototext"
 
+	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sets"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/discovery"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
 	"vitess.io/vitess/go/vt/topo"
+	"vitess.io/vitess/go/vt/topotools"
 	"vitess.io/vitess/go/vt/vtctl/workflow/vexec"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/proto/vttime"
 )
 
@@ -58,26 +67,44 @@ var (
 	// target keyspaces across different shard primaries. This should be
 	// impossible.
 	ErrMultipleTargetKeyspaces = errors.New("multiple target keyspaces for a single workflow")
+	// ErrWorkflowNotFullySwitched occurs when trying to complete a workflow
+	// whose read and write traffic has not all been switched yet.
+	ErrWorkflowNotFullySwitched = errors.New("cannot complete workflow because you have not yet switched all read and write traffic")
+	// ErrWorkflowPartiallySwitched occurs when trying to cancel a workflow
+	// whose read or write traffic has already been (partially) switched.
+	ErrWorkflowPartiallySwitched = errors.New("cannot cancel workflow because you have already switched some or all read and write traffic")
+)
+
+const (
+	cannotSwitchError               = "workflow has errors"
+	cannotSwitchCopyIncomplete      = "copy is still in progress"
+	cannotSwitchHighLag             = "replication lag %ds is higher than allowed lag %ds"
+	cannotSwitchFailedTabletRefresh = "could not refresh all of the tablets involved in the operation:\n%s"
+	cannotSwitchFrozen              = "workflow is frozen"
+
+	defaultMaxReplicationLagAllowed = 30 * time.Second
+	defaultSwitchTrafficTimeout     = 30 * time.Second
 )
 
 // Server provides an API to work with Vitess workflows, like vreplication
 // workflows (MoveTables, Reshard, etc) and schema migration workflows.
 //
 // NB: This is in alpha, and you probably don't want to depend on it (yet!).
-// Currently, it provides only a read-only API to vreplication workflows. Write
-// actions on vreplication workflows, and schema migration workflows entirely,
-// are not yet supported, but planned.
+// Currently, write actions are only supported for MoveTables and Reshard
+// workflows. Schema migration workflows are not yet supported, but planned.
 type Server struct {
-	ts  *topo.Server
-	tmc tmclient.TabletManagerClient
+	ts     *topo.Server
+	tmc    tmclient.TabletManagerClient
+	logger logutil.Logger
 }
 
 // NewServer returns a new server instance with the given topo.Server and
 // TabletManagerClient.
 func NewServer(ts *topo.Server, tmc tmclient.TabletManagerClient) *Server {
 	return &Server{
-		ts:  ts,
-		tmc: tmc,
+		ts:     ts,
+		tmc:    tmc,
+		logger: logutil.NewConsoleLogger(),
 	}
 }
 
@@ -277,10 +304,18 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 
 	span.Annotate("keyspace", req.Keyspace)
 	span.Annotate("active_only", req.ActiveOnly)
+	span.Annotate("workflow", req.Workflow)
 
-	where := ""
+	var conds []string
 	if req.ActiveOnly {
-		where = "WHERE state <> 'Stopped'"
+		conds = append(conds, "state <> 'Stopped'")
+	}
+	if req.Workflow != "" {
+		conds = append(conds, fmt.Sprintf("workflow = %s", encodeString(req.Workflow)))
+	}
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
 	}
 
 	query := fmt.Sprintf(`
@@ -295,6 +330,7 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 			db_name,
 			time_updated,
 			transaction_timestamp,
+			time_heartbeat,
 			message,
 			tags,
 			workflow_type,
@@ -318,6 +354,7 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 	targetKeyspaceByWorkflow := make(map[string]string, len(results))
 	targetShardsByWorkflow := make(map[string]sets.Set[string], len(results))
 	maxVReplicationLagByWorkflow := make(map[string]float64, len(results))
+	maxVReplicationTransactionLagByWorkflow := make(map[string]int64, len(results))
 
 	// We guarantee the following invariants when this function is called for a
 	// given workflow:
@@ -365,6 +402,9 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 			return err
 		}
 
+		// time_heartbeat is NULL until the first heartbeat is recorded.
+		timeHeartbeatSeconds, _ := evalengine.ToInt64(row["time_heartbeat"])
+
 		message := row["message"].ToString()
 
 		tags := row["tags"].ToString()
@@ -389,6 +429,9 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 			TimeUpdated: &vttime.Time{
 				Seconds: timeUpdatedSeconds,
 			},
+			TimeHeartbeat: &vttime.Time{
+				Seconds: timeHeartbeatSeconds,
+			},
 			Message: message,
 			Tags:    tagArray,
 		}
@@ -466,6 +509,25 @@ func (s *Server) GetWorkflows(ctx context.Context, req *vtctldatapb.GetWorkflows
 			maxVReplicationLagByWorkflow[workflow.Name] = vreplicationLag.Seconds()
 		}
 
+		// The transaction lag is how far behind the source the stream is in
+		// terms of applied transactions. A stream that is still copying has
+		// not started replicating yet, so it is considered infinitely lagged.
+		var transactionLag int64
+		if stream.State == "Copying" {
+			transactionLag = math.MaxInt64
+		} else {
+			lastActivity := transactionTimeSeconds
+			if timeHeartbeatSeconds > lastActivity {
+				lastActivity = timeHeartbeatSeconds
+			}
+			if lastActivity > 0 {
+				transactionLag = time.Now().Unix() - lastActivity
+			}
+		}
+		if transactionLag > maxVReplicationTransactionLagByWorkflow[workflow.Name] {
+			maxVReplicationTransactionLagByWorkflow[workflow.Name] = transactionLag
+		}
+
 		return nil
 	}
 
@@ -693,6 +755,7 @@ ORDER BY
 		}
 
 		workflow.MaxVReplicationLag = int64(maxVReplicationLag)
+		workflow.MaxVReplicationTransactionLag = maxVReplicationTransactionLagByWorkflow[name]
 
 		// Sort shard streams by stream_id ASC, to support an optimization
 		// in fetchStreamLogs below.
@@ -798,3 +861,1535 @@ func (s *Server) WorkflowUpdate(ctx context.Context, req *vtctldatapb.WorkflowUp
 	response.Details = details
 	return response, nil
 }
+
+// MoveTablesCreate is part of the vtctlservicepb.VtctldServer interface.
+// It creates a MoveTables workflow that copies the requested tables from the
+// source keyspace to the target keyspace's primary tablets.
+func (s *Server) MoveTablesCreate(ctx context.Context, req *vtctldatapb.MoveTablesCreateRequest) (*vtctldatapb.MoveTablesCreateResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.MoveTablesCreate")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("source_keyspace", req.SourceKeyspace)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("on_ddl", req.OnDdl)
+
+	mz, err := s.moveTablesCreate(ctx, req, binlogdatapb.VReplicationWorkflowType_MoveTables)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &vtctldatapb.MoveTablesCreateResponse{}
+	response.Summary = fmt.Sprintf("Successfully created the %s MoveTables workflow on (%d) target primary tablets in the %s keyspace", req.Workflow, len(mz.targetShards), req.TargetKeyspace)
+	response.Details = make([]*vtctldatapb.MoveTablesCreateResponse_TabletInfo, 0, len(mz.targetShards))
+	err = s.workflowCreatedOnTargets(ctx, mz.targetShards, req.Workflow, func(tablet *topodatapb.TabletAlias, created bool) {
+		response.Details = append(response.Details, &vtctldatapb.MoveTablesCreateResponse_TabletInfo{
+			Tablet:  tablet,
+			Created: created,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// MigrateCreate is part of the vtctlservicepb.VtctldServer interface.
+// It creates a Migrate workflow that imports the requested tables from a
+// keyspace of a mounted external cluster to the target keyspace's primary
+// tablets. Unlike a MoveTables workflow, its traffic is never switched: it is
+// completed once the application has been pointed at the target keyspace.
+func (s *Server) MigrateCreate(ctx context.Context, req *vtctldatapb.MigrateCreateRequest) (*vtctldatapb.MigrateCreateResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.MigrateCreate")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("source_keyspace", req.SourceKeyspace)
+	span.Annotate("mount_name", req.MountName)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("on_ddl", req.OnDdl)
+
+	if req.MountName == "" {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "the name of the mounted external cluster must be provided")
+	}
+	vci, err := s.ts.GetExternalVitessCluster(ctx, req.MountName)
+	if err != nil {
+		return nil, err
+	}
+	if vci == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "there is no vitess cluster named %s", req.MountName)
+	}
+
+	mz, err := s.moveTablesCreate(ctx, &vtctldatapb.MoveTablesCreateRequest{
+		Workflow:            req.Workflow,
+		SourceKeyspace:      req.SourceKeyspace,
+		TargetKeyspace:      req.TargetKeyspace,
+		Cells:               req.Cells,
+		TabletTypes:         req.TabletTypes,
+		AllTables:           req.AllTables,
+		IncludeTables:       req.IncludeTables,
+		ExcludeTables:       req.ExcludeTables,
+		ExternalClusterName: req.MountName,
+		SourceTimeZone:      req.SourceTimeZone,
+		OnDdl:               req.OnDdl,
+		StopAfterCopy:       req.StopAfterCopy,
+		DropForeignKeys:     req.DropForeignKeys,
+		DeferSecondaryKeys:  req.DeferSecondaryKeys,
+		AutoStart:           req.AutoStart,
+	}, binlogdatapb.VReplicationWorkflowType_Migrate)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &vtctldatapb.MigrateCreateResponse{}
+	response.Summary = fmt.Sprintf("Successfully created the %s Migrate workflow on (%d) target primary tablets in the %s keyspace", req.Workflow, len(mz.targetShards), req.TargetKeyspace)
+	response.Details = make([]*vtctldatapb.MigrateCreateResponse_TabletInfo, 0, len(mz.targetShards))
+	err = s.workflowCreatedOnTargets(ctx, mz.targetShards, req.Workflow, func(tablet *topodatapb.TabletAlias, created bool) {
+		response.Details = append(response.Details, &vtctldatapb.MigrateCreateResponse_TabletInfo{
+			Tablet:  tablet,
+			Created: created,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// ReshardCreate is part of the vtctlservicepb.VtctldServer interface.
+// It creates a Reshard workflow that copies the keyspace's data from the
+// source shards to the target shards' primary tablets.
+func (s *Server) ReshardCreate(ctx context.Context, req *vtctldatapb.ReshardCreateRequest) (*vtctldatapb.ReshardCreateResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.ReshardCreate")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("source_shards", req.SourceShards)
+	span.Annotate("target_shards", req.TargetShards)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("on_ddl", req.OnDdl)
+
+	rs, err := s.reshardCreate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &vtctldatapb.ReshardCreateResponse{}
+	response.Summary = fmt.Sprintf("Successfully created the %s Reshard workflow on (%d) target primary tablets in the %s keyspace", req.Workflow, len(rs.targetShards), req.Keyspace)
+	response.Details = make([]*vtctldatapb.ReshardCreateResponse_TabletInfo, 0, len(rs.targetShards))
+	err = s.workflowCreatedOnTargets(ctx, rs.targetShards, req.Workflow, func(tablet *topodatapb.TabletAlias, created bool) {
+		response.Details = append(response.Details, &vtctldatapb.ReshardCreateResponse_TabletInfo{
+			Tablet:  tablet,
+			Created: created,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// WorkflowSwitchTraffic is part of the vtctlservicepb.VtctldServer interface.
+// It switches the requested tablet types' traffic for a MoveTables or Reshard
+// workflow from the source to the target.
+func (s *Server) WorkflowSwitchTraffic(ctx context.Context, req *vtctldatapb.WorkflowSwitchTrafficRequest) (*vtctldatapb.WorkflowSwitchTrafficResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.WorkflowSwitchTraffic")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("dry_run", req.DryRun)
+
+	return s.switchTraffic(ctx, req, DirectionForward)
+}
+
+// WorkflowReverseTraffic is part of the vtctlservicepb.VtctldServer interface.
+// It switches the requested tablet types' traffic for a MoveTables or Reshard
+// workflow back from the target to the source.
+func (s *Server) WorkflowReverseTraffic(ctx context.Context, req *vtctldatapb.WorkflowReverseTrafficRequest) (*vtctldatapb.WorkflowReverseTrafficResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.WorkflowReverseTraffic")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("cells", req.Cells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("dry_run", req.DryRun)
+
+	resp, err := s.switchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace:                 req.Keyspace,
+		Workflow:                 req.Workflow,
+		Cells:                    req.Cells,
+		TabletTypes:              req.TabletTypes,
+		MaxReplicationLagAllowed: req.MaxReplicationLagAllowed,
+		EnableReverseReplication: req.EnableReverseReplication,
+		Timeout:                  req.Timeout,
+		DryRun:                   req.DryRun,
+	}, DirectionBackward)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.WorkflowReverseTrafficResponse{
+		Summary:       resp.Summary,
+		StartState:    resp.StartState,
+		CurrentState:  resp.CurrentState,
+		DryRunResults: resp.DryRunResults,
+	}, nil
+}
+
+// WorkflowComplete is part of the vtctlservicepb.VtctldServer interface.
+// It cleans up the source side of a MoveTables or Reshard workflow once all
+// of its traffic has been switched to the target, and removes the workflow.
+func (s *Server) WorkflowComplete(ctx context.Context, req *vtctldatapb.WorkflowCompleteRequest) (*vtctldatapb.WorkflowCompleteResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.WorkflowComplete")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("keep_data", req.KeepData)
+	span.Annotate("keep_routing_rules", req.KeepRoutingRules)
+	span.Annotate("rename_tables", req.RenameTables)
+	span.Annotate("dry_run", req.DryRun)
+
+	ts, state, err := s.getWorkflowState(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if ts == nil {
+		return nil, fmt.Errorf("the %s workflow does not exist in the %s keyspace", req.Workflow, req.Keyspace)
+	}
+	var dryRunResults *[]string
+	if ts.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate {
+		dryRunResults, err = s.finalizeMigrateWorkflow(ctx, req.Keyspace, req.Workflow, false /* cancel */, req.KeepData, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		return workflowCompleteResponse(req, dryRunResults), nil
+	}
+	if !state.WritesSwitched || len(state.ReplicaCellsNotSwitched) > 0 || len(state.RdonlyCellsNotSwitched) > 0 {
+		return nil, ErrWorkflowNotFullySwitched
+	}
+
+	removalType := DropTable
+	if req.RenameTables {
+		removalType = RenameTable
+	}
+	dryRunResults, err = s.dropSources(ctx, req.Keyspace, req.Workflow, removalType, req.KeepData, req.KeepRoutingRules, false /* force */, req.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	return workflowCompleteResponse(req, dryRunResults), nil
+}
+
+func workflowCompleteResponse(req *vtctldatapb.WorkflowCompleteRequest, dryRunResults *[]string) *vtctldatapb.WorkflowCompleteResponse {
+	response := &vtctldatapb.WorkflowCompleteResponse{}
+	if req.DryRun {
+		response.Summary = fmt.Sprintf("Complete dry run results for workflow %s.%s at %v", req.Keyspace, req.Workflow, time.Now().UTC().Format(time.RFC822))
+		response.DryRunResults = *dryRunResults
+	} else {
+		response.Summary = fmt.Sprintf("Successfully completed the %s workflow in the %s keyspace", req.Workflow, req.Keyspace)
+	}
+	return response
+}
+
+// WorkflowCancel is part of the vtctlservicepb.VtctldServer interface.
+// It removes a MoveTables or Reshard workflow, along with the data it copied
+// to the target, as long as none of its traffic has been switched yet.
+func (s *Server) WorkflowCancel(ctx context.Context, req *vtctldatapb.WorkflowCancelRequest) (*vtctldatapb.WorkflowCancelResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.WorkflowCancel")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("keep_data", req.KeepData)
+	span.Annotate("keep_routing_rules", req.KeepRoutingRules)
+	span.Annotate("dry_run", req.DryRun)
+
+	ts, state, err := s.getWorkflowState(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if ts == nil {
+		return nil, fmt.Errorf("the %s workflow does not exist in the %s keyspace", req.Workflow, req.Keyspace)
+	}
+	var dryRunResults *[]string
+	if ts.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate {
+		dryRunResults, err = s.finalizeMigrateWorkflow(ctx, req.Keyspace, req.Workflow, true /* cancel */, req.KeepData, req.DryRun)
+	} else {
+		if state.WritesSwitched || len(state.ReplicaCellsSwitched) > 0 || len(state.RdonlyCellsSwitched) > 0 {
+			return nil, ErrWorkflowPartiallySwitched
+		}
+		dryRunResults, err = s.dropTargets(ctx, req.Keyspace, req.Workflow, req.KeepData, req.KeepRoutingRules, req.DryRun)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	response := &vtctldatapb.WorkflowCancelResponse{}
+	if req.DryRun {
+		response.Summary = fmt.Sprintf("Cancel dry run results for workflow %s.%s at %v", req.Keyspace, req.Workflow, time.Now().UTC().Format(time.RFC822))
+		response.DryRunResults = *dryRunResults
+	} else {
+		response.Summary = fmt.Sprintf("Successfully cancelled the %s workflow in the %s keyspace", req.Workflow, req.Keyspace)
+	}
+	return response, nil
+}
+
+// WorkflowStatus is part of the vtctlservicepb.VtctldServer interface.
+// It returns the copy progress, the per-stream state and the traffic state of
+// a MoveTables or Reshard workflow.
+func (s *Server) WorkflowStatus(ctx context.Context, req *vtctldatapb.WorkflowStatusRequest) (*vtctldatapb.WorkflowStatusResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.WorkflowStatus")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("workflow", req.Workflow)
+
+	ts, state, err := s.getWorkflowState(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if ts == nil {
+		return nil, fmt.Errorf("the %s workflow does not exist in the %s keyspace", req.Workflow, req.Keyspace)
+	}
+
+	copyProgress, err := s.getCopyProgress(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &vtctldatapb.WorkflowStatusResponse{
+		TableCopyState: make(map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState, len(copyProgress)),
+		ShardStreams:   make(map[string]*vtctldatapb.WorkflowStatusResponse_ShardStreams),
+		TrafficState:   workflowStateString(state),
+	}
+	for table, progress := range copyProgress {
+		var rowPct, bytePct float32
+		if progress.SourceRowCount > 0 {
+			rowPct = float32(100.0 * float64(progress.TargetRowCount) / float64(progress.SourceRowCount))
+		}
+		if progress.SourceTableSize > 0 {
+			bytePct = float32(100.0 * float64(progress.TargetTableSize) / float64(progress.SourceTableSize))
+		}
+		resp.TableCopyState[table] = &vtctldatapb.WorkflowStatusResponse_TableCopyState{
+			RowsCopied:      progress.TargetRowCount,
+			RowsTotal:       progress.SourceRowCount,
+			RowsPercentage:  rowPct,
+			BytesCopied:     progress.TargetTableSize,
+			BytesTotal:      progress.SourceTableSize,
+			BytesPercentage: bytePct,
+		}
+	}
+
+	workflow, err := s.getWorkflow(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	for _, shardStream := range workflow.ShardStreams {
+		for _, stream := range shardStream.Streams {
+			key := fmt.Sprintf("%s/%s", req.Keyspace, stream.Shard)
+			streams, ok := resp.ShardStreams[key]
+			if !ok {
+				streams = &vtctldatapb.WorkflowStatusResponse_ShardStreams{}
+				resp.ShardStreams[key] = streams
+			}
+
+			info := []string{}
+			if stream.Message != "" {
+				info = append(info, stream.Message)
+			}
+			if stream.TimeUpdated != nil && stream.TimeUpdated.Seconds > 0 {
+				info = append(info, fmt.Sprintf("Updated: %s", time.Unix(stream.TimeUpdated.Seconds, 0).UTC().Format(time.RFC3339)))
+			}
+			if stream.TransactionTimestamp != nil && stream.TransactionTimestamp.Seconds > 0 {
+				info = append(info, fmt.Sprintf("Tx time: %s", time.Unix(stream.TransactionTimestamp.Seconds, 0).UTC().Format(time.RFC3339)))
+			}
+
+			streams.Streams = append(streams.Streams, &vtctldatapb.WorkflowStatusResponse_ShardStreamState{
+				Id:          int32(stream.Id),
+				Tablet:      stream.Tablet,
+				SourceShard: fmt.Sprintf("%s/%s", stream.BinlogSource.Keyspace, stream.BinlogSource.Shard),
+				Position:    stream.Position,
+				Status:      stream.State,
+				Info:        strings.Join(info, "; "),
+			})
+		}
+	}
+	for _, streams := range resp.ShardStreams {
+		sort.Slice(streams.Streams, func(i, j int) bool {
+			return streams.Streams[i].Id < streams.Streams[j].Id
+		})
+	}
+
+	return resp, nil
+}
+
+// getWorkflow returns the workflow with the given name in the given keyspace.
+func (s *Server) getWorkflow(ctx context.Context, keyspace, workflow string) (*vtctldatapb.Workflow, error) {
+	resp, err := s.GetWorkflows(ctx, &vtctldatapb.GetWorkflowsRequest{
+		Keyspace: keyspace,
+		Workflow: workflow,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Workflows) != 1 {
+		return nil, fmt.Errorf("the %s workflow does not exist in the %s keyspace", workflow, keyspace)
+	}
+	return resp.Workflows[0], nil
+}
+
+// workflowCreatedOnTargets calls f, in order, with the primary tablet of each
+// of the target shards and whether the workflow was created on it.
+func (s *Server) workflowCreatedOnTargets(ctx context.Context, targetShards []*topo.ShardInfo, workflow string, f func(tablet *topodatapb.TabletAlias, created bool)) error {
+	for _, si := range targetShards {
+		created, err := s.workflowExistsOnTablet(ctx, si.PrimaryAlias, workflow)
+		if err != nil {
+			return err
+		}
+		f(si.PrimaryAlias, created)
+	}
+	return nil
+}
+
+// workflowExistsOnTablet returns true if the tablet with the given alias has
+// any streams for the given workflow.
+func (s *Server) workflowExistsOnTablet(ctx context.Context, tabletAlias *topodatapb.TabletAlias, workflow string) (bool, error) {
+	ti, err := s.ts.GetTablet(ctx, tabletAlias)
+	if err != nil {
+		return false, err
+	}
+	query := fmt.Sprintf("select 1 from _vt.vreplication where db_name=%s and workflow=%s", encodeString(ti.DbName()), encodeString(workflow))
+	qr, err := s.tmc.VReplicationExec(ctx, ti.Tablet, query)
+	if err != nil {
+		return false, err
+	}
+	return len(qr.Rows) > 0, nil
+}
+
+// switchTraffic switches the requested tablet types' traffic for the workflow
+// in the given direction. Reads are switched before writes.
+func (s *Server) switchTraffic(ctx context.Context, req *vtctldatapb.WorkflowSwitchTrafficRequest, direction TrafficSwitchDirection) (*vtctldatapb.WorkflowSwitchTrafficResponse, error) {
+	ts, startState, err := s.getWorkflowState(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	if ts == nil {
+		return nil, fmt.Errorf("the %s workflow does not exist in the %s keyspace", req.Workflow, req.Keyspace)
+	}
+	if ts.workflowType == binlogdatapb.VReplicationWorkflowType_Migrate {
+		return nil, fmt.Errorf("invalid action for Migrate workflow: SwitchTraffic")
+	}
+
+	maxReplicationLagAllowed, set, err := protoutil.DurationFromProto(req.MaxReplicationLagAllowed)
+	if err != nil {
+		return nil, err
+	}
+	if !set {
+		maxReplicationLagAllowed = defaultMaxReplicationLagAllowed
+	}
+	timeout, set, err := protoutil.DurationFromProto(req.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	if !set {
+		timeout = defaultSwitchTrafficTimeout
+	}
+
+	hasReplica, hasRdonly, hasPrimary, err := parseTabletTypes(req.TabletTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Before switching back, we check on the reverse workflow, which
+	// replicates from the original target keyspace to the original source.
+	keyspace, workflowName := req.Keyspace, req.Workflow
+	if direction == DirectionBackward {
+		keyspace, workflowName = startState.SourceKeyspace, ReverseWorkflowName(req.Workflow)
+	}
+	reason, err := s.canSwitch(ctx, ts, startState, direction, keyspace, workflowName, int64(math.Ceil(maxReplicationLagAllowed.Seconds())))
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return nil, fmt.Errorf("cannot switch traffic for workflow %s at this time: %s", workflowName, reason)
+	}
+
+	var dryRunResults []string
+	if hasReplica || hasRdonly {
+		var servedTypes []topodatapb.TabletType
+		if hasReplica {
+			servedTypes = append(servedTypes, topodatapb.TabletType_REPLICA)
+		}
+		if hasRdonly {
+			servedTypes = append(servedTypes, topodatapb.TabletType_RDONLY)
+		}
+		rdDryRunResults, err := s.switchReads(ctx, req.Keyspace, req.Workflow, servedTypes, req.Cells, direction, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		if rdDryRunResults != nil {
+			dryRunResults = append(dryRunResults, *rdDryRunResults...)
+		}
+	}
+	if hasPrimary {
+		// Writes are switched back using the reverse workflow, whose target
+		// is the original source keyspace.
+		targetKeyspace, targetWorkflow := req.Keyspace, req.Workflow
+		if direction == DirectionBackward {
+			targetKeyspace, targetWorkflow = startState.SourceKeyspace, ReverseWorkflowName(req.Workflow)
+		}
+		journalID, wrDryRunResults, err := s.switchWrites(ctx, targetKeyspace, targetWorkflow, timeout, false,
+			direction == DirectionBackward, req.EnableReverseReplication, req.DryRun)
+		if err != nil {
+			return nil, err
+		}
+		log.Infof("switchWrites succeeded with journal id %d", journalID)
+		if wrDryRunResults != nil {
+			dryRunResults = append(dryRunResults, *wrDryRunResults...)
+		}
+	}
+
+	cmd := "SwitchTraffic"
+	if direction == DirectionBackward {
+		cmd = "ReverseTraffic"
+	}
+	resp := &vtctldatapb.WorkflowSwitchTrafficResponse{
+		StartState: workflowStateString(startState),
+	}
+	if req.DryRun {
+		resp.Summary = fmt.Sprintf("%s dry run results for workflow %s.%s at %v", cmd, req.Keyspace, req.Workflow, time.Now().UTC().Format(time.RFC822))
+		resp.DryRunResults = dryRunResults
+		return resp, nil
+	}
+
+	_, currentState, err := s.getWorkflowState(ctx, req.Keyspace, req.Workflow)
+	if err != nil {
+		return nil, err
+	}
+	resp.Summary = fmt.Sprintf("%s was successful for workflow %s.%s", cmd, req.Keyspace, req.Workflow)
+	resp.CurrentState = workflowStateString(currentState)
+	return resp, nil
+}
+
+// canSwitch returns a non-empty reason if traffic cannot be switched for the
+// workflow at this time, e.g. because it is still copying or is lagging too
+// far behind the source.
+func (s *Server) canSwitch(ctx context.Context, ts *trafficSwitcher, state *State, direction TrafficSwitchDirection,
+	keyspace, workflowName string, maxAllowedTransactionLagSeconds int64) (reason string, err error) {
+	if direction == DirectionForward && state.WritesSwitched ||
+		direction == DirectionBackward && !state.WritesSwitched {
+		log.Infof("writes already switched no need to check lag")
+		return "", nil
+	}
+
+	workflow, err := s.getWorkflow(ctx, keyspace, workflowName)
+	if err != nil {
+		return "", err
+	}
+	for _, shardStream := range workflow.ShardStreams {
+		for _, stream := range shardStream.Streams {
+			switch {
+			case stream.State == "Copying":
+				return cannotSwitchCopyIncomplete, nil
+			case stream.State == "Error":
+				return cannotSwitchError, nil
+			case stream.Message == Frozen:
+				return cannotSwitchFrozen, nil
+			}
+		}
+	}
+	if workflow.MaxVReplicationTransactionLag > maxAllowedTransactionLagSeconds {
+		return fmt.Sprintf(cannotSwitchHighLag, workflow.MaxVReplicationTransactionLag, maxAllowedTransactionLagSeconds), nil
+	}
+
+	// Ensure that the tablets on both sides are in good shape as we make this
+	// same call in the process and an error will cause us to back out.
+	refreshErrors := strings.Builder{}
+	var m sync.Mutex
+	var wg sync.WaitGroup
+	rtbsCtx, cancel := context.WithTimeout(ctx, shardTabletRefreshTimeout)
+	defer cancel()
+	refreshTablets := func(shards []*topo.ShardInfo, stype string) {
+		defer wg.Done()
+		for _, si := range shards {
+			if partial, partialDetails, err := topotools.RefreshTabletsByShard(rtbsCtx, s.ts, s.tmc, si, nil, s.Logger()); err != nil || partial {
+				m.Lock()
+				refreshErrors.WriteString(fmt.Sprintf("failed to successfully refresh all tablets in the %s/%s %s shard (%v):\n  %v\n",
+					si.Keyspace(), si.ShardName(), stype, err, partialDetails))
+				m.Unlock()
+			}
+		}
+	}
+	wg.Add(1)
+	go refreshTablets(ts.SourceShards(), "source")
+	wg.Add(1)
+	go refreshTablets(ts.TargetShards(), "target")
+	wg.Wait()
+	if refreshErrors.Len() > 0 {
+		return fmt.Sprintf(cannotSwitchFailedTabletRefresh, refreshErrors.String()), nil
+	}
+	return "", nil
+}
+
+// parseTabletTypes reports which of the tablet types whose traffic can be
+// switched are in the given list. An empty list means all of them.
+func parseTabletTypes(tabletTypes []topodatapb.TabletType) (hasReplica, hasRdonly, hasPrimary bool, err error) {
+	if len(tabletTypes) == 0 {
+		return true, true, true, nil
+	}
+	for _, tabletType := range tabletTypes {
+		switch tabletType {
+		case topodatapb.TabletType_REPLICA:
+			hasReplica = true
+		case topodatapb.TabletType_RDONLY:
+			hasRdonly = true
+		case topodatapb.TabletType_PRIMARY:
+			hasPrimary = true
+		default:
+			return false, false, false, fmt.Errorf("invalid tablet type passed %s", tabletType)
+		}
+	}
+	return hasReplica, hasRdonly, hasPrimary, nil
+}
+
+// workflowStateString returns a human readable description of which traffic
+// has been switched for the workflow.
+func workflowStateString(state *State) string {
+	var stateInfo []string
+	s := ""
+	if !state.IsPartialMigration { // shard level traffic switching is all or nothing
+		if len(state.RdonlyCellsNotSwitched) == 0 && len(state.ReplicaCellsNotSwitched) == 0 && len(state.ReplicaCellsSwitched) > 0 {
+			s = "All Reads Switched"
+		} else if len(state.RdonlyCellsSwitched) == 0 && len(state.ReplicaCellsSwitched) == 0 {
+			s = "Reads Not Switched"
+		} else {
+			stateInfo = append(stateInfo, "Reads partially switched")
+			if len(state.ReplicaCellsNotSwitched) == 0 {
+				s += "All Replica Reads Switched"
+			} else if len(state.ReplicaCellsSwitched) == 0 {
+				s += "Replica not switched"
+			} else {
+				s += "Replica switched in cells: " + strings.Join(state.ReplicaCellsSwitched, ",")
+			}
+			stateInfo = append(stateInfo, s)
+			s = ""
+			if len(state.RdonlyCellsNotSwitched) == 0 {
+				s += "All Rdonly Reads Switched"
+			} else if len(state.RdonlyCellsSwitched) == 0 {
+				s += "Rdonly not switched"
+			} else {
+				s += "Rdonly switched in cells: " + strings.Join(state.RdonlyCellsSwitched, ",")
+			}
+		}
+		stateInfo = append(stateInfo, s)
+	}
+	if state.WritesSwitched {
+		stateInfo = append(stateInfo, "Writes Switched")
+	} else if state.IsPartialMigration {
+		// For partial migrations, the traffic switching is all or nothing
+		// at the shard level, so reads are effectively switched on the
+		// shard when writes are switched.
+		if len(state.ShardsAlreadySwitched) > 0 && len(state.ShardsNotYetSwitched) > 0 {
+			stateInfo = append(stateInfo, fmt.Sprintf("Reads partially switched, for shards: %s", strings.Join(state.ShardsAlreadySwitched, ",")))
+			stateInfo = append(stateInfo, fmt.Sprintf("Writes partially switched, for shards: %s", strings.Join(state.ShardsAlreadySwitched, ",")))
+		} else {
+			if len(state.ShardsAlreadySwitched) == 0 {
+				stateInfo = append(stateInfo, "Reads Not Switched")
+				stateInfo = append(stateInfo, "Writes Not Switched")
+			} else {
+				stateInfo = append(stateInfo, "All Reads Switched")
+				stateInfo = append(stateInfo, "All Writes Switched")
+			}
+		}
+	} else {
+		stateInfo = append(stateInfo, "Writes Not Switched")
+	}
+	return strings.Join(stateInfo, ". ")
+}
+
+// tableCopyProgress stores the row counts and disk sizes of the source and
+// target tables.
+type tableCopyProgress struct {
+	TargetRowCount, TargetTableSize int64
+	SourceRowCount, SourceTableSize int64
+}
+
+// copyProgress stores the tableCopyProgress for all tables still being copied.
+type copyProgress map[string]*tableCopyProgress
+
+// getCopyProgress returns the progress of all tables being copied in the
+// workflow.
+func (s *Server) getCopyProgress(ctx context.Context, ts *trafficSwitcher) (copyProgress, error) {
+	getTablesQuery := "select distinct table_name from _vt.copy_state cs, _vt.vreplication vr where vr.id = cs.vrepl_id and vr.id = %d"
+	getRowCountQuery := "select table_name, table_rows, data_length from information_schema.tables where table_schema = %s and table_name in (%s)"
+	tables := make(map[string]bool)
+	const MaxRows = 1000
+	sourcePrimaries := make(map[*topodatapb.TabletAlias]bool)
+	for _, target := range ts.targets {
+		for id, bls := range target.Sources {
+			query := fmt.Sprintf(getTablesQuery, id)
+			p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, target.GetPrimary().Tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+				Query:   []byte(query),
+				MaxRows: MaxRows,
+			})
+			if err != nil {
+				return nil, err
+			}
+			if len(p3qr.Rows) < 1 {
+				continue
+			}
+			qr := sqltypes.Proto3ToResult(p3qr)
+			for i := 0; i < len(p3qr.Rows); i++ {
+				tables[qr.Rows[i][0].ToString()] = true
+			}
+			sourcesi, err := s.ts.GetShard(ctx, bls.Keyspace, bls.Shard)
+			if err != nil {
+				return nil, err
+			}
+			found := false
+			for existingSource := range sourcePrimaries {
+				if existingSource.Uid == sourcesi.PrimaryAlias.Uid {
+					found = true
+				}
+			}
+			if !found {
+				sourcePrimaries[sourcesi.PrimaryAlias] = true
+			}
+		}
+	}
+	if len(tables) == 0 {
+		return nil, nil
+	}
+	var tableList []string
+	targetRowCounts := make(map[string]int64)
+	sourceRowCounts := make(map[string]int64)
+	targetTableSizes := make(map[string]int64)
+	sourceTableSizes := make(map[string]int64)
+
+	for table := range tables {
+		tableList = append(tableList, encodeString(table))
+		targetRowCounts[table] = 0
+		sourceRowCounts[table] = 0
+		targetTableSizes[table] = 0
+		sourceTableSizes[table] = 0
+	}
+
+	getTableMetrics := func(tablet *topodatapb.Tablet, query string, rowCounts map[string]int64, tableSizes map[string]int64) error {
+		p3qr, err := s.tmc.ExecuteFetchAsDba(ctx, tablet, true, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+			Query:   []byte(query),
+			MaxRows: uint64(len(tables)),
+		})
+		if err != nil {
+			return err
+		}
+		qr := sqltypes.Proto3ToResult(p3qr)
+		for i := 0; i < len(qr.Rows); i++ {
+			table := qr.Rows[i][0].ToString()
+			rowCount, err := evalengine.ToInt64(qr.Rows[i][1])
+			if err != nil {
+				return err
+			}
+			tableSize, err := evalengine.ToInt64(qr.Rows[i][2])
+			if err != nil {
+				return err
+			}
+			rowCounts[table] += rowCount
+			tableSizes[table] += tableSize
+		}
+		return nil
+	}
+	sourceDbName := ""
+	for _, tsSource := range ts.sources {
+		sourceDbName = tsSource.GetPrimary().DbName()
+		break
+	}
+	if sourceDbName == "" {
+		return nil, fmt.Errorf("no sources found for workflow %s.%s", ts.targetKeyspace, ts.workflow)
+	}
+	targetDbName := ""
+	for _, tsTarget := range ts.targets {
+		targetDbName = tsTarget.GetPrimary().DbName()
+		break
+	}
+	if sourceDbName == "" || targetDbName == "" {
+		return nil, fmt.Errorf("workflow %s.%s is incorrectly configured", ts.targetKeyspace, ts.workflow)
+	}
+	sort.Strings(tableList) // sort list for repeatability for mocking in tests
+	tablesStr := strings.Join(tableList, ",")
+	query := fmt.Sprintf(getRowCountQuery, encodeString(targetDbName), tablesStr)
+	for _, target := range ts.targets {
+		tablet := target.GetPrimary().Tablet
+		if err := getTableMetrics(tablet, query, targetRowCounts, targetTableSizes); err != nil {
+			return nil, err
+		}
+	}
+
+	query = fmt.Sprintf(getRowCountQuery, encodeString(sourceDbName), tablesStr)
+	for source := range sourcePrimaries {
+		ti, err := s.ts.GetTablet(ctx, source)
+		if err != nil {
+			return nil, err
+		}
+		if err := getTableMetrics(ti.Tablet, query, sourceRowCounts, sourceTableSizes); err != nil {
+			return nil, err
+		}
+	}
+
+	progress := copyProgress{}
+	for table, rowCount := range targetRowCounts {
+		progress[table] = &tableCopyProgress{
+			TargetRowCount:  rowCount,
+			TargetTableSize: targetTableSizes[table],
+			SourceRowCount:  sourceRowCounts[table],
+			SourceTableSize: sourceTableSizes[table],
+		}
+	}
+	return progress, nil
+}
+
+func (s *Server) getWorkflowState(ctx context.Context, targetKeyspace, workflowName string) (*trafficSwitcher, *State, error) {
+	ts, err := s.buildTrafficSwitcher(ctx, targetKeyspace, workflowName)
+
+	if ts == nil || err != nil {
+		if errors.Is(err, ErrNoStreams) || err.Error() == fmt.Sprintf(errorNoStreams, targetKeyspace, workflowName) {
+			return nil, nil, nil
+		}
+		s.Logger().Errorf("buildTrafficSwitcher failed: %v", err)
+		return nil, nil, err
+	}
+
+	state := &State{
+		Workflow:           workflowName,
+		SourceKeyspace:     ts.SourceKeyspaceName(),
+		TargetKeyspace:     targetKeyspace,
+		IsPartialMigration: ts.isPartialMigration,
+	}
+
+	var (
+		reverse  bool
+		keyspace string
+	)
+
+	// We reverse writes by using the source_keyspace.workflowname_reverse workflow
+	// spec, so we need to use the source of the reverse workflow, which is the
+	// target of the workflow initiated by the user for checking routing rules.
+	// Similarly we use a target shard of the reverse workflow as the original
+	// source to check if writes have been switched.
+	if strings.HasSuffix(workflowName, "_reverse") {
+		reverse = true
+		keyspace = state.SourceKeyspace
+		workflowName = ReverseWorkflowName(workflowName)
+	} else {
+		keyspace = targetKeyspace
+	}
+	if ts.MigrationType() == binlogdatapb.MigrationType_TABLES {
+		state.WorkflowType = TypeMoveTables
+
+		// We assume a consistent state, so only choose routing rule for one table.
+		if len(ts.Tables()) == 0 {
+			return nil, nil, fmt.Errorf("no tables in workflow %s.%s", keyspace, workflowName)
+
+		}
+		table := ts.Tables()[0]
+
+		if ts.isPartialMigration { // shard level traffic switching is all or nothing
+			shardRoutingRules, err := s.ts.GetShardRoutingRules(ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			rules := shardRoutingRules.Rules
+			for _, rule := range rules {
+				if rule.ToKeyspace == ts.SourceKeyspaceName() {
+					state.ShardsNotYetSwitched = append(state.ShardsNotYetSwitched, rule.Shard)
+				} else {
+					state.ShardsAlreadySwitched = append(state.ShardsAlreadySwitched, rule.Shard)
+				}
+			}
+		} else {
+			state.RdonlyCellsSwitched, state.RdonlyCellsNotSwitched, err = s.GetCellsWithTableReadsSwitched(ctx, keyspace, table, topodatapb.TabletType_RDONLY)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			state.ReplicaCellsSwitched, state.ReplicaCellsNotSwitched, err = s.GetCellsWithTableReadsSwitched(ctx, keyspace, table, topodatapb.TabletType_REPLICA)
+			if err != nil {
+				return nil, nil, err
+			}
+			globalRules, err := topotools.GetRoutingRules(ctx, ts.TopoServer())
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, table := range ts.Tables() {
+				rr := globalRules[table]
+				// if a rule exists for the table and points to the target keyspace, writes have been switched
+				if len(rr) > 0 && rr[0] == fmt.Sprintf("%s.%s", keyspace, table) {
+					state.WritesSwitched = true
+					break
+				}
+			}
+		}
+	} else {
+		state.WorkflowType = TypeReshard
+
+		// we assume a consistent state, so only choose one shard
+		var shard *topo.ShardInfo
+		if reverse {
+			shard = ts.TargetShards()[0]
+		} else {
+			shard = ts.SourceShards()[0]
+		}
+
+		state.RdonlyCellsSwitched, state.RdonlyCellsNotSwitched, err = s.GetCellsWithShardReadsSwitched(ctx, keyspace, shard, topodatapb.TabletType_RDONLY)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		state.ReplicaCellsSwitched, state.ReplicaCellsNotSwitched, err = s.GetCellsWithShardReadsSwitched(ctx, keyspace, shard, topodatapb.TabletType_REPLICA)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if !shard.IsPrimaryServing {
+			state.WritesSwitched = true
+		}
+	}
+
+	return ts, state, nil
+}
+
+// switchReads is a generic way of switching read traffic for a resharding workflow.
+func (s *Server) switchReads(ctx context.Context, targetKeyspace, workflowName string, servedTypes []topodatapb.TabletType,
+	cells []string, direction TrafficSwitchDirection, dryRun bool) (*[]string, error) {
+
+	ts, state, err := s.getWorkflowState(ctx, targetKeyspace, workflowName)
+	if err != nil {
+		s.Logger().Errorf("getWorkflowState failed: %v", err)
+		return nil, err
+	}
+	if ts == nil {
+		err := fmt.Errorf("workflow %s not found in keyspace %s", workflowName, targetKeyspace)
+		s.Logger().Error(err)
+		return nil, err
+	}
+	log.Infof("Switching reads: %s.%s tt %+v, cells %+v, workflow state: %+v", targetKeyspace, workflowName, servedTypes, cells, state)
+	var switchReplicas, switchRdonly bool
+	for _, servedType := range servedTypes {
+		if servedType != topodatapb.TabletType_REPLICA && servedType != topodatapb.TabletType_RDONLY {
+			return nil, fmt.Errorf("tablet type must be REPLICA or RDONLY: %v", servedType)
+		}
+		if direction == DirectionBackward && servedType == topodatapb.TabletType_REPLICA && len(state.ReplicaCellsSwitched) == 0 {
+			return nil, fmt.Errorf("requesting reversal of read traffic for REPLICAs but REPLICA reads have not been switched")
+		}
+		if direction == DirectionBackward && servedType == topodatapb.TabletType_RDONLY && len(state.RdonlyCellsSwitched) == 0 {
+			return nil, fmt.Errorf("requesting reversal of SwitchReads for RDONLYs but RDONLY reads have not been switched")
+		}
+		switch servedType {
+		case topodatapb.TabletType_REPLICA:
+			switchReplicas = true
+		case topodatapb.TabletType_RDONLY:
+			switchRdonly = true
+		}
+	}
+
+	// if there are no rdonly tablets in the cells ask to switch rdonly tablets as well so that routing rules
+	// are updated for rdonly as well. Otherwise vitess will not know that the workflow has completed and will
+	// incorrectly report that not all reads have been switched. User currently is forced to switch non-existent rdonly tablets
+	if switchReplicas && !switchRdonly {
+		var err error
+		rdonlyTabletsExist, err := topotools.DoCellsHaveRdonlyTablets(ctx, s.ts, cells)
+		if err != nil {
+			return nil, err
+		}
+		if !rdonlyTabletsExist {
+			servedTypes = append(servedTypes, topodatapb.TabletType_RDONLY)
+		}
+	}
+
+	// If journals exist notify user and fail
+	journalsExist, _, err := ts.checkJournals(ctx)
+	if err != nil {
+		s.Logger().Errorf("checkJournals failed: %v", err)
+		return nil, err
+	}
+	if journalsExist {
+		log.Infof("Found a previous journal entry for %d", ts.id)
+	}
+	var sw iswitcher
+	if dryRun {
+		sw = &switcherDryRun{ts: ts, drLog: NewLogRecorder()}
+	} else {
+		sw = &switcher{ts: ts, s: s}
+	}
+
+	if err := ts.validate(ctx); err != nil {
+		ts.Logger().Errorf("validate failed: %v", err)
+		return nil, err
+	}
+
+	// For reads, locking the source keyspace is sufficient.
+	ctx, unlock, lockErr := sw.lockKeyspace(ctx, ts.SourceKeyspaceName(), "SwitchReads")
+	if lockErr != nil {
+		ts.Logger().Errorf("LockKeyspace failed: %v", lockErr)
+		return nil, lockErr
+	}
+	defer unlock(&err)
+
+	if ts.MigrationType() == binlogdatapb.MigrationType_TABLES {
+		if ts.isPartialMigration {
+			ts.Logger().Infof("Partial migration, skipping switchTableReads as traffic is all or nothing per shard and overridden for reads AND writes in the ShardRoutingRule created when switching writes.")
+		} else if err := sw.switchTableReads(ctx, cells, servedTypes, direction); err != nil {
+			ts.Logger().Errorf("switchTableReads failed: %v", err)
+			return nil, err
+		}
+		return sw.logs(), nil
+	}
+	s.Logger().Infof("About to switchShardReads: %+v, %+v, %+v", cells, servedTypes, direction)
+	if err := sw.switchShardReads(ctx, cells, servedTypes, direction); err != nil {
+		ts.Logger().Errorf("switchShardReads failed: %v", err)
+		return nil, err
+	}
+
+	s.Logger().Infof("switchShardReads Completed: %+v, %+v, %+v", cells, servedTypes, direction)
+	if err := s.ts.ValidateSrvKeyspace(ctx, targetKeyspace, strings.Join(cells, ",")); err != nil {
+		err2 := vterrors.Wrapf(err, "After switching shard reads, found SrvKeyspace for %s is corrupt in cell %s",
+			targetKeyspace, strings.Join(cells, ","))
+		log.Errorf("%v", err2)
+		return nil, err2
+	}
+	return sw.logs(), nil
+}
+
+func (s *Server) areTabletsAvailableToStreamFrom(ctx context.Context, ts *trafficSwitcher, keyspace string, shards []*topo.ShardInfo) error {
+	var cells []string
+	tabletTypes := ts.optTabletTypes
+	if ts.optCells != "" {
+		cells = strings.Split(ts.optCells, ",")
+	}
+	// FIXME: currently there is a default setting in the tablet that is used if user does not specify a tablet type,
+	// we use the value specified in the tablet flag `-vreplication_tablet_type`
+	// but ideally we should populate the vreplication table with a default value when we setup the workflow
+	if tabletTypes == "" {
+		tabletTypes = "PRIMARY,REPLICA"
+	}
+
+	var wg sync.WaitGroup
+	allErrors := &concurrency.AllErrorRecorder{}
+	for _, shard := range shards {
+		wg.Add(1)
+		go func(cells []string, keyspace string, shard *topo.ShardInfo) {
+			defer wg.Done()
+			if cells == nil {
+				cells = append(cells, shard.PrimaryAlias.Cell)
+			}
+			tp, err := discovery.NewTabletPicker(ctx, s.ts, cells, shard.PrimaryAlias.Cell, keyspace, shard.ShardName(), tabletTypes, discovery.TabletPickerOptions{})
+			if err != nil {
+				allErrors.RecordError(err)
+				return
+			}
+			tablets := tp.GetMatchingTablets(ctx)
+			if len(tablets) == 0 {
+				allErrors.RecordError(fmt.Errorf("no tablet found to source data in keyspace %s, shard %s", keyspace, shard.ShardName()))
+				return
+			}
+		}(cells, keyspace, shard)
+	}
+
+	wg.Wait()
+	if allErrors.HasErrors() {
+		log.Errorf("%s", allErrors.Error())
+		return allErrors.Error()
+	}
+	return nil
+}
+
+// switchWrites is a generic way of migrating write traffic for a resharding workflow.
+func (s *Server) switchWrites(ctx context.Context, targetKeyspace, workflowName string, timeout time.Duration,
+	cancel, reverse, reverseReplication bool, dryRun bool) (journalID int64, dryRunResults *[]string, err error) {
+	ts, _, err := s.getWorkflowState(ctx, targetKeyspace, workflowName)
+	if err != nil {
+		s.Logger().Errorf("getWorkflowState failed: %v", err)
+		return 0, nil, err
+	}
+	if ts == nil {
+		err := fmt.Errorf("workflow %s not found in keyspace %s", workflowName, targetKeyspace)
+		s.Logger().Error(err)
+		return 0, nil, err
+	}
+
+	var sw iswitcher
+	if dryRun {
+		sw = &switcherDryRun{ts: ts, drLog: NewLogRecorder()}
+	} else {
+		sw = &switcher{ts: ts, s: s}
+	}
+
+	if ts.frozen {
+		ts.Logger().Warningf("Writes have already been switched for workflow %s, nothing to do here", ts.WorkflowName())
+		return 0, sw.logs(), nil
+	}
+
+	ts.Logger().Infof("Built switching metadata: %+v", ts)
+	if err := ts.validate(ctx); err != nil {
+		ts.Logger().Errorf("validate failed: %v", err)
+		return 0, nil, err
+	}
+
+	if reverseReplication {
+		err := s.areTabletsAvailableToStreamFrom(ctx, ts, ts.TargetKeyspaceName(), ts.TargetShards())
+		if err != nil {
+			return 0, nil, err
+		}
+	}
+
+	// Need to lock both source and target keyspaces.
+	tctx, sourceUnlock, lockErr := sw.lockKeyspace(ctx, ts.SourceKeyspaceName(), "SwitchWrites")
+	if lockErr != nil {
+		ts.Logger().Errorf("LockKeyspace failed: %v", lockErr)
+		return 0, nil, lockErr
+	}
+	ctx = tctx
+	defer sourceUnlock(&err)
+	if ts.TargetKeyspaceName() != ts.SourceKeyspaceName() {
+		tctx, targetUnlock, lockErr := sw.lockKeyspace(ctx, ts.TargetKeyspaceName(), "SwitchWrites")
+		if lockErr != nil {
+			ts.Logger().Errorf("LockKeyspace failed: %v", lockErr)
+			return 0, nil, lockErr
+		}
+		ctx = tctx
+		defer targetUnlock(&err)
+	}
+
+	// If no journals exist, sourceWorkflows will be initialized by sm.MigrateStreams.
+	journalsExist, sourceWorkflows, err := ts.checkJournals(ctx)
+	if err != nil {
+		ts.Logger().Errorf("checkJournals failed: %v", err)
+		return 0, nil, err
+	}
+	if !journalsExist {
+		ts.Logger().Infof("No previous journals were found. Proceeding normally.")
+		sm, err := BuildStreamMigrator(ctx, ts, cancel)
+		if err != nil {
+			ts.Logger().Errorf("buildStreamMigrater failed: %v", err)
+			return 0, nil, err
+		}
+		if cancel {
+			sw.cancelMigration(ctx, sm)
+			return 0, sw.logs(), nil
+		}
+
+		ts.Logger().Infof("Stopping streams")
+		sourceWorkflows, err = sw.stopStreams(ctx, sm)
+		if err != nil {
+			ts.Logger().Errorf("stopStreams failed: %v", err)
+			for key, streams := range sm.Streams() {
+				for _, stream := range streams {
+					ts.Logger().Errorf("stream in stopStreams: key %s shard %s stream %+v", key, stream.BinlogSource.Shard, stream.BinlogSource)
+				}
+			}
+			sw.cancelMigration(ctx, sm)
+			return 0, nil, err
+		}
+
+		ts.Logger().Infof("Stopping source writes")
+		if err := sw.stopSourceWrites(ctx); err != nil {
+			ts.Logger().Errorf("stopSourceWrites failed: %v", err)
+			sw.cancelMigration(ctx, sm)
+			return 0, nil, err
+		}
+
+		if ts.MigrationType() == binlogdatapb.MigrationType_TABLES {
+			ts.Logger().Infof("Executing LOCK TABLES on source tables %d times", lockTablesCycles)
+			// Doing this twice with a pause in-between to catch any writes that may have raced in between
+			// the tablet's deny list check and the first mysqld side table lock.
+			for cnt := 1; cnt <= lockTablesCycles; cnt++ {
+				if err := ts.executeLockTablesOnSource(ctx); err != nil {
+					ts.Logger().Errorf("Failed to execute LOCK TABLES (attempt %d of %d) on sources: %v", cnt, lockTablesCycles, err)
+					sw.cancelMigration(ctx, sm)
+					return 0, nil, err
+				}
+				// No need to UNLOCK the tables as the connection was closed once the locks were acquired
+				// and thus the locks released.
+				time.Sleep(lockTablesCycleDelay)
+			}
+		}
+
+		ts.Logger().Infof("Waiting for streams to catchup")
+		if err := sw.waitForCatchup(ctx, timeout); err != nil {
+			ts.Logger().Errorf("waitForCatchup failed: %v", err)
+			sw.cancelMigration(ctx, sm)
+			return 0, nil, err
+		}
+
+		ts.Logger().Infof("Migrating streams")
+		if err := sw.migrateStreams(ctx, sm); err != nil {
+			ts.Logger().Errorf("migrateStreams failed: %v", err)
+			sw.cancelMigration(ctx, sm)
+			return 0, nil, err
+		}
+
+		ts.Logger().Infof("Resetting sequences")
+		if err := sw.resetSequences(ctx); err != nil {
+			ts.Logger().Errorf("resetSequences failed: %v", err)
+			sw.cancelMigration(ctx, sm)
+			return 0, nil, err
+		}
+
+		ts.Logger().Infof("Creating reverse streams")
+		if err := sw.createReverseVReplication(ctx); err != nil {
+			ts.Logger().Errorf("createReverseVReplication failed: %v", err)
+			sw.cancelMigration(ctx, sm)
+			return 0, nil, err
+		}
+	} else {
+		if cancel {
+			err := fmt.Errorf("traffic switching has reached the point of no return, cannot cancel")
+			ts.Logger().Errorf("%v", err)
+			return 0, nil, err
+		}
+		ts.Logger().Infof("Journals were found. Completing the left over steps.")
+		// Need to gather positions in case all journals were not created.
+		if err := ts.gatherPositions(ctx); err != nil {
+			ts.Logger().Errorf("gatherPositions failed: %v", err)
+			return 0, nil, err
+		}
+	}
+
+	// This is the point of no return. Once a journal is created,
+	// traffic can be redirected to target shards.
+	if err := sw.createJournals(ctx, sourceWorkflows); err != nil {
+		ts.Logger().Errorf("createJournals failed: %v", err)
+		return 0, nil, err
+	}
+	if err := sw.allowTargetWrites(ctx); err != nil {
+		ts.Logger().Errorf("allowTargetWrites failed: %v", err)
+		return 0, nil, err
+	}
+	if err := sw.changeRouting(ctx); err != nil {
+		ts.Logger().Errorf("changeRouting failed: %v", err)
+		return 0, nil, err
+	}
+	if err := sw.streamMigraterfinalize(ctx, ts, sourceWorkflows); err != nil {
+		ts.Logger().Errorf("finalize failed: %v", err)
+		return 0, nil, err
+	}
+	if reverseReplication {
+		if err := sw.startReverseVReplication(ctx); err != nil {
+			ts.Logger().Errorf("startReverseVReplication failed: %v", err)
+			return 0, nil, err
+		}
+	}
+
+	if err := sw.freezeTargetVReplication(ctx); err != nil {
+		ts.Logger().Errorf("deleteTargetVReplication failed: %v", err)
+		return 0, nil, err
+	}
+
+	return ts.id, sw.logs(), nil
+}
+
+// finalizeMigrateWorkflow deletes the streams of a Migrate workflow. Only the
+// target is cleaned up, as the source belongs to the external cluster. When
+// the workflow is completed, the imported tables are added to the target
+// keyspace's vschema; when it is cancelled, they are dropped unless keepData
+// is set.
+func (s *Server) finalizeMigrateWorkflow(ctx context.Context, targetKeyspace, workflow string, cancel, keepData, dryRun bool) (*[]string, error) {
+	ts, err := s.buildTrafficSwitcher(ctx, targetKeyspace, workflow)
+	if err != nil {
+		s.Logger().Errorf("buildTrafficSwitcher failed: %v", err)
+		return nil, err
+	}
+	var sw iswitcher
+	if dryRun {
+		sw = &switcherDryRun{ts: ts, drLog: NewLogRecorder()}
+	} else {
+		sw = &switcher{ts: ts, s: s}
+	}
+	var tctx context.Context
+	tctx, targetUnlock, lockErr := sw.lockKeyspace(ctx, ts.TargetKeyspaceName(), "completeMigrateWorkflow")
+	if lockErr != nil {
+		ts.Logger().Errorf("Target LockKeyspace failed: %v", lockErr)
+		return nil, lockErr
+	}
+	defer targetUnlock(&err)
+	ctx = tctx
+	if err := sw.dropTargetVReplicationStreams(ctx); err != nil {
+		return nil, err
+	}
+	if !cancel {
+		if err := sw.addParticipatingTablesToKeyspace(ctx, targetKeyspace); err != nil {
+			// The streams are gone already, so the vschema is left for the
+			// user to fix rather than failing the whole operation.
+			ts.Logger().Warningf("Could not add the migrated tables to the %s vschema: %v", targetKeyspace, err)
+		}
+		if err := ts.TopoServer().RebuildSrvVSchema(ctx, nil); err != nil {
+			return nil, err
+		}
+	}
+	if cancel && !keepData {
+		if err := sw.removeTargetTables(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return sw.logs(), nil
+}
+
+// dropTargets cleans up target tables, shards and denied tables if a MoveTables/Reshard is cancelled
+func (s *Server) dropTargets(ctx context.Context, targetKeyspace, workflow string, keepData, keepRoutingRules, dryRun bool) (*[]string, error) {
+	ts, err := s.buildTrafficSwitcher(ctx, targetKeyspace, workflow)
+	if err != nil {
+		s.Logger().Errorf("buildTrafficSwitcher failed: %v", err)
+		return nil, err
+	}
+	ts.keepRoutingRules = keepRoutingRules
+	var sw iswitcher
+	if dryRun {
+		sw = &switcherDryRun{ts: ts, drLog: NewLogRecorder()}
+	} else {
+		sw = &switcher{ts: ts, s: s}
+	}
+	var tctx context.Context
+	tctx, sourceUnlock, lockErr := sw.lockKeyspace(ctx, ts.SourceKeyspaceName(), "DropTargets")
+	if lockErr != nil {
+		ts.Logger().Errorf("Source LockKeyspace failed: %v", lockErr)
+		return nil, lockErr
+	}
+	defer sourceUnlock(&err)
+	ctx = tctx
+	if ts.TargetKeyspaceName() != ts.SourceKeyspaceName() {
+		tctx, targetUnlock, lockErr := sw.lockKeyspace(ctx, ts.TargetKeyspaceName(), "DropTargets")
+		if lockErr != nil {
+			ts.Logger().Errorf("Target LockKeyspace failed: %v", lockErr)
+			return nil, lockErr
+		}
+		defer targetUnlock(&err)
+		ctx = tctx
+	}
+	if !keepData {
+		switch ts.MigrationType() {
+		case binlogdatapb.MigrationType_TABLES:
+			log.Infof("Deleting target tables")
+			if err := sw.removeTargetTables(ctx); err != nil {
+				return nil, err
+			}
+			if err := sw.dropSourceDeniedTables(ctx); err != nil {
+				return nil, err
+			}
+		case binlogdatapb.MigrationType_SHARDS:
+			log.Infof("Removing target shards")
+			if err := sw.dropTargetShards(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.dropArtifacts(ctx, keepRoutingRules, sw); err != nil {
+		return nil, err
+	}
+	if err := ts.TopoServer().RebuildSrvVSchema(ctx, nil); err != nil {
+		return nil, err
+	}
+	return sw.logs(), nil
+}
+
+func (s *Server) dropArtifacts(ctx context.Context, keepRoutingRules bool, sw iswitcher) error {
+	if err := sw.dropSourceReverseVReplicationStreams(ctx); err != nil {
+		return err
+	}
+	if err := sw.dropTargetVReplicationStreams(ctx); err != nil {
+		return err
+	}
+	if !keepRoutingRules {
+		if err := sw.deleteRoutingRules(ctx); err != nil {
+			return err
+		}
+		if err := sw.deleteShardRoutingRules(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dropSources cleans up source tables, shards and denied tables after a MoveTables/Reshard is completed
+func (s *Server) dropSources(ctx context.Context, targetKeyspace, workflowName string, removalType TableRemovalType, keepData, keepRoutingRules, force, dryRun bool) (*[]string, error) {
+	ts, err := s.buildTrafficSwitcher(ctx, targetKeyspace, workflowName)
+	if err != nil {
+		s.Logger().Errorf("buildTrafficSwitcher failed: %v", err)
+		return nil, err
+	}
+	var sw iswitcher
+	if dryRun {
+		sw = &switcherDryRun{ts: ts, drLog: NewLogRecorder()}
+	} else {
+		sw = &switcher{ts: ts, s: s}
+	}
+	var tctx context.Context
+	tctx, sourceUnlock, lockErr := sw.lockKeyspace(ctx, ts.SourceKeyspaceName(), "DropSources")
+	if lockErr != nil {
+		ts.Logger().Errorf("Source LockKeyspace failed: %v", lockErr)
+		return nil, lockErr
+	}
+	defer sourceUnlock(&err)
+	ctx = tctx
+	if ts.TargetKeyspaceName() != ts.SourceKeyspaceName() {
+		tctx, targetUnlock, lockErr := sw.lockKeyspace(ctx, ts.TargetKeyspaceName(), "DropSources")
+		if lockErr != nil {
+			ts.Logger().Errorf("Target LockKeyspace failed: %v", lockErr)
+			return nil, lockErr
+		}
+		defer targetUnlock(&err)
+		ctx = tctx
+	}
+	if !force {
+		if err := sw.validateWorkflowHasCompleted(ctx); err != nil {
+			s.Logger().Errorf("Workflow has not completed, cannot DropSources: %v", err)
+			return nil, err
+		}
+	}
+	if !keepData {
+		switch ts.MigrationType() {
+		case binlogdatapb.MigrationType_TABLES:
+			log.Infof("Deleting tables")
+			if err := sw.removeSourceTables(ctx, removalType); err != nil {
+				return nil, err
+			}
+			if err := sw.dropSourceDeniedTables(ctx); err != nil {
+				return nil, err
+			}
+
+		case binlogdatapb.MigrationType_SHARDS:
+			log.Infof("Removing shards")
+			if err := sw.dropSourceShards(ctx); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := s.dropArtifacts(ctx, keepRoutingRules, sw); err != nil {
+		return nil, err
+	}
+	if err := ts.TopoServer().RebuildSrvVSchema(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	return sw.logs(), nil
+}
+
+func (s *Server) buildTrafficSwitcher(ctx context.Context, targetKeyspace, workflowName string) (*trafficSwitcher, error) {
+	tgtInfo, err := BuildTargets(ctx, s.ts, s.tmc, targetKeyspace, workflowName)
+	if err != nil {
+		log.Infof("Error building targets: %s", err)
+		return nil, err
+	}
+	targets, frozen, optCells, optTabletTypes := tgtInfo.Targets, tgtInfo.Frozen, tgtInfo.OptCells, tgtInfo.OptTabletTypes
+
+	ts := &trafficSwitcher{
+		ws:              s,
+		workflow:        workflowName,
+		reverseWorkflow: ReverseWorkflowName(workflowName),
+		id:              HashStreams(targetKeyspace, targets),
+		targets:         targets,
+		sources:         make(map[string]*MigrationSource),
+		targetKeyspace:  targetKeyspace,
+		frozen:          frozen,
+		optCells:        optCells,
+		optTabletTypes:  optTabletTypes,
+		workflowType:    tgtInfo.WorkflowType,
+		workflowSubType: tgtInfo.WorkflowSubType,
+	}
+	log.Infof("Migration ID for workflow %s: %d", workflowName, ts.id)
+	sourceTopo := s.ts
+
+	// Build the sources
+	for _, target := range targets {
+		for _, bls := range target.Sources {
+			if ts.sourceKeyspace == "" {
+				ts.sourceKeyspace = bls.Keyspace
+				ts.sourceTimeZone = bls.SourceTimeZone
+				ts.targetTimeZone = bls.TargetTimeZone
+				ts.externalCluster = bls.ExternalCluster
+				if ts.externalCluster != "" {
+					externalTopo, err := s.ts.OpenExternalVitessClusterServer(ctx, ts.externalCluster)
+					if err != nil {
+						return nil, err
+					}
+					sourceTopo = externalTopo
+					ts.externalTopo = externalTopo
+				}
+			} else if ts.sourceKeyspace != bls.Keyspace {
+				return nil, fmt.Errorf("source keyspaces are mismatched across streams: %v vs %v", ts.sourceKeyspace, bls.Keyspace)
+			}
+
+			if ts.tables == nil {
+				for _, rule := range bls.Filter.Rules {
+					ts.tables = append(ts.tables, rule.Match)
+				}
+				sort.Strings(ts.tables)
+			} else {
+				var tables []string
+				for _, rule := range bls.Filter.Rules {
+					tables = append(tables, rule.Match)
+				}
+				sort.Strings(tables)
+				if !reflect.DeepEqual(ts.tables, tables) {
+					return nil, fmt.Errorf("table lists are mismatched across streams: %v vs %v", ts.tables, tables)
+				}
+			}
+
+			if _, ok := ts.sources[bls.Shard]; ok {
+				continue
+			}
+			sourcesi, err := sourceTopo.GetShard(ctx, bls.Keyspace, bls.Shard)
+			if err != nil {
+				return nil, err
+			}
+			sourcePrimary, err := sourceTopo.GetTablet(ctx, sourcesi.PrimaryAlias)
+			if err != nil {
+				return nil, err
+			}
+			ts.sources[bls.Shard] = NewMigrationSource(sourcesi, sourcePrimary)
+		}
+	}
+	if ts.sourceKeyspace != ts.targetKeyspace || ts.externalCluster != "" {
+		ts.migrationType = binlogdatapb.MigrationType_TABLES
+	} else {
+		// TODO(sougou): for shard migration, validate that source and target combined
+		// keyranges match.
+		ts.migrationType = binlogdatapb.MigrationType_SHARDS
+		for sourceShard := range ts.sources {
+			if _, ok := ts.targets[sourceShard]; ok {
+				// If shards are overlapping, then this is a table migration.
+				ts.migrationType = binlogdatapb.MigrationType_TABLES
+				break
+			}
+		}
+	}
+	vs, err := sourceTopo.GetVSchema(ctx, ts.sourceKeyspace)
+	if err != nil {
+		return nil, err
+	}
+	ts.sourceKSSchema, err = vindexes.BuildKeyspaceSchema(vs, ts.sourceKeyspace)
+	if err != nil {
+		return nil, err
+	}
+
+	sourceShards, targetShards := ts.getSourceAndTargetShardsNames()
+
+	ts.isPartialMigration, err = ts.isPartialMoveTables(sourceShards, targetShards)
+	if err != nil {
+		return nil, err
+	}
+	if ts.isPartialMigration {
+		log.Infof("Migration is partial, for shards %+v", sourceShards)
+	}
+	return ts, nil
+}
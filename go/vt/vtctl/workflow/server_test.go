@@ -28,16 +28,22 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/topotools"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 )
 
 type fakeTMC struct {
 	tmclient.TabletManagerClient
 	vrepQueriesByTablet map[string]map[string]*querypb.QueryResult
+	dbaQueriesByTablet  map[string]map[string]*querypb.QueryResult
 }
 
 func (fake *fakeTMC) VReplicationExec(ctx context.Context, tablet *topodatapb.Tablet, query string) (*querypb.QueryResult, error) {
@@ -55,6 +61,21 @@ func (fake *fakeTMC) VReplicationExec(ctx context.Context, tablet *topodatapb.Ta
 	return p3qr, nil
 }
 
+func (fake *fakeTMC) ExecuteFetchAsDba(ctx context.Context, tablet *topodatapb.Tablet, usePool bool, req *tabletmanagerdatapb.ExecuteFetchAsDbaRequest) (*querypb.QueryResult, error) {
+	alias := topoproto.TabletAliasString(tablet.Alias)
+	tabletQueries, ok := fake.dbaQueriesByTablet[alias]
+	if !ok {
+		return nil, fmt.Errorf("no dba query map registered on fake for %s", alias)
+	}
+
+	p3qr, ok := tabletQueries[string(req.Query)]
+	if !ok {
+		return nil, fmt.Errorf("no result on fake for dba query %q on tablet %s", req.Query, alias)
+	}
+
+	return p3qr, nil
+}
+
 func TestCheckReshardingJournalExistsOnTablet(t *testing.T) {
 	t.Parallel()
 
@@ -163,3 +184,1101 @@ func TestCheckReshardingJournalExistsOnTablet(t *testing.T) {
 		})
 	}
 }
+
+func TestParseTabletTypes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		tabletTypes []topodatapb.TabletType
+		hasReplica  bool
+		hasRdonly   bool
+		hasPrimary  bool
+		shouldErr   bool
+	}{
+		{
+			name:       "empty list means all types",
+			hasReplica: true,
+			hasRdonly:  true,
+			hasPrimary: true,
+		},
+		{
+			name:        "reads only",
+			tabletTypes: []topodatapb.TabletType{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY},
+			hasReplica:  true,
+			hasRdonly:   true,
+		},
+		{
+			name:        "writes only",
+			tabletTypes: []topodatapb.TabletType{topodatapb.TabletType_PRIMARY},
+			hasPrimary:  true,
+		},
+		{
+			name:        "invalid type",
+			tabletTypes: []topodatapb.TabletType{topodatapb.TabletType_REPLICA, topodatapb.TabletType_BACKUP},
+			shouldErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			hasReplica, hasRdonly, hasPrimary, err := parseTabletTypes(tt.tabletTypes)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.hasReplica, hasReplica, "hasReplica")
+			assert.Equal(t, tt.hasRdonly, hasRdonly, "hasRdonly")
+			assert.Equal(t, tt.hasPrimary, hasPrimary, "hasPrimary")
+		})
+	}
+}
+
+func TestWorkflowStateString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		state *State
+		want  string
+	}{
+		{
+			name: "nothing switched",
+			state: &State{
+				ReplicaCellsNotSwitched: []string{"zone1"},
+				RdonlyCellsNotSwitched:  []string{"zone1"},
+			},
+			want: "Reads Not Switched. Writes Not Switched",
+		},
+		{
+			name: "replica reads switched in some cells",
+			state: &State{
+				ReplicaCellsSwitched:    []string{"zone1"},
+				ReplicaCellsNotSwitched: []string{"zone2"},
+				RdonlyCellsNotSwitched:  []string{"zone1", "zone2"},
+			},
+			want: "Reads partially switched. Replica switched in cells: zone1. Rdonly not switched. Writes Not Switched",
+		},
+		{
+			name: "everything switched",
+			state: &State{
+				ReplicaCellsSwitched: []string{"zone1"},
+				RdonlyCellsSwitched:  []string{"zone1"},
+				WritesSwitched:       true,
+			},
+			want: "All Reads Switched. Writes Switched",
+		},
+		{
+			name: "partial migration with some shards switched",
+			state: &State{
+				IsPartialMigration:    true,
+				ShardsAlreadySwitched: []string{"-80"},
+				ShardsNotYetSwitched:  []string{"80-"},
+			},
+			want: "Reads partially switched, for shards: -80. Writes partially switched, for shards: -80",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, workflowStateString(tt.state))
+		})
+	}
+}
+
+// newMoveTablesTestEnv returns a testEnv in which the customer and corder
+// tables are moved from the unsharded source keyspace to the target keyspace,
+// sharded in two.
+func newMoveTablesTestEnv(t *testing.T) *testEnv {
+	env := newTestEnv(t, &testKeyspace{
+		name:   "source",
+		shards: []string{"0"},
+	}, &testKeyspace{
+		name:   "target",
+		shards: []string{"-80", "80-"},
+		vs: &vschemapb.Keyspace{
+			Sharded: true,
+			Vindexes: map[string]*vschemapb.Vindex{
+				"hash": {Type: "hash"},
+			},
+			Tables: map[string]*vschemapb.Table{
+				"customer": {
+					ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "customer_id", Name: "hash"}},
+				},
+				"corder": {
+					ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "customer_id", Name: "hash"}},
+				},
+			},
+		},
+	})
+	env.tmc.setSchema("source", map[string]string{
+		"customer": "create table customer(customer_id bigint, email varchar(128), primary key(customer_id))",
+		"corder":   "create table corder(order_id bigint, customer_id bigint, sku varchar(128), primary key(order_id))",
+	})
+	return env
+}
+
+func TestMoveTablesCreate(t *testing.T) {
+	// The routing rules keep routing all the traffic of the customer table
+	// to the source keyspace until it is switched.
+	customerRules := map[string][]string{
+		"customer":                {"source.customer"},
+		"customer@replica":        {"source.customer"},
+		"customer@rdonly":         {"source.customer"},
+		"target.customer":         {"source.customer"},
+		"target.customer@replica": {"source.customer"},
+		"target.customer@rdonly":  {"source.customer"},
+		"source.customer@replica": {"source.customer"},
+		"source.customer@rdonly":  {"source.customer"},
+	}
+	tests := []struct {
+		name      string
+		req       *vtctldatapb.MoveTablesCreateRequest
+		exists    bool
+		wantErr   string
+		wantRules map[string][]string
+	}{
+		{
+			name: "tables",
+			req: &vtctldatapb.MoveTablesCreateRequest{
+				IncludeTables: []string{"customer"},
+			},
+			wantRules: customerRules,
+		},
+		{
+			name: "all tables but excluded ones, started",
+			req: &vtctldatapb.MoveTablesCreateRequest{
+				AllTables:     true,
+				ExcludeTables: []string{"corder"},
+				AutoStart:     true,
+			},
+			wantRules: customerRules,
+		},
+		{
+			name: "missing table",
+			req: &vtctldatapb.MoveTablesCreateRequest{
+				IncludeTables: []string{"customer", "product"},
+			},
+			wantErr: "table(s) not found in source keyspace source: product",
+		},
+		{
+			name:    "no tables",
+			req:     &vtctldatapb.MoveTablesCreateRequest{},
+			wantErr: "no tables to move",
+		},
+		{
+			name: "existing workflow",
+			req: &vtctldatapb.MoveTablesCreateRequest{
+				IncludeTables: []string{"customer"},
+			},
+			exists:  true,
+			wantErr: "workflow wf1 already exists in keyspace target",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newMoveTablesTestEnv(t)
+			existsQuery := "select 1 from _vt.vreplication where db_name='vt_target' and workflow='wf1'"
+			for _, tablet := range env.targetTablets() {
+				env.tmc.addQuery(tablet, existsQuery, sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1")))
+				if !tt.exists {
+					env.tmc.addQueryOnce(tablet, existsQuery, nil)
+				}
+				env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_target' and message='FROZEN' and workflow_sub_type != 1", nil)
+				env.tmc.addQueryRE(tablet, `^insert into _vt\.vreplication`, nil)
+				env.tmc.addQuery(tablet, "select id from _vt.vreplication where db_name='vt_target' and workflow='wf1'", idsResult(1))
+				env.tmc.addQuery(tablet, "update _vt.vreplication set state='Running' where db_name='vt_target' and workflow='wf1'", nil)
+			}
+			for _, tablet := range env.sourceTablets() {
+				env.tmc.addQueryRE(tablet, `^select val from _vt\.resharding_journal where id=`, nil)
+			}
+
+			req := tt.req
+			req.Workflow = testWorkflow
+			req.SourceKeyspace = "source"
+			req.TargetKeyspace = "target"
+			resp, err := env.ws.MoveTablesCreate(ctx, req)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "Successfully created the wf1 MoveTables workflow on (2) target primary tablets in the target keyspace", resp.Summary)
+			require.Len(t, resp.Details, 2)
+			for i, tablet := range env.targetTablets() {
+				utils.MustMatch(t, tablet.Alias, resp.Details[i].Tablet)
+				assert.True(t, resp.Details[i].Created)
+
+				// The target tables are created from the source schema, and
+				// each target shard streams its key range of the rows.
+				applied := env.tmc.executed(tablet, `^create table`)
+				assert.Equal(t, []string{"create table customer(customer_id bigint, email varchar(128), primary key(customer_id))"}, applied)
+				inserts := env.tmc.executed(tablet, `^insert into _vt\.vreplication`)
+				require.Len(t, inserts, 1)
+				assert.Contains(t, inserts[0], fmt.Sprintf(`in_keyrange(customer_id, \'target.hash\', \'%s\')`, tablet.Shard))
+				assert.Contains(t, inserts[0], `keyspace:\"source\" shard:\"0\"`)
+				assert.NotContains(t, inserts[0], "corder")
+
+				started := env.tmc.executed(tablet, `^update _vt\.vreplication set state='Running'`)
+				if req.AutoStart {
+					assert.Len(t, started, 1)
+				} else {
+					assert.Empty(t, started)
+				}
+			}
+
+			rules, err := topotools.GetRoutingRules(ctx, env.ts)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantRules, rules)
+		})
+	}
+}
+
+func TestMigrateCreate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     *vtctldatapb.MigrateCreateRequest
+		wantErr string
+	}{
+		{
+			name: "tables",
+			req: &vtctldatapb.MigrateCreateRequest{
+				MountName:     "ext1",
+				IncludeTables: []string{"customer"},
+				AutoStart:     true,
+			},
+		},
+		{
+			name:    "no mount name",
+			req:     &vtctldatapb.MigrateCreateRequest{},
+			wantErr: "the name of the mounted external cluster must be provided",
+		},
+		{
+			name: "unknown mount",
+			req: &vtctldatapb.MigrateCreateRequest{
+				MountName:     "ext2",
+				IncludeTables: []string{"customer"},
+			},
+			wantErr: "there is no vitess cluster named ext2",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newMoveTablesTestEnv(t)
+			// The source keyspace is migrated from the env's own topo,
+			// mounted as the ext1 external cluster.
+			env.mountSelf(t, "ext1")
+			for _, tablet := range env.targetTablets() {
+				env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_target' and workflow='wf1'", sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1")))
+				env.tmc.addQueryOnce(tablet, "select 1 from _vt.vreplication where db_name='vt_target' and workflow='wf1'", nil)
+				env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_target' and message='FROZEN' and workflow_sub_type != 1", nil)
+				env.tmc.addQueryRE(tablet, `^insert into _vt\.vreplication`, nil)
+				env.tmc.addQuery(tablet, "select id from _vt.vreplication where db_name='vt_target' and workflow='wf1'", idsResult(1))
+				env.tmc.addQuery(tablet, "update _vt.vreplication set state='Running' where db_name='vt_target' and workflow='wf1'", nil)
+			}
+			for _, tablet := range env.sourceTablets() {
+				env.tmc.addQueryRE(tablet, `^select val from _vt\.resharding_journal where id=`, nil)
+			}
+
+			req := tt.req
+			req.Workflow = testWorkflow
+			req.SourceKeyspace = "source"
+			req.TargetKeyspace = "target"
+			resp, err := env.ws.MigrateCreate(ctx, req)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "Successfully created the wf1 Migrate workflow on (2) target primary tablets in the target keyspace", resp.Summary)
+			require.Len(t, resp.Details, 2)
+			for i, tablet := range env.targetTablets() {
+				utils.MustMatch(t, tablet.Alias, resp.Details[i].Tablet)
+				assert.True(t, resp.Details[i].Created)
+
+				inserts := env.tmc.executed(tablet, `^insert into _vt\.vreplication`)
+				require.Len(t, inserts, 1)
+				assert.Contains(t, inserts[0], `external_cluster:\"ext1\"`)
+				assert.Contains(t, inserts[0], fmt.Sprintf(", 'vt_target', %d, ", binlogdatapb.VReplicationWorkflowType_Migrate))
+				assert.Len(t, env.tmc.executed(tablet, `^update _vt\.vreplication set state='Running'`), 1)
+			}
+
+			// Unlike MoveTables, Migrate does not route the traffic of the
+			// tables to the external cluster's keyspace.
+			rules, err := topotools.GetRoutingRules(ctx, env.ts)
+			require.NoError(t, err)
+			assert.Empty(t, rules)
+		})
+	}
+}
+
+// newReshardTestEnv returns a testEnv in which the unsharded customer
+// keyspace is split in two.
+func newReshardTestEnv(t *testing.T) *testEnv {
+	ks := &testKeyspace{
+		name:   "customer",
+		shards: []string{"0"},
+		vs: &vschemapb.Keyspace{
+			Sharded: true,
+			Vindexes: map[string]*vschemapb.Vindex{
+				"hash": {Type: "hash"},
+			},
+			Tables: map[string]*vschemapb.Table{
+				"customer": {
+					ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "customer_id", Name: "hash"}},
+				},
+				"zipcode": {
+					Type: vindexes.TypeReference,
+				},
+			},
+		},
+	}
+	env := newTestEnv(t, ks, &testKeyspace{
+		name:   "customer",
+		shards: []string{"-80", "80-"},
+	})
+	env.tmc.setSchema("customer", map[string]string{
+		"customer": "create table customer(customer_id bigint, email varchar(128), primary key(customer_id))",
+		"zipcode":  "create table zipcode(code varchar(16), city varchar(128), primary key(code))",
+	})
+	return env
+}
+
+func TestReshardCreate(t *testing.T) {
+	refStream := func(t *testing.T, workflow string, table string) *querypb.QueryResult {
+		source, err := prototext.Marshal(&binlogdatapb.BinlogSource{
+			Keyspace: "product",
+			Shard:    "0",
+			Filter: &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{Match: table}},
+			},
+		})
+		require.NoError(t, err)
+		return sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields(
+			"workflow|source|cell|tablet_types",
+			"varchar|varbinary|varchar|varchar"),
+			fmt.Sprintf("%s|%s||", workflow, source),
+		))
+	}
+
+	tests := []struct {
+		name         string
+		req          *vtctldatapb.ReshardCreateRequest
+		sourceShards []string
+		targetShards []string
+		// sourceStreams is the result of the query for the streams
+		// replicating into the source shards.
+		sourceStreams *querypb.QueryResult
+		// targetStreams is set if the target shards already have streams.
+		targetStreams bool
+		wantErr       string
+		wantInserts   []string
+	}{
+		{
+			name: "one to many",
+			req:  &vtctldatapb.ReshardCreateRequest{},
+			wantInserts: []string{
+				`'keyspace:\"customer\" shard:\"0\" filter:{rules:{match:\"zipcode\" filter:\"exclude\"} rules:{match:\"/.*\" filter:\"-80\"}}'`,
+				`'keyspace:\"customer\" shard:\"0\" filter:{rules:{match:\"zipcode\" filter:\"exclude\"} rules:{match:\"/.*\" filter:\"80-\"}}'`,
+			},
+		},
+		{
+			name:          "reference table stream",
+			req:           &vtctldatapb.ReshardCreateRequest{},
+			sourceStreams: refStream(t, "zipcodes", "zipcode"),
+			wantInserts: []string{
+				`'keyspace:\"product\" shard:\"0\" filter:{rules:{match:\"zipcode\"}}'`,
+				`'keyspace:\"product\" shard:\"0\" filter:{rules:{match:\"zipcode\"}}'`,
+			},
+		},
+		{
+			name:          "sharded table stream",
+			req:           &vtctldatapb.ReshardCreateRequest{},
+			sourceStreams: refStream(t, "customers", "customer"),
+			wantInserts: []string{
+				`filter:\"-80\"`,
+				`filter:\"80-\"`,
+			},
+		},
+		{
+			name:          "unnamed stream",
+			req:           &vtctldatapb.ReshardCreateRequest{},
+			sourceStreams: refStream(t, "", "zipcode"),
+			wantErr:       "VReplication streams must have named workflows for migration: shard: customer:0",
+		},
+		{
+			name:          "stream of a table missing from the vschema",
+			req:           &vtctldatapb.ReshardCreateRequest{},
+			sourceStreams: refStream(t, "products", "product"),
+			wantErr:       "table product not found in vschema",
+		},
+		{
+			name:          "target already resharding",
+			req:           &vtctldatapb.ReshardCreateRequest{},
+			targetStreams: true,
+			wantErr:       "some streams already exist in the target shards, please clean them up and retry the command",
+		},
+		{
+			name:         "serving target",
+			req:          &vtctldatapb.ReshardCreateRequest{},
+			targetShards: []string{"0"},
+			wantErr:      "target shard 0 is in serving state",
+		},
+		{
+			name:         "non-serving source",
+			req:          &vtctldatapb.ReshardCreateRequest{},
+			sourceShards: []string{"-80"},
+			wantErr:      "source shard -80 is not in serving state",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newReshardTestEnv(t)
+			for _, tablet := range env.tablets {
+				env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_customer' and workflow='wf1'", nil)
+				env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_customer' and message='FROZEN' and workflow_sub_type != 1", nil)
+			}
+			for _, tablet := range env.sourceTablets() {
+				env.tmc.addQuery(tablet, "select workflow, source, cell, tablet_types from _vt.vreplication where db_name='vt_customer' and message != 'FROZEN'", tt.sourceStreams)
+			}
+			var targetStreams *querypb.QueryResult
+			if tt.targetStreams {
+				targetStreams = idsResult(1)
+			}
+			for _, tablet := range env.targetTablets() {
+				env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_customer'", targetStreams)
+				env.tmc.addQueryRE(tablet, `^insert into _vt\.vreplication`, nil)
+				env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_customer' and workflow='wf1'", sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("1", "int64"), "1")))
+				env.tmc.addQueryOnce(tablet, "select 1 from _vt.vreplication where db_name='vt_customer' and workflow='wf1'", nil)
+			}
+
+			req := tt.req
+			req.Workflow = testWorkflow
+			req.Keyspace = "customer"
+			req.SourceShards = env.sourceKeyspace.shards
+			if tt.sourceShards != nil {
+				req.SourceShards = tt.sourceShards
+			}
+			req.TargetShards = env.targetKeyspace.shards
+			if tt.targetShards != nil {
+				req.TargetShards = tt.targetShards
+			}
+			req.SkipSchemaCopy = true
+			resp, err := env.ws.ReshardCreate(ctx, req)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, "Successfully created the wf1 Reshard workflow on (2) target primary tablets in the customer keyspace", resp.Summary)
+			require.Len(t, resp.Details, 2)
+			for i, tablet := range env.targetTablets() {
+				utils.MustMatch(t, tablet.Alias, resp.Details[i].Tablet)
+				assert.True(t, resp.Details[i].Created)
+
+				inserts := env.tmc.executed(tablet, `^insert into _vt\.vreplication`)
+				require.Len(t, inserts, 1)
+				assert.Contains(t, inserts[0], tt.wantInserts[i])
+			}
+		})
+	}
+}
+
+// moveTablesSource is the source of the streams of the MoveTables workflow
+// of newMoveTablesTestEnv, which moves the customer and corder tables.
+var moveTablesSource = &binlogdatapb.BinlogSource{
+	Keyspace: "source",
+	Shard:    "0",
+	Filter: &binlogdatapb.Filter{
+		Rules: []*binlogdatapb.Rule{
+			{Match: "corder", Filter: "select * from corder"},
+			{Match: "customer", Filter: "select * from customer"},
+		},
+	},
+}
+
+// newMoveTablesWorkflowTestEnv returns a newMoveTablesTestEnv in which the
+// MoveTables workflow has been created and is running.
+func newMoveTablesWorkflowTestEnv(t *testing.T) *testEnv {
+	env := newMoveTablesTestEnv(t)
+	require.NoError(t, topotools.SaveRoutingRules(context.Background(), env.ts,
+		routingRules([]string{"corder", "customer"}, []string{"primary", "replica", "rdonly"}, "source")))
+	addMoveTablesStreams(t, env, "Running", "")
+	return env
+}
+
+// addMoveTablesStreams registers the streams of the MoveTables workflow of
+// newMoveTablesTestEnv, in the given state.
+func addMoveTablesStreams(t *testing.T, env *testEnv, state, message string) {
+	sources := map[int32]*binlogdatapb.BinlogSource{1: moveTablesSource}
+	for _, tablet := range env.targetTablets() {
+		env.tmc.addQuery(tablet, "select id, source, message, cell, tablet_types, workflow_type, workflow_sub_type, defer_secondary_keys from _vt.vreplication where workflow='wf1' and db_name='vt_target'",
+			streamsResult(t, binlogdatapb.VReplicationWorkflowType_MoveTables, message, sources))
+		env.tmc.addQueryRE(tablet, `^select id, workflow, source, pos, .* from _vt\.vreplication where workflow = 'wf1' and db_name = 'vt_target'$`,
+			workflowStreamsResult(t, "target", testWorkflow, binlogdatapb.VReplicationWorkflowType_MoveTables, state, message, sources))
+		env.tmc.addQueryRE(tablet, `^select table_name, lastpk from _vt\.copy_state where vrepl_id = 1 `, nil)
+		env.tmc.addQueryRE(tablet, `^select id, vrepl_id, type, state, message, created_at, updated_at, .* from _vt\.vreplication_log `, nil)
+		env.tmc.addQuery(tablet, "select id from _vt.vreplication where db_name = 'vt_target' and workflow = 'wf1'", idsResult(1))
+	}
+	for _, tablet := range env.sourceTablets() {
+		env.tmc.addQueryRE(tablet, `^select val from _vt\.resharding_journal where id=`, nil)
+		env.tmc.addQueryRE(tablet, `^(UN)?LOCK TABLES`, nil)
+	}
+}
+
+func TestWorkflowSwitchTrafficDryRun(t *testing.T) {
+	ctx := context.Background()
+	env := newMoveTablesWorkflowTestEnv(t)
+	rules, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+
+	resp, err := env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+		DryRun:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Reads Not Switched. Writes Not Switched", resp.StartState)
+	assert.Equal(t, []string{
+		"Lock keyspace source",
+		"Switch reads for tables [corder,customer] to keyspace target for tablet types [REPLICA,RDONLY]",
+		"Routing rules for tables [corder,customer] will be updated",
+		"Unlock keyspace source",
+		"Lock keyspace source",
+		"Lock keyspace target",
+		"Stop writes on keyspace source, tables [corder,customer]:",
+		"\tKeyspace source, Shard 0 at Position " + testPosition,
+		"Wait for VReplication on stopped streams to catchup for up to 30s",
+		"Create reverse replication workflow wf1_reverse",
+		"Create journal entries on source databases",
+		"Enable writes on keyspace target tables [corder,customer]",
+		"Switch routing from keyspace source to keyspace target",
+		"Routing rules for tables [corder,customer] will be updated",
+		"Switch writes completed, freeze and delete vreplication streams on:",
+		"\ttablet 200",
+		"\ttablet 210",
+		"Mark vreplication streams frozen on:",
+		"\tKeyspace target, Shard -80, Tablet 200, Workflow wf1, DbName vt_target",
+		"\tKeyspace target, Shard 80-, Tablet 210, Workflow wf1, DbName vt_target",
+		"Unlock keyspace target",
+		"Unlock keyspace source",
+	}, resp.DryRunResults)
+
+	// Nothing was changed.
+	got, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+	assert.Equal(t, rules, got)
+	for _, tablet := range env.tablets {
+		assert.Empty(t, env.tmc.executed(tablet, `^(insert|update|delete) `))
+	}
+}
+
+// addSwitchWritesQueries registers the results of the queries run to switch
+// the writes of the MoveTables workflow of newMoveTablesTestEnv.
+func addSwitchWritesQueries(env *testEnv) {
+	for _, tablet := range env.targetTablets() {
+		env.tmc.addQueryRE(tablet, `^update _vt\.vreplication set `, nil)
+	}
+	for _, tablet := range env.sourceTablets() {
+		env.tmc.addQueryRE(tablet, `^(insert|update|delete) `, nil)
+		env.tmc.addQueryRE(tablet, `^(optimize|alter) table _vt\.copy_state`, nil)
+	}
+}
+
+// switchTraffic switches all the traffic of the MoveTables workflow of
+// newMoveTablesTestEnv, reads first.
+func switchTraffic(t *testing.T, env *testEnv) {
+	ctx := context.Background()
+	addSwitchWritesQueries(env)
+	for _, tabletTypes := range [][]topodatapb.TabletType{
+		{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY},
+		{topodatapb.TabletType_PRIMARY},
+	} {
+		_, err := env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+			Keyspace:    "target",
+			Workflow:    testWorkflow,
+			TabletTypes: tabletTypes,
+		})
+		require.NoError(t, err)
+	}
+}
+
+// routingRules returns the routing rules of the MoveTables workflow of
+// newMoveTablesTestEnv which route the traffic of the given tablet types for
+// the given tables to the given keyspace.
+func routingRules(tables []string, tabletTypes []string, keyspace string) map[string][]string {
+	rules := make(map[string][]string)
+	for _, table := range tables {
+		to := []string{keyspace + "." + table}
+		for _, tabletType := range tabletTypes {
+			if tabletType == "primary" {
+				// The primary of the keyspace being routed to is reached
+				// without any rule.
+				rules[table] = to
+				if keyspace == "source" {
+					rules["target."+table] = to
+				} else {
+					rules["source."+table] = to
+				}
+				continue
+			}
+			rules[table+"@"+tabletType] = to
+			rules["source."+table+"@"+tabletType] = to
+			rules["target."+table+"@"+tabletType] = to
+		}
+	}
+	return rules
+}
+
+func TestWorkflowSwitchTraffic(t *testing.T) {
+	ctx := context.Background()
+	env := newMoveTablesWorkflowTestEnv(t)
+	tables := []string{"corder", "customer"}
+
+	// Reads first.
+	resp, err := env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace:    "target",
+		Workflow:    testWorkflow,
+		TabletTypes: []topodatapb.TabletType{topodatapb.TabletType_REPLICA, topodatapb.TabletType_RDONLY},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "SwitchTraffic was successful for workflow target.wf1", resp.Summary)
+	assert.Equal(t, "Reads Not Switched. Writes Not Switched", resp.StartState)
+	assert.Equal(t, "All Reads Switched. Writes Not Switched", resp.CurrentState)
+	rules, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+	want := routingRules(tables, []string{"replica", "rdonly"}, "target")
+	for table, to := range routingRules(tables, []string{"primary"}, "source") {
+		want[table] = to
+	}
+	assert.Equal(t, want, rules)
+
+	// Then writes.
+	addSwitchWritesQueries(env)
+	resp, err = env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace:    "target",
+		Workflow:    testWorkflow,
+		TabletTypes: []topodatapb.TabletType{topodatapb.TabletType_PRIMARY},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "All Reads Switched. Writes Not Switched", resp.StartState)
+	assert.Equal(t, "All Reads Switched. Writes Switched", resp.CurrentState)
+	rules, err = topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+	assert.Equal(t, routingRules(tables, []string{"primary", "replica", "rdonly"}, "target"), rules)
+
+	// The source tables no longer take writes, and the source journals the
+	// switch and replicates back from the target.
+	si, err := env.ts.GetShard(ctx, "source", "0")
+	require.NoError(t, err)
+	require.NotNil(t, si.GetTabletControl(topodatapb.TabletType_PRIMARY))
+	assert.Equal(t, tables, si.GetTabletControl(topodatapb.TabletType_PRIMARY).DeniedTables)
+	source := env.sourceTablets()[0]
+	assert.Len(t, env.tmc.executed(source, `^insert into _vt\.resharding_journal .*tables:\\"corder\\" tables:\\"customer\\"`), 1)
+	for _, shard := range []string{"-80", "80-"} {
+		assert.Len(t, env.tmc.executed(source, fmt.Sprintf(`^insert into _vt\.vreplication .*'wf1_reverse', 'keyspace:\\"target\\" shard:\\"%s\\"`, shard)), 1)
+	}
+	for _, tablet := range env.targetTablets() {
+		assert.Equal(t, []string{"update _vt.vreplication set state='Stopped', message='stopped for cutover' where id=1"},
+			env.tmc.executed(tablet, `stopped for cutover`))
+		assert.Equal(t, []string{"update _vt.vreplication set message = 'FROZEN' where db_name='vt_target' and workflow='wf1'"},
+			env.tmc.executed(tablet, `FROZEN' where`))
+	}
+}
+
+func TestWorkflowSwitchTrafficCancelled(t *testing.T) {
+	ctx := context.Background()
+	env := newMoveTablesWorkflowTestEnv(t)
+	addSwitchWritesQueries(env)
+	for _, tablet := range env.targetTablets() {
+		env.tmc.addQueryError(tablet, `stopped for cutover`, fmt.Errorf("cutover failed"))
+	}
+	rules, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+
+	// The migration is cancelled if the target streams can't catch up,
+	// which leaves the workflow as it was.
+	_, err = env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace:    "target",
+		Workflow:    testWorkflow,
+		TabletTypes: []topodatapb.TabletType{topodatapb.TabletType_PRIMARY},
+	})
+	require.ErrorContains(t, err, "cutover failed")
+
+	got, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+	assert.Equal(t, rules, got)
+	si, err := env.ts.GetShard(ctx, "source", "0")
+	require.NoError(t, err)
+	assert.Nil(t, si.GetTabletControl(topodatapb.TabletType_PRIMARY))
+	for _, tablet := range env.targetTablets() {
+		assert.Equal(t, []string{"update _vt.vreplication set state='Running', message='' where db_name='vt_target' and workflow='wf1'"},
+			env.tmc.executed(tablet, `set state='Running'`))
+	}
+	source := env.sourceTablets()[0]
+	assert.Empty(t, env.tmc.executed(source, `^insert into _vt\.resharding_journal`))
+	assert.NotEmpty(t, env.tmc.executed(source, `^delete from _vt\.vreplication where db_name = 'vt_source' and workflow = 'wf1_reverse'`))
+}
+
+func TestWorkflowSwitchTrafficNotReady(t *testing.T) {
+	tests := []struct {
+		name      string
+		state     string
+		message   string
+		copyState bool
+		wantErr   string
+	}{
+		{
+			name:      "copying",
+			state:     "Running",
+			copyState: true,
+			wantErr:   "cannot switch traffic for workflow wf1 at this time: copy is still in progress",
+		},
+		{
+			name:    "error",
+			state:   "Running",
+			message: "Error: duplicate key",
+			wantErr: "cannot switch traffic for workflow wf1 at this time: workflow has errors",
+		},
+		{
+			name:    "frozen",
+			state:   "Stopped",
+			message: Frozen,
+			wantErr: "cannot switch traffic for workflow wf1 at this time: workflow is frozen",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			env := newMoveTablesWorkflowTestEnv(t)
+			addMoveTablesStreams(t, env, tt.state, tt.message)
+			if tt.copyState {
+				for _, tablet := range env.targetTablets() {
+					env.tmc.addQueryRE(tablet, `^select table_name, lastpk from _vt\.copy_state where vrepl_id = 1 `,
+						sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name|lastpk", "varchar|varbinary"), "customer|")))
+				}
+			}
+
+			_, err := env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+				Keyspace: "target",
+				Workflow: testWorkflow,
+			})
+			require.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+// addReverseStreams registers the streams of the reverse workflow which
+// WorkflowSwitchTraffic creates on the source of the MoveTables workflow of
+// newMoveTablesTestEnv once its writes are switched, and freezes the streams
+// of the workflow.
+func addReverseStreams(t *testing.T, env *testEnv) {
+	addMoveTablesStreams(t, env, "Stopped", Frozen)
+	sources := make(map[int32]*binlogdatapb.BinlogSource)
+	for i, tablet := range env.targetTablets() {
+		sources[int32(i+1)] = &binlogdatapb.BinlogSource{
+			Keyspace: "target",
+			Shard:    tablet.Shard,
+			Filter: &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{
+					{Match: "corder", Filter: "select * from `corder`"},
+					{Match: "customer", Filter: "select * from `customer`"},
+				},
+			},
+		}
+	}
+	for _, tablet := range env.sourceTablets() {
+		env.tmc.addQuery(tablet, "select id, source, message, cell, tablet_types, workflow_type, workflow_sub_type, defer_secondary_keys from _vt.vreplication where workflow='wf1_reverse' and db_name='vt_source'",
+			streamsResult(t, binlogdatapb.VReplicationWorkflowType_MoveTables, "", sources))
+		env.tmc.addQueryRE(tablet, `^select id, workflow, source, pos, .* from _vt\.vreplication where workflow = 'wf1_reverse' and db_name = 'vt_source'$`,
+			workflowStreamsResult(t, "source", "wf1_reverse", binlogdatapb.VReplicationWorkflowType_MoveTables, "Running", "", sources))
+		env.tmc.addQueryRE(tablet, `^select table_name, lastpk from _vt\.copy_state where vrepl_id = `, nil)
+		env.tmc.addQueryRE(tablet, `^select id, vrepl_id, type, state, message, created_at, updated_at, .* from _vt\.vreplication_log `, nil)
+		env.tmc.addQuery(tablet, "select id from _vt.vreplication where db_name = 'vt_source' and workflow = 'wf1_reverse'", idsResult(1, 2))
+	}
+}
+
+func TestWorkflowReverseTraffic(t *testing.T) {
+	ctx := context.Background()
+	env := newMoveTablesWorkflowTestEnv(t)
+	tables := []string{"corder", "customer"}
+
+	// There is nothing to reverse yet.
+	_, err := env.ws.WorkflowReverseTraffic(ctx, &vtctldatapb.WorkflowReverseTrafficRequest{
+		Keyspace:    "target",
+		Workflow:    testWorkflow,
+		TabletTypes: []topodatapb.TabletType{topodatapb.TabletType_REPLICA},
+	})
+	require.EqualError(t, err, "requesting reversal of read traffic for REPLICAs but REPLICA reads have not been switched")
+
+	switchTraffic(t, env)
+	addReverseStreams(t, env)
+	for _, tablet := range env.targetTablets() {
+		env.tmc.addQueryRE(tablet, `^(insert|update|delete) `, nil)
+		env.tmc.addQueryRE(tablet, `^(optimize|alter) table _vt\.copy_state`, nil)
+		env.tmc.addQueryRE(tablet, `^select val from _vt\.resharding_journal where id=`, nil)
+		env.tmc.addQueryRE(tablet, `^(UN)?LOCK TABLES`, nil)
+	}
+	for _, tablet := range env.sourceTablets() {
+		env.tmc.addQueryRE(tablet, `^update _vt\.vreplication set `, nil)
+	}
+
+	resp, err := env.ws.WorkflowReverseTraffic(ctx, &vtctldatapb.WorkflowReverseTrafficRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ReverseTraffic was successful for workflow target.wf1", resp.Summary)
+	assert.Equal(t, "All Reads Switched. Writes Switched", resp.StartState)
+	rules, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+	assert.Equal(t, routingRules(tables, []string{"primary", "replica", "rdonly"}, "source"), rules)
+
+	// The writes are switched back using the reverse workflow: the target
+	// tables no longer take writes, and the target journals the switch and
+	// replicates from the source again.
+	si, err := env.ts.GetShard(ctx, "source", "0")
+	require.NoError(t, err)
+	assert.Nil(t, si.GetTabletControl(topodatapb.TabletType_PRIMARY))
+	source := env.sourceTablets()[0]
+	assert.Len(t, env.tmc.executed(source, `stopped for cutover`), 2)
+	assert.Equal(t, []string{"update _vt.vreplication set message = 'FROZEN' where db_name='vt_source' and workflow='wf1_reverse'"},
+		env.tmc.executed(source, `FROZEN' where`))
+	for _, tablet := range env.targetTablets() {
+		si, err := env.ts.GetShard(ctx, "target", tablet.Shard)
+		require.NoError(t, err)
+		require.NotNil(t, si.GetTabletControl(topodatapb.TabletType_PRIMARY))
+		assert.Equal(t, tables, si.GetTabletControl(topodatapb.TabletType_PRIMARY).DeniedTables)
+		assert.Len(t, env.tmc.executed(tablet, `^insert into _vt\.resharding_journal .*participants:\{keyspace:\\"target\\" shard:\\"-80\\"\} participants:\{keyspace:\\"target\\" shard:\\"80-\\"\}`), 1)
+		assert.Len(t, env.tmc.executed(tablet, fmt.Sprintf(`^insert into _vt\.vreplication .*'wf1', 'keyspace:\\"source\\" shard:\\"0\\" .*in_keyrange\(customer_id, \\'target\.hash\\', \\'%s\\'\)`, tablet.Shard)), 1)
+	}
+}
+
+func TestWorkflowComplete(t *testing.T) {
+	ctx := context.Background()
+	env := newMoveTablesWorkflowTestEnv(t)
+
+	_, err := env.ws.WorkflowComplete(ctx, &vtctldatapb.WorkflowCompleteRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+	})
+	require.ErrorIs(t, err, ErrWorkflowNotFullySwitched)
+
+	switchTraffic(t, env)
+	addReverseStreams(t, env)
+	for _, tablet := range env.targetTablets() {
+		env.tmc.addQuery(tablet, "select 1 from _vt.vreplication where db_name='vt_target' and workflow='wf1' and message!='FROZEN'", nil)
+		env.tmc.addQueryRE(tablet, `^delete from _vt\.`, nil)
+	}
+	for _, tablet := range env.sourceTablets() {
+		env.tmc.addQueryRE(tablet, `^drop table `, nil)
+		env.tmc.addQueryRE(tablet, `^delete from _vt\.`, nil)
+	}
+
+	resp, err := env.ws.WorkflowComplete(ctx, &vtctldatapb.WorkflowCompleteRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+		DryRun:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Lock keyspace source",
+		"Lock keyspace target",
+		"Dropping these tables from the database and removing them from the vschema for keyspace source:",
+		"	Keyspace source Shard 0 DbName vt_source Tablet 100 Table corder",
+		"	Keyspace source Shard 0 DbName vt_source Tablet 100 Table customer",
+		"Denied tables [corder,customer] will be removed from:",
+		"	Keyspace source Shard 0 Tablet 100",
+		"Delete reverse vreplication streams on source:",
+		"	Keyspace source Shard 0 Workflow wf1_reverse DbName vt_source Tablet 100",
+		"Delete vreplication streams on target:",
+		"	Keyspace target Shard -80 Workflow wf1 DbName vt_target Tablet 200",
+		"	Keyspace target Shard 80- Workflow wf1 DbName vt_target Tablet 210",
+		"Routing rules for participating tables will be deleted",
+		"Unlock keyspace target",
+		"Unlock keyspace source",
+	}, resp.DryRunResults)
+	source := env.sourceTablets()[0]
+	assert.Empty(t, env.tmc.executed(source, `^drop table `))
+	assert.Len(t, env.tmc.executed(source, `^delete from _vt\.vreplication `), 1)
+
+	resp, err = env.ws.WorkflowComplete(ctx, &vtctldatapb.WorkflowCompleteRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully completed the wf1 workflow in the target keyspace", resp.Summary)
+
+	// The source tables are dropped, and the workflow, its reverse workflow
+	// and its routing rules are deleted.
+	assert.Equal(t, []string{"drop table `vt_source`.`corder`", "drop table `vt_source`.`customer`"},
+		env.tmc.executed(source, `^drop table `))
+	assert.Contains(t, env.tmc.executed(source, `^delete from _vt\.vreplication `),
+		"delete from _vt.vreplication where db_name = 'vt_source' and workflow = 'wf1_reverse'")
+	for _, tablet := range env.targetTablets() {
+		assert.Equal(t, []string{"delete from _vt.vreplication where db_name = 'vt_target' and workflow = 'wf1'"},
+			env.tmc.executed(tablet, `^delete from _vt\.vreplication `))
+	}
+	si, err := env.ts.GetShard(ctx, "source", "0")
+	require.NoError(t, err)
+	assert.Nil(t, si.GetTabletControl(topodatapb.TabletType_PRIMARY))
+	rules, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestWorkflowCancel(t *testing.T) {
+	ctx := context.Background()
+	env := newMoveTablesWorkflowTestEnv(t)
+	for _, tablet := range env.targetTablets() {
+		env.tmc.addQueryRE(tablet, `^drop table `, nil)
+		env.tmc.addQueryRE(tablet, `^delete from _vt\.`, nil)
+	}
+	for _, tablet := range env.sourceTablets() {
+		env.tmc.addQuery(tablet, "select id from _vt.vreplication where db_name = 'vt_source' and workflow = 'wf1_reverse'", nil)
+		env.tmc.addQueryRE(tablet, `^delete from _vt\.`, nil)
+	}
+
+	resp, err := env.ws.WorkflowCancel(ctx, &vtctldatapb.WorkflowCancelRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+		DryRun:   true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"Lock keyspace source",
+		"Lock keyspace target",
+		"Dropping these tables from the database and removing from the vschema for keyspace target:",
+		"	Keyspace target Shard -80 DbName vt_target Tablet 200 Table corder",
+		"	Keyspace target Shard -80 DbName vt_target Tablet 200 Table customer",
+		"	Keyspace target Shard 80- DbName vt_target Tablet 210 Table corder",
+		"	Keyspace target Shard 80- DbName vt_target Tablet 210 Table customer",
+		"Denied tables [corder,customer] will be removed from:",
+		"	Keyspace source Shard 0 Tablet 100",
+		"Delete reverse vreplication streams on source:",
+		"	Keyspace source Shard 0 Workflow wf1_reverse DbName vt_source Tablet 100",
+		"Delete vreplication streams on target:",
+		"	Keyspace target Shard -80 Workflow wf1 DbName vt_target Tablet 200",
+		"	Keyspace target Shard 80- Workflow wf1 DbName vt_target Tablet 210",
+		"Routing rules for participating tables will be deleted",
+		"Unlock keyspace target",
+		"Unlock keyspace source",
+	}, resp.DryRunResults)
+	for _, tablet := range env.targetTablets() {
+		assert.Empty(t, env.tmc.executed(tablet, `^(drop|delete) `))
+	}
+
+	resp, err = env.ws.WorkflowCancel(ctx, &vtctldatapb.WorkflowCancelRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Successfully cancelled the wf1 workflow in the target keyspace", resp.Summary)
+
+	// The copied tables are dropped from the target, and the workflow and its
+	// routing rules are deleted. The vschema of a sharded target is managed by
+	// the user, so its table entries are kept.
+	for _, tablet := range env.targetTablets() {
+		assert.Equal(t, []string{"drop table `vt_target`.`corder`", "drop table `vt_target`.`customer`"},
+			env.tmc.executed(tablet, `^drop table `))
+		assert.Equal(t, []string{"delete from _vt.vreplication where db_name = 'vt_target' and workflow = 'wf1'"},
+			env.tmc.executed(tablet, `^delete from _vt\.vreplication `))
+	}
+	vs, err := env.ts.GetVSchema(ctx, "target")
+	require.NoError(t, err)
+	assert.Len(t, vs.Tables, 2)
+	rules, err := topotools.GetRoutingRules(ctx, env.ts)
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+}
+
+func TestWorkflowCancelSwitched(t *testing.T) {
+	ctx := context.Background()
+	env := newMoveTablesWorkflowTestEnv(t)
+	addSwitchWritesQueries(env)
+
+	_, err := env.ws.WorkflowSwitchTraffic(ctx, &vtctldatapb.WorkflowSwitchTrafficRequest{
+		Keyspace:    "target",
+		Workflow:    testWorkflow,
+		TabletTypes: []topodatapb.TabletType{topodatapb.TabletType_RDONLY},
+	})
+	require.NoError(t, err)
+
+	_, err = env.ws.WorkflowCancel(ctx, &vtctldatapb.WorkflowCancelRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+	})
+	require.ErrorIs(t, err, ErrWorkflowPartiallySwitched)
+	_, err = env.ws.WorkflowComplete(ctx, &vtctldatapb.WorkflowCompleteRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+	})
+	require.ErrorIs(t, err, ErrWorkflowNotFullySwitched)
+}
+
+func TestWorkflowStatus(t *testing.T) {
+	ctx := context.Background()
+	env := newMoveTablesWorkflowTestEnv(t)
+	metricsFields := sqltypes.MakeTestFields("table_name|table_rows|data_length", "varchar|int64|int64")
+	for _, tablet := range env.targetTablets() {
+		env.tmc.addQuery(tablet, "select distinct table_name from _vt.copy_state cs, _vt.vreplication vr where vr.id = cs.vrepl_id and vr.id = 1",
+			sqltypes.ResultToProto3(sqltypes.MakeTestResult(sqltypes.MakeTestFields("table_name", "varchar"), "customer")))
+		env.tmc.addQuery(tablet, "select table_name, table_rows, data_length from information_schema.tables where table_schema = 'vt_target' and table_name in ('customer')",
+			sqltypes.ResultToProto3(sqltypes.MakeTestResult(metricsFields, "customer|10|1000")))
+	}
+	for _, tablet := range env.sourceTablets() {
+		env.tmc.addQuery(tablet, "select table_name, table_rows, data_length from information_schema.tables where table_schema = 'vt_source' and table_name in ('customer')",
+			sqltypes.ResultToProto3(sqltypes.MakeTestResult(metricsFields, "customer|40|4000")))
+	}
+
+	resp, err := env.ws.WorkflowStatus(ctx, &vtctldatapb.WorkflowStatusRequest{
+		Keyspace: "target",
+		Workflow: testWorkflow,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Reads Not Switched. Writes Not Switched", resp.TrafficState)
+	utils.MustMatch(t, map[string]*vtctldatapb.WorkflowStatusResponse_TableCopyState{
+		"customer": {
+			RowsCopied:      20,
+			RowsTotal:       40,
+			RowsPercentage:  50,
+			BytesCopied:     2000,
+			BytesTotal:      4000,
+			BytesPercentage: 50,
+		},
+	}, resp.TableCopyState)
+	require.Len(t, resp.ShardStreams, 2)
+	for _, tablet := range env.targetTablets() {
+		streams := resp.ShardStreams["target/"+tablet.Shard]
+		require.NotNil(t, streams)
+		require.Len(t, streams.Streams, 1)
+		stream := streams.Streams[0]
+		assert.Equal(t, int32(1), stream.Id)
+		utils.MustMatch(t, tablet.Alias, stream.Tablet)
+		assert.Equal(t, "source/0", stream.SourceShard)
+		assert.Equal(t, testPosition, stream.Position)
+		assert.Equal(t, "Running", stream.Status)
+	}
+
+	_, err = env.ws.WorkflowStatus(ctx, &vtctldatapb.WorkflowStatusRequest{
+		Keyspace: "target",
+		Workflow: "nonexistent",
+	})
+	require.Error(t, err)
+}
@@ -0,0 +1,569 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vdiff"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+const (
+	// defaultVDiffTabletTypes is the source tablet type preference used when
+	// a VDiff is created without any tablet types.
+	defaultVDiffTabletTypes = "in_order:RDONLY,REPLICA,PRIMARY"
+	// defaultVDiffMaxExtraRowsToCompare matches the default of the legacy
+	// vtctl VDiff command.
+	defaultVDiffMaxExtraRowsToCompare = 1000
+)
+
+// VDiffCreate is part of the vtctlservicepb.VtctldServer interface. It
+// schedules a VDiff on every target shard of the workflow and returns the
+// UUID of the new VDiff.
+func (s *Server) VDiffCreate(ctx context.Context, req *vtctldatapb.VDiffCreateRequest) (*vtctldatapb.VDiffCreateResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.VDiffCreate")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("uuid", req.Uuid)
+	span.Annotate("source_cells", req.SourceCells)
+	span.Annotate("target_cells", req.TargetCells)
+	span.Annotate("tablet_types", req.TabletTypes)
+	span.Annotate("tables", req.Tables)
+	span.Annotate("auto_retry", req.AutoRetry)
+
+	var (
+		vdiffUUID uuid.UUID
+		err       error
+	)
+	if req.Uuid != "" {
+		vdiffUUID, err = uuid.Parse(req.Uuid)
+	} else {
+		vdiffUUID, err = uuid.NewUUID()
+	}
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%v, please provide a valid UUID", err)
+	}
+
+	limit := req.Limit
+	switch {
+	case limit == 0:
+		limit = math.MaxInt64
+	case limit < 0:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid limit (%d), maximum number of rows to compare needs to be greater than 0", limit)
+	}
+
+	maxExtraRowsToCompare := req.MaxExtraRowsToCompare
+	if maxExtraRowsToCompare == 0 {
+		maxExtraRowsToCompare = defaultVDiffMaxExtraRowsToCompare
+	}
+
+	tabletTypes := defaultVDiffTabletTypes
+	if len(req.TabletTypes) > 0 {
+		types := make([]string, 0, len(req.TabletTypes))
+		for _, tt := range req.TabletTypes {
+			types = append(types, tt.String())
+		}
+		tabletTypes = strings.Join(types, ",")
+	}
+
+	var timeoutSeconds int64
+	if req.FilteredReplicationWaitTime != nil {
+		timeout, ok, err := protoutil.DurationFromProto(req.FilteredReplicationWaitTime)
+		if err != nil {
+			return nil, vterrors.Wrapf(err, "unable to parse FilteredReplicationWaitTime")
+		}
+		if ok {
+			timeoutSeconds = int64(timeout.Seconds())
+		}
+	}
+	if timeoutSeconds == 0 {
+		timeoutSeconds = int64(defaultSwitchTrafficTimeout.Seconds())
+	}
+
+	options := &tabletmanagerdatapb.VDiffOptions{
+		PickerOptions: &tabletmanagerdatapb.VDiffPickerOptions{
+			TabletTypes: tabletTypes,
+			SourceCell:  strings.Join(req.SourceCells, ","),
+			TargetCell:  strings.Join(req.TargetCells, ","),
+		},
+		CoreOptions: &tabletmanagerdatapb.VDiffCoreOptions{
+			Tables:                strings.Join(req.Tables, ","),
+			AutoRetry:             req.AutoRetry,
+			MaxRows:               limit,
+			SamplePct:             100,
+			TimeoutSeconds:        timeoutSeconds,
+			MaxExtraRowsToCompare: maxExtraRowsToCompare,
+			UpdateTableStats:      req.UpdateTableStats,
+		},
+		ReportOptions: &tabletmanagerdatapb.VDiffReportOptions{
+			OnlyPks:    req.OnlyPKs,
+			DebugQuery: req.DebugQuery,
+			Format:     "json",
+		},
+	}
+
+	if _, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.CreateAction, "", vdiffUUID.String(), options); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.VDiffCreateResponse{
+		Uuid: vdiffUUID.String(),
+	}, nil
+}
+
+// VDiffDelete is part of the vtctlservicepb.VtctldServer interface.
+func (s *Server) VDiffDelete(ctx context.Context, req *vtctldatapb.VDiffDeleteRequest) (*vtctldatapb.VDiffDeleteResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.VDiffDelete")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("argument", req.Arg)
+
+	arg := strings.ToLower(req.Arg)
+	var vdiffUUID string
+	if arg != vdiff.AllActionArg {
+		id, err := uuid.Parse(arg)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "can only delete a specific vdiff, please provide a valid UUID or %s", vdiff.AllActionArg)
+		}
+		vdiffUUID = id.String()
+	}
+
+	if _, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.DeleteAction, arg, vdiffUUID, nil); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.VDiffDeleteResponse{}, nil
+}
+
+// VDiffResume is part of the vtctlservicepb.VtctldServer interface.
+func (s *Server) VDiffResume(ctx context.Context, req *vtctldatapb.VDiffResumeRequest) (*vtctldatapb.VDiffResumeResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.VDiffResume")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("uuid", req.Uuid)
+
+	vdiffUUID, err := uuid.Parse(req.Uuid)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "can only resume a specific vdiff, please provide a valid UUID")
+	}
+
+	if _, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.ResumeAction, vdiffUUID.String(), vdiffUUID.String(), nil); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.VDiffResumeResponse{}, nil
+}
+
+// VDiffShow is part of the vtctlservicepb.VtctldServer interface. With a UUID
+// or "last" it returns the summary of a single VDiff aggregated across all
+// target shards, and with "all" it lists every VDiff on the target shards.
+func (s *Server) VDiffShow(ctx context.Context, req *vtctldatapb.VDiffShowRequest) (*vtctldatapb.VDiffShowResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.VDiffShow")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("argument", req.Arg)
+
+	arg := strings.ToLower(req.Arg)
+	var vdiffUUID string
+	switch arg {
+	case vdiff.AllActionArg, vdiff.LastActionArg:
+	default:
+		id, err := uuid.Parse(arg)
+		if err != nil {
+			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "can only show a specific vdiff, please provide a valid UUID, %s or %s", vdiff.LastActionArg, vdiff.AllActionArg)
+		}
+		vdiffUUID = id.String()
+	}
+
+	responses, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.ShowAction, arg, vdiffUUID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if arg == vdiff.AllActionArg {
+		return &vtctldatapb.VDiffShowResponse{
+			Listings: buildVDiffListings(req.TargetKeyspace, req.Workflow, responses),
+		}, nil
+	}
+
+	if vdiffUUID == "" {
+		// For "last" each shard reports the UUID of its most recent VDiff.
+		for _, resp := range responses {
+			if resp != nil && resp.VdiffUuid != "" {
+				vdiffUUID = resp.VdiffUuid
+				break
+			}
+		}
+	}
+
+	summary, err := buildVDiffSummary(req.TargetKeyspace, req.Workflow, vdiffUUID, responses, req.Verbose, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.VDiffShowResponse{
+		Summary: summary,
+	}, nil
+}
+
+// VDiffStop is part of the vtctlservicepb.VtctldServer interface.
+func (s *Server) VDiffStop(ctx context.Context, req *vtctldatapb.VDiffStopRequest) (*vtctldatapb.VDiffStopResponse, error) {
+	span, ctx := trace.NewSpan(ctx, "workflow.Server.VDiffStop")
+	defer span.Finish()
+
+	span.Annotate("keyspace", req.TargetKeyspace)
+	span.Annotate("workflow", req.Workflow)
+	span.Annotate("uuid", req.Uuid)
+
+	vdiffUUID, err := uuid.Parse(req.Uuid)
+	if err != nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "can only stop a specific vdiff, please provide a valid UUID")
+	}
+
+	if _, err := s.vdiff(ctx, req.TargetKeyspace, req.Workflow, vdiff.StopAction, vdiffUUID.String(), vdiffUUID.String(), nil); err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.VDiffStopResponse{}, nil
+}
+
+// vdiff runs the given VDiff action on the primary tablet of every target
+// shard of the workflow and returns the responses keyed by shard name.
+func (s *Server) vdiff(ctx context.Context, keyspace, workflow string, action vdiff.VDiffAction, actionArg, vdiffUUID string, options *tabletmanagerdatapb.VDiffOptions) (map[string]*tabletmanagerdatapb.VDiffResponse, error) {
+	ts, err := s.buildTrafficSwitcher(ctx, keyspace, workflow)
+	if err != nil {
+		return nil, err
+	}
+	if action == vdiff.CreateAction && ts.frozen {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "invalid VDiff run: writes have been already been switched for workflow %s.%s",
+			keyspace, workflow)
+	}
+
+	req := &tabletmanagerdatapb.VDiffRequest{
+		Keyspace:  keyspace,
+		Workflow:  workflow,
+		Action:    string(action),
+		ActionArg: actionArg,
+		VdiffUuid: vdiffUUID,
+		Options:   options,
+	}
+
+	var (
+		m         sync.Mutex
+		responses = make(map[string]*tabletmanagerdatapb.VDiffResponse, len(ts.targets))
+	)
+	err = ts.ForAllTargets(func(target *MigrationTarget) error {
+		resp, err := s.tmc.VDiff(ctx, target.GetPrimary().Tablet, req)
+
+		m.Lock()
+		defer m.Unlock()
+		responses[target.GetShard().ShardName()] = resp
+
+		return err
+	})
+	if err != nil {
+		log.Errorf("Error executing VDiff action %s on %s.%s: %v", action, keyspace, workflow, err)
+		return nil, err
+	}
+
+	return responses, nil
+}
+
+// buildVDiffListings converts the rows of the _vt.vdiff table returned by each
+// target shard into listings for the given workflow.
+func buildVDiffListings(keyspace, workflow string, responses map[string]*tabletmanagerdatapb.VDiffResponse) []*vtctldatapb.VDiffListing {
+	var listings []*vtctldatapb.VDiffListing
+	for _, resp := range responses {
+		if resp == nil || resp.Output == nil {
+			continue
+		}
+
+		qr := sqltypes.Proto3ToResult(resp.Output)
+		for _, row := range qr.Named().Rows {
+			listing := &vtctldatapb.VDiffListing{
+				Uuid:     row.AsString("vdiff_uuid", ""),
+				Workflow: row.AsString("workflow", ""),
+				Keyspace: row.AsString("keyspace", ""),
+				Shard:    row.AsString("shard", ""),
+				State:    row.AsString("state", ""),
+			}
+			if listing.Keyspace != keyspace || listing.Workflow != workflow {
+				continue
+			}
+
+			listings = append(listings, listing)
+		}
+	}
+
+	sort.Slice(listings, func(i, j int) bool {
+		if listings[i].Uuid != listings[j].Uuid {
+			return listings[i].Uuid < listings[j].Uuid
+		}
+		return listings[i].Shard < listings[j].Shard
+	})
+
+	return listings
+}
+
+// buildVDiffSummary aggregates the per-shard, per-table state of a single
+// VDiff into a summary. The shard reports are only included when a mismatch
+// was found or verbose is set.
+func buildVDiffSummary(keyspace, workflow, vdiffUUID string, responses map[string]*tabletmanagerdatapb.VDiffResponse, verbose bool, now time.Time) (*vtctldatapb.VDiffSummary, error) {
+	summary := &vtctldatapb.VDiffSummary{
+		Workflow:       workflow,
+		Keyspace:       keyspace,
+		Uuid:           vdiffUUID,
+		TableSummaries: map[string]*vtctldatapb.VDiffTableSummary{},
+		Errors:         map[string]string{},
+	}
+
+	var (
+		// Our timestamps are strings in vdiff.TimestampFormat, so they sort
+		// lexicographically.
+		startedAt, completedAt string
+		// Tallies of the table states across all shards, and of the VDiff
+		// states of each shard, used to determine the overall state.
+		tableStateCounts = map[vdiff.VDiffState]int{}
+		shardStateCounts = map[vdiff.VDiffState]int{}
+		// The approximate number of rows to compare, used for the progress.
+		totalRowsToCompare int64
+	)
+
+	for shard, resp := range responses {
+		if resp == nil || resp.Output == nil {
+			continue
+		}
+
+		summary.Shards = append(summary.Shards, shard)
+		qr := sqltypes.Proto3ToResult(resp.Output)
+		for i, row := range qr.Named().Rows {
+			// The VDiff level values are the same for every row of a shard.
+			if i == 0 {
+				// We use the earliest started_at and the latest completed_at
+				// across all shards.
+				if sa := row.AsString("started_at", ""); startedAt == "" || sa < startedAt {
+					startedAt = sa
+				}
+				if ca := row.AsString("completed_at", ""); completedAt == "" || ca > completedAt {
+					completedAt = ca
+				}
+				if le := row.AsString("last_error", ""); le != "" {
+					summary.Errors[shard] = le
+				}
+
+				shardStateCounts[vdiff.VDiffState(strings.ToLower(row.AsString("vdiff_state", "")))]++
+			}
+
+			summary.RowsCompared += row.AsInt64("rows_compared", 0)
+			totalRowsToCompare += row.AsInt64("table_rows", 0)
+			if mm, _ := row.ToBool("has_mismatch"); mm {
+				summary.HasMismatch = true
+			}
+
+			table := row.AsString("table_name", "")
+			ts, ok := summary.TableSummaries[table]
+			if !ok {
+				ts = &vtctldatapb.VDiffTableSummary{
+					TableName:    table,
+					State:        string(vdiff.UnknownState),
+					ShardReports: map[string]*vtctldatapb.VDiffTableReport{},
+				}
+				summary.TableSummaries[table] = ts
+			}
+
+			sts := vdiff.VDiffState(strings.ToLower(row.AsString("table_state", "")))
+			tableStateCounts[sts]++
+
+			// The error state is sticky, and completed must not override any
+			// other known state.
+			switch sts {
+			case vdiff.CompletedState:
+				if ts.State == string(vdiff.UnknownState) {
+					ts.State = string(sts)
+				}
+			case vdiff.ErrorState:
+				ts.State = string(sts)
+			default:
+				if ts.State != string(vdiff.ErrorState) {
+					ts.State = string(sts)
+				}
+			}
+
+			report := &vtctldatapb.VDiffTableReport{
+				State: string(sts),
+			}
+			if r := row.AsString("report", ""); r != "" {
+				dr := vdiff.DiffReport{}
+				if err := json.Unmarshal([]byte(r), &dr); err != nil {
+					return nil, vterrors.Wrapf(err, "failed to unmarshal the %s report for shard %s", table, shard)
+				}
+
+				report = vdiffTableReportFromDiffReport(string(sts), &dr)
+				ts.RowsCompared += dr.ProcessedRows
+				ts.MatchingRows += dr.MatchingRows
+				ts.MismatchedRows += dr.MismatchedRows
+				ts.ExtraRowsSource += dr.ExtraRowsSource
+				ts.ExtraRowsTarget += dr.ExtraRowsTarget
+			}
+			ts.ShardReports[shard] = report
+		}
+	}
+
+	sort.Strings(summary.Shards)
+
+	// The overall state progresses from pending to started to completed, with
+	// stopped on any shard and error on any table being sticky. The VDiff is
+	// only complete once it is complete for every table on every shard. When
+	// merging shards, N sources write to the same _vt.vdiff_table record of a
+	// target shard, so we also require the shard level state to be completed.
+	var state vdiff.VDiffState
+	switch {
+	case shardStateCounts[vdiff.StoppedState] > 0:
+		state = vdiff.StoppedState
+	case shardStateCounts[vdiff.ErrorState] > 0 || tableStateCounts[vdiff.ErrorState] > 0:
+		state = vdiff.ErrorState
+	case tableStateCounts[vdiff.StartedState] > 0:
+		state = vdiff.StartedState
+	case tableStateCounts[vdiff.PendingState] > 0:
+		state = vdiff.PendingState
+	case len(summary.TableSummaries) > 0 && tableStateCounts[vdiff.CompletedState] == len(summary.TableSummaries)*len(summary.Shards):
+		if shardStateCounts[vdiff.CompletedState] == len(summary.Shards) {
+			state = vdiff.CompletedState
+		} else {
+			state = vdiff.StartedState
+		}
+	default:
+		state = vdiff.UnknownState
+	}
+	summary.State = string(state)
+
+	if t, err := time.Parse(vdiff.TimestampFormat, startedAt); err == nil {
+		summary.StartedAt = protoutil.TimeToProto(t)
+	}
+	// A VDiff that is not complete has no completion time.
+	if state == vdiff.CompletedState {
+		if t, err := time.Parse(vdiff.TimestampFormat, completedAt); err == nil {
+			summary.CompletedAt = protoutil.TimeToProto(t)
+		}
+	}
+
+	if state == vdiff.StartedState {
+		summary.Progress = buildVDiffProgress(summary.RowsCompared, totalRowsToCompare, startedAt, now)
+	}
+
+	if !summary.HasMismatch && !verbose {
+		for _, ts := range summary.TableSummaries {
+			ts.ShardReports = nil
+		}
+	}
+
+	return summary, nil
+}
+
+// buildVDiffProgress estimates the completion percentage and time of a
+// running VDiff, which started at startedAt, from the number of rows compared
+// so far.
+func buildVDiffProgress(rowsCompared, rowsToCompare int64, startedAt string, now time.Time) *vtctldatapb.VDiffProgress {
+	progress := &vtctldatapb.VDiffProgress{}
+	if rowsCompared >= 1 && rowsToCompare > 0 {
+		// Round to 2 decimal points.
+		progress.Percentage = math.Round(math.Min((float64(rowsCompared)/float64(rowsToCompare))*100, 100.00)*100) / 100
+	}
+	if math.IsNaN(progress.Percentage) {
+		progress.Percentage = 0
+	}
+
+	startTime, err := time.Parse(vdiff.TimestampFormat, startedAt)
+	if err != nil || progress.Percentage < 1 {
+		return progress
+	}
+
+	// Calculate how long 1% took, on average, and multiply that by the
+	// percentage left.
+	pctToGo := math.Abs(progress.Percentage - 100.00)
+	runTime := now.Unix() - startTime.Unix()
+	eta := time.Unix(((runTime/int64(progress.Percentage))*int64(pctToGo))+now.Unix(), 0).UTC()
+	// Cap the ETA at 1 year out to prevent providing nonsensical ETAs.
+	if eta.Before(now.AddDate(1, 0, 0)) {
+		progress.Eta = protoutil.TimeToProto(eta)
+	}
+
+	return progress
+}
+
+func vdiffTableReportFromDiffReport(state string, dr *vdiff.DiffReport) *vtctldatapb.VDiffTableReport {
+	rowDiff := func(rd *vdiff.RowDiff) *vtctldatapb.VDiffRowDiff {
+		if rd == nil {
+			return nil
+		}
+		return &vtctldatapb.VDiffRowDiff{
+			Row:   rd.Row,
+			Query: rd.Query,
+		}
+	}
+
+	report := &vtctldatapb.VDiffTableReport{
+		State:           state,
+		ProcessedRows:   dr.ProcessedRows,
+		MatchingRows:    dr.MatchingRows,
+		MismatchedRows:  dr.MismatchedRows,
+		ExtraRowsSource: dr.ExtraRowsSource,
+		ExtraRowsTarget: dr.ExtraRowsTarget,
+	}
+	for _, rd := range dr.ExtraRowsSourceDiffs {
+		report.ExtraRowsSourceSamples = append(report.ExtraRowsSourceSamples, rowDiff(rd))
+	}
+	for _, rd := range dr.ExtraRowsTargetDiffs {
+		report.ExtraRowsTargetSamples = append(report.ExtraRowsTargetSamples, rowDiff(rd))
+	}
+	for _, mm := range dr.MismatchedRowsDiffs {
+		if mm == nil {
+			continue
+		}
+		report.MismatchedRowsSamples = append(report.MismatchedRowsSamples, &vtctldatapb.VDiffRowMismatch{
+			Source: rowDiff(mm.Source),
+			Target: rowDiff(mm.Target),
+		})
+	}
+
+	return report
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workflow
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vttablet/tabletmanager/vdiff"
+
+	tabletmanagerdatapb "vitess.io/vitess/go/vt/proto/tabletmanagerdata"
+)
+
+const (
+	testVDiffUUID          = "a037a9e2-5628-11ee-8c99-0242ac120002"
+	testVDiffSummaryFields = "vdiff_state|last_error|table_name|uuid|table_state|table_rows|started_at|rows_compared|completed_at|has_mismatch|report"
+	testVDiffSummaryTypes  = "varchar|varchar|varchar|varchar|varchar|int64|timestamp|int64|timestamp|int64|json"
+)
+
+func testVDiffResponse(rows ...string) *tabletmanagerdatapb.VDiffResponse {
+	return &tabletmanagerdatapb.VDiffResponse{
+		Output: sqltypes.ResultToProto3(sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(testVDiffSummaryFields, testVDiffSummaryTypes),
+			rows...,
+		)),
+	}
+}
+
+func TestBuildVDiffSummary(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 1, 1, 0, 10, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		responses      map[string]*tabletmanagerdatapb.VDiffResponse
+		verbose        bool
+		state          vdiff.VDiffState
+		rowsCompared   int64
+		hasMismatch    bool
+		completed      bool
+		shardReports   bool
+		expectProgress bool
+	}{
+		{
+			name: "completed on all shards",
+			responses: map[string]*tabletmanagerdatapb.VDiffResponse{
+				"-80": testVDiffResponse(`completed||t1|` + testVDiffUUID + `|completed|10|2023-01-01 00:00:00|10|2023-01-01 00:05:00|0|{"TableName":"t1","ProcessedRows":10,"MatchingRows":10}`),
+				"80-": testVDiffResponse(`completed||t1|` + testVDiffUUID + `|completed|10|2023-01-01 00:01:00|10|2023-01-01 00:06:00|0|{"TableName":"t1","ProcessedRows":10,"MatchingRows":10}`),
+			},
+			state:        vdiff.CompletedState,
+			rowsCompared: 20,
+			completed:    true,
+		},
+		{
+			name: "completed with verbose reports",
+			responses: map[string]*tabletmanagerdatapb.VDiffResponse{
+				"0": testVDiffResponse(`completed||t1|` + testVDiffUUID + `|completed|10|2023-01-01 00:00:00|10|2023-01-01 00:05:00|0|{"TableName":"t1","ProcessedRows":10,"MatchingRows":10}`),
+			},
+			verbose:      true,
+			state:        vdiff.CompletedState,
+			rowsCompared: 10,
+			completed:    true,
+			shardReports: true,
+		},
+		{
+			name: "tables completed but shard still running",
+			responses: map[string]*tabletmanagerdatapb.VDiffResponse{
+				"-80": testVDiffResponse(`started||t1|` + testVDiffUUID + `|completed|10|2023-01-01 00:00:00|5|2023-01-01 00:05:00|0|{"TableName":"t1","ProcessedRows":5,"MatchingRows":5}`),
+				"80-": testVDiffResponse(`completed||t1|` + testVDiffUUID + `|completed|10|2023-01-01 00:00:00|10|2023-01-01 00:05:00|0|{"TableName":"t1","ProcessedRows":10,"MatchingRows":10}`),
+			},
+			state:          vdiff.StartedState,
+			rowsCompared:   15,
+			expectProgress: true,
+		},
+		{
+			name: "mismatch",
+			responses: map[string]*tabletmanagerdatapb.VDiffResponse{
+				"0": testVDiffResponse(`completed||t1|` + testVDiffUUID + `|completed|10|2023-01-01 00:00:00|10|2023-01-01 00:05:00|1|{"TableName":"t1","ProcessedRows":10,"MatchingRows":9,"MismatchedRows":1,"MismatchedRowsSample":[{"Source":{"Row":{"id":"1"}},"Target":{"Row":{"id":"1"}}}]}`),
+			},
+			state:        vdiff.CompletedState,
+			rowsCompared: 10,
+			hasMismatch:  true,
+			completed:    true,
+			shardReports: true,
+		},
+		{
+			name: "error is sticky",
+			responses: map[string]*tabletmanagerdatapb.VDiffResponse{
+				"-80": testVDiffResponse(`error|boom|t1|` + testVDiffUUID + `|error|10|2023-01-01 00:00:00|0||0|`),
+				"80-": testVDiffResponse(`started||t1|` + testVDiffUUID + `|started|10|2023-01-01 00:00:00|5||0|`),
+			},
+			state:        vdiff.ErrorState,
+			rowsCompared: 5,
+		},
+		{
+			name: "stopped on any shard",
+			responses: map[string]*tabletmanagerdatapb.VDiffResponse{
+				"-80": testVDiffResponse(`stopped||t1|` + testVDiffUUID + `|started|10|2023-01-01 00:00:00|5||0|`),
+				"80-": testVDiffResponse(`started||t1|` + testVDiffUUID + `|started|10|2023-01-01 00:00:00|5||0|`),
+			},
+			state:        vdiff.StoppedState,
+			rowsCompared: 10,
+		},
+		{
+			name:      "no results",
+			responses: map[string]*tabletmanagerdatapb.VDiffResponse{"0": nil},
+			state:     vdiff.UnknownState,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			summary, err := buildVDiffSummary("ks", "wf", testVDiffUUID, tt.responses, tt.verbose, now)
+			require.NoError(t, err)
+
+			assert.Equal(t, string(tt.state), summary.State)
+			assert.Equal(t, tt.rowsCompared, summary.RowsCompared)
+			assert.Equal(t, tt.hasMismatch, summary.HasMismatch)
+			assert.Equal(t, tt.completed, summary.CompletedAt != nil, "CompletedAt")
+			assert.Equal(t, tt.expectProgress, summary.Progress != nil, "Progress")
+
+			for _, ts := range summary.TableSummaries {
+				assert.Equal(t, tt.shardReports, ts.ShardReports != nil, "ShardReports for %s", ts.TableName)
+			}
+		})
+	}
+}
+
+func TestBuildVDiffSummaryMismatchSamples(t *testing.T) {
+	t.Parallel()
+
+	responses := map[string]*tabletmanagerdatapb.VDiffResponse{
+		"0": testVDiffResponse(`completed||t1|` + testVDiffUUID + `|completed|10|2023-01-01 00:00:00|10|2023-01-01 00:05:00|1|{"TableName":"t1","ProcessedRows":10,"MatchingRows":8,"MismatchedRows":1,"ExtraRowsTarget":1,"ExtraRowsTargetSample":[{"Row":{"id":"2"},"Query":"select id from t1 where id=2"}],"MismatchedRowsSample":[{"Source":{"Row":{"id":"1","c":"a"}},"Target":{"Row":{"id":"1","c":"b"}}}]}`),
+	}
+
+	summary, err := buildVDiffSummary("ks", "wf", testVDiffUUID, responses, false, time.Now())
+	require.NoError(t, err)
+
+	require.Contains(t, summary.TableSummaries, "t1")
+	ts := summary.TableSummaries["t1"]
+	assert.Equal(t, int64(8), ts.MatchingRows)
+	assert.Equal(t, int64(1), ts.MismatchedRows)
+	assert.Equal(t, int64(1), ts.ExtraRowsTarget)
+
+	require.Contains(t, ts.ShardReports, "0")
+	report := ts.ShardReports["0"]
+	require.Len(t, report.MismatchedRowsSamples, 1)
+	assert.Equal(t, "a", report.MismatchedRowsSamples[0].Source.Row["c"])
+	assert.Equal(t, "b", report.MismatchedRowsSamples[0].Target.Row["c"])
+	require.Len(t, report.ExtraRowsTargetSamples, 1)
+	assert.Equal(t, "select id from t1 where id=2", report.ExtraRowsTargetSamples[0].Query)
+}
+
+func TestBuildVDiffProgress(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2023, 1, 1, 0, 10, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		rowsCompared  int64
+		rowsToCompare int64
+		startedAt     string
+		percentage    float64
+		eta           *time.Time
+	}{
+		{
+			name:          "nothing compared",
+			rowsToCompare: 100,
+			startedAt:     "2023-01-01 00:00:00",
+		},
+		{
+			name:          "half way",
+			rowsCompared:  50,
+			rowsToCompare: 100,
+			startedAt:     "2023-01-01 00:00:00",
+			percentage:    50,
+			eta:           func() *time.Time { t := now.Add(10 * time.Minute); return &t }(),
+		},
+		{
+			name:          "capped at 100%",
+			rowsCompared:  150,
+			rowsToCompare: 100,
+			startedAt:     "2023-01-01 00:00:00",
+			percentage:    100,
+			eta:           &now,
+		},
+		{
+			name:         "unknown row count",
+			rowsCompared: 10,
+			startedAt:    "2023-01-01 00:00:00",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			progress := buildVDiffProgress(tt.rowsCompared, tt.rowsToCompare, tt.startedAt, now)
+			assert.Equal(t, tt.percentage, progress.Percentage)
+			if tt.eta == nil {
+				assert.Nil(t, progress.Eta)
+				return
+			}
+			require.NotNil(t, progress.Eta)
+			assert.Equal(t, tt.eta.Unix(), protoutil.TimeFromProto(progress.Eta).Unix())
+		})
+	}
+}
+
+func TestBuildVDiffListings(t *testing.T) {
+	t.Parallel()
+
+	fields := sqltypes.MakeTestFields("vdiff_uuid|workflow|keyspace|shard|state", "varchar|varchar|varchar|varchar|varchar")
+	responses := map[string]*tabletmanagerdatapb.VDiffResponse{
+		"80-": {
+			Output: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields,
+				"b|wf|ks|80-|started",
+				"a|wf|ks|80-|completed",
+				"c|other|ks|80-|completed",
+			)),
+		},
+		"-80": {
+			Output: sqltypes.ResultToProto3(sqltypes.MakeTestResult(fields,
+				"a|wf|ks|-80|completed",
+			)),
+		},
+		"0": nil,
+	}
+
+	listings := buildVDiffListings("ks", "wf", responses)
+	require.Len(t, listings, 3)
+
+	var got []string
+	for _, l := range listings {
+		got = append(got, l.Uuid+"/"+l.Shard)
+	}
+	assert.Equal(t, []string{"a/-80", "a/80-", "b/80-"}, got)
+}
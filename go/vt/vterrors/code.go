@@ -48,6 +48,8 @@ var (
 	VT03023 = errorWithoutState("VT03023", vtrpcpb.Code_INVALID_ARGUMENT, "INSERT not supported when targeting a key range: %s", "When targeting a range of shards, Vitess does not know which shard to send the INSERT to.")
 	VT03024 = errorWithoutState("VT03024", vtrpcpb.Code_INVALID_ARGUMENT, "'%s' user defined variable does not exists", "The query cannot be prepared using the user defined variable as it does not exists for this session.")
 	VT03025 = errorWithState("VT03025", vtrpcpb.Code_INVALID_ARGUMENT, WrongArguments, "Incorrect arguments to %s", "The execute statement have wrong number of arguments")
+	VT03026 = errorWithoutState("VT03026", vtrpcpb.Code_INVALID_ARGUMENT, "window name '%s' is not defined", "The OVER clause refers to a named window that is not defined in the WINDOW clause of the query.")
+	VT03027 = errorWithoutState("VT03027", vtrpcpb.Code_INVALID_ARGUMENT, "%s is not allowed in the recursive query block of common table expression '%s'", "The recursive query block of a recursive common table expression cannot use aggregate or window functions, GROUP BY, DISTINCT, ORDER BY or LIMIT.")
 
 	VT05001 = errorWithState("VT05001", vtrpcpb.Code_NOT_FOUND, DbDropExists, "cannot drop database '%s'; database does not exists", "The given database does not exist; Vitess cannot drop it.")
 	VT05002 = errorWithState("VT05002", vtrpcpb.Code_NOT_FOUND, BadDb, "cannot alter database '%s'; unknown database", "The given database does not exist; Vitess cannot alter it.")
@@ -76,6 +78,7 @@ var (
 	VT09013 = errorWithoutState("VT09013", vtrpcpb.Code_FAILED_PRECONDITION, "semi-sync plugins are not loaded", "Durability policy wants Vitess to use semi-sync, but the MySQL instances don't have the semi-sync plugin loaded.")
 	VT09014 = errorWithoutState("VT09014", vtrpcpb.Code_FAILED_PRECONDITION, "vindex cannot be modified", "The vindex cannot be used as table in DML statement")
 	VT09015 = errorWithoutState("VT09015", vtrpcpb.Code_FAILED_PRECONDITION, "schema tracking required", "This query cannot be planned without more information on the SQL schema. Please turn on schema tracking or add authoritative columns information to your VSchema.")
+	VT09016 = errorWithoutState("VT09016", vtrpcpb.Code_FAILED_PRECONDITION, "recursive query aborted after %d iterations", "The recursive common table expression did not stop producing rows within the maximum number of iterations allowed. Make sure the recursive part of the query has a terminating condition.")
 
 	VT10001 = errorWithoutState("VT10001", vtrpcpb.Code_ABORTED, "foreign key constraints are not allowed", "Foreign key constraints are not allowed, see https://vitess.io/blog/2021-06-15-online-ddl-why-no-fk/.")
 
@@ -117,6 +120,8 @@ var (
 		VT03023,
 		VT03024,
 		VT03025,
+		VT03026,
+		VT03027,
 		VT05001,
 		VT05002,
 		VT05003,
@@ -141,6 +146,7 @@ var (
 		VT09013,
 		VT09014,
 		VT09015,
+		VT09016,
 		VT10001,
 		VT12001,
 		VT13001,
@@ -25,6 +25,7 @@ import (
 	"google.golang.org/protobuf/proto"
 
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/json"
 	"vitess.io/vitess/go/slices2"
 	"vitess.io/vitess/go/sqltypes"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
@@ -98,13 +99,24 @@ func (ap *AggregateParams) typ(inputType querypb.Type) querypb.Type {
 	return typ
 }
 
+// aggregationState holds the per-group state of the aggregations that is not kept in
+// the row itself: the last distinct value seen, and the elements accumulated by
+// JSON_ARRAYAGG, which are only serialized once the group is complete.
+type aggregationState struct {
+	distincts  []sqltypes.Value
+	jsonArrays [][]*json.Value
+}
+
 func convertRow(
 	fields []*querypb.Field,
 	row []sqltypes.Value,
 	aggregates []*AggregateParams,
-) (newRow []sqltypes.Value, curDistincts []sqltypes.Value) {
+) (newRow []sqltypes.Value, state *aggregationState) {
 	newRow = append(newRow, row...)
-	curDistincts = make([]sqltypes.Value, len(aggregates))
+	state = &aggregationState{
+		distincts:  make([]sqltypes.Value, len(aggregates)),
+		jsonArrays: make([][]*json.Value, len(aggregates)),
+	}
 	for index, aggr := range aggregates {
 		switch aggr.Opcode {
 		case AggregateCountStar:
@@ -116,7 +128,7 @@ func convertRow(
 			}
 			newRow[aggr.Col] = val
 		case AggregateCountDistinct:
-			curDistincts[index] = findComparableCurrentDistinct(row, aggr)
+			state.distincts[index] = findComparableCurrentDistinct(row, aggr)
 			// Type is int64. Ok to call MakeTrusted.
 			if row[aggr.KeyCol].IsNull() {
 				newRow[aggr.Col] = countZero
@@ -133,7 +145,7 @@ func convertRow(
 				newRow[aggr.Col] = sumZero
 			}
 		case AggregateSumDistinct:
-			curDistincts[index] = findComparableCurrentDistinct(row, aggr)
+			state.distincts[index] = findComparableCurrentDistinct(row, aggr)
 			var err error
 			newRow[aggr.Col], err = evalengine.Cast(row[aggr.Col], fields[aggr.Col].Type)
 			if err != nil {
@@ -153,31 +165,33 @@ func convertRow(
 			if !row[aggr.Col].IsNull() {
 				newRow[aggr.Col] = sqltypes.MakeTrusted(fields[aggr.Col].Type, []byte(row[aggr.Col].ToString()))
 			}
+		case AggregateJSONArrayAgg:
+			state.jsonArrays[index], _ = jsonArrayAggElements(row[aggr.Col], aggr)
 		}
 	}
-	return newRow, curDistincts
+	return newRow, state
 }
 
 func merge(
 	fields []*querypb.Field,
 	row1, row2 []sqltypes.Value,
-	curDistincts []sqltypes.Value,
+	state *aggregationState,
 	aggregates []*AggregateParams,
-) ([]sqltypes.Value, []sqltypes.Value, error) {
+) ([]sqltypes.Value, *aggregationState, error) {
 	result := sqltypes.CopyRow(row1)
 	for index, aggr := range aggregates {
 		if aggr.Opcode.IsDistinct() {
 			if row2[aggr.KeyCol].IsNull() {
 				continue
 			}
-			cmp, err := evalengine.NullsafeCompare(curDistincts[index], row2[aggr.KeyCol], aggr.CollationID)
+			cmp, err := evalengine.NullsafeCompare(state.distincts[index], row2[aggr.KeyCol], aggr.CollationID)
 			if err != nil {
 				return nil, nil, err
 			}
 			if cmp == 0 {
 				continue
 			}
-			curDistincts[index] = findComparableCurrentDistinct(row2, aggr)
+			state.distincts[index] = findComparableCurrentDistinct(row2, aggr)
 		}
 
 		var err error
@@ -242,6 +256,10 @@ func merge(
 			}
 			concat := row1[aggr.Col].ToString() + "," + row2[aggr.Col].ToString()
 			result[aggr.Col] = sqltypes.MakeTrusted(fields[aggr.Col].Type, []byte(concat))
+		case AggregateJSONArrayAgg:
+			var elems []*json.Value
+			elems, err = jsonArrayAggElements(row2[aggr.Col], aggr)
+			state.jsonArrays[index] = append(state.jsonArrays[index], elems...)
 		default:
 			return nil, nil, fmt.Errorf("BUG: Unexpected opcode: %v", aggr.Opcode)
 		}
@@ -249,17 +267,32 @@ func merge(
 			return nil, nil, err
 		}
 	}
-	return result, curDistincts, nil
+	return result, state, nil
+}
+
+// jsonArrayAggElements returns the elements that a row adds to a JSON_ARRAYAGG aggregation:
+// the row value itself, or the elements of the array already aggregated below a route.
+func jsonArrayAggElements(v sqltypes.Value, aggr *AggregateParams) ([]*json.Value, error) {
+	if aggr.OrigOpcode == AggregateJSONArrayAgg {
+		return evalengine.JSONArrayElements(v)
+	}
+	elem, err := evalengine.JSONArrayElement(v, aggr.CollationID)
+	if err != nil {
+		return nil, err
+	}
+	return []*json.Value{elem}, nil
 }
 
-func minMaxWeightStringError() ([]sqltypes.Value, []sqltypes.Value, error) {
+func minMaxWeightStringError() ([]sqltypes.Value, *aggregationState, error) {
 	return nil, nil, vterrors.VT12001("min/max on types that are not comparable is not supported")
 }
 
-func convertFinal(current []sqltypes.Value, aggregates []*AggregateParams) ([]sqltypes.Value, error) {
+func convertFinal(current []sqltypes.Value, state *aggregationState, aggregates []*AggregateParams) ([]sqltypes.Value, error) {
 	result := sqltypes.CopyRow(current)
-	for _, aggr := range aggregates {
+	for index, aggr := range aggregates {
 		switch aggr.Opcode {
+		case AggregateJSONArrayAgg:
+			result[aggr.Col] = evalengine.JSONArray(state.jsonArrays[index])
 		case AggregateGtid:
 			vgtid := &binlogdatapb.VGtid{}
 			currentBytes, err := current[aggr.Col].ToBytes()
@@ -19,6 +19,8 @@ package engine
 import (
 	"context"
 	"fmt"
+	"io"
+	"math"
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/sqltypes"
@@ -49,35 +51,37 @@ type (
 )
 
 func (pt *probeTable) exists(inputRow sqltypes.Row) (bool, error) {
+	code, exists, err := pt.find(inputRow)
+	if err != nil || exists {
+		return exists, err
+	}
+	pt.seenRows[code] = append(pt.seenRows[code], inputRow)
+	return false, nil
+}
+
+// find returns the hash code of the row, and whether the row has been seen
+// already, without adding it to the seen rows.
+func (pt *probeTable) find(inputRow sqltypes.Row) (evalengine.HashCode, bool, error) {
 	// the two prime numbers used here (17 and 31) are used to
 	// calculate hashcode from all column values in the input sqltypes.Row
 	code, err := pt.hashCodeForRow(inputRow)
 	if err != nil {
-		return false, err
-	}
-
-	existingRows, found := pt.seenRows[code]
-	if !found {
-		// nothing with this hash code found, we can be sure it's a not seen sqltypes.Row
-		pt.seenRows[code] = []sqltypes.Row{inputRow}
-		return false, nil
+		return 0, false, err
 	}
 
-	// we found something in the map - still need to check all individual values
+	// if nothing with this hash code is found, we can be sure it's a not seen sqltypes.Row.
+	// otherwise we still need to check all individual values
 	// so we don't just fall for a hash collision
-	for _, existingRow := range existingRows {
+	for _, existingRow := range pt.seenRows[code] {
 		exists, err := pt.equal(existingRow, inputRow)
 		if err != nil {
-			return false, err
+			return 0, false, err
 		}
 		if exists {
-			return true, nil
+			return code, true, nil
 		}
 	}
-
-	pt.seenRows[code] = append(existingRows, inputRow)
-
-	return false, nil
+	return code, false, nil
 }
 
 func (pt *probeTable) hashCodeForRow(inputRow sqltypes.Row) (evalengine.HashCode, error) {
@@ -168,6 +172,12 @@ func newProbeTable(checkCols []CheckCol) *probeTable {
 
 // TryExecute implements the Primitive interface
 func (d *Distinct) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if vcursor.MemoryBudget() != nil {
+		return collectStreamed(func(callback func(*sqltypes.Result) error) error {
+			return d.TryStreamExecute(ctx, vcursor, bindVars, wantfields, callback)
+		})
+	}
+
 	input, err := vcursor.ExecutePrimitive(ctx, d.Source, bindVars, wantfields)
 	if err != nil {
 		return nil, err
@@ -199,24 +209,147 @@ func (d *Distinct) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 func (d *Distinct) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	pt := newProbeTable(d.CheckCols)
 
+	// once the seen rows exceed the memory budget of the query, the rows
+	// which have not been seen yet are spilled to disk, and deduplicated
+	// after all the input has been read.
+	budget := vcursor.MemoryBudget()
+	var seenBytes int64
+	defer func() { budget.release(seenBytes) }()
+	var ds *distinctSpill
+
 	err := vcursor.StreamExecutePrimitive(ctx, d.Source, bindVars, wantfields, func(input *sqltypes.Result) error {
 		result := &sqltypes.Result{
 			Fields:   input.Fields,
 			InsertID: input.InsertID,
 		}
 		for _, row := range input.Rows {
+			if ds != nil {
+				if err := ds.add(pt, row); err != nil {
+					return err
+				}
+				continue
+			}
 			exists, err := pt.exists(row)
 			if err != nil {
 				return err
 			}
 			if !exists {
 				result.Rows = append(result.Rows, row)
+				size := rowSize(row)
+				seenBytes += size
+				if !budget.grow(size) {
+					ds = newDistinctSpill()
+				}
 			}
 		}
 		return callback(result.Truncate(len(d.CheckCols)))
 	})
+	if err != nil || ds == nil {
+		return err
+	}
+	defer ds.close()
+
+	// none of the spilled rows have been seen before they were spilled,
+	// the seen rows don't need to be kept in memory anymore.
+	pt = nil
+	budget.release(seenBytes)
+	seenBytes = 0
+	return ds.emit(d.CheckCols, func(rows [][]sqltypes.Value) error {
+		result := &sqltypes.Result{Rows: rows}
+		return callback(result.Truncate(len(d.CheckCols)))
+	})
+}
+
+// distinctSpill holds the rows a Distinct spills to disk, partitioned by
+// their hash code. Every row is spilled with its position in the input,
+// so that the distinct rows can be emitted in the order of the input.
+type distinctSpill struct {
+	partitions *spillPartitions
+	pos        int64
+}
+
+func newDistinctSpill() *distinctSpill {
+	return &distinctSpill{partitions: newSpillPartitions(newSpillStats("Distinct"))}
+}
+
+// add spills the row, unless it has been seen already.
+func (ds *distinctSpill) add(pt *probeTable, row sqltypes.Row) error {
+	code, exists, err := pt.find(row)
+	if err != nil || exists {
+		return err
+	}
+	ds.pos++
+	return ds.partitions.add(code, append(row[:len(row):len(row)], sqltypes.NewInt64(ds.pos)))
+}
+
+// emit deduplicates the rows of every partition in memory, and passes the
+// distinct rows to the callback, in the order of the input.
+func (ds *distinctSpill) emit(checkCols []CheckCol, callback func([][]sqltypes.Value) error) error {
+	var distinct []*spillBuffer
+	defer func() {
+		for _, buf := range distinct {
+			_ = buf.close()
+		}
+	}()
+	for i := 0; i < spillPartitionCount; i++ {
+		part := ds.partitions.partition(i)
+		if part == nil {
+			continue
+		}
+		buf, err := dedupPartition(part, checkCols, ds.partitions.stats)
+		if err != nil {
+			return err
+		}
+		distinct = append(distinct, buf)
+	}
+
+	byPos := func(a, b []sqltypes.Value) (int, error) {
+		posA, _ := a[len(a)-1].ToInt64()
+		posB, _ := b[len(b)-1].ToInt64()
+		switch {
+		case posA < posB:
+			return -1, nil
+		case posA > posB:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return mergeSpilled(distinct, byPos, math.MaxInt, func(rows [][]sqltypes.Value) error {
+		for i, row := range rows {
+			rows[i] = row[:len(row)-1]
+		}
+		return callback(rows)
+	})
+}
+
+func dedupPartition(part *spillBuffer, checkCols []CheckCol, ss *spillStats) (*spillBuffer, error) {
+	r, err := part.reader()
+	if err != nil {
+		return nil, err
+	}
+	pt := newProbeTable(checkCols)
+	buf := newDiskBuffer(ss)
+	for {
+		row, err := r.next()
+		if err == io.EOF {
+			return buf, nil
+		}
+		if err == nil {
+			var exists bool
+			exists, err = pt.exists(row)
+			if err == nil && !exists {
+				err = buf.add(row)
+			}
+		}
+		if err != nil {
+			_ = buf.close()
+			return nil, err
+		}
+	}
+}
 
-	return err
+func (ds *distinctSpill) close() {
+	ds.partitions.close()
 }
 
 // RouteType implements the Primitive interface
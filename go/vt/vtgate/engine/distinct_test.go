@@ -128,6 +128,35 @@ func TestDistinct(t *testing.T) {
 	}
 }
 
+func TestDistinctStreamSpill(t *testing.T) {
+	// each row uses 33 bytes, two of them fit in the budget.
+	testMemoryBudget = NewMemoryBudget(70)
+	defer func() { testMemoryBudget = nil }()
+
+	distinct := &Distinct{
+		Source: &fakePrimitive{results: []*sqltypes.Result{
+			r("myid", "int64", "5", "3", "5", "1", "3", "4", "2", "1", "4", "6", "null", "2", "null"),
+		}},
+		CheckCols: []CheckCol{{Col: 0, Collation: collations.CollationBinaryID}},
+	}
+
+	spilled := spills.Counts()["Distinct"]
+	result, err := wrapStreamExecute(distinct, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	// the rows are returned in the order of the input, whether they have been spilled or not.
+	utils.MustMatch(t, fmt.Sprintf("%v", r("myid", "int64", "5", "3", "1", "4", "2", "6", "null").Rows), fmt.Sprintf("%v", result.Rows))
+	require.EqualValues(t, spilled+1, spills.Counts()["Distinct"])
+	require.Zero(t, testMemoryBudget.used.Load())
+
+	// the non-streaming execution spills as well.
+	distinct.Source.(*fakePrimitive).rewind()
+	result, err = distinct.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, fmt.Sprintf("%v", r("myid", "int64", "5", "3", "1", "4", "2", "6", "null").Rows), fmt.Sprintf("%v", result.Rows))
+	require.EqualValues(t, spilled+2, spills.Counts()["Distinct"])
+	require.Zero(t, testMemoryBudget.used.Load())
+}
+
 func TestWeightStringFallBack(t *testing.T) {
 	offsetOne := 1
 	checkCols := []CheckCol{{
@@ -0,0 +1,216 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+var _ Primitive = (*DMLWithInput)(nil)
+
+// DMLWithInput executes DMLs on the rows selected by its input.
+// It is used for the DMLs that can't be sent as is to the shards,
+// like a multi-shard UPDATE or DELETE with a LIMIT, or a cross-shard
+// multi-table UPDATE or DELETE: the input selects the primary keys of
+// the rows to change across all the shards, and each DML changes the
+// rows of one table.
+type DMLWithInput struct {
+	txNeeded
+
+	// Input selects the primary keys of the rows to change.
+	Input Primitive
+	// DMLs change the rows selected by Input.
+	DMLs []Primitive
+	// OutputCols are the offsets of the primary key columns of Input
+	// used by each DML.
+	OutputCols [][]int
+	// BVList are the bind variables of each DML that hold the new values
+	// of an UPDATE, mapped to their offset in Input.
+	BVList []map[string]int
+
+	// BVName is the name of the list bind variable the primary keys are
+	// passed to the DMLs with, as values for a single column and as tuples
+	// otherwise. A DML without BVList is executed once for all the selected
+	// rows. Otherwise it is executed once per primary key, with the new
+	// values of the first row selected for that key.
+	BVName string
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (dml *DMLWithInput) RouteType() string {
+	return "DMLWithInput"
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (dml *DMLWithInput) GetKeyspaceName() string {
+	var keyspaces []string
+	for _, prim := range dml.DMLs {
+		ks := prim.GetKeyspaceName()
+		if !slices.Contains(keyspaces, ks) {
+			keyspaces = append(keyspaces, ks)
+		}
+	}
+	return strings.Join(keyspaces, "_")
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (dml *DMLWithInput) GetTableName() string {
+	var tables []string
+	for _, prim := range dml.DMLs {
+		tables = append(tables, prim.GetTableName())
+	}
+	return strings.Join(tables, "_")
+}
+
+// Inputs returns the input primitives of the DMLWithInput.
+func (dml *DMLWithInput) Inputs() []Primitive {
+	return append([]Primitive{dml.Input}, dml.DMLs...)
+}
+
+// TryExecute performs a non-streaming exec.
+func (dml *DMLWithInput) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	inputRes, err := vcursor.ExecutePrimitive(ctx, dml.Input, bindVars, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(inputRes.Rows) == 0 {
+		return &sqltypes.Result{}, nil
+	}
+
+	result := &sqltypes.Result{}
+	for i, prim := range dml.DMLs {
+		res, err := dml.executeDML(ctx, vcursor, bindVars, prim, i, inputRes.Rows)
+		if err != nil {
+			return nil, err
+		}
+		result.RowsAffected += res.RowsAffected
+	}
+	return result, nil
+}
+
+func (dml *DMLWithInput) executeDML(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, prim Primitive, idx int, rows []sqltypes.Row) (*sqltypes.Result, error) {
+	cols := dml.OutputCols[idx]
+	var bvList map[string]int
+	if idx < len(dml.BVList) {
+		bvList = dml.BVList[idx]
+	}
+	if len(bvList) == 0 {
+		values := &querypb.BindVariable{
+			Type:   querypb.Type_TUPLE,
+			Values: make([]*querypb.Value, 0, len(rows)),
+		}
+		for _, row := range rows {
+			values.Values = append(values.Values, pkValue(row, cols))
+		}
+		newBv := copyBindVars(bindVars)
+		newBv[dml.BVName] = values
+		return vcursor.ExecutePrimitive(ctx, prim, newBv, false)
+	}
+
+	// the same row can be selected more than once by a join,
+	// it is only changed once, like MySQL does.
+	seen := make(map[string]bool, len(rows))
+	result := &sqltypes.Result{}
+	for _, row := range rows {
+		pk := pkValue(row, cols)
+		key := fmt.Sprintf("%d:%s", pk.Type, pk.Value)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		newBv := copyBindVars(bindVars)
+		newBv[dml.BVName] = &querypb.BindVariable{
+			Type:   querypb.Type_TUPLE,
+			Values: []*querypb.Value{pk},
+		}
+		for bvName, offset := range bvList {
+			newBv[bvName] = sqltypes.ValueBindVariable(row[offset])
+		}
+		res, err := vcursor.ExecutePrimitive(ctx, prim, newBv, false)
+		if err != nil {
+			return nil, err
+		}
+		result.RowsAffected += res.RowsAffected
+	}
+	return result, nil
+}
+
+// pkValue returns the primary key of the row, as a single value
+// or as a tuple if the primary key has more than one column.
+func pkValue(row sqltypes.Row, cols []int) *querypb.Value {
+	if len(cols) == 1 {
+		return sqltypes.ValueToProto(row[cols[0]])
+	}
+	values := make([]sqltypes.Value, 0, len(cols))
+	for _, col := range cols {
+		values = append(values, row[col])
+	}
+	return sqltypes.TupleToProto(values)
+}
+
+// TryStreamExecute performs a streaming exec.
+func (dml *DMLWithInput) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := dml.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// GetFields fetches the field info.
+func (dml *DMLWithInput) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return nil, vterrors.VT13001("unreachable code for DMLWithInput")
+}
+
+func (dml *DMLWithInput) description() PrimitiveDescription {
+	var outputCols []string
+	for _, cols := range dml.OutputCols {
+		var offsets []string
+		for _, col := range cols {
+			offsets = append(offsets, strconv.Itoa(col))
+		}
+		outputCols = append(outputCols, strings.Join(offsets, ","))
+	}
+	other := map[string]any{
+		"BindVarName": dml.BVName,
+		"OutputCols":  outputCols,
+	}
+	if slices.ContainsFunc(dml.BVList, func(bvs map[string]int) bool { return len(bvs) > 0 }) {
+		var bvList []orderedMap
+		for _, bvs := range dml.BVList {
+			bvList = append(bvList, orderedStringIntMap(bvs))
+		}
+		other["BVList"] = bvList
+	}
+	return PrimitiveDescription{
+		OperatorType:     "DMLWithInput",
+		TargetTabletType: topodatapb.TabletType_PRIMARY,
+		Other:            other,
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestDMLWithInputSingleColumn(t *testing.T) {
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1", "2", "3"),
+	}}
+	dml := &DMLWithInput{
+		Input: input,
+		DMLs: []Primitive{&Delete{
+			DML: &DML{
+				RoutingParameters: &RoutingParameters{
+					Opcode: Scatter,
+					Keyspace: &vindexes.Keyspace{
+						Name:    "ks",
+						Sharded: true,
+					},
+				},
+				Query: "delete from t where id in ::dml_vals",
+			},
+		}},
+		OutputCols: [][]int{{0}},
+		BVName:     "dml_vals",
+	}
+	assert.True(t, dml.NeedsTransaction())
+
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.results = []*sqltypes.Result{{RowsAffected: 3}}
+	qr, err := dml.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, qr.RowsAffected)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ` +
+			`ks.-20: delete from t where id in ::dml_vals {dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} values:{type:INT64 value:"3"}} ` +
+			`ks.20-: delete from t where id in ::dml_vals {dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} values:{type:INT64 value:"3"}} true false`,
+	})
+
+	// Nothing to change when the input returns no rows.
+	input.rewind()
+	input.results = []*sqltypes.Result{sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"))}
+	vc = newDMLTestVCursor("-20", "20-")
+	qr, err = dml.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 0, qr.RowsAffected)
+	vc.ExpectLog(t, nil)
+}
+
+func TestDMLWithInputMultiColumn(t *testing.T) {
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("a|b", "int64|varchar"), "1|x", "2|y"),
+	}}
+	dml := &DMLWithInput{
+		Input: input,
+		DMLs: []Primitive{&Update{
+			DML: &DML{
+				RoutingParameters: &RoutingParameters{
+					Opcode: Scatter,
+					Keyspace: &vindexes.Keyspace{
+						Name:    "ks",
+						Sharded: true,
+					},
+				},
+				Query: "update t set c = 1 where (a, b) in ::dml_vals",
+			},
+		}},
+		OutputCols: [][]int{{0, 1}},
+		BVName:     "dml_vals",
+	}
+
+	// a composite primary key is passed as a list of tuples, in a single DML.
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.results = []*sqltypes.Result{{RowsAffected: 2}}
+	qr, err := dml.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{"other": sqltypes.Int64BindVariable(7)}, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, qr.RowsAffected)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ` +
+			`ks.-20: update t set c = 1 where (a, b) in ::dml_vals {dml_vals: type:TUPLE values:{type:TUPLE value:"\x89\x02\x011\x950\x01x"} values:{type:TUPLE value:"\x89\x02\x012\x950\x01y"} other: type:INT64 value:"7"} ` +
+			`ks.20-: update t set c = 1 where (a, b) in ::dml_vals {dml_vals: type:TUPLE values:{type:TUPLE value:"\x89\x02\x011\x950\x01x"} values:{type:TUPLE value:"\x89\x02\x012\x950\x01y"} other: type:INT64 value:"7"} true false`,
+	})
+}
+
+func TestDMLWithInputMultiTable(t *testing.T) {
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("u.id|ue.id|ue.col", "int64|int64|varchar"), "1|10|a", "1|10|a", "2|11|b"),
+	}}
+	ks := &vindexes.Keyspace{
+		Name:    "ks",
+		Sharded: true,
+	}
+	dml := &DMLWithInput{
+		Input: input,
+		DMLs: []Primitive{&Delete{
+			DML: &DML{
+				RoutingParameters: &RoutingParameters{
+					Opcode:   Scatter,
+					Keyspace: ks,
+				},
+				Query: "delete from u where id in ::dml_vals",
+			},
+		}, &Update{
+			DML: &DML{
+				RoutingParameters: &RoutingParameters{
+					Opcode:   Scatter,
+					Keyspace: ks,
+				},
+				Query: "update ue set val = :dml_vals_0 where id in ::dml_vals",
+			},
+		}},
+		OutputCols: [][]int{{0}, {1}},
+		BVList:     []map[string]int{nil, {"dml_vals_0": 2}},
+		BVName:     "dml_vals",
+	}
+	assert.Equal(t, "ks", dml.GetKeyspaceName())
+
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.results = []*sqltypes.Result{{RowsAffected: 2}, {RowsAffected: 1}, {RowsAffected: 1}}
+	qr, err := dml.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 4, qr.RowsAffected)
+	// the update of the row selected twice is only executed once.
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ` +
+			`ks.-20: delete from u where id in ::dml_vals {dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"1"} values:{type:INT64 value:"2"}} ` +
+			`ks.20-: delete from u where id in ::dml_vals {dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"1"} values:{type:INT64 value:"2"}} true false`,
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ` +
+			`ks.-20: update ue set val = :dml_vals_0 where id in ::dml_vals {dml_vals: type:TUPLE values:{type:INT64 value:"10"} dml_vals_0: type:VARCHAR value:"a"} ` +
+			`ks.20-: update ue set val = :dml_vals_0 where id in ::dml_vals {dml_vals: type:TUPLE values:{type:INT64 value:"10"} dml_vals_0: type:VARCHAR value:"a"} true false`,
+		`ResolveDestinations ks [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ` +
+			`ks.-20: update ue set val = :dml_vals_0 where id in ::dml_vals {dml_vals: type:TUPLE values:{type:INT64 value:"11"} dml_vals_0: type:VARCHAR value:"b"} ` +
+			`ks.20-: update ue set val = :dml_vals_0 where id in ::dml_vals {dml_vals: type:TUPLE values:{type:INT64 value:"11"} dml_vals_0: type:VARCHAR value:"b"} true false`,
+	})
+}
@@ -43,6 +43,7 @@ import (
 
 var testMaxMemoryRows = 100
 var testIgnoreMaxMemoryRows = false
+var testMemoryBudget *MemoryBudget
 
 var _ VCursor = (*noopVCursor)(nil)
 var _ SessionActions = (*noopVCursor)(nil)
@@ -293,6 +294,10 @@ func (t *noopVCursor) ExceedsMaxMemoryRows(numRows int) bool {
 	return !testIgnoreMaxMemoryRows && numRows > testMaxMemoryRows
 }
 
+func (t *noopVCursor) MemoryBudget() *MemoryBudget {
+	return testMemoryBudget
+}
+
 func (t *noopVCursor) GetKeyspace() string {
 	return ""
 }
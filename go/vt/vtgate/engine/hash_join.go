@@ -19,6 +19,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"io"
 	"strings"
 
 	"vitess.io/vitess/go/mysql/collations"
@@ -65,6 +66,12 @@ type HashJoin struct {
 
 // TryExecute implements the Primitive interface
 func (hj *HashJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if vcursor.MemoryBudget() != nil {
+		return collectStreamed(func(callback func(*sqltypes.Result) error) error {
+			return hj.TryStreamExecute(ctx, vcursor, bindVars, wantfields, callback)
+		})
+	}
+
 	lresult, err := vcursor.ExecutePrimitive(ctx, hj.Left, bindVars, wantfields)
 	if err != nil {
 		return nil, err
@@ -131,6 +138,22 @@ func (hj *HashJoin) buildProbeTable(lresult *sqltypes.Result) (map[evalengine.Ha
 
 // TryStreamExecute implements the Primitive interface
 func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	// Once the probe table exceeds the memory budget of the query, the remaining
+	// rows of the LHS are spilled to disk, partitioned by the hash code of their
+	// join value, along with the rows of the RHS that fall in the same partitions.
+	// The spilled partitions are joined one at a time once the RHS has been read,
+	// so their matches are returned after the other ones.
+	budget := vcursor.MemoryBudget()
+	var probeBytes int64
+	defer func() { budget.release(probeBytes) }()
+	var lhsSpill, rhsSpill *spillPartitions
+	defer func() {
+		if lhsSpill != nil {
+			lhsSpill.close()
+			rhsSpill.close()
+		}
+	}()
+
 	// build the probe table from the LHS result
 	probeTable := map[evalengine.HashCode][]sqltypes.Row{}
 	var lfields []*querypb.Field
@@ -147,7 +170,20 @@ func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindV
 			if err != nil {
 				return err
 			}
+			if lhsSpill != nil {
+				if err := lhsSpill.add(hashcode, current); err != nil {
+					return err
+				}
+				continue
+			}
 			probeTable[hashcode] = append(probeTable[hashcode], current)
+			size := rowSize(current)
+			probeBytes += size
+			if !budget.grow(size) {
+				ss := newSpillStats("HashJoin")
+				lhsSpill = newSpillPartitions(ss)
+				rhsSpill = newSpillPartitions(ss)
+			}
 		}
 		return nil
 	})
@@ -155,7 +191,7 @@ func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindV
 		return err
 	}
 
-	return vcursor.StreamExecutePrimitive(ctx, hj.Right, bindVars, wantfields, func(result *sqltypes.Result) error {
+	err = vcursor.StreamExecutePrimitive(ctx, hj.Right, bindVars, wantfields, func(result *sqltypes.Result) error {
 		// compare the results coming from the RHS with the probe-table
 		res := &sqltypes.Result{}
 		if len(result.Fields) != 0 {
@@ -172,19 +208,13 @@ func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindV
 			if err != nil {
 				return err
 			}
-			lftRows := probeTable[hashcode]
-			for _, currentLHSRow := range lftRows {
-				lhsVal := currentLHSRow[hj.LHSKey]
-				// hash codes can give false positives, so we need to check with a real comparison as well
-				cmp, err := evalengine.NullsafeCompare(joinVal, lhsVal, hj.Collation)
-				if err != nil {
+			if res.Rows, err = hj.probe(probeTable[hashcode], currentRHSRow, res.Rows); err != nil {
+				return err
+			}
+			if lhsSpill != nil && lhsSpill.has(hashcode) {
+				if err := rhsSpill.add(hashcode, currentRHSRow); err != nil {
 					return err
 				}
-
-				if cmp == 0 {
-					// we have a match!
-					res.Rows = append(res.Rows, joinRows(currentLHSRow, currentRHSRow, hj.Cols))
-				}
 			}
 		}
 		if len(res.Rows) != 0 || len(res.Fields) != 0 {
@@ -192,6 +222,98 @@ func (hj *HashJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindV
 		}
 		return nil
 	})
+	if err != nil || lhsSpill == nil {
+		return err
+	}
+
+	probeTable = nil
+	budget.release(probeBytes)
+	probeBytes = 0
+	for i := 0; i < spillPartitionCount; i++ {
+		lhs, rhs := lhsSpill.partition(i), rhsSpill.partition(i)
+		if lhs == nil || rhs == nil {
+			continue
+		}
+		if err := hj.joinSpilledPartition(lhs, rhs, callback); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// probe appends to out the join of the RHS row with the rows of the LHS
+// that have the same hash code.
+func (hj *HashJoin) probe(lftRows []sqltypes.Row, currentRHSRow sqltypes.Row, out [][]sqltypes.Value) ([][]sqltypes.Value, error) {
+	joinVal := currentRHSRow[hj.RHSKey]
+	for _, currentLHSRow := range lftRows {
+		lhsVal := currentLHSRow[hj.LHSKey]
+		// hash codes can give false positives, so we need to check with a real comparison as well
+		cmp, err := evalengine.NullsafeCompare(joinVal, lhsVal, hj.Collation)
+		if err != nil {
+			return nil, err
+		}
+
+		if cmp == 0 {
+			// we have a match!
+			out = append(out, joinRows(currentLHSRow, currentRHSRow, hj.Cols))
+		}
+	}
+	return out, nil
+}
+
+// joinSpilledPartition joins the rows of a partition spilled by both sides,
+// building the probe table of the partition in memory.
+func (hj *HashJoin) joinSpilledPartition(lhs, rhs *spillBuffer, callback func(*sqltypes.Result) error) error {
+	probeTable := map[evalengine.HashCode][]sqltypes.Row{}
+	r, err := lhs.reader()
+	if err != nil {
+		return err
+	}
+	for {
+		row, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		hashcode, err := evalengine.NullsafeHashcode(row[hj.LHSKey], hj.Collation, hj.ComparisonType)
+		if err != nil {
+			return err
+		}
+		probeTable[hashcode] = append(probeTable[hashcode], row)
+	}
+
+	if r, err = rhs.reader(); err != nil {
+		return err
+	}
+	var rows [][]sqltypes.Value
+	for {
+		row, err := r.next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		hashcode, err := evalengine.NullsafeHashcode(row[hj.RHSKey], hj.Collation, hj.ComparisonType)
+		if err != nil {
+			return err
+		}
+		if rows, err = hj.probe(probeTable[hashcode], row, rows); err != nil {
+			return err
+		}
+		if len(rows) >= spillBatchRows {
+			if err := callback(&sqltypes.Result{Rows: rows}); err != nil {
+				return err
+			}
+			rows = nil
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return callback(&sqltypes.Result{Rows: rows})
 }
 
 // RouteType implements the Primitive interface
@@ -145,3 +145,77 @@ func TestHashJoinExecuteDifferentType(t *testing.T) {
 		"5|c| 5.0toto|g",
 	))
 }
+
+func TestHashJoinStreamSpill(t *testing.T) {
+	// the probe table exceeds the budget after its second row.
+	testMemoryBudget = NewMemoryBudget(150)
+	defer func() { testMemoryBudget = nil }()
+
+	leftPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"col1|col2",
+					"int64|varchar",
+				),
+				"1|a",
+				"2|b",
+				"3|c",
+				"4|d",
+				"null|e",
+				"5|f",
+				"3|g",
+			),
+		},
+	}
+	rightPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"col3|col4",
+					"int64|varchar",
+				),
+				"3|x",
+				"1|y",
+				"null|z",
+				"5|w",
+				"6|v",
+			),
+		},
+	}
+
+	jn := &HashJoin{
+		Opcode: InnerJoin,
+		Left:   leftPrim,
+		Right:  rightPrim,
+		Cols:   []int{-1, -2, 1, 2},
+		LHSKey: 0,
+		RHSKey: 0,
+	}
+	spilled := spills.Counts()["HashJoin"]
+	r, err := wrapStreamExecute(jn, &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	// the matches of the rows spilled to disk are returned last.
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"col1|col2|col3|col4",
+			"int64|varchar|int64|varchar",
+		),
+		"3|c|3|x",
+		"1|a|1|y",
+		"3|g|3|x",
+		"5|f|5|w",
+	)
+	expectResult(t, "jn.StreamExecute", r, want)
+	require.EqualValues(t, spilled+1, spills.Counts()["HashJoin"])
+	require.Zero(t, testMemoryBudget.used.Load())
+
+	// the non-streaming execution spills as well.
+	leftPrim.rewind()
+	rightPrim.rewind()
+	r, err = jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	expectResult(t, "jn.Execute", r, want)
+	require.EqualValues(t, spilled+2, spills.Counts()["HashJoin"])
+	require.Zero(t, testMemoryBudget.used.Load())
+}
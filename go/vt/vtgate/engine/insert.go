@@ -320,7 +320,6 @@ func (ins *Insert) GetFields(context.Context, VCursor, map[string]*querypb.BindV
 }
 
 func (ins *Insert) execInsertUnsharded(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
-	query := ins.Query
 	if ins.Input != nil {
 		result, err := vcursor.ExecutePrimitive(ctx, ins.Input, bindVars, false)
 		if err != nil {
@@ -329,10 +328,15 @@ func (ins *Insert) execInsertUnsharded(ctx context.Context, vcursor VCursor, bin
 		if len(result.Rows) == 0 {
 			return &sqltypes.Result{}, nil
 		}
-		query = ins.getInsertQueryForUnsharded(result, bindVars)
+		_, qr, err := ins.insertIntoUnshardedTable(ctx, vcursor, bindVars, result)
+		return qr, err
 	}
 
-	_, qr, err := ins.executeUnshardedTableQuery(ctx, vcursor, bindVars, query)
+	insertID, err := ins.processGenerateFromValues(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	_, qr, err := ins.executeUnshardedTableQuery(ctx, vcursor, bindVars, ins.Query, insertID)
 	return qr, err
 }
 
@@ -1010,16 +1014,15 @@ func (ins *Insert) description() PrimitiveDescription {
 }
 
 func (ins *Insert) insertIntoUnshardedTable(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, result *sqltypes.Result) (int64, *sqltypes.Result, error) {
-	query := ins.getInsertQueryForUnsharded(result, bindVars)
-	return ins.executeUnshardedTableQuery(ctx, vcursor, bindVars, query)
-}
-
-func (ins *Insert) executeUnshardedTableQuery(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, query string) (int64, *sqltypes.Result, error) {
-	insertID, err := ins.processGenerateFromValues(ctx, vcursor, bindVars)
+	insertID, err := ins.processGenerateFromRows(ctx, vcursor, result.Rows)
 	if err != nil {
 		return 0, nil, err
 	}
+	query := ins.getInsertQueryForUnsharded(result, bindVars)
+	return ins.executeUnshardedTableQuery(ctx, vcursor, bindVars, query, insertID)
+}
 
+func (ins *Insert) executeUnshardedTableQuery(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, query string, insertID int64) (int64, *sqltypes.Result, error) {
 	rss, _, err := vcursor.ResolveDestinations(ctx, ins.Keyspace.Name, nil, []key.Destination{key.DestinationAllShards{}})
 	if err != nil {
 		return 0, nil, err
@@ -1036,7 +1039,7 @@ func (ins *Insert) executeUnshardedTableQuery(ctx context.Context, vcursor VCurs
 		return 0, nil, err
 	}
 
-	// If processGenerateFromValues generated new values, it supercedes
+	// If the sequence generated new values, it supercedes
 	// any ids that MySQL might have generated. If both generated
 	// values, we don't return an error because this behavior
 	// is required to support migration.
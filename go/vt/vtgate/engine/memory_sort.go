@@ -35,6 +35,8 @@ import (
 var _ Primitive = (*MemorySort)(nil)
 
 // MemorySort is a primitive that performs in-memory sorting.
+// Without a LIMIT, the rows that exceed the memory budget of the query
+// are sorted in runs spilled to disk, and merged at the end.
 type MemorySort struct {
 	UpperLimit evalengine.Expr
 	OrderBy    []OrderByParams
@@ -68,6 +70,12 @@ func (ms *MemorySort) SetTruncateColumnCount(count int) {
 
 // TryExecute satisfies the Primitive interface.
 func (ms *MemorySort) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if vcursor.MemoryBudget() != nil {
+		return collectStreamed(func(callback func(*sqltypes.Result) error) error {
+			return ms.TryStreamExecute(ctx, vcursor, bindVars, wantfields, callback)
+		})
+	}
+
 	count, err := ms.fetchCount(ctx, vcursor, bindVars)
 	if err != nil {
 		return nil, err
@@ -103,6 +111,12 @@ func (ms *MemorySort) TryStreamExecute(ctx context.Context, vcursor VCursor, bin
 		return callback(qr.Truncate(ms.TruncateColumnCount))
 	}
 
+	// with a LIMIT, the heap below holds a bounded number of rows and
+	// there is nothing to spill.
+	if budget := vcursor.MemoryBudget(); budget != nil && ms.UpperLimit == nil {
+		return ms.streamExternalSort(ctx, vcursor, bindVars, wantfields, count, budget, cb)
+	}
+
 	// You have to reverse the ordering because the highest values
 	// must be dropped once the upper limit is reached.
 	sh := &sortHeap{
@@ -144,6 +158,136 @@ func (ms *MemorySort) TryStreamExecute(ctx context.Context, vcursor VCursor, bin
 	return cb(&sqltypes.Result{Rows: sh.rows})
 }
 
+// streamExternalSort sorts the rows streamed by the input within the memory
+// budget of the query: the rows are sorted in runs that fit in the budget,
+// the runs are spilled to disk and merged once all the rows have been read.
+func (ms *MemorySort) streamExternalSort(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, count int, budget *MemoryBudget, callback func(*sqltypes.Result) error) error {
+	es := &externalSort{
+		comparers: extractSlices(ms.OrderBy),
+		count:     count,
+		budget:    budget,
+		stats:     newSpillStats("MemorySort"),
+	}
+	defer es.close()
+
+	err := vcursor.StreamExecutePrimitive(ctx, ms.Input, bindVars, wantfields, func(qr *sqltypes.Result) error {
+		if len(qr.Fields) != 0 {
+			if err := callback(&sqltypes.Result{Fields: qr.Fields}); err != nil {
+				return err
+			}
+		}
+		for _, row := range qr.Rows {
+			if err := es.add(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return es.emit(func(rows [][]sqltypes.Value) error {
+		return callback(&sqltypes.Result{Rows: rows})
+	})
+}
+
+// externalSort sorts rows that may not fit in the memory budget of a query.
+// The rows are added to a run held in memory; whenever the run exceeds the
+// budget, it is sorted and spilled to disk. Only the first count rows of
+// every run are kept, as no other row can be part of the result.
+type externalSort struct {
+	comparers []*comparer
+	count     int
+	budget    *MemoryBudget
+	stats     *spillStats
+
+	run      [][]sqltypes.Value
+	runBytes int64
+	spilled  []*spillBuffer
+}
+
+func (es *externalSort) add(row []sqltypes.Value) error {
+	size := rowSize(row)
+	es.run = append(es.run, row)
+	es.runBytes += size
+	if es.budget.grow(size) || len(es.run) == 1 {
+		return nil
+	}
+	return es.spill()
+}
+
+func (es *externalSort) sortRun() error {
+	sh := &sortHeap{
+		rows:      es.run,
+		comparers: es.comparers,
+	}
+	sort.Sort(sh)
+	if sh.err != nil {
+		return sh.err
+	}
+	if len(es.run) > es.count {
+		es.run = es.run[:es.count]
+	}
+	return nil
+}
+
+func (es *externalSort) spill() error {
+	if err := es.sortRun(); err != nil {
+		return err
+	}
+	buf := newDiskBuffer(es.stats)
+	es.spilled = append(es.spilled, buf)
+	for _, row := range es.run {
+		if err := buf.add(row); err != nil {
+			return err
+		}
+	}
+	es.releaseRun()
+	return nil
+}
+
+func (es *externalSort) releaseRun() {
+	es.budget.release(es.runBytes)
+	es.run = nil
+	es.runBytes = 0
+}
+
+// emit passes the sorted rows to the callback. If no run was spilled,
+// the rows are passed all at once, otherwise they are merged from the
+// runs and passed in batches.
+func (es *externalSort) emit(callback func([][]sqltypes.Value) error) error {
+	if err := es.sortRun(); err != nil {
+		return err
+	}
+	if len(es.spilled) == 0 {
+		return callback(es.run)
+	}
+
+	last := newSpillBuffer(es.stats, 0, nil)
+	for _, row := range es.run {
+		if err := last.add(row); err != nil {
+			return err
+		}
+	}
+	cmp := func(a, b []sqltypes.Value) (int, error) {
+		for _, c := range es.comparers {
+			cmp, err := c.compare(a, b)
+			if err != nil || cmp != 0 {
+				return cmp, err
+			}
+		}
+		return 0, nil
+	}
+	return mergeSpilled(append(es.spilled, last), cmp, es.count, callback)
+}
+
+func (es *externalSort) close() {
+	es.releaseRun()
+	for _, buf := range es.spilled {
+		_ = buf.close()
+	}
+}
+
 // GetFields satisfies the Primitive interface.
 func (ms *MemorySort) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
 	return ms.Input.GetFields(ctx, vcursor, bindVars)
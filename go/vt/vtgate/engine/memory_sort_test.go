@@ -621,6 +621,83 @@ func TestMemorySortMaxMemoryRows(t *testing.T) {
 	}
 }
 
+func TestMemorySortStreamSpill(t *testing.T) {
+	// each row uses 66 bytes, two of them fit in the budget.
+	testMemoryBudget = NewMemoryBudget(150)
+	defer func() { testMemoryBudget = nil }()
+
+	fields := sqltypes.MakeTestFields(
+		"c1|c2",
+		"varbinary|decimal",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"a|1",
+			"g|2",
+			"a|1",
+			"c|4",
+			"c|3",
+			"b|0",
+			"e|5",
+		)},
+	}
+
+	ms := &MemorySort{
+		OrderBy: []OrderByParams{{
+			WeightStringCol: -1,
+			Col:             1,
+		}},
+		Input: fp,
+	}
+
+	spilled := spills.Counts()["MemorySort"]
+	result, err := wrapStreamExecute(ms, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	wantResult := sqltypes.MakeTestResult(
+		fields,
+		"b|0",
+		"a|1",
+		"a|1",
+		"g|2",
+		"c|3",
+		"c|4",
+		"e|5",
+	)
+	utils.MustMatch(t, wantResult, result)
+	require.EqualValues(t, spilled+1, spills.Counts()["MemorySort"])
+	require.Zero(t, testMemoryBudget.used.Load())
+
+	// the non-streaming execution spills as well.
+	fp.rewind()
+	result, err = ms.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, wantResult, result)
+	require.EqualValues(t, spilled+2, spills.Counts()["MemorySort"])
+	require.Zero(t, testMemoryBudget.used.Load())
+
+	// with a LIMIT, the rows are kept in a bounded heap and nothing is spilled.
+	fp.rewind()
+	ms.UpperLimit = evalengine.NewBindVar("__upper_limit")
+	bv := map[string]*querypb.BindVariable{"__upper_limit": sqltypes.Int64BindVariable(3)}
+	result, err = wrapStreamExecute(ms, &noopVCursor{}, bv, true)
+	require.NoError(t, err)
+	wantResult = sqltypes.MakeTestResult(
+		fields,
+		"b|0",
+		"a|1",
+		"a|1",
+	)
+	utils.MustMatch(t, wantResult, result)
+
+	fp.rewind()
+	result, err = ms.TryExecute(context.Background(), &noopVCursor{}, bv, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, wantResult, result)
+	require.EqualValues(t, spilled+2, spills.Counts()["MemorySort"])
+	require.Zero(t, testMemoryBudget.used.Load())
+}
+
 func TestMemorySortExecuteNoVarChar(t *testing.T) {
 	fields := sqltypes.MakeTestFields(
 		"c1|c2",
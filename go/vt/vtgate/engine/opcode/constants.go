@@ -68,6 +68,7 @@ const (
 	AggregateAnyValue
 	AggregateCountStar
 	AggregateGroupConcat
+	AggregateJSONArrayAgg
 	_NumOfOpCodes // This line must be last of the opcodes!
 )
 
@@ -80,6 +81,7 @@ var (
 		AggregateSumDistinct:   sqltypes.Decimal,
 		AggregateSum:           sqltypes.Decimal,
 		AggregateGtid:          sqltypes.VarChar,
+		AggregateJSONArrayAgg:  sqltypes.TypeJSON,
 	}
 )
 
@@ -98,6 +100,7 @@ var SupportedAggregates = map[string]AggregateOpcode{
 	"count_star":     AggregateCountStar,
 	"any_value":      AggregateAnyValue,
 	"group_concat":   AggregateGroupConcat,
+	"json_arrayagg":  AggregateJSONArrayAgg,
 }
 
 var AggregateName = map[AggregateOpcode]string{
@@ -111,6 +114,7 @@ var AggregateName = map[AggregateOpcode]string{
 	AggregateCountStar:     "count_star",
 	AggregateGroupConcat:   "group_concat",
 	AggregateAnyValue:      "any_value",
+	AggregateJSONArrayAgg:  "json_arrayagg",
 }
 
 func (code AggregateOpcode) String() string {
@@ -157,6 +161,8 @@ func (code AggregateOpcode) Type(typ *querypb.Type) (querypb.Type, bool) {
 		return sqltypes.Int64, true
 	case AggregateGtid:
 		return sqltypes.VarChar, true
+	case AggregateJSONArrayAgg:
+		return sqltypes.TypeJSON, true
 	default:
 		panic(code.String()) // we have a unit test checking we never reach here
 	}
@@ -179,3 +185,91 @@ func (code AggregateOpcode) IsDistinct() bool {
 		return false
 	}
 }
+
+// WindowOpcode is the opcode of a window function evaluated by the Window primitive.
+type WindowOpcode int
+
+// These constants list the possible window function opcodes.
+const (
+	WindowRowNumber = WindowOpcode(iota)
+	WindowRank
+	WindowDenseRank
+	WindowPercentRank
+	WindowCumeDist
+	WindowNtile
+	WindowLag
+	WindowLead
+	WindowFirstValue
+	WindowLastValue
+	WindowNthValue
+	WindowCount
+	WindowCountStar
+	WindowSum
+	WindowAvg
+	WindowMin
+	WindowMax
+)
+
+var WindowName = map[WindowOpcode]string{
+	WindowRowNumber:   "row_number",
+	WindowRank:        "rank",
+	WindowDenseRank:   "dense_rank",
+	WindowPercentRank: "percent_rank",
+	WindowCumeDist:    "cume_dist",
+	WindowNtile:       "ntile",
+	WindowLag:         "lag",
+	WindowLead:        "lead",
+	WindowFirstValue:  "first_value",
+	WindowLastValue:   "last_value",
+	WindowNthValue:    "nth_value",
+	WindowCount:       "count",
+	WindowCountStar:   "count_star",
+	WindowSum:         "sum",
+	WindowAvg:         "avg",
+	WindowMin:         "min",
+	WindowMax:         "max",
+}
+
+func (code WindowOpcode) String() string {
+	name := WindowName[code]
+	if name == "" {
+		name = "ERROR"
+	}
+	return name
+}
+
+// MarshalJSON serializes the WindowOpcode as a JSON string.
+// It's used for testing and diagnostics.
+func (code WindowOpcode) MarshalJSON() ([]byte, error) {
+	return ([]byte)(fmt.Sprintf("\"%s\"", code.String())), nil
+}
+
+// Type returns the sql type produced by the window function, given the type of its argument.
+func (code WindowOpcode) Type(typ querypb.Type) querypb.Type {
+	switch code {
+	case WindowRowNumber, WindowRank, WindowDenseRank, WindowNtile:
+		return sqltypes.Uint64
+	case WindowPercentRank, WindowCumeDist:
+		return sqltypes.Float64
+	case WindowCount, WindowCountStar:
+		return sqltypes.Int64
+	case WindowSum, WindowAvg:
+		if sqltypes.IsIntegral(typ) || sqltypes.IsDecimal(typ) {
+			return sqltypes.Decimal
+		}
+		return sqltypes.Float64
+	default:
+		return typ
+	}
+}
+
+// IsAggregate returns true if the window function is an aggregate function
+// which is computed over the window frame.
+func (code WindowOpcode) IsAggregate() bool {
+	switch code {
+	case WindowCount, WindowCountStar, WindowSum, WindowAvg, WindowMin, WindowMax:
+		return true
+	default:
+		return false
+	}
+}
@@ -133,11 +133,11 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 
 	// This code is similar to the one in StreamExecute.
 	var current []sqltypes.Value
-	var curDistincts []sqltypes.Value
+	var curState *aggregationState
 	for _, row := range result.Rows {
 		// this is the first row. set up everything
 		if current == nil {
-			current, curDistincts = convertRow(fields, row, oa.Aggregates)
+			current, curState = convertRow(fields, row, oa.Aggregates)
 			continue
 		}
 
@@ -149,7 +149,7 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 
 		if equal {
 			// we are continuing to add values to the current grouping
-			current, curDistincts, err = merge(fields, current, row, curDistincts, oa.Aggregates)
+			current, curState, err = merge(fields, current, row, curState, oa.Aggregates)
 			if err != nil {
 				return nil, err
 			}
@@ -157,13 +157,17 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 		}
 
 		// this is a new grouping. let's yield the old one, and start a new
-		out.Rows = append(out.Rows, current)
-		current, curDistincts = convertRow(fields, row, oa.Aggregates)
+		final, err := convertFinal(current, curState, oa.Aggregates)
+		if err != nil {
+			return nil, err
+		}
+		out.Rows = append(out.Rows, final)
+		current, curState = convertRow(fields, row, oa.Aggregates)
 		continue
 	}
 
 	if current != nil {
-		final, err := convertFinal(current, oa.Aggregates)
+		final, err := convertFinal(current, curState, oa.Aggregates)
 		if err != nil {
 			return nil, err
 		}
@@ -175,7 +179,7 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 // TryStreamExecute is a Primitive function.
 func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool, callback func(*sqltypes.Result) error) error {
 	var current []sqltypes.Value
-	var curDistincts []sqltypes.Value
+	var curState *aggregationState
 	var fields []*querypb.Field
 
 	cb := func(qr *sqltypes.Result) error {
@@ -193,7 +197,7 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 		for _, row := range qr.Rows {
 			// this is the first row. set up everything
 			if current == nil {
-				current, curDistincts = convertRow(fields, row, oa.Aggregates)
+				current, curState = convertRow(fields, row, oa.Aggregates)
 				continue
 			}
 
@@ -205,7 +209,7 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 
 			if equal {
 				// we are continuing to add values to the current grouping
-				current, curDistincts, err = merge(fields, current, row, curDistincts, oa.Aggregates)
+				current, curState, err = merge(fields, current, row, curState, oa.Aggregates)
 				if err != nil {
 					return err
 				}
@@ -213,10 +217,14 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 			}
 
 			// this is a new grouping. let's yield the old one, and start a new
-			if err := cb(&sqltypes.Result{Rows: [][]sqltypes.Value{current}}); err != nil {
+			final, err := convertFinal(current, curState, oa.Aggregates)
+			if err != nil {
 				return err
 			}
-			current, curDistincts = convertRow(fields, row, oa.Aggregates)
+			if err := cb(&sqltypes.Result{Rows: [][]sqltypes.Value{final}}); err != nil {
+				return err
+			}
+			current, curState = convertRow(fields, row, oa.Aggregates)
 			continue
 		}
 		return nil
@@ -232,7 +240,11 @@ func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCurso
 	}
 
 	if current != nil {
-		if err := cb(&sqltypes.Result{Rows: [][]sqltypes.Value{current}}); err != nil {
+		final, err := convertFinal(current, curState, oa.Aggregates)
+		if err != nil {
+			return err
+		}
+		if err := cb(&sqltypes.Result{Rows: [][]sqltypes.Value{final}}); err != nil {
 			return err
 		}
 	}
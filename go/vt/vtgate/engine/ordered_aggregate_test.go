@@ -185,6 +185,48 @@ func TestOrderedAggregateStreamExecute(t *testing.T) {
 	utils.MustMatch(t, wantResults, results)
 }
 
+func TestOrderedAggregateStreamMemorySortSpill(t *testing.T) {
+	// the memory sort feeding the aggregation spills its rows to disk.
+	testMemoryBudget = NewMemoryBudget(150)
+	defer func() { testMemoryBudget = nil }()
+
+	fields := sqltypes.MakeTestFields(
+		"col|count(*)",
+		"varbinary|decimal",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"c|3",
+			"a|1",
+			"b|2",
+			"a|1",
+			"c|4",
+			"b|5",
+		)},
+	}
+
+	oa := &OrderedAggregate{
+		Aggregates:  []*AggregateParams{NewAggregateParam(AggregateSum, 1, "")},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+		Input: &MemorySort{
+			OrderBy: []OrderByParams{{WeightStringCol: -1, Col: 0}},
+			Input:   fp,
+		},
+	}
+
+	result, err := wrapStreamExecute(oa, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	wantResult := sqltypes.MakeTestResult(
+		fields,
+		"a|2",
+		"b|7",
+		"c|7",
+	)
+	utils.MustMatch(t, fmt.Sprintf("%v", wantResult.Rows), fmt.Sprintf("%v", result.Rows))
+	require.Zero(t, testMemoryBudget.used.Load())
+}
+
 func TestOrderedAggregateStreamExecuteTruncate(t *testing.T) {
 	fp := &fakePrimitive{
 		results: []*sqltypes.Result{sqltypes.MakeTestResult(
@@ -1198,3 +1240,71 @@ func TestGroupConcat(t *testing.T) {
 		})
 	}
 }
+
+// TestOrderedAggregateJSONArrayAgg tests json_arrayagg with full and partial aggregation per group on engine.
+func TestOrderedAggregateJSONArrayAgg(t *testing.T) {
+	var tcases = []struct {
+		name        string
+		origOpcode  AggregateOpcode
+		inputResult *sqltypes.Result
+		expResult   []string
+	}{{
+		name: "full aggregation",
+		inputResult: sqltypes.MakeTestResult(sqltypes.MakeTestFields("c1|c2", "int64|varchar"),
+			"10|a", "10|null", "10|b",
+			"20|c",
+			"30|null"),
+		expResult: []string{
+			`[INT64(10) JSON("[\"a\", null, \"b\"]")]`,
+			`[INT64(20) JSON("[\"c\"]")]`,
+			`[INT64(30) JSON("[null]")]`,
+		},
+	}, {
+		name:       "partial aggregation",
+		origOpcode: AggregateJSONArrayAgg,
+		inputResult: sqltypes.MakeTestResult(sqltypes.MakeTestFields("c1|json_arrayagg(c2)", "int64|json"),
+			`10|[1, "a"]`, "10|null", `10|[null, 2]`,
+			"20|null", "20|null",
+			`30|[3]`),
+		expResult: []string{
+			`[INT64(10) JSON("[1, \"a\", null, 2]")]`,
+			`[INT64(20) NULL]`,
+			`[INT64(30) JSON("[3]")]`,
+		},
+	}}
+
+	printRows := func(rows [][]sqltypes.Value) []string {
+		var out []string
+		for _, row := range rows {
+			out = append(out, fmt.Sprintf("%v", row))
+		}
+		return out
+	}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			fp := &fakePrimitive{results: []*sqltypes.Result{tcase.inputResult}}
+			oa := &OrderedAggregate{
+				Aggregates: []*AggregateParams{{
+					Opcode:     AggregateJSONArrayAgg,
+					OrigOpcode: tcase.origOpcode,
+					Col:        1,
+				}},
+				GroupByKeys: []*GroupByParams{{KeyCol: 0}},
+				Input:       fp,
+			}
+			qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.expResult, printRows(qr.Rows))
+
+			fp.rewind()
+			var rows [][]sqltypes.Value
+			err = oa.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+				rows = append(rows, qr.Rows...)
+				return nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tcase.expResult, printRows(rows))
+		})
+	}
+}
@@ -54,6 +54,11 @@ type (
 		// if the max memory rows override directive is set to true
 		ExceedsMaxMemoryRows(numRows int) bool
 
+		// MemoryBudget returns the memory budget of the query, after
+		// which the primitives spill rows to disk. Returns nil if the
+		// primitives are not allowed to spill.
+		MemoryBudget() *MemoryBudget
+
 		// V3 functions.
 		Execute(ctx context.Context, method string, query string, bindVars map[string]*querypb.BindVariable, rollbackOnError bool, co vtgatepb.CommitOrder) (*sqltypes.Result, error)
 		AutocommitApproval() bool
@@ -0,0 +1,138 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+var _ Primitive = (*RecurseCTE)(nil)
+
+// MaxRecursionDepth is the maximum number of iterations a recursive
+// common table expression is allowed to run for. It mirrors the default
+// value of MySQL's cte_max_recursion_depth.
+const MaxRecursionDepth = 1000
+
+// RecurseCTE evaluates a recursive common table expression in vtgate.
+// The Seed is executed once, and the Term is then executed once for every
+// row produced by the previous iteration, with the columns of that row
+// available as bind variables. Iteration stops when an iteration does not
+// produce any new rows.
+type RecurseCTE struct {
+	// Seed is the non-recursive part of the common table expression.
+	Seed Primitive
+
+	// Term is the recursive part of the common table expression.
+	Term Primitive
+
+	// Vars defines the bind variables that need to be built from
+	// a row of the previous iteration before invoking the Term.
+	Vars map[string]int `json:",omitempty"`
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (r *RecurseCTE) RouteType() string {
+	return "RecurseCTE"
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (r *RecurseCTE) GetKeyspaceName() string {
+	if r.Seed.GetKeyspaceName() == r.Term.GetKeyspaceName() {
+		return r.Seed.GetKeyspaceName()
+	}
+	return r.Seed.GetKeyspaceName() + "_" + r.Term.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (r *RecurseCTE) GetTableName() string {
+	return r.Seed.GetTableName() + "_" + r.Term.GetTableName()
+}
+
+// NeedsTransaction implements the Primitive interface
+func (r *RecurseCTE) NeedsTransaction() bool {
+	return r.Seed.NeedsTransaction() || r.Term.NeedsTransaction()
+}
+
+// TryExecute performs a non-streaming exec.
+func (r *RecurseCTE) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	seed, err := vcursor.ExecutePrimitive(ctx, r.Seed, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &sqltypes.Result{Fields: seed.Fields}
+	result.Rows = append(result.Rows, seed.Rows...)
+	working := seed.Rows
+	for depth := 0; len(working) > 0; depth++ {
+		if depth >= MaxRecursionDepth {
+			return nil, vterrors.VT09016(MaxRecursionDepth)
+		}
+		var next [][]sqltypes.Value
+		for _, row := range working {
+			termVars := make(map[string]*querypb.BindVariable, len(r.Vars))
+			for k, col := range r.Vars {
+				termVars[k] = sqltypes.ValueBindVariable(row[col])
+			}
+			res, err := vcursor.ExecutePrimitive(ctx, r.Term, combineVars(bindVars, termVars), false)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, res.Rows...)
+		}
+		result.Rows = append(result.Rows, next...)
+		if vcursor.ExceedsMaxMemoryRows(len(result.Rows)) {
+			return nil, fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		working = next
+	}
+	return result, nil
+}
+
+// TryStreamExecute performs a streaming exec.
+func (r *RecurseCTE) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := r.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// GetFields fetches the field info.
+func (r *RecurseCTE) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return r.Seed.GetFields(ctx, vcursor, bindVars)
+}
+
+// Inputs returns the input primitives for this RecurseCTE
+func (r *RecurseCTE) Inputs() []Primitive {
+	return []Primitive{r.Seed, r.Term}
+}
+
+func (r *RecurseCTE) description() PrimitiveDescription {
+	other := map[string]any{}
+	if len(r.Vars) > 0 {
+		other["JoinVars"] = orderedStringIntMap(r.Vars)
+	}
+	return PrimitiveDescription{
+		OperatorType: "RecurseCTE",
+		Other:        other,
+	}
+}
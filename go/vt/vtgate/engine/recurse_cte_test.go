@@ -0,0 +1,109 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestRecurseCTEExecute(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"id|parent",
+		"int64|int64",
+	)
+	seed := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1|0"),
+		},
+	}
+	term := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "2|1", "3|1"),
+			sqltypes.MakeTestResult(fields, "4|2"),
+			sqltypes.MakeTestResult(fields),
+			sqltypes.MakeTestResult(fields),
+		},
+	}
+	bv := map[string]*querypb.BindVariable{
+		"a": sqltypes.Int64BindVariable(10),
+	}
+
+	rec := &RecurseCTE{
+		Seed: seed,
+		Term: term,
+		Vars: map[string]int{
+			"id": 0,
+		},
+	}
+	r, err := rec.TryExecute(context.Background(), &noopVCursor{}, bv, true)
+	require.NoError(t, err)
+
+	seed.ExpectLog(t, []string{
+		`Execute a: type:INT64 value:"10" true`,
+	})
+	term.ExpectLog(t, []string{
+		`Execute a: type:INT64 value:"10" id: type:INT64 value:"1" false`,
+		`Execute a: type:INT64 value:"10" id: type:INT64 value:"2" false`,
+		`Execute a: type:INT64 value:"10" id: type:INT64 value:"3" false`,
+		`Execute a: type:INT64 value:"10" id: type:INT64 value:"4" false`,
+	})
+	expectResult(t, "rec.Execute", r, sqltypes.MakeTestResult(
+		fields,
+		"1|0",
+		"2|1",
+		"3|1",
+		"4|2",
+	))
+}
+
+func TestRecurseCTEMaxDepth(t *testing.T) {
+	saveIgnore := testIgnoreMaxMemoryRows
+	testIgnoreMaxMemoryRows = true
+	defer func() {
+		testIgnoreMaxMemoryRows = saveIgnore
+	}()
+
+	fields := sqltypes.MakeTestFields(
+		"n",
+		"int64",
+	)
+	seed := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1"),
+		},
+	}
+	term := &fakePrimitive{}
+	for i := 0; i <= MaxRecursionDepth; i++ {
+		term.results = append(term.results, sqltypes.MakeTestResult(fields, "1"))
+	}
+
+	rec := &RecurseCTE{
+		Seed: seed,
+		Term: term,
+		Vars: map[string]int{
+			"n": 0,
+		},
+	}
+	_, err := rec.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "VT09016: recursive query aborted after 1000 iterations")
+}
@@ -87,13 +87,13 @@ func (sa *ScalarAggregate) TryExecute(ctx context.Context, vcursor VCursor, bind
 	}
 
 	var resultRow []sqltypes.Value
-	var curDistincts []sqltypes.Value
+	var curState *aggregationState
 	for _, row := range result.Rows {
 		if resultRow == nil {
-			resultRow, curDistincts = convertRow(fields, row, sa.Aggregates)
+			resultRow, curState = convertRow(fields, row, sa.Aggregates)
 			continue
 		}
-		resultRow, curDistincts, err = merge(fields, resultRow, row, curDistincts, sa.Aggregates)
+		resultRow, curState, err = merge(fields, resultRow, row, curState, sa.Aggregates)
 		if err != nil {
 			return nil, err
 		}
@@ -104,7 +104,7 @@ func (sa *ScalarAggregate) TryExecute(ctx context.Context, vcursor VCursor, bind
 		// different aggregation functions
 		resultRow, err = sa.createEmptyRow()
 	} else {
-		resultRow, err = convertFinal(resultRow, sa.Aggregates)
+		resultRow, err = convertFinal(resultRow, curState, sa.Aggregates)
 	}
 	if err != nil {
 		return nil, err
@@ -120,7 +120,7 @@ func (sa *ScalarAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor
 		return callback(qr.Truncate(sa.TruncateColumnCount))
 	}
 	var current []sqltypes.Value
-	var curDistincts []sqltypes.Value
+	var curState *aggregationState
 	var fields []*querypb.Field
 	fieldsSent := false
 	var mu sync.Mutex
@@ -142,11 +142,11 @@ func (sa *ScalarAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor
 		// this code is very similar to the TryExecute method
 		for _, row := range result.Rows {
 			if current == nil {
-				current, curDistincts = convertRow(fields, row, sa.Aggregates)
+				current, curState = convertRow(fields, row, sa.Aggregates)
 				continue
 			}
 			var err error
-			current, curDistincts, err = merge(fields, current, row, curDistincts, sa.Aggregates)
+			current, curState, err = merge(fields, current, row, curState, sa.Aggregates)
 			if err != nil {
 				return err
 			}
@@ -165,7 +165,7 @@ func (sa *ScalarAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor
 			return err
 		}
 	} else {
-		current, err = convertFinal(current, sa.Aggregates)
+		current, err = convertFinal(current, curState, sa.Aggregates)
 		if err != nil {
 			return err
 		}
@@ -204,7 +204,8 @@ func createEmptyValueFor(opcode AggregateOpcode) (sqltypes.Value, error) {
 		AggregateMin,
 		AggregateMax,
 		AggregateAnyValue,
-		AggregateGroupConcat:
+		AggregateGroupConcat,
+		AggregateJSONArrayAgg:
 		return sqltypes.NULL, nil
 
 	}
@@ -330,3 +330,53 @@ func TestScalarGroupConcat(t *testing.T) {
 		})
 	}
 }
+
+// TestScalarJSONArrayAgg tests json_arrayagg with full and partial aggregation on engine.
+func TestScalarJSONArrayAgg(t *testing.T) {
+	var tcases = []struct {
+		name        string
+		origOpcode  AggregateOpcode
+		inputResult *sqltypes.Result
+		expResult   string
+	}{{
+		name:        "full aggregation",
+		inputResult: sqltypes.MakeTestResult(sqltypes.MakeTestFields("c2", "varchar"), "a", "null", "b"),
+		expResult:   `JSON("[\"a\", null, \"b\"]")`,
+	}, {
+		name:        "full aggregation of integers",
+		inputResult: sqltypes.MakeTestResult(sqltypes.MakeTestFields("c2", "int64"), "1", "2", "null"),
+		expResult:   `JSON("[1, 2, null]")`,
+	}, {
+		name:        "partial aggregation",
+		origOpcode:  AggregateJSONArrayAgg,
+		inputResult: sqltypes.MakeTestResult(sqltypes.MakeTestFields("json_arrayagg(c2)", "json"), `[1, "a"]`, "null", `[null, 2]`),
+		expResult:   `JSON("[1, \"a\", null, 2]")`,
+	}, {
+		name:        "partial aggregation of empty shards",
+		origOpcode:  AggregateJSONArrayAgg,
+		inputResult: sqltypes.MakeTestResult(sqltypes.MakeTestFields("json_arrayagg(c2)", "json"), "null", "null"),
+		expResult:   `NULL`,
+	}, {
+		name:        "empty result",
+		inputResult: sqltypes.MakeTestResult(sqltypes.MakeTestFields("c2", "varchar")),
+		expResult:   `NULL`,
+	}}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			fp := &fakePrimitive{results: []*sqltypes.Result{tcase.inputResult}}
+			oa := &ScalarAggregate{
+				Aggregates: []*AggregateParams{{
+					Opcode:     AggregateJSONArrayAgg,
+					OrigOpcode: tcase.origOpcode,
+					Col:        0,
+				}},
+				Input: fp,
+			}
+			qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+			require.NoError(t, err)
+			require.Len(t, qr.Rows, 1)
+			assert.Equal(t, tcase.expResult, qr.Rows[0][0].String())
+		})
+	}
+}
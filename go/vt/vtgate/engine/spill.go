@@ -0,0 +1,456 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+	"sync/atomic"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var (
+	spills       = stats.NewCountersWithSingleLabel("VtgateSpills", "Number of primitive executions that spilled rows to disk", "Primitive")
+	spilledBytes = stats.NewCountersWithSingleLabel("VtgateSpilledBytes", "Number of bytes spilled to disk by primitives", "Primitive")
+)
+
+// MemoryBudget is the amount of memory the primitives of a query can use
+// to buffer rows. The primitives that exceed it spill rows to disk.
+// A nil MemoryBudget is unlimited.
+type MemoryBudget struct {
+	limit int64
+	used  atomic.Int64
+}
+
+// NewMemoryBudget returns a MemoryBudget of limit bytes.
+func NewMemoryBudget(limit int64) *MemoryBudget {
+	return &MemoryBudget{limit: limit}
+}
+
+// grow accounts for n more bytes held in memory, and returns false
+// if the budget is exceeded.
+func (mb *MemoryBudget) grow(n int64) bool {
+	if mb == nil {
+		return true
+	}
+	return mb.used.Add(n) <= mb.limit
+}
+
+// release accounts for n bytes that are no longer held in memory.
+func (mb *MemoryBudget) release(n int64) {
+	if mb == nil {
+		return
+	}
+	mb.used.Add(-n)
+}
+
+// valueSize is the size of a sqltypes.Value, without its contents.
+const valueSize = 32
+
+// rowSize estimates the memory used by a row.
+func rowSize(row []sqltypes.Value) int64 {
+	size := int64(len(row)) * valueSize
+	for _, val := range row {
+		size += int64(len(val.Raw()))
+	}
+	return size
+}
+
+// collectStreamed returns all the rows of a streaming execution at once.
+// The primitives that spill run their non-streaming execution this way when
+// the query has a memory budget, so that their input is streamed as well and
+// the rows exceeding the budget are spilled to disk instead of being buffered.
+func collectStreamed(stream func(callback func(*sqltypes.Result) error) error) (*sqltypes.Result, error) {
+	result := &sqltypes.Result{}
+	err := stream(func(qr *sqltypes.Result) error {
+		if len(qr.Fields) != 0 {
+			result.Fields = qr.Fields
+		}
+		if qr.InsertID != 0 {
+			result.InsertID = qr.InsertID
+		}
+		result.Rows = append(result.Rows, qr.Rows...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// spillStats records the spilling of a single execution of a primitive.
+type spillStats struct {
+	primitive string
+	spilled   bool
+}
+
+func newSpillStats(primitive string) *spillStats {
+	return &spillStats{primitive: primitive}
+}
+
+func (s *spillStats) record(bytes int) {
+	if !s.spilled {
+		s.spilled = true
+		spills.Add(s.primitive, 1)
+	}
+	spilledBytes.Add(s.primitive, int64(bytes))
+}
+
+// spillBuffer is an append-only buffer of rows. The first rows are kept
+// in memory; once the buffer holds more than memoryRows rows, or once the
+// memory budget of the query is exceeded, the remaining rows are written
+// to a temporary file. The rows can be read back any number of times, by
+// any number of concurrent readers.
+type spillBuffer struct {
+	stats      *spillStats
+	memoryRows int
+	budget     *MemoryBudget
+	diskOnly   bool
+
+	rows        [][]sqltypes.Value
+	memoryBytes int64
+	spilled     int
+
+	file    *os.File
+	writer  *bufio.Writer
+	scratch []byte
+}
+
+// newSpillBuffer returns a spillBuffer which keeps up to memoryRows rows
+// in memory, as long as they fit in the budget. A non-positive memoryRows
+// and a nil budget keep all the rows in memory.
+func newSpillBuffer(ss *spillStats, memoryRows int, budget *MemoryBudget) *spillBuffer {
+	return &spillBuffer{stats: ss, memoryRows: memoryRows, budget: budget}
+}
+
+// newDiskBuffer returns a spillBuffer which writes all its rows to
+// a temporary file.
+func newDiskBuffer(ss *spillStats) *spillBuffer {
+	return &spillBuffer{stats: ss, diskOnly: true}
+}
+
+// len returns the number of rows in the buffer.
+func (sb *spillBuffer) len() int {
+	return len(sb.rows) + sb.spilled
+}
+
+// fits returns whether the row can be kept in memory. Once a row has
+// been spilled, all the following rows are spilled as well so that the
+// rows are read back in order.
+func (sb *spillBuffer) fits(row []sqltypes.Value) bool {
+	if sb.diskOnly || sb.spilled > 0 {
+		return false
+	}
+	if sb.memoryRows > 0 && len(sb.rows) >= sb.memoryRows {
+		return false
+	}
+	size := rowSize(row)
+	if !sb.budget.grow(size) {
+		sb.budget.release(size)
+		return false
+	}
+	sb.memoryBytes += size
+	return true
+}
+
+// add appends a row to the buffer.
+func (sb *spillBuffer) add(row []sqltypes.Value) error {
+	if sb.fits(row) {
+		sb.rows = append(sb.rows, row)
+		return nil
+	}
+	if sb.file == nil {
+		file, err := os.CreateTemp("", "vtgate-spill-")
+		if err != nil {
+			return err
+		}
+		sb.file = file
+		sb.writer = bufio.NewWriter(file)
+	}
+
+	buf := binary.AppendUvarint(sb.scratch[:0], uint64(len(row)))
+	for _, val := range row {
+		buf = binary.AppendUvarint(buf, uint64(val.Type()))
+		if val.IsNull() {
+			continue
+		}
+		raw := val.Raw()
+		buf = binary.AppendUvarint(buf, uint64(len(raw)))
+		buf = append(buf, raw...)
+	}
+	sb.scratch = buf
+	if _, err := sb.writer.Write(buf); err != nil {
+		return err
+	}
+	sb.spilled++
+	sb.stats.record(len(buf))
+	return nil
+}
+
+// reader returns a new reader positioned on the first row of the buffer.
+func (sb *spillBuffer) reader() (*spillReader, error) {
+	r := &spillReader{sb: sb}
+	if sb.file != nil {
+		if err := sb.writer.Flush(); err != nil {
+			return nil, err
+		}
+		r.file = bufio.NewReader(io.NewSectionReader(sb.file, 0, math.MaxInt64))
+	}
+	return r, nil
+}
+
+// reset empties the buffer so it can be reused. The temporary file,
+// if any, is kept around and truncated.
+func (sb *spillBuffer) reset() error {
+	sb.releaseRows()
+	if sb.file == nil {
+		return nil
+	}
+	sb.writer.Reset(sb.file)
+	if err := sb.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err := sb.file.Seek(0, io.SeekStart)
+	return err
+}
+
+// close releases the temporary file used by the buffer.
+func (sb *spillBuffer) close() error {
+	sb.releaseRows()
+	if sb.file == nil {
+		return nil
+	}
+	name := sb.file.Name()
+	err := sb.file.Close()
+	sb.file = nil
+	sb.writer = nil
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+func (sb *spillBuffer) releaseRows() {
+	sb.budget.release(sb.memoryBytes)
+	sb.memoryBytes = 0
+	sb.rows = nil
+	sb.spilled = 0
+}
+
+// spillReader reads the rows of a spillBuffer sequentially.
+type spillReader struct {
+	sb   *spillBuffer
+	pos  int
+	file *bufio.Reader
+}
+
+// next returns the next row of the buffer, or io.EOF once all the rows
+// have been read.
+func (r *spillReader) next() ([]sqltypes.Value, error) {
+	if r.pos >= r.sb.len() {
+		return nil, io.EOF
+	}
+	r.pos++
+	if r.pos <= len(r.sb.rows) {
+		return r.sb.rows[r.pos-1], nil
+	}
+
+	width, err := binary.ReadUvarint(r.file)
+	if err != nil {
+		return nil, err
+	}
+	row := make([]sqltypes.Value, width)
+	for i := range row {
+		typ, err := binary.ReadUvarint(r.file)
+		if err != nil {
+			return nil, err
+		}
+		if querypb.Type(typ) == sqltypes.Null {
+			continue
+		}
+		size, err := binary.ReadUvarint(r.file)
+		if err != nil {
+			return nil, err
+		}
+		raw := make([]byte, size)
+		if _, err := io.ReadFull(r.file, raw); err != nil {
+			return nil, err
+		}
+		row[i] = sqltypes.MakeTrusted(querypb.Type(typ), raw)
+	}
+	return row, nil
+}
+
+// spillPartitionCount is the number of partitions the rows spilled by
+// hash are split into.
+const spillPartitionCount = 16
+
+// spillPartitions spills rows to disk, partitioned by their hash code,
+// so that the rows of a partition can be processed in memory later on.
+type spillPartitions struct {
+	stats   *spillStats
+	buffers [spillPartitionCount]*spillBuffer
+}
+
+func newSpillPartitions(ss *spillStats) *spillPartitions {
+	return &spillPartitions{stats: ss}
+}
+
+func spillPartition(code evalengine.HashCode) int {
+	return int(code % spillPartitionCount)
+}
+
+// add spills a row to the partition of its hash code.
+func (sp *spillPartitions) add(code evalengine.HashCode, row []sqltypes.Value) error {
+	p := spillPartition(code)
+	if sp.buffers[p] == nil {
+		sp.buffers[p] = newDiskBuffer(sp.stats)
+	}
+	return sp.buffers[p].add(row)
+}
+
+// has returns whether rows have been spilled to the partition of the hash code.
+func (sp *spillPartitions) has(code evalengine.HashCode) bool {
+	return sp.buffers[spillPartition(code)] != nil
+}
+
+// partition returns the buffer of the i-th partition, nil if it is empty.
+func (sp *spillPartitions) partition(i int) *spillBuffer {
+	return sp.buffers[i]
+}
+
+func (sp *spillPartitions) close() {
+	for _, buf := range sp.buffers {
+		if buf != nil {
+			_ = buf.close()
+		}
+	}
+}
+
+// spillBatchRows is the number of rows read back from disk that are
+// passed to the callback of a streaming primitive at once.
+const spillBatchRows = 256
+
+// mergeSpilled merges the rows of buffers that are each sorted according
+// to cmp, and passes them to emit in batches. It stops after limit rows.
+func mergeSpilled(buffers []*spillBuffer, cmp func(a, b []sqltypes.Value) (int, error), limit int, emit func([][]sqltypes.Value) error) error {
+	mh := &mergeHeap{cmp: cmp}
+	for _, buf := range buffers {
+		r, err := buf.reader()
+		if err != nil {
+			return err
+		}
+		row, err := r.next()
+		if err == io.EOF {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		mh.heads = append(mh.heads, mergeHead{row: row, r: r})
+	}
+	heap.Init(mh)
+
+	var batch [][]sqltypes.Value
+	for count := 0; mh.Len() > 0 && count < limit; count++ {
+		if mh.err != nil {
+			return mh.err
+		}
+		head := &mh.heads[0]
+		batch = append(batch, head.row)
+		if len(batch) == spillBatchRows {
+			if err := emit(batch); err != nil {
+				return err
+			}
+			batch = nil
+		}
+
+		row, err := head.r.next()
+		switch {
+		case err == io.EOF:
+			heap.Pop(mh)
+		case err != nil:
+			return err
+		default:
+			head.row = row
+			heap.Fix(mh, 0)
+		}
+	}
+	if mh.err != nil {
+		return mh.err
+	}
+	if len(batch) == 0 {
+		return nil
+	}
+	return emit(batch)
+}
+
+type mergeHead struct {
+	row []sqltypes.Value
+	r   *spillReader
+}
+
+// mergeHeap orders the next rows of the buffers merged by mergeSpilled.
+type mergeHeap struct {
+	heads []mergeHead
+	cmp   func(a, b []sqltypes.Value) (int, error)
+	err   error
+}
+
+// Len satisfies heap.Interface.
+func (mh *mergeHeap) Len() int {
+	return len(mh.heads)
+}
+
+// Less satisfies heap.Interface.
+func (mh *mergeHeap) Less(i, j int) bool {
+	if mh.err != nil {
+		return true
+	}
+	cmp, err := mh.cmp(mh.heads[i].row, mh.heads[j].row)
+	if err != nil {
+		mh.err = err
+		return true
+	}
+	return cmp < 0
+}
+
+// Swap satisfies heap.Interface.
+func (mh *mergeHeap) Swap(i, j int) {
+	mh.heads[i], mh.heads[j] = mh.heads[j], mh.heads[i]
+}
+
+// Push satisfies heap.Interface.
+func (mh *mergeHeap) Push(x any) {
+	mh.heads = append(mh.heads, x.(mergeHead))
+}
+
+// Pop satisfies heap.Interface.
+func (mh *mergeHeap) Pop() any {
+	n := len(mh.heads)
+	x := mh.heads[n-1]
+	mh.heads = mh.heads[:n-1]
+	return x
+}
@@ -123,6 +123,8 @@ func (upd *Update) updateVindexEntries(ctx context.Context, vcursor VCursor, bin
 	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
 
 	for _, row := range subQueryResult.Rows {
+		// the new values that depend on the row are read by the owned vindex query.
+		env.Row = row
 		ksid, err := resolveKeyspaceID(ctx, vcursor, upd.KsidVindex, row[0:upd.KsidLength])
 		if err != nil {
 			return err
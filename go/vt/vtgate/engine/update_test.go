@@ -381,6 +381,59 @@ func TestUpdateEqualChangedVindex(t *testing.T) {
 
 }
 
+func TestUpdateEqualChangedVindexReadFromRow(t *testing.T) {
+	ks := buildTestVSchema().Keyspaces["sharded"]
+	upd := &Update{
+		DML: &DML{
+			RoutingParameters: &RoutingParameters{
+				Opcode:   Equal,
+				Keyspace: ks.Keyspace,
+				Vindex:   ks.Vindexes["hash"],
+				Values:   []evalengine.Expr{evalengine.NewLiteralInt(1)},
+			},
+			Query: "dummy_update",
+			Table: []*vindexes.Table{
+				ks.Tables["t1"],
+			},
+			OwnedVindexQuery: "dummy_subquery",
+			KsidVindex:       ks.Vindexes["hash"],
+			KsidLength:       1,
+		},
+		ChangedVindexValues: map[string]*VindexValues{
+			"onecol": {
+				// the new value of c3 depends on the row, it is read by the owned vindex query.
+				PvMap: map[string]evalengine.Expr{
+					"c3": evalengine.NewColumn(5),
+				},
+				Offset: 4,
+			},
+		},
+	}
+
+	results := []*sqltypes.Result{sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"id|c1|c2|c3|onecol|c3 + 1",
+			"int64|int64|int64|int64|int64|int64",
+		),
+		"1|4|5|6|0|7",
+		"1|7|8|9|0|10",
+	)}
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.results = results
+
+	_, err := upd.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations sharded [type:INT64 value:"1"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6)`,
+		`ExecuteMultiShard sharded.-20: dummy_subquery {} false false`,
+		`Execute delete from lkp1 where from = :from and toc = :toc from: type:INT64 value:"6" toc: type:VARBINARY value:"\x16k@\xb4J\xbaK\xd6" true`,
+		`Execute insert into lkp1(from, toc) values(:from_0, :toc_0) from_0: type:INT64 value:"7" toc_0: type:VARBINARY value:"\x16k@\xb4J\xbaK\xd6" true`,
+		`Execute delete from lkp1 where from = :from and toc = :toc from: type:INT64 value:"9" toc: type:VARBINARY value:"\x16k@\xb4J\xbaK\xd6" true`,
+		`Execute insert into lkp1(from, toc) values(:from_0, :toc_0) from_0: type:INT64 value:"10" toc_0: type:VARBINARY value:"\x16k@\xb4J\xbaK\xd6" true`,
+		`ExecuteMultiShard sharded.-20: dummy_update {} true true`,
+	})
+}
+
 func TestUpdateEqualMultiColChangedVindex(t *testing.T) {
 	ks := buildTestVSchema().Keyspaces["sharded"]
 	upd := &Update{
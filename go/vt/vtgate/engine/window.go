@@ -0,0 +1,666 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*Window)(nil)
+
+// Window is a primitive that evaluates window functions in vtgate.
+// It expects the underlying primitive to feed rows sorted by the
+// PartitionBy columns, followed by the OrderBy columns. The rows of
+// every partition are buffered, and spilled to disk once the partition
+// holds more rows than the max memory rows setting allows, or once the
+// memory budget of the query is exceeded.
+type Window struct {
+	// PartitionBy specifies the columns the rows are partitioned by.
+	PartitionBy []OrderByParams `json:",omitempty"`
+
+	// OrderBy specifies the ordering of the rows within a partition.
+	// Rows that compare equal on these columns are peers.
+	OrderBy []OrderByParams `json:",omitempty"`
+
+	// Functions specifies the window functions to evaluate.
+	Functions []*WindowFunc
+
+	// Cols defines the columns of the output rows. A non-negative value
+	// is the offset of a column of the input row, while a negative value
+	// -n is the result of the n-th window function.
+	Cols []int
+
+	// Input is the primitive that will feed into this Primitive.
+	Input Primitive
+}
+
+// WindowFrame is the frame a window function is evaluated over.
+type WindowFrame int
+
+// These constants list the supported window frames. All of them start
+// at the first row of the partition.
+const (
+	// WindowFrameRange ends with the last peer of the current row.
+	// It is the default frame when the window has an ORDER BY clause.
+	WindowFrameRange = WindowFrame(iota)
+	// WindowFrameRows ends with the current row.
+	WindowFrameRows
+	// WindowFramePartition ends with the last row of the partition.
+	WindowFramePartition
+)
+
+func (f WindowFrame) String() string {
+	switch f {
+	case WindowFrameRange:
+		return "range"
+	case WindowFrameRows:
+		return "rows"
+	default:
+		return "partition"
+	}
+}
+
+// WindowFunc specifies a single window function to evaluate.
+type WindowFunc struct {
+	Opcode WindowOpcode
+	// Col is the offset of the argument of the function, -1 if it has none.
+	Col int
+	// N is the number of buckets of NTILE, the offset of LAG and LEAD,
+	// and the position of the row NTH_VALUE returns.
+	N int
+	// DefaultCol is the offset of the default value of LAG and LEAD, -1 if it has none.
+	DefaultCol int
+	Frame      WindowFrame
+	// CollationID is used to compare the arguments of MIN and MAX.
+	CollationID collations.ID
+	Alias       string
+}
+
+func (f *WindowFunc) String() string {
+	var args []string
+	if f.Col >= 0 {
+		args = append(args, strconv.Itoa(f.Col))
+	}
+	switch f.Opcode {
+	case WindowNtile, WindowNthValue:
+		args = append(args, strconv.Itoa(f.N))
+	case WindowLag, WindowLead:
+		args = append(args, strconv.Itoa(f.N))
+		if f.DefaultCol >= 0 {
+			args = append(args, strconv.Itoa(f.DefaultCol))
+		}
+	}
+	out := fmt.Sprintf("%s(%s)", f.Opcode.String(), strings.Join(args, ", "))
+	if f.usesFrame() {
+		out += " " + f.Frame.String()
+	}
+	if f.Alias != "" {
+		out += " AS " + f.Alias
+	}
+	return out
+}
+
+func (f *WindowFunc) usesFrame() bool {
+	switch f.Opcode {
+	case WindowFirstValue, WindowLastValue, WindowNthValue:
+		return true
+	default:
+		return f.Opcode.IsAggregate()
+	}
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (w *Window) RouteType() string {
+	return w.Input.RouteType()
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (w *Window) GetKeyspaceName() string {
+	return w.Input.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (w *Window) GetTableName() string {
+	return w.Input.GetTableName()
+}
+
+// TryExecute is a Primitive function.
+func (w *Window) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	result, err := vcursor.ExecutePrimitive(
+		ctx,
+		w.Input,
+		bindVars,
+		true, /* we need the input fields types to correctly calculate the output types */
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	st := w.newState(vcursor, result.Fields)
+	defer st.close()
+
+	out := &sqltypes.Result{
+		Fields: w.fields(result.Fields),
+		Rows:   make([][]sqltypes.Value, 0, len(result.Rows)),
+	}
+	emit := func(row []sqltypes.Value) error {
+		out.Rows = append(out.Rows, row)
+		return nil
+	}
+	for _, row := range result.Rows {
+		if err := st.add(row, emit); err != nil {
+			return nil, err
+		}
+	}
+	if err := st.flush(emit); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TryStreamExecute is a Primitive function.
+func (w *Window) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool, callback func(*sqltypes.Result) error) error {
+	var st *windowState
+	defer func() {
+		if st != nil {
+			st.close()
+		}
+	}()
+
+	var rows [][]sqltypes.Value
+	emit := func(row []sqltypes.Value) error {
+		rows = append(rows, row)
+		if len(rows) >= streamRowLimit {
+			return sendRows(&rows, callback)
+		}
+		return nil
+	}
+
+	visitor := func(qr *sqltypes.Result) error {
+		if len(qr.Fields) != 0 {
+			if err := callback(&sqltypes.Result{Fields: w.fields(qr.Fields)}); err != nil {
+				return err
+			}
+		}
+		if st == nil {
+			st = w.newState(vcursor, qr.Fields)
+		}
+		for _, row := range qr.Rows {
+			if err := st.add(row, emit); err != nil {
+				return err
+			}
+		}
+		return sendRows(&rows, callback)
+	}
+
+	err := vcursor.StreamExecutePrimitive(ctx,
+		w.Input,
+		bindVars,
+		true, /* we need the input fields types to correctly calculate the output types */
+		visitor)
+	if err != nil {
+		return err
+	}
+
+	if st == nil {
+		return nil
+	}
+	if err := st.flush(emit); err != nil {
+		return err
+	}
+	return sendRows(&rows, callback)
+}
+
+// streamRowLimit is the number of rows the Window primitive accumulates
+// before sending them to the callback while streaming.
+const streamRowLimit = 1000
+
+func sendRows(rows *[][]sqltypes.Value, callback func(*sqltypes.Result) error) error {
+	if len(*rows) == 0 {
+		return nil
+	}
+	qr := &sqltypes.Result{Rows: *rows}
+	*rows = nil
+	return callback(qr)
+}
+
+// GetFields is a Primitive function.
+func (w *Window) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	qr, err := w.Input.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: w.fields(qr.Fields)}, nil
+}
+
+// Inputs returns the Primitive input for this window
+func (w *Window) Inputs() []Primitive {
+	return []Primitive{w.Input}
+}
+
+// NeedsTransaction implements the Primitive interface
+func (w *Window) NeedsTransaction() bool {
+	return w.Input.NeedsTransaction()
+}
+
+func (w *Window) fields(input []*querypb.Field) []*querypb.Field {
+	if input == nil {
+		return nil
+	}
+	fields := make([]*querypb.Field, 0, len(w.Cols))
+	for _, col := range w.Cols {
+		if col >= 0 {
+			fields = append(fields, input[col])
+			continue
+		}
+		f := w.Functions[-col-1]
+		fields = append(fields, &querypb.Field{
+			Name: f.Alias,
+			Type: f.Opcode.Type(argType(input, f)),
+		})
+	}
+	return fields
+}
+
+func argType(fields []*querypb.Field, f *WindowFunc) querypb.Type {
+	if f.Col < 0 || f.Col >= len(fields) {
+		return sqltypes.Null
+	}
+	return fields[f.Col].Type
+}
+
+func (w *Window) description() PrimitiveDescription {
+	cols := make([]string, 0, len(w.Cols))
+	for _, col := range w.Cols {
+		cols = append(cols, strconv.Itoa(col))
+	}
+	other := map[string]any{
+		"Functions":     GenericJoin(w.Functions, windowFuncToString),
+		"ResultColumns": strings.Join(cols, ","),
+	}
+	if len(w.PartitionBy) > 0 {
+		other["PartitionBy"] = GenericJoin(w.PartitionBy, orderByParamsToString)
+	}
+	if len(w.OrderBy) > 0 {
+		other["OrderBy"] = GenericJoin(w.OrderBy, orderByParamsToString)
+	}
+	return PrimitiveDescription{
+		OperatorType: "Window",
+		Other:        other,
+	}
+}
+
+func windowFuncToString(i any) string {
+	return i.(*WindowFunc).String()
+}
+
+// windowState holds the rows of the partition being read from the input
+// of a Window, along with everything about the partition that can be
+// computed while its rows are being buffered.
+type windowState struct {
+	w     *Window
+	types []querypb.Type
+	rows  *spillBuffer
+
+	first, last []sqltypes.Value
+	// nth holds the argument of NTH_VALUE functions, once reached.
+	nth []sqltypes.Value
+	// totals holds the aggregation of the whole partition.
+	totals []windowAcc
+}
+
+// windowAcc is the running state of an aggregate window function.
+type windowAcc struct {
+	value sqltypes.Value
+	count int64
+}
+
+func (w *Window) newState(vcursor VCursor, fields []*querypb.Field) *windowState {
+	st := &windowState{
+		w:      w,
+		types:  make([]querypb.Type, len(w.Functions)),
+		rows:   newSpillBuffer(newSpillStats("Window"), vcursor.MaxMemoryRows(), vcursor.MemoryBudget()),
+		nth:    make([]sqltypes.Value, len(w.Functions)),
+		totals: make([]windowAcc, len(w.Functions)),
+	}
+	for i, f := range w.Functions {
+		st.types[i] = f.Opcode.Type(argType(fields, f))
+	}
+	return st
+}
+
+func (st *windowState) close() {
+	_ = st.rows.close()
+}
+
+// add buffers a row of the input. If the row starts a new partition,
+// the rows of the previous partition are evaluated and emitted first.
+func (st *windowState) add(row []sqltypes.Value, emit func([]sqltypes.Value) error) error {
+	if st.first != nil {
+		same, err := rowsEqual(st.w.PartitionBy, st.first, row)
+		if err != nil {
+			return err
+		}
+		if !same {
+			if err := st.flush(emit); err != nil {
+				return err
+			}
+		}
+	}
+
+	pos := st.rows.len()
+	if err := st.rows.add(row); err != nil {
+		return err
+	}
+	if st.first == nil {
+		st.first = row
+	}
+	st.last = row
+	for i, f := range st.w.Functions {
+		switch {
+		case f.Opcode == WindowNthValue && pos == f.N-1:
+			st.nth[i] = row[f.Col]
+		case f.Opcode.IsAggregate() && f.Frame == WindowFramePartition:
+			if err := f.accumulate(&st.totals[i], row, st.types[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flush evaluates the window functions over the buffered partition,
+// emits its rows and resets the state for the next partition.
+func (st *windowState) flush(emit func([]sqltypes.Value) error) error {
+	if st.first == nil {
+		return nil
+	}
+	if err := st.evaluate(emit); err != nil {
+		return err
+	}
+	st.first, st.last = nil, nil
+	for i := range st.w.Functions {
+		st.nth[i] = sqltypes.NULL
+		st.totals[i] = windowAcc{}
+	}
+	return st.rows.reset()
+}
+
+// offsetReader reads the rows of a partition that lag or lead the current
+// row by a fixed offset.
+type offsetReader struct {
+	r   *spillReader
+	pos int
+	row []sqltypes.Value
+}
+
+func (o *offsetReader) at(pos int) ([]sqltypes.Value, error) {
+	for o.pos <= pos {
+		row, err := o.r.next()
+		if err != nil {
+			return nil, err
+		}
+		o.row = row
+		o.pos++
+	}
+	return o.row, nil
+}
+
+func (st *windowState) evaluate(emit func([]sqltypes.Value) error) error {
+	w := st.w
+	size := st.rows.len()
+
+	cur, err := st.rows.reader()
+	if err != nil {
+		return err
+	}
+	peers, err := st.rows.reader()
+	if err != nil {
+		return err
+	}
+	offsets := make([]*offsetReader, len(w.Functions))
+	for i, f := range w.Functions {
+		if f.Opcode != WindowLag && f.Opcode != WindowLead {
+			continue
+		}
+		r, err := st.rows.reader()
+		if err != nil {
+			return err
+		}
+		offsets[i] = &offsetReader{r: r}
+	}
+
+	// running holds the aggregation up to the current row, and
+	// ranged the aggregation up to the last peer of the current row.
+	running := make([]windowAcc, len(w.Functions))
+	ranged := make([]windowAcc, len(w.Functions))
+	lastPeer := make([]sqltypes.Value, len(w.Functions))
+
+	var peerStart, peerEnd, denseRank int
+	var peeked []sqltypes.Value
+	for pos := 0; pos < size; pos++ {
+		row, err := cur.next()
+		if err != nil {
+			return err
+		}
+
+		if pos == peerEnd {
+			// this row starts a new group of peers: find where it ends
+			peerStart = pos
+			denseRank++
+			for peerEnd < size {
+				next := peeked
+				if next == nil {
+					next, err = peers.next()
+					if err != nil {
+						return err
+					}
+				}
+				if peerEnd > pos {
+					same, err := rowsEqual(w.OrderBy, row, next)
+					if err != nil {
+						return err
+					}
+					if !same {
+						peeked = next
+						break
+					}
+				}
+				peeked = nil
+				for i, f := range w.Functions {
+					if f.Frame != WindowFrameRange {
+						continue
+					}
+					if f.Col >= 0 {
+						lastPeer[i] = next[f.Col]
+					}
+					if f.Opcode.IsAggregate() {
+						if err := f.accumulate(&ranged[i], next, st.types[i]); err != nil {
+							return err
+						}
+					}
+				}
+				peerEnd++
+			}
+		}
+
+		values := make([]sqltypes.Value, len(w.Functions))
+		for i, f := range w.Functions {
+			var v sqltypes.Value
+			switch f.Opcode {
+			case WindowRowNumber:
+				v = sqltypes.NewUint64(uint64(pos + 1))
+			case WindowRank:
+				v = sqltypes.NewUint64(uint64(peerStart + 1))
+			case WindowDenseRank:
+				v = sqltypes.NewUint64(uint64(denseRank))
+			case WindowPercentRank:
+				rank := float64(0)
+				if size > 1 {
+					rank = float64(peerStart) / float64(size-1)
+				}
+				v = sqltypes.NewFloat64(rank)
+			case WindowCumeDist:
+				v = sqltypes.NewFloat64(float64(peerEnd) / float64(size))
+			case WindowNtile:
+				v = sqltypes.NewUint64(ntile(pos, size, f.N))
+			case WindowLag, WindowLead:
+				target := pos - f.N
+				if f.Opcode == WindowLead {
+					target = pos + f.N
+				}
+				switch {
+				case target >= 0 && target < size:
+					other, err := offsets[i].at(target)
+					if err != nil {
+						return err
+					}
+					v = other[f.Col]
+				case f.DefaultCol >= 0:
+					v = row[f.DefaultCol]
+				}
+			case WindowFirstValue:
+				v = st.first[f.Col]
+			case WindowLastValue:
+				switch f.Frame {
+				case WindowFrameRows:
+					v = row[f.Col]
+				case WindowFrameRange:
+					v = lastPeer[i]
+				default:
+					v = st.last[f.Col]
+				}
+			case WindowNthValue:
+				end := size
+				switch f.Frame {
+				case WindowFrameRows:
+					end = pos + 1
+				case WindowFrameRange:
+					end = peerEnd
+				}
+				if end >= f.N {
+					v = st.nth[i]
+				}
+			default:
+				acc := &st.totals[i]
+				switch f.Frame {
+				case WindowFrameRows:
+					if err := f.accumulate(&running[i], row, st.types[i]); err != nil {
+						return err
+					}
+					acc = &running[i]
+				case WindowFrameRange:
+					acc = &ranged[i]
+				}
+				v, err = f.result(acc)
+				if err != nil {
+					return err
+				}
+			}
+			values[i] = v
+		}
+
+		out := make([]sqltypes.Value, 0, len(w.Cols))
+		for _, col := range w.Cols {
+			if col >= 0 {
+				out = append(out, row[col])
+			} else {
+				out = append(out, values[-col-1])
+			}
+		}
+		if err := emit(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ntile returns the bucket of the row at position pos in a partition of
+// the given size divided in n buckets. As in MySQL, the first size%n
+// buckets hold one more row than the others.
+func ntile(pos, size, n int) uint64 {
+	if n <= 0 {
+		return 0
+	}
+	small := size / n
+	large := small + 1
+	bigBuckets := size % n
+	if pos < bigBuckets*large {
+		return uint64(pos/large + 1)
+	}
+	return uint64(bigBuckets + (pos-bigBuckets*large)/small + 1)
+}
+
+func (f *WindowFunc) accumulate(acc *windowAcc, row []sqltypes.Value, typ querypb.Type) error {
+	if f.Opcode == WindowCountStar {
+		acc.count++
+		return nil
+	}
+	v := row[f.Col]
+	if v.IsNull() {
+		return nil
+	}
+	acc.count++
+
+	var err error
+	switch f.Opcode {
+	case WindowSum, WindowAvg:
+		acc.value, err = evalengine.NullSafeAdd(acc.value, v, typ)
+	case WindowMin:
+		acc.value, err = evalengine.Min(acc.value, v, f.CollationID)
+	case WindowMax:
+		acc.value, err = evalengine.Max(acc.value, v, f.CollationID)
+	}
+	return err
+}
+
+func (f *WindowFunc) result(acc *windowAcc) (sqltypes.Value, error) {
+	switch f.Opcode {
+	case WindowCount, WindowCountStar:
+		return sqltypes.NewInt64(acc.count), nil
+	case WindowAvg:
+		if acc.count == 0 {
+			return sqltypes.NULL, nil
+		}
+		return evalengine.Divide(acc.value, sqltypes.NewInt64(acc.count))
+	default:
+		return acc.value, nil
+	}
+}
+
+// rowsEqual returns true if both rows have the same values in the given columns.
+func rowsEqual(cols []OrderByParams, r1, r2 []sqltypes.Value) (bool, error) {
+	for _, col := range cols {
+		cmp, err := evalengine.NullsafeCompare(r1[col.Col], r2[col.Col], col.CollationID)
+		if err != nil {
+			return false, err
+		}
+		if cmp != 0 {
+			return false, nil
+		}
+	}
+	return true, nil
+}
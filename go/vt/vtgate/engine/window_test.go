@@ -0,0 +1,205 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+)
+
+func windowTestPrimitive(fields string, types string, rows ...string) *fakePrimitive {
+	return &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(sqltypes.MakeTestFields(fields, types), rows...),
+		},
+	}
+}
+
+func TestWindowRanking(t *testing.T) {
+	w := &Window{
+		PartitionBy: []OrderByParams{{Col: 0, WeightStringCol: -1}},
+		OrderBy:     []OrderByParams{{Col: 1, WeightStringCol: -1}},
+		Functions: []*WindowFunc{
+			{Opcode: WindowRowNumber, Col: -1, DefaultCol: -1, Alias: "rn"},
+			{Opcode: WindowRank, Col: -1, DefaultCol: -1, Alias: "rk"},
+			{Opcode: WindowDenseRank, Col: -1, DefaultCol: -1, Alias: "drk"},
+			{Opcode: WindowPercentRank, Col: -1, DefaultCol: -1, Alias: "prk"},
+			{Opcode: WindowCumeDist, Col: -1, DefaultCol: -1, Alias: "cd"},
+			{Opcode: WindowNtile, Col: -1, N: 3, DefaultCol: -1, Alias: "nt"},
+		},
+		Cols: []int{0, 1, -1, -2, -3, -4, -5, -6},
+		Input: windowTestPrimitive("grp|val", "int64|int64",
+			"1|10",
+			"1|10",
+			"1|20",
+			"1|30",
+			"2|5",
+		),
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"grp|val|rn|rk|drk|prk|cd|nt",
+			"int64|int64|uint64|uint64|uint64|float64|float64|uint64",
+		),
+		"1|10|1|1|1|0|0.5|1",
+		"1|10|2|1|1|0|0.5|1",
+		"1|20|3|3|2|0.6666666666666666|0.75|2",
+		"1|30|4|4|3|1|1|3",
+		"2|5|1|1|1|0|1|1",
+	)
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResult(t, "Execute", result, want)
+
+	w.Input.(*fakePrimitive).rewind()
+	result, err = wrapStreamExecute(w, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResult(t, "StreamExecute", result, want)
+}
+
+func TestWindowAggregates(t *testing.T) {
+	w := &Window{
+		PartitionBy: []OrderByParams{{Col: 0, WeightStringCol: -1}},
+		OrderBy:     []OrderByParams{{Col: 1, WeightStringCol: -1}},
+		Functions: []*WindowFunc{
+			{Opcode: WindowSum, Col: 1, DefaultCol: -1, Frame: WindowFrameRange, Alias: "s_range"},
+			{Opcode: WindowSum, Col: 1, DefaultCol: -1, Frame: WindowFrameRows, Alias: "s_rows"},
+			{Opcode: WindowCount, Col: 1, DefaultCol: -1, Frame: WindowFramePartition, Alias: "c"},
+			{Opcode: WindowCountStar, Col: -1, DefaultCol: -1, Frame: WindowFrameRange, Alias: "cs"},
+			{Opcode: WindowAvg, Col: 1, DefaultCol: -1, Frame: WindowFramePartition, Alias: "a"},
+			{Opcode: WindowMax, Col: 1, DefaultCol: -1, Frame: WindowFrameRows, Alias: "m"},
+		},
+		Cols: []int{0, 1, -1, -2, -3, -4, -5, -6},
+		Input: windowTestPrimitive("grp|val", "int64|int64",
+			"1|null",
+			"1|1",
+			"1|2",
+			"1|2",
+			"2|5",
+		),
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResult(t, "Execute", result, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"grp|val|s_range|s_rows|c|cs|a|m",
+			"int64|int64|decimal|decimal|int64|int64|decimal|int64",
+		),
+		"1|null|null|null|3|1|1.6667|null",
+		"1|1|1|1|3|2|1.6667|1",
+		"1|2|5|3|3|4|1.6667|2",
+		"1|2|5|5|3|4|1.6667|2",
+		"2|5|5|5|1|1|5.0000|5",
+	))
+}
+
+func TestWindowValues(t *testing.T) {
+	w := &Window{
+		PartitionBy: []OrderByParams{{Col: 0, WeightStringCol: -1}},
+		OrderBy:     []OrderByParams{{Col: 1, WeightStringCol: -1}},
+		Functions: []*WindowFunc{
+			{Opcode: WindowLag, Col: 1, N: 1, DefaultCol: 2, Alias: "lg"},
+			{Opcode: WindowLead, Col: 1, N: 2, DefaultCol: -1, Alias: "ld"},
+			{Opcode: WindowFirstValue, Col: 1, DefaultCol: -1, Frame: WindowFrameRange, Alias: "fv"},
+			{Opcode: WindowLastValue, Col: 1, DefaultCol: -1, Frame: WindowFrameRange, Alias: "lv"},
+			{Opcode: WindowLastValue, Col: 1, DefaultCol: -1, Frame: WindowFramePartition, Alias: "lvp"},
+			{Opcode: WindowNthValue, Col: 1, N: 2, DefaultCol: -1, Frame: WindowFrameRows, Alias: "nv"},
+		},
+		Cols: []int{0, 1, -1, -2, -3, -4, -5, -6},
+		Input: windowTestPrimitive("grp|val|def", "int64|int64|int64",
+			"1|1|0",
+			"1|2|0",
+			"1|3|0",
+			"2|4|9",
+		),
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"grp|val|lg|ld|fv|lv|lvp|nv",
+			"int64|int64|int64|int64|int64|int64|int64|int64",
+		),
+		"1|1|0|3|1|1|3|null",
+		"1|2|1|null|1|2|3|2",
+		"1|3|2|null|1|3|3|2",
+		"2|4|9|null|4|4|4|null",
+	)
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResult(t, "Execute", result, want)
+
+	// a partition larger than the max memory rows is spilled to disk
+	saveMax := testMaxMemoryRows
+	defer func() { testMaxMemoryRows = saveMax }()
+	testMaxMemoryRows = 1
+
+	w.Input.(*fakePrimitive).rewind()
+	result, err = wrapStreamExecute(w, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	expectResult(t, "StreamExecute", result, want)
+}
+
+func TestSpillBuffer(t *testing.T) {
+	sb := newSpillBuffer(newSpillStats("Test"), 2, nil)
+	defer sb.close()
+
+	rows := [][]sqltypes.Value{
+		{sqltypes.NewInt64(1), sqltypes.NewVarChar("a")},
+		{sqltypes.NewInt64(2), sqltypes.NULL},
+		{sqltypes.NewInt64(3), sqltypes.NewVarChar("")},
+		{sqltypes.NULL, sqltypes.NewVarChar("abc")},
+	}
+	for _, row := range rows {
+		require.NoError(t, sb.add(row))
+	}
+	require.Equal(t, 4, sb.len())
+	require.NotNil(t, sb.file)
+
+	r1, err := sb.reader()
+	require.NoError(t, err)
+	r2, err := sb.reader()
+	require.NoError(t, err)
+	for _, want := range rows {
+		got, err := r1.next()
+		require.NoError(t, err)
+		require.Equal(t, sqltypes.RowToProto3(want), sqltypes.RowToProto3(got))
+		got, err = r2.next()
+		require.NoError(t, err)
+		require.Equal(t, sqltypes.RowToProto3(want), sqltypes.RowToProto3(got))
+	}
+	_, err = r1.next()
+	require.Equal(t, io.EOF, err)
+
+	require.NoError(t, sb.reset())
+	require.Equal(t, 0, sb.len())
+	require.NoError(t, sb.add(rows[0]))
+	r1, err = sb.reader()
+	require.NoError(t, err)
+	got, err := r1.next()
+	require.NoError(t, err)
+	require.Equal(t, sqltypes.RowToProto3(rows[0]), sqltypes.RowToProto3(got))
+}
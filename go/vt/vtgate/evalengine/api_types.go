@@ -18,6 +18,7 @@ package evalengine
 
 import (
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/json"
 	"vitess.io/vitess/go/sqltypes"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -172,3 +173,45 @@ func ToNative(v sqltypes.Value) (any, error) {
 	}
 	return out, err
 }
+
+// JSONArrayElement converts the value v into the JSON value that the JSON_ARRAYAGG
+// aggregation adds to its array for it. A NULL v becomes a JSON null. Textual values
+// are read using the given collation, or the default one when it is unknown.
+func JSONArrayElement(v sqltypes.Value, collation collations.ID) (*json.Value, error) {
+	if collation == collations.Unknown {
+		collation = collations.Default()
+	}
+	e, err := valueToEval(v, defaultCoercionCollation(collation))
+	if err != nil {
+		return nil, err
+	}
+	return argToJSON(e)
+}
+
+// JSONArray serializes the elements accumulated by a JSON_ARRAYAGG aggregation into
+// its JSON array result. An aggregation without any element yields NULL.
+func JSONArray(elems []*json.Value) sqltypes.Value {
+	if len(elems) == 0 {
+		return sqltypes.NULL
+	}
+	return evalToSQLValue(json.NewArray(elems))
+}
+
+// JSONArrayElements returns the elements of the JSON array arr, which is the way the
+// partial results of a JSON_ARRAYAGG aggregation are merged. A NULL array is handled
+// as an empty one.
+func JSONArrayElements(arr sqltypes.Value) ([]*json.Value, error) {
+	if arr.IsNull() {
+		return nil, nil
+	}
+	var p json.Parser
+	doc, err := p.ParseBytes(arr.Raw())
+	if err != nil {
+		return nil, err
+	}
+	elems, ok := doc.Array()
+	if !ok {
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "JSON_ARRAYAGG: expected a JSON array, got %s", doc.Type())
+	}
+	return elems, nil
+}
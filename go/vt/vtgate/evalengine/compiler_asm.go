@@ -2169,6 +2169,27 @@ func (asm *assembler) Fn_JSON_CONTAINS_PATH(match jsonMatch, paths []*json.Path)
 	}
 }
 
+func (asm *assembler) Fn_JSON_CONTAINS(jp *json.Path) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		target := env.vm.stack[env.vm.sp-2].(*evalJSON)
+		candidate := env.vm.stack[env.vm.sp-1].(*evalJSON)
+		if jp != nil {
+			target = jsonMatchFirst(jp, target)
+			if target == nil {
+				env.vm.stack[env.vm.sp-2] = nil
+				env.vm.sp--
+				return 1
+			}
+		}
+		var ok bool
+		ok, env.vm.err = jsonContains(target, candidate)
+		env.vm.stack[env.vm.sp-2] = env.vm.arena.newEvalBool(ok)
+		env.vm.sp--
+		return 1
+	}, "FN JSON_CONTAINS (SP-2), (SP-1)")
+}
+
 func (asm *assembler) Fn_JSON_EXTRACT0(jp []*json.Path) {
 	multi := len(jp) > 1 || slices2.Any(jp, func(path *json.Path) bool { return path.ContainsWildcards() })
 
@@ -2248,6 +2269,29 @@ func (asm *assembler) Fn_JSON_KEYS(jp *json.Path) {
 	}
 }
 
+func (asm *assembler) Fn_JSON_MODIFY(method string, t json.Transformation, paths []*json.Path) {
+	var args int
+	if t != json.Remove {
+		args = len(paths)
+	}
+	asm.adjustStack(-args)
+	asm.emit(func(env *ExpressionEnv) int {
+		doc := env.vm.stack[env.vm.sp-args-1].(*evalJSON)
+		values := make([]*json.Value, 0, args)
+		for sp := env.vm.sp - args; sp < env.vm.sp; sp++ {
+			values = append(values, env.vm.stack[sp].(*evalJSON))
+		}
+		res, err := jsonModify(t, doc, paths, values)
+		if err != nil {
+			env.vm.err = err
+			return 0
+		}
+		env.vm.stack[env.vm.sp-args-1] = res
+		env.vm.sp -= args
+		return 1
+	}, "FN %s (SP-%d)...(SP-1), [static]", method, args+1)
+}
+
 func (asm *assembler) Fn_JSON_OBJECT(args int) {
 	asm.adjustStack(-(args - 1))
 	asm.emit(func(env *ExpressionEnv) int {
@@ -2686,7 +2730,7 @@ func (asm *assembler) Fn_LTRIM2(col collations.TypedCollation) {
 		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
 		pat := env.vm.stack[env.vm.sp-1].(*evalBytes)
 		str.tt = int16(sqltypes.VarChar)
-		str.bytes = bytes.TrimPrefix(str.bytes, pat.bytes)
+		str.bytes = trimPrefix(str.bytes, pat.bytes)
 		str.col = col
 		env.vm.sp--
 		return 1
@@ -2699,7 +2743,7 @@ func (asm *assembler) Fn_RTRIM2(col collations.TypedCollation) {
 		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
 		pat := env.vm.stack[env.vm.sp-1].(*evalBytes)
 		str.tt = int16(sqltypes.VarChar)
-		str.bytes = bytes.TrimSuffix(str.bytes, pat.bytes)
+		str.bytes = trimSuffix(str.bytes, pat.bytes)
 		str.col = col
 		env.vm.sp--
 		return 1
@@ -2712,13 +2756,229 @@ func (asm *assembler) Fn_TRIM2(col collations.TypedCollation) {
 		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
 		pat := env.vm.stack[env.vm.sp-1].(*evalBytes)
 		str.tt = int16(sqltypes.VarChar)
-		str.bytes = bytes.TrimPrefix(bytes.TrimSuffix(str.bytes, pat.bytes), pat.bytes)
+		str.bytes = trimPrefix(trimSuffix(str.bytes, pat.bytes), pat.bytes)
 		str.col = col
 		env.vm.sp--
 		return 1
 	}, "FN TRIM VARCHAR(SP-2) VARCHAR(SP-1)")
 }
 
+func (asm *assembler) Fn_SUBSTRING(col collations.TypedCollation, hasLength bool) {
+	if hasLength {
+		asm.adjustStack(-2)
+		asm.emit(func(env *ExpressionEnv) int {
+			str := env.vm.stack[env.vm.sp-3].(*evalBytes)
+			pos := env.vm.stack[env.vm.sp-2].(*evalInt64)
+			length := env.vm.stack[env.vm.sp-1].(*evalInt64)
+
+			cs := col.Collation.Get().Charset()
+			str.tt = int16(sqltypes.VarChar)
+			str.bytes = substring(cs, str.bytes, pos.i, length.i)
+			str.col = col
+			env.vm.sp -= 2
+			return 1
+		}, "FN SUBSTRING VARCHAR(SP-3) INT64(SP-2) INT64(SP-1)")
+		return
+	}
+
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
+		pos := env.vm.stack[env.vm.sp-1].(*evalInt64)
+
+		cs := col.Collation.Get().Charset()
+		str.tt = int16(sqltypes.VarChar)
+		str.bytes = substring(cs, str.bytes, pos.i, math.MaxInt64)
+		str.col = col
+		env.vm.sp--
+		return 1
+	}, "FN SUBSTRING VARCHAR(SP-2) INT64(SP-1)")
+}
+
+func (asm *assembler) Fn_SUBSTRING_INDEX(col collations.TypedCollation) {
+	asm.adjustStack(-2)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-3].(*evalBytes)
+		delim := env.vm.stack[env.vm.sp-2].(*evalBytes)
+		count := env.vm.stack[env.vm.sp-1].(*evalInt64)
+
+		str.tt = int16(sqltypes.VarChar)
+		str.bytes = substringIndex(str.bytes, delim.bytes, count.i)
+		str.col = col
+		env.vm.sp -= 2
+		return 1
+	}, "FN SUBSTRING_INDEX VARCHAR(SP-3) VARCHAR(SP-2) INT64(SP-1)")
+}
+
+func (asm *assembler) Fn_LOCATE(col collations.Collation, hasPos bool) {
+	if hasPos {
+		asm.adjustStack(-2)
+		asm.emit(func(env *ExpressionEnv) int {
+			sub := env.vm.stack[env.vm.sp-3].(*evalBytes)
+			str := env.vm.stack[env.vm.sp-2].(*evalBytes)
+			pos := env.vm.stack[env.vm.sp-1].(*evalInt64)
+
+			env.vm.stack[env.vm.sp-3] = env.vm.arena.newEvalInt64(locate(col, str.bytes, sub.bytes, pos.i))
+			env.vm.sp -= 2
+			return 1
+		}, "FN LOCATE VARCHAR(SP-3) VARCHAR(SP-2) INT64(SP-1)")
+		return
+	}
+
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		sub := env.vm.stack[env.vm.sp-2].(*evalBytes)
+		str := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		env.vm.stack[env.vm.sp-2] = env.vm.arena.newEvalInt64(locate(col, str.bytes, sub.bytes, 1))
+		env.vm.sp--
+		return 1
+	}, "FN LOCATE VARCHAR(SP-2) VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_REPLACE(col collations.TypedCollation) {
+	asm.adjustStack(-2)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-3].(*evalBytes)
+		from := env.vm.stack[env.vm.sp-2].(*evalBytes)
+		to := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		str.tt = int16(sqltypes.VarChar)
+		str.bytes = replaceAll(str.bytes, from.bytes, to.bytes)
+		str.col = col
+		env.vm.sp -= 2
+		return 1
+	}, "FN REPLACE VARCHAR(SP-3) VARCHAR(SP-2) VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_INSERT(col collations.TypedCollation) {
+	asm.adjustStack(-3)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-4].(*evalBytes)
+		pos := env.vm.stack[env.vm.sp-3].(*evalInt64)
+		length := env.vm.stack[env.vm.sp-2].(*evalInt64)
+		newstr := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		cs := col.Collation.Get().Charset()
+		str.tt = int16(sqltypes.VarChar)
+		str.bytes = insertString(cs, str.bytes, newstr.bytes, pos.i, length.i)
+		str.col = col
+		env.vm.sp -= 3
+		return 1
+	}, "FN INSERT VARCHAR(SP-4) INT64(SP-3) INT64(SP-2) VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_REVERSE(col collations.TypedCollation) {
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-1].(*evalBytes)
+
+		cs := col.Collation.Get().Charset()
+		str.tt = int16(sqltypes.VarChar)
+		str.bytes = reverse(cs, str.bytes)
+		str.col = col
+		return 1
+	}, "FN REVERSE VARCHAR(SP-1)")
+}
+
+func (asm *assembler) Fn_SPACE(col collations.TypedCollation) {
+	asm.emit(func(env *ExpressionEnv) int {
+		n := env.vm.stack[env.vm.sp-1].(*evalInt64).i
+		if n < 0 {
+			n = 0
+		}
+		if !validMaxLength(1, n) {
+			env.vm.stack[env.vm.sp-1] = nil
+			return 1
+		}
+		env.vm.stack[env.vm.sp-1] = env.vm.arena.newEvalText(bytes.Repeat([]byte{' '}, int(n)), col)
+		return 1
+	}, "FN SPACE INT64(SP-1)")
+}
+
+func (asm *assembler) Fn_FIELD_c(args int, col collations.Collation) {
+	asm.adjustStack(-(args - 1))
+	asm.emit(func(env *ExpressionEnv) int {
+		var idx int64
+		if str, ok := env.vm.stack[env.vm.sp-args].(*evalBytes); ok {
+			for i := 1; i < args; i++ {
+				candidate, ok := env.vm.stack[env.vm.sp-args+i].(*evalBytes)
+				if ok && col.Collate(str.bytes, candidate.bytes, false) == 0 {
+					idx = int64(i)
+					break
+				}
+			}
+		}
+		env.vm.stack[env.vm.sp-args] = env.vm.arena.newEvalInt64(idx)
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN FIELD VARCHAR(SP-%d)...VARCHAR(SP-1)", args)
+}
+
+func (asm *assembler) Fn_FIELD_f(args int) {
+	asm.adjustStack(-(args - 1))
+	asm.emit(func(env *ExpressionEnv) int {
+		var idx int64
+		if f, ok := env.vm.stack[env.vm.sp-args].(*evalFloat); ok {
+			for i := 1; i < args; i++ {
+				candidate, ok := env.vm.stack[env.vm.sp-args+i].(*evalFloat)
+				if ok && f.f == candidate.f {
+					idx = int64(i)
+					break
+				}
+			}
+		}
+		env.vm.stack[env.vm.sp-args] = env.vm.arena.newEvalInt64(idx)
+		env.vm.sp -= args - 1
+		return 1
+	}, "FN FIELD FLOAT64(SP-%d)...FLOAT64(SP-1)", args)
+}
+
+func (asm *assembler) Fn_ELT(args int, tt sqltypes.Type, tc collations.TypedCollation) {
+	asm.adjustStack(-args)
+	asm.emit(func(env *ExpressionEnv) int {
+		n := env.vm.stack[env.vm.sp-args-1].(*evalInt64).i
+		if n < 1 || n > int64(args) {
+			env.vm.stack[env.vm.sp-args-1] = nil
+		} else {
+			picked := env.vm.stack[env.vm.sp-args-1+int(n)]
+			if str, ok := picked.(*evalBytes); ok {
+				str.tt = int16(tt)
+				str.col = tc
+			}
+			env.vm.stack[env.vm.sp-args-1] = picked
+		}
+		env.vm.sp -= args
+		return 1
+	}, "FN ELT INT64(SP-%d) VARCHAR(SP-%d)...VARCHAR(SP-1)", args+1, args)
+}
+
+func (asm *assembler) Fn_FORMAT_locale() {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		env.vm.err = checkFormatLocale(env.vm.stack[env.vm.sp-1])
+		env.vm.sp--
+		return 1
+	}, "FN FORMAT LOCALE(SP-1)")
+}
+
+func (asm *assembler) Fn_FORMAT(col collations.TypedCollation) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		dec := formatDecimals(env.vm.stack[env.vm.sp-1].(*evalInt64).i)
+
+		var num []byte
+		switch x := env.vm.stack[env.vm.sp-2].(type) {
+		case *evalDecimal:
+			num = []byte(x.dec.StringFixed(dec))
+		case *evalFloat:
+			num = formatFloat(x.f, dec)
+		}
+		env.vm.stack[env.vm.sp-2] = env.vm.arena.newEvalText(formatThousands(num), col)
+		env.vm.sp--
+		return 1
+	}, "FN FORMAT NUMERIC(SP-2) INT64(SP-1)")
+}
+
 func (asm *assembler) Fn_TO_BASE64(t sqltypes.Type, col collations.TypedCollation) {
 	asm.emit(func(env *ExpressionEnv) int {
 		str := env.vm.stack[env.vm.sp-1].(*evalBytes)
@@ -4284,6 +4544,69 @@ func (asm *assembler) Fn_DATEADD_s(unit datetime.IntervalType, sub bool, col col
 
 }
 
+func (asm *assembler) Fn_DATEDIFF() {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		l, _ := env.vm.stack[env.vm.sp-2].(*evalTemporal)
+		r, _ := env.vm.stack[env.vm.sp-1].(*evalTemporal)
+		if l == nil || r == nil || l.dt.Date.IsZero() || r.dt.Date.IsZero() {
+			env.vm.stack[env.vm.sp-2] = nil
+			env.vm.sp--
+			return 1
+		}
+		env.vm.stack[env.vm.sp-2] = env.vm.arena.newEvalInt64(int64(l.dt.Date.DayNumber() - r.dt.Date.DayNumber()))
+		env.vm.sp--
+		return 1
+	}, "FN DATEDIFF DATE(SP-2), DATE(SP-1)")
+}
+
+func (asm *assembler) Fn_TIMESTAMPDIFF(unit datetime.IntervalType) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		l, _ := env.vm.stack[env.vm.sp-2].(*evalTemporal)
+		r, _ := env.vm.stack[env.vm.sp-1].(*evalTemporal)
+		if l == nil || r == nil || l.dt.Date.IsZero() || r.dt.Date.IsZero() {
+			env.vm.stack[env.vm.sp-2] = nil
+			env.vm.sp--
+			return 1
+		}
+		diff, err := datetime.TimestampDiff(l.dt, r.dt, unit)
+		if err != nil {
+			env.vm.err = err
+			return 0
+		}
+		env.vm.stack[env.vm.sp-2] = env.vm.arena.newEvalInt64(diff)
+		env.vm.sp--
+		return 1
+	}, "FN TIMESTAMPDIFF DATETIME(SP-2), DATETIME(SP-1)")
+}
+
+func (asm *assembler) Fn_LAST_DAY() {
+	asm.emit(func(env *ExpressionEnv) int {
+		if env.vm.stack[env.vm.sp-1] == nil {
+			return 1
+		}
+		arg := env.vm.stack[env.vm.sp-1].(*evalTemporal)
+		if arg.dt.Date.IsZero() {
+			env.vm.stack[env.vm.sp-1] = nil
+			return 1
+		}
+		env.vm.stack[env.vm.sp-1] = env.vm.arena.newEvalDate(arg.dt.Date.LastDay())
+		return 1
+	}, "FN LAST_DAY DATE(SP-1)")
+}
+
+func (asm *assembler) Fn_STR_TO_DATE(tt sqltypes.Type, prec int) {
+	asm.adjustStack(-1)
+	asm.emit(func(env *ExpressionEnv) int {
+		str := env.vm.stack[env.vm.sp-2].(*evalBytes)
+		format := env.vm.stack[env.vm.sp-1].(*evalBytes)
+		env.vm.stack[env.vm.sp-2] = strToDate(str.string(), format.string(), tt, prec)
+		env.vm.sp--
+		return 1
+	}, "FN STR_TO_DATE VARCHAR(SP-2), VARCHAR(SP-1)")
+}
+
 func (asm *assembler) Fn_REGEXP_LIKE(m *icuregex.Matcher, negate bool, c charset.Charset, offset int) {
 	asm.adjustStack(-offset)
 	asm.emit(func(env *ExpressionEnv) int {
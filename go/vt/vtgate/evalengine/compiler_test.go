@@ -448,6 +448,42 @@ func TestCompilerSingle(t *testing.T) {
 			expression: `REGEXP_REPLACE(1234, 12, 6, 1)`,
 			result:     `TEXT("634")`,
 		},
+		{
+			expression: `JSON_CONTAINS('{"a": 1, "b": 2, "c": {"d": 4}}', '1', '$.a')`,
+			result:     `INT64(1)`,
+		},
+		{
+			expression: `JSON_CONTAINS('{"a": 1, "b": 2, "c": {"d": 4}}', '{"d": 4}', '$.a')`,
+			result:     `INT64(0)`,
+		},
+		{
+			expression: `JSON_CONTAINS('[1, [2, 3]]', '[1, 2]')`,
+			result:     `INT64(1)`,
+		},
+		{
+			expression: `JSON_CONTAINS('[1, 2]', '1', '$.a')`,
+			result:     `NULL`,
+		},
+		{
+			expression: `JSON_SET('{"a": 1, "b": [2, 3]}', '$.a', 10, '$.c', '[true, false]')`,
+			result:     `JSON("{\"a\": 10, \"b\": [2, 3], \"c\": \"[true, false]\"}")`,
+		},
+		{
+			expression: `JSON_INSERT('{"a": 1, "b": [2, 3]}', '$.a', 10, '$.c', '[true, false]')`,
+			result:     `JSON("{\"a\": 1, \"b\": [2, 3], \"c\": \"[true, false]\"}")`,
+		},
+		{
+			expression: `JSON_REPLACE('{"a": 1, "b": [2, 3]}', '$.a', 10, '$.c', '[true, false]')`,
+			result:     `JSON("{\"a\": 10, \"b\": [2, 3]}")`,
+		},
+		{
+			expression: `JSON_SET('[1, 2]', '$', CAST('{}' AS JSON), '$[3]', 4)`,
+			result:     `JSON("[{}, 4]")`,
+		},
+		{
+			expression: `JSON_REMOVE('[1, [2, 3], 4]', '$[1]')`,
+			result:     `JSON("[1, 4]")`,
+		},
 	}
 
 	for _, tc := range testCases {
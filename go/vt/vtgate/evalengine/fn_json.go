@@ -58,6 +58,15 @@ type (
 	builtinJSONKeys struct {
 		CallExpr
 	}
+
+	builtinJSONContains struct {
+		CallExpr
+	}
+
+	builtinJSONModify struct {
+		CallExpr
+		t json.Transformation
+	}
 )
 
 var _ Expr = (*builtinJSONExtract)(nil)
@@ -68,8 +77,11 @@ var _ Expr = (*builtinJSONDepth)(nil)
 var _ Expr = (*builtinJSONLength)(nil)
 var _ Expr = (*builtinJSONContainsPath)(nil)
 var _ Expr = (*builtinJSONKeys)(nil)
+var _ Expr = (*builtinJSONContains)(nil)
+var _ Expr = (*builtinJSONModify)(nil)
 
 var errInvalidPathForTransform = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "In this situation, path expressions may not contain the * and ** tokens or an array range.")
+var errJSONVacuousPath = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "The path expression '$' is not allowed in this context.")
 
 func (call *builtinJSONExtract) eval(env *ExpressionEnv) (eval, error) {
 	args, err := call.args(env)
@@ -550,3 +562,275 @@ func (call *builtinJSONKeys) compile(c *compiler) (ctype, error) {
 	c.asm.Fn_JSON_KEYS(jp)
 	return ctype{Type: sqltypes.TypeJSON, Flag: flagNullable, Col: collationJSON}, nil
 }
+
+func (call *builtinJSONContains) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	target, err := intoJSON("JSON_CONTAINS", args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	candidate, err := intoJSON("JSON_CONTAINS", args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if len(args) == 3 {
+		jp, err := intoJSONPath(args[2])
+		if err != nil {
+			return nil, err
+		}
+		if jp.ContainsWildcards() {
+			return nil, errInvalidPathForTransform
+		}
+		target = jsonMatchFirst(jp, target)
+		if target == nil {
+			return nil, nil
+		}
+	}
+
+	ok, err := jsonContains(target, candidate)
+	if err != nil {
+		return nil, err
+	}
+	return newEvalBool(ok), nil
+}
+
+func jsonMatchFirst(jp *json.Path, doc *json.Value) *json.Value {
+	var match *json.Value
+	jp.Match(doc, true, func(value *json.Value) {
+		if match == nil {
+			match = value
+		}
+	})
+	return match
+}
+
+// jsonContains returns whether candidate is contained in target, following
+// the containment rules of MySQL's JSON_CONTAINS.
+func jsonContains(target, candidate *json.Value) (bool, error) {
+	switch target.Type() {
+	case json.TypeArray:
+		elems, _ := target.Array()
+		if cands, ok := candidate.Array(); ok {
+			for _, c := range cands {
+				ok, err := jsonContainsAny(elems, c)
+				if err != nil || !ok {
+					return false, err
+				}
+			}
+			return true, nil
+		}
+		return jsonContainsAny(elems, candidate)
+	case json.TypeObject:
+		cobj, ok := candidate.Object()
+		if !ok {
+			return false, nil
+		}
+		tobj, _ := target.Object()
+		for _, key := range cobj.Keys() {
+			tval := tobj.Get(key)
+			if tval == nil {
+				return false, nil
+			}
+			ok, err := jsonContains(tval, cobj.Get(key))
+			if err != nil || !ok {
+				return false, err
+			}
+		}
+		return true, nil
+	default:
+		switch candidate.Type() {
+		case json.TypeArray, json.TypeObject:
+			return false, nil
+		}
+		cmp, err := compareJSONValue(target, candidate)
+		return cmp == 0, err
+	}
+}
+
+func jsonContainsAny(elems []*json.Value, candidate *json.Value) (bool, error) {
+	for _, e := range elems {
+		ok, err := jsonContains(e, candidate)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func (call *builtinJSONContains) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.Int64, f | flagIsBoolean | flagNullable
+}
+
+func (call *builtinJSONContains) compile(c *compiler) (ctype, error) {
+	target, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip1 := c.compileNullCheck1(target)
+	if target.Type != sqltypes.Null {
+		if _, err = c.compileParseJSON("JSON_CONTAINS", target, 1); err != nil {
+			return ctype{}, err
+		}
+	}
+
+	candidate, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip2 := c.compileNullCheck1r(candidate)
+	if candidate.Type != sqltypes.Null {
+		if _, err = c.compileParseJSON("JSON_CONTAINS", candidate, 1); err != nil {
+			return ctype{}, err
+		}
+	}
+
+	var jp *json.Path
+	if len(call.Arguments) == 3 {
+		if lit, ok := call.Arguments[2].(*Literal); !ok || lit.inner == nil {
+			return ctype{}, c.unsupported(call)
+		}
+		jp, err = c.jsonExtractPath(call.Arguments[2])
+		if err != nil {
+			return ctype{}, err
+		}
+		if jp.ContainsWildcards() {
+			return ctype{}, errInvalidPathForTransform
+		}
+	}
+
+	c.asm.Fn_JSON_CONTAINS(jp)
+	c.asm.jumpDestination(skip1, skip2)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: flagIsBoolean | flagNullable}, nil
+}
+
+// jsonModify applies the given transformation to a copy of doc for all the
+// paths, in order. It implements JSON_SET, JSON_INSERT, JSON_REPLACE and JSON_REMOVE.
+func jsonModify(t json.Transformation, doc *json.Value, paths []*json.Path, values []*json.Value) (*json.Value, error) {
+	for _, jp := range paths {
+		if jp.ContainsWildcards() {
+			return nil, errInvalidPathForTransform
+		}
+		if t == json.Remove && jp.IsRoot() {
+			return nil, errJSONVacuousPath
+		}
+	}
+
+	var cloned []*json.Value
+	for _, v := range values {
+		cloned = append(cloned, v.Clone())
+	}
+	return json.ApplyTransform(t, doc.Clone(), paths, cloned)
+}
+
+func (call *builtinJSONModify) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	doc, err := intoJSON(call.Method, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	step := 2
+	if call.t == json.Remove {
+		step = 1
+	}
+
+	var paths []*json.Path
+	var values []*json.Value
+	for i := 1; i < len(args); i += step {
+		if args[i] == nil {
+			return nil, nil
+		}
+		jp, err := intoJSONPath(args[i])
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, jp)
+
+		if call.t != json.Remove {
+			value, err := argToJSON(args[i+1])
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+	}
+
+	return jsonModify(call.t, doc, paths, values)
+}
+
+func (call *builtinJSONModify) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f := call.Arguments[0].typeof(env, fields)
+	return sqltypes.TypeJSON, f | flagNullable
+}
+
+func (call *builtinJSONModify) compile(c *compiler) (ctype, error) {
+	doc, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(doc)
+	if doc.Type != sqltypes.Null {
+		if _, err = c.compileParseJSON(call.Method, doc, 1); err != nil {
+			return ctype{}, err
+		}
+	}
+
+	step := 2
+	if call.t == json.Remove {
+		step = 1
+	}
+
+	var paths []*json.Path
+	for i := 1; i < len(call.Arguments); i += step {
+		if lit, ok := call.Arguments[i].(*Literal); !ok || lit.inner == nil {
+			return ctype{}, c.unsupported(call)
+		}
+		jp, err := c.jsonExtractPath(call.Arguments[i])
+		if err != nil {
+			return ctype{}, err
+		}
+		if jp.ContainsWildcards() {
+			return ctype{}, errInvalidPathForTransform
+		}
+		if call.t == json.Remove && jp.IsRoot() {
+			return ctype{}, errJSONVacuousPath
+		}
+		paths = append(paths, jp)
+
+		if call.t != json.Remove {
+			val, err := call.Arguments[i+1].compile(c)
+			if err != nil {
+				return ctype{}, err
+			}
+			if _, err = c.compileArgToJSON(val, 1); err != nil {
+				return ctype{}, err
+			}
+		}
+	}
+
+	c.asm.Fn_JSON_MODIFY(call.Method, call.t, paths)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.TypeJSON, Col: collationJSON, Flag: doc.Flag | flagNullable}, nil
+}
@@ -18,7 +18,11 @@ package evalengine
 
 import (
 	"bytes"
+	"math"
+	"strconv"
+	"strings"
 
+	"vitess.io/vitess/go/hack"
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/mysql/collations/charset"
 	"vitess.io/vitess/go/sqltypes"
@@ -868,14 +872,36 @@ func (call builtinTrim) eval(env *ExpressionEnv) (eval, error) {
 
 	switch call.trim {
 	case sqlparser.LeadingTrimType:
-		return newEvalText(bytes.TrimPrefix(text.bytes, pat.bytes), text.col), nil
+		return newEvalText(trimPrefix(text.bytes, pat.bytes), text.col), nil
 	case sqlparser.TrailingTrimType:
-		return newEvalText(bytes.TrimSuffix(text.bytes, pat.bytes), text.col), nil
+		return newEvalText(trimSuffix(text.bytes, pat.bytes), text.col), nil
 	default:
-		return newEvalText(bytes.TrimPrefix(bytes.TrimSuffix(text.bytes, pat.bytes), pat.bytes), text.col), nil
+		return newEvalText(trimPrefix(trimSuffix(text.bytes, pat.bytes), pat.bytes), text.col), nil
 	}
 }
 
+// trimPrefix removes all the leading repetitions of pat from b.
+func trimPrefix(b, pat []byte) []byte {
+	if len(pat) == 0 {
+		return b
+	}
+	for bytes.HasPrefix(b, pat) {
+		b = b[len(pat):]
+	}
+	return b
+}
+
+// trimSuffix removes all the trailing repetitions of pat from b.
+func trimSuffix(b, pat []byte) []byte {
+	if len(pat) == 0 {
+		return b
+	}
+	for bytes.HasSuffix(b, pat) {
+		b = b[:len(b)-len(pat)]
+	}
+	return b
+}
+
 func (call builtinTrim) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
 	_, f1 := call.Arguments[0].typeof(env, fields)
 	return sqltypes.VarChar, f1
@@ -1230,3 +1256,1023 @@ func (call *builtinConcatWs) compile(c *compiler) (ctype, error) {
 
 	return ctype{Type: tt, Flag: args[0].Flag, Col: tc}, nil
 }
+
+type (
+	builtinSubstring struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinSubstringIndex struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinLocate struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinReplace struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinInsert struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinReverse struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinSpace struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinField struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinElt struct {
+		CallExpr
+		collate collations.ID
+	}
+
+	builtinFormat struct {
+		CallExpr
+		collate collations.ID
+	}
+)
+
+var _ Expr = (*builtinSubstring)(nil)
+var _ Expr = (*builtinSubstringIndex)(nil)
+var _ Expr = (*builtinLocate)(nil)
+var _ Expr = (*builtinReplace)(nil)
+var _ Expr = (*builtinInsert)(nil)
+var _ Expr = (*builtinReverse)(nil)
+var _ Expr = (*builtinSpace)(nil)
+var _ Expr = (*builtinField)(nil)
+var _ Expr = (*builtinElt)(nil)
+var _ Expr = (*builtinFormat)(nil)
+
+// substring returns the characters of b starting at the 1-based position pos,
+// which counts from the end of the string when negative, up to length characters.
+func substring(cs charset.Charset, b []byte, pos, length int64) []byte {
+	if pos == 0 || length <= 0 {
+		return nil
+	}
+	strLen := int64(charset.Length(cs, b))
+	if pos < 0 {
+		pos += strLen + 1
+		if pos < 1 {
+			return nil
+		}
+	}
+	if pos > strLen {
+		return nil
+	}
+	end := strLen
+	if length < strLen-pos+1 {
+		end = pos - 1 + length
+	}
+	return charset.Slice(cs, b, int(pos-1), int(end))
+}
+
+func (call *builtinSubstring) eval(env *ExpressionEnv) (eval, error) {
+	str, p, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if str == nil || p == nil {
+		return nil, nil
+	}
+
+	length := int64(math.MaxInt64)
+	if len(call.Arguments) > 2 {
+		l, err := call.Arguments[2].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if l == nil {
+			return nil, nil
+		}
+		length = evalToInt64(l).i
+	}
+
+	text, ok := str.(*evalBytes)
+	if !ok {
+		text, err = evalToVarchar(str, call.collate, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cs := text.col.Collation.Get().Charset()
+	return newEvalText(substring(cs, text.bytes, evalToInt64(p).i, length), text.col), nil
+}
+
+func (call *builtinSubstring) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, f1
+}
+
+func (call *builtinSubstring) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	p, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	var skip *jump
+	if len(call.Arguments) > 2 {
+		l, err := call.Arguments[2].compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		skip = c.compileNullCheck3(str, p, l)
+		_ = c.compileToInt64(l, 1)
+	} else {
+		skip = c.compileNullCheck2(str, p)
+	}
+
+	args := len(call.Arguments)
+	col := defaultCoercionCollation(c.cfg.Collation)
+	switch {
+	case str.isTextual():
+		col = str.Col
+	default:
+		c.asm.Convert_xc(args, sqltypes.VarChar, col.Collation, 0, false)
+	}
+	_ = c.compileToInt64(p, args-1)
+
+	c.asm.Fn_SUBSTRING(col, args > 2)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
+
+// substringIndex returns the part of b before the count-th occurrence of delim,
+// or after the count-th occurrence counting from the end when count is negative.
+// Like in MySQL, delim is matched byte by byte, so the match is case-sensitive.
+func substringIndex(b, delim []byte, count int64) []byte {
+	if count == 0 || len(delim) == 0 {
+		return nil
+	}
+	if count > 0 {
+		offset := 0
+		for ; count > 0; count-- {
+			idx := bytes.Index(b[offset:], delim)
+			if idx < 0 {
+				return b
+			}
+			offset += idx + len(delim)
+		}
+		return b[:offset-len(delim)]
+	}
+	end := len(b)
+	for ; count < 0; count++ {
+		idx := bytes.LastIndex(b[:end], delim)
+		if idx < 0 {
+			return b
+		}
+		end = idx
+	}
+	return b[end+len(delim):]
+}
+
+func (call *builtinSubstringIndex) eval(env *ExpressionEnv) (eval, error) {
+	str, d, cnt, err := call.arg3(env)
+	if err != nil {
+		return nil, err
+	}
+	if str == nil || d == nil || cnt == nil {
+		return nil, nil
+	}
+
+	text, ok := str.(*evalBytes)
+	if !ok {
+		text, err = evalToVarchar(str, call.collate, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	delim, err := evalToVarchar(d, text.col.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEvalText(substringIndex(text.bytes, delim.bytes, evalToInt64(cnt).i), text.col), nil
+}
+
+func (call *builtinSubstringIndex) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, f1
+}
+
+func (call *builtinSubstringIndex) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	delim, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	cnt, err := call.Arguments[2].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck3(str, delim, cnt)
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	switch {
+	case str.isTextual():
+		col = str.Col
+	default:
+		c.asm.Convert_xc(3, sqltypes.VarChar, col.Collation, 0, false)
+	}
+	if !delim.isTextual() || delim.Col.Collation != col.Collation {
+		c.asm.Convert_xce(2, sqltypes.VarChar, col.Collation)
+	}
+	_ = c.compileToInt64(cnt, 1)
+
+	c.asm.Fn_SUBSTRING_INDEX(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
+
+// locate returns the 1-based position of the first occurrence of substr in str
+// at or after the character position pos, or 0 if there is none. The search is
+// collation-aware, so it is case-insensitive for case-insensitive collations.
+func locate(coll collations.Collation, str, substr []byte, pos int64) int64 {
+	if pos < 1 {
+		return 0
+	}
+	cs := coll.Charset()
+	offset := 0
+	for i := int64(1); i < pos; i++ {
+		if offset >= len(str) {
+			return 0
+		}
+		_, size := cs.DecodeRune(str[offset:])
+		offset += size
+	}
+	for idx := pos; ; idx++ {
+		if coll.Collate(str[offset:], substr, true) == 0 {
+			return idx
+		}
+		if offset >= len(str) {
+			return 0
+		}
+		_, size := cs.DecodeRune(str[offset:])
+		offset += size
+	}
+}
+
+func (call *builtinLocate) eval(env *ExpressionEnv) (eval, error) {
+	sub, str, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil || str == nil {
+		return nil, nil
+	}
+
+	pos := int64(1)
+	if len(call.Arguments) > 2 {
+		p, err := call.Arguments[2].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			return nil, nil
+		}
+		pos = evalToInt64(p).i
+	}
+
+	local := collations.Local()
+	var ca collationAggregation
+	if err := ca.add(local, evalCollation(sub)); err != nil {
+		return nil, err
+	}
+	if err := ca.add(local, evalCollation(str)); err != nil {
+		return nil, err
+	}
+	tc := ca.result()
+	if tc.Coercibility == collations.CoerceNumeric {
+		tc = defaultCoercionCollation(call.collate)
+	}
+
+	subtext, err := evalToVarchar(sub, tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	text, err := evalToVarchar(str, tc.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEvalInt64(locate(tc.Collation.Get(), text.bytes, subtext.bytes, pos)), nil
+}
+
+func (call *builtinLocate) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	return sqltypes.Int64, f1 | f2
+}
+
+func (call *builtinLocate) compile(c *compiler) (ctype, error) {
+	sub, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	str, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	var skip *jump
+	var pos ctype
+	args := len(call.Arguments)
+	if args > 2 {
+		pos, err = call.Arguments[2].compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		skip = c.compileNullCheck3(sub, str, pos)
+	} else {
+		skip = c.compileNullCheck2(sub, str)
+	}
+
+	local := collations.Local()
+	var ca collationAggregation
+	if err := ca.add(local, sub.Col); err != nil {
+		return ctype{}, err
+	}
+	if err := ca.add(local, str.Col); err != nil {
+		return ctype{}, err
+	}
+	tc := ca.result()
+	if tc.Coercibility == collations.CoerceNumeric {
+		tc = defaultCoercionCollation(call.collate)
+	}
+
+	if !sub.isTextual() || sub.Col.Collation != tc.Collation {
+		c.asm.Convert_xce(args, sqltypes.VarChar, tc.Collation)
+	}
+	if !str.isTextual() || str.Col.Collation != tc.Collation {
+		c.asm.Convert_xce(args-1, sqltypes.VarChar, tc.Collation)
+	}
+	if args > 2 {
+		_ = c.compileToInt64(pos, 1)
+	}
+
+	c.asm.Fn_LOCATE(tc.Collation.Get(), args > 2)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: flagNullable}, nil
+}
+
+func replaceAll(str, from, to []byte) []byte {
+	if len(from) == 0 {
+		return str
+	}
+	return bytes.ReplaceAll(str, from, to)
+}
+
+func (call *builtinReplace) eval(env *ExpressionEnv) (eval, error) {
+	str, f, t, err := call.arg3(env)
+	if err != nil {
+		return nil, err
+	}
+	if str == nil || f == nil || t == nil {
+		return nil, nil
+	}
+
+	text, ok := str.(*evalBytes)
+	if !ok {
+		text, err = evalToVarchar(str, call.collate, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	from, err := evalToVarchar(f, text.col.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+	to, err := evalToVarchar(t, text.col.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return newEvalText(replaceAll(text.bytes, from.bytes, to.bytes), text.col), nil
+}
+
+func (call *builtinReplace) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, f1
+}
+
+func (call *builtinReplace) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	from, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	to, err := call.Arguments[2].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck3(str, from, to)
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	switch {
+	case str.isTextual():
+		col = str.Col
+	default:
+		c.asm.Convert_xc(3, sqltypes.VarChar, col.Collation, 0, false)
+	}
+	if !from.isTextual() || from.Col.Collation != col.Collation {
+		c.asm.Convert_xce(2, sqltypes.VarChar, col.Collation)
+	}
+	if !to.isTextual() || to.Col.Collation != col.Collation {
+		c.asm.Convert_xce(1, sqltypes.VarChar, col.Collation)
+	}
+
+	c.asm.Fn_REPLACE(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
+
+// insertString replaces the length characters of str starting at the 1-based
+// position pos with newstr. The string is returned unchanged if pos is not
+// within its bounds.
+func insertString(cs charset.Charset, str, newstr []byte, pos, length int64) []byte {
+	strLen := int64(charset.Length(cs, str))
+	if pos < 1 || pos > strLen {
+		return str
+	}
+	if length < 0 || length > strLen-pos+1 {
+		length = strLen - pos + 1
+	}
+	head := charset.Slice(cs, str, 0, int(pos-1))
+	tail := charset.Slice(cs, str, int(pos-1+length), int(strLen))
+
+	res := make([]byte, 0, len(head)+len(newstr)+len(tail))
+	res = append(res, head...)
+	res = append(res, newstr...)
+	return append(res, tail...)
+}
+
+func (call *builtinInsert) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	for _, arg := range args {
+		if arg == nil {
+			return nil, nil
+		}
+	}
+
+	text, ok := args[0].(*evalBytes)
+	if !ok {
+		text, err = evalToVarchar(args[0], call.collate, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	newstr, err := evalToVarchar(args[3], text.col.Collation, true)
+	if err != nil {
+		return nil, err
+	}
+
+	cs := text.col.Collation.Get().Charset()
+	pos := evalToInt64(args[1]).i
+	length := evalToInt64(args[2]).i
+	return newEvalText(insertString(cs, text.bytes, newstr.bytes, pos, length), text.col), nil
+}
+
+func (call *builtinInsert) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, f1
+}
+
+func (call *builtinInsert) compile(c *compiler) (ctype, error) {
+	args := make([]ctype, 0, len(call.Arguments))
+	skips := make([]*jump, 0, len(call.Arguments))
+	for i, arg := range call.Arguments {
+		a, err := arg.compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		skips = append(skips, c.compileNullCheckArg(a, i))
+		args = append(args, a)
+	}
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	switch {
+	case args[0].isTextual():
+		col = args[0].Col
+	default:
+		c.asm.Convert_xc(4, sqltypes.VarChar, col.Collation, 0, false)
+	}
+	_ = c.compileToInt64(args[1], 3)
+	_ = c.compileToInt64(args[2], 2)
+	if !args[3].isTextual() || args[3].Col.Collation != col.Collation {
+		c.asm.Convert_xce(1, sqltypes.VarChar, col.Collation)
+	}
+
+	c.asm.Fn_INSERT(col)
+	c.asm.jumpDestination(skips...)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
+
+// reverse returns the characters of b in reverse order.
+func reverse(cs charset.Charset, b []byte) []byte {
+	res := make([]byte, len(b))
+	end := len(res)
+	for len(b) > 0 {
+		_, size := cs.DecodeRune(b)
+		if size < 1 {
+			size = 1
+		}
+		end -= size
+		copy(res[end:], b[:size])
+		b = b[size:]
+	}
+	return res
+}
+
+func (call *builtinReverse) eval(env *ExpressionEnv) (eval, error) {
+	str, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if str == nil {
+		return nil, nil
+	}
+
+	text, ok := str.(*evalBytes)
+	if !ok {
+		text, err = evalToVarchar(str, call.collate, true)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	cs := text.col.Collation.Get().Charset()
+	return newEvalText(reverse(cs, text.bytes), text.col), nil
+}
+
+func (call *builtinReverse) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, f1
+}
+
+func (call *builtinReverse) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(str)
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	switch {
+	case str.isTextual():
+		col = str.Col
+	default:
+		c.asm.Convert_xc(1, sqltypes.VarChar, col.Collation, 0, false)
+	}
+
+	c.asm.Fn_REVERSE(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: str.Flag}, nil
+}
+
+func (call *builtinSpace) eval(env *ExpressionEnv) (eval, error) {
+	arg, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if arg == nil {
+		return nil, nil
+	}
+
+	n := evalToInt64(arg).i
+	if n < 0 {
+		n = 0
+	}
+	if !validMaxLength(1, n) {
+		return nil, nil
+	}
+	return newEvalText(bytes.Repeat([]byte{' '}, int(n)), defaultCoercionCollation(call.collate)), nil
+}
+
+func (call *builtinSpace) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	call.Arguments[0].typeof(env, fields)
+	return sqltypes.VarChar, flagNullable
+}
+
+func (call *builtinSpace) compile(c *compiler) (ctype, error) {
+	n, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(n)
+	_ = c.compileToInt64(n, 1)
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	c.asm.Fn_SPACE(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
+
+func (call *builtinField) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	if args[0] == nil {
+		return newEvalInt64(0), nil
+	}
+
+	// If all the arguments are strings, they are compared as strings
+	// using their aggregated collation. Otherwise, they are compared as
+	// floating point numbers.
+	local := collations.Local()
+	var ca collationAggregation
+	asStrings := true
+	for _, arg := range args {
+		if arg == nil {
+			continue
+		}
+		if _, ok := arg.(*evalBytes); !ok {
+			asStrings = false
+			break
+		}
+		if err := ca.add(local, evalCollation(arg)); err != nil {
+			return nil, err
+		}
+	}
+
+	if asStrings {
+		tc := ca.result()
+		str, err := evalToVarchar(args[0], tc.Collation, true)
+		if err != nil {
+			return nil, err
+		}
+		coll := tc.Collation.Get()
+		for i, arg := range args[1:] {
+			if arg == nil {
+				continue
+			}
+			candidate, err := evalToVarchar(arg, tc.Collation, true)
+			if err != nil {
+				return nil, err
+			}
+			if coll.Collate(str.bytes, candidate.bytes, false) == 0 {
+				return newEvalInt64(int64(i + 1)), nil
+			}
+		}
+		return newEvalInt64(0), nil
+	}
+
+	f, _ := evalToFloat(args[0])
+	for i, arg := range args[1:] {
+		if arg == nil {
+			continue
+		}
+		candidate, _ := evalToFloat(arg)
+		if f.f == candidate.f {
+			return newEvalInt64(int64(i + 1)), nil
+		}
+	}
+	return newEvalInt64(0), nil
+}
+
+func (call *builtinField) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	for _, arg := range call.Arguments {
+		arg.typeof(env, fields)
+	}
+	return sqltypes.Int64, 0
+}
+
+func (call *builtinField) compile(c *compiler) (ctype, error) {
+	local := collations.Local()
+	var ca collationAggregation
+	asStrings := true
+
+	args := make([]ctype, 0, len(call.Arguments))
+	for _, arg := range call.Arguments {
+		a, err := arg.compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		args = append(args, a)
+
+		if a.Type == sqltypes.Null {
+			continue
+		}
+		if !a.isTextual() {
+			asStrings = false
+			continue
+		}
+		if err := ca.add(local, a.Col); err != nil {
+			return ctype{}, err
+		}
+	}
+
+	tc := ca.result()
+	for i, arg := range args {
+		offset := len(args) - i
+		skipArg := c.compileNullCheckOffset(arg, offset)
+		if asStrings {
+			if !arg.isTextual() || arg.Col.Collation != tc.Collation {
+				c.asm.Convert_xce(offset, sqltypes.VarChar, tc.Collation)
+			}
+		} else {
+			_ = c.compileToFloat(arg, offset)
+		}
+		c.asm.jumpDestination(skipArg)
+	}
+
+	if asStrings {
+		c.asm.Fn_FIELD_c(len(args), tc.Collation.Get())
+	} else {
+		c.asm.Fn_FIELD_f(len(args))
+	}
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric}, nil
+}
+
+func (call *builtinElt) eval(env *ExpressionEnv) (eval, error) {
+	args, err := call.args(env)
+	if err != nil {
+		return nil, err
+	}
+	if args[0] == nil {
+		return nil, nil
+	}
+
+	local := collations.Local()
+	var ca collationAggregation
+	tt := sqltypes.VarChar
+	for _, arg := range args[1:] {
+		if arg != nil {
+			tt = concatSQLType(arg.SQLType(), tt)
+		}
+		if err := ca.add(local, evalCollation(arg)); err != nil {
+			return nil, err
+		}
+	}
+
+	tc := ca.result()
+	// If we only had numbers, we instead fall back to the default
+	// collation instead of using the numeric collation.
+	if tc.Coercibility == collations.CoerceNumeric {
+		tc = defaultCoercionCollation(call.collate)
+	}
+
+	n := evalToInt64(args[0]).i
+	if n < 1 || n >= int64(len(args)) {
+		return nil, nil
+	}
+
+	switch a := args[n].(type) {
+	case nil:
+		return nil, nil
+	case *evalBytes:
+		buf, err := concatConvert(nil, a, tc)
+		if err != nil {
+			return nil, err
+		}
+		return newEvalRaw(tt, buf, tc), nil
+	default:
+		text, err := evalToVarchar(a, tc.Collation, true)
+		if err != nil {
+			return nil, err
+		}
+		return newEvalRaw(tt, text.bytes, tc), nil
+	}
+}
+
+func (call *builtinElt) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	tt := sqltypes.VarChar
+	call.Arguments[0].typeof(env, fields)
+	for _, arg := range call.Arguments[1:] {
+		argt, _ := arg.typeof(env, fields)
+		tt = concatSQLType(argt, tt)
+	}
+	return tt, flagNullable
+}
+
+func (call *builtinElt) compile(c *compiler) (ctype, error) {
+	n, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(n)
+	_ = c.compileToInt64(n, 1)
+
+	local := collations.Local()
+	var ca collationAggregation
+	tt := sqltypes.VarChar
+
+	args := make([]ctype, 0, len(call.Arguments)-1)
+	for _, arg := range call.Arguments[1:] {
+		a, err := arg.compile(c)
+		if err != nil {
+			return ctype{}, err
+		}
+		if a.Type != sqltypes.Null {
+			tt = concatSQLType(a.Type, tt)
+		}
+		if err := ca.add(local, a.Col); err != nil {
+			return ctype{}, err
+		}
+		args = append(args, a)
+	}
+
+	tc := ca.result()
+	// If we only had numbers, we instead fall back to the default
+	// collation instead of using the numeric collation.
+	if tc.Coercibility == collations.CoerceNumeric {
+		tc = defaultCoercionCollation(call.collate)
+	}
+
+	for i, arg := range args {
+		offset := len(args) - i
+		skipArg := c.compileNullCheckOffset(arg, offset)
+		switch arg.Type {
+		case sqltypes.VarBinary, sqltypes.Binary, sqltypes.Blob:
+			if tc.Collation != collations.CollationBinaryID {
+				c.asm.Convert_xce(offset, arg.Type, tc.Collation)
+			}
+		case sqltypes.VarChar, sqltypes.Char, sqltypes.Text:
+			fromCharset := arg.Col.Collation.Get().Charset()
+			toCharset := tc.Collation.Get().Charset()
+			if fromCharset != toCharset && !toCharset.IsSuperset(fromCharset) {
+				c.asm.Convert_xce(offset, arg.Type, tc.Collation)
+			}
+		case sqltypes.Null:
+		default:
+			c.asm.Convert_xce(offset, arg.Type, tc.Collation)
+		}
+		c.asm.jumpDestination(skipArg)
+	}
+
+	c.asm.Fn_ELT(len(args), tt, tc)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: tt, Col: tc, Flag: flagNullable}, nil
+}
+
+// maxFormatDecimals is the maximum number of decimal places that FORMAT
+// will output, which is the maximum scale of a DECIMAL in MySQL.
+const maxFormatDecimals = 30
+
+func formatDecimals(d int64) int32 {
+	switch {
+	case d < 0:
+		return 0
+	case d > maxFormatDecimals:
+		return maxFormatDecimals
+	default:
+		return int32(d)
+	}
+}
+
+func formatFloat(f float64, dec int32) []byte {
+	p := math.Pow(10, float64(dec))
+	if r := math.Round(f*p) / p; !math.IsInf(r, 0) && !math.IsNaN(r) {
+		f = r
+	}
+	return strconv.AppendFloat(nil, f, 'f', int(dec), 64)
+}
+
+// formatThousands adds a comma between every group of three digits in the
+// integral part of num, as FORMAT does for the en_US locale.
+func formatThousands(num []byte) []byte {
+	buf := make([]byte, 0, len(num)+len(num)/3)
+	if len(num) > 0 && num[0] == '-' {
+		buf = append(buf, '-')
+		num = num[1:]
+	}
+	integral := num
+	if idx := bytes.IndexByte(num, '.'); idx >= 0 {
+		integral = num[:idx]
+	}
+	for i, c := range integral {
+		if i > 0 && (len(integral)-i)%3 == 0 {
+			buf = append(buf, ',')
+		}
+		buf = append(buf, c)
+	}
+	return append(buf, num[len(integral):]...)
+}
+
+// checkFormatLocale returns an error for any locale other than en_US, which
+// is the only one FORMAT supports. A NULL locale falls back to en_US.
+func checkFormatLocale(locale eval) error {
+	if locale == nil {
+		return nil
+	}
+	if name := locale.ToRawBytes(); !strings.EqualFold(hack.String(name), "en_US") {
+		return vterrors.Errorf(vtrpcpb.Code_UNIMPLEMENTED, "unsupported locale for FORMAT: '%s'", name)
+	}
+	return nil
+}
+
+func (call *builtinFormat) eval(env *ExpressionEnv) (eval, error) {
+	x, d, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if len(call.Arguments) > 2 {
+		locale, err := call.Arguments[2].eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkFormatLocale(locale); err != nil {
+			return nil, err
+		}
+	}
+	if x == nil || d == nil {
+		return nil, nil
+	}
+
+	dec := formatDecimals(evalToInt64(d).i)
+
+	var num []byte
+	switch x := x.(type) {
+	case *evalInt64, *evalUint64, *evalDecimal:
+		num = []byte(evalToDecimal(x, 0, 0).dec.StringFixed(dec))
+	default:
+		f, _ := evalToFloat(x)
+		num = formatFloat(f.f, dec)
+	}
+	return newEvalText(formatThousands(num), defaultCoercionCollation(call.collate)), nil
+}
+
+func (call *builtinFormat) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	_, f1 := call.Arguments[0].typeof(env, fields)
+	_, f2 := call.Arguments[1].typeof(env, fields)
+	return sqltypes.VarChar, f1 | f2
+}
+
+func (call *builtinFormat) compile(c *compiler) (ctype, error) {
+	x, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	d, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	args := len(call.Arguments)
+	if args > 2 {
+		if _, err := call.Arguments[2].compile(c); err != nil {
+			return ctype{}, err
+		}
+		c.asm.Fn_FORMAT_locale()
+	}
+
+	skip := c.compileNullCheck2(x, d)
+
+	switch x.Type {
+	case sqltypes.Int64, sqltypes.Uint64, sqltypes.Decimal:
+		_ = c.compileToDecimal(x, 2)
+	default:
+		_ = c.compileToFloat(x, 2)
+	}
+	_ = c.compileToInt64(d, 1)
+
+	col := defaultCoercionCollation(c.cfg.Collation)
+	c.asm.Fn_FORMAT(col)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.VarChar, Col: col, Flag: flagNullable}, nil
+}
@@ -148,6 +148,25 @@ type (
 		unit    datetime.IntervalType
 		collate collations.ID
 	}
+
+	builtinDateDiff struct {
+		CallExpr
+	}
+
+	builtinTimestampDiff struct {
+		CallExpr
+		unit datetime.IntervalType
+	}
+
+	builtinLastDay struct {
+		CallExpr
+	}
+
+	builtinStrToDate struct {
+		CallExpr
+		tt   sqltypes.Type
+		prec int
+	}
 )
 
 var _ Expr = (*builtinNow)(nil)
@@ -176,6 +195,11 @@ var _ Expr = (*builtinWeekDay)(nil)
 var _ Expr = (*builtinWeekOfYear)(nil)
 var _ Expr = (*builtinYear)(nil)
 var _ Expr = (*builtinYearWeek)(nil)
+var _ Expr = (*builtinDateMath)(nil)
+var _ Expr = (*builtinDateDiff)(nil)
+var _ Expr = (*builtinTimestampDiff)(nil)
+var _ Expr = (*builtinLastDay)(nil)
+var _ Expr = (*builtinStrToDate)(nil)
 
 func (call *builtinNow) eval(env *ExpressionEnv) (eval, error) {
 	now := env.time(call.utc)
@@ -1758,11 +1782,13 @@ func (call *builtinDateMath) compile(c *compiler) (ctype, error) {
 	}
 
 	// TODO: constant propagation
-	_, err = call.Arguments[1].compile(c)
+	itv, err := call.Arguments[1].compile(c)
 	if err != nil {
 		return ctype{}, err
 	}
 
+	skip := c.compileNullCheck2(date, itv)
+
 	var ret ctype
 	ret.Flag = date.Flag | flagNullable
 	ret.Col = collationBinary
@@ -1782,5 +1808,252 @@ func (call *builtinDateMath) compile(c *compiler) (ctype, error) {
 		ret.Col = defaultCoercionCollation(c.cfg.Collation)
 		c.asm.Fn_DATEADD_s(call.unit, call.sub, ret.Col)
 	}
+	c.asm.jumpDestination(skip)
 	return ret, nil
 }
+
+func (call *builtinDateDiff) eval(env *ExpressionEnv) (eval, error) {
+	date1, date2, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if date1 == nil || date2 == nil {
+		return nil, nil
+	}
+
+	d1 := evalToDate(date1)
+	if d1 == nil || d1.dt.Date.IsZero() {
+		return nil, nil
+	}
+	d2 := evalToDate(date2)
+	if d2 == nil || d2.dt.Date.IsZero() {
+		return nil, nil
+	}
+	return newEvalInt64(int64(d1.dt.Date.DayNumber() - d2.dt.Date.DayNumber())), nil
+}
+
+func (call *builtinDateDiff) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinDateDiff) compile(c *compiler) (ctype, error) {
+	date1, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	date2, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(date1, date2)
+
+	switch date1.Type {
+	case sqltypes.Date, sqltypes.Datetime:
+	default:
+		c.asm.Convert_xD_nz(2)
+	}
+
+	switch date2.Type {
+	case sqltypes.Date, sqltypes.Datetime:
+	default:
+		c.asm.Convert_xD_nz(1)
+	}
+
+	c.asm.Fn_DATEDIFF()
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: flagNullable}, nil
+}
+
+func (call *builtinTimestampDiff) eval(env *ExpressionEnv) (eval, error) {
+	date1, date2, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if date1 == nil || date2 == nil {
+		return nil, nil
+	}
+
+	dt1 := evalToDateTime(date1, -1)
+	if dt1 == nil || dt1.dt.Date.IsZero() {
+		return nil, nil
+	}
+	dt2 := evalToDateTime(date2, -1)
+	if dt2 == nil || dt2.dt.Date.IsZero() {
+		return nil, nil
+	}
+	diff, err := datetime.TimestampDiff(dt1.dt, dt2.dt, call.unit)
+	if err != nil {
+		return nil, err
+	}
+	return newEvalInt64(diff), nil
+}
+
+func (call *builtinTimestampDiff) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	return sqltypes.Int64, flagNullable
+}
+
+func (call *builtinTimestampDiff) compile(c *compiler) (ctype, error) {
+	date1, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	date2, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(date1, date2)
+
+	switch date1.Type {
+	case sqltypes.Date, sqltypes.Datetime:
+	default:
+		c.asm.Convert_xDT_nz(2, -1)
+	}
+
+	switch date2.Type {
+	case sqltypes.Date, sqltypes.Datetime:
+	default:
+		c.asm.Convert_xDT_nz(1, -1)
+	}
+
+	c.asm.Fn_TIMESTAMPDIFF(call.unit)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Int64, Col: collationNumeric, Flag: flagNullable}, nil
+}
+
+func (call *builtinLastDay) eval(env *ExpressionEnv) (eval, error) {
+	date, err := call.arg1(env)
+	if err != nil {
+		return nil, err
+	}
+	if date == nil {
+		return nil, nil
+	}
+	d := evalToDate(date)
+	if d == nil || d.dt.Date.IsZero() {
+		return nil, nil
+	}
+	return newEvalDate(d.dt.Date.LastDay()), nil
+}
+
+func (call *builtinLastDay) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	return sqltypes.Date, flagNullable
+}
+
+func (call *builtinLastDay) compile(c *compiler) (ctype, error) {
+	arg, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck1(arg)
+
+	switch arg.Type {
+	case sqltypes.Date, sqltypes.Datetime:
+	default:
+		c.asm.Convert_xD_nz(1)
+	}
+
+	c.asm.Fn_LAST_DAY()
+	c.asm.jumpDestination(skip)
+	return ctype{Type: sqltypes.Date, Col: collationBinary, Flag: arg.Flag | flagNullable}, nil
+}
+
+// strToDateType returns the type and precision of the values returned by STR_TO_DATE
+// for the given format: like in MySQL, this depends on whether a constant format
+// contains date specifiers, time specifiers or both.
+func strToDateType(format Expr) (sqltypes.Type, int) {
+	lit, ok := format.(*Literal)
+	if !ok || lit.inner == nil {
+		return sqltypes.Datetime, datetime.DefaultPrecision
+	}
+
+	f, err := datetime.New(evalToBinary(lit.inner).string())
+	if err != nil {
+		return sqltypes.Datetime, datetime.DefaultPrecision
+	}
+
+	var prec int
+	hasDate, hasTime, hasFrac := f.Parts()
+	if hasFrac {
+		prec = datetime.DefaultPrecision
+	}
+
+	switch {
+	case hasDate && !hasTime:
+		return sqltypes.Date, 0
+	case hasTime && !hasDate:
+		return sqltypes.Time, prec
+	default:
+		return sqltypes.Datetime, prec
+	}
+}
+
+func strToDate(str, format string, tt sqltypes.Type, prec int) eval {
+	f, err := datetime.New(format)
+	if err != nil {
+		return nil
+	}
+
+	dt, _, ok := f.ParseLenient(str, prec)
+	if !ok {
+		return nil
+	}
+
+	switch tt {
+	case sqltypes.Date:
+		return newEvalDate(dt.Date)
+	case sqltypes.Time:
+		return newEvalTime(dt.Time, prec)
+	default:
+		return newEvalDateTime(dt, prec)
+	}
+}
+
+func (call *builtinStrToDate) eval(env *ExpressionEnv) (eval, error) {
+	str, format, err := call.arg2(env)
+	if err != nil {
+		return nil, err
+	}
+	if str == nil || format == nil {
+		return nil, nil
+	}
+	return strToDate(evalToBinary(str).string(), evalToBinary(format).string(), call.tt, call.prec), nil
+}
+
+func (call *builtinStrToDate) typeof(env *ExpressionEnv, fields []*querypb.Field) (sqltypes.Type, typeFlag) {
+	return call.tt, flagNullable
+}
+
+func (call *builtinStrToDate) compile(c *compiler) (ctype, error) {
+	str, err := call.Arguments[0].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	format, err := call.Arguments[1].compile(c)
+	if err != nil {
+		return ctype{}, err
+	}
+
+	skip := c.compileNullCheck2(str, format)
+
+	switch str.Type {
+	case sqltypes.VarChar, sqltypes.VarBinary:
+	default:
+		c.asm.Convert_xb(2, sqltypes.VarBinary, 0, false)
+	}
+
+	switch format.Type {
+	case sqltypes.VarChar, sqltypes.VarBinary:
+	default:
+		c.asm.Convert_xb(1, sqltypes.VarBinary, 0, false)
+	}
+
+	c.asm.Fn_STR_TO_DATE(call.tt, call.prec)
+	c.asm.jumpDestination(skip)
+	return ctype{Type: call.tt, Col: collationBinary, Flag: flagNullable}, nil
+}
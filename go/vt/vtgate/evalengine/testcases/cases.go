@@ -33,6 +33,8 @@ var Cases = []TestCase{
 	{Run: JSONPathOperations},
 	{Run: JSONArray},
 	{Run: JSONObject},
+	{Run: JSONContains},
+	{Run: JSONModify},
 	{Run: CharsetConversionOperators},
 	{Run: CaseExprWithPredicate},
 	{Run: CaseExprWithValue},
@@ -80,6 +82,16 @@ var Cases = []TestCase{
 	{Run: FnTrim},
 	{Run: FnConcat},
 	{Run: FnConcatWs},
+	{Run: FnSubstring},
+	{Run: FnSubstringIndex},
+	{Run: FnLocate},
+	{Run: FnReplace},
+	{Run: FnInsert},
+	{Run: FnReverse},
+	{Run: FnSpace},
+	{Run: FnField},
+	{Run: FnElt},
+	{Run: FnFormat},
 	{Run: FnHex},
 	{Run: FnUnhex},
 	{Run: FnCeil},
@@ -151,6 +163,10 @@ var Cases = []TestCase{
 	{Run: FnUUID},
 	{Run: FnUUIDToBin},
 	{Run: DateMath},
+	{Run: FnDateDiff},
+	{Run: FnTimestampDiff},
+	{Run: FnLastDay},
+	{Run: FnStrToDate},
 	{Run: RegexpLike},
 	{Run: RegexpInstr},
 	{Run: RegexpSubstr},
@@ -195,6 +211,48 @@ func JSONObject(yield Query) {
 	yield("JSON_OBJECT()", nil)
 }
 
+func JSONContains(yield Query) {
+	for _, obj := range inputJSONObjects {
+		for _, cand := range inputJSONObjects {
+			yield(fmt.Sprintf("JSON_CONTAINS('%s', '%s')", obj, cand), nil)
+		}
+		for _, cand := range inputJSONPrimitives {
+			yield(fmt.Sprintf("JSON_CONTAINS('%s', JSON_ARRAY(%s))", obj, cand), nil)
+		}
+		for _, path := range inputJSONPaths {
+			yield(fmt.Sprintf("JSON_CONTAINS('%s', '1', '%s')", obj, path), nil)
+			yield(fmt.Sprintf("JSON_CONTAINS('%s', '{\"d\": 4}', '%s')", obj, path), nil)
+		}
+	}
+	yield("JSON_CONTAINS(NULL, '1')", nil)
+	yield("JSON_CONTAINS('1', NULL)", nil)
+	yield("JSON_CONTAINS('[1]', '1', NULL)", nil)
+}
+
+func JSONModify(yield Query) {
+	for _, fn := range []string{"JSON_SET", "JSON_INSERT", "JSON_REPLACE"} {
+		for _, obj := range inputJSONObjects {
+			for _, path := range inputJSONPaths {
+				for _, val := range inputJSONPrimitives {
+					yield(fmt.Sprintf("%s('%s', '%s', %s)", fn, obj, path, val), nil)
+				}
+			}
+			yield(fmt.Sprintf("%s('%s', '$[5]', 1, '$.a', 2, '$.z', 3)", fn, obj), nil)
+		}
+		yield(fmt.Sprintf("%s(NULL, '$.a', 1)", fn), nil)
+		yield(fmt.Sprintf("%s('{}', NULL, 1)", fn), nil)
+	}
+
+	for _, obj := range inputJSONObjects {
+		for _, path := range inputJSONPaths {
+			yield(fmt.Sprintf("JSON_REMOVE('%s', '%s')", obj, path), nil)
+		}
+		yield(fmt.Sprintf("JSON_REMOVE('%s', '$[1]', '$.a', '$[0].a')", obj), nil)
+	}
+	yield("JSON_REMOVE(NULL, '$.a')", nil)
+	yield("JSON_REMOVE('{}', NULL)", nil)
+}
+
 func CharsetConversionOperators(yield Query) {
 	var introducers = []string{
 		"", "_latin1", "_utf8mb4", "_utf8", "_binary",
@@ -1449,6 +1507,172 @@ func FnConcatWs(yield Query) {
 	}
 }
 
+func FnSubstring(yield Query) {
+	positions := []string{"-10", "-3", "-1", "0", "1", "2", "10", "NULL", "'1.9'"}
+	lengths := []string{"-1", "0", "1", "1.9", "3", "NULL"}
+	for _, str := range inputStrings {
+		for _, pos := range positions {
+			yield(fmt.Sprintf("SUBSTRING(%s, %s)", str, pos), nil)
+			yield(fmt.Sprintf("SUBSTRING(%s FROM %s)", str, pos), nil)
+			for _, l := range lengths {
+				yield(fmt.Sprintf("SUBSTRING(%s, %s, %s)", str, pos, l), nil)
+				yield(fmt.Sprintf("MID(%s, %s, %s)", str, pos, l), nil)
+			}
+		}
+	}
+}
+
+func FnSubstringIndex(yield Query) {
+	delims := []string{"'.'", "'b'", "'B'", "''", "1", "NULL"}
+	counts := []string{"-3", "-1", "0", "1", "2", "NULL"}
+	strs := append([]string{"'www.mysql.com'", "'a.b.c.d'", "'abcabcabc'"}, inputStrings...)
+	for _, str := range strs {
+		for _, delim := range delims {
+			for _, cnt := range counts {
+				yield(fmt.Sprintf("SUBSTRING_INDEX(%s, %s, %s)", str, delim, cnt), nil)
+			}
+		}
+	}
+}
+
+func FnLocate(yield Query) {
+	positions := []string{"-1", "0", "1", "2", "3", "10", "NULL"}
+	for _, substr := range inputStrings {
+		for _, str := range inputStrings {
+			yield(fmt.Sprintf("LOCATE(%s, %s)", substr, str), nil)
+			yield(fmt.Sprintf("INSTR(%s, %s)", str, substr), nil)
+			yield(fmt.Sprintf("POSITION(%s IN %s)", substr, str), nil)
+		}
+	}
+
+	for _, substr := range []string{"'bar'", "'BAR'", "''", "'å'"} {
+		for _, str := range []string{"'foobarbar'", "'xbar'", "'Å å'", "_binary 'foobarbar'"} {
+			for _, pos := range positions {
+				yield(fmt.Sprintf("LOCATE(%s, %s, %s)", substr, str, pos), nil)
+			}
+		}
+	}
+}
+
+func FnReplace(yield Query) {
+	cases := []string{
+		"REPLACE('www.mysql.com', 'w', 'Ww')",
+		"REPLACE('www.mysql.com', 'W', 'Ww')",
+		"REPLACE('www.mysql.com', '', 'Ww')",
+		"REPLACE('aaaa', 'aa', 'a')",
+		"REPLACE('abcABCÅå', 'Å', 'å')",
+		"REPLACE(_binary 'abcabc', 'b', 'x')",
+	}
+	for _, q := range cases {
+		yield(q, nil)
+	}
+
+	for _, str := range inputStrings {
+		for _, from := range inputStrings {
+			for _, to := range []string{"'x'", "''", "NULL", "1"} {
+				yield(fmt.Sprintf("REPLACE(%s, %s, %s)", str, from, to), nil)
+			}
+		}
+	}
+}
+
+func FnInsert(yield Query) {
+	positions := []string{"-1", "0", "1", "2", "3", "10", "NULL"}
+	lengths := []string{"-1", "0", "1", "2", "100", "NULL"}
+	for _, str := range inputStrings {
+		for _, pos := range positions {
+			for _, l := range lengths {
+				yield(fmt.Sprintf("INSERT(%s, %s, %s, 'What')", str, pos, l), nil)
+			}
+		}
+	}
+
+	for _, str := range inputStrings {
+		for _, newstr := range inputStrings {
+			yield(fmt.Sprintf("INSERT(%s, 2, 1, %s)", str, newstr), nil)
+		}
+	}
+}
+
+func FnReverse(yield Query) {
+	for _, str := range inputStrings {
+		yield(fmt.Sprintf("REVERSE(%s)", str), nil)
+	}
+}
+
+func FnSpace(yield Query) {
+	counts := []string{"-1", "0", "1", "1.9", "3", "'3'", "NULL", "1073741825"}
+	for _, cnt := range counts {
+		yield(fmt.Sprintf("SPACE(%s)", cnt), nil)
+	}
+}
+
+func FnField(yield Query) {
+	cases := []string{
+		"FIELD('Bb', 'Aa', 'Bb', 'Cc', 'Dd', 'Ff')",
+		"FIELD('Gg', 'Aa', 'Bb', 'Cc', 'Dd', 'Ff')",
+		"FIELD('bb', 'Aa', 'Bb', 'Cc')",
+		"FIELD(NULL, 'Aa', NULL)",
+		"FIELD('Aa', NULL, 'Aa')",
+		"FIELD(1, 2, 3, 1)",
+		"FIELD(1.0, 2, 3, 1)",
+		"FIELD('1', 2, 3, 1)",
+		"FIELD('1.0', 2, 3, '1')",
+		"FIELD(_binary 'aa', 'AA', 'aa')",
+	}
+	for _, q := range cases {
+		yield(q, nil)
+	}
+
+	for _, str1 := range inputStrings {
+		for _, str2 := range inputStrings {
+			yield(fmt.Sprintf("FIELD(%s, %s)", str1, str2), nil)
+			yield(fmt.Sprintf("FIELD(%s, 'a', %s)", str1, str2), nil)
+		}
+	}
+}
+
+func FnElt(yield Query) {
+	indexes := []string{"-1", "0", "1", "2", "3", "4", "1.9", "'2'", "NULL"}
+	for _, idx := range indexes {
+		yield(fmt.Sprintf("ELT(%s, 'Aa', 'Bb', 'Cc')", idx), nil)
+		yield(fmt.Sprintf("ELT(%s, 'Aa', NULL, 3)", idx), nil)
+	}
+
+	for _, str1 := range inputStrings {
+		for _, str2 := range inputStrings {
+			yield(fmt.Sprintf("ELT(1, %s, %s)", str1, str2), nil)
+			yield(fmt.Sprintf("ELT(2, %s, %s)", str1, str2), nil)
+		}
+	}
+}
+
+func FnFormat(yield Query) {
+	decimals := []string{"-1", "0", "1", "2", "4", "31", "NULL", "'2'"}
+	nums := []string{
+		"12332.123456",
+		"12332.1",
+		"12332.2",
+		"-12332.2",
+		"12332",
+		"0",
+		"-0.5",
+		"1e10",
+		"1.5e-3",
+		"'1234567.891'",
+		"18446744073709551615",
+		"-9223372036854775808",
+		"999999999999999999999999.999999",
+		"NULL",
+	}
+	for _, num := range nums {
+		for _, d := range decimals {
+			yield(fmt.Sprintf("FORMAT(%s, %s)", num, d), nil)
+		}
+		yield(fmt.Sprintf("FORMAT(%s, 2, 'en_US')", num), nil)
+	}
+}
+
 func FnHex(yield Query) {
 	for _, str := range inputStrings {
 		yield(fmt.Sprintf("hex(%s)", str), nil)
@@ -1886,6 +2110,14 @@ func DateMath(yield Query) {
 		`TIMESTAMPADD(WEEK,1,'2003-01-02')`,
 		`TIMESTAMPADD(MONTH, 1, DATE '2024-03-30')`,
 		`TIMESTAMPADD(MONTH, 1, DATE '2024-03-31')`,
+		`DATE'2018-05-01' + INTERVAL 1 DAY`,
+		`INTERVAL 1 DAY + DATE'2018-05-01'`,
+		`DATE'2018-05-01' - INTERVAL 1 SECOND`,
+		`TIMESTAMP'2018-12-31 23:59:59' + INTERVAL 1 SECOND`,
+		`'2018-12-31 23:59:59.5' + INTERVAL '0.5' SECOND_MICROSECOND`,
+		`NULL + INTERVAL 1 DAY`,
+		`DATE'2018-05-01' + INTERVAL NULL DAY`,
+		`DATE_ADD(NULL, INTERVAL 1 DAY)`,
 	}
 
 	for _, q := range mysqlDocSamples {
@@ -1903,6 +2135,127 @@ func DateMath(yield Query) {
 	}
 }
 
+var temporalInputs = []string{
+	`DATE'2018-05-01'`,
+	`DATE'2000-02-29'`,
+	`TIMESTAMP'2020-12-31 23:59:59'`,
+	`TIMESTAMP'2020-12-31 23:59:59.123456'`,
+	`TIMESTAMP'2025-01-01 00:00:00'`,
+	`TIME'10:04:03'`,
+	`'2018-05-01'`,
+	`'2020-12-31 23:59:59'`,
+	`'2020-12-31 23:59:59.999'`,
+	`'0000-00-00'`,
+	`'0000-00-00 00:00:00'`,
+	`20250101`,
+	`20250101123456.5`,
+	`'pokemon trainers'`,
+	`NULL`,
+}
+
+func FnDateDiff(yield Query) {
+	mysqlDocSamples := []string{
+		`DATEDIFF('2007-12-31 23:59:59','2007-12-30')`,
+		`DATEDIFF('2010-11-30 23:59:59','2010-12-31')`,
+	}
+
+	for _, q := range mysqlDocSamples {
+		yield(q, nil)
+	}
+
+	for _, d1 := range temporalInputs {
+		for _, d2 := range temporalInputs {
+			yield(fmt.Sprintf("DATEDIFF(%s, %s)", d1, d2), nil)
+		}
+	}
+}
+
+func FnTimestampDiff(yield Query) {
+	mysqlDocSamples := []string{
+		`TIMESTAMPDIFF(MONTH,'2003-02-01','2003-05-01')`,
+		`TIMESTAMPDIFF(YEAR,'2002-05-01','2001-01-01')`,
+		`TIMESTAMPDIFF(MINUTE,'2003-02-01','2003-05-01 12:05:55')`,
+	}
+
+	for _, q := range mysqlDocSamples {
+		yield(q, nil)
+	}
+
+	units := []string{"MICROSECOND", "SECOND", "MINUTE", "HOUR", "DAY", "WEEK", "MONTH", "QUARTER", "YEAR"}
+	for _, unit := range units {
+		for _, d1 := range temporalInputs {
+			for _, d2 := range temporalInputs {
+				yield(fmt.Sprintf("TIMESTAMPDIFF(%s, %s, %s)", unit, d1, d2), nil)
+			}
+		}
+	}
+}
+
+func FnLastDay(yield Query) {
+	mysqlDocSamples := []string{
+		`LAST_DAY('2003-02-05')`,
+		`LAST_DAY('2004-02-05')`,
+		`LAST_DAY('2004-01-01 01:01:01')`,
+		`LAST_DAY('2003-03-32')`,
+	}
+
+	for _, q := range mysqlDocSamples {
+		yield(q, nil)
+	}
+
+	for _, d := range temporalInputs {
+		yield(fmt.Sprintf("LAST_DAY(%s)", d), nil)
+	}
+	for _, d := range inputConversions {
+		yield(fmt.Sprintf("LAST_DAY(%s)", d), nil)
+	}
+}
+
+func FnStrToDate(yield Query) {
+	mysqlDocSamples := []string{
+		`STR_TO_DATE('01,5,2013','%d,%m,%Y')`,
+		`STR_TO_DATE('May 1, 2013','%M %d,%Y')`,
+		`STR_TO_DATE('a09:30:17','a%h:%i:%s')`,
+		`STR_TO_DATE('a09:30:17','%h:%i:%s')`,
+		`STR_TO_DATE('09:30:17a','%h:%i:%s')`,
+		`STR_TO_DATE('abc','abc')`,
+		`STR_TO_DATE('9','%m')`,
+		`STR_TO_DATE('9','%s')`,
+		`STR_TO_DATE('2013-05-10 11:22:33.123456','%Y-%m-%d %H:%i:%s.%f')`,
+		`STR_TO_DATE('11:22:33.5','%H:%i:%s.%f')`,
+		`STR_TO_DATE('2013-02-30','%Y-%m-%d')`,
+		`STR_TO_DATE(NULL,'%Y-%m-%d')`,
+		`STR_TO_DATE('2013-05-10', NULL)`,
+	}
+
+	for _, q := range mysqlDocSamples {
+		yield(q, nil)
+	}
+
+	inputs := []string{
+		`'2013-05-10'`,
+		`'2013-05-10 11:22:33'`,
+		`'10/05/2013'`,
+		`'11:22:33 PM'`,
+		`20130510`,
+		`'pokemon trainers'`,
+	}
+	formats := []string{
+		`'%Y-%m-%d'`,
+		`'%Y-%m-%d %H:%i:%s'`,
+		`'%d/%m/%Y'`,
+		`'%r'`,
+		`'%Y%m%d'`,
+		`'%H:%i:%s'`,
+	}
+
+	for _, i := range inputs {
+		for _, f := range formats {
+			yield(fmt.Sprintf("STR_TO_DATE(%s, %s)", i, f), nil)
+		}
+	}
+}
+
 func RegexpLike(yield Query) {
 	mysqlDocSamples := []string{
 		`'Michael!' REGEXP '.*'`,
@@ -255,6 +255,8 @@ var inputTrimStrings = []string{
 	"\"a\"",
 	"\"abc\"",
 	"'abca'",
+	"'x'",
+	"'xxbarxx'",
 	"1",
 	"-1",
 	"0123",
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"strings"
 
+	"vitess.io/vitess/go/mysql/json"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -315,6 +316,53 @@ func (ast *astCompiler) translateFuncExpr(fn *sqlparser.FuncExpr) (Expr, error)
 			return nil, argError(method)
 		}
 		return &builtinConcatWs{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "mid":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinSubstring{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "substring_index":
+		if len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinSubstringIndex{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "instr":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		// INSTR is LOCATE with its arguments swapped
+		call = CallExpr{Arguments: TupleExpr{args[1], args[0]}, Method: "locate"}
+		return &builtinLocate{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "replace":
+		if len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinReplace{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "reverse":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinReverse{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "space":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinSpace{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "field":
+		if len(args) < 2 {
+			return nil, argError(method)
+		}
+		return &builtinField{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "elt":
+		if len(args) < 2 {
+			return nil, argError(method)
+		}
+		return &builtinElt{CallExpr: call, collate: ast.cfg.Collation}, nil
+	case "format":
+		if len(args) != 2 && len(args) != 3 {
+			return nil, argError(method)
+		}
+		return &builtinFormat{CallExpr: call, collate: ast.cfg.Collation}, nil
 	case "from_base64":
 		if len(args) != 1 {
 			return nil, argError(method)
@@ -465,6 +513,22 @@ func (ast *astCompiler) translateFuncExpr(fn *sqlparser.FuncExpr) (Expr, error)
 		default:
 			return nil, argError(method)
 		}
+	case "datediff":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		return &builtinDateDiff{CallExpr: call}, nil
+	case "last_day":
+		if len(args) != 1 {
+			return nil, argError(method)
+		}
+		return &builtinLastDay{CallExpr: call}, nil
+	case "str_to_date":
+		if len(args) != 2 {
+			return nil, argError(method)
+		}
+		tt, prec := strToDateType(args[1])
+		return &builtinStrToDate{CallExpr: call, tt: tt, prec: prec}, nil
 	case "inet_aton":
 		if len(args) != 1 {
 			return nil, argError(method)
@@ -673,6 +737,67 @@ func (ast *astCompiler) translateCallable(call sqlparser.Callable) (Expr, error)
 			Method:    "JSON_CONTAINS_PATH",
 		}}, nil
 
+	case *sqlparser.JSONContainsExpr:
+		if len(call.PathList) > 1 {
+			return nil, argError("json_contains")
+		}
+		exprs := []sqlparser.Expr{call.Target, call.Candidate}
+		exprs = append(exprs, call.PathList...)
+		args, err := ast.translateFuncArgs(exprs)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinJSONContains{CallExpr: CallExpr{
+			Arguments: args,
+			Method:    "JSON_CONTAINS",
+		}}, nil
+
+	case *sqlparser.JSONValueModifierExpr:
+		var t json.Transformation
+		var method string
+		switch call.Type {
+		case sqlparser.JSONSetType:
+			t, method = json.Set, "JSON_SET"
+		case sqlparser.JSONInsertType:
+			t, method = json.Insert, "JSON_INSERT"
+		case sqlparser.JSONReplaceType:
+			t, method = json.Replace, "JSON_REPLACE"
+		default:
+			return nil, translateExprNotSupported(call)
+		}
+
+		args := make([]Expr, 0, 1+2*len(call.Params))
+		doc, err := ast.translateExpr(call.JSONDoc)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, doc)
+		for _, param := range call.Params {
+			path, err := ast.translateExpr(param.Key)
+			if err != nil {
+				return nil, err
+			}
+			val, err := ast.translateExpr(param.Value)
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, path, val)
+		}
+		return &builtinJSONModify{CallExpr: CallExpr{
+			Arguments: args,
+			Method:    method,
+		}, t: t}, nil
+
+	case *sqlparser.JSONRemoveExpr:
+		args, err := ast.translateFuncArgs(append([]sqlparser.Expr{call.JSONDoc}, call.PathList...))
+		if err != nil {
+			return nil, err
+		}
+		return &builtinJSONModify{CallExpr: CallExpr{
+			Arguments: args,
+			Method:    "JSON_REMOVE",
+		}, t: json.Remove}, nil
+
 	case *sqlparser.JSONKeysExpr:
 		var args []Expr
 		doc, err := ast.translateExpr(call.JSONDoc)
@@ -744,6 +869,54 @@ func (ast *astCompiler) translateCallable(call sqlparser.Callable) (Expr, error)
 			trim:     call.Type,
 		}, nil
 
+	case *sqlparser.SubstrExpr:
+		exprs := []sqlparser.Expr{call.Name, call.From}
+		if call.To != nil {
+			exprs = append(exprs, call.To)
+		}
+		args, err := ast.translateFuncArgs(exprs)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinSubstring{
+			CallExpr: CallExpr{Arguments: args, Method: "SUBSTRING"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.LocateExpr:
+		exprs := []sqlparser.Expr{call.SubStr, call.Str}
+		if call.Pos != nil {
+			exprs = append(exprs, call.Pos)
+		}
+		args, err := ast.translateFuncArgs(exprs)
+		if err != nil {
+			return nil, err
+		}
+		return &builtinLocate{
+			CallExpr: CallExpr{Arguments: args, Method: "LOCATE"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.InsertExpr:
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.Str, call.Pos, call.Len, call.NewStr})
+		if err != nil {
+			return nil, err
+		}
+		return &builtinInsert{
+			CallExpr: CallExpr{Arguments: args, Method: "INSERT"},
+			collate:  ast.cfg.Collation,
+		}, nil
+
+	case *sqlparser.TimestampDiffExpr:
+		args, err := ast.translateFuncArgs([]sqlparser.Expr{call.Expr1, call.Expr2})
+		if err != nil {
+			return nil, err
+		}
+		return &builtinTimestampDiff{
+			CallExpr: CallExpr{Arguments: args, Method: "TIMESTAMPDIFF"},
+			unit:     call.Unit,
+		}, nil
+
 	case *sqlparser.IntervalDateExpr:
 		var err error
 		args := make([]Expr, 2)
@@ -32,6 +32,9 @@ const (
   <a href="/debug/queryz">Query Plan Stats</a><br>
   <a href="/debug/query_plans">Query Plans</a><br>
   <a href="/debug/scatter_stats">Scatter Query Statistics</a><br>
+  <br>
+  <!-- The div in the next line will be overwritten by the JavaScript spill statistics. -->
+  <div id="spill_stats"></div>
 </td>
 </tr>
 </table>
@@ -99,6 +102,15 @@ function drawQPSChart() {
         data.push(datum)
       }
       chart.draw(google.visualization.arrayToDataTable(data), options);
+
+      // Number of query executions and bytes spilled to disk by primitive.
+      var spills = input_data.VtgateSpills || {};
+      var spilledBytes = input_data.VtgateSpilledBytes || {};
+      var rows = Object.keys(spills).sort().map(function(primitive) {
+        return "<tr><td>" + primitive + "</td><td>" + spills[primitive] + "</td><td>" + (spilledBytes[primitive] || 0) + "</td></tr>";
+      });
+      document.getElementById("spill_stats").innerHTML =
+        "<table><tr><th>Spilling primitive</th><th>Spills</th><th>Spilled bytes</th></tr>" + rows.join("") + "</table>";
   })
 
   redraw();
@@ -62,11 +62,25 @@ func (e *Executor) newExecute(
 		return err
 	}
 
+	attributes := queryAttributes(bindVars)
+	logStats.QueryAttributes = attributes
+	if err := applyQueryAttributeTabletType(vcursor, attributes); err != nil {
+		return err
+	}
+
 	// 2: Parse and Validate query
 	stmt, reservedVars, err := parseAndValidateQuery(query)
 	if err != nil {
 		return err
 	}
+	modified, err := applyQueryAttributeDirectives(stmt, attributes)
+	if err != nil {
+		return err
+	}
+	if modified {
+		// The plan cache key must reflect the directives.
+		query = sqlparser.String(stmt)
+	}
 
 	// 3: Create a plan for the query
 	plan, err := e.getPlan(ctx, vcursor, query, stmt, comments, bindVars, reservedVars, e.normalize, logStats)
@@ -0,0 +1,748 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/slices"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+// inlineCTEs replaces every reference to a non-recursive common table expression
+// with a derived table holding the body of the CTE, and removes the WITH clauses
+// that are no longer needed. A CTE that is referenced more than once is inlined
+// once per reference, which is also what MySQL does when it merges a CTE into the
+// outer query. Recursive WITH clauses are left untouched on the root statement,
+// and are refused anywhere else.
+func inlineCTEs(stmt sqlparser.Statement) error {
+	var err error
+	_ = sqlparser.Rewrite(stmt, nil, func(cursor *sqlparser.Cursor) bool {
+		var with *sqlparser.With
+		switch node := cursor.Node().(type) {
+		case *sqlparser.Select:
+			with = node.With
+		case *sqlparser.Union:
+			with = node.With
+		case *sqlparser.Update:
+			with = node.With
+		case *sqlparser.Delete:
+			with = node.With
+		default:
+			return true
+		}
+		if with == nil {
+			return true
+		}
+		if with.Recursive {
+			if cursor.Node() != stmt {
+				err = vterrors.VT12001("recursive WITH expression in a subquery")
+				return false
+			}
+			return true
+		}
+
+		setWith(cursor.Node(), nil)
+		ctes := with.CTEs()
+		for i, cte := range ctes {
+			for _, later := range ctes[i+1:] {
+				replaceCTERefs(later.Subquery, cte)
+			}
+			replaceCTERefs(cursor.Node(), cte)
+		}
+		return true
+	})
+	return err
+}
+
+func setWith(node sqlparser.SQLNode, with *sqlparser.With) {
+	switch node := node.(type) {
+	case *sqlparser.Select:
+		node.With = with
+	case *sqlparser.Union:
+		node.With = with
+	case *sqlparser.Update:
+		node.With = with
+	case *sqlparser.Delete:
+		node.With = with
+	}
+}
+
+func getWith(node sqlparser.SQLNode) *sqlparser.With {
+	switch node := node.(type) {
+	case *sqlparser.Select:
+		return node.With
+	case *sqlparser.Union:
+		return node.With
+	case *sqlparser.Update:
+		return node.With
+	case *sqlparser.Delete:
+		return node.With
+	}
+	return nil
+}
+
+// materializedCTEs returns a copy of the statement if one of the common table
+// expressions of its WITH clause is referenced more than once. If the statement
+// ends up being sent to a single route, the copy is sent instead of the statement
+// with the inlined CTEs, so that MySQL materializes the CTE once instead of
+// evaluating it for every reference.
+func materializedCTEs(stmt sqlparser.SelectStatement) sqlparser.SelectStatement {
+	with := getWith(stmt)
+	if with == nil || with.Recursive {
+		return nil
+	}
+	for _, cte := range with.CTEs() {
+		if countCTERefs(stmt, cte.ID) > 1 {
+			return sqlparser.CloneSelectStatement(stmt)
+		}
+	}
+	return nil
+}
+
+// sendWithClause makes the route send the statement that still has its WITH
+// clause. It does nothing if the route changes the shape of the query it sends,
+// or if the primitive is not a route.
+func sendWithClause(prim engine.Primitive, stmt sqlparser.SelectStatement) {
+	route, ok := prim.(*engine.Route)
+	if !ok || len(route.OrderBy) > 0 || route.TruncateColumnCount > 0 {
+		return
+	}
+	route.Query, route.FieldQuery = withClauseQueries(stmt)
+}
+
+// withClauseQueries returns the query and the field query to send to a single
+// route for a statement that has a WITH clause
+func withClauseQueries(stmt sqlparser.SelectStatement) (string, string) {
+	stmt = sqlparser.CloneSelectStatement(stmt)
+	sqlparser.SafeRewrite(stmt, nil, func(cursor *sqlparser.Cursor) bool {
+		switch node := cursor.Node().(type) {
+		case sqlparser.SelectExpr:
+			removeKeyspaceFromSelectExpr(node)
+		case sqlparser.TableName:
+			cursor.Replace(sqlparser.TableName{
+				Name: node.Name,
+			})
+		}
+		return true
+	})
+	query := sqlparser.String(stmt)
+
+	// the impossible query of the statement itself does not have the WITH clause
+	with := getWith(stmt)
+	setWith(stmt, nil)
+	buffer := sqlparser.NewTrackedBuffer(sqlparser.FormatImpossibleQuery)
+	fieldQuery := sqlparser.String(with) + buffer.WriteNode(stmt).ParsedQuery().Query
+	return query, fieldQuery
+}
+
+// replaceCTERefs replaces all table references to the given CTE with a derived table
+func replaceCTERefs(node sqlparser.SQLNode, cte *sqlparser.CommonTableExpr) {
+	_ = sqlparser.Rewrite(node, func(cursor *sqlparser.Cursor) bool {
+		ate, ok := cursor.Node().(*sqlparser.AliasedTableExpr)
+		if !ok || !isCTERef(ate, cte.ID) {
+			return true
+		}
+		alias := ate.As
+		if alias.IsEmpty() {
+			alias = cte.ID
+		}
+		cursor.Replace(&sqlparser.AliasedTableExpr{
+			Expr:    &sqlparser.DerivedTable{Select: cloneCTEBody(cte.Subquery.Select)},
+			As:      alias,
+			Columns: sqlparser.CloneColumns(cte.Columns),
+		})
+		return false
+	}, nil)
+}
+
+// cloneCTEBody clones the body of a CTE for one of its references. The column
+// names are copied too: CloneSelectStatement keeps them shared, and the semantic
+// analysis binds every column name to a single table.
+func cloneCTEBody(stmt sqlparser.SelectStatement) sqlparser.SelectStatement {
+	clone := sqlparser.CloneSelectStatement(stmt)
+	_ = sqlparser.Rewrite(clone, func(cursor *sqlparser.Cursor) bool {
+		if col, ok := cursor.Node().(*sqlparser.ColName); ok {
+			newCol := *col
+			cursor.Replace(&newCol)
+		}
+		return true
+	}, nil)
+	return clone
+}
+
+// isCTERef returns true if the table expression is an unqualified reference to the CTE
+func isCTERef(ate *sqlparser.AliasedTableExpr, id sqlparser.IdentifierCS) bool {
+	tbl, ok := ate.Expr.(sqlparser.TableName)
+	return ok && tbl.Qualifier.IsEmpty() && tbl.Name.String() == id.String()
+}
+
+// countCTERefs returns the number of table references to the CTE found in the node
+func countCTERefs(node sqlparser.SQLNode, id sqlparser.IdentifierCS) int {
+	count := 0
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if ate, ok := node.(*sqlparser.AliasedTableExpr); ok && isCTERef(ate, id) {
+			count++
+		}
+		return true, nil
+	}, node)
+	return count
+}
+
+// recursiveCTE holds the pieces of a recursive common table expression
+type recursiveCTE struct {
+	id      sqlparser.IdentifierCS
+	columns sqlparser.Columns
+	seed    sqlparser.SelectStatement
+	term    *sqlparser.Select
+}
+
+// columnOffset returns the offset of the column in the CTE, or -1 if the column
+// does not belong to the CTE known under the given alias
+func (r *recursiveCTE) columnOffset(col *sqlparser.ColName, alias sqlparser.IdentifierCS) int {
+	if !col.Qualifier.IsEmpty() && (!col.Qualifier.Qualifier.IsEmpty() || col.Qualifier.Name.String() != alias.String()) {
+		return -1
+	}
+	for i, c := range r.columns {
+		if c.Equal(col.Name) {
+			return i
+		}
+	}
+	return -1
+}
+
+// gen4RecursiveCTEPlanner plans a statement of the form
+//
+//	WITH RECURSIVE cte AS (<seed> UNION ALL <term>) SELECT <cte columns> FROM cte [ORDER BY ...] [LIMIT ...]
+//
+// The seed and the recursive term are planned as independent queries. In the
+// recursive term, the reference to the CTE is removed, and its columns are
+// replaced by bind variables that engine.RecurseCTE fills in with each row
+// produced by the previous iteration.
+func gen4RecursiveCTEPlanner(
+	plannerVersion querypb.ExecuteOptions_PlannerVersion,
+	stmt sqlparser.SelectStatement,
+	reservedVars *sqlparser.ReservedVars,
+	vschema plancontext.VSchema,
+) (*planResult, error) {
+	sel, ok := stmt.(*sqlparser.Select)
+	if !ok {
+		return nil, vterrors.VT12001("recursive WITH expression in UNION statement")
+	}
+	original := sqlparser.CloneRefOfSelect(sel)
+	rcte, err := extractRecursiveCTE(sel)
+	if err != nil {
+		return nil, err
+	}
+
+	seedPlan, _, seedTables, err := newBuildSelectPlan(rcte.seed, reservedVars, vschema, plannerVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	vars, err := rewriteRecursiveTerm(rcte, reservedVars)
+	if err != nil {
+		return nil, err
+	}
+	termPlan, _, termTables, err := newBuildSelectPlan(rcte.term, reservedVars, vschema, plannerVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	tablesUsed := seedTables
+	for _, tbl := range termTables {
+		if !slices.Contains(tablesUsed, tbl) {
+			tablesUsed = append(tablesUsed, tbl)
+		}
+	}
+
+	if countOtherTables(sel, rcte.id) == 0 {
+		if route := recursiveCTERoute(seedPlan.Primitive(), termPlan.Primitive()); route != nil {
+			route.Query, route.FieldQuery = withClauseQueries(original)
+			return newPlanResult(route, tablesUsed...), nil
+		}
+	}
+
+	var prim engine.Primitive = &engine.RecurseCTE{
+		Seed: seedPlan.Primitive(),
+		Term: termPlan.Primitive(),
+		Vars: vars,
+	}
+	prim, err = planOnTopOfRecursiveCTE(sel, rcte, prim, vschema)
+	if err != nil {
+		return nil, err
+	}
+	return newPlanResult(prim, tablesUsed...), nil
+}
+
+// countOtherTables returns the number of table references in the node that
+// are not references to the given CTE
+func countOtherTables(node sqlparser.SQLNode, id sqlparser.IdentifierCS) int {
+	count := 0
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if ate, ok := node.(*sqlparser.AliasedTableExpr); ok && !isCTERef(ate, id) {
+			count++
+		}
+		return true, nil
+	}, node)
+	return count
+}
+
+// recursiveCTERoute returns a route that sends the whole statement to MySQL, when
+// the seed and the recursive term of the CTE are both sent to the same single
+// shard or unsharded keyspace. It returns nil if the CTE has to be evaluated
+// in vtgate.
+func recursiveCTERoute(seed, term engine.Primitive) *engine.Route {
+	seedRoute, ok := seed.(*engine.Route)
+	if !ok {
+		return nil
+	}
+	termRoute, ok := term.(*engine.Route)
+	if !ok {
+		return nil
+	}
+
+	var params *engine.RoutingParameters
+	switch {
+	case routesToAnyShardOf(termRoute, seedRoute):
+		params = seedRoute.RoutingParameters
+	case routesToAnyShardOf(seedRoute, termRoute) && termRoute.Opcode != engine.EqualUnique:
+		// the vindex values of the recursive term could depend on the columns of the CTE
+		params = termRoute.RoutingParameters
+	case seedRoute.Keyspace.Name != termRoute.Keyspace.Name || seedRoute.Opcode != termRoute.Opcode:
+		return nil
+	case seedRoute.Opcode == engine.Unsharded:
+		params = seedRoute.RoutingParameters
+	case seedRoute.Opcode == engine.EqualUnique && seedRoute.Vindex == termRoute.Vindex && sameValues(seedRoute.Values, termRoute.Values):
+		// the values of the recursive term can't use the columns of the CTE,
+		// since they are the same as the ones of the seed
+		params = seedRoute.RoutingParameters
+	default:
+		return nil
+	}
+	switch params.Opcode {
+	case engine.Unsharded, engine.EqualUnique, engine.Reference:
+	default:
+		return nil
+	}
+
+	var tableNames []string
+	for _, name := range []string{seedRoute.TableName, termRoute.TableName} {
+		if name != "dual" && !slices.Contains(tableNames, name) {
+			tableNames = append(tableNames, name)
+		}
+	}
+	tableName := "dual"
+	if len(tableNames) > 0 {
+		tableName = strings.Join(tableNames, ", ")
+	}
+	return &engine.Route{
+		RoutingParameters: params,
+		TableName:         tableName,
+	}
+}
+
+// routesToAnyShardOf returns true if the route can be sent to any of the shards
+// the other route is sent to: it only reads dual, or reference tables of the
+// keyspace of the other route
+func routesToAnyShardOf(route, other *engine.Route) bool {
+	if route.Opcode != engine.Reference {
+		return false
+	}
+	return route.TableName == "dual" || route.Keyspace.Name == other.Keyspace.Name
+}
+
+func sameValues(a, b []evalengine.Expr) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if evalengine.FormatExpr(a[i]) != evalengine.FormatExpr(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// extractRecursiveCTE inlines the non-recursive CTEs of the WITH clause, and
+// returns the single recursive CTE that the statement selects from
+func extractRecursiveCTE(sel *sqlparser.Select) (*recursiveCTE, error) {
+	var rcte *recursiveCTE
+	ctes := sel.With.CTEs()
+	sel.With = nil
+	for i, cte := range ctes {
+		if countCTERefs(cte.Subquery, cte.ID) == 0 {
+			// a CTE in a WITH RECURSIVE clause does not have to be recursive
+			for _, later := range ctes[i+1:] {
+				replaceCTERefs(later.Subquery, cte)
+			}
+			replaceCTERefs(sel, cte)
+			continue
+		}
+		if rcte != nil {
+			return nil, vterrors.VT12001("multiple recursive common table expressions")
+		}
+		union, ok := cte.Subquery.Select.(*sqlparser.Union)
+		if !ok {
+			return nil, vterrors.VT12001(fmt.Sprintf("recursive common table expression '%s' without UNION ALL", cte.ID.String()))
+		}
+		if union.Distinct {
+			return nil, vterrors.VT12001("UNION DISTINCT in a recursive common table expression")
+		}
+		if len(union.OrderBy) > 0 || union.Limit != nil {
+			return nil, vterrors.VT12001("ORDER BY or LIMIT in a recursive common table expression")
+		}
+		term, ok := union.Right.(*sqlparser.Select)
+		if !ok || countCTERefs(union.Left, cte.ID) > 0 {
+			return nil, vterrors.VT12001("more than one recursive query block in a recursive common table expression")
+		}
+		if err := checkRecursiveTerm(cte.ID, term); err != nil {
+			return nil, err
+		}
+		rcte = &recursiveCTE{
+			id:      cte.ID,
+			columns: cte.Columns,
+			seed:    union.Left,
+			term:    term,
+		}
+	}
+	if rcte == nil {
+		return nil, vterrors.VT13001("recursive WITH clause without a recursive common table expression")
+	}
+
+	if len(rcte.columns) == 0 {
+		for _, expr := range sqlparser.GetFirstSelect(rcte.seed).SelectExprs {
+			ae, ok := expr.(*sqlparser.AliasedExpr)
+			if !ok {
+				return nil, vterrors.VT12001(fmt.Sprintf("'%s' in a recursive common table expression", sqlparser.String(expr)))
+			}
+			rcte.columns = append(rcte.columns, sqlparser.NewIdentifierCI(ae.ColumnName()))
+		}
+	}
+	if len(rcte.term.SelectExprs) != len(rcte.columns) {
+		return nil, vterrors.VT03006()
+	}
+	return rcte, nil
+}
+
+// checkRecursiveTerm refuses the constructs that MySQL does not allow in the
+// recursive query block of a common table expression: they would apply to the
+// rows of a single iteration instead of to the whole result.
+func checkRecursiveTerm(id sqlparser.IdentifierCS, term *sqlparser.Select) error {
+	forbidden := func(what string) error {
+		return vterrors.VT03027(what, id.String())
+	}
+	switch {
+	case len(term.GroupBy) > 0:
+		return forbidden("GROUP BY")
+	case term.Distinct:
+		return forbidden("DISTINCT")
+	case len(term.OrderBy) > 0:
+		return forbidden("ORDER BY")
+	case term.Limit != nil:
+		return forbidden("LIMIT")
+	}
+
+	var err error
+	check := func(node sqlparser.SQLNode) (bool, error) {
+		switch node.(type) {
+		case *sqlparser.Subquery:
+			// a subquery is a query block of its own
+			return false, nil
+		case sqlparser.AggrFunc:
+			err = forbidden("aggregate function")
+		default:
+			if isWindowFunc(node) {
+				err = forbidden("window function")
+			}
+		}
+		return err == nil, err
+	}
+	_ = sqlparser.Walk(check, term.SelectExprs)
+	if err == nil && term.Having != nil {
+		_ = sqlparser.Walk(check, term.Having)
+	}
+	return err
+}
+
+// rewriteRecursiveTerm removes the reference to the CTE from the recursive term,
+// and replaces the columns of the CTE with bind variables. It returns the bind
+// variables needed by the term, mapped to their column offset in the CTE.
+func rewriteRecursiveTerm(rcte *recursiveCTE, reservedVars *sqlparser.ReservedVars) (map[string]int, error) {
+	term := rcte.term
+	var alias sqlparser.IdentifierCS
+	var conds []sqlparser.Expr
+	var from sqlparser.TableExprs
+	for _, te := range term.From {
+		newTE, on, found, err := removeCTERef(te, rcte.id, &alias)
+		if err != nil {
+			return nil, err
+		}
+		if found {
+			conds = append(conds, on...)
+		}
+		if newTE != nil {
+			from = append(from, newTE)
+		}
+	}
+	if alias.IsEmpty() {
+		return nil, vterrors.VT12001(fmt.Sprintf("recursive reference to '%s' in this position", rcte.id.String()))
+	}
+	if countCTERefs(term, rcte.id) > 1 {
+		return nil, vterrors.VT12001(fmt.Sprintf("more than one recursive reference to '%s'", rcte.id.String()))
+	}
+	if len(from) == 0 {
+		from = sqlparser.TableExprs{&sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: sqlparser.NewIdentifierCS("dual")}}}
+	}
+	term.From = from
+	for _, cond := range conds {
+		term.AddWhere(cond)
+	}
+
+	for _, expr := range term.SelectExprs {
+		if _, ok := expr.(*sqlparser.AliasedExpr); !ok {
+			return nil, vterrors.VT12001(fmt.Sprintf("'%s' in a recursive common table expression", sqlparser.String(expr)))
+		}
+	}
+
+	vars := map[string]int{}
+	bvNames := map[int]string{}
+	_ = sqlparser.Rewrite(term, func(cursor *sqlparser.Cursor) bool {
+		col, ok := cursor.Node().(*sqlparser.ColName)
+		if !ok {
+			return true
+		}
+		offset := rcte.columnOffset(col, alias)
+		if offset < 0 {
+			return true
+		}
+		bvName, ok := bvNames[offset]
+		if !ok {
+			bvName = reservedVars.ReserveColName(col)
+			bvNames[offset] = bvName
+			vars[bvName] = offset
+		}
+		cursor.Replace(sqlparser.NewArgument(bvName))
+		return false
+	}, nil)
+	return vars, nil
+}
+
+// removeCTERef removes the reference to the CTE from the table expression. If the
+// reference is part of an inner join, the join condition is returned so it can be
+// added to the WHERE clause of the recursive term.
+func removeCTERef(te sqlparser.TableExpr, id sqlparser.IdentifierCS, alias *sqlparser.IdentifierCS) (sqlparser.TableExpr, []sqlparser.Expr, bool, error) {
+	switch te := te.(type) {
+	case *sqlparser.AliasedTableExpr:
+		if !isCTERef(te, id) {
+			return te, nil, false, nil
+		}
+		*alias = te.As
+		if alias.IsEmpty() {
+			*alias = id
+		}
+		return nil, nil, true, nil
+	case *sqlparser.JoinTableExpr:
+		for _, side := range []*sqlparser.TableExpr{&te.LeftExpr, &te.RightExpr} {
+			newTE, conds, found, err := removeCTERef(*side, id, alias)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			if !found {
+				continue
+			}
+			if te.Join != sqlparser.NormalJoinType || len(te.Condition.Using) > 0 {
+				return nil, nil, false, vterrors.VT12001(fmt.Sprintf("recursive reference to '%s' in a %s", id.String(), te.Join.ToString()))
+			}
+			if te.Condition.On != nil {
+				conds = append(conds, te.Condition.On)
+			}
+			if newTE == nil {
+				if side == &te.LeftExpr {
+					return te.RightExpr, conds, true, nil
+				}
+				return te.LeftExpr, conds, true, nil
+			}
+			*side = newTE
+			return te, conds, true, nil
+		}
+		return te, nil, false, nil
+	case *sqlparser.ParenTableExpr:
+		for i, expr := range te.Exprs {
+			newTE, conds, found, err := removeCTERef(expr, id, alias)
+			if err != nil {
+				return nil, nil, false, err
+			}
+			if !found {
+				continue
+			}
+			if newTE == nil {
+				te.Exprs = append(te.Exprs[:i:i], te.Exprs[i+1:]...)
+			} else {
+				te.Exprs[i] = newTE
+			}
+			if len(te.Exprs) == 0 {
+				return nil, conds, true, nil
+			}
+			return te, conds, true, nil
+		}
+	}
+	return te, nil, false, nil
+}
+
+// planOnTopOfRecursiveCTE adds the projection, ordering and limit of the outer query
+// on top of the RecurseCTE primitive. Only columns of the CTE can be selected or
+// ordered by.
+func planOnTopOfRecursiveCTE(sel *sqlparser.Select, rcte *recursiveCTE, prim engine.Primitive, vschema plancontext.VSchema) (engine.Primitive, error) {
+	unsupported := func(what string) error {
+		return vterrors.VT12001(fmt.Sprintf("%s on top of a recursive WITH expression", what))
+	}
+	if len(sel.From) != 1 {
+		return nil, unsupported("JOIN")
+	}
+	ate, ok := sel.From[0].(*sqlparser.AliasedTableExpr)
+	if !ok || !isCTERef(ate, rcte.id) {
+		return nil, unsupported("JOIN")
+	}
+	alias := ate.As
+	if alias.IsEmpty() {
+		alias = rcte.id
+	}
+	switch {
+	case sel.Where != nil:
+		return nil, unsupported("WHERE")
+	case len(sel.GroupBy) > 0 || sel.Having != nil:
+		return nil, unsupported("GROUP BY")
+	case sel.Distinct:
+		return nil, unsupported("DISTINCT")
+	}
+
+	var cols []int
+	var names []string
+	for _, expr := range sel.SelectExprs {
+		switch expr := expr.(type) {
+		case *sqlparser.StarExpr:
+			if !expr.TableName.IsEmpty() && (!expr.TableName.Qualifier.IsEmpty() || expr.TableName.Name.String() != alias.String()) {
+				return nil, vterrors.VT05004(sqlparser.String(expr.TableName))
+			}
+			for i, col := range rcte.columns {
+				cols = append(cols, i)
+				names = append(names, col.String())
+			}
+		case *sqlparser.AliasedExpr:
+			col, ok := expr.Expr.(*sqlparser.ColName)
+			if !ok {
+				return nil, unsupported(fmt.Sprintf("expression '%s'", sqlparser.String(expr.Expr)))
+			}
+			offset := rcte.columnOffset(col, alias)
+			if offset < 0 {
+				return nil, vterrors.VT03019(sqlparser.String(col))
+			}
+			cols = append(cols, offset)
+			names = append(names, expr.ColumnName())
+		default:
+			return nil, unsupported(fmt.Sprintf("expression '%s'", sqlparser.String(expr)))
+		}
+	}
+
+	if len(sel.OrderBy) > 0 {
+		ms := &engine.MemorySort{Input: prim}
+		for _, order := range sel.OrderBy {
+			col, ok := order.Expr.(*sqlparser.ColName)
+			if !ok {
+				return nil, unsupported(fmt.Sprintf("ORDER BY '%s'", sqlparser.String(order.Expr)))
+			}
+			offset := -1
+			if col.Qualifier.IsEmpty() {
+				for i, name := range names {
+					if col.Name.EqualString(name) {
+						offset = cols[i]
+						break
+					}
+				}
+			}
+			if offset < 0 {
+				offset = rcte.columnOffset(col, alias)
+			}
+			if offset < 0 {
+				return nil, vterrors.VT03019(sqlparser.String(col))
+			}
+			ms.OrderBy = append(ms.OrderBy, engine.OrderByParams{
+				Col:             offset,
+				WeightStringCol: -1,
+				Desc:            order.Direction == sqlparser.DescOrder,
+				CollationID:     vschema.ConnCollation(),
+			})
+		}
+		prim = ms
+	}
+
+	if !isIdentityProjection(cols, len(rcte.columns)) {
+		prim = &engine.SimpleProjection{Cols: cols, Input: prim}
+	}
+
+	var renames []string
+	var indices []int
+	for i, name := range names {
+		if name != rcte.columns[cols[i]].String() {
+			renames = append(renames, name)
+			indices = append(indices, i)
+		}
+	}
+	if len(renames) > 0 {
+		rename, err := engine.NewRenameField(renames, indices, prim)
+		if err != nil {
+			return nil, err
+		}
+		prim = rename
+	}
+
+	if sel.Limit != nil {
+		count, err := evalengine.Translate(sel.Limit.Rowcount, nil)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "unexpected expression in LIMIT")
+		}
+		limit := &engine.Limit{Count: count, Input: prim}
+		if sel.Limit.Offset != nil {
+			limit.Offset, err = evalengine.Translate(sel.Limit.Offset, nil)
+			if err != nil {
+				return nil, vterrors.Wrap(err, "unexpected expression in OFFSET")
+			}
+		}
+		prim = limit
+	}
+	return prim, nil
+}
+
+func isIdentityProjection(cols []int, width int) bool {
+	if len(cols) != width {
+		return false
+	}
+	for i, col := range cols {
+		if col != i {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+// dmlWithInput is the logical plan of DMLs run on the rows selected by its source.
+type dmlWithInput struct {
+	source     logicalPlan
+	dmls       []logicalPlan
+	outputCols [][]int
+	bvList     []map[string]int
+	bvName     string
+	gen4Plan
+}
+
+var _ logicalPlan = (*dmlWithInput)(nil)
+
+func (d *dmlWithInput) WireupGen4(ctx *plancontext.PlanningContext) error {
+	if err := d.source.WireupGen4(ctx); err != nil {
+		return err
+	}
+	for _, dml := range d.dmls {
+		if err := dml.WireupGen4(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *dmlWithInput) Primitive() engine.Primitive {
+	dmls := make([]engine.Primitive, 0, len(d.dmls))
+	for _, dml := range d.dmls {
+		dmls = append(dmls, dml.Primitive())
+	}
+	return &engine.DMLWithInput{
+		Input:      d.source.Primitive(),
+		DMLs:       dmls,
+		OutputCols: d.outputCols,
+		BVList:     d.bvList,
+		BVName:     d.bvName,
+	}
+}
+
+func (d *dmlWithInput) Inputs() []logicalPlan {
+	return append([]logicalPlan{d.source}, d.dmls...)
+}
+
+func (d *dmlWithInput) Rewrite(inputs ...logicalPlan) error {
+	if len(inputs) != len(d.dmls)+1 {
+		return vterrors.VT13001("dmlWithInput: wrong number of inputs")
+	}
+	d.source = inputs[0]
+	d.dmls = inputs[1:]
+	return nil
+}
+
+func (d *dmlWithInput) ContainsTables() semantics.TableSet {
+	return d.source.ContainsTables()
+}
+
+func (d *dmlWithInput) OutputColumns() []sqlparser.SelectExpr {
+	return nil
+}
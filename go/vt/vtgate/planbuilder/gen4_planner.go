@@ -53,14 +53,18 @@ func gen4SelectStmtPlanner(
 	reservedVars *sqlparser.ReservedVars,
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
+	materialized := materializedCTEs(stmt)
+	if err := inlineCTEs(stmt); err != nil {
+		return nil, err
+	}
 	switch node := stmt.(type) {
 	case *sqlparser.Select:
 		if node.With != nil {
-			return nil, vterrors.VT12001("WITH expression in SELECT statement")
+			return gen4RecursiveCTEPlanner(plannerVersion, node, reservedVars, vschema)
 		}
 	case *sqlparser.Union:
 		if node.With != nil {
-			return nil, vterrors.VT12001("WITH expression in UNION statement")
+			return gen4RecursiveCTEPlanner(plannerVersion, node, reservedVars, vschema)
 		}
 	}
 
@@ -87,6 +91,10 @@ func gen4SelectStmtPlanner(
 		}
 		// if there was no limit, we can safely ignore the SQLCalcFoundRows directive
 		sel.SQLCalcFoundRows = false
+
+		if containsWindowFunc(sel.SelectExprs) {
+			return gen4WindowPlanner(plannerVersion, sel, reservedVars, vschema)
+		}
 	}
 
 	getPlan := func(selStatement sqlparser.SelectStatement) (logicalPlan, *semantics.SemTable, []string, error) {
@@ -102,11 +110,18 @@ func gen4SelectStmtPlanner(
 		// by transforming the predicates to CNF, the planner will sometimes find better plans
 		plan2, _, tablesUsed := gen4PredicateRewrite(stmt, getPlan)
 		if plan2 != nil {
-			return newPlanResult(plan2.Primitive(), tablesUsed...), nil
+			primitive := plan2.Primitive()
+			if materialized != nil {
+				sendWithClause(primitive, materialized)
+			}
+			return newPlanResult(primitive, tablesUsed...), nil
 		}
 	}
 
 	primitive := plan.Primitive()
+	if materialized != nil {
+		sendWithClause(primitive, materialized)
+	}
 	if !isSel {
 		return newPlanResult(primitive, tablesUsed...), nil
 	}
@@ -261,8 +276,11 @@ func gen4UpdateStmtPlanner(
 	reservedVars *sqlparser.ReservedVars,
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
+	if err := inlineCTEs(updStmt); err != nil {
+		return nil, err
+	}
 	if updStmt.With != nil {
-		return nil, vterrors.VT12001("WITH expression in UPDATE statement")
+		return nil, vterrors.VT12001("recursive WITH expression in UPDATE statement")
 	}
 
 	ksName := ""
@@ -334,8 +352,11 @@ func gen4DeleteStmtPlanner(
 	reservedVars *sqlparser.ReservedVars,
 	vschema plancontext.VSchema,
 ) (*planResult, error) {
+	if err := inlineCTEs(deleteStmt); err != nil {
+		return nil, err
+	}
 	if deleteStmt.With != nil {
-		return nil, vterrors.VT12001("WITH expression in DELETE statement")
+		return nil, vterrors.VT12001("recursive WITH expression in DELETE statement")
 	}
 
 	var err error
@@ -522,6 +543,9 @@ func setLockOnAllSelect(plan logicalPlan) {
 		case *routeGen4:
 			node.Select.SetLock(sqlparser.ShareModeLock)
 			return true, node, nil
+		case *dmlWithInput:
+			// the rows selected by the input are already locked for update.
+			return false, node, nil
 		}
 		return true, plan, nil
 	})
@@ -656,33 +680,18 @@ func checkIfDeleteSupported(del *sqlparser.Delete, semTable *semantics.SemTable)
 		return semTable.NotUnshardedErr
 	}
 
-	// Delete is only supported for a single TableExpr which is supposed to be an aliased expression
-	multiShardErr := vterrors.VT12001("multi-shard or vindex write statement")
+	// The subqueries in the rest of the statement are planned separately from the DML,
+	// but the table we delete from can't be a derived table. The targets of the
+	// multi-table deletes are checked when they are planned.
 	if len(del.TableExprs) != 1 {
-		return multiShardErr
-	}
-	_, isAliasedExpr := del.TableExprs[0].(*sqlparser.AliasedTableExpr)
-	if !isAliasedExpr {
-		return multiShardErr
+		return nil
 	}
-
-	if len(del.Targets) > 1 {
-		return vterrors.VT12001("multi-table DELETE statement in a sharded keyspace")
+	alias, isAliased := del.TableExprs[0].(*sqlparser.AliasedTableExpr)
+	if !isAliased {
+		return nil
 	}
-
-	err := sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
-		switch node.(type) {
-		case *sqlparser.Subquery, *sqlparser.DerivedTable:
-			// We have a subquery, so we must fail the planning.
-			// If this subquery and the table expression were all belonging to the same unsharded keyspace,
-			// we would have already created a plan for them before doing these checks.
-			return false, vterrors.VT12001("subqueries in DML")
-		}
-		return true, nil
-	}, del)
-	if err != nil {
-		return err
+	if _, isDerived := alias.Expr.(*sqlparser.DerivedTable); isDerived {
+		return vterrors.VT03004(sqlparser.String(alias.As))
 	}
-
 	return nil
 }
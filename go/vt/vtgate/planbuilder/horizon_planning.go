@@ -367,7 +367,7 @@ func (hp *horizonPlanning) planAggrUsingOA(
 
 func unsupportedAggregations(aggrs []operators.Aggr) error {
 	for _, aggr := range aggrs {
-		if aggr.OpCode == popcode.AggregateGroupConcat {
+		if aggr.OpCode == popcode.AggregateGroupConcat || aggr.OpCode == popcode.AggregateJSONArrayAgg {
 			return vterrors.VT12001(fmt.Sprintf("in scatter query: aggregation function '%s'", sqlparser.String(aggr.Func)))
 		}
 	}
@@ -85,18 +85,27 @@ func buildInsertUnshardedPlan(ins *sqlparser.Insert, table *vindexes.Table, rese
 	tc.addVindexTable(table)
 	switch insertValues := ins.Rows.(type) {
 	case *sqlparser.Select, *sqlparser.Union:
-		if eins.Table.AutoIncrement != nil {
-			return nil, vterrors.VT12001("auto-increment and SELECT in INSERT")
+		// the auto-increment column can only be located in the selected rows with a column list.
+		if eins.Table.AutoIncrement != nil && len(ins.Columns) == 0 {
+			if !table.ColumnListAuthoritative {
+				return nil, vterrors.VT09004()
+			}
+			populateInsertColumnlist(ins, table)
 		}
 		plan, err := subquerySelectPlan(ins, vschema, reservedVars, false)
 		if err != nil {
 			return nil, err
 		}
 		tc.addAllTables(plan.tables)
-		if route, ok := plan.primitive.(*engine.Route); ok && !route.Keyspace.Sharded && table.Keyspace.Name == route.Keyspace.Name {
+		route, ok := plan.primitive.(*engine.Route)
+		if ok && !route.Keyspace.Sharded && table.Keyspace.Name == route.Keyspace.Name && eins.Table.AutoIncrement == nil {
 			eins.Query = generateQuery(ins)
 		} else {
+			// the auto-increment values are generated for the selected rows before they are inserted.
 			eins.Input = plan.primitive
+			if err := modifyForAutoinc(ins, eins); err != nil {
+				return nil, err
+			}
 			eins.Prefix, _, eins.Suffix = generateInsertShardedQuery(ins)
 		}
 		return newPlanResult(eins, tc.getTables()...), nil
@@ -65,6 +65,8 @@ func transformToLogicalPlan(ctx *plancontext.PlanningContext, op ops.Operator, i
 		return transformAggregator(ctx, op)
 	case *operators.Distinct:
 		return transformDistinct(ctx, op)
+	case *operators.DMLWithInput:
+		return transformDMLWithInput(ctx, op)
 	}
 
 	return nil, vterrors.VT13001(fmt.Sprintf("unknown type encountered: %T (transformToLogicalPlan)", op))
@@ -517,7 +519,7 @@ func transformUpdatePlan(ctx *plancontext.PlanningContext, op *operators.Route,
 		Table: []*vindexes.Table{
 			upd.VTable,
 		},
-		OwnedVindexQuery:  upd.OwnedVindexQuery,
+		OwnedVindexQuery:  ownedVindexQuery(ctx, upd.OwnedVindexQuery),
 		RoutingParameters: rp,
 	}
 
@@ -544,11 +546,11 @@ func transformDeletePlan(ctx *plancontext.PlanningContext, op *operators.Route,
 		Table: []*vindexes.Table{
 			del.VTable,
 		},
-		OwnedVindexQuery:  del.OwnedVindexQuery,
+		OwnedVindexQuery:  ownedVindexQuery(ctx, del.OwnedVindexQuery),
 		RoutingParameters: rp,
 	}
 
-	transformDMLPlan(del.VTable, edml, op.Routing, del.OwnedVindexQuery != "")
+	transformDMLPlan(del.VTable, edml, op.Routing, del.OwnedVindexQuery != nil)
 
 	e := &engine.Delete{
 		DML: edml,
@@ -557,6 +559,46 @@ func transformDeletePlan(ctx *plancontext.PlanningContext, op *operators.Route,
 	return &primitiveWrapper{prim: e}, nil
 }
 
+func transformDMLWithInput(ctx *plancontext.PlanningContext, op *operators.DMLWithInput) (logicalPlan, error) {
+	source, err := transformToLogicalPlan(ctx, op.Source, true)
+	if err != nil {
+		return nil, err
+	}
+	// The rows to change are locked when they are selected.
+	_, _ = visit(source, func(plan logicalPlan) (bool, logicalPlan, error) {
+		if route, ok := plan.(*routeGen4); ok {
+			route.Select.SetLock(sqlparser.ForUpdateLock)
+		}
+		return true, plan, nil
+	})
+
+	var dmls []logicalPlan
+	for _, dmlOp := range op.DML {
+		dml, err := transformToLogicalPlan(ctx, dmlOp, false)
+		if err != nil {
+			return nil, err
+		}
+		dmls = append(dmls, dml)
+	}
+	return &dmlWithInput{
+		source:     source,
+		dmls:       dmls,
+		outputCols: op.OutputCols,
+		bvList:     op.BVList,
+		bvName:     op.BVName,
+	}, nil
+}
+
+// ownedVindexQuery returns the query reading the rows changed by a DML, once
+// the subqueries pulled out of the DML have been replaced by their arguments.
+func ownedVindexQuery(ctx *plancontext.PlanningContext, sel *sqlparser.Select) string {
+	if sel == nil {
+		return ""
+	}
+	replaceSubQuery(ctx, sel)
+	return generateQuery(sel)
+}
+
 func transformDMLPlan(vtable *vindexes.Table, edml *engine.DML, routing operators.Routing, setVindex bool) {
 	if routing.OpCode() != engine.Unsharded && setVindex {
 		primary := vtable.ColumnVindexes[0]
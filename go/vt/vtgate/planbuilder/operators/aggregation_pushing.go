@@ -75,6 +75,10 @@ func aggregateTheAggregate(a *Aggregator, i int) {
 		// Think of it as we are SUMming together a bunch of distributed COUNTs.
 		aggr.OriginalOpCode, aggr.OpCode = aggr.OpCode, opcode.AggregateSum
 		a.Aggregations[i] = aggr
+	case opcode.AggregateJSONArrayAgg:
+		// The Route returns one JSON array per shard, which are concatenated above it.
+		aggr.OriginalOpCode = aggr.OpCode
+		a.Aggregations[i] = aggr
 	}
 }
 
@@ -503,6 +507,9 @@ func (ab *aggBuilder) handleAggr(ctx *plancontext.PlanningContext, aggr Aggr) er
 		// and later will try pushing the column instead.
 		// TODO: this should be handled better by pushing the function down.
 		return errAbortAggrPushing
+	case opcode.AggregateJSONArrayAgg:
+		// like group_concat, the column is pushed down instead of the function
+		return errAbortAggrPushing
 	case opcode.AggregateUnassigned:
 		return vterrors.VT12001(fmt.Sprintf("in scatter query: aggregation function '%s'", sqlparser.String(aggr.Original)))
 	case opcode.AggregateGtid:
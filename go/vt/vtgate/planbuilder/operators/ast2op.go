@@ -108,6 +108,10 @@ func createOperatorFromUnion(ctx *plancontext.PlanningContext, node *sqlparser.U
 }
 
 func createOperatorFromUpdate(ctx *plancontext.PlanningContext, updStmt *sqlparser.Update) (ops.Operator, error) {
+	if isMultiTableDML(updStmt.TableExprs) {
+		return createMultiTableUpdate(ctx, updStmt)
+	}
+
 	tableInfo, qt, err := createQueryTableForDML(ctx, updStmt.TableExprs[0], updStmt.Where)
 	if err != nil {
 		return nil, err
@@ -141,11 +145,6 @@ func createOperatorFromUpdate(ctx *plancontext.PlanningContext, updStmt *sqlpars
 		}
 	}
 
-	if routing.OpCode() == engine.Scatter && updStmt.Limit != nil {
-		// TODO systay: we should probably check for other op code types - IN could also hit multiple shards (2022-04-07)
-		return nil, vterrors.VT12001("multi shard UPDATE with LIMIT")
-	}
-
 	r := &Route{
 		Source: &Update{
 			QTable:              qt,
@@ -158,18 +157,26 @@ func createOperatorFromUpdate(ctx *plancontext.PlanningContext, updStmt *sqlpars
 		Routing: routing,
 	}
 
-	subq, err := createSubqueryFromStatement(ctx, updStmt)
-	if err != nil {
-		return nil, err
-	}
-	if subq == nil {
-		return r, nil
+	if needsDMLWithInput(r, updStmt.Limit) {
+		return createDMLWithInput(ctx, updStmt, qt, vindexTable, "UPDATE", func(where *sqlparser.Where) (ops.Operator, error) {
+			return createOperatorFromUpdate(ctx, &sqlparser.Update{
+				Comments:   updStmt.Comments,
+				Ignore:     updStmt.Ignore,
+				TableExprs: updStmt.TableExprs,
+				Exprs:      updStmt.Exprs,
+				Where:      where,
+			})
+		})
 	}
-	subq.Outer = r
-	return subq, nil
+
+	return addSubqueryToDML(ctx, updStmt, r)
 }
 
 func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlparser.Delete) (ops.Operator, error) {
+	if isMultiTableDML(deleteStmt.TableExprs) {
+		return createMultiTableDelete(ctx, deleteStmt)
+	}
+
 	tableInfo, qt, err := createQueryTableForDML(ctx, deleteStmt.TableExprs[0], deleteStmt.Where)
 	if err != nil {
 		return nil, err
@@ -191,7 +198,7 @@ func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlp
 	}
 
 	if !vindexTable.Keyspace.Sharded {
-		return route, nil
+		return addSubqueryToDML(ctx, deleteStmt, route)
 	}
 
 	primaryVindex, vindexAndPredicates, err := getVindexInformation(qt.ID, qt.Predicates, vindexTable)
@@ -204,14 +211,11 @@ func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlp
 		tr.VindexPreds = vindexAndPredicates
 	}
 
-	var ovq string
 	if len(vindexTable.Owned) > 0 {
 		tblExpr := &sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: vindexTable.Name}, As: qt.Alias.As}
-		ovq = generateOwnedVindexQuery(tblExpr, deleteStmt, vindexTable, primaryVindex.Columns)
+		del.OwnedVindexQuery = generateOwnedVindexQuery(tblExpr, deleteStmt, vindexTable, primaryVindex.Columns)
 	}
 
-	del.OwnedVindexQuery = ovq
-
 	for _, predicate := range qt.Predicates {
 		var err error
 		route.Routing, err = UpdateRoutingLogic(ctx, predicate, route.Routing)
@@ -220,12 +224,24 @@ func createOperatorFromDelete(ctx *plancontext.PlanningContext, deleteStmt *sqlp
 		}
 	}
 
-	if routing.OpCode() == engine.Scatter && deleteStmt.Limit != nil {
-		// TODO systay: we should probably check for other op code types - IN could also hit multiple shards (2022-04-07)
-		return nil, vterrors.VT12001("multi shard DELETE with LIMIT")
+	if needsDMLWithInput(route, deleteStmt.Limit) {
+		return createDMLWithInput(ctx, deleteStmt, qt, vindexTable, "DELETE", func(where *sqlparser.Where) (ops.Operator, error) {
+			return createOperatorFromDelete(ctx, &sqlparser.Delete{
+				Comments:   deleteStmt.Comments,
+				Ignore:     deleteStmt.Ignore,
+				TableExprs: deleteStmt.TableExprs,
+				Targets:    deleteStmt.Targets,
+				Where:      where,
+			})
+		})
 	}
 
-	subq, err := createSubqueryFromStatement(ctx, deleteStmt)
+	return addSubqueryToDML(ctx, deleteStmt, route)
+}
+
+// addSubqueryToDML plans the subqueries of the DML, if any, with the route of the DML as outer query.
+func addSubqueryToDML(ctx *plancontext.PlanningContext, stmt sqlparser.Statement, route *Route) (ops.Operator, error) {
+	subq, err := createSubqueryFromStatement(ctx, stmt)
 	if err != nil {
 		return nil, err
 	}
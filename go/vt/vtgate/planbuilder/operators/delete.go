@@ -28,7 +28,7 @@ import (
 type Delete struct {
 	QTable           *QueryTable
 	VTable           *vindexes.Table
-	OwnedVindexQuery string
+	OwnedVindexQuery *sqlparser.Select
 	AST              *sqlparser.Delete
 
 	noInputs
@@ -18,6 +18,7 @@ package operators
 
 import (
 	"fmt"
+	"strings"
 
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -59,50 +60,70 @@ func getVindexInformation(
 	return primaryVindex, vindexesAndPredicates, nil
 }
 
-func buildChangedVindexesValues(update *sqlparser.Update, table *vindexes.Table, ksidCols []sqlparser.IdentifierCI) (map[string]*engine.VindexValues, string, error) {
+func buildChangedVindexesValues(update *sqlparser.Update, table *vindexes.Table, ksidCols []sqlparser.IdentifierCI) (map[string]*engine.VindexValues, *sqlparser.Select, error) {
 	changedVindexes := make(map[string]*engine.VindexValues)
-	buf, offset := initialQuery(ksidCols, table)
+	selExprs, offset := initialQuery(ksidCols, table)
+
+	// the values that can't be computed by vtgate are read from the table
+	// along with the current values of the vindex columns.
+	type preRead struct {
+		pvMap map[string]evalengine.Expr
+		col   string
+		expr  sqlparser.Expr
+	}
+	var preReads []preRead
+
 	for i, vindex := range table.ColumnVindexes {
 		vindexValueMap := make(map[string]evalengine.Expr)
-		first := true
+		var compExprs []sqlparser.Expr
 		for _, vcol := range vindex.Columns {
 			// Searching in order of columns in colvindex.
 			found := false
-			for _, assignment := range update.Exprs {
+			for idx, assignment := range update.Exprs {
 				if !vcol.Equal(assignment.Name.Name) {
 					continue
 				}
 				if found {
-					return nil, "", vterrors.VT03015(assignment.Name.Name)
+					return nil, nil, vterrors.VT03015(assignment.Name.Name)
 				}
 				found = true
 				pv, err := extractValueFromUpdate(assignment)
 				if err != nil {
-					return nil, "", err
+					return nil, nil, err
 				}
-				vindexValueMap[vcol.String()] = pv
-				if first {
-					buf.Myprintf(", %v", assignment)
-					first = false
+				if pv == nil {
+					// the pre-read reads the values of the row before the update,
+					// while MySQL uses the values assigned earlier in the same SET.
+					if col := assignedColumn(assignment.Expr, update.Exprs[:idx]); col != nil {
+						return nil, nil, vterrors.VT12001(fmt.Sprintf("column `%s` changed earlier in the same UPDATE; invalid update on column: `%s` with expr: [%s]",
+							col.Name.String(), assignment.Name.Name.String(), sqlparser.String(assignment.Expr)))
+					}
+					preReads = append(preReads, preRead{pvMap: vindexValueMap, col: vcol.String(), expr: assignment.Expr})
 				} else {
-					buf.Myprintf(" and %v", assignment)
+					vindexValueMap[vcol.String()] = pv
 				}
+				compExprs = append(compExprs, &sqlparser.ComparisonExpr{
+					Operator: sqlparser.EqualOp,
+					Left:     assignment.Name,
+					Right:    assignment.Expr,
+				})
 			}
 		}
-		if len(vindexValueMap) == 0 {
+		if len(compExprs) == 0 {
 			// Vindex not changing, continue
 			continue
 		}
 
 		if update.Limit != nil && len(update.OrderBy) == 0 {
-			return nil, "", vterrors.VT12001(fmt.Sprintf("you need to provide the ORDER BY clause when using LIMIT; invalid update on vindex: %v", vindex.Name))
+			return nil, nil, vterrors.VT12001(fmt.Sprintf("you need to provide the ORDER BY clause when using LIMIT; invalid update on vindex: %v", vindex.Name))
 		}
 		if i == 0 {
-			return nil, "", vterrors.VT12001(fmt.Sprintf("you cannot UPDATE primary vindex columns; invalid update on vindex: %v", vindex.Name))
+			return nil, nil, vterrors.VT12001(fmt.Sprintf("you cannot UPDATE primary vindex columns; invalid update on vindex: %v", vindex.Name))
 		}
 		if _, ok := vindex.Vindex.(vindexes.Lookup); !ok {
-			return nil, "", vterrors.VT12001(fmt.Sprintf("you can only UPDATE lookup vindexes; invalid update on vindex: %v", vindex.Name))
+			return nil, nil, vterrors.VT12001(fmt.Sprintf("you can only UPDATE lookup vindexes; invalid update on vindex: %v", vindex.Name))
 		}
+		selExprs = append(selExprs, &sqlparser.AliasedExpr{Expr: sqlparser.AndExpressions(compExprs...)})
 		changedVindexes[vindex.Name] = &engine.VindexValues{
 			PvMap:  vindexValueMap,
 			Offset: offset,
@@ -110,59 +131,117 @@ func buildChangedVindexesValues(update *sqlparser.Update, table *vindexes.Table,
 		offset++
 	}
 	if len(changedVindexes) == 0 {
-		return nil, "", nil
+		return nil, nil, nil
 	}
+	for _, pr := range preReads {
+		selExprs = append(selExprs, &sqlparser.AliasedExpr{Expr: pr.expr})
+		pr.pvMap[pr.col] = evalengine.NewColumn(offset)
+		offset++
+	}
+
 	// generate rest of the owned vindex query.
 	aTblExpr, ok := update.TableExprs[0].(*sqlparser.AliasedTableExpr)
 	if !ok {
-		return nil, "", vterrors.VT12001("UPDATE on complex table expression")
+		return nil, nil, vterrors.VT12001("UPDATE on complex table expression")
 	}
 	tblExpr := &sqlparser.AliasedTableExpr{Expr: sqlparser.TableName{Name: table.Name}, As: aTblExpr.As}
-	buf.Myprintf(" from %v%v%v%v for update", tblExpr, update.Where, update.OrderBy, update.Limit)
-	return changedVindexes, buf.String(), nil
+	return changedVindexes, ownedVindexQuery(selExprs, tblExpr, update.Where, update.OrderBy, update.Limit), nil
 }
 
-func initialQuery(ksidCols []sqlparser.IdentifierCI, table *vindexes.Table) (*sqlparser.TrackedBuffer, int) {
-	buf := sqlparser.NewTrackedBuffer(nil)
-	offset := 0
+func initialQuery(ksidCols []sqlparser.IdentifierCI, table *vindexes.Table) (sqlparser.SelectExprs, int) {
+	var selExprs sqlparser.SelectExprs
 	for _, col := range ksidCols {
-		if offset == 0 {
-			buf.Myprintf("select %v", col)
-		} else {
-			buf.Myprintf(", %v", col)
-		}
-		offset++
+		selExprs = append(selExprs, &sqlparser.AliasedExpr{Expr: sqlparser.NewColName(col.String())})
 	}
 	for _, cv := range table.Owned {
 		for _, column := range cv.Columns {
-			buf.Myprintf(", %v", column)
-			offset++
+			selExprs = append(selExprs, &sqlparser.AliasedExpr{Expr: sqlparser.NewColName(column.String())})
 		}
 	}
-	return buf, offset
+	return selExprs, len(selExprs)
+}
+
+// ownedVindexQuery returns the query reading and locking the rows changed by a DML,
+// along with the values needed to maintain the owned vindexes.
+// It shares the WHERE clause of the DML, so that the subqueries that are pulled
+// out of the DML are replaced in both.
+func ownedVindexQuery(selExprs sqlparser.SelectExprs, tblExpr sqlparser.TableExpr, where *sqlparser.Where, orderBy sqlparser.OrderBy, limit *sqlparser.Limit) *sqlparser.Select {
+	return &sqlparser.Select{
+		SelectExprs: selExprs,
+		From:        sqlparser.TableExprs{tblExpr},
+		Where:       where,
+		OrderBy:     orderBy,
+		Limit:       limit,
+		Lock:        sqlparser.ForUpdateLock,
+	}
 }
 
-// extractValueFromUpdate given an UpdateExpr, builds an evalengine.Expr
+// extractValueFromUpdate given an UpdateExpr, builds an evalengine.Expr.
+// It returns a nil expression when the value can't be computed by vtgate,
+// like when it depends on the columns of the row being changed.
 func extractValueFromUpdate(upd *sqlparser.UpdateExpr) (evalengine.Expr, error) {
 	expr := upd.Expr
 	if sq, ok := expr.(*sqlparser.ExtractedSubquery); ok {
 		// if we are planning an update that needs one or more values from the outside, we can trust that they have
 		// been correctly extracted from this query before we reach this far
-		// if Merged is true, it means that this subquery was happily merged with the outer.
-		// But in that case we should not be here, so we fail
+		// if Merged is true, it means that this subquery was happily merged with the outer,
+		// and its value has to be read from the table.
 		if sq.Merged {
-			return nil, invalidUpdateExpr(upd, expr)
+			return nil, nil
 		}
 		expr = sqlparser.NewArgument(sq.GetArgName())
 	}
 
-	pv, err := evalengine.Translate(expr, nil)
-	if err != nil || sqlparser.IsSimpleTuple(expr) {
+	if sqlparser.IsSimpleTuple(expr) {
 		return nil, invalidUpdateExpr(upd, expr)
 	}
+	if hasColumn(expr) {
+		return nil, nil
+	}
+	pv, err := evalengine.Translate(expr, nil)
+	if err != nil {
+		if strings.Contains(err.Error(), evalengine.ErrTranslateExprNotSupported) {
+			return nil, nil
+		}
+		return nil, err
+	}
 	return pv, nil
 }
 
+// hasColumn returns true if the expression references a column.
+func hasColumn(expr sqlparser.Expr) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, ok := node.(*sqlparser.ColName); ok {
+			found = true
+			return false, nil
+		}
+		return !found, nil
+	}, expr)
+	return found
+}
+
+// assignedColumn returns the column of the assignments referenced by the expression,
+// ignoring the columns of its subqueries.
+func assignedColumn(expr sqlparser.Expr, assignments sqlparser.UpdateExprs) *sqlparser.ColName {
+	var col *sqlparser.ColName
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Subquery:
+			return false, nil
+		case *sqlparser.ColName:
+			for _, assignment := range assignments {
+				if assignment.Name.Name.Equal(node.Name) {
+					col = node
+					return false, nil
+				}
+			}
+		}
+		return col == nil, nil
+	}, expr)
+	return col
+}
+
 func invalidUpdateExpr(upd *sqlparser.UpdateExpr, expr sqlparser.Expr) error {
 	return vterrors.VT12001(fmt.Sprintf("only values are supported; invalid update on column: `%s` with expr: [%s]", upd.Name.Name.String(), sqlparser.String(expr)))
 }
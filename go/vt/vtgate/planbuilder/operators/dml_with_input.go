@@ -0,0 +1,428 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"strconv"
+
+	"golang.org/x/exp/slices"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/operators/ops"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+// DMLWithInput is used for the UPDATE and DELETE statements that can't be
+// sent to the shards as they are, like the multi-shard ones with a LIMIT or
+// the cross-shard multi-table ones. Source selects the primary keys of the
+// rows to change, and each DML changes the rows of one table that have
+// these primary keys.
+type DMLWithInput struct {
+	Source ops.Operator
+	DML    []ops.Operator
+
+	// OutputCols are the offsets of the primary key columns of Source used by each DML.
+	OutputCols [][]int
+	// BVList are the bind variables of each DML holding the new values of an
+	// UPDATE, mapped to their offset in Source.
+	BVList []map[string]int
+	// BVName is the list bind variable the primary keys are passed to the DMLs with.
+	BVName string
+
+	noColumns
+	noPredicates
+}
+
+var _ ops.Operator = (*DMLWithInput)(nil)
+
+// Clone implements the Operator interface
+func (d *DMLWithInput) Clone(inputs []ops.Operator) ops.Operator {
+	return &DMLWithInput{
+		Source:     inputs[0],
+		DML:        inputs[1:],
+		OutputCols: d.OutputCols,
+		BVList:     d.BVList,
+		BVName:     d.BVName,
+	}
+}
+
+// Inputs implements the Operator interface
+func (d *DMLWithInput) Inputs() []ops.Operator {
+	return append([]ops.Operator{d.Source}, d.DML...)
+}
+
+// SetInputs implements the Operator interface
+func (d *DMLWithInput) SetInputs(inputs []ops.Operator) {
+	if len(inputs) != len(d.DML)+1 {
+		panic("unexpected number of inputs for DMLWithInput operator")
+	}
+	d.Source = inputs[0]
+	d.DML = inputs[1:]
+}
+
+func (d *DMLWithInput) ShortDescription() string {
+	return d.BVName
+}
+
+func (d *DMLWithInput) GetOrdering() ([]ops.OrderBy, error) {
+	return nil, nil
+}
+
+// needsDMLWithInput returns true if the UPDATE or DELETE routed by r has a
+// LIMIT, and can change rows on more than one shard.
+func needsDMLWithInput(r *Route, limit *sqlparser.Limit) bool {
+	return limit != nil && !r.IsSingleShard() && r.Routing.OpCode() != engine.ByDestination
+}
+
+// isMultiTableDML returns true if the UPDATE or DELETE reads from more than one table.
+func isMultiTableDML(tableExprs sqlparser.TableExprs) bool {
+	if len(tableExprs) != 1 {
+		return true
+	}
+	_, isAliased := tableExprs[0].(*sqlparser.AliasedTableExpr)
+	return !isAliased
+}
+
+// createDMLWithInput plans a multi-shard UPDATE or DELETE with a LIMIT. The
+// rows to change are selected across all the shards, using the ORDER BY and
+// the LIMIT of the statement, and then changed using their primary key:
+//
+//	delete from t where x = 1 order by id limit 10
+//
+// becomes
+//
+//	select id from t where x = 1 order by id limit 10 for update
+//	delete from t where id in ::dml_vals
+//
+// newDML is called with the WHERE clause matching the selected rows, and
+// returns the operator of the DML to run on them.
+func createDMLWithInput(
+	ctx *plancontext.PlanningContext,
+	stmt sqlparser.Statement,
+	qt *QueryTable,
+	vTbl *vindexes.Table,
+	dmlType string,
+	newDML func(where *sqlparser.Where) (ops.Operator, error),
+) (ops.Operator, error) {
+	if len(ctx.SemTable.SubqueryMap[stmt]) > 0 {
+		return nil, vterrors.VT12001(fmt.Sprintf("multi shard %s with LIMIT and subqueries", dmlType))
+	}
+	if len(vTbl.PrimaryKey) == 0 {
+		return nil, vterrors.VT12001(fmt.Sprintf("multi shard %s with LIMIT on a table without a known primary key", dmlType))
+	}
+
+	var tableExprs sqlparser.TableExprs
+	var where *sqlparser.Where
+	var orderBy sqlparser.OrderBy
+	var limit *sqlparser.Limit
+	switch stmt := stmt.(type) {
+	case *sqlparser.Update:
+		tableExprs, where, orderBy, limit = stmt.TableExprs, stmt.Where, stmt.OrderBy, stmt.Limit
+	case *sqlparser.Delete:
+		tableExprs, where, orderBy, limit = stmt.TableExprs, stmt.Where, stmt.OrderBy, stmt.Limit
+	default:
+		return nil, vterrors.VT13001(fmt.Sprintf("unexpected statement for DMLWithInput: %T", stmt))
+	}
+
+	sel := &sqlparser.Select{
+		From:    tableExprs,
+		Where:   where,
+		OrderBy: orderBy,
+		Limit:   limit,
+		Lock:    sqlparser.ForUpdateLock,
+	}
+	// The columns added here are not known to the semantic analysis,
+	// they all belong to the table being changed.
+	var outputCols []int
+	for i, pk := range vTbl.PrimaryKey {
+		sel.SelectExprs = append(sel.SelectExprs, &sqlparser.AliasedExpr{Expr: targetColumn(ctx, qt.ID, sqlparser.TableName{}, pk)})
+		outputCols = append(outputCols, i)
+	}
+	source, err := PlanQuery(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	bvName := ctx.ReservedVars.ReserveVariable("dml_vals")
+	var pkCols []*sqlparser.ColName
+	for _, pk := range vTbl.PrimaryKey {
+		pkCols = append(pkCols, targetColumn(ctx, qt.ID, sqlparser.TableName{}, pk))
+	}
+	dml, err := newDML(sqlparser.NewWhere(sqlparser.WhereClause, targetCondition(bvName, pkCols)))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DMLWithInput{
+		Source:     source,
+		DML:        []ops.Operator{dml},
+		OutputCols: [][]int{outputCols},
+		BVName:     bvName,
+	}, nil
+}
+
+// dmlTarget is a table changed by a multi-table UPDATE or DELETE.
+type dmlTarget struct {
+	alias *sqlparser.AliasedTableExpr
+	id    semantics.TableSet
+	vTbl  *vindexes.Table
+
+	// exprs are the assignments of an UPDATE changing this table.
+	exprs sqlparser.UpdateExprs
+}
+
+// createMultiTableDelete plans a multi-table DELETE that can't be sent as is to a
+// single keyspace. The primary keys of the rows to delete are selected using a join
+// of all the tables, and the rows of each target table are then deleted by their
+// primary key:
+//
+//	delete u from user u join user_extra ue on u.id = ue.user_id where ue.col = 5
+//
+// becomes
+//
+//	select u.id from user u join user_extra ue on u.id = ue.user_id where ue.col = 5 for update
+//	delete from user as u where u.id in ::dml_vals
+func createMultiTableDelete(ctx *plancontext.PlanningContext, del *sqlparser.Delete) (ops.Operator, error) {
+	var targets []*dmlTarget
+	for _, target := range del.Targets {
+		alias := findTableExpr(del.TableExprs, func(alias *sqlparser.AliasedTableExpr) bool {
+			name, err := alias.TableName()
+			return err == nil && name.Name == target.Name
+		})
+		if alias == nil {
+			return nil, vterrors.VT03003(target.Name.String())
+		}
+		t, err := newDMLTarget(ctx, alias, "DELETE")
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+
+	return createMultiTableDML(ctx, del, del.TableExprs, del.Where, targets, func(t *dmlTarget, where *sqlparser.Where) (ops.Operator, error) {
+		return createOperatorFromDelete(ctx, &sqlparser.Delete{
+			Comments:   del.Comments,
+			Ignore:     del.Ignore,
+			TableExprs: sqlparser.TableExprs{t.alias},
+			Where:      where,
+		})
+	})
+}
+
+// createMultiTableUpdate plans a multi-table UPDATE that can't be sent as is to a
+// single keyspace, the same way as createMultiTableDelete. The new values depending
+// on the other tables of the join are selected along with the primary keys, and each
+// selected row is then updated with its own values.
+func createMultiTableUpdate(ctx *plancontext.PlanningContext, upd *sqlparser.Update) (ops.Operator, error) {
+	var targets []*dmlTarget
+	for _, ue := range upd.Exprs {
+		id := ctx.SemTable.DirectDeps(ue.Name)
+		idx := slices.IndexFunc(targets, func(t *dmlTarget) bool { return t.id == id })
+		if idx == -1 {
+			alias := findTableExpr(upd.TableExprs, func(alias *sqlparser.AliasedTableExpr) bool {
+				return ctx.SemTable.TableSetFor(alias) == id
+			})
+			if alias == nil {
+				return nil, vterrors.VT13001(fmt.Sprintf("could not find the table of the column %s", sqlparser.String(ue.Name)))
+			}
+			t, err := newDMLTarget(ctx, alias, "UPDATE")
+			if err != nil {
+				return nil, err
+			}
+			targets = append(targets, t)
+			idx = len(targets) - 1
+		}
+		targets[idx].exprs = append(targets[idx].exprs, ue)
+	}
+
+	return createMultiTableDML(ctx, upd, upd.TableExprs, upd.Where, targets, func(t *dmlTarget, where *sqlparser.Where) (ops.Operator, error) {
+		return createOperatorFromUpdate(ctx, &sqlparser.Update{
+			Comments:   upd.Comments,
+			Ignore:     upd.Ignore,
+			TableExprs: sqlparser.TableExprs{t.alias},
+			Exprs:      t.exprs,
+			Where:      where,
+		})
+	})
+}
+
+func newDMLTarget(ctx *plancontext.PlanningContext, alias *sqlparser.AliasedTableExpr, dmlType string) (*dmlTarget, error) {
+	id := ctx.SemTable.TableSetFor(alias)
+	tableInfo, err := ctx.SemTable.TableInfoFor(id)
+	if err != nil {
+		return nil, err
+	}
+	vTbl := tableInfo.GetVindexTable()
+	if _, isTable := alias.Expr.(sqlparser.TableName); !isTable || vTbl == nil {
+		if dmlType == "DELETE" {
+			return nil, vterrors.VT03004(sqlparser.String(alias.As))
+		}
+		return nil, &semantics.TableNotUpdatableError{Table: alias.As.String()}
+	}
+	if len(vTbl.PrimaryKey) == 0 {
+		return nil, vterrors.VT12001(fmt.Sprintf("cross-shard multi-table %s on the table %s without a known primary key", dmlType, vTbl.Name.String()))
+	}
+	return &dmlTarget{
+		alias: alias,
+		id:    id,
+		vTbl:  vTbl,
+	}, nil
+}
+
+func createMultiTableDML(
+	ctx *plancontext.PlanningContext,
+	stmt sqlparser.Statement,
+	tableExprs sqlparser.TableExprs,
+	where *sqlparser.Where,
+	targets []*dmlTarget,
+	newDML func(t *dmlTarget, where *sqlparser.Where) (ops.Operator, error),
+) (ops.Operator, error) {
+	sel := &sqlparser.Select{
+		From:  tableExprs,
+		Where: where,
+		Lock:  sqlparser.ForUpdateLock,
+	}
+	// the subqueries of the statement are planned with the select.
+	ctx.SemTable.SubqueryMap[sel] = ctx.SemTable.SubqueryMap[stmt]
+
+	bvName := ctx.ReservedVars.ReserveVariable("dml_vals")
+	outputCols := make([][]int, len(targets))
+	bvList := make([]map[string]int, len(targets))
+	var pkCols [][]*sqlparser.ColName
+	for i, t := range targets {
+		qualifier, err := t.alias.TableName()
+		if err != nil {
+			return nil, err
+		}
+		var cols []*sqlparser.ColName
+		for _, pk := range t.vTbl.PrimaryKey {
+			outputCols[i] = append(outputCols[i], len(sel.SelectExprs))
+			sel.SelectExprs = append(sel.SelectExprs, &sqlparser.AliasedExpr{Expr: targetColumn(ctx, t.id, qualifier, pk)})
+			cols = append(cols, targetColumn(ctx, t.id, qualifier, pk))
+		}
+		pkCols = append(pkCols, cols)
+
+		// the new values that can't be computed on the rows of the target table
+		// are selected, and passed to the UPDATE as bind variables.
+		for j, ue := range t.exprs {
+			if !needsInput(ctx, ue.Expr, t.id) {
+				continue
+			}
+			if bvList[i] == nil {
+				bvList[i] = map[string]int{}
+			}
+			arg := bvName + "_" + strconv.Itoa(len(bvList[i]))
+			bvList[i][arg] = len(sel.SelectExprs)
+			sel.SelectExprs = append(sel.SelectExprs, &sqlparser.AliasedExpr{Expr: ue.Expr})
+			t.exprs[j] = &sqlparser.UpdateExpr{Name: ue.Name, Expr: sqlparser.NewArgument(arg)}
+		}
+	}
+
+	source, err := PlanQuery(ctx, sel)
+	if err != nil {
+		return nil, err
+	}
+
+	dmls := make([]ops.Operator, 0, len(targets))
+	for i, t := range targets {
+		cond := targetCondition(bvName, pkCols[i])
+		dml, err := newDML(t, sqlparser.NewWhere(sqlparser.WhereClause, cond))
+		if err != nil {
+			return nil, err
+		}
+		dmls = append(dmls, dml)
+	}
+
+	return &DMLWithInput{
+		Source:     source,
+		DML:        dmls,
+		OutputCols: outputCols,
+		BVList:     bvList,
+		BVName:     bvName,
+	}, nil
+}
+
+// needsInput returns true if the value of the expression can't be computed
+// by an UPDATE of the given table alone.
+func needsInput(ctx *plancontext.PlanningContext, expr sqlparser.Expr, id semantics.TableSet) bool {
+	if !ctx.SemTable.RecursiveDeps(expr).IsSolvedBy(id) {
+		return true
+	}
+	hasSubquery := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, isSubq := node.(*sqlparser.ExtractedSubquery); isSubq {
+			hasSubquery = true
+			return false, nil
+		}
+		return true, nil
+	}, expr)
+	return hasSubquery
+}
+
+// findTableExpr returns the table of the FROM clause matching the given function.
+func findTableExpr(tableExprs sqlparser.TableExprs, match func(*sqlparser.AliasedTableExpr) bool) *sqlparser.AliasedTableExpr {
+	for _, tableExpr := range tableExprs {
+		switch tableExpr := tableExpr.(type) {
+		case *sqlparser.AliasedTableExpr:
+			if match(tableExpr) {
+				return tableExpr
+			}
+		case *sqlparser.JoinTableExpr:
+			if alias := findTableExpr(sqlparser.TableExprs{tableExpr.LeftExpr, tableExpr.RightExpr}, match); alias != nil {
+				return alias
+			}
+		case *sqlparser.ParenTableExpr:
+			if alias := findTableExpr(tableExpr.Exprs, match); alias != nil {
+				return alias
+			}
+		}
+	}
+	return nil
+}
+
+// targetColumn returns a column of the table being changed. These columns
+// are not known to the semantic analysis, their dependencies are set here.
+func targetColumn(ctx *plancontext.PlanningContext, id semantics.TableSet, qualifier sqlparser.TableName, name sqlparser.IdentifierCI) *sqlparser.ColName {
+	col := sqlparser.NewColNameWithQualifier(name.String(), qualifier)
+	ctx.SemTable.Recursive[col] = id
+	ctx.SemTable.Direct[col] = id
+	return col
+}
+
+// targetCondition returns the condition matching the rows selected for a DML:
+// the primary key is compared with the bvName list, which holds values for a
+// single column primary key, and tuples otherwise.
+func targetCondition(bvName string, pkCols []*sqlparser.ColName) sqlparser.Expr {
+	var left sqlparser.Expr = pkCols[0]
+	if len(pkCols) > 1 {
+		tuple := make(sqlparser.ValTuple, 0, len(pkCols))
+		for _, col := range pkCols {
+			tuple = append(tuple, col)
+		}
+		left = tuple
+	}
+	return &sqlparser.ComparisonExpr{
+		Operator: sqlparser.InOp,
+		Left:     left,
+		Right:    sqlparser.ListArg(bvName),
+	}
+}
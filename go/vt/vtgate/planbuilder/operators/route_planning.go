@@ -169,22 +169,9 @@ func buildVindexTableForDML(
 	return vindexTable, routing, nil
 }
 
-func generateOwnedVindexQuery(tblExpr sqlparser.TableExpr, del *sqlparser.Delete, table *vindexes.Table, ksidCols []sqlparser.IdentifierCI) string {
-	buf := sqlparser.NewTrackedBuffer(nil)
-	for idx, col := range ksidCols {
-		if idx == 0 {
-			buf.Myprintf("select %v", col)
-		} else {
-			buf.Myprintf(", %v", col)
-		}
-	}
-	for _, cv := range table.Owned {
-		for _, column := range cv.Columns {
-			buf.Myprintf(", %v", column)
-		}
-	}
-	buf.Myprintf(" from %v%v%v%v for update", tblExpr, del.Where, del.OrderBy, del.Limit)
-	return buf.String()
+func generateOwnedVindexQuery(tblExpr sqlparser.TableExpr, del *sqlparser.Delete, table *vindexes.Table, ksidCols []sqlparser.IdentifierCI) *sqlparser.Select {
+	selExprs, _ := initialQuery(ksidCols, table)
+	return ownedVindexQuery(selExprs, tblExpr, del.Where, del.OrderBy, del.Limit)
 }
 
 func getUpdateVindexInformation(
@@ -192,19 +179,19 @@ func getUpdateVindexInformation(
 	vindexTable *vindexes.Table,
 	tableID semantics.TableSet,
 	predicates []sqlparser.Expr,
-) ([]*VindexPlusPredicates, map[string]*engine.VindexValues, string, error) {
+) ([]*VindexPlusPredicates, map[string]*engine.VindexValues, *sqlparser.Select, error) {
 	if !vindexTable.Keyspace.Sharded {
-		return nil, nil, "", nil
+		return nil, nil, nil, nil
 	}
 
 	primaryVindex, vindexAndPredicates, err := getVindexInformation(tableID, predicates, vindexTable)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, err
 	}
 
 	changedVindexValues, ownedVindexQuery, err := buildChangedVindexesValues(updStmt, vindexTable, primaryVindex.Columns)
 	if err != nil {
-		return nil, nil, "", err
+		return nil, nil, nil, err
 	}
 	return vindexAndPredicates, changedVindexValues, ownedVindexQuery, nil
 }
@@ -29,7 +29,7 @@ type Update struct {
 	VTable              *vindexes.Table
 	Assignments         map[string]sqlparser.Expr
 	ChangedVindexValues map[string]*engine.VindexValues
-	OwnedVindexQuery    string
+	OwnedVindexQuery    *sqlparser.Select
 	AST                 *sqlparser.Update
 
 	noInputs
@@ -291,6 +291,8 @@ func TestPlan(t *testing.T) {
 	testFile(t, "reference_cases.json", testOutputTempDir, vschemaWrapper, false)
 	testFile(t, "vexplain_cases.json", testOutputTempDir, vschemaWrapper, false)
 	testFile(t, "misc_cases.json", testOutputTempDir, vschemaWrapper, false)
+	testFile(t, "cte_cases.json", testOutputTempDir, vschemaWrapper, false)
+	testFile(t, "window_cases.json", testOutputTempDir, vschemaWrapper, false)
 }
 
 func TestSystemTables57(t *testing.T) {
@@ -584,6 +586,15 @@ func loadSchema(t testing.TB, filename string, setCollation bool) *vindexes.VSch
 	if err != nil {
 		t.Fatal(err)
 	}
+	// the primary keys of the tables, per keyspace
+	var primaryKeys map[string]map[string][]string
+	pkData, err := os.ReadFile(locateFile("vschemas/primary_keys.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(pkData, &primaryKeys); err != nil {
+		t.Fatal(err)
+	}
 	for _, ks := range vschema.Keyspaces {
 		if ks.Error != nil {
 			t.Fatal(ks.Error)
@@ -598,6 +609,17 @@ func loadSchema(t testing.TB, filename string, setCollation bool) *vindexes.VSch
 			}
 		}
 
+		// setting the primary keys of the tables, as the schema tracker would.
+		for tbl, pk := range primaryKeys[ks.Keyspace.Name] {
+			table, ok := ks.Tables[tbl]
+			if !ok {
+				continue
+			}
+			for _, col := range pk {
+				table.PrimaryKey = append(table.PrimaryKey, sqlparser.NewIdentifierCI(col))
+			}
+		}
+
 		// setting a default value to all the text columns in the tables of this keyspace
 		// so that we can "simulate" a real case scenario where the vschema is aware of
 		// columns' collations.
@@ -0,0 +1,500 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package planbuilder
+
+import (
+	"fmt"
+	"strconv"
+
+	"vitess.io/vitess/go/mysql/collations"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+// isWindowFunc returns true if the expression is a window function
+func isWindowFunc(node sqlparser.SQLNode) bool {
+	switch node.(type) {
+	case *sqlparser.ArgumentLessWindowExpr, *sqlparser.FirstOrLastValueExpr, *sqlparser.NtileExpr,
+		*sqlparser.NTHValueExpr, *sqlparser.LagLeadExpr, *sqlparser.AggregateWindowExpr:
+		return true
+	}
+	return false
+}
+
+// containsWindowFunc returns true if the node contains a window function
+func containsWindowFunc(node sqlparser.SQLNode) bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if isWindowFunc(node) {
+			found = true
+			return false, nil
+		}
+		return !found, nil
+	}, node)
+	return found
+}
+
+// windowPlan holds the pieces needed to evaluate the window functions of a
+// query in vtgate: the query sent to the shards, and the Window primitive
+// that will read its results.
+type windowPlan struct {
+	inner  *sqlparser.Select
+	window *engine.Window
+
+	// order is the ordering of the output of the Window primitive
+	// required by the ORDER BY clause of the query
+	order []engine.OrderByParams
+	// visible is the number of columns returned to the user
+	visible int
+}
+
+// gen4WindowPlanner plans a SELECT that uses window functions. If the query can be
+// sent to a single shard, MySQL evaluates the window functions. Otherwise, the
+// window functions are removed from the query sent to the shards, which instead
+// returns the arguments of the window functions sorted by the PARTITION BY and
+// ORDER BY clauses of the window, and the window functions are evaluated in
+// vtgate by engine.Window over the merge-sorted rows.
+func gen4WindowPlanner(
+	plannerVersion querypb.ExecuteOptions_PlannerVersion,
+	sel *sqlparser.Select,
+	reservedVars *sqlparser.ReservedVars,
+	vschema plancontext.VSchema,
+) (*planResult, error) {
+	plan, _, tablesUsed, err := newBuildSelectPlan(sqlparser.CloneSelectStatement(sel), reservedVars, vschema, plannerVersion)
+	if err == nil {
+		if route, ok := plan.Primitive().(*engine.Route); ok && (route.Opcode.IsSingleShard() || route.Opcode == engine.None) {
+			return newPlanResult(route, tablesUsed...), nil
+		}
+	}
+
+	wp, err := buildWindowPlan(sel)
+	if err != nil {
+		return nil, err
+	}
+	innerPlan, semTable, tablesUsed, err := newBuildSelectPlan(wp.inner, reservedVars, vschema, plannerVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	collationFor := func(expr sqlparser.Expr) collations.ID {
+		if coll := semTable.CollationForExpr(expr); coll != collations.Unknown {
+			return coll
+		}
+		return vschema.ConnCollation()
+	}
+	w := wp.window
+	for i := range w.PartitionBy {
+		w.PartitionBy[i].CollationID = collationFor(wp.column(w.PartitionBy[i].Col))
+	}
+	for i := range w.OrderBy {
+		w.OrderBy[i].CollationID = collationFor(wp.column(w.OrderBy[i].Col))
+	}
+	for _, f := range w.Functions {
+		if f.Opcode == opcode.WindowMin || f.Opcode == opcode.WindowMax {
+			f.CollationID = collationFor(wp.column(f.Col))
+		}
+	}
+	w.Input = innerPlan.Primitive()
+
+	var prim engine.Primitive = w
+	if len(wp.order) > 0 {
+		for i, order := range wp.order {
+			if col := w.Cols[order.Col]; col >= 0 {
+				wp.order[i].CollationID = collationFor(wp.column(col))
+			} else {
+				wp.order[i].CollationID = vschema.ConnCollation()
+			}
+		}
+		ms := &engine.MemorySort{OrderBy: wp.order, Input: prim}
+		if len(w.Cols) > wp.visible {
+			ms.TruncateColumnCount = wp.visible
+		}
+		prim = ms
+	}
+	prim, err = planLimitOnTopOfWindow(sel, prim)
+	if err != nil {
+		return nil, err
+	}
+	return newPlanResult(prim, tablesUsed...), nil
+}
+
+// buildWindowPlan builds the query sent to the shards, and the Window primitive
+// evaluating the window functions of the query. Only window functions used as
+// select expressions are supported, and they must all share the same PARTITION BY
+// and ORDER BY clauses.
+func buildWindowPlan(sel *sqlparser.Select) (*windowPlan, error) {
+	switch {
+	case sel.Distinct:
+		return nil, vterrors.VT12001("DISTINCT with window functions across shards")
+	case len(sel.GroupBy) > 0 || sel.Having != nil || sqlparser.ContainsAggregation(sel.SelectExprs):
+		return nil, vterrors.VT12001("aggregation with window functions across shards")
+	case sel.Where != nil && containsWindowFunc(sel.Where):
+		return nil, vterrors.VT03008("window function in WHERE")
+	}
+
+	inner := sqlparser.CloneRefOfSelect(sel)
+	inner.SelectExprs = nil
+	inner.OrderBy = nil
+	inner.Limit = nil
+	inner.Windows = nil
+
+	wp := &windowPlan{
+		inner:  inner,
+		window: &engine.Window{},
+	}
+
+	var spec *sqlparser.WindowSpecification
+	var windowExprs []*sqlparser.AliasedExpr
+	for _, expr := range sel.SelectExprs {
+		ae, ok := expr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return nil, vterrors.VT12001(fmt.Sprintf("'%s' with window functions across shards", sqlparser.String(expr)))
+		}
+		if !isWindowFunc(ae.Expr) {
+			if containsWindowFunc(ae.Expr) {
+				return nil, vterrors.VT12001(fmt.Sprintf("window function inside the expression '%s' across shards", sqlparser.String(ae.Expr)))
+			}
+			inner.SelectExprs = append(inner.SelectExprs, sqlparser.CloneRefOfAliasedExpr(ae))
+			wp.window.Cols = append(wp.window.Cols, len(inner.SelectExprs)-1)
+			continue
+		}
+
+		fnSpec, err := resolveWindowSpec(sel, overClause(ae.Expr))
+		if err != nil {
+			return nil, err
+		}
+		if spec == nil {
+			spec = fnSpec
+		} else if !sqlparser.Equals.Exprs(spec.PartitionClause, fnSpec.PartitionClause) || !sqlparser.Equals.OrderBy(spec.OrderClause, fnSpec.OrderClause) {
+			return nil, vterrors.VT12001("window functions with different PARTITION BY or ORDER BY clauses across shards")
+		}
+		windowExprs = append(windowExprs, ae)
+		wp.window.Cols = append(wp.window.Cols, -len(windowExprs))
+	}
+
+	for _, ae := range windowExprs {
+		f, err := wp.buildWindowFunc(ae, sel)
+		if err != nil {
+			return nil, err
+		}
+		wp.window.Functions = append(wp.window.Functions, f)
+	}
+
+	for _, expr := range spec.PartitionClause {
+		wp.window.PartitionBy = append(wp.window.PartitionBy, engine.OrderByParams{
+			Col:             wp.addColumn(expr),
+			WeightStringCol: -1,
+		})
+		inner.OrderBy = append(inner.OrderBy, &sqlparser.Order{Expr: sqlparser.CloneExpr(expr), Direction: sqlparser.AscOrder})
+	}
+	for _, order := range spec.OrderClause {
+		wp.window.OrderBy = append(wp.window.OrderBy, engine.OrderByParams{
+			Col:             wp.addColumn(order.Expr),
+			WeightStringCol: -1,
+			Desc:            order.Direction == sqlparser.DescOrder,
+		})
+		inner.OrderBy = append(inner.OrderBy, sqlparser.CloneRefOfOrder(order))
+	}
+
+	wp.visible = len(wp.window.Cols)
+	for _, order := range sel.OrderBy {
+		offset, err := wp.outputOffset(sel, order.Expr)
+		if err != nil {
+			return nil, err
+		}
+		wp.order = append(wp.order, engine.OrderByParams{
+			Col:             offset,
+			WeightStringCol: -1,
+			Desc:            order.Direction == sqlparser.DescOrder,
+		})
+	}
+	return wp, nil
+}
+
+// outputOffset returns the offset of an ORDER BY expression in the output of the
+// Window primitive. Expressions that are not selected are added as hidden columns.
+func (wp *windowPlan) outputOffset(sel *sqlparser.Select, expr sqlparser.Expr) (int, error) {
+	for i, se := range sel.SelectExprs {
+		ae := se.(*sqlparser.AliasedExpr)
+		if col, ok := expr.(*sqlparser.ColName); ok && col.Qualifier.IsEmpty() && !ae.As.IsEmpty() && col.Name.Equal(ae.As) {
+			return i, nil
+		}
+		if sqlparser.Equals.Expr(expr, ae.Expr) {
+			return i, nil
+		}
+	}
+	if containsWindowFunc(expr) {
+		return 0, vterrors.VT12001(fmt.Sprintf("ORDER BY '%s' with window functions across shards", sqlparser.String(expr)))
+	}
+	wp.window.Cols = append(wp.window.Cols, wp.addColumn(expr))
+	return len(wp.window.Cols) - 1, nil
+}
+
+// column returns the expression of the inner query at the given offset
+func (wp *windowPlan) column(offset int) sqlparser.Expr {
+	return wp.inner.SelectExprs[offset].(*sqlparser.AliasedExpr).Expr
+}
+
+// addColumn returns the offset of the expression in the inner query,
+// adding it to the select expressions if it is not already there
+func (wp *windowPlan) addColumn(expr sqlparser.Expr) int {
+	for i, se := range wp.inner.SelectExprs {
+		if sqlparser.Equals.Expr(se.(*sqlparser.AliasedExpr).Expr, expr) {
+			return i
+		}
+	}
+	wp.inner.SelectExprs = append(wp.inner.SelectExprs, &sqlparser.AliasedExpr{Expr: sqlparser.CloneExpr(expr)})
+	return len(wp.inner.SelectExprs) - 1
+}
+
+func overClause(expr sqlparser.Expr) *sqlparser.OverClause {
+	switch expr := expr.(type) {
+	case *sqlparser.ArgumentLessWindowExpr:
+		return expr.OverClause
+	case *sqlparser.FirstOrLastValueExpr:
+		return expr.OverClause
+	case *sqlparser.NtileExpr:
+		return expr.OverClause
+	case *sqlparser.NTHValueExpr:
+		return expr.OverClause
+	case *sqlparser.LagLeadExpr:
+		return expr.OverClause
+	case *sqlparser.AggregateWindowExpr:
+		return expr.OverClause
+	}
+	return nil
+}
+
+// resolveWindowSpec returns the window specification of an OVER clause,
+// merging in the named windows it refers to
+func resolveWindowSpec(sel *sqlparser.Select, over *sqlparser.OverClause) (*sqlparser.WindowSpecification, error) {
+	spec := &sqlparser.WindowSpecification{Name: over.WindowName}
+	if over.WindowSpec != nil {
+		spec = sqlparser.CloneRefOfWindowSpecification(over.WindowSpec)
+	}
+	for seen := 0; !spec.Name.IsEmpty(); seen++ {
+		if seen > len(sel.Windows) {
+			return nil, vterrors.VT12001(fmt.Sprintf("circular reference to window '%s'", spec.Name.String()))
+		}
+		named := findNamedWindow(sel, spec.Name)
+		if named == nil {
+			return nil, vterrors.VT03026(spec.Name.String())
+		}
+		if len(named.PartitionClause) > 0 {
+			if len(spec.PartitionClause) > 0 {
+				return nil, vterrors.VT12001(fmt.Sprintf("overriding the PARTITION BY clause of window '%s'", spec.Name.String()))
+			}
+			spec.PartitionClause = named.PartitionClause
+		}
+		if len(named.OrderClause) > 0 {
+			if len(spec.OrderClause) > 0 {
+				return nil, vterrors.VT12001(fmt.Sprintf("overriding the ORDER BY clause of window '%s'", spec.Name.String()))
+			}
+			spec.OrderClause = named.OrderClause
+		}
+		if spec.FrameClause == nil {
+			spec.FrameClause = named.FrameClause
+		}
+		spec.Name = named.Name
+	}
+	return spec, nil
+}
+
+func findNamedWindow(sel *sqlparser.Select, name sqlparser.IdentifierCI) *sqlparser.WindowSpecification {
+	for _, nw := range sel.Windows {
+		for _, def := range nw.Windows {
+			if def.Name.Equal(name) {
+				return def.WindowSpec
+			}
+		}
+	}
+	return nil
+}
+
+// buildWindowFunc translates a window function to its engine representation
+func (wp *windowPlan) buildWindowFunc(ae *sqlparser.AliasedExpr, sel *sqlparser.Select) (*engine.WindowFunc, error) {
+	unsupported := func(what string) error {
+		return vterrors.VT12001(fmt.Sprintf("%s in window function '%s' across shards", what, sqlparser.String(ae.Expr)))
+	}
+	f := &engine.WindowFunc{
+		Col:        -1,
+		DefaultCol: -1,
+		Alias:      ae.ColumnName(),
+	}
+	switch expr := ae.Expr.(type) {
+	case *sqlparser.ArgumentLessWindowExpr:
+		switch expr.Type {
+		case sqlparser.RowNumberExprType:
+			f.Opcode = opcode.WindowRowNumber
+		case sqlparser.RankExprType:
+			f.Opcode = opcode.WindowRank
+		case sqlparser.DenseRankExprType:
+			f.Opcode = opcode.WindowDenseRank
+		case sqlparser.PercentRankExprType:
+			f.Opcode = opcode.WindowPercentRank
+		case sqlparser.CumeDistExprType:
+			f.Opcode = opcode.WindowCumeDist
+		}
+	case *sqlparser.NtileExpr:
+		f.Opcode = opcode.WindowNtile
+		n, ok := positiveIntLiteral(expr.N, 1)
+		if !ok {
+			return nil, unsupported("non-literal or non-positive argument")
+		}
+		f.N = n
+	case *sqlparser.LagLeadExpr:
+		if expr.NullTreatmentClause != nil && expr.NullTreatmentClause.Type == sqlparser.IgnoreNullsType {
+			return nil, unsupported("IGNORE NULLS")
+		}
+		f.Opcode = opcode.WindowLag
+		if expr.Type == sqlparser.LeadExprType {
+			f.Opcode = opcode.WindowLead
+		}
+		f.N = 1
+		if expr.N != nil {
+			n, ok := positiveIntLiteral(expr.N, 0)
+			if !ok {
+				return nil, unsupported("non-literal or negative offset")
+			}
+			f.N = n
+		}
+		f.Col = wp.addColumn(expr.Expr)
+		if expr.Default != nil {
+			f.DefaultCol = wp.addColumn(expr.Default)
+		}
+	case *sqlparser.FirstOrLastValueExpr:
+		if expr.NullTreatmentClause != nil && expr.NullTreatmentClause.Type == sqlparser.IgnoreNullsType {
+			return nil, unsupported("IGNORE NULLS")
+		}
+		f.Opcode = opcode.WindowFirstValue
+		if expr.Type == sqlparser.LastValueExprType {
+			f.Opcode = opcode.WindowLastValue
+		}
+		f.Col = wp.addColumn(expr.Expr)
+	case *sqlparser.NTHValueExpr:
+		if expr.NullTreatmentClause != nil && expr.NullTreatmentClause.Type == sqlparser.IgnoreNullsType {
+			return nil, unsupported("IGNORE NULLS")
+		}
+		if expr.FromFirstLastClause != nil && expr.FromFirstLastClause.Type == sqlparser.FromLastType {
+			return nil, unsupported("FROM LAST")
+		}
+		f.Opcode = opcode.WindowNthValue
+		n, ok := positiveIntLiteral(expr.N, 1)
+		if !ok {
+			return nil, unsupported("non-literal or non-positive position")
+		}
+		f.N = n
+		f.Col = wp.addColumn(expr.Expr)
+	case *sqlparser.AggregateWindowExpr:
+		if expr.Distinct {
+			return nil, unsupported("DISTINCT")
+		}
+		switch expr.Type {
+		case sqlparser.CountWindowExprType:
+			f.Opcode = opcode.WindowCount
+			if expr.Arg == nil {
+				f.Opcode = opcode.WindowCountStar
+			}
+		case sqlparser.SumWindowExprType:
+			f.Opcode = opcode.WindowSum
+		case sqlparser.AvgWindowExprType:
+			f.Opcode = opcode.WindowAvg
+		case sqlparser.MinWindowExprType:
+			f.Opcode = opcode.WindowMin
+		case sqlparser.MaxWindowExprType:
+			f.Opcode = opcode.WindowMax
+		}
+		if expr.Arg != nil {
+			f.Col = wp.addColumn(expr.Arg)
+		}
+	}
+
+	spec, err := resolveWindowSpec(sel, overClause(ae.Expr))
+	if err != nil {
+		return nil, err
+	}
+	frame, err := windowFrame(spec)
+	if err != nil {
+		return nil, unsupported(fmt.Sprintf("frame '%s'", sqlparser.String(spec.FrameClause)))
+	}
+	f.Frame = frame
+	return f, nil
+}
+
+// windowFrame returns the engine frame matching the window specification.
+// Only the frames starting at the first row of the partition are supported.
+func windowFrame(spec *sqlparser.WindowSpecification) (engine.WindowFrame, error) {
+	frame := spec.FrameClause
+	if frame == nil {
+		if len(spec.OrderClause) == 0 {
+			return engine.WindowFramePartition, nil
+		}
+		return engine.WindowFrameRange, nil
+	}
+	if frame.Start == nil || frame.Start.Type != sqlparser.UnboundedPrecedingType {
+		return 0, vterrors.VT12001("window frame")
+	}
+	switch {
+	case frame.End != nil && frame.End.Type == sqlparser.UnboundedFollowingType:
+		return engine.WindowFramePartition, nil
+	case frame.End != nil && frame.End.Type != sqlparser.CurrentRowType:
+		return 0, vterrors.VT12001("window frame")
+	case frame.Unit == sqlparser.FrameRowsType:
+		return engine.WindowFrameRows, nil
+	case len(spec.OrderClause) == 0:
+		return engine.WindowFramePartition, nil
+	default:
+		return engine.WindowFrameRange, nil
+	}
+}
+
+// positiveIntLiteral returns the value of an integer literal, if it is at least min
+func positiveIntLiteral(expr sqlparser.Expr, min int) (int, bool) {
+	lit, ok := expr.(*sqlparser.Literal)
+	if !ok || lit.Type != sqlparser.IntVal {
+		return 0, false
+	}
+	n, err := strconv.Atoi(lit.Val)
+	if err != nil || n < min {
+		return 0, false
+	}
+	return n, true
+}
+
+// planLimitOnTopOfWindow adds the LIMIT clause of the query on top of the primitive
+func planLimitOnTopOfWindow(sel *sqlparser.Select, prim engine.Primitive) (engine.Primitive, error) {
+	if sel.Limit == nil {
+		return prim, nil
+	}
+	count, err := evalengine.Translate(sel.Limit.Rowcount, nil)
+	if err != nil {
+		return nil, vterrors.Wrap(err, "unexpected expression in LIMIT")
+	}
+	limit := &engine.Limit{Count: count, Input: prim}
+	if sel.Limit.Offset != nil {
+		limit.Offset, err = evalengine.Translate(sel.Limit.Offset, nil)
+		if err != nil {
+			return nil, vterrors.Wrap(err, "unexpected expression in OFFSET")
+		}
+	}
+	return limit, nil
+}
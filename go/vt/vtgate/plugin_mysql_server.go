@@ -69,6 +69,8 @@ var (
 	mysqlSlowConnectWarnThreshold time.Duration
 	mysqlConnBufferPooling        bool
 
+	mysqlServerCompressionAlgorithms []string
+
 	mysqlDefaultWorkloadName = "OLTP"
 	mysqlDefaultWorkload     int32
 
@@ -96,6 +98,7 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&mysqlQueryTimeout, "mysql_server_query_timeout", mysqlQueryTimeout, "mysql query timeout")
 	fs.BoolVar(&mysqlConnBufferPooling, "mysql-server-pool-conn-read-buffers", mysqlConnBufferPooling, "If set, the server will pool incoming connection read buffers")
 	fs.DurationVar(&mysqlKeepAlivePeriod, "mysql-server-keepalive-period", mysqlKeepAlivePeriod, "TCP period between keep-alives")
+	fs.StringSliceVar(&mysqlServerCompressionAlgorithms, "mysql-server-compression-algorithms", mysqlServerCompressionAlgorithms, "Protocol compression algorithms the server accepts, in addition to uncompressed connections. Clients choose which one to use, if any. Options: zlib, zstd.")
 	fs.StringVar(&mysqlDefaultWorkloadName, "mysql_default_workload", mysqlDefaultWorkloadName, "Default session workload (OLTP, OLAP, DBA)")
 }
 
@@ -236,15 +239,16 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 		}
 	}()
 
+	bindVars := queryAttributeBindVars(make(map[string]*querypb.BindVariable), c.QueryAttributes())
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
-		session, err := vh.vtg.StreamExecute(ctx, vh, session, query, make(map[string]*querypb.BindVariable), callback)
+		session, err := vh.vtg.StreamExecute(ctx, vh, session, query, bindVars, callback)
 		if err != nil {
 			return mysql.NewSQLErrorFromError(err)
 		}
 		fillInTxStatusFlags(c, session)
 		return nil
 	}
-	session, result, err := vh.vtg.Execute(ctx, vh, session, query, make(map[string]*querypb.BindVariable))
+	session, result, err := vh.vtg.Execute(ctx, vh, session, query, bindVars)
 
 	if err := mysql.NewSQLErrorFromError(err); err != nil {
 		return err
@@ -342,15 +346,16 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		}
 	}()
 
+	bindVars := queryAttributeBindVars(prepare.BindVars, c.QueryAttributes())
 	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
-		_, err := vh.vtg.StreamExecute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars, callback)
+		_, err := vh.vtg.StreamExecute(ctx, vh, session, prepare.PrepareStmt, bindVars, callback)
 		if err != nil {
 			return mysql.NewSQLErrorFromError(err)
 		}
 		fillInTxStatusFlags(c, session)
 		return nil
 	}
-	_, qr, err := vh.vtg.Execute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars)
+	_, qr, err := vh.vtg.Execute(ctx, vh, session, prepare.PrepareStmt, bindVars)
 	if err != nil {
 		return mysql.NewSQLErrorFromError(err)
 	}
@@ -523,6 +528,9 @@ func initMySQLProtocol() {
 			_ = initTLSConfig(mysqlListener, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlServerRequireSecureTransport, tlsVersion)
 		}
 		mysqlListener.AllowClearTextWithoutTLS.Store(mysqlAllowClearTextWithoutTLS)
+		if err := mysqlListener.SetCompressionAlgorithms(mysqlServerCompressionAlgorithms); err != nil {
+			log.Exitf("mysql.NewListener failed: %v", err)
+		}
 		// Check for the connection threshold
 		if mysqlSlowConnectWarnThreshold != 0 {
 			log.Infof("setting mysql slow connection threshold to %v", mysqlSlowConnectWarnThreshold)
@@ -542,6 +550,9 @@ func initMySQLProtocol() {
 			log.Exitf("mysql.NewListener failed: %v", err)
 			return
 		}
+		if err := mysqlUnixListener.SetCompressionAlgorithms(mysqlServerCompressionAlgorithms); err != nil {
+			log.Exitf("mysql.NewListener failed: %v", err)
+		}
 		// Listen for unix socket
 		go mysqlUnixListener.Accept()
 	}
@@ -34,6 +34,7 @@ import (
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/trace"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
 	"vitess.io/vitess/go/vt/tlstest"
 )
 
@@ -335,3 +336,58 @@ func TestKillMethods(t *testing.T) {
 	require.EqualError(t, ctx.Err(), "context canceled")
 	require.True(t, mysqlConn.IsMarkedForClose())
 }
+
+func TestComStmtExecuteQueryAttributes(t *testing.T) {
+	executor, _, _, sbclookup := createExecutorEnv()
+	vtg := *rpcVTGate
+	vtg.executor = executor
+	vh := newVtgateHandler(&vtg)
+
+	mysqlConn := mysql.GetTestConn()
+	mysqlConn.UserData = &mysql.StaticUserData{}
+	mysqlConn.ClientData = &vtgatepb.Session{
+		TargetString: KsTestUnsharded,
+		Autocommit:   true,
+		Options:      &querypb.ExecuteOptions{},
+	}
+	prepare := &mysql.PrepareData{
+		PrepareStmt: "select id from main1",
+		BindVars:    map[string]*querypb.BindVariable{},
+	}
+	callback := func(*sqltypes.Result) error { return nil }
+
+	// The query attributes apply to the execution they were sent with.
+	mysqlConn.SetTestQueryAttributes(map[string]sqltypes.Value{
+		"workload_name": sqltypes.NewVarBinary("report"),
+	})
+	err := vh.ComStmtExecute(mysqlConn, prepare, callback)
+	require.NoError(t, err)
+	require.Len(t, sbclookup.Queries, 1)
+	assert.Equal(t, "select /*vt+ WORKLOAD_NAME=report */ id from main1", sbclookup.Queries[0].Sql)
+	assert.Empty(t, prepare.BindVars)
+
+	// They don't carry over to the next execution of the statement.
+	sbclookup.Queries = nil
+	mysqlConn.SetTestQueryAttributes(nil)
+	err = vh.ComStmtExecute(mysqlConn, prepare, callback)
+	require.NoError(t, err)
+	require.Len(t, sbclookup.Queries, 1)
+	assert.Equal(t, "select id from main1", sbclookup.Queries[0].Sql)
+
+	// A COM_STMT_RESET clears the bind variables of the statement, which
+	// must not leave unset query attributes behind.
+	mysqlConn.SetTestQueryAttributes(map[string]sqltypes.Value{
+		"workload_name": sqltypes.NewVarBinary("report"),
+	})
+	err = vh.ComStmtExecute(mysqlConn, prepare, callback)
+	require.NoError(t, err)
+	for name := range prepare.BindVars {
+		prepare.BindVars[name] = nil
+	}
+	sbclookup.Queries = nil
+	mysqlConn.SetTestQueryAttributes(nil)
+	err = vh.ComStmtExecute(mysqlConn, prepare, callback)
+	require.NoError(t, err)
+	require.Len(t, sbclookup.Queries, 1)
+	assert.Equal(t, "select id from main1", sbclookup.Queries[0].Sql)
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Query attributes sent by MySQL clients are passed to the executor as
+// bind variables prefixed with sqlparser.QueryAttributePrefix. Being
+// bind variables, they are forwarded to the tablets with the queries,
+// where query rules can match on them.
+//
+// A few query attributes are also interpreted by vtgate:
+//   - tablet_type overrides the tablet type of the session target for
+//     the query.
+//   - the attributes named after a comment directive, such as
+//     workload_name or query_timeout_ms, act as if the directive was
+//     set in the query. Directives in the query take precedence.
+
+// queryAttributeTabletType is the query attribute that overrides the
+// tablet type targeted by the query.
+const queryAttributeTabletType = "tablet_type"
+
+// queryAttributeDirectives are the comment directives that can be set
+// through query attributes, keyed by the lower case attribute name.
+var queryAttributeDirectives = func() map[string]string {
+	directives := []string{
+		sqlparser.DirectiveMultiShardAutocommit,
+		sqlparser.DirectiveSkipQueryPlanCache,
+		sqlparser.DirectiveQueryTimeout,
+		sqlparser.DirectiveScatterErrorsAsWarnings,
+		sqlparser.DirectiveIgnoreMaxMemoryRows,
+		sqlparser.DirectiveAllowScatter,
+		sqlparser.DirectiveAllowHashJoin,
+		sqlparser.DirectiveQueryPlanner,
+		sqlparser.DirectiveConsolidator,
+		sqlparser.DirectiveWorkloadName,
+		sqlparser.DirectivePriority,
+	}
+	m := make(map[string]string, len(directives))
+	for _, directive := range directives {
+		m[strings.ToLower(directive)] = directive
+	}
+	return m
+}()
+
+// queryAttributeBindVars returns bindVars with the query attributes added,
+// so that they can be passed to the executor. bindVars itself is never
+// modified: the attributes only apply to the command they were sent with,
+// and the bind variables of a prepared statement outlive its executions.
+func queryAttributeBindVars(bindVars map[string]*querypb.BindVariable, attributes map[string]sqltypes.Value) map[string]*querypb.BindVariable {
+	if len(attributes) == 0 {
+		return bindVars
+	}
+	result := make(map[string]*querypb.BindVariable, len(bindVars)+len(attributes))
+	for name, bv := range bindVars {
+		result[name] = bv
+	}
+	for name, value := range attributes {
+		result[sqlparser.QueryAttributePrefix+name] = sqltypes.ValueBindVariable(value)
+	}
+	return result
+}
+
+// queryAttributes returns the query attributes found in bindVars, keyed
+// by their name.
+func queryAttributes(bindVars map[string]*querypb.BindVariable) map[string]*querypb.BindVariable {
+	var attributes map[string]*querypb.BindVariable
+	for name, bv := range bindVars {
+		name, ok := strings.CutPrefix(name, sqlparser.QueryAttributePrefix)
+		if !ok {
+			continue
+		}
+		if attributes == nil {
+			attributes = make(map[string]*querypb.BindVariable)
+		}
+		attributes[name] = bv
+	}
+	return attributes
+}
+
+// queryAttributeString returns the value of a query attribute as a
+// string, and whether it is set.
+func queryAttributeString(attributes map[string]*querypb.BindVariable, name string) (string, bool) {
+	for attr, bv := range attributes {
+		if strings.EqualFold(attr, name) && bv.Type != querypb.Type_NULL_TYPE {
+			return string(bv.Value), true
+		}
+	}
+	return "", false
+}
+
+// applyQueryAttributeTabletType overrides the tablet type of vcursor with
+// the one set by the tablet_type query attribute, if any.
+func applyQueryAttributeTabletType(vcursor *vcursorImpl, attributes map[string]*querypb.BindVariable) error {
+	val, ok := queryAttributeString(attributes, queryAttributeTabletType)
+	if !ok {
+		return nil
+	}
+	tabletType, err := topoproto.ParseTabletType(val)
+	if err != nil {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid %s query attribute: %v", queryAttributeTabletType, err)
+	}
+	if vcursor.safeSession.InTransaction() && tabletType != topodatapb.TabletType_PRIMARY {
+		return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.LockOrActiveTransaction, "can't execute the given command because you have an active transaction")
+	}
+	vcursor.tabletType = tabletType
+	return nil
+}
+
+// applyQueryAttributeDirectives adds the comment directives set through
+// query attributes to stmt. The directives are prepended to the comments
+// of the statement, so that the ones already present take precedence.
+// It returns true if stmt was modified.
+func applyQueryAttributeDirectives(stmt sqlparser.Statement, attributes map[string]*querypb.BindVariable) (bool, error) {
+	commented, ok := stmt.(sqlparser.Commented)
+	if !ok || len(attributes) == 0 {
+		return false, nil
+	}
+
+	var directives []string
+	for name, bv := range attributes {
+		directive, ok := queryAttributeDirectives[strings.ToLower(name)]
+		if !ok || bv.Type == querypb.Type_NULL_TYPE {
+			continue
+		}
+		val := string(bv.Value)
+		if val == "" || strings.IndexFunc(val, unicode.IsSpace) >= 0 || strings.Contains(val, "*/") {
+			return false, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid value for the %s query attribute: '%s'", name, val)
+		}
+		directives = append(directives, directive+"="+val)
+	}
+	if len(directives) == 0 {
+		return false, nil
+	}
+	sort.Strings(directives)
+
+	comment := "/*vt+ " + strings.Join(directives, " ") + " */"
+	commented.SetComments(commented.GetParsedComments().Prepend(comment))
+	return true, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestQueryAttributeDirectives(t *testing.T) {
+	tcases := []struct {
+		query      string
+		attributes map[string]sqltypes.Value
+		want       string
+		modified   bool
+		err        string
+	}{{
+		query: "select 1 from user",
+		attributes: map[string]sqltypes.Value{
+			"workload_name": sqltypes.NewVarBinary("report"),
+			"PRIORITY":      sqltypes.NewInt64(10),
+			"tag":           sqltypes.NewVarBinary("ignored"),
+		},
+		want:     "select /*vt+ PRIORITY=10 WORKLOAD_NAME=report */ 1 from `user`",
+		modified: true,
+	}, {
+		query: "select /*vt+ WORKLOAD_NAME=batch */ 1 from user",
+		attributes: map[string]sqltypes.Value{
+			"workload_name": sqltypes.NewVarBinary("report"),
+		},
+		want:     "select /*vt+ WORKLOAD_NAME=report */ /*vt+ WORKLOAD_NAME=batch */ 1 from `user`",
+		modified: true,
+	}, {
+		query: "update user set a = 1",
+		attributes: map[string]sqltypes.Value{
+			"multi_shard_autocommit": sqltypes.NewVarBinary("true"),
+			"consolidator":           sqltypes.NULL,
+		},
+		want:     "update /*vt+ MULTI_SHARD_AUTOCOMMIT=true */ `user` set a = 1",
+		modified: true,
+	}, {
+		query: "select 1 from user",
+		attributes: map[string]sqltypes.Value{
+			"tag": sqltypes.NewVarBinary("x"),
+		},
+		want: "select 1 from `user`",
+	}, {
+		query: "select 1 from user",
+		attributes: map[string]sqltypes.Value{
+			"workload_name": sqltypes.NewVarBinary("a */ b"),
+		},
+		err: "invalid value for the workload_name query attribute: 'a */ b'",
+	}}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			stmt, err := sqlparser.Parse(tcase.query)
+			require.NoError(t, err)
+
+			attributes := queryAttributes(queryAttributeBindVars(nil, tcase.attributes))
+			modified, err := applyQueryAttributeDirectives(stmt, attributes)
+			if tcase.err != "" {
+				require.EqualError(t, err, tcase.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.modified, modified)
+			assert.Equal(t, tcase.want, sqlparser.String(stmt))
+		})
+	}
+
+	// The directives set in the query take precedence.
+	stmt, err := sqlparser.Parse("select /*vt+ WORKLOAD_NAME=batch */ 1 from user")
+	require.NoError(t, err)
+	_, err = applyQueryAttributeDirectives(stmt, map[string]*querypb.BindVariable{"workload_name": sqltypes.StringBindVariable("report")})
+	require.NoError(t, err)
+	assert.Equal(t, "batch", sqlparser.GetWorkloadNameFromStatement(stmt))
+}
+
+func TestExecutorQueryAttributes(t *testing.T) {
+	executor, _, _, sbclookup := createExecutorEnv()
+	logChan := QueryLogger.Subscribe("Test")
+	defer QueryLogger.Unsubscribe(logChan)
+
+	session := NewSafeSession(&vtgatepb.Session{TargetString: KsTestUnsharded, Autocommit: true})
+	bindVars := queryAttributeBindVars(map[string]*querypb.BindVariable{}, map[string]sqltypes.Value{
+		"workload_name": sqltypes.NewVarBinary("report"),
+		"tag":           sqltypes.NewVarBinary("x"),
+	})
+	_, err := executor.Execute(context.Background(), nil, "TestExecute", session, "select id from main1", bindVars)
+	require.NoError(t, err)
+
+	// The query attributes are forwarded to the tablet as bind variables.
+	require.Len(t, sbclookup.Queries, 1)
+	assert.Equal(t, "select /*vt+ WORKLOAD_NAME=report */ id from main1", sbclookup.Queries[0].Sql)
+	assert.Equal(t, sqltypes.BytesBindVariable([]byte("x")), sbclookup.Queries[0].BindVariables[sqlparser.QueryAttributePrefix+"tag"])
+	assert.Equal(t, "report", sbclookup.Options[0].WorkloadName)
+
+	logStats := getQueryLog(logChan)
+	require.NotNil(t, logStats)
+	assert.Equal(t, map[string]*querypb.BindVariable{
+		"workload_name": sqltypes.BytesBindVariable([]byte("report")),
+		"tag":           sqltypes.BytesBindVariable([]byte("x")),
+	}, logStats.QueryAttributes)
+	assert.Equal(t, "PRIMARY", logStats.TabletType)
+
+	// tablet_type overrides the tablet type of the target.
+	sbclookup.Queries = nil
+	bindVars = queryAttributeBindVars(map[string]*querypb.BindVariable{}, map[string]sqltypes.Value{
+		"tablet_type": sqltypes.NewVarBinary("replica"),
+	})
+	_, err = executor.Execute(context.Background(), nil, "TestExecute", session, "select id from main1", bindVars)
+	require.NoError(t, err)
+	assert.Empty(t, sbclookup.Queries)
+	logStats = getQueryLog(logChan)
+	require.NotNil(t, logStats)
+	assert.Equal(t, "REPLICA", logStats.TabletType)
+
+	bindVars = queryAttributeBindVars(map[string]*querypb.BindVariable{}, map[string]sqltypes.Value{
+		"tablet_type": sqltypes.NewVarBinary("foo"),
+	})
+	_, err = executor.Execute(context.Background(), nil, "TestExecute", session, "select id from main1", bindVars)
+	require.ErrorContains(t, err, "invalid tablet_type query attribute")
+
+	// The tablet type can't be changed inside a transaction.
+	session = NewSafeSession(&vtgatepb.Session{TargetString: KsTestUnsharded, InTransaction: true})
+	bindVars = queryAttributeBindVars(map[string]*querypb.BindVariable{}, map[string]sqltypes.Value{
+		"tablet_type": sqltypes.NewVarBinary("replica"),
+	})
+	_, err = executor.Execute(context.Background(), nil, "TestExecute", session, "select id from main1", bindVars)
+	require.ErrorContains(t, err, "can't execute the given command because you have an active transaction")
+}
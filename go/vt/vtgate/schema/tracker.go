@@ -310,9 +310,10 @@ func (t *Tracker) updateTables(keyspace string, res *sqltypes.Result) {
 		colName := row[1].ToString()
 		colType := row[2].ToString()
 		collation := row[3].ToString()
+		colKey := row[4].ToString()
 
 		cType := sqlparser.ColumnType{Type: colType}
-		col := vindexes.Column{Name: sqlparser.NewIdentifierCI(colName), Type: cType.SQLType(), CollationName: collation}
+		col := vindexes.Column{Name: sqlparser.NewIdentifierCI(colName), Type: cType.SQLType(), CollationName: collation, PrimaryKey: colKey == "PRI"}
 		cols := t.tables.get(keyspace, tbl)
 
 		t.tables.set(keyspace, tbl, append(cols, col))
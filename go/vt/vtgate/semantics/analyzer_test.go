@@ -1479,9 +1479,6 @@ func TestUpdateErrors(t *testing.T) {
 		query, expectedError string
 	}{
 		{
-			query:         "update t1, t2 set id = 12",
-			expectedError: "VT12001: unsupported: multiple (2) tables in update",
-		}, {
 			query:         "update (select 1 from dual) dt set id = 1",
 			expectedError: "The target table dt of the UPDATE is not updatable",
 		},
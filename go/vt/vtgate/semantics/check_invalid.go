@@ -137,11 +137,12 @@ func (a *analyzer) checkSelect(cursor *sqlparser.Cursor, node *sqlparser.Select)
 
 func checkUpdate(node *sqlparser.Update) error {
 	if len(node.TableExprs) != 1 {
-		return ShardedError{Inner: &UnsupportedMultiTablesInUpdateError{ExprCount: len(node.TableExprs)}}
+		// the targets of multi-table updates are checked when they are planned
+		return nil
 	}
 	alias, isAlias := node.TableExprs[0].(*sqlparser.AliasedTableExpr)
 	if !isAlias {
-		return ShardedError{Inner: &UnsupportedMultiTablesInUpdateError{NotAlias: true}}
+		return nil
 	}
 	_, isDerived := alias.Expr.(*sqlparser.DerivedTable)
 	if isDerived {
@@ -66,23 +66,6 @@ func (e *UnionColumnsDoNotMatchError) Error() string {
 	return eprintf(e, "The used SELECT statements have a different number of columns: %v, %v", e.FirstProj, e.SecondProj)
 }
 
-// UnsupportedMultiTablesInUpdateError
-type UnsupportedMultiTablesInUpdateError struct {
-	ExprCount int
-	NotAlias  bool
-}
-
-func (e *UnsupportedMultiTablesInUpdateError) Error() string {
-	switch {
-	case e.NotAlias:
-		return eprintf(e, "unaliased multiple tables in update")
-	default:
-		return eprintf(e, "multiple (%d) tables in update", e.ExprCount)
-	}
-}
-
-func (e *UnsupportedMultiTablesInUpdateError) unsupported() {}
-
 // UnsupportedNaturalJoinError
 type UnsupportedNaturalJoinError struct {
 	JoinExpr *sqlparser.JoinTableExpr
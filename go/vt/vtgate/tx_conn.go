@@ -26,6 +26,7 @@ import (
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/txresolver"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -319,64 +320,7 @@ func (txc *TxConn) ReleaseAll(ctx context.Context, session *SafeSession) error {
 
 // Resolve resolves the specified 2PC transaction.
 func (txc *TxConn) Resolve(ctx context.Context, dtid string) error {
-	mmShard, err := dtids.ShardSession(dtid)
-	if err != nil {
-		return err
-	}
-
-	transaction, err := txc.tabletGateway.ReadTransaction(ctx, mmShard.Target, dtid)
-	if err != nil {
-		return err
-	}
-	if transaction == nil || transaction.Dtid == "" {
-		// It was already resolved.
-		return nil
-	}
-	switch transaction.State {
-	case querypb.TransactionState_PREPARE:
-		// If state is PREPARE, make a decision to rollback and
-		// fallthrough to the rollback workflow.
-		qs, err := txc.queryService(mmShard.TabletAlias)
-		if err != nil {
-			return err
-		}
-		if err := qs.SetRollback(ctx, mmShard.Target, transaction.Dtid, mmShard.TransactionId); err != nil {
-			return err
-		}
-		fallthrough
-	case querypb.TransactionState_ROLLBACK:
-		if err := txc.resumeRollback(ctx, mmShard.Target, transaction); err != nil {
-			return err
-		}
-	case querypb.TransactionState_COMMIT:
-		if err := txc.resumeCommit(ctx, mmShard.Target, transaction); err != nil {
-			return err
-		}
-	default:
-		// Should never happen.
-		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid state: %v", transaction.State)
-	}
-	return nil
-}
-
-func (txc *TxConn) resumeRollback(ctx context.Context, target *querypb.Target, transaction *querypb.TransactionMetadata) error {
-	err := txc.runTargets(transaction.Participants, func(t *querypb.Target) error {
-		return txc.tabletGateway.RollbackPrepared(ctx, t, transaction.Dtid, 0)
-	})
-	if err != nil {
-		return err
-	}
-	return txc.tabletGateway.ConcludeTransaction(ctx, target, transaction.Dtid)
-}
-
-func (txc *TxConn) resumeCommit(ctx context.Context, target *querypb.Target, transaction *querypb.TransactionMetadata) error {
-	err := txc.runTargets(transaction.Participants, func(t *querypb.Target) error {
-		return txc.tabletGateway.CommitPrepared(ctx, t, transaction.Dtid)
-	})
-	if err != nil {
-		return err
-	}
-	return txc.tabletGateway.ConcludeTransaction(ctx, target, transaction.Dtid)
+	return txresolver.Resolve(ctx, txc.tabletGateway, dtid)
 }
 
 // runSessions executes the action for all shardSessions in parallel and returns a consolidated error.
@@ -400,24 +344,3 @@ func (txc *TxConn) runSessions(ctx context.Context, shardSessions []*vtgatepb.Se
 	wg.Wait()
 	return allErrors.AggrError(vterrors.Aggregate)
 }
-
-// runTargets executes the action for all targets in parallel and returns a consolildated error.
-// Flow is identical to runSessions.
-func (txc *TxConn) runTargets(targets []*querypb.Target, action func(*querypb.Target) error) error {
-	if len(targets) == 1 {
-		return action(targets[0])
-	}
-	allErrors := new(concurrency.AllErrorRecorder)
-	var wg sync.WaitGroup
-	for _, t := range targets {
-		wg.Add(1)
-		go func(t *querypb.Target) {
-			defer wg.Done()
-			if err := action(t); err != nil {
-				allErrors.RecordError(err)
-			}
-		}(t)
-	}
-	wg.Wait()
-	return allErrors.AggrError(vterrors.Aggregate)
-}
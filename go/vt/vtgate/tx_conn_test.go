@@ -1218,36 +1218,6 @@ func TestTxConnMultiGoSessions(t *testing.T) {
 	require.NoError(t, err)
 }
 
-func TestTxConnMultiGoTargets(t *testing.T) {
-	txc := &TxConn{}
-	input := []*querypb.Target{{
-		Keyspace: "0",
-	}}
-	err := txc.runTargets(input, func(t *querypb.Target) error {
-		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "err %s", t.Keyspace)
-	})
-	want := "err 0"
-	require.EqualError(t, err, want, "runTargets(1)")
-
-	input = []*querypb.Target{{
-		Keyspace: "0",
-	}, {
-		Keyspace: "1",
-	}}
-	err = txc.runTargets(input, func(t *querypb.Target) error {
-		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "err %s", t.Keyspace)
-	})
-	want = "err 0\nerr 1"
-	require.EqualError(t, err, want, "runTargets(2)")
-	wantCode := vtrpcpb.Code_INTERNAL
-	assert.Equal(t, wantCode, vterrors.Code(err), "error code")
-
-	err = txc.runTargets(input, func(t *querypb.Target) error {
-		return nil
-	})
-	require.NoError(t, err)
-}
-
 func TestTxConnAccessModeReset(t *testing.T) {
 	sc, _, _, _, _, _ := newTestTxConnEnv(t, "TestTxConn")
 
@@ -0,0 +1,117 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package txresolver drives unresolved distributed (2PC) transactions to
+// completion. It is shared by vtgate, which resolves transactions on behalf
+// of the tablet watchdogs, and vtctld, which lets operators resolve them
+// manually.
+package txresolver
+
+import (
+	"context"
+	"sync"
+
+	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/dtids"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Resolve resolves the specified 2PC transaction, using qs to reach the
+// primary tablet of each target involved. A transaction that was still
+// being prepared is rolled back, otherwise the recorded decision to commit
+// or rollback is carried out on all participants before the transaction
+// metadata is concluded.
+func Resolve(ctx context.Context, qs queryservice.QueryService, dtid string) error {
+	mmShard, err := dtids.ShardSession(dtid)
+	if err != nil {
+		return err
+	}
+
+	transaction, err := qs.ReadTransaction(ctx, mmShard.Target, dtid)
+	if err != nil {
+		return err
+	}
+	if transaction == nil || transaction.Dtid == "" {
+		// It was already resolved.
+		return nil
+	}
+	switch transaction.State {
+	case querypb.TransactionState_PREPARE:
+		// If state is PREPARE, make a decision to rollback and
+		// fallthrough to the rollback workflow.
+		if err := qs.SetRollback(ctx, mmShard.Target, transaction.Dtid, mmShard.TransactionId); err != nil {
+			return err
+		}
+		fallthrough
+	case querypb.TransactionState_ROLLBACK:
+		if err := resumeRollback(ctx, qs, mmShard.Target, transaction); err != nil {
+			return err
+		}
+	case querypb.TransactionState_COMMIT:
+		if err := resumeCommit(ctx, qs, mmShard.Target, transaction); err != nil {
+			return err
+		}
+	default:
+		// Should never happen.
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid state: %v", transaction.State)
+	}
+	return nil
+}
+
+func resumeRollback(ctx context.Context, qs queryservice.QueryService, target *querypb.Target, transaction *querypb.TransactionMetadata) error {
+	err := runTargets(transaction.Participants, func(t *querypb.Target) error {
+		return qs.RollbackPrepared(ctx, t, transaction.Dtid, 0)
+	})
+	if err != nil {
+		return err
+	}
+	return qs.ConcludeTransaction(ctx, target, transaction.Dtid)
+}
+
+func resumeCommit(ctx context.Context, qs queryservice.QueryService, target *querypb.Target, transaction *querypb.TransactionMetadata) error {
+	err := runTargets(transaction.Participants, func(t *querypb.Target) error {
+		return qs.CommitPrepared(ctx, t, transaction.Dtid)
+	})
+	if err != nil {
+		return err
+	}
+	return qs.ConcludeTransaction(ctx, target, transaction.Dtid)
+}
+
+// runTargets executes the action for all targets in parallel and returns a
+// consolidated error.
+func runTargets(targets []*querypb.Target, action func(*querypb.Target) error) error {
+	if len(targets) == 1 {
+		return action(targets[0])
+	}
+	allErrors := new(concurrency.AllErrorRecorder)
+	var wg sync.WaitGroup
+	for _, t := range targets {
+		wg.Add(1)
+		go func(t *querypb.Target) {
+			defer wg.Done()
+			if err := action(t); err != nil {
+				allErrors.RecordError(err)
+			}
+		}(t)
+	}
+	wg.Wait()
+	return allErrors.AggrError(vterrors.Aggregate)
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package txresolver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func TestRunTargets(t *testing.T) {
+	input := []*querypb.Target{{
+		Keyspace: "0",
+	}}
+	err := runTargets(input, func(t *querypb.Target) error {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "err %s", t.Keyspace)
+	})
+	want := "err 0"
+	require.EqualError(t, err, want, "runTargets(1)")
+
+	input = []*querypb.Target{{
+		Keyspace: "0",
+	}, {
+		Keyspace: "1",
+	}}
+	err = runTargets(input, func(t *querypb.Target) error {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "err %s", t.Keyspace)
+	})
+	want = "err 0\nerr 1"
+	require.EqualError(t, err, want, "runTargets(2)")
+	wantCode := vtrpcpb.Code_INTERNAL
+	assert.Equal(t, wantCode, vterrors.Code(err), "error code")
+
+	err = runTargets(input, func(t *querypb.Target) error {
+		return nil
+	})
+	require.NoError(t, err)
+}
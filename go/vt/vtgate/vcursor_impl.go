@@ -104,6 +104,7 @@ type vcursorImpl struct {
 	collation      collations.ID
 
 	ignoreMaxMemoryRows bool
+	memoryBudget        *engine.MemoryBudget
 	vschema             *vindexes.VSchema
 	vm                  VSchemaOperator
 	semTable            *semantics.SemTable
@@ -170,9 +171,17 @@ func newVCursorImpl(
 		topoServer:      ts,
 		warnShardedOnly: warnShardedOnly,
 		pv:              pv,
+		memoryBudget:    newQueryMemoryBudget(),
 	}, nil
 }
 
+func newQueryMemoryBudget() *engine.MemoryBudget {
+	if queryMemoryBudget <= 0 {
+		return nil
+	}
+	return engine.NewMemoryBudget(queryMemoryBudget)
+}
+
 // HasSystemVariables returns whether the session has set system variables or not
 func (vc *vcursorImpl) HasSystemVariables() bool {
 	return vc.safeSession.HasSystemVariables()
@@ -203,6 +212,11 @@ func (vc *vcursorImpl) ExceedsMaxMemoryRows(numRows int) bool {
 	return !vc.ignoreMaxMemoryRows && numRows > maxMemoryRows
 }
 
+// MemoryBudget returns the memory budget of the query.
+func (vc *vcursorImpl) MemoryBudget() *engine.MemoryBudget {
+	return vc.memoryBudget
+}
+
 // SetIgnoreMaxMemoryRows sets the ignoreMaxMemoryRows value.
 func (vc *vcursorImpl) SetIgnoreMaxMemoryRows(ignoreMaxMemoryRows bool) {
 	vc.ignoreMaxMemoryRows = ignoreMaxMemoryRows
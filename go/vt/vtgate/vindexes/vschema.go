@@ -111,6 +111,9 @@ type Table struct {
 	// Source is a keyspace-qualified table name that points to the source of a
 	// reference table. Only applicable for tables with Type set to "reference".
 	Source *Source `json:"source,omitempty"`
+	// PrimaryKey lists the columns of the primary key of the table. It is
+	// only known when the schema of the table is tracked.
+	PrimaryKey []sqlparser.IdentifierCI `json:"primary_key,omitempty"`
 }
 
 // Keyspace contains the keyspcae info for each Table.
@@ -154,6 +157,9 @@ type Column struct {
 	Name          sqlparser.IdentifierCI `json:"name"`
 	Type          querypb.Type           `json:"type"`
 	CollationName string                 `json:"collation_name"`
+	// PrimaryKey is set by the schema tracker for the columns
+	// that are part of the primary key of the table.
+	PrimaryKey bool `json:"-"`
 }
 
 // MarshalJSON returns a JSON representation of Column.
@@ -188,6 +188,17 @@ func (vm *VSchemaManager) buildAndEnhanceVSchema(v *vschemapb.SrvVSchema) *vinde
 	return vschema
 }
 
+// primaryKey returns the primary key columns among the tracked columns of a table.
+func primaryKey(columns []vindexes.Column) []sqlparser.IdentifierCI {
+	var pk []sqlparser.IdentifierCI
+	for _, col := range columns {
+		if col.PrimaryKey {
+			pk = append(pk, col.Name)
+		}
+	}
+	return pk
+}
+
 func (vm *VSchemaManager) updateFromSchema(vschema *vindexes.VSchema) {
 	for ksName, ks := range vschema.Keyspaces {
 		m := vm.schema.Tables(ksName)
@@ -201,9 +212,11 @@ func (vm *VSchemaManager) updateFromSchema(vschema *vindexes.VSchema) {
 					Keyspace:                ks.Keyspace,
 					Columns:                 columns,
 					ColumnListAuthoritative: true,
+					PrimaryKey:              primaryKey(columns),
 				}
 				continue
 			}
+			vTbl.PrimaryKey = primaryKey(columns)
 			if !vTbl.ColumnListAuthoritative {
 				// if we found the matching table and the vschema view of it is not authoritative, then we just update the columns of the table
 				vTbl.Columns = columns
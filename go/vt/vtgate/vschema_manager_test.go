@@ -28,6 +28,16 @@ func TestVSchemaUpdate(t *testing.T) {
 	tblCol1 := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: cols1, ColumnListAuthoritative: true}
 	tblCol2 := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: cols2, ColumnListAuthoritative: true}
 	tblCol2NA := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: cols2}
+	colsPK := []vindexes.Column{{
+		Name:       sqlparser.NewIdentifierCI("id"),
+		Type:       querypb.Type_INT64,
+		PrimaryKey: true,
+	}, {
+		Name: sqlparser.NewIdentifierCI("name"),
+		Type: querypb.Type_VARCHAR,
+	}}
+	tblColPK := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: colsPK, ColumnListAuthoritative: true, PrimaryKey: []sqlparser.IdentifierCI{sqlparser.NewIdentifierCI("id")}}
+	tblCol2PK := &vindexes.Table{Name: sqlparser.NewIdentifierCS("tbl"), Keyspace: ks, Columns: cols2, ColumnListAuthoritative: true, PrimaryKey: []sqlparser.IdentifierCI{sqlparser.NewIdentifierCI("id")}}
 
 	tcases := []struct {
 		name           string
@@ -85,6 +95,22 @@ func TestVSchemaUpdate(t *testing.T) {
 		schema: map[string][]vindexes.Column{"tbl": cols1},
 		// schema tracker will be ignored for authoritative tables.
 		expected: makeTestVSchema("ks", false, map[string]*vindexes.Table{"tbl": tblCol2}),
+	}, {
+		name:       "1 Schematracking with primary key - 0 srvVSchema",
+		srvVschema: makeTestSrvVSchema("ks", false, nil),
+		schema:     map[string][]vindexes.Column{"tbl": colsPK},
+		expected:   makeTestVSchema("ks", false, map[string]*vindexes.Table{"tbl": tblColPK}),
+	}, {
+		name: "1 Schematracking with primary key - 1 srvVSchema (have columns) authoritative",
+		srvVschema: makeTestSrvVSchema("ks", false, map[string]*vschemapb.Table{
+			"tbl": {
+				Columns:                 []*vschemapb.Column{{Name: "uid", Type: querypb.Type_INT64}, {Name: "name", Type: querypb.Type_VARCHAR}},
+				ColumnListAuthoritative: true,
+			},
+		}),
+		schema: map[string][]vindexes.Column{"tbl": colsPK},
+		// the primary key is tracked even when the columns of the vschema are authoritative.
+		expected: makeTestVSchema("ks", false, map[string]*vindexes.Table{"tbl": tblCol2PK}),
 	}, {
 		name:     "srvVschema received as nil",
 		schema:   map[string][]vindexes.Column{"tbl": cols1},
@@ -74,6 +74,9 @@ var (
 	maxPayloadSize  int
 	warnPayloadSize int
 
+	// queryMemoryBudget is the number of bytes a query can buffer in memory before spilling to disk
+	queryMemoryBudget int64
+
 	noScatter          bool
 	enableShardRouting bool
 
@@ -126,6 +129,7 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.Int64Var(&queryPlanCacheMemory, "gate_query_cache_memory", queryPlanCacheMemory, "gate server query cache size in bytes, maximum amount of memory to be cached. vtgate analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
 	fs.BoolVar(&queryPlanCacheLFU, "gate_query_cache_lfu", cache.DefaultConfig.LFU, "gate server cache algorithm. when set to true, a new cache algorithm based on a TinyLFU admission policy will be used to improve cache behavior and prevent pollution from sparse queries")
 	fs.IntVar(&maxMemoryRows, "max_memory_rows", maxMemoryRows, "Maximum number of rows that will be held in memory for intermediate results as well as the final result.")
+	fs.Int64Var(&queryMemoryBudget, "query-memory-budget", queryMemoryBudget, "Maximum number of bytes a streaming query can hold in vtgate memory to sort, deduplicate and hash join rows, after which the rows are spilled to temporary files. 0 means the rows are never spilled.")
 	fs.IntVar(&warnMemoryRows, "warn_memory_rows", warnMemoryRows, "Warning threshold for in-memory results. A row count higher than this amount will cause the VtGateWarnings.ResultsExceeded counter to be incremented.")
 	fs.StringVar(&defaultDDLStrategy, "ddl_strategy", defaultDDLStrategy, "Set default strategy for DDL statements. Override with @@ddl_strategy session variable")
 	fs.StringVar(&dbDDLPlugin, "dbddl_plugin", dbDDLPlugin, "controls how to handle CREATE/DROP DATABASE. use it if you are using your own database provisioning service")
@@ -47,6 +47,16 @@ const (
 	FailureDetectionPeriodBlockMinutes    = 60  // The time for which an instance's failure discovery is kept "active", so as to avoid concurrent "discoveries" of the instance's failure; this preceeds any recovery process, if any.
 )
 
+// The remediations VTOrc can run on replicas with errant GTIDs.
+const (
+	// ErrantGTIDRemediationNone only reports the errant GTIDs.
+	ErrantGTIDRemediationNone = "none"
+	// ErrantGTIDRemediationDrain changes the tablet type of the replica to DRAINED, which makes it non-promotable.
+	ErrantGTIDRemediationDrain = "drain"
+	// ErrantGTIDRemediationInjectEmptyTransactions commits empty transactions for the errant GTIDs on the primary.
+	ErrantGTIDRemediationInjectEmptyTransactions = "inject-empty-transactions"
+)
+
 var (
 	sqliteDataFile                 = "file::memory:?mode=memory&cache=shared"
 	instancePollTime               = 5 * time.Second
@@ -62,6 +72,9 @@ var (
 	topoInformationRefreshDuration = 15 * time.Second
 	recoveryPollDuration           = 1 * time.Second
 	ersEnabled                     = true
+	errantGTIDRemediation          = ErrantGTIDRemediationNone
+	unreachablePrimaryQuorum       = 50
+	errantGTIDMaxInjected          = 1000
 )
 
 // RegisterFlags registers the flags required by VTOrc
@@ -82,6 +95,9 @@ func RegisterFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&topoInformationRefreshDuration, "topo-information-refresh-duration", topoInformationRefreshDuration, "Timer duration on which VTOrc refreshes the keyspace and vttablet records from the topology server")
 	fs.DurationVar(&recoveryPollDuration, "recovery-poll-duration", recoveryPollDuration, "Timer duration on which VTOrc polls its database to run a recovery")
 	fs.BoolVar(&ersEnabled, "allow-emergency-reparent", ersEnabled, "Whether VTOrc should be allowed to run emergency reparent operation when it detects a dead primary")
+	fs.StringVar(&errantGTIDRemediation, "errant-gtid-remediation", errantGTIDRemediation, "What VTOrc does with a replica that has errant GTIDs: none, drain (change its type to DRAINED so it can't be promoted) or inject-empty-transactions (commit empty transactions for the errant GTIDs on the primary)")
+	fs.IntVar(&errantGTIDMaxInjected, "errant-gtid-max-injected-transactions", errantGTIDMaxInjected, "Maximum number of errant GTIDs of a replica for which VTOrc commits empty transactions on the primary with the inject-empty-transactions remediation. Replicas with more errant GTIDs are left alone, to be drained by the operator")
+	fs.IntVar(&unreachablePrimaryQuorum, "unreachable-primary-quorum-percent", unreachablePrimaryQuorum, "Percentage of the replicas of a primary that VTOrc cannot reach which must confirm that the primary is gone, before VTOrc runs an emergency reparent. VTOrc runs it only when strictly more than this percentage confirms")
 }
 
 // Configuration makes for vtorc configuration input, which can be provided by user via JSON formatted file.
@@ -102,6 +118,9 @@ type Configuration struct {
 	WaitReplicasTimeoutSeconds            int    // Timeout on amount of time to wait for the replicas in case of ERS. Should be a small value because we should fail-fast. Should not be larger than LockTimeout since that is the total time we use for an ERS.
 	TopoInformationRefreshSeconds         int    // Timer duration on which VTOrc refreshes the keyspace and vttablet records from the topo-server.
 	RecoveryPollSeconds                   int    // Timer duration on which VTOrc recovery analysis runs
+	ErrantGTIDRemediation                 string // What VTOrc does with a replica that has errant GTIDs: none, drain or inject-empty-transactions
+	UnreachablePrimaryQuorumPercent       int    // Strictly more than this percentage of the replicas of an unreachable primary must confirm it is gone before VTOrc runs ERS
+	ErrantGTIDMaxInjectedTransactions     int    // Maximum number of errant GTIDs of a replica for which VTOrc commits empty transactions on the primary
 }
 
 // ToJSONString will marshal this configuration as JSON
@@ -131,6 +150,9 @@ func UpdateConfigValuesFromFlags() {
 	Config.WaitReplicasTimeoutSeconds = int(waitReplicasTimeout / time.Second)
 	Config.TopoInformationRefreshSeconds = int(topoInformationRefreshDuration / time.Second)
 	Config.RecoveryPollSeconds = int(recoveryPollDuration / time.Second)
+	Config.ErrantGTIDRemediation = errantGTIDRemediation
+	Config.UnreachablePrimaryQuorumPercent = unreachablePrimaryQuorum
+	Config.ErrantGTIDMaxInjectedTransactions = errantGTIDMaxInjected
 }
 
 // ERSEnabled reports whether VTOrc is allowed to run ERS or not.
@@ -164,6 +186,9 @@ func newConfiguration() *Configuration {
 		WaitReplicasTimeoutSeconds:            30,
 		TopoInformationRefreshSeconds:         15,
 		RecoveryPollSeconds:                   1,
+		ErrantGTIDRemediation:                 ErrantGTIDRemediationNone,
+		UnreachablePrimaryQuorumPercent:       50,
+		ErrantGTIDMaxInjectedTransactions:     1000,
 	}
 }
 
@@ -171,6 +196,17 @@ func (config *Configuration) postReadAdjustments() error {
 	if config.SQLite3DataFile == "" {
 		return fmt.Errorf("SQLite3DataFile must be set")
 	}
+	switch config.ErrantGTIDRemediation {
+	case ErrantGTIDRemediationNone, ErrantGTIDRemediationDrain, ErrantGTIDRemediationInjectEmptyTransactions:
+	default:
+		return fmt.Errorf("ErrantGTIDRemediation must be one of %v, %v or %v", ErrantGTIDRemediationNone, ErrantGTIDRemediationDrain, ErrantGTIDRemediationInjectEmptyTransactions)
+	}
+	if config.UnreachablePrimaryQuorumPercent < 0 || config.UnreachablePrimaryQuorumPercent >= 100 {
+		return fmt.Errorf("UnreachablePrimaryQuorumPercent must be in the range [0, 100)")
+	}
+	if config.ErrantGTIDMaxInjectedTransactions <= 0 {
+		return fmt.Errorf("ErrantGTIDMaxInjectedTransactions must be positive")
+	}
 
 	return nil
 }
@@ -231,4 +231,68 @@ func TestUpdateConfigValuesFromFlags(t *testing.T) {
 		UpdateConfigValuesFromFlags()
 		require.Equal(t, testConfig, Config)
 	})
+
+	t.Run("override errantGTIDRemediation", func(t *testing.T) {
+		oldErrantGTIDRemediation := errantGTIDRemediation
+		errantGTIDRemediation = ErrantGTIDRemediationDrain
+		// Restore the changes we make
+		defer func() {
+			Config = newConfiguration()
+			errantGTIDRemediation = oldErrantGTIDRemediation
+		}()
+
+		testConfig := newConfiguration()
+		testConfig.ErrantGTIDRemediation = ErrantGTIDRemediationDrain
+		UpdateConfigValuesFromFlags()
+		require.Equal(t, testConfig, Config)
+	})
+
+	t.Run("override unreachablePrimaryQuorum", func(t *testing.T) {
+		oldUnreachablePrimaryQuorum := unreachablePrimaryQuorum
+		unreachablePrimaryQuorum = 75
+		// Restore the changes we make
+		defer func() {
+			Config = newConfiguration()
+			unreachablePrimaryQuorum = oldUnreachablePrimaryQuorum
+		}()
+
+		testConfig := newConfiguration()
+		testConfig.UnreachablePrimaryQuorumPercent = 75
+		UpdateConfigValuesFromFlags()
+		require.Equal(t, testConfig, Config)
+	})
+
+	t.Run("override errantGTIDMaxInjected", func(t *testing.T) {
+		oldErrantGTIDMaxInjected := errantGTIDMaxInjected
+		errantGTIDMaxInjected = 10
+		// Restore the changes we make
+		defer func() {
+			Config = newConfiguration()
+			errantGTIDMaxInjected = oldErrantGTIDMaxInjected
+		}()
+
+		testConfig := newConfiguration()
+		testConfig.ErrantGTIDMaxInjectedTransactions = 10
+		UpdateConfigValuesFromFlags()
+		require.Equal(t, testConfig, Config)
+	})
+}
+
+func TestPostReadAdjustments(t *testing.T) {
+	testConfig := newConfiguration()
+	require.NoError(t, testConfig.postReadAdjustments())
+
+	testConfig.ErrantGTIDRemediation = ErrantGTIDRemediationInjectEmptyTransactions
+	require.NoError(t, testConfig.postReadAdjustments())
+
+	testConfig.ErrantGTIDRemediation = "unknown"
+	require.ErrorContains(t, testConfig.postReadAdjustments(), "ErrantGTIDRemediation must be one of")
+
+	testConfig = newConfiguration()
+	testConfig.UnreachablePrimaryQuorumPercent = 100
+	require.ErrorContains(t, testConfig.postReadAdjustments(), "UnreachablePrimaryQuorumPercent must be in the range")
+
+	testConfig = newConfiguration()
+	testConfig.ErrantGTIDMaxInjectedTransactions = 0
+	require.ErrorContains(t, testConfig.postReadAdjustments(), "ErrantGTIDMaxInjectedTransactions must be positive")
 }
@@ -316,6 +316,15 @@ CREATE TABLE global_recovery_disable (
 	PRIMARY KEY (disable_recovery)
 )`,
 	`
+DROP TABLE IF EXISTS shard_recovery_disable
+`,
+	`
+CREATE TABLE shard_recovery_disable (
+	keyspace varchar(128) NOT NULL,
+	shard varchar(128) NOT NULL,
+	PRIMARY KEY (keyspace, shard)
+)`,
+	`
 DROP TABLE IF EXISTS topology_recovery_steps
 `,
 	`
@@ -0,0 +1,155 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcvtorcclient contains the gRPC version of the VTOrc client protocol.
+package grpcvtorcclient
+
+import (
+	"context"
+
+	"github.com/spf13/pflag"
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtorc/vtorcclient"
+
+	vtorcdatapb "vitess.io/vitess/go/vt/proto/vtorcdata"
+	vtorcservicepb "vitess.io/vitess/go/vt/proto/vtorcservice"
+)
+
+var (
+	cert string
+	key  string
+	ca   string
+	crl  string
+	name string
+)
+
+// RegisterFlags registers the gRPC VTOrc client flags on a given flagset. It
+// is exported for the binaries and tools that talk to VTOrc.
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&cert, "vtorc_client_grpc_cert", cert, "the cert to use to connect")
+	fs.StringVar(&key, "vtorc_client_grpc_key", key, "the key to use to connect")
+	fs.StringVar(&ca, "vtorc_client_grpc_ca", ca, "the server ca to use to validate servers when connecting")
+	fs.StringVar(&crl, "vtorc_client_grpc_crl", crl, "the server crl to use to validate server certificates when connecting")
+	fs.StringVar(&name, "vtorc_client_grpc_server_name", name, "the server name to use to validate server certificate")
+}
+
+type client struct {
+	conn       *grpc.ClientConn
+	gRPCClient vtorcservicepb.VTOrcClient
+}
+
+func factory(addr string) (vtorcclient.Client, error) {
+	opt, err := grpcclient.SecureDialOption(cert, key, ca, crl, name)
+	if err != nil {
+		return nil, err
+	}
+	conn, err := grpcclient.Dial(addr, grpcclient.FailFast(false), opt)
+	if err != nil {
+		return nil, err
+	}
+	gRPCClient := vtorcservicepb.NewVTOrcClient(conn)
+
+	return &client{conn, gRPCClient}, nil
+}
+
+// GetProblems is part of the vtorcclient.Client interface.
+func (c *client) GetProblems(ctx context.Context, keyspace string, shard string) ([]*vtorcdatapb.Instance, error) {
+	response, err := c.gRPCClient.GetProblems(ctx, &vtorcdatapb.GetProblemsRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	return response.Instances, nil
+}
+
+// GetReplicationAnalysis is part of the vtorcclient.Client interface.
+func (c *client) GetReplicationAnalysis(ctx context.Context, keyspace string, shard string) ([]*vtorcdatapb.ReplicationAnalysis, error) {
+	response, err := c.gRPCClient.GetReplicationAnalysis(ctx, &vtorcdatapb.GetReplicationAnalysisRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	return response.Analyses, nil
+}
+
+// GetRecoveries is part of the vtorcclient.Client interface.
+func (c *client) GetRecoveries(ctx context.Context, keyspace string, shard string, unacknowledgedOnly bool, page int32) ([]*vtorcdatapb.TopologyRecovery, error) {
+	response, err := c.gRPCClient.GetRecoveries(ctx, &vtorcdatapb.GetRecoveriesRequest{
+		Keyspace:           keyspace,
+		Shard:              shard,
+		UnacknowledgedOnly: unacknowledgedOnly,
+		Page:               page,
+	})
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	return response.Recoveries, nil
+}
+
+// GetRecoveryStatus is part of the vtorcclient.Client interface.
+func (c *client) GetRecoveryStatus(ctx context.Context) (*vtorcdatapb.GetRecoveryStatusResponse, error) {
+	response, err := c.gRPCClient.GetRecoveryStatus(ctx, &vtorcdatapb.GetRecoveryStatusRequest{})
+	if err != nil {
+		return nil, vterrors.FromGRPC(err)
+	}
+	return response, nil
+}
+
+// DisableGlobalRecoveries is part of the vtorcclient.Client interface.
+func (c *client) DisableGlobalRecoveries(ctx context.Context) error {
+	_, err := c.gRPCClient.DisableGlobalRecoveries(ctx, &vtorcdatapb.DisableGlobalRecoveriesRequest{})
+	return vterrors.FromGRPC(err)
+}
+
+// EnableGlobalRecoveries is part of the vtorcclient.Client interface.
+func (c *client) EnableGlobalRecoveries(ctx context.Context) error {
+	_, err := c.gRPCClient.EnableGlobalRecoveries(ctx, &vtorcdatapb.EnableGlobalRecoveriesRequest{})
+	return vterrors.FromGRPC(err)
+}
+
+// DisableShardRecoveries is part of the vtorcclient.Client interface.
+func (c *client) DisableShardRecoveries(ctx context.Context, keyspace string, shard string) error {
+	_, err := c.gRPCClient.DisableShardRecoveries(ctx, &vtorcdatapb.DisableShardRecoveriesRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	return vterrors.FromGRPC(err)
+}
+
+// EnableShardRecoveries is part of the vtorcclient.Client interface.
+func (c *client) EnableShardRecoveries(ctx context.Context, keyspace string, shard string) error {
+	_, err := c.gRPCClient.EnableShardRecoveries(ctx, &vtorcdatapb.EnableShardRecoveriesRequest{
+		Keyspace: keyspace,
+		Shard:    shard,
+	})
+	return vterrors.FromGRPC(err)
+}
+
+// Close is part of the vtorcclient.Client interface.
+func (c *client) Close() {
+	c.conn.Close()
+}
+
+func init() {
+	vtorcclient.RegisterFactory("grpc", factory)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtorcclient
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	_ "modernc.org/sqlite"
+
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtorc/db"
+	"vitess.io/vitess/go/vt/vtorc/grpcvtorcserver"
+
+	vtorcdatapb "vitess.io/vitess/go/vt/proto/vtorcdata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// TestVTOrcServer tests the gRPC implementation using a VTOrc client and server.
+func TestVTOrcServer(t *testing.T) {
+	ctx := context.Background()
+	orcDb, err := db.OpenVTOrc()
+	require.NoError(t, err)
+	defer func() {
+		for _, table := range []string{"global_recovery_disable", "shard_recovery_disable", "topology_recovery"} {
+			_, err = orcDb.Exec("delete from " + table)
+			require.NoError(t, err)
+		}
+	}()
+
+	port := startGRPCServer(t)
+	client, err := factory(fmt.Sprintf("localhost:%v", port))
+	require.NoError(t, err)
+	defer client.Close()
+
+	// Global and shard recoveries can be disabled and enabled independently.
+	require.NoError(t, client.DisableGlobalRecoveries(ctx))
+	require.NoError(t, client.DisableShardRecoveries(ctx, "ks", "-80"))
+	require.NoError(t, client.DisableShardRecoveries(ctx, "ks2", ""))
+	status, err := client.GetRecoveryStatus(ctx)
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtorcdatapb.GetRecoveryStatusResponse{
+		GlobalRecoveriesDisabled: true,
+		DisabledShardRecoveries: []*vtorcdatapb.ShardRecoveryDisable{
+			{Keyspace: "ks", Shard: "-80"},
+			{Keyspace: "ks2"},
+		},
+	}, status)
+
+	require.NoError(t, client.EnableGlobalRecoveries(ctx))
+	require.NoError(t, client.EnableShardRecoveries(ctx, "ks", "-80"))
+	status, err = client.GetRecoveryStatus(ctx)
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtorcdatapb.GetRecoveryStatusResponse{
+		DisabledShardRecoveries: []*vtorcdatapb.ShardRecoveryDisable{
+			{Keyspace: "ks2"},
+		},
+	}, status)
+
+	err = client.DisableShardRecoveries(ctx, "", "-80")
+	require.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), "%v", err)
+
+	// The recovery history can be filtered by keyspace and shard.
+	for i, shard := range []string{"-80", "80-"} {
+		_, err = orcDb.Exec("insert into topology_recovery (recovery_id, uid, alias, processing_node_hostname, processcing_node_token, analysis, keyspace, shard, is_successful, successor_alias) values (?, ?, ?, 'localhost', 'token', 'DeadPrimary', 'ks', ?, 1, 'zone1-0000000101')",
+			i+1, fmt.Sprintf("uid%d", i+1), "zone1-0000000100", shard)
+		require.NoError(t, err)
+	}
+	recoveries, err := client.GetRecoveries(ctx, "", "", false, 0)
+	require.NoError(t, err)
+	require.Len(t, recoveries, 2)
+	recoveries, err = client.GetRecoveries(ctx, "ks", "80-", false, 0)
+	require.NoError(t, err)
+	require.Len(t, recoveries, 1)
+	require.Equal(t, int64(2), recoveries[0].Id)
+	require.Equal(t, "DeadPrimary", recoveries[0].Analysis)
+	require.Equal(t, "zone1-0000000101", recoveries[0].SuccessorAlias)
+	require.True(t, recoveries[0].IsSuccessful)
+
+	_, err = client.GetRecoveries(ctx, "", "80-", false, 0)
+	require.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), "%v", err)
+	_, err = client.GetProblems(ctx, "", "80-")
+	require.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), "%v", err)
+	_, err = client.GetReplicationAnalysis(ctx, "", "80-")
+	require.Equal(t, vtrpcpb.Code_INVALID_ARGUMENT, vterrors.Code(err), "%v", err)
+}
+
+func startGRPCServer(t *testing.T) int {
+	// Listen on a random port.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := grpc.NewServer()
+	grpcvtorcserver.RegisterServer(s)
+	// Call Serve() after our service has been registered. Otherwise, the test
+	// will fail with the error "grpc: Server.RegisterService after Server.Serve".
+	go s.Serve(listener)
+	t.Cleanup(s.Stop)
+	return listener.Addr().(*net.TCPAddr).Port
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package grpcvtorcserver
+
+import (
+	"vitess.io/vitess/go/vt/vtorc/inst"
+	"vitess.io/vitess/go/vt/vtorc/logic"
+
+	vtorcdatapb "vitess.io/vitess/go/vt/proto/vtorcdata"
+)
+
+// instanceToProto converts an instance to its proto representation.
+// The keyspace and shard are read from the tablet of the instance, if VTOrc still knows it.
+func instanceToProto(instance *inst.Instance) *vtorcdatapb.Instance {
+	res := &vtorcdatapb.Instance{
+		Alias:                       instance.InstanceAlias,
+		Hostname:                    instance.Hostname,
+		Port:                        int32(instance.Port),
+		SourceHost:                  instance.SourceHost,
+		SourcePort:                  int32(instance.SourcePort),
+		ReadOnly:                    instance.ReadOnly,
+		ReplicationSqlThreadRunning: instance.ReplicationSQLThreadRuning,
+		ReplicationIoThreadRunning:  instance.ReplicationIOThreadRuning,
+		LastSqlError:                instance.LastSQLError,
+		LastIoError:                 instance.LastIOError,
+		ReplicationLagSeconds:       instance.ReplicationLagSeconds.Int64,
+		ReplicationLagKnown:         instance.ReplicationLagSeconds.Valid,
+		ExecutedGtidSet:             instance.ExecutedGtidSet,
+		GtidErrant:                  instance.GtidErrant,
+		SemiSyncPrimaryEnabled:      instance.SemiSyncPrimaryEnabled,
+		SemiSyncReplicaEnabled:      instance.SemiSyncReplicaEnabled,
+		IsLastCheckValid:            instance.IsLastCheckValid,
+		LastSeenTimestamp:           instance.LastSeenTimestamp,
+		Problems:                    instance.Problems,
+	}
+	if tablet, err := inst.ReadTablet(instance.InstanceAlias); err == nil {
+		res.Keyspace = tablet.Keyspace
+		res.Shard = tablet.Shard
+	}
+	return res
+}
+
+// replicationAnalysisToProto converts a replication analysis to its proto representation.
+func replicationAnalysisToProto(analysis *inst.ReplicationAnalysis) *vtorcdatapb.ReplicationAnalysis {
+	return &vtorcdatapb.ReplicationAnalysis{
+		AnalyzedInstanceAlias:         analysis.AnalyzedInstanceAlias,
+		AnalyzedInstancePrimaryAlias:  analysis.AnalyzedInstancePrimaryAlias,
+		TabletType:                    analysis.TabletType,
+		Keyspace:                      analysis.AnalyzedKeyspace,
+		Shard:                         analysis.AnalyzedShard,
+		Analysis:                      string(analysis.Analysis),
+		Description:                   analysis.Description,
+		IsPrimary:                     analysis.IsPrimary,
+		LastCheckValid:                analysis.LastCheckValid,
+		CountReplicas:                 uint32(analysis.CountReplicas),
+		CountValidReplicas:            uint32(analysis.CountValidReplicas),
+		CountValidReplicatingReplicas: uint32(analysis.CountValidReplicatingReplicas),
+		IsActionableRecovery:          analysis.IsActionableRecovery,
+	}
+}
+
+// topologyRecoveryToProto converts a topology recovery to its proto representation.
+func topologyRecoveryToProto(recovery *logic.TopologyRecovery) *vtorcdatapb.TopologyRecovery {
+	return &vtorcdatapb.TopologyRecovery{
+		Id:                     recovery.ID,
+		Uid:                    recovery.UID,
+		AnalyzedInstanceAlias:  recovery.AnalysisEntry.AnalyzedInstanceAlias,
+		Keyspace:               recovery.AnalysisEntry.ClusterDetails.Keyspace,
+		Shard:                  recovery.AnalysisEntry.ClusterDetails.Shard,
+		Analysis:               string(recovery.AnalysisEntry.Analysis),
+		SuccessorAlias:         recovery.SuccessorAlias,
+		IsActive:               recovery.IsActive,
+		IsSuccessful:           recovery.IsSuccessful,
+		AllErrors:              recovery.AllErrors,
+		RecoveryStartTimestamp: recovery.RecoveryStartTimestamp,
+		RecoveryEndTimestamp:   recovery.RecoveryEndTimestamp,
+		ProcessingNodeHostname: recovery.ProcessingNodeHostname,
+		Acknowledged:           recovery.Acknowledged,
+		AcknowledgedAt:         recovery.AcknowledgedAt,
+		AcknowledgedBy:         recovery.AcknowledgedBy,
+		AcknowledgedComment:    recovery.AcknowledgedComment,
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package grpcvtorcserver contains the gRPC implementation of the server
+// side of the VTOrc service.
+package grpcvtorcserver
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtorc/inst"
+	"vitess.io/vitess/go/vt/vtorc/logic"
+
+	vtorcdatapb "vitess.io/vitess/go/vt/proto/vtorcdata"
+	vtorcservicepb "vitess.io/vitess/go/vt/proto/vtorcservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Server is the gRPC server implementation of the VTOrc service.
+type Server struct {
+	vtorcservicepb.UnimplementedVTOrcServer
+}
+
+// NewServer creates a new RPC server for VTOrc.
+func NewServer() *Server {
+	return &Server{}
+}
+
+// validateFilter checks the optional keyspace and shard filters of a request.
+func validateFilter(keyspace string, shard string) error {
+	if shard != "" && keyspace == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "filtering by shard without keyspace isn't supported")
+	}
+	return nil
+}
+
+// GetProblems implements the gRPC server interface.
+func (s *Server) GetProblems(_ context.Context, request *vtorcdatapb.GetProblemsRequest) (_ *vtorcdatapb.GetProblemsResponse, err error) {
+	defer servenv.HandlePanic("vtorc", &err)
+
+	if err := validateFilter(request.Keyspace, request.Shard); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	instances, err := inst.ReadProblemInstances(request.Keyspace, request.Shard)
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	response := &vtorcdatapb.GetProblemsResponse{}
+	for _, instance := range instances {
+		response.Instances = append(response.Instances, instanceToProto(instance))
+	}
+	return response, nil
+}
+
+// GetReplicationAnalysis implements the gRPC server interface.
+func (s *Server) GetReplicationAnalysis(_ context.Context, request *vtorcdatapb.GetReplicationAnalysisRequest) (_ *vtorcdatapb.GetReplicationAnalysisResponse, err error) {
+	defer servenv.HandlePanic("vtorc", &err)
+
+	if err := validateFilter(request.Keyspace, request.Shard); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	analyses, err := inst.GetReplicationAnalysis(request.Keyspace, request.Shard, &inst.ReplicationAnalysisHints{})
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	response := &vtorcdatapb.GetReplicationAnalysisResponse{}
+	for _, analysis := range analyses {
+		response.Analyses = append(response.Analyses, replicationAnalysisToProto(analysis))
+	}
+	return response, nil
+}
+
+// GetRecoveries implements the gRPC server interface.
+func (s *Server) GetRecoveries(_ context.Context, request *vtorcdatapb.GetRecoveriesRequest) (_ *vtorcdatapb.GetRecoveriesResponse, err error) {
+	defer servenv.HandlePanic("vtorc", &err)
+
+	if err := validateFilter(request.Keyspace, request.Shard); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	if request.Page < 0 {
+		return nil, vterrors.ToGRPC(vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "page must not be negative"))
+	}
+	recoveries, err := logic.ReadRecentShardRecoveries(request.Keyspace, request.Shard, request.UnacknowledgedOnly, int(request.Page))
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	response := &vtorcdatapb.GetRecoveriesResponse{}
+	for _, recovery := range recoveries {
+		response.Recoveries = append(response.Recoveries, topologyRecoveryToProto(recovery))
+	}
+	return response, nil
+}
+
+// GetRecoveryStatus implements the gRPC server interface.
+func (s *Server) GetRecoveryStatus(_ context.Context, _ *vtorcdatapb.GetRecoveryStatusRequest) (_ *vtorcdatapb.GetRecoveryStatusResponse, err error) {
+	defer servenv.HandlePanic("vtorc", &err)
+
+	disabled, err := logic.IsRecoveryDisabled()
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	disabledShards, err := logic.ReadDisabledShardRecoveries()
+	if err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	response := &vtorcdatapb.GetRecoveryStatusResponse{
+		GlobalRecoveriesDisabled: disabled,
+	}
+	for _, disabledShard := range disabledShards {
+		response.DisabledShardRecoveries = append(response.DisabledShardRecoveries, &vtorcdatapb.ShardRecoveryDisable{
+			Keyspace: disabledShard.Keyspace,
+			Shard:    disabledShard.Shard,
+		})
+	}
+	return response, nil
+}
+
+// DisableGlobalRecoveries implements the gRPC server interface.
+func (s *Server) DisableGlobalRecoveries(_ context.Context, _ *vtorcdatapb.DisableGlobalRecoveriesRequest) (_ *vtorcdatapb.DisableGlobalRecoveriesResponse, err error) {
+	defer servenv.HandlePanic("vtorc", &err)
+
+	if err := logic.DisableRecovery(); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	return &vtorcdatapb.DisableGlobalRecoveriesResponse{}, nil
+}
+
+// EnableGlobalRecoveries implements the gRPC server interface.
+func (s *Server) EnableGlobalRecoveries(_ context.Context, _ *vtorcdatapb.EnableGlobalRecoveriesRequest) (_ *vtorcdatapb.EnableGlobalRecoveriesResponse, err error) {
+	defer servenv.HandlePanic("vtorc", &err)
+
+	if err := logic.EnableRecovery(); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	return &vtorcdatapb.EnableGlobalRecoveriesResponse{}, nil
+}
+
+// DisableShardRecoveries implements the gRPC server interface.
+func (s *Server) DisableShardRecoveries(_ context.Context, request *vtorcdatapb.DisableShardRecoveriesRequest) (_ *vtorcdatapb.DisableShardRecoveriesResponse, err error) {
+	defer servenv.HandlePanic("vtorc", &err)
+
+	if request.Keyspace == "" {
+		return nil, vterrors.ToGRPC(vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "keyspace must be set"))
+	}
+	if err := logic.DisableShardRecovery(request.Keyspace, request.Shard); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	return &vtorcdatapb.DisableShardRecoveriesResponse{}, nil
+}
+
+// EnableShardRecoveries implements the gRPC server interface.
+func (s *Server) EnableShardRecoveries(_ context.Context, request *vtorcdatapb.EnableShardRecoveriesRequest) (_ *vtorcdatapb.EnableShardRecoveriesResponse, err error) {
+	defer servenv.HandlePanic("vtorc", &err)
+
+	if request.Keyspace == "" {
+		return nil, vterrors.ToGRPC(vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "keyspace must be set"))
+	}
+	if err := logic.EnableShardRecovery(request.Keyspace, request.Shard); err != nil {
+		return nil, vterrors.ToGRPC(err)
+	}
+	return &vtorcdatapb.EnableShardRecoveriesResponse{}, nil
+}
+
+// RegisterServer registers a new VTOrc server instance with the gRPC server.
+func RegisterServer(s *grpc.Server) {
+	vtorcservicepb.RegisterVTOrcServer(s, NewServer())
+}
+
+func init() {
+	servenv.OnRun(func() {
+		if servenv.GRPCCheckServiceMap("vtorc") {
+			RegisterServer(servenv.GRPCServer)
+		}
+	})
+}
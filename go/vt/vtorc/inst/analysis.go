@@ -46,6 +46,7 @@ const (
 	ReplicationStopped                     AnalysisCode = "ReplicationStopped"
 	ReplicaSemiSyncMustBeSet               AnalysisCode = "ReplicaSemiSyncMustBeSet"
 	ReplicaSemiSyncMustNotBeSet            AnalysisCode = "ReplicaSemiSyncMustNotBeSet"
+	ErrantGTIDDetected                     AnalysisCode = "ErrantGTIDDetected"
 	UnreachablePrimaryWithLaggingReplicas  AnalysisCode = "UnreachablePrimaryWithLaggingReplicas"
 	UnreachablePrimary                     AnalysisCode = "UnreachablePrimary"
 	PrimarySingleReplicaNotReplicating     AnalysisCode = "PrimarySingleReplicaNotReplicating"
@@ -143,6 +144,7 @@ type ReplicationAnalysis struct {
 	MinReplicaGTIDMode                        string
 	MaxReplicaGTIDMode                        string
 	MaxReplicaGTIDErrant                      string
+	ErrantGTID                                string
 	IsReadOnly                                bool
 }
 
@@ -116,6 +116,7 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 		) AS is_primary,
 		MIN(primary_instance.is_co_primary) AS is_co_primary,
 		MIN(primary_instance.gtid_mode) AS gtid_mode,
+		MIN(primary_instance.gtid_errant) AS gtid_errant,
 		COUNT(replica_instance.server_id) AS count_replicas,
 		IFNULL(
 			SUM(
@@ -345,6 +346,7 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 		a.ClusterDetails.Keyspace = m.GetString("keyspace")
 		a.ClusterDetails.Shard = m.GetString("shard")
 		a.GTIDMode = m.GetString("gtid_mode")
+		a.ErrantGTID = m.GetString("gtid_errant")
 		a.LastCheckValid = m.GetBool("is_last_check_valid")
 		a.LastCheckPartialSuccess = m.GetBool("last_check_partial_success")
 		a.CountReplicas = m.GetUint("count_replicas")
@@ -497,6 +499,10 @@ func GetReplicationAnalysis(keyspace string, shard string, hints *ReplicationAna
 			a.Analysis = ReplicaSemiSyncMustNotBeSet
 			a.Description = "Replica semi-sync must not be set"
 			//
+		} else if topo.IsReplicaType(a.TabletType) && !a.IsPrimary && a.ErrantGTID != "" {
+			a.Analysis = ErrantGTIDDetected
+			a.Description = "Tablet has errant GTIDs"
+			//
 			// TODO(sougou): Events below here are either ignored or not possible.
 		} else if a.IsPrimary && !a.LastCheckValid && a.CountLaggingReplicas == a.CountReplicas && a.CountDelayedReplicas < a.CountReplicas && a.CountValidReplicatingReplicas > 0 {
 			a.Analysis = UnreachablePrimaryWithLaggingReplicas
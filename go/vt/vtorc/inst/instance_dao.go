@@ -679,6 +679,18 @@ func ReadProblemInstances(keyspace string, shard string) ([](*Instance), error)
 	return readInstancesByCondition(condition, args, "")
 }
 
+// ReadInstancesWithErrantGTIds reads all instances with errant GTIDs
+func ReadInstancesWithErrantGTIds(keyspace string, shard string) ([]*Instance, error) {
+	condition := `
+		keyspace LIKE (CASE WHEN ? = '' THEN '%' ELSE ? END)
+		and shard LIKE (CASE WHEN ? = '' THEN '%' ELSE ? END)
+		and gtid_errant != ''
+	`
+
+	args := sqlutils.Args(keyspace, keyspace, shard, shard)
+	return readInstancesByCondition(condition, args, "")
+}
+
 // GetKeyspaceShardName gets the keyspace shard name for the given instance key
 func GetKeyspaceShardName(tabletAlias string) (keyspace string, shard string, err error) {
 	query := `
@@ -326,6 +326,81 @@ func TestReadProblemInstances(t *testing.T) {
 }
 
 // TestReadInstancesByCondition is used to test the functionality of readInstancesByCondition and verify its failure modes and successes.
+func TestReadInstancesWithErrantGTIds(t *testing.T) {
+	tests := []struct {
+		name              string
+		keyspace          string
+		shard             string
+		sql               []string
+		instancesRequired []string
+	}{
+		{
+			name:              "No instances with errant GTID",
+			sql:               nil,
+			instancesRequired: nil,
+		}, {
+			name: "errant GTID",
+			sql: []string{
+				"update database_instance set gtid_errant = '729a4cc4-8680-11ed-a104-47706090afbd:1' where alias = 'zone1-0000000112'",
+			},
+			instancesRequired: []string{"zone1-0000000112"},
+		}, {
+			name:     "keyspace filtering - success",
+			keyspace: "ks",
+			sql: []string{
+				"update database_instance set gtid_errant = '729a4cc4-8680-11ed-a104-47706090afbd:1' where alias = 'zone1-0000000112'",
+			},
+			instancesRequired: []string{"zone1-0000000112"},
+		}, {
+			name:     "keyspace filtering - failure",
+			keyspace: "unknown",
+			sql: []string{
+				"update database_instance set gtid_errant = '729a4cc4-8680-11ed-a104-47706090afbd:1' where alias = 'zone1-0000000112'",
+			},
+			instancesRequired: nil,
+		}, {
+			name:     "shard filtering - success",
+			keyspace: "ks",
+			shard:    "0",
+			sql: []string{
+				"update database_instance set gtid_errant = '729a4cc4-8680-11ed-a104-47706090afbd:1' where alias = 'zone1-0000000112'",
+				"update database_instance set gtid_errant = '729a4cc4-8680-11ed-a104-47706090afbd:2' where alias = 'zone1-0000000100'",
+			},
+			instancesRequired: []string{"zone1-0000000112", "zone1-0000000100"},
+		}, {
+			name:     "shard filtering - failure",
+			keyspace: "ks",
+			shard:    "unknown",
+			sql: []string{
+				"update database_instance set gtid_errant = '729a4cc4-8680-11ed-a104-47706090afbd:1' where alias = 'zone1-0000000112'",
+			},
+			instancesRequired: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Each test should clear the database. The easiest way to do that is to run all the initialization commands again
+			defer func() {
+				db.ClearVTOrcDatabase()
+			}()
+
+			for _, query := range append(initialSQL, tt.sql...) {
+				_, err := db.ExecVTOrc(query)
+				require.NoError(t, err)
+			}
+
+			instances, err := ReadInstancesWithErrantGTIds(tt.keyspace, tt.shard)
+			require.NoError(t, err)
+			var tabletAliases []string
+			for _, instance := range instances {
+				tabletAliases = append(tabletAliases, instance.InstanceAlias)
+			}
+			require.ElementsMatch(t, tabletAliases, tt.instancesRequired)
+		})
+	}
+}
+
 func TestReadInstancesByCondition(t *testing.T) {
 	tests := []struct {
 		name              string
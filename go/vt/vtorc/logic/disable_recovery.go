@@ -80,3 +80,81 @@ func EnableRecovery() error {
 	)
 	return err
 }
+
+// ShardRecoveryDisable is a keyspace/shard for which recoveries are disabled.
+// An empty Shard stands for the whole keyspace.
+type ShardRecoveryDisable struct {
+	Keyspace string
+	Shard    string
+}
+
+// IsShardRecoveryDisabled returns true if Recoveries are disabled for the given shard, or for its whole keyspace
+func IsShardRecoveryDisabled(keyspace string, shard string) (disabled bool, err error) {
+	query := `
+		SELECT
+			COUNT(*) as mycount
+		FROM
+			shard_recovery_disable
+		WHERE
+			keyspace=?
+			AND (shard=? OR shard='')
+		`
+	err = db.QueryVTOrc(query, sqlutils.Args(keyspace, shard), func(m sqlutils.RowMap) error {
+		mycount := m.GetInt("mycount")
+		disabled = (mycount > 0)
+		return nil
+	})
+	if err != nil {
+		errMsg := fmt.Sprintf("recovery.IsShardRecoveryDisabled(): %v", err)
+		log.Errorf(errMsg)
+		err = fmt.Errorf(errMsg)
+	}
+	return disabled, err
+}
+
+// ReadDisabledShardRecoveries returns all the keyspaces/shards for which recoveries are disabled
+func ReadDisabledShardRecoveries() ([]ShardRecoveryDisable, error) {
+	var res []ShardRecoveryDisable
+	query := `
+		SELECT
+			keyspace,
+			shard
+		FROM
+			shard_recovery_disable
+		ORDER BY
+			keyspace, shard
+		`
+	err := db.QueryVTOrc(query, nil, func(m sqlutils.RowMap) error {
+		res = append(res, ShardRecoveryDisable{
+			Keyspace: m.GetString("keyspace"),
+			Shard:    m.GetString("shard"),
+		})
+		return nil
+	})
+	if err != nil {
+		log.Error(err)
+	}
+	return res, err
+}
+
+// DisableShardRecovery ensures recoveries are disabled for the given shard, or for the whole keyspace if the shard is empty
+func DisableShardRecovery(keyspace string, shard string) error {
+	_, err := db.ExecVTOrc(`
+		INSERT IGNORE INTO shard_recovery_disable
+			(keyspace, shard)
+		VALUES  (?, ?)
+	`,
+		keyspace, shard,
+	)
+	return err
+}
+
+// EnableShardRecovery reverts DisableShardRecovery for the given shard, or for the whole keyspace if the shard is empty
+func EnableShardRecovery(keyspace string, shard string) error {
+	_, err := db.ExecVTOrc(`
+		DELETE FROM shard_recovery_disable WHERE keyspace=? AND shard=?
+	`,
+		keyspace, shard,
+	)
+	return err
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vtorc/db"
+)
+
+func TestShardRecoveryDisable(t *testing.T) {
+	orcDb, err := db.OpenVTOrc()
+	require.NoError(t, err)
+	defer func() {
+		_, err = orcDb.Exec("delete from shard_recovery_disable")
+		require.NoError(t, err)
+	}()
+
+	require.NoError(t, DisableShardRecovery("ks", "-80"))
+	require.NoError(t, DisableShardRecovery("ks2", ""))
+	// Disabling twice is a no-op.
+	require.NoError(t, DisableShardRecovery("ks", "-80"))
+
+	for _, tt := range []struct {
+		keyspace string
+		shard    string
+		disabled bool
+	}{
+		{keyspace: "ks", shard: "-80", disabled: true},
+		{keyspace: "ks", shard: "80-", disabled: false},
+		{keyspace: "ks2", shard: "-80", disabled: true},
+		{keyspace: "ks3", shard: "-80", disabled: false},
+	} {
+		disabled, err := IsShardRecoveryDisabled(tt.keyspace, tt.shard)
+		require.NoError(t, err)
+		require.Equal(t, tt.disabled, disabled, "%v/%v", tt.keyspace, tt.shard)
+	}
+
+	disabledShards, err := ReadDisabledShardRecoveries()
+	require.NoError(t, err)
+	require.Equal(t, []ShardRecoveryDisable{{Keyspace: "ks", Shard: "-80"}, {Keyspace: "ks2"}}, disabledShards)
+
+	require.NoError(t, EnableShardRecovery("ks2", ""))
+	disabled, err := IsShardRecoveryDisabled("ks2", "-80")
+	require.NoError(t, err)
+	require.False(t, disabled)
+}
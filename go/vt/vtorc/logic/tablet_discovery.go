@@ -289,6 +289,16 @@ func setReadOnly(ctx context.Context, tablet *topodatapb.Tablet) error {
 	return tmc.SetReadOnly(ctx, tablet)
 }
 
+// changeTabletType calls the said RPC for the given tablet with the given parameters.
+func changeTabletType(ctx context.Context, tablet *topodatapb.Tablet, tabletType topodatapb.TabletType, semiSync bool) error {
+	return tmc.ChangeType(ctx, tablet, tabletType, semiSync)
+}
+
+// injectEmptyTransactions calls the said RPC for the given primary tablet and GTID set.
+func injectEmptyTransactions(ctx context.Context, primary *topodatapb.Tablet, gtidSet string) error {
+	return tmc.InjectEmptyTransactions(ctx, primary, gtidSet)
+}
+
 // setReplicationSource calls the said RPC with the parameters provided
 func setReplicationSource(ctx context.Context, replica *topodatapb.Tablet, primary *topodatapb.Tablet, semiSync bool) error {
 	return tmc.SetReplicationSource(ctx, replica, primary.Alias, 0, "", true, semiSync)
@@ -321,6 +331,30 @@ func shardPrimary(keyspace string, shard string) (primary *topodatapb.Tablet, er
 	return primary, err
 }
 
+// shardReplicas finds the replica tablets of the given keyspace-shard by reading the vtorc backend
+func shardReplicas(keyspace string, shard string) (replicas []*topodatapb.Tablet, err error) {
+	query := `SELECT
+		info
+	FROM
+		vitess_tablet
+	WHERE
+		keyspace = ? AND shard = ?
+		AND tablet_type != ?
+`
+	err = db.Db.QueryVTOrc(query, sqlutils.Args(keyspace, shard, topodatapb.TabletType_PRIMARY), func(m sqlutils.RowMap) error {
+		tablet := &topodatapb.Tablet{}
+		opts := prototext.UnmarshalOptions{DiscardUnknown: true}
+		if err := opts.Unmarshal([]byte(m.GetString("info")), tablet); err != nil {
+			return err
+		}
+		if topo.IsReplicaType(tablet.Type) {
+			replicas = append(replicas, tablet)
+		}
+		return nil
+	})
+	return replicas, err
+}
+
 // restartsReplication restarts the replication on the provided replicaKey. It also sets the correct semi-sync settings when it starts replication
 func restartReplication(replicaAlias string) error {
 	replicaTablet, err := inst.ReadTablet(replicaAlias)
@@ -25,10 +25,12 @@ import (
 
 	"github.com/patrickmn/go-cache"
 
+	"vitess.io/vitess/go/mysql"
 	"vitess.io/vitess/go/stats"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/logutil"
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/reparentutil"
 	"vitess.io/vitess/go/vt/vtorc/config"
@@ -47,6 +49,8 @@ const (
 	ElectNewPrimaryRecoveryName                      string = "ElectNewPrimary"
 	FixPrimaryRecoveryName                           string = "FixPrimary"
 	FixReplicaRecoveryName                           string = "FixReplica"
+	RecoverErrantGTIDDetectedRecoveryName            string = "RecoverErrantGTIDDetected"
+	RecoverUnreachablePrimaryRecoveryName            string = "RecoverUnreachablePrimary"
 )
 
 var (
@@ -56,6 +60,8 @@ var (
 		ElectNewPrimaryRecoveryName,
 		FixPrimaryRecoveryName,
 		FixReplicaRecoveryName,
+		RecoverErrantGTIDDetectedRecoveryName,
+		RecoverUnreachablePrimaryRecoveryName,
 	}
 
 	countPendingRecoveries = stats.NewGauge("PendingRecoveries", "Count of the number of pending recoveries")
@@ -84,6 +90,8 @@ const (
 	electNewPrimaryFunc
 	fixPrimaryFunc
 	fixReplicaFunc
+	recoverErrantGTIDDetectedFunc
+	recoverUnreachablePrimaryFunc
 )
 
 // TopologyRecovery represents an entry in the topology_recovery table
@@ -270,6 +278,46 @@ func postErsCompletion(topologyRecovery *TopologyRecovery, analysisEntry *inst.R
 	}
 }
 
+// recoverUnreachablePrimary runs ERS for a primary that VTOrc cannot reach, but only after confirming that the primary is gone.
+// The primary might only be partitioned from VTOrc, so its vttablet health stream must not report it as serving, and strictly more than
+// UnreachablePrimaryQuorumPercent of its replicas must confirm that they cannot replicate from it either. Otherwise, we hold off and alert.
+func recoverUnreachablePrimary(ctx context.Context, analysisEntry *inst.ReplicationAnalysis) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
+	if !analysisEntry.ClusterDetails.HasAutomatedPrimaryRecovery {
+		return false, nil, nil
+	}
+
+	primaryTablet, err := inst.ReadTablet(analysisEntry.AnalyzedInstanceAlias)
+	if err != nil {
+		return false, nil, err
+	}
+
+	if serving, err := primaryServing(ctx, primaryTablet); err == nil && serving {
+		holdOffUnreachablePrimaryRecovery(analysisEntry, primaryTablet, "its vttablet health stream reports it as serving")
+		return false, nil, nil
+	}
+
+	replicas, err := shardReplicas(primaryTablet.Keyspace, primaryTablet.Shard)
+	if err != nil {
+		return false, nil, err
+	}
+	confirmations := unreachablePrimaryConfirmations(ctx, primaryTablet, replicas)
+	if !unreachablePrimaryQuorumReached(confirmations, len(replicas)) {
+		holdOffUnreachablePrimaryRecovery(analysisEntry, primaryTablet, fmt.Sprintf("only %d out of %d replicas confirm that it is gone", confirmations, len(replicas)))
+		return false, nil, nil
+	}
+
+	_ = inst.AuditOperation(string(analysisEntry.Analysis), analysisEntry.AnalyzedInstanceAlias, fmt.Sprintf("%d out of %d replicas confirm that the primary is gone", confirmations, len(replicas)))
+	return recoverDeadPrimary(ctx, analysisEntry)
+}
+
+// holdOffUnreachablePrimaryRecovery raises an alert that VTOrc does not run ERS for the given unreachable primary, for the given reason.
+func holdOffUnreachablePrimaryRecovery(analysisEntry *inst.ReplicationAnalysis, primaryTablet *topodatapb.Tablet, reason string) {
+	message := fmt.Sprintf("holding off emergency reparent of unreachable primary %v: %s", analysisEntry.AnalyzedInstanceAlias, reason)
+	log.Warning(message)
+	unreachablePrimaryRecoveriesHeldOff.Add([]string{primaryTablet.Keyspace, primaryTablet.Shard}, 1)
+	_ = inst.AuditOperation(string(analysisEntry.Analysis), analysisEntry.AnalyzedInstanceAlias, message)
+}
+
 // checkAndRecoverGenericProblem is a general-purpose recovery function
 func checkAndRecoverLockedSemiSyncPrimary(ctx context.Context, analysisEntry *inst.ReplicationAnalysis) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
 	return false, nil, nil
@@ -395,13 +443,21 @@ func getCheckAndRecoverFunctionCode(analysisCode inst.AnalysisCode, tabletAlias
 	case inst.NotConnectedToPrimary, inst.ConnectedToWrongPrimary, inst.ReplicationStopped, inst.ReplicaIsWritable,
 		inst.ReplicaSemiSyncMustBeSet, inst.ReplicaSemiSyncMustNotBeSet:
 		return fixReplicaFunc
+	case inst.ErrantGTIDDetected:
+		// Errant GTIDs are only reported unless a remediation has been configured.
+		if config.Config.ErrantGTIDRemediation == config.ErrantGTIDRemediationNone {
+			return noRecoveryFunc
+		}
+		return recoverErrantGTIDDetectedFunc
 	// primary, non actionable
 	case inst.DeadPrimaryAndReplicas:
 		return recoverGenericProblemFunc
-	case inst.UnreachablePrimary:
-		return recoverGenericProblemFunc
-	case inst.UnreachablePrimaryWithLaggingReplicas:
-		return recoverGenericProblemFunc
+	case inst.UnreachablePrimary, inst.UnreachablePrimaryWithLaggingReplicas:
+		// Without ERS, or during the graceful period of an emergency operation, we only report the unreachable primary.
+		if !config.ERSEnabled() || isInEmergencyOperationGracefulPeriod(tabletAlias) {
+			return recoverGenericProblemFunc
+		}
+		return recoverUnreachablePrimaryFunc
 	case inst.AllPrimaryReplicasNotReplicating:
 		return recoverGenericProblemFunc
 	case inst.AllPrimaryReplicasNotReplicatingOrDead:
@@ -434,6 +490,10 @@ func hasActionableRecovery(recoveryFunctionCode recoveryFunction) bool {
 		return true
 	case fixReplicaFunc:
 		return true
+	case recoverErrantGTIDDetectedFunc:
+		return true
+	case recoverUnreachablePrimaryFunc:
+		return true
 	default:
 		return false
 	}
@@ -460,6 +520,10 @@ func getCheckAndRecoverFunction(recoveryFunctionCode recoveryFunction) (
 		return fixPrimary
 	case fixReplicaFunc:
 		return fixReplica
+	case recoverErrantGTIDDetectedFunc:
+		return recoverErrantGTIDDetected
+	case recoverUnreachablePrimaryFunc:
+		return recoverUnreachablePrimary
 	default:
 		return nil
 	}
@@ -485,6 +549,10 @@ func getRecoverFunctionName(recoveryFunctionCode recoveryFunction) string {
 		return FixPrimaryRecoveryName
 	case fixReplicaFunc:
 		return FixReplicaRecoveryName
+	case recoverErrantGTIDDetectedFunc:
+		return RecoverErrantGTIDDetectedRecoveryName
+	case recoverUnreachablePrimaryFunc:
+		return RecoverUnreachablePrimaryRecoveryName
 	default:
 		return ""
 	}
@@ -493,7 +561,7 @@ func getRecoverFunctionName(recoveryFunctionCode recoveryFunction) string {
 // isClusterWideRecovery returns whether the given recovery is a cluster-wide recovery or not
 func isClusterWideRecovery(recoveryFunctionCode recoveryFunction) bool {
 	switch recoveryFunctionCode {
-	case recoverDeadPrimaryFunc, electNewPrimaryFunc:
+	case recoverDeadPrimaryFunc, recoverUnreachablePrimaryFunc, electNewPrimaryFunc:
 		return true
 	default:
 		return false
@@ -577,6 +645,17 @@ func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (er
 		return err
 	}
 
+	// Check for recovery being disabled for the shard
+	if recoveryDisabledForShard, err := IsShardRecoveryDisabled(analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard); err != nil {
+		// Unexpected. Shouldn't get this
+		log.Errorf("Unable to determine if recovery is disabled for %v/%v: %v", analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard, err)
+	} else if recoveryDisabledForShard {
+		log.Infof("CheckAndRecover: Analysis: %+v, Tablet: %+v: NOT Recovering host (disabled for %v/%v)",
+			analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias, analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard)
+
+		return err
+	}
+
 	// We lock the shard here and then refresh the tablets information
 	ctx, unlock, err := LockShard(context.Background(), analysisEntry.AnalyzedInstanceAlias, getLockAction(analysisEntry.AnalyzedInstanceAlias, analysisEntry.Analysis))
 	if err != nil {
@@ -602,10 +681,10 @@ func executeCheckAndRecoverFunction(analysisEntry *inst.ReplicationAnalysis) (er
 		// run a cluster operation of our own.
 		if isClusterWideRecovery(checkAndRecoverFunctionCode) {
 			var tabletsToIgnore []string
-			if checkAndRecoverFunctionCode == recoverDeadPrimaryFunc {
+			if checkAndRecoverFunctionCode == recoverDeadPrimaryFunc || checkAndRecoverFunctionCode == recoverUnreachablePrimaryFunc {
 				tabletsToIgnore = append(tabletsToIgnore, analysisEntry.AnalyzedInstanceAlias)
 			}
-			// We ignore the dead primary tablet because it is going to be unreachable. If all the other tablets aren't able to reach this tablet either,
+			// We ignore the dead or unreachable primary tablet because it is going to be unreachable. If all the other tablets aren't able to reach this tablet either,
 			// we can proceed with the dead primary recovery. We don't need to refresh the information for this dead tablet.
 			forceRefreshAllTabletsInShard(ctx, analysisEntry.AnalyzedKeyspace, analysisEntry.AnalyzedShard, tabletsToIgnore)
 		} else {
@@ -830,7 +909,7 @@ func fixReplica(ctx context.Context, analysisEntry *inst.ReplicationAnalysis) (r
 
 	primaryTablet, err := shardPrimary(analyzedTablet.Keyspace, analyzedTablet.Shard)
 	if err != nil {
-		log.Info("Could not compute primary for %v/%v", analyzedTablet.Keyspace, analyzedTablet.Shard)
+		log.Infof("Could not compute primary for %v/%v", analyzedTablet.Keyspace, analyzedTablet.Shard)
 		return false, topologyRecovery, err
 	}
 
@@ -849,3 +928,71 @@ func fixReplica(ctx context.Context, analysisEntry *inst.ReplicationAnalysis) (r
 	err = setReplicationSource(ctx, analyzedTablet, primaryTablet, reparentutil.IsReplicaSemiSync(durabilityPolicy, primaryTablet, analyzedTablet))
 	return true, topologyRecovery, err
 }
+
+// recoverErrantGTIDDetected remediates the errant GTIDs of a replica, as configured by ErrantGTIDRemediation.
+// The replica is either changed to DRAINED, which makes it non-promotable, or empty transactions are committed
+// for its errant GTIDs on the primary, which makes them part of the replication stream of the shard.
+func recoverErrantGTIDDetected(ctx context.Context, analysisEntry *inst.ReplicationAnalysis) (recoveryAttempted bool, topologyRecovery *TopologyRecovery, err error) {
+	topologyRecovery, err = AttemptRecoveryRegistration(analysisEntry, false, true)
+	if topologyRecovery == nil {
+		_ = AuditTopologyRecovery(topologyRecovery, fmt.Sprintf("found an active or recent recovery on %+v. Will not issue another recoverErrantGTIDDetected.", analysisEntry.AnalyzedInstanceAlias))
+		return false, nil, err
+	}
+	log.Infof("Analysis: %v, will remediate errant GTIDs of %+v", analysisEntry.Analysis, analysisEntry.AnalyzedInstanceAlias)
+	// This has to be done in the end; whether successful or not, we should mark that the recovery is done.
+	// So that after the active period passes, we are able to run other recoveries.
+	defer func() {
+		_ = resolveRecovery(topologyRecovery, nil)
+	}()
+
+	analyzedTablet, err := inst.ReadTablet(analysisEntry.AnalyzedInstanceAlias)
+	if err != nil {
+		return false, topologyRecovery, err
+	}
+	// The instance was refreshed before running the recovery, so we use its errant GTIDs instead of the ones of the analysis.
+	instance, found, err := inst.ReadInstance(analysisEntry.AnalyzedInstanceAlias)
+	if err != nil {
+		return false, topologyRecovery, err
+	}
+	if !found || instance.GtidErrant == "" {
+		return false, topologyRecovery, nil
+	}
+
+	var message string
+	switch config.Config.ErrantGTIDRemediation {
+	case config.ErrantGTIDRemediationDrain:
+		message = fmt.Sprintf("changed the tablet type of %v with errant GTIDs %v to DRAINED", analysisEntry.AnalyzedInstanceAlias, instance.GtidErrant)
+		// A DRAINED tablet never sends semi-sync ACKs.
+		err = changeTabletType(ctx, analyzedTablet, topodatapb.TabletType_DRAINED, false)
+	case config.ErrantGTIDRemediationInjectEmptyTransactions:
+		var primaryTablet *topodatapb.Tablet
+		primaryTablet, err = shardPrimary(analyzedTablet.Keyspace, analyzedTablet.Shard)
+		if err != nil {
+			log.Infof("Could not compute primary for %v/%v", analyzedTablet.Keyspace, analyzedTablet.Shard)
+			return false, topologyRecovery, err
+		}
+		var errantGTIDs mysql.Mysql56GTIDSet
+		errantGTIDs, err = mysql.ParseMysql56GTIDSet(instance.GtidErrant)
+		if err != nil {
+			break
+		}
+		// Every errant GTID becomes a transaction on the primary, so a replica with too many of them
+		// is left for the operator to drain instead.
+		if count := errantGTIDs.Count(); count > int64(config.Config.ErrantGTIDMaxInjectedTransactions) {
+			err = fmt.Errorf("%v has %d errant GTIDs, more than the %d empty transactions VTOrc injects; drain it instead", analysisEntry.AnalyzedInstanceAlias, count, config.Config.ErrantGTIDMaxInjectedTransactions)
+			break
+		}
+		message = fmt.Sprintf("injected empty transactions for errant GTIDs %v of %v on the primary %v", instance.GtidErrant, analysisEntry.AnalyzedInstanceAlias, topoproto.TabletAliasString(primaryTablet.Alias))
+		err = injectEmptyTransactions(ctx, primaryTablet, instance.GtidErrant)
+	default:
+		err = fmt.Errorf("unknown errant GTID remediation: %v", config.Config.ErrantGTIDRemediation)
+	}
+	if err != nil {
+		_ = topologyRecovery.AddError(err)
+		_ = AuditTopologyRecovery(topologyRecovery, fmt.Sprintf("failed to remediate errant GTIDs %v of %v: %v", instance.GtidErrant, analysisEntry.AnalyzedInstanceAlias, err))
+		return true, topologyRecovery, err
+	}
+	_ = AuditTopologyRecovery(topologyRecovery, message)
+	_ = inst.AuditOperation(string(analysisEntry.Analysis), analysisEntry.AnalyzedInstanceAlias, message)
+	return true, topologyRecovery, nil
+}
@@ -512,12 +512,25 @@ func ReadInActivePeriodSuccessorInstanceRecovery(tabletAlias string) ([]*Topolog
 
 // ReadRecentRecoveries reads latest recovery entries from topology_recovery
 func ReadRecentRecoveries(unacknowledgedOnly bool, page int) ([]*TopologyRecovery, error) {
+	return ReadRecentShardRecoveries("", "", unacknowledgedOnly, page)
+}
+
+// ReadRecentShardRecoveries reads latest recovery entries from topology_recovery, optionally filtered by keyspace and shard
+func ReadRecentShardRecoveries(keyspace string, shard string, unacknowledgedOnly bool, page int) ([]*TopologyRecovery, error) {
 	whereConditions := []string{}
 	whereClause := ""
 	var args []any
 	if unacknowledgedOnly {
 		whereConditions = append(whereConditions, `acknowledged=0`)
 	}
+	if keyspace != "" {
+		whereConditions = append(whereConditions, `keyspace=?`)
+		args = append(args, keyspace)
+		if shard != "" {
+			whereConditions = append(whereConditions, `shard=?`)
+			args = append(args, shard)
+		}
+	}
 	if len(whereConditions) > 0 {
 		whereClause = fmt.Sprintf("where %s", strings.Join(whereConditions, " and "))
 	}
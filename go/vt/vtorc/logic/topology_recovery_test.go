@@ -18,6 +18,7 @@ package logic
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
@@ -31,6 +32,7 @@ import (
 	"vitess.io/vitess/go/vt/vtorc/db"
 	"vitess.io/vitess/go/vt/vtorc/inst"
 	_ "vitess.io/vitess/go/vt/vttablet/grpctmclient"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
 )
 
 func TestAnalysisEntriesHaveSameRecovery(t *testing.T) {
@@ -184,10 +186,11 @@ func TestDifferentAnalysescHaveDifferentCooldowns(t *testing.T) {
 
 func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 	tests := []struct {
-		name                 string
-		ersEnabled           bool
-		analysisCode         inst.AnalysisCode
-		wantRecoveryFunction recoveryFunction
+		name                  string
+		ersEnabled            bool
+		errantGTIDRemediation string
+		analysisCode          inst.AnalysisCode
+		wantRecoveryFunction  recoveryFunction
 	}{
 		{
 			name:                 "DeadPrimary with ERS enabled",
@@ -219,6 +222,36 @@ func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 			ersEnabled:           false,
 			analysisCode:         inst.PrimarySemiSyncMustBeSet,
 			wantRecoveryFunction: fixPrimaryFunc,
+		}, {
+			name:                 "UnreachablePrimary with ERS enabled",
+			ersEnabled:           true,
+			analysisCode:         inst.UnreachablePrimary,
+			wantRecoveryFunction: recoverUnreachablePrimaryFunc,
+		}, {
+			name:                 "UnreachablePrimaryWithLaggingReplicas with ERS enabled",
+			ersEnabled:           true,
+			analysisCode:         inst.UnreachablePrimaryWithLaggingReplicas,
+			wantRecoveryFunction: recoverUnreachablePrimaryFunc,
+		}, {
+			name:                 "UnreachablePrimary with ERS disabled",
+			ersEnabled:           false,
+			analysisCode:         inst.UnreachablePrimary,
+			wantRecoveryFunction: recoverGenericProblemFunc,
+		}, {
+			name:                  "ErrantGTIDDetected with remediation disabled",
+			errantGTIDRemediation: config.ErrantGTIDRemediationNone,
+			analysisCode:          inst.ErrantGTIDDetected,
+			wantRecoveryFunction:  noRecoveryFunc,
+		}, {
+			name:                  "ErrantGTIDDetected with drain remediation",
+			errantGTIDRemediation: config.ErrantGTIDRemediationDrain,
+			analysisCode:          inst.ErrantGTIDDetected,
+			wantRecoveryFunction:  recoverErrantGTIDDetectedFunc,
+		}, {
+			name:                  "ErrantGTIDDetected with inject remediation",
+			errantGTIDRemediation: config.ErrantGTIDRemediationInjectEmptyTransactions,
+			analysisCode:          inst.ErrantGTIDDetected,
+			wantRecoveryFunction:  recoverErrantGTIDDetectedFunc,
 		},
 	}
 
@@ -233,9 +266,147 @@ func TestGetCheckAndRecoverFunctionCode(t *testing.T) {
 			prevVal := config.ERSEnabled()
 			config.SetERSEnabled(tt.ersEnabled)
 			defer config.SetERSEnabled(prevVal)
+			prevRemediation := config.Config.ErrantGTIDRemediation
+			if tt.errantGTIDRemediation != "" {
+				config.Config.ErrantGTIDRemediation = tt.errantGTIDRemediation
+			}
+			defer func() {
+				config.Config.ErrantGTIDRemediation = prevRemediation
+			}()
 
 			gotFunc := getCheckAndRecoverFunctionCode(tt.analysisCode, "")
 			require.EqualValues(t, tt.wantRecoveryFunction, gotFunc)
 		})
 	}
 }
+
+// errantGTIDTMClient is a tmclient.TabletManagerClient that records the calls of the errant GTID remediations.
+type errantGTIDTMClient struct {
+	tmclient.TabletManagerClient
+	changedTypes map[string]topodatapb.TabletType
+	injected     map[string]string
+}
+
+func (c *errantGTIDTMClient) ChangeType(ctx context.Context, tablet *topodatapb.Tablet, dbType topodatapb.TabletType, semiSync bool) error {
+	c.changedTypes[topoproto.TabletAliasString(tablet.Alias)] = dbType
+	return nil
+}
+
+func (c *errantGTIDTMClient) InjectEmptyTransactions(ctx context.Context, tablet *topodatapb.Tablet, gtidSet string) error {
+	c.injected[topoproto.TabletAliasString(tablet.Alias)] = gtidSet
+	return nil
+}
+
+func TestRecoverErrantGTIDDetected(t *testing.T) {
+	primary := &topodatapb.Tablet{
+		Alias:         &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Hostname:      "localhost1",
+		MysqlHostname: "localhost1",
+		MysqlPort:     1200,
+		Keyspace:      "ks",
+		Shard:         "0",
+		Type:          topodatapb.TabletType_PRIMARY,
+	}
+	replica := &topodatapb.Tablet{
+		Alias:         &topodatapb.TabletAlias{Cell: "zone1", Uid: 101},
+		Hostname:      "localhost2",
+		MysqlHostname: "localhost2",
+		MysqlPort:     1200,
+		Keyspace:      "ks",
+		Shard:         "0",
+		Type:          topodatapb.TabletType_REPLICA,
+	}
+	primaryAlias := topoproto.TabletAliasString(primary.Alias)
+	replicaAlias := topoproto.TabletAliasString(replica.Alias)
+
+	tests := []struct {
+		name             string
+		remediation      string
+		gtidErrant       string
+		maxInjected      int
+		wantErr          string
+		wantChangedTypes map[string]topodatapb.TabletType
+		wantInjected     map[string]string
+	}{
+		{
+			name:             "drain",
+			remediation:      config.ErrantGTIDRemediationDrain,
+			gtidErrant:       "729a4cc4-8680-11ed-a104-47706090afbd:5-7",
+			maxInjected:      1000,
+			wantChangedTypes: map[string]topodatapb.TabletType{replicaAlias: topodatapb.TabletType_DRAINED},
+			wantInjected:     map[string]string{},
+		}, {
+			name:             "inject empty transactions",
+			remediation:      config.ErrantGTIDRemediationInjectEmptyTransactions,
+			gtidErrant:       "729a4cc4-8680-11ed-a104-47706090afbd:5-7",
+			maxInjected:      1000,
+			wantChangedTypes: map[string]topodatapb.TabletType{},
+			wantInjected:     map[string]string{primaryAlias: "729a4cc4-8680-11ed-a104-47706090afbd:5-7"},
+		}, {
+			name:             "inject empty transactions up to the limit",
+			remediation:      config.ErrantGTIDRemediationInjectEmptyTransactions,
+			gtidErrant:       "729a4cc4-8680-11ed-a104-47706090afbd:5-7,729a5138-8680-11ed-9240-92a06c3be3c2:1",
+			maxInjected:      4,
+			wantChangedTypes: map[string]topodatapb.TabletType{},
+			wantInjected:     map[string]string{primaryAlias: "729a4cc4-8680-11ed-a104-47706090afbd:5-7,729a5138-8680-11ed-9240-92a06c3be3c2:1"},
+		}, {
+			name:             "too many errant GTIDs to inject",
+			remediation:      config.ErrantGTIDRemediationInjectEmptyTransactions,
+			gtidErrant:       "729a4cc4-8680-11ed-a104-47706090afbd:1-1000000",
+			maxInjected:      1000,
+			wantErr:          fmt.Sprintf("%v has 1000000 errant GTIDs, more than the 1000 empty transactions VTOrc injects; drain it instead", replicaAlias),
+			wantChangedTypes: map[string]topodatapb.TabletType{},
+			wantInjected:     map[string]string{},
+		},
+	}
+
+	orcDb, err := db.OpenVTOrc()
+	require.NoError(t, err)
+	oldTmc := tmc
+	oldRemediation := config.Config.ErrantGTIDRemediation
+	oldMaxInjected := config.Config.ErrantGTIDMaxInjectedTransactions
+	defer func() {
+		tmc = oldTmc
+		config.Config.ErrantGTIDRemediation = oldRemediation
+		config.Config.ErrantGTIDMaxInjectedTransactions = oldMaxInjected
+	}()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				for _, table := range []string{"vitess_tablet", "database_instance", "topology_recovery"} {
+					_, err := orcDb.Exec("delete from " + table)
+					require.NoError(t, err)
+				}
+			}()
+			require.NoError(t, inst.SaveTablet(primary))
+			require.NoError(t, inst.SaveTablet(replica))
+			_, err := orcDb.Exec(`INSERT INTO database_instance VALUES('zone1-0000000101','localhost2',1200,'2022-12-28 07:26:04','2022-12-28 07:26:04',213696377,'8.0.31','ROW',1,1,'vt-0000000101-bin.000001',15963,'localhost1',1200,1,1,'vt-0000000100-bin.000001',15583,'vt-0000000100-bin.000001',15583,0,0,1,'','',1,0,'vt-0000000101-relay-bin.000002',15815,0,1,0,'zone1','',0,0,0,1,'729a4cc4-8680-11ed-a104-47706090afbd:1-54','729a5138-8680-11ed-9240-92a06c3be3c2','2022-12-28 07:26:04','',1,0,0,'Homebrew','8.0','FULL',10816929,0,0,'ON',1,'729a4cc4-8680-11ed-a104-47706090afbd','','729a4cc4-8680-11ed-a104-47706090afbd,729a5138-8680-11ed-9240-92a06c3be3c2',1,1,'',1000000000000000000,1,0,0,0);`)
+			require.NoError(t, err)
+			_, err = orcDb.Exec("update database_instance set gtid_errant = ? where alias = ?", tt.gtidErrant, replicaAlias)
+			require.NoError(t, err)
+
+			tmClient := &errantGTIDTMClient{
+				changedTypes: map[string]topodatapb.TabletType{},
+				injected:     map[string]string{},
+			}
+			tmc = tmClient
+			config.Config.ErrantGTIDRemediation = tt.remediation
+			config.Config.ErrantGTIDMaxInjectedTransactions = tt.maxInjected
+
+			recoveryAttempted, _, err := recoverErrantGTIDDetected(context.Background(), &inst.ReplicationAnalysis{
+				AnalyzedInstanceAlias: replicaAlias,
+				Analysis:              inst.ErrantGTIDDetected,
+				ClusterDetails:        inst.ClusterInfo{Keyspace: "ks", Shard: "0"},
+			})
+			require.True(t, recoveryAttempted)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantChangedTypes, tmClient.changedTypes)
+			require.Equal(t, tt.wantInjected, tmClient.injected)
+		})
+	}
+}
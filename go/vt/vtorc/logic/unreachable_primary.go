@@ -0,0 +1,104 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/grpcclient"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// unreachablePrimaryRecoveriesHeldOff counts the emergency reparents of unreachable primaries that VTOrc held off,
+// because it could not confirm that the primary is gone.
+var unreachablePrimaryRecoveriesHeldOff = stats.NewCountersWithMultiLabels("UnreachablePrimaryRecoveriesHeldOff", "Count of the emergency reparents of unreachable primaries held off for lack of a quorum", []string{"Keyspace", "Shard"})
+
+// primaryServing reads the first message of the health stream of the given primary tablet,
+// and reports whether it is serving without any health error.
+// A primary that VTOrc cannot reach is expected to fail this with an error.
+func primaryServing(ctx context.Context, primary *topodatapb.Tablet) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(config.Config.WaitReplicasTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	conn, err := tabletconn.GetDialer()(primary, grpcclient.FailFast(true))
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close(ctx)
+
+	serving := false
+	err = conn.StreamHealth(ctx, func(shr *querypb.StreamHealthResponse) error {
+		serving = shr.Serving && shr.GetRealtimeStats().GetHealthError() == ""
+		return io.EOF
+	})
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	return serving, nil
+}
+
+// unreachablePrimaryConfirmations concurrently reads the replication status of the given replicas, and returns
+// how many of them confirm that they cannot replicate from the given primary. A replica confirms it when its IO
+// thread replicating from the primary is broken with an error. Replicas that cannot be reached themselves,
+// that replicate from another source, or whose replication was just stopped, don't confirm anything.
+func unreachablePrimaryConfirmations(ctx context.Context, primary *topodatapb.Tablet, replicas []*topodatapb.Tablet) int {
+	var (
+		mu            sync.Mutex
+		wg            sync.WaitGroup
+		confirmations int
+	)
+	for _, replica := range replicas {
+		wg.Add(1)
+		go func(replica *topodatapb.Tablet) {
+			defer wg.Done()
+			status, err := tmc.ReplicationStatus(ctx, replica)
+			if err != nil {
+				log.Infof("Could not read the replication status of %v - %v", topoproto.TabletAliasString(replica.Alias), err)
+				return
+			}
+			replicationStatus := mysql.ProtoToReplicationStatus(status)
+			if replicationStatus.SourceHost != primary.MysqlHostname || replicationStatus.SourcePort != primary.MysqlPort {
+				return
+			}
+			if replicationStatus.IOHealthy() || replicationStatus.LastIOError == "" {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			confirmations++
+		}(replica)
+	}
+	wg.Wait()
+	return confirmations
+}
+
+// unreachablePrimaryQuorumReached reports whether the given number of confirmations, out of the given number of replicas,
+// is strictly more than the configured quorum percentage.
+func unreachablePrimaryQuorumReached(confirmations int, replicas int) bool {
+	return replicas > 0 && confirmations*100 > config.Config.UnreachablePrimaryQuorumPercent*replicas
+}
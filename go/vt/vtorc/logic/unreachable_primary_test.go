@@ -0,0 +1,133 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logic
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vtorc/config"
+	"vitess.io/vitess/go/vt/vttablet/tmclient"
+
+	replicationdatapb "vitess.io/vitess/go/vt/proto/replicationdata"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+// replicationStatusTMClient is a tmclient.TabletManagerClient that only answers ReplicationStatus,
+// with the statuses configured per tablet alias.
+type replicationStatusTMClient struct {
+	tmclient.TabletManagerClient
+	statuses map[string]*replicationdatapb.Status
+}
+
+func (c *replicationStatusTMClient) ReplicationStatus(ctx context.Context, tablet *topodatapb.Tablet) (*replicationdatapb.Status, error) {
+	status, ok := c.statuses[topoproto.TabletAliasString(tablet.Alias)]
+	if !ok {
+		return nil, fmt.Errorf("tablet %v is unreachable", topoproto.TabletAliasString(tablet.Alias))
+	}
+	return status, nil
+}
+
+func TestUnreachablePrimaryConfirmations(t *testing.T) {
+	primary := &topodatapb.Tablet{
+		Alias:         &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		MysqlHostname: "primary",
+		MysqlPort:     3306,
+	}
+	var replicas []*topodatapb.Tablet
+	for uid := uint32(101); uid <= 105; uid++ {
+		replicas = append(replicas, &topodatapb.Tablet{Alias: &topodatapb.TabletAlias{Cell: "zone1", Uid: uid}})
+	}
+
+	oldTmc := tmc
+	defer func() {
+		tmc = oldTmc
+	}()
+	tmc = &replicationStatusTMClient{
+		statuses: map[string]*replicationdatapb.Status{
+			// Broken IO thread replicating from the primary.
+			"zone1-0000000101": {SourceHost: "primary", SourcePort: 3306, IoState: int32(mysql.ReplicationStateConnecting), LastIoError: "error reconnecting to source"},
+			"zone1-0000000102": {SourceHost: "primary", SourcePort: 3306, IoState: int32(mysql.ReplicationStateStopped), LastIoError: "error connecting to source"},
+			// Healthy IO thread replicating from the primary.
+			"zone1-0000000103": {SourceHost: "primary", SourcePort: 3306, IoState: int32(mysql.ReplicationStateRunning)},
+			// Broken IO thread replicating from another source.
+			"zone1-0000000104": {SourceHost: "other", SourcePort: 3306, IoState: int32(mysql.ReplicationStateConnecting), LastIoError: "error reconnecting to source"},
+			// zone1-0000000105 is unreachable.
+		},
+	}
+
+	confirmations := unreachablePrimaryConfirmations(context.Background(), primary, replicas)
+	require.Equal(t, 2, confirmations)
+}
+
+func TestUnreachablePrimaryQuorumReached(t *testing.T) {
+	tests := []struct {
+		name          string
+		quorum        int
+		confirmations int
+		replicas      int
+		want          bool
+	}{
+		{
+			name:          "majority",
+			quorum:        50,
+			confirmations: 2,
+			replicas:      3,
+			want:          true,
+		}, {
+			name:          "half is not a majority",
+			quorum:        50,
+			confirmations: 1,
+			replicas:      2,
+			want:          false,
+		}, {
+			name:          "no replicas",
+			quorum:        0,
+			confirmations: 0,
+			replicas:      0,
+			want:          false,
+		}, {
+			name:          "any confirmation",
+			quorum:        0,
+			confirmations: 1,
+			replicas:      5,
+			want:          true,
+		}, {
+			name:          "all but one",
+			quorum:        75,
+			confirmations: 3,
+			replicas:      4,
+			want:          false,
+		},
+	}
+
+	oldQuorum := config.Config.UnreachablePrimaryQuorumPercent
+	defer func() {
+		config.Config.UnreachablePrimaryQuorumPercent = oldQuorum
+	}()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config.Config.UnreachablePrimaryQuorumPercent = tt.quorum
+			require.Equal(t, tt.want, unreachablePrimaryQuorumReached(tt.confirmations, tt.replicas))
+		})
+	}
+}
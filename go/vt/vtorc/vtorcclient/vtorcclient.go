@@ -0,0 +1,99 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package vtorcclient defines the generic RPC client interface for the
+// VTOrc service. It has to be implemented for the different RPC frameworks
+// e.g. gRPC.
+package vtorcclient
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"vitess.io/vitess/go/vt/log"
+
+	vtorcdatapb "vitess.io/vitess/go/vt/proto/vtorcdata"
+)
+
+// protocol specifies which RPC client implementation should be used.
+var protocol = "grpc"
+
+// RegisterFlags registers the vtorcclient flags on a given flagset. It is
+// exported for the binaries and tools that talk to VTOrc.
+func RegisterFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&protocol, "vtorc_client_protocol", protocol, "the protocol to use to talk to the VTOrc service")
+}
+
+// Client defines the generic RPC interface for the VTOrc service.
+// The keyspace and shard filters are optional, but a shard requires a keyspace.
+type Client interface {
+	// GetProblems returns the instances that have problems.
+	GetProblems(ctx context.Context, keyspace string, shard string) ([]*vtorcdatapb.Instance, error)
+
+	// GetReplicationAnalysis returns the problems that VTOrc currently detects.
+	GetReplicationAnalysis(ctx context.Context, keyspace string, shard string) ([]*vtorcdatapb.ReplicationAnalysis, error)
+
+	// GetRecoveries returns the given page of the history of the recoveries
+	// that VTOrc ran, most recent first.
+	GetRecoveries(ctx context.Context, keyspace string, shard string, unacknowledgedOnly bool, page int32) ([]*vtorcdatapb.TopologyRecovery, error)
+
+	// GetRecoveryStatus returns whether recoveries are disabled globally,
+	// and the keyspaces/shards for which they are disabled.
+	GetRecoveryStatus(ctx context.Context) (*vtorcdatapb.GetRecoveryStatusResponse, error)
+
+	// DisableGlobalRecoveries disables all the recoveries.
+	DisableGlobalRecoveries(ctx context.Context) error
+
+	// EnableGlobalRecoveries enables the recoveries again, except for the
+	// keyspaces/shards for which they are disabled.
+	EnableGlobalRecoveries(ctx context.Context) error
+
+	// DisableShardRecoveries disables the recoveries of the given shard, or
+	// of the whole keyspace if "shard" is empty.
+	DisableShardRecoveries(ctx context.Context, keyspace string, shard string) error
+
+	// EnableShardRecoveries reverts DisableShardRecoveries for the same
+	// keyspace and shard.
+	EnableShardRecoveries(ctx context.Context, keyspace string, shard string) error
+
+	// Close will terminate the connection and free resources.
+	Close()
+}
+
+// Factory has to be implemented and must create a new RPC client for a given
+// "addr".
+type Factory func(addr string) (Client, error)
+
+var factories = make(map[string]Factory)
+
+// RegisterFactory allows a client implementation to register itself.
+func RegisterFactory(name string, factory Factory) {
+	if _, ok := factories[name]; ok {
+		log.Fatalf("RegisterFactory: %s already exists", name)
+	}
+	factories[name] = factory
+}
+
+// New will return a client for the selected RPC implementation.
+func New(addr string) (Client, error) {
+	factory, ok := factories[protocol]
+	if !ok {
+		return nil, fmt.Errorf("unknown vtorc client protocol: %v", protocol)
+	}
+	return factory(addr)
+}
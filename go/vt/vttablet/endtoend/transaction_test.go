@@ -315,6 +315,44 @@ func TestPrepareReparentCommit(t *testing.T) {
 	assert.Equal(t, 4, len(qr.Rows))
 }
 
+func TestPrepareReparentMidCommit(t *testing.T) {
+	client := framework.NewClient()
+	defer client.Execute("delete from vitess_test where intval=4", nil)
+
+	query := "insert into vitess_test (intval, floatval, charval, binval) " +
+		"values(4, null, null, null)"
+	err := client.Begin(false)
+	require.NoError(t, err)
+	_, err = client.Execute(query, nil)
+	require.NoError(t, err)
+	err = client.Prepare("aa")
+	if err != nil {
+		client.RollbackPrepared("aa", 0)
+		t.Fatal(err)
+	}
+
+	// The prepared transaction is handed over through the redo log,
+	// so it must not hold up the demotion until the grace period expires.
+	start := time.Now()
+	err = client.SetServingType(topodatapb.TabletType_REPLICA)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// The commit decision reaches the demoted primary: it must be rejected.
+	err = client.CommitPrepared("aa")
+	require.Error(t, err)
+
+	// The new primary resurrects the prepared transaction before serving,
+	// and the retried commit succeeds.
+	err = client.SetServingType(topodatapb.TabletType_PRIMARY)
+	require.NoError(t, err)
+	err = client.CommitPrepared("aa")
+	require.NoError(t, err)
+	qr, err := client.Execute("select * from vitess_test", nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, len(qr.Rows))
+}
+
 func TestShutdownGracePeriod(t *testing.T) {
 	client := framework.NewClient()
 
@@ -237,6 +237,11 @@ func (client *FakeTabletManagerClient) ResetReplicationParameters(ctx context.Co
 	return nil
 }
 
+// InjectEmptyTransactions is part of the tmclient.TabletManagerClient interface.
+func (client *FakeTabletManagerClient) InjectEmptyTransactions(ctx context.Context, tablet *topodatapb.Tablet, gtidSet string) error {
+	return nil
+}
+
 // ReplicaWasRestarted is part of the tmclient.TabletManagerClient interface.
 func (client *FakeTabletManagerClient) ReplicaWasRestarted(ctx context.Context, tablet *topodatapb.Tablet, parent *topodatapb.TabletAlias) error {
 	return nil
@@ -63,6 +63,7 @@ func init() {
 		"vtctl",
 		"vtctld",
 		"vtgate",
+		"vtorc",
 		"vttablet",
 	} {
 		servenv.OnParseFor(cmd, registerFlags)
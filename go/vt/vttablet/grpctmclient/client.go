@@ -858,6 +858,19 @@ func (client *Client) ResetReplicationParameters(ctx context.Context, tablet *to
 	return err
 }
 
+// InjectEmptyTransactions is part of the tmclient.TabletManagerClient interface.
+func (client *Client) InjectEmptyTransactions(ctx context.Context, tablet *topodatapb.Tablet, gtidSet string) error {
+	c, closer, err := client.dialer.dial(ctx, tablet)
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	_, err = c.InjectEmptyTransactions(ctx, &tabletmanagerdatapb.InjectEmptyTransactionsRequest{
+		GtidSet: gtidSet,
+	})
+	return err
+}
+
 // SetReplicationSource is part of the tmclient.TabletManagerClient interface.
 func (client *Client) SetReplicationSource(ctx context.Context, tablet *topodatapb.Tablet, parent *topodatapb.TabletAlias, timeCreatedNS int64, waitPosition string, forceStartReplication bool, semiSync bool) error {
 	c, closer, err := client.dialer.dial(ctx, tablet)
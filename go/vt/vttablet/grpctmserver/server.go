@@ -448,6 +448,13 @@ func (s *server) ResetReplicationParameters(ctx context.Context, request *tablet
 	return response, s.tm.ResetReplicationParameters(ctx)
 }
 
+func (s *server) InjectEmptyTransactions(ctx context.Context, request *tabletmanagerdatapb.InjectEmptyTransactionsRequest) (response *tabletmanagerdatapb.InjectEmptyTransactionsResponse, err error) {
+	defer s.tm.HandleRPCPanic(ctx, "InjectEmptyTransactions", request, response, true /*verbose*/, &err)
+	ctx = callinfo.GRPCCallInfo(ctx)
+	response = &tabletmanagerdatapb.InjectEmptyTransactionsResponse{}
+	return response, s.tm.InjectEmptyTransactions(ctx, request.GtidSet)
+}
+
 func (s *server) SetReplicationSource(ctx context.Context, request *tabletmanagerdatapb.SetReplicationSourceRequest) (response *tabletmanagerdatapb.SetReplicationSourceResponse, err error) {
 	defer s.tm.HandleRPCPanic(ctx, "SetReplicationSource", request, response, true /*verbose*/, &err)
 	ctx = callinfo.GRPCCallInfo(ctx)
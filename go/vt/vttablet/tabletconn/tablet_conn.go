@@ -51,6 +51,7 @@ func init() {
 		"vtctld",
 		"vtctldclient",
 		"vtgate",
+		"vtorc",
 		"vttablet",
 	} {
 		servenv.OnParseFor(cmd, RegisterFlags)
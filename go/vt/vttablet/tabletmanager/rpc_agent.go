@@ -124,6 +124,8 @@ type RPCTM interface {
 
 	ResetReplicationParameters(ctx context.Context) error
 
+	InjectEmptyTransactions(ctx context.Context, gtidSet string) error
+
 	SetReplicationSource(ctx context.Context, parent *topodatapb.TabletAlias, timeCreatedNS int64, waitPosition string, forceStartReplication bool, semiSync bool) error
 
 	StopReplicationAndGetStatus(ctx context.Context, stopReplicationMode replicationdatapb.StopReplicationMode) (StopReplicationAndGetStatusResponse, error)
@@ -592,6 +592,21 @@ func (tm *TabletManager) ResetReplicationParameters(ctx context.Context) error {
 	return nil
 }
 
+// InjectEmptyTransactions commits an empty transaction for every GTID of the given set.
+// It is used to make errant GTIDs of a replica part of the primary's executed GTID set.
+func (tm *TabletManager) InjectEmptyTransactions(ctx context.Context, gtidSet string) error {
+	log.Infof("InjectEmptyTransactions: %v", gtidSet)
+	if err := tm.lock(ctx); err != nil {
+		return err
+	}
+	defer tm.unlock()
+
+	if tabletType := tm.Tablet().Type; tabletType != topodatapb.TabletType_PRIMARY {
+		return vterrors.Errorf(vtrpc.Code_FAILED_PRECONDITION, "empty transactions can only be injected on a primary tablet, this tablet is %v", tabletType)
+	}
+	return tm.MysqlDaemon.InjectEmptyTransactions(ctx, gtidSet)
+}
+
 // SetReplicationSource sets replication primary, and waits for the
 // reparent_journal table entry up to context timeout
 func (tm *TabletManager) SetReplicationSource(ctx context.Context, parentAlias *topodatapb.TabletAlias, timeCreatedNS int64, waitPosition string, forceStartReplication bool, semiSync bool) error {
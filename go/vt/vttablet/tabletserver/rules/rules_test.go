@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -586,6 +587,38 @@ func TestAction(t *testing.T) {
 	assert.Equalf(t, desc, "rule 5", "want rule 5, got %s", desc)
 }
 
+func TestQueryAttributeAction(t *testing.T) {
+	// Query attributes sent to vtgate are forwarded as bind variables, so
+	// that rules can match on them.
+	qrs := New()
+	err := qrs.UnmarshalJSON([]byte(`[{
+		"Description": "no reports on this tablet",
+		"Name": "reports",
+		"BindVarConds": [{
+			"Name": "` + sqlparser.QueryAttributePrefix + `workload_name",
+			"OnAbsent": false,
+			"OnMismatch": false,
+			"Operator": "==",
+			"Value": "report"
+		}],
+		"Action": "FAIL"
+	}]`))
+	require.NoError(t, err)
+
+	bv := map[string]*querypb.BindVariable{}
+	action, _, _, _ := qrs.GetAction("123", "user", bv, sqlparser.MarginComments{})
+	assert.Equal(t, QRContinue, action)
+
+	bv[sqlparser.QueryAttributePrefix+"workload_name"] = sqltypes.BytesBindVariable([]byte("batch"))
+	action, _, _, _ = qrs.GetAction("123", "user", bv, sqlparser.MarginComments{})
+	assert.Equal(t, QRContinue, action)
+
+	bv[sqlparser.QueryAttributePrefix+"workload_name"] = sqltypes.BytesBindVariable([]byte("report"))
+	action, _, _, desc := qrs.GetAction("123", "user", bv, sqlparser.MarginComments{})
+	assert.Equal(t, QRFail, action)
+	assert.Equal(t, "no reports on this tablet", desc)
+}
+
 func TestImport(t *testing.T) {
 	var qrs = New()
 	jsondata := `[{
@@ -265,6 +265,55 @@ func TestTabletServerRedoLogIsKeptBetweenRestarts(t *testing.T) {
 	assert.Empty(t, tsv.te.preparedPool.conns, "tsv.te.preparedPool.conns")
 }
 
+func TestTabletServerBeginWaitsForRedoResurrection(t *testing.T) {
+	_, tsv, db := newTestTxExecutor(t)
+	defer tsv.StopService()
+	defer db.Close()
+	tsv.SetServingType(topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+
+	tpc := tsv.te.twoPC
+	db.AddQuery(tpc.readAllRedo, &sqltypes.Result{
+		Fields: []*querypb.Field{
+			{Type: sqltypes.VarBinary},
+			{Type: sqltypes.Uint64},
+			{Type: sqltypes.Uint64},
+			{Type: sqltypes.VarBinary},
+		},
+		Rows: [][]sqltypes.Value{{
+			sqltypes.NewVarBinary("dtid0"),
+			sqltypes.NewInt64(RedoStatePrepared),
+			sqltypes.NewVarBinary(""),
+			sqltypes.NewVarBinary("update test_table set `name` = 2 where pk = 1 limit 10001"),
+		}},
+	})
+	release := make(chan struct{})
+	db.SetBeforeFunc(tpc.readAllRedo, func() { <-release })
+
+	// the transition doesn't wait for the redo log to be read.
+	tsv.SetServingType(topodatapb.TabletType_PRIMARY, time.Time{}, true, "")
+
+	// but new transactions do.
+	began := make(chan error)
+	go func() {
+		txid, _, _, err := tsv.te.Begin(ctx, nil, 0, nil, &querypb.ExecuteOptions{})
+		if err == nil {
+			_, err = tsv.te.Rollback(ctx, txid)
+		}
+		began <- err
+	}()
+	select {
+	case <-began:
+		t.Fatal("Begin should wait for the prepared transactions to be resurrected")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-began)
+	tsv.TwoPCEngineWait()
+	assert.EqualValues(t, 1, len(tsv.te.preparedPool.conns), "len(tsv.te.preparedPool.conns)")
+	tsv.SetServingType(topodatapb.TabletType_REPLICA, time.Time{}, true, "")
+}
+
 func TestTabletServerCreateTransaction(t *testing.T) {
 	_, tsv, db := newTestTxExecutor(t)
 	defer tsv.StopService()
@@ -0,0 +1,51 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	// LagMetricName is the name of the default metric: the replication lag, or the custom query,
+	// with the threshold of the throttler config
+	LagMetricName = "lag"
+	// ThreadsRunningMetricName is a built-in metric, the number of running threads
+	ThreadsRunningMetricName = "threads_running"
+	// HistoryListLengthMetricName is a built-in metric, the InnoDB history list length
+	HistoryListLengthMetricName = "history_list_length"
+)
+
+// BuiltinMetricQueries are the queries of the built-in metrics, which only need a threshold
+var BuiltinMetricQueries = map[string]string{
+	ThreadsRunningMetricName:    "show global status like 'threads_running'",
+	HistoryListLengthMetricName: "select count as history_list_length from information_schema.innodb_metrics where name = 'trx_rseg_history_len'",
+}
+
+var metricNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateMetricName checks that a metric name can be used for a named metric
+func ValidateMetricName(metricName string) error {
+	if metricName == LagMetricName {
+		return fmt.Errorf("the %s metric is configured by the throttler threshold and custom query", LagMetricName)
+	}
+	if !metricNameRegexp.MatchString(metricName) {
+		return fmt.Errorf("invalid metric name %q: only letters, digits, '_' and '-' are allowed", metricName)
+	}
+	return nil
+}
@@ -31,6 +31,8 @@ type CheckFlags struct {
 	LowPriority           bool
 	OKIfNotExists         bool
 	SkipRequestHeartbeats bool
+	// MetricName, when set, limits the check to this metric, rather than to the metrics the app is checked against
+	MetricName string
 }
 
 // StandardCheckFlags have no special hints
@@ -95,7 +97,9 @@ func (check *ThrottlerCheck) checkAppMetricResult(ctx context.Context, appName s
 		// all good!
 		statusCode = http.StatusOK // 200
 	}
-	return NewCheckResult(statusCode, value, threshold, err)
+	checkResult = NewCheckResult(statusCode, value, threshold, err)
+	_, checkResult.MetricName = parseMetricStoreName(storeName)
+	return checkResult
 }
 
 // Check is the core function that runs when a user wants to check a metric
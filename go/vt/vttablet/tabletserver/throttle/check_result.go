@@ -15,6 +15,7 @@ import (
 // CheckResult is the result for an app inquiring on a metric. It also exports as JSON via the API
 type CheckResult struct {
 	StatusCode      int     `json:"StatusCode"`
+	MetricName      string  `json:"MetricName"`
 	Value           float64 `json:"Value"`
 	Threshold       float64 `json:"Threshold"`
 	Error           error   `json:"-"`
@@ -15,6 +15,7 @@ import (
 	"math"
 	"math/rand"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 	"sync"
@@ -145,6 +146,11 @@ type Throttler struct {
 	metricsQuery     atomic.Value
 	MetricsThreshold atomic.Uint64
 
+	// metrics holds the named metrics collected besides the lag metric, as map[string]*topodatapb.ThrottlerConfig_Metric
+	metrics atomic.Value
+	// appCheckedMetrics holds the metrics that apps are checked against, as map[string][]string
+	appCheckedMetrics atomic.Value
+
 	mysqlClusterThresholds *cache.Cache
 	aggregatedMetrics      *cache.Cache
 	throttledApps          *cache.Cache
@@ -176,6 +182,9 @@ type ThrottlerStatus struct {
 	Query     string
 	Threshold float64
 
+	MetricThresholds  map[string]float64
+	AppCheckedMetrics map[string][]string
+
 	AggregatedMetrics map[string]base.MetricResult
 	MetricsHealth     base.MetricHealthMap
 }
@@ -219,6 +228,8 @@ func NewThrottler(env tabletenv.Env, srvTopoServer srvtopo.Server, ts *topo.Serv
 	if throttleMetricThreshold != math.MaxFloat64 {
 		throttler.StoreMetricsThreshold(throttleMetricThreshold) // override
 	}
+	throttler.metrics.Store(map[string]*topodatapb.ThrottlerConfig_Metric{})
+	throttler.appCheckedMetrics.Store(map[string][]string{})
 
 	return throttler
 }
@@ -257,6 +268,48 @@ func (throttler *Throttler) GetMetricsThreshold() float64 {
 	return math.Float64frombits(throttler.MetricsThreshold.Load())
 }
 
+func (throttler *Throttler) getMetrics() map[string]*topodatapb.ThrottlerConfig_Metric {
+	return throttler.metrics.Load().(map[string]*topodatapb.ThrottlerConfig_Metric)
+}
+
+func (throttler *Throttler) getAppCheckedMetrics() map[string][]string {
+	return throttler.appCheckedMetrics.Load().(map[string][]string)
+}
+
+// metricStoreName returns the name of the store of the given scope (selfStoreName or shardStoreName)
+// in which the given metric is collected. The lag metric is collected in the scope's own store.
+func metricStoreName(scope string, metricName string) string {
+	if metricName == "" || metricName == base.LagMetricName {
+		return scope
+	}
+	return fmt.Sprintf("%s.%s", scope, metricName)
+}
+
+// parseMetricStoreName is the reverse of metricStoreName.
+func parseMetricStoreName(storeName string) (scope string, metricName string) {
+	scope, metricName, found := strings.Cut(storeName, ".")
+	if !found {
+		return storeName, base.LagMetricName
+	}
+	return scope, metricName
+}
+
+// appMetricNames returns the names of the metrics that the given app is checked against. An app name
+// may be composed of several colon-separated names, e.g. "vreplication:vcopier:<uuid>", in which case
+// the first name that has assigned metrics is used. Other apps are checked against the lag metric only.
+func (throttler *Throttler) appMetricNames(appName string) []string {
+	appCheckedMetrics := throttler.getAppCheckedMetrics()
+	if metricNames, ok := appCheckedMetrics[appName]; ok {
+		return metricNames
+	}
+	for _, singleAppName := range strings.Split(appName, ":") {
+		if metricNames, ok := appCheckedMetrics[singleAppName]; ok {
+			return metricNames
+		}
+	}
+	return []string{base.LagMetricName}
+}
+
 // initThrottler initializes config
 func (throttler *Throttler) initConfig() {
 	log.Infof("Throttler: initializing config")
@@ -343,6 +396,7 @@ func (throttler *Throttler) applyThrottlerConfig(ctx context.Context, throttlerC
 		throttler.metricsQuery.Store(throttlerConfig.CustomQuery)
 	}
 	throttler.StoreMetricsThreshold(throttlerConfig.Threshold)
+	throttler.applyThrottlerConfigMetrics(throttlerConfig)
 	throttlerCheckAsCheckSelf = throttlerConfig.CheckAsCheckSelf
 	if throttlerConfig.Enabled {
 		go throttler.Enable(ctx)
@@ -351,6 +405,38 @@ func (throttler *Throttler) applyThrottlerConfig(ctx context.Context, throttlerC
 	}
 }
 
+// applyThrottlerConfigMetrics applies the named metrics of a ThrottlerConfig, and the metrics that apps are
+// checked against. Built-in metrics get their default query, and custom metrics without a query are ignored.
+func (throttler *Throttler) applyThrottlerConfigMetrics(throttlerConfig *topodatapb.ThrottlerConfig) {
+	metrics := make(map[string]*topodatapb.ThrottlerConfig_Metric)
+	for metricName, metric := range throttlerConfig.Metrics {
+		if err := base.ValidateMetricName(metricName); err != nil {
+			log.Errorf("Throttler: ignoring metric: %v", err)
+			continue
+		}
+		metric = &topodatapb.ThrottlerConfig_Metric{
+			Threshold: metric.GetThreshold(),
+			Query:     metric.GetQuery(),
+		}
+		if metric.Query == "" {
+			metric.Query = base.BuiltinMetricQueries[metricName]
+		}
+		if metric.Query == "" {
+			log.Errorf("Throttler: ignoring metric %q which has no query", metricName)
+			continue
+		}
+		metrics[metricName] = metric
+	}
+	appCheckedMetrics := make(map[string][]string)
+	for appName, appMetrics := range throttlerConfig.AppCheckedMetrics {
+		if len(appMetrics.GetNames()) > 0 {
+			appCheckedMetrics[appName] = appMetrics.GetNames()
+		}
+	}
+	throttler.metrics.Store(metrics)
+	throttler.appCheckedMetrics.Store(appCheckedMetrics)
+}
+
 func (throttler *Throttler) IsEnabled() bool {
 	return atomic.LoadInt64(&throttler.isEnabled) > 0
 }
@@ -519,17 +605,17 @@ func (throttler *Throttler) Close() {
 	log.Infof("Throttler: finished execution of Close")
 }
 
-func (throttler *Throttler) generateSelfMySQLThrottleMetricFunc(ctx context.Context, probe *mysql.Probe) func() *mysql.MySQLThrottleMetric {
+func (throttler *Throttler) generateSelfMySQLThrottleMetricFunc(ctx context.Context, clusterName string, probe *mysql.Probe) func() *mysql.MySQLThrottleMetric {
 	f := func() *mysql.MySQLThrottleMetric {
-		return throttler.readSelfMySQLThrottleMetric(ctx, probe)
+		return throttler.readSelfMySQLThrottleMetric(ctx, clusterName, probe)
 	}
 	return f
 }
 
 // readSelfMySQLThrottleMetric reads the mysql metric from thi very tablet's backend mysql.
-func (throttler *Throttler) readSelfMySQLThrottleMetric(ctx context.Context, probe *mysql.Probe) *mysql.MySQLThrottleMetric {
+func (throttler *Throttler) readSelfMySQLThrottleMetric(ctx context.Context, clusterName string, probe *mysql.Probe) *mysql.MySQLThrottleMetric {
 	metric := &mysql.MySQLThrottleMetric{
-		ClusterName: selfStoreName,
+		ClusterName: clusterName,
 		Key:         *mysql.SelfInstanceKey,
 		Value:       0,
 		Err:         nil,
@@ -552,7 +638,7 @@ func (throttler *Throttler) readSelfMySQLThrottleMetric(ctx context.Context, pro
 		return metric
 	}
 
-	metricsQueryType := mysql.GetMetricsQueryType(throttler.GetMetricsQuery())
+	metricsQueryType := mysql.GetMetricsQueryType(probe.MetricQuery)
 	switch metricsQueryType {
 	case mysql.MetricsQueryTypeSelect:
 		// We expect a single row, single column result.
@@ -563,7 +649,7 @@ func (throttler *Throttler) readSelfMySQLThrottleMetric(ctx context.Context, pro
 	case mysql.MetricsQueryTypeShowGlobal:
 		metric.Value, metric.Err = strconv.ParseFloat(row["Value"].ToString(), 64)
 	default:
-		metric.Err = fmt.Errorf("Unsupported metrics query type for query: %s", throttler.GetMetricsQuery())
+		metric.Err = fmt.Errorf("Unsupported metrics query type for query: %s", probe.MetricQuery)
 	}
 
 	return metric
@@ -699,6 +785,8 @@ func (throttler *Throttler) Operate(ctx context.Context) {
 				}
 			case throttlerConfig := <-throttler.throttlerConfigChan:
 				throttler.applyThrottlerConfig(ctx, throttlerConfig)
+				// the config may add or remove metrics, which the inventory should reflect right away
+				go mysqlRefreshTicker.TickNow()
 			case <-recentCheckTicker.C:
 				// Increment recentCheckTickerValue by one.
 				atomic.AddInt64(&throttler.recentCheckTickerValue, 1)
@@ -715,6 +803,9 @@ func (throttler *Throttler) generateTabletHTTPProbeFunction(ctx context.Context,
 		mySQLThrottleMetric.Key = probe.Key
 
 		tabletCheckSelfURL := fmt.Sprintf("http://%s:%d/throttler/check-self?app=%s", probe.TabletHost, probe.TabletPort, throttlerapp.VitessName)
+		if _, metricName := parseMetricStoreName(clusterName); metricName != base.LagMetricName {
+			tabletCheckSelfURL = fmt.Sprintf("%s&metric=%s", tabletCheckSelfURL, url.QueryEscape(metricName))
+		}
 		resp, err := throttler.httpClient.Get(tabletCheckSelfURL)
 		if err != nil {
 			mySQLThrottleMetric.Err = err
@@ -764,8 +855,8 @@ func (throttler *Throttler) collectMySQLMetrics(ctx context.Context) error {
 					defer atomic.StoreInt64(&probe.QueryInProgress, 0)
 
 					var throttleMetricFunc func() *mysql.MySQLThrottleMetric
-					if clusterName == selfStoreName {
-						throttleMetricFunc = throttler.generateSelfMySQLThrottleMetricFunc(ctx, probe)
+					if scope, _ := parseMetricStoreName(clusterName); scope == selfStoreName {
+						throttleMetricFunc = throttler.generateSelfMySQLThrottleMetricFunc(ctx, clusterName, probe)
 					} else {
 						throttleMetricFunc = throttler.generateTabletHTTPProbeFunction(ctx, clusterName, probe)
 					}
@@ -778,12 +869,43 @@ func (throttler *Throttler) collectMySQLMetrics(ctx context.Context) error {
 	return nil
 }
 
-// refreshMySQLInventory will re-structure the inventory based on reading config settings
-func (throttler *Throttler) refreshMySQLInventory(ctx context.Context) error {
-
+// clustersSettings returns the settings of all the stores: the configured ones, which collect the lag metric,
+// and a self and a shard store for each of the named metrics.
+func (throttler *Throttler) clustersSettings() map[string]*config.MySQLClusterConfigurationSettings {
 	// distribute the query/threshold from the throttler down to the cluster settings and from there to the probes
 	metricsQuery := throttler.GetMetricsQuery()
 	metricsThreshold := throttler.MetricsThreshold.Load()
+	clustersSettings := make(map[string]*config.MySQLClusterConfigurationSettings)
+	for clusterName, clusterSettings := range config.Settings().Stores.MySQL.Clusters {
+		clusterSettings.MetricQuery = metricsQuery
+		clusterSettings.ThrottleThreshold.Store(metricsThreshold)
+		clustersSettings[clusterName] = clusterSettings
+	}
+	for metricName, metric := range throttler.getMetrics() {
+		for _, scope := range []string{selfStoreName, shardStoreName} {
+			threshold := &atomic.Uint64{}
+			threshold.Store(math.Float64bits(metric.Threshold))
+			clustersSettings[metricStoreName(scope, metricName)] = &config.MySQLClusterConfigurationSettings{
+				MetricQuery:       metric.Query,
+				ThrottleThreshold: threshold,
+			}
+		}
+	}
+	return clustersSettings
+}
+
+// isMetricStore returns whether the given store collects the lag metric or one of the named metrics.
+func (throttler *Throttler) isMetricStore(storeName string) bool {
+	_, metricName := parseMetricStoreName(storeName)
+	if metricName == base.LagMetricName {
+		return true
+	}
+	_, ok := throttler.getMetrics()[metricName]
+	return ok
+}
+
+// refreshMySQLInventory will re-structure the inventory based on reading config settings
+func (throttler *Throttler) refreshMySQLInventory(ctx context.Context) error {
 	addInstanceKey := func(tabletHost string, tabletPort int, key *mysql.InstanceKey, clusterName string, clusterSettings *config.MySQLClusterConfigurationSettings, probes *mysql.Probes) {
 		for _, ignore := range clusterSettings.IgnoreHosts {
 			if strings.Contains(key.StringCode(), ignore) {
@@ -806,11 +928,10 @@ func (throttler *Throttler) refreshMySQLInventory(ctx context.Context) error {
 		(*probes)[*key] = probe
 	}
 
-	for clusterName, clusterSettings := range config.Settings().Stores.MySQL.Clusters {
+	for clusterName, clusterSettings := range throttler.clustersSettings() {
 		clusterName := clusterName
 		clusterSettings := clusterSettings
-		clusterSettings.MetricQuery = metricsQuery
-		clusterSettings.ThrottleThreshold.Store(metricsThreshold)
+		scope, metricName := parseMetricStoreName(clusterName)
 		// config may dynamically change, but internal structure (config.Settings().Stores.MySQL.Clusters in our case)
 		// is immutable and can only be _replaced_. Hence, it's safe to read in a goroutine:
 		go func() {
@@ -821,7 +942,7 @@ func (throttler *Throttler) refreshMySQLInventory(ctx context.Context) error {
 				InstanceProbes:   mysql.NewProbes(),
 			}
 
-			if clusterName == selfStoreName {
+			if scope == selfStoreName {
 				// special case: just looking at this tablet's MySQL server
 				// We will probe this "cluster" (of one server) is a special way.
 				addInstanceKey("", 0, mysql.SelfInstanceKey, clusterName, clusterSettings, clusterProbes.InstanceProbes)
@@ -843,7 +964,8 @@ func (throttler *Throttler) refreshMySQLInventory(ctx context.Context) error {
 					if err != nil {
 						return err
 					}
-					if throttler.throttleTabletTypesMap[tablet.Type] {
+					// Besides the lag, the named metrics (e.g. threads_running) are also relevant on the primary itself.
+					if throttler.throttleTabletTypesMap[tablet.Type] || (metricName != base.LagMetricName && tablet.Type == topodatapb.TabletType_PRIMARY) {
 						key := mysql.InstanceKey{Hostname: tablet.MysqlHostname, Port: int(tablet.MysqlPort)}
 						addInstanceKey(tablet.Hostname, int(tablet.PortMap["vt"]), &key, clusterName, clusterSettings, clusterProbes.InstanceProbes)
 					}
@@ -870,6 +992,11 @@ func (throttler *Throttler) updateMySQLClusterProbes(ctx context.Context, cluste
 // synchronous aggregation of collected data
 func (throttler *Throttler) aggregateMySQLMetrics(ctx context.Context) error {
 	for clusterName, probes := range throttler.mysqlInventory.ClustersProbes {
+		if !throttler.isMetricStore(clusterName) {
+			// the metric was removed from the config
+			throttler.removeMySQLCluster(clusterName)
+			continue
+		}
 		metricName := fmt.Sprintf("mysql/%s", clusterName)
 		ignoreHostsCount := throttler.mysqlInventory.IgnoreHostsCount[clusterName]
 		ignoreHostsThreshold := throttler.mysqlInventory.IgnoreHostsThreshold[clusterName]
@@ -879,6 +1006,19 @@ func (throttler *Throttler) aggregateMySQLMetrics(ctx context.Context) error {
 	return nil
 }
 
+// removeMySQLCluster synchronously removes a cluster and its collected metrics from the inventory
+func (throttler *Throttler) removeMySQLCluster(clusterName string) {
+	delete(throttler.mysqlInventory.ClustersProbes, clusterName)
+	delete(throttler.mysqlInventory.IgnoreHostsCount, clusterName)
+	delete(throttler.mysqlInventory.IgnoreHostsThreshold, clusterName)
+	for key := range throttler.mysqlInventory.InstanceKeyMetrics {
+		if key.ClusterName == clusterName {
+			delete(throttler.mysqlInventory.InstanceKeyMetrics, key)
+		}
+	}
+	throttler.mysqlClusterThresholds.Delete(clusterName)
+}
+
 func (throttler *Throttler) getNamedMetric(metricName string) base.MetricResult {
 	if metricResultVal, found := throttler.aggregatedMetrics.Get(metricName); found {
 		return metricResultVal.(base.MetricResult)
@@ -1077,14 +1217,34 @@ func (throttler *Throttler) checkStore(ctx context.Context, appName string, stor
 	return checkResult
 }
 
+// checkScope checks the metrics of the given scope (selfStoreName or shardStoreName) that the app is checked against,
+// or only flags.MetricName when set. It returns the result of the first metric that fails the check, if any, or
+// otherwise the result of the first metric.
+func (throttler *Throttler) checkScope(ctx context.Context, appName string, scope string, remoteAddr string, flags *CheckFlags) (checkResult *CheckResult) {
+	metricNames := throttler.appMetricNames(appName)
+	if flags.MetricName != "" {
+		metricNames = []string{flags.MetricName}
+	}
+	for _, metricName := range metricNames {
+		metricCheckResult := throttler.checkStore(ctx, appName, metricStoreName(scope, metricName), remoteAddr, flags)
+		if checkResult == nil || metricCheckResult.StatusCode != http.StatusOK {
+			checkResult = metricCheckResult
+		}
+		if checkResult.StatusCode != http.StatusOK {
+			break
+		}
+	}
+	return checkResult
+}
+
 // checkShard checks the health of the shard, and runs on the primary tablet only
 func (throttler *Throttler) checkShard(ctx context.Context, appName string, remoteAddr string, flags *CheckFlags) (checkResult *CheckResult) {
-	return throttler.checkStore(ctx, appName, shardStoreName, remoteAddr, flags)
+	return throttler.checkScope(ctx, appName, shardStoreName, remoteAddr, flags)
 }
 
 // CheckSelf is checks the mysql/self metric, and is available on each tablet
 func (throttler *Throttler) checkSelf(ctx context.Context, appName string, remoteAddr string, flags *CheckFlags) (checkResult *CheckResult) {
-	return throttler.checkStore(ctx, appName, selfStoreName, remoteAddr, flags)
+	return throttler.checkScope(ctx, appName, selfStoreName, remoteAddr, flags)
 }
 
 // CheckByType runs a check by requested check type
@@ -1102,6 +1262,17 @@ func (throttler *Throttler) CheckByType(ctx context.Context, appName string, rem
 	}
 }
 
+// metricThresholdsSnapshot returns the thresholds of all metrics, including the lag metric
+func (throttler *Throttler) metricThresholdsSnapshot() map[string]float64 {
+	snapshot := map[string]float64{
+		base.LagMetricName: throttler.GetMetricsThreshold(),
+	}
+	for metricName, metric := range throttler.getMetrics() {
+		snapshot[metricName] = metric.Threshold
+	}
+	return snapshot
+}
+
 // Status exports a status breakdown
 func (throttler *Throttler) Status() *ThrottlerStatus {
 	return &ThrottlerStatus{
@@ -1116,6 +1287,9 @@ func (throttler *Throttler) Status() *ThrottlerStatus {
 		Query:     throttler.GetMetricsQuery(),
 		Threshold: throttler.GetMetricsThreshold(),
 
+		MetricThresholds:  throttler.metricThresholdsSnapshot(),
+		AppCheckedMetrics: throttler.getAppCheckedMetrics(),
+
 		AggregatedMetrics: throttler.aggregatedMetricsSnapshot(),
 		MetricsHealth:     throttler.metricsHealthSnapshot(),
 	}
@@ -0,0 +1,132 @@
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/patrickmn/go-cache"
+	"github.com/stretchr/testify/assert"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func newTestThrottler() *Throttler {
+	throttler := &Throttler{
+		isOpen:                             1,
+		isEnabled:                          1,
+		mysqlClusterThresholds:             cache.New(cache.NoExpiration, 0),
+		aggregatedMetrics:                  cache.New(aggregatedMetricsExpiration, 0),
+		throttledApps:                      cache.New(cache.NoExpiration, 0),
+		recentApps:                         cache.New(recentAppsExpiration, 0),
+		nonLowPriorityAppRequestsThrottled: cache.New(nonDeprioritizedAppMapExpiration, 0),
+	}
+	throttler.check = NewThrottlerCheck(throttler)
+	throttler.StoreMetricsThreshold(1)
+	throttler.applyThrottlerConfigMetrics(&topodatapb.ThrottlerConfig{
+		Metrics: map[string]*topodatapb.ThrottlerConfig_Metric{
+			base.HistoryListLengthMetricName: {Threshold: 1000},
+			base.ThreadsRunningMetricName:    {Threshold: 50},
+			"custom":                         {Threshold: 5, Query: "select 1"},
+			// invalid metrics are ignored
+			"no_query":         {Threshold: 5},
+			base.LagMetricName: {Threshold: 5},
+		},
+		AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_AppCheckedMetrics{
+			"online-ddl":   {Names: []string{base.LagMetricName, base.HistoryListLengthMetricName}},
+			"vreplication": {Names: []string{base.ThreadsRunningMetricName}},
+		},
+	})
+	return throttler
+}
+
+func TestMetricStoreName(t *testing.T) {
+	assert.Equal(t, "self", metricStoreName(selfStoreName, base.LagMetricName))
+	assert.Equal(t, "shard.threads_running", metricStoreName(shardStoreName, base.ThreadsRunningMetricName))
+
+	scope, metricName := parseMetricStoreName("shard")
+	assert.Equal(t, shardStoreName, scope)
+	assert.Equal(t, base.LagMetricName, metricName)
+	scope, metricName = parseMetricStoreName("self.threads_running")
+	assert.Equal(t, selfStoreName, scope)
+	assert.Equal(t, base.ThreadsRunningMetricName, metricName)
+}
+
+func TestApplyThrottlerConfigMetrics(t *testing.T) {
+	throttler := newTestThrottler()
+
+	metrics := throttler.getMetrics()
+	assert.Len(t, metrics, 3)
+	assert.Equal(t, base.BuiltinMetricQueries[base.HistoryListLengthMetricName], metrics[base.HistoryListLengthMetricName].Query)
+	assert.Equal(t, "select 1", metrics["custom"].Query)
+
+	assert.True(t, throttler.isMetricStore("shard"))
+	assert.True(t, throttler.isMetricStore("self.custom"))
+	assert.False(t, throttler.isMetricStore("self.no_query"))
+
+	assert.Equal(t, map[string]float64{
+		base.LagMetricName:               1,
+		base.HistoryListLengthMetricName: 1000,
+		base.ThreadsRunningMetricName:    50,
+		"custom":                         5,
+	}, throttler.metricThresholdsSnapshot())
+}
+
+func TestAppMetricNames(t *testing.T) {
+	throttler := newTestThrottler()
+
+	assert.Equal(t, []string{base.LagMetricName, base.HistoryListLengthMetricName}, throttler.appMetricNames("online-ddl"))
+	assert.Equal(t, []string{base.ThreadsRunningMetricName}, throttler.appMetricNames("vreplication:vcopier:some-uuid"))
+	assert.Equal(t, []string{base.LagMetricName}, throttler.appMetricNames("tablegc"))
+}
+
+func TestCheckScope(t *testing.T) {
+	ctx := context.Background()
+	throttler := newTestThrottler()
+	flags := &CheckFlags{SkipRequestHeartbeats: true}
+
+	setMetric := func(metricName string, value float64, threshold float64) {
+		storeName := metricStoreName(shardStoreName, metricName)
+		throttler.mysqlClusterThresholds.Set(storeName, threshold, cache.DefaultExpiration)
+		throttler.aggregatedMetrics.Set("mysql/"+storeName, base.NewSimpleMetricResult(value), cache.DefaultExpiration)
+	}
+	setMetric(base.LagMetricName, 0.5, 1)
+	setMetric(base.HistoryListLengthMetricName, 2000, 1000)
+
+	// tablegc is only checked against lag
+	checkResult := throttler.checkShard(ctx, "tablegc", "", flags)
+	assert.Equal(t, http.StatusOK, checkResult.StatusCode)
+	assert.Equal(t, base.LagMetricName, checkResult.MetricName)
+
+	// online-ddl is also checked against the history list length
+	checkResult = throttler.checkShard(ctx, "online-ddl", "", flags)
+	assert.Equal(t, http.StatusTooManyRequests, checkResult.StatusCode)
+	assert.Equal(t, base.HistoryListLengthMetricName, checkResult.MetricName)
+	assert.Equal(t, float64(2000), checkResult.Value)
+
+	// threads_running isn't collected yet
+	checkResult = throttler.checkShard(ctx, "vreplication", "", flags)
+	assert.Equal(t, http.StatusNotFound, checkResult.StatusCode)
+
+	// a check can be limited to a single metric
+	checkResult = throttler.checkShard(ctx, "online-ddl", "", &CheckFlags{SkipRequestHeartbeats: true, MetricName: base.LagMetricName})
+	assert.Equal(t, http.StatusOK, checkResult.StatusCode)
+}
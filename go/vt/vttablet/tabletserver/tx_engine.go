@@ -170,6 +170,10 @@ func (te *TxEngine) transition(state txEngineState) {
 		// than blocking everything for the sake of a few transactions.
 		// We do this async; so we do not end up blocking writes on
 		// failover for our setup tasks if using semi-sync replication.
+		// New transactions wait for the prepared transactions to be
+		// resurrected from the redo log, so that they can't grab the
+		// locks held by a prepared transaction of the previous primary.
+		te.preparedPool.Open()
 		te.twoPCReady.Add(1)
 		go func() {
 			defer te.twoPCReady.Done()
@@ -237,6 +241,7 @@ func (te *TxEngine) Begin(ctx context.Context, savepointQueries []string, reserv
 	}
 
 	defer te.beginRequests.Done()
+	te.twoPCReady.Wait()
 	conn, beginSQL, sessionStateChanges, err := te.txPool.Begin(ctx, options, te.state == AcceptingReadOnly, reservedID, savepointQueries, setting)
 	if err != nil {
 		return 0, "", "", err
@@ -305,6 +310,9 @@ func (te *TxEngine) shutdownLocked() {
 	te.stateLock.Unlock()
 	log.Infof("TxEngine - waiting for begin requests")
 	te.beginRequests.Wait()
+	// the prepared transactions can only be handed over once
+	// they have all been resurrected.
+	te.twoPCReady.Wait()
 	log.Infof("TxEngine - acquiring state lock again")
 	te.stateLock.Lock()
 	log.Infof("TxEngine - state lock acquired again")
@@ -314,6 +322,17 @@ func (te *TxEngine) shutdownLocked() {
 	log.Infof("TxEngine - stop watchdog")
 	te.stopWatchdog()
 
+	if !immediate {
+		// Prepared transactions are handed over to the next primary
+		// through the redo log, which it will use to resurrect them.
+		// Stop accepting new prepares, wait for the commits that are
+		// already in flight, and release the connections, so they don't
+		// hold up the wait for the tx pool to become empty.
+		log.Infof("TxEngine - handing over prepared transactions")
+		te.preparedPool.Shutdown()
+		te.rollbackPrepared()
+	}
+
 	poolEmpty := make(chan bool)
 	rollbackDone := make(chan bool)
 	// This goroutine decides if transactions have to be
@@ -337,12 +356,6 @@ func (te *TxEngine) shutdownLocked() {
 		te.txPool.scp.ShutdownNonTx()
 		if te.shutdownGracePeriod <= 0 {
 			// No grace period was specified. Wait indefinitely for transactions to be concluded.
-			// TODO(sougou): invoking rollbackPrepared is incorrect here. Prepared statements should
-			// actually be rolled back last. But this will cause the shutdown to hang because the
-			// tx pool will never become empty, because the prepared pool is holding on to connections
-			// from the tx pool. But we plan to deprecate this approach to 2PC. So, this
-			// should eventually be deleted.
-			te.rollbackPrepared()
 			log.Info("No grace period specified: performing normal wait.")
 			return
 		}
@@ -517,6 +530,7 @@ func (te *TxEngine) ReserveBegin(ctx context.Context, options *querypb.ExecuteOp
 		return 0, "", err
 	}
 	defer te.beginRequests.Done()
+	te.twoPCReady.Wait()
 
 	conn, err := te.reserve(ctx, options, preQueries)
 	if err != nil {
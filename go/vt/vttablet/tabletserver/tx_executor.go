@@ -57,7 +57,8 @@ func (txe *TxExecutor) Prepare(transactionID int64, dtid string) error {
 	}
 
 	// If no queries were executed, we just rollback.
-	if len(conn.TxProperties().Queries) == 0 {
+	queries := conn.TxProperties().Queries
+	if len(queries) == 0 {
 		conn.Release(tx.TxRollback)
 		return nil
 	}
@@ -68,8 +69,10 @@ func (txe *TxExecutor) Prepare(transactionID int64, dtid string) error {
 		return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "prepare failed for transaction %d: %v", transactionID, err)
 	}
 
+	// From here on, the connection may be handed over to the next
+	// primary by a concurrent shutdown, so it must not be used anymore.
 	return txe.inTransaction(func(localConn *StatefulConnection) error {
-		return txe.te.twoPC.SaveRedo(txe.ctx, localConn, dtid, conn.TxProperties().Queries)
+		return txe.te.twoPC.SaveRedo(txe.ctx, localConn, dtid, queries)
 	})
 
 }
@@ -138,6 +138,35 @@ func TestTxExecutorCommit(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestTxExecutorPrepareHandedOverOnDemotion(t *testing.T) {
+	txe, tsv, db := newTestTxExecutor(t)
+	defer db.Close()
+	defer tsv.StopService()
+	txid := newTxForPrep(tsv)
+	err := txe.Prepare(txid, "aa")
+	require.NoError(t, err)
+
+	// The prepared transaction must not hold up the demotion: it is
+	// released, and left in the redo log for the next primary.
+	tsv.te.shutdownGracePeriod = 10 * time.Second
+	start := time.Now()
+	tsv.te.AcceptReadOnly()
+	require.Less(t, time.Since(start), tsv.te.shutdownGracePeriod)
+	require.Empty(t, tsv.te.preparedPool.conns, "tsv.te.preparedPool.conns")
+
+	// A commit arriving after the hand over must fail, so that
+	// the coordinator retries it against the new primary.
+	err = txe.CommitPrepared("aa")
+	require.ErrorContains(t, err, "shutting down")
+
+	// So does a new prepare.
+	tsv.te.AcceptReadWrite()
+	txid = newTxForPrep(tsv)
+	tsv.te.preparedPool.Shutdown()
+	err = txe.Prepare(txid, "aa")
+	require.ErrorContains(t, err, "shutting down")
+}
+
 func TestTxExecutorCommitRedoFail(t *testing.T) {
 	txe, tsv, db := newTestTxExecutor(t)
 	defer db.Close()
@@ -25,6 +25,7 @@ import (
 var (
 	errPrepCommitting = errors.New("committing")
 	errPrepFailed     = errors.New("failed")
+	errPrepShutdown   = errors.New("shutting down")
 )
 
 // TxPreparedPool manages connections for prepared transactions.
@@ -35,6 +36,13 @@ type TxPreparedPool struct {
 	conns    map[string]*StatefulConnection
 	reserved map[string]error
 	capacity int
+
+	// shutdown is set while the pool is not accepting new
+	// prepared transactions, i.e. when the tablet is not a
+	// serving primary. resolved is signaled whenever a
+	// committing dtid gets resolved.
+	shutdown bool
+	resolved *sync.Cond
 }
 
 // NewTxPreparedPool creates a new TxPreparedPool.
@@ -43,11 +51,43 @@ func NewTxPreparedPool(capacity int) *TxPreparedPool {
 		// If capacity is 0 all prepares will fail.
 		capacity = 0
 	}
-	return &TxPreparedPool{
+	pp := &TxPreparedPool{
 		conns:    make(map[string]*StatefulConnection, capacity),
 		reserved: make(map[string]error),
 		capacity: capacity,
 	}
+	pp.resolved = sync.NewCond(&pp.mu)
+	return pp
+}
+
+// Open makes the pool accept prepared transactions again
+// after a Shutdown.
+func (pp *TxPreparedPool) Open() {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	pp.shutdown = false
+}
+
+// Shutdown stops the pool from accepting new prepared transactions
+// or commits, and waits for the commits that are already in flight
+// to conclude. The remaining prepared connections are left in the
+// pool, and can be fetched with FetchAll.
+func (pp *TxPreparedPool) Shutdown() {
+	pp.mu.Lock()
+	defer pp.mu.Unlock()
+	pp.shutdown = true
+	for pp.committingLocked() {
+		pp.resolved.Wait()
+	}
+}
+
+func (pp *TxPreparedPool) committingLocked() bool {
+	for _, err := range pp.reserved {
+		if err == errPrepCommitting {
+			return true
+		}
+	}
+	return false
 }
 
 // Put adds the connection to the pool. It returns an error
@@ -55,6 +95,9 @@ func NewTxPreparedPool(capacity int) *TxPreparedPool {
 func (pp *TxPreparedPool) Put(c *StatefulConnection, dtid string) error {
 	pp.mu.Lock()
 	defer pp.mu.Unlock()
+	if pp.shutdown {
+		return errPrepShutdown
+	}
 	if _, ok := pp.reserved[dtid]; ok {
 		return errors.New("duplicate DTID in Prepare: " + dtid)
 	}
@@ -91,10 +134,15 @@ func (pp *TxPreparedPool) FetchForRollback(dtid string) *StatefulConnection {
 // If the commit is successful, the dtid can be removed from the
 // reserved list by calling Forget. If the commit failed, SetFailed
 // must be called. This will inform future retries that the previous
-// commit failed.
+// commit failed. If the pool is shutting down, it returns an error,
+// because the prepared transaction is being handed over to the next
+// primary.
 func (pp *TxPreparedPool) FetchForCommit(dtid string) (*StatefulConnection, error) {
 	pp.mu.Lock()
 	defer pp.mu.Unlock()
+	if pp.shutdown {
+		return nil, errPrepShutdown
+	}
 	if err, ok := pp.reserved[dtid]; ok {
 		return nil, err
 	}
@@ -112,6 +160,7 @@ func (pp *TxPreparedPool) SetFailed(dtid string) {
 	pp.mu.Lock()
 	defer pp.mu.Unlock()
 	pp.reserved[dtid] = errPrepFailed
+	pp.resolved.Broadcast()
 }
 
 // Forget removes the dtid from the reserved list.
@@ -119,6 +168,7 @@ func (pp *TxPreparedPool) Forget(dtid string) {
 	pp.mu.Lock()
 	defer pp.mu.Unlock()
 	delete(pp.reserved, dtid)
+	pp.resolved.Broadcast()
 }
 
 // FetchAll removes all connections and returns them as a list.
@@ -132,5 +182,6 @@ func (pp *TxPreparedPool) FetchAll() []*StatefulConnection {
 	}
 	pp.conns = make(map[string]*StatefulConnection, pp.capacity)
 	pp.reserved = make(map[string]error)
+	pp.resolved.Broadcast()
 	return conns
 }
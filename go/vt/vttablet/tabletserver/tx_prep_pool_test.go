@@ -18,6 +18,7 @@ package tabletserver
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -130,3 +131,40 @@ func TestPrepFetchAll(t *testing.T) {
 		t.Errorf("len(pp.conns): %d, want 0", len(pp.conns))
 	}
 }
+
+func TestPrepShutdown(t *testing.T) {
+	pp := NewTxPreparedPool(2)
+	conn := &StatefulConnection{}
+	err := pp.Put(conn, "aa")
+	require.NoError(t, err)
+	_, err = pp.FetchForCommit("aa")
+	require.NoError(t, err)
+
+	// Shutdown waits for the commit in flight.
+	done := make(chan struct{})
+	go func() {
+		pp.Shutdown()
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("Shutdown returned while a commit was in flight")
+	case <-time.After(10 * time.Millisecond):
+	}
+	pp.Forget("aa")
+	<-done
+
+	want := "shutting down"
+	err = pp.Put(conn, "bb")
+	if err == nil || err.Error() != want {
+		t.Errorf("Put err: %v, want %s", err, want)
+	}
+	_, err = pp.FetchForCommit("bb")
+	if err == nil || err.Error() != want {
+		t.Errorf("FetchForCommit err: %v, want %s", err, want)
+	}
+
+	pp.Open()
+	err = pp.Put(conn, "bb")
+	require.NoError(t, err)
+}
@@ -79,6 +79,9 @@ const (
 	GreaterThanEqual
 	// NotEqual is used to filter a comparable column if != specific value
 	NotEqual
+	// Expression is used to filter a row on an arbitrary expression
+	// evaluated by the evalengine
+	Expression
 )
 
 // Filter contains opcodes for filtering.
@@ -95,6 +98,10 @@ type Filter struct {
 	Vindex        vindexes.Vindex
 	VindexColumns []int
 	KeyRange      *topodatapb.KeyRange
+
+	// Expr is the predicate for Expression. The row matches
+	// if it evaluates to true.
+	Expr evalengine.Expr
 }
 
 // ColExpr represents a column expression.
@@ -112,6 +119,10 @@ type ColExpr struct {
 	Field *querypb.Field
 
 	FixedValue sqltypes.Value
+
+	// Expr, if set, is evaluated against the row of the table
+	// to generate the value. If so, ColNum is ignored.
+	Expr evalengine.Expr
 }
 
 // Table contains the metadata for a table.
@@ -214,8 +225,24 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 	if len(result) != len(plan.ColExprs) {
 		return false, fmt.Errorf("expected %d values in result slice", len(plan.ColExprs))
 	}
+	var env *evalengine.ExpressionEnv
+	evaluate := func(expr evalengine.Expr) (evalengine.EvalResult, error) {
+		if env == nil {
+			env = evalengine.EmptyExpressionEnv()
+			env.Row = values
+		}
+		return env.Evaluate(expr)
+	}
 	for _, filter := range plan.Filters {
 		switch filter.Opcode {
+		case Expression:
+			res, err := evaluate(filter.Expr)
+			if err != nil {
+				return false, err
+			}
+			if !res.ToBoolean() {
+				return false, nil
+			}
 		case VindexMatch:
 			ksid, err := getKeyspaceID(values, filter.Vindex, filter.VindexColumns, plan.Table.Fields)
 			if err != nil {
@@ -235,6 +262,14 @@ func (plan *Plan) filter(values, result []sqltypes.Value, charsets []collations.
 		}
 	}
 	for i, colExpr := range plan.ColExprs {
+		if colExpr.Expr != nil {
+			res, err := evaluate(colExpr.Expr)
+			if err != nil {
+				return false, err
+			}
+			result[i] = res.Value()
+			continue
+		}
 		if colExpr.ColNum == -1 {
 			result[i] = colExpr.FixedValue
 			continue
@@ -506,55 +541,113 @@ func (plan *Plan) analyzeWhere(vschema *localVSchema, where *sqlparser.Where) er
 	for _, expr := range exprs {
 		switch expr := expr.(type) {
 		case *sqlparser.ComparisonExpr:
-			opcode, err := getOpcode(expr)
+			filter, ok, err := plan.analyzeComparison(expr)
 			if err != nil {
 				return err
 			}
-			qualifiedName, ok := expr.Left.(*sqlparser.ColName)
-			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
+			if ok {
+				plan.Filters = append(plan.Filters, filter)
+				continue
 			}
-			if !qualifiedName.Qualifier.IsEmpty() {
-				return fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(qualifiedName))
+		case *sqlparser.FuncExpr:
+			if expr.Name.EqualString("in_keyrange") {
+				if err := plan.analyzeInKeyRange(vschema, expr.Exprs); err != nil {
+					return err
+				}
+				continue
 			}
-			colnum, err := findColumn(plan.Table, qualifiedName.Name)
-			if err != nil {
-				return err
+		}
+		// Any other constraint is evaluated against the row.
+		evalExpr, err := plan.translateExpr(expr)
+		if err != nil {
+			return fmt.Errorf("unsupported constraint: %v: %s", sqlparser.String(expr), err.Error())
+		}
+		plan.Filters = append(plan.Filters, Filter{
+			Opcode: Expression,
+			Expr:   evalExpr,
+		})
+	}
+	return nil
+}
+
+// analyzeComparison builds the filter for a comparison between a column
+// and a literal. It returns false if the comparison is of another form,
+// in which case it must be evaluated as an expression.
+func (plan *Plan) analyzeComparison(expr *sqlparser.ComparisonExpr) (Filter, bool, error) {
+	opcode, err := getOpcode(expr)
+	if err != nil {
+		return Filter{}, false, nil
+	}
+	qualifiedName, ok := expr.Left.(*sqlparser.ColName)
+	if !ok {
+		return Filter{}, false, nil
+	}
+	if !qualifiedName.Qualifier.IsEmpty() {
+		return Filter{}, false, fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(qualifiedName))
+	}
+	colnum, err := findColumn(plan.Table, qualifiedName.Name)
+	if err != nil {
+		return Filter{}, false, err
+	}
+	val, ok := expr.Right.(*sqlparser.Literal)
+	if !ok {
+		return Filter{}, false, nil
+	}
+	//StrVal is varbinary, we do not support varchar since we would have to implement all collation types
+	if val.Type != sqlparser.IntVal && val.Type != sqlparser.StrVal {
+		return Filter{}, false, nil
+	}
+	pv, err := evalengine.Translate(val, nil)
+	if err != nil {
+		return Filter{}, false, err
+	}
+	env := evalengine.EmptyExpressionEnv()
+	resolved, err := env.Evaluate(pv)
+	if err != nil {
+		return Filter{}, false, err
+	}
+	return Filter{
+		Opcode: opcode,
+		ColNum: colnum,
+		Value:  resolved.Value(),
+	}, true, nil
+}
+
+// translateExpr compiles expr with the evalengine, so that it can be
+// evaluated against the rows of the table.
+func (plan *Plan) translateExpr(expr sqlparser.Expr) (evalengine.Expr, error) {
+	err := sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ColName:
+			if !node.Qualifier.IsEmpty() {
+				return false, fmt.Errorf("unsupported qualifier for column: %v", sqlparser.String(node))
 			}
-			val, ok := expr.Right.(*sqlparser.Literal)
+		case *sqlparser.Subquery:
+			return false, fmt.Errorf("unsupported subquery: %v", sqlparser.String(node))
+		}
+		return true, nil
+	}, expr)
+	if err != nil {
+		return nil, err
+	}
+	return evalengine.Translate(expr, &evalengine.Config{
+		ResolveColumn: func(col *sqlparser.ColName) (int, error) {
+			return findColumn(plan.Table, col.Name)
+		},
+		ResolveType: func(expr sqlparser.Expr) (sqltypes.Type, collations.ID, bool) {
+			col, ok := expr.(*sqlparser.ColName)
 			if !ok {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
+				return 0, 0, false
 			}
-			//StrVal is varbinary, we do not support varchar since we would have to implement all collation types
-			if val.Type != sqlparser.IntVal && val.Type != sqlparser.StrVal {
-				return fmt.Errorf("unexpected: %v", sqlparser.String(expr))
-			}
-			pv, err := evalengine.Translate(val, nil)
+			colnum, err := findColumn(plan.Table, col.Name)
 			if err != nil {
-				return err
-			}
-			env := evalengine.EmptyExpressionEnv()
-			resolved, err := env.Evaluate(pv)
-			if err != nil {
-				return err
-			}
-			plan.Filters = append(plan.Filters, Filter{
-				Opcode: opcode,
-				ColNum: colnum,
-				Value:  resolved.Value(),
-			})
-		case *sqlparser.FuncExpr:
-			if !expr.Name.EqualString("in_keyrange") {
-				return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
+				return 0, 0, false
 			}
-			if err := plan.analyzeInKeyRange(vschema, expr.Exprs); err != nil {
-				return err
-			}
-		default:
-			return fmt.Errorf("unsupported constraint: %v", sqlparser.String(expr))
-		}
-	}
-	return nil
+			field := plan.Table.Fields[colnum]
+			return field.Type, collations.ID(field.Charset), true
+		},
+		Collation: collations.Default(),
+	})
 }
 
 // splitAndExpression breaks up the Expr into AND-separated conditions
@@ -673,19 +766,17 @@ func (plan *Plan) analyzeExpr(vschema *localVSchema, selExpr sqlparser.SelectExp
 				Field:  field,
 			}, nil
 		default:
-			return ColExpr{}, fmt.Errorf("unsupported function: %v", sqlparser.String(inner))
+			return plan.analyzeEvalExpr(aliased)
 		}
 	case *sqlparser.Literal:
-		//allow only intval 1
+		// The integer literal 1 is sent as a fixed value, other
+		// literals are evaluated like any other expression.
 		if inner.Type != sqlparser.IntVal {
-			return ColExpr{}, fmt.Errorf("only integer literals are supported")
+			return plan.analyzeEvalExpr(aliased)
 		}
 		num, err := strconv.ParseInt(string(inner.Val), 0, 64)
-		if err != nil {
-			return ColExpr{}, err
-		}
-		if num != 1 {
-			return ColExpr{}, fmt.Errorf("only the integer literal 1 is supported")
+		if err != nil || num != 1 {
+			return plan.analyzeEvalExpr(aliased)
 		}
 		return ColExpr{
 			Field: &querypb.Field{
@@ -707,11 +798,33 @@ func (plan *Plan) analyzeExpr(vschema *localVSchema, selExpr sqlparser.SelectExp
 			Field:  field,
 		}, nil
 	default:
-		log.Infof("Unsupported expression: %v", inner)
-		return ColExpr{}, fmt.Errorf("unsupported: %v", sqlparser.String(aliased.Expr))
+		return plan.analyzeEvalExpr(aliased)
 	}
 }
 
+// analyzeEvalExpr handles the column expressions that are computed from
+// the row of the table by the evalengine.
+func (plan *Plan) analyzeEvalExpr(aliased *sqlparser.AliasedExpr) (ColExpr, error) {
+	expr, err := plan.translateExpr(aliased.Expr)
+	if err != nil {
+		log.Infof("Unsupported expression: %v", aliased.Expr)
+		return ColExpr{}, fmt.Errorf("unsupported: %v: %s", sqlparser.String(aliased.Expr), err.Error())
+	}
+	typ, err := evalengine.EmptyExpressionEnv().TypeOf(expr, plan.Table.Fields)
+	if err != nil {
+		// The type depends on the values of the row.
+		typ = sqltypes.VarBinary
+	}
+	return ColExpr{
+		ColNum: -1,
+		Field: &querypb.Field{
+			Name: aliased.ColumnName(),
+			Type: typ,
+		},
+		Expr: expr,
+	}, nil
+}
+
 // analyzeInKeyRange allows the following constructs: "in_keyrange('-80')",
 // "in_keyrange(col, 'hash', '-80')", "in_keyrange(col, 'local_vindex', '-80')", or
 // "in_keyrange(col, 'ks.external_vindex', '-80')".
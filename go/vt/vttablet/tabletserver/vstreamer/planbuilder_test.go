@@ -497,7 +497,7 @@ func TestPlanBuilder(t *testing.T) {
 	}, {
 		inTable: t1,
 		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, val from t1 where max(id)"},
-		outErr:  `unsupported constraint: max(id)`,
+		outErr:  `unsupported constraint: max(id): expr cannot be translated, not supported: max(id)`,
 	}, {
 		inTable: t1,
 		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, val from t1 where in_keyrange(id)"},
@@ -537,8 +537,12 @@ func TestPlanBuilder(t *testing.T) {
 		outErr:  `unsupported function: max(val)`,
 	}, {
 		inTable: t1,
-		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id+1, val from t1"},
-		outErr:  `unsupported: id + 1`,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, (select 1 from dual) as one from t1"},
+		outErr:  `unsupported: (select 1 from dual): unsupported subquery: (select 1 from dual)`,
+	}, {
+		inTable: t1,
+		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select id, concat(none, val) as c from t1"},
+		outErr:  "unsupported: concat(`none`, val): column `none` not found in table t1",
 	}, {
 		inTable: t1,
 		inRule:  &binlogdatapb.Rule{Match: "t1", Filter: "select t1.id, val from t1"},
@@ -663,6 +667,90 @@ func TestPlanBuilderFilterComparison(t *testing.T) {
 	}
 }
 
+func TestPlanBuilderExpressions(t *testing.T) {
+	t1 := &Table{
+		Name: "t1",
+		Fields: []*querypb.Field{{
+			Name: "id",
+			Type: sqltypes.Int64,
+		}, {
+			Name:    "status",
+			Type:    sqltypes.VarChar,
+			Charset: uint32(collations.CollationUtf8mb4ID),
+		}, {
+			Name: "deleted_at",
+			Type: sqltypes.Datetime,
+		}, {
+			Name: "doc",
+			Type: sqltypes.TypeJSON,
+		}},
+	}
+	rows := [][]sqltypes.Value{{
+		sqltypes.NewInt64(1), sqltypes.NewVarChar("a"), sqltypes.NULL, sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"x": 10}`)),
+	}, {
+		sqltypes.NewInt64(2), sqltypes.NewVarChar("c"), sqltypes.NULL, sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"x": 20}`)),
+	}, {
+		sqltypes.NewInt64(3), sqltypes.NewVarChar("b"), sqltypes.NewDatetime("2023-01-01 00:00:00"), sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"x": 30}`)),
+	}, {
+		sqltypes.NewInt64(4), sqltypes.NewVarChar("b"), sqltypes.NULL, sqltypes.MakeTrusted(sqltypes.TypeJSON, []byte(`{"y": 40}`)),
+	}}
+	charsets := make([]collations.ID, len(t1.Fields))
+
+	testcases := []struct {
+		name      string
+		inFilter  string
+		outFields []*querypb.Field
+		outRows   []string
+	}{{
+		name:      "in and is null",
+		inFilter:  "select id from t1 where status in ('a', 'b') and deleted_at is null",
+		outFields: []*querypb.Field{{Name: "id", Type: sqltypes.Int64}},
+		outRows:   []string{"[INT64(1)]", "[INT64(4)]"},
+	}, {
+		name:      "comparison between expressions",
+		inFilter:  "select id from t1 where id + 1 > 3 and not status = 'c'",
+		outFields: []*querypb.Field{{Name: "id", Type: sqltypes.Int64}},
+		outRows:   []string{"[INT64(3)]", "[INT64(4)]"},
+	}, {
+		name:     "projections",
+		inFilter: "select id, concat(status, '-', id) as tag, json_extract(doc, '$.x') as x, id * 2 from t1 where id = 1",
+		outFields: []*querypb.Field{
+			{Name: "id", Type: sqltypes.Int64},
+			{Name: "tag", Type: sqltypes.VarChar},
+			{Name: "x", Type: sqltypes.TypeJSON},
+			{Name: "id * 2", Type: sqltypes.Int64},
+		},
+		outRows: []string{`[INT64(1) VARCHAR("a-1") JSON("10") INT64(2)]`},
+	}, {
+		name:      "literals",
+		inFilter:  "select 1, 'abc' as s from t1 where id = 1",
+		outFields: []*querypb.Field{{Name: "1", Type: sqltypes.Int64}, {Name: "s", Type: sqltypes.VarChar}},
+		outRows:   []string{`[INT64(1) VARCHAR("abc")]`},
+	}}
+
+	for _, tcase := range testcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			plan, err := buildPlan(t1, testLocalVSchema, &binlogdatapb.Filter{
+				Rules: []*binlogdatapb.Rule{{Match: "t1", Filter: tcase.inFilter}},
+			})
+			require.NoError(t, err)
+			require.NotNil(t, plan)
+			utils.MustMatch(t, tcase.outFields, plan.fields())
+
+			var got []string
+			for _, row := range rows {
+				result := make([]sqltypes.Value, len(plan.ColExprs))
+				ok, err := plan.filter(row, result, charsets)
+				require.NoError(t, err)
+				if ok {
+					got = append(got, fmt.Sprintf("%v", result))
+				}
+			}
+			assert.Equal(t, tcase.outRows, got)
+		})
+	}
+}
+
 func TestCompare(t *testing.T) {
 	type testcase struct {
 		opcode                   Opcode
@@ -215,6 +215,10 @@ type TabletManagerClient interface {
 	// ResetReplicationParameters resets the replica replication parameters
 	ResetReplicationParameters(ctx context.Context, tablet *topodatapb.Tablet) error
 
+	// InjectEmptyTransactions commits an empty transaction on the primary
+	// tablet for every GTID of the given set
+	InjectEmptyTransactions(ctx context.Context, tablet *topodatapb.Tablet, gtidSet string) error
+
 	// SetReplicationSource tells a tablet to start replicating from the
 	// passed in tablet alias, and wait for the row in the
 	// reparent_journal table (if timeCreatedNS is non-zero).
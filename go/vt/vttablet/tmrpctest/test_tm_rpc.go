@@ -1128,6 +1128,28 @@ func tmRPCTestResetReplicationParametersPanic(ctx context.Context, t *testing.T,
 	expectHandleRPCPanic(t, "ResetReplicationParameters", true /*verbose*/, err)
 }
 
+var testInjectEmptyTransactionsGTIDSet = "00010203-0405-0607-0809-0a0b0c0d0e0f:5-7"
+var testInjectEmptyTransactionsCalled = false
+
+func (fra *fakeRPCTM) InjectEmptyTransactions(ctx context.Context, gtidSet string) error {
+	if fra.panics {
+		panic(fmt.Errorf("test-triggered panic"))
+	}
+	compare(fra.t, "InjectEmptyTransactions gtidSet", gtidSet, testInjectEmptyTransactionsGTIDSet)
+	testInjectEmptyTransactionsCalled = true
+	return nil
+}
+
+func tmRPCTestInjectEmptyTransactions(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.InjectEmptyTransactions(ctx, tablet, testInjectEmptyTransactionsGTIDSet)
+	compareError(t, "InjectEmptyTransactions", err, true, testInjectEmptyTransactionsCalled)
+}
+
+func tmRPCTestInjectEmptyTransactionsPanic(ctx context.Context, t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.Tablet) {
+	err := client.InjectEmptyTransactions(ctx, tablet, testInjectEmptyTransactionsGTIDSet)
+	expectHandleRPCPanic(t, "InjectEmptyTransactions", true /*verbose*/, err)
+}
+
 var testSetReplicationSourceCalled = false
 var testForceStartReplica = true
 
@@ -1368,6 +1390,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestReplicaWasPromoted(ctx, t, client, tablet)
 	tmRPCTestReplicaWasRestarted(ctx, t, client, tablet)
 	tmRPCTestResetReplicationParameters(ctx, t, client, tablet)
+	tmRPCTestInjectEmptyTransactions(ctx, t, client, tablet)
 
 	// Backup / restore related methods
 	tmRPCTestBackup(ctx, t, client, tablet)
@@ -1420,6 +1443,7 @@ func Run(t *testing.T, client tmclient.TabletManagerClient, tablet *topodatapb.T
 	tmRPCTestInitReplicaPanic(ctx, t, client, tablet)
 	tmRPCTestReplicaWasPromotedPanic(ctx, t, client, tablet)
 	tmRPCTestResetReplicationParametersPanic(ctx, t, client, tablet)
+	tmRPCTestInjectEmptyTransactionsPanic(ctx, t, client, tablet)
 	tmRPCTestReplicaWasRestartedPanic(ctx, t, client, tablet)
 	// Backup / restore related methods
 	tmRPCTestBackupPanic(ctx, t, client, tablet)
@@ -840,3 +840,43 @@ export const validateVersionShard = async (params: ValidateVersionShardParams) =
 
     return vtctldata.ValidateVersionShardResponse.create(result);
 };
+
+export interface FetchTransactionsParams {
+    clusterID: string;
+    keyspace: string;
+    // abandonAge, in seconds, restricts the results to the transactions older than it.
+    abandonAge?: number;
+}
+
+export const fetchTransactions = async (params: FetchTransactionsParams) => {
+    const req = new URLSearchParams();
+    if (params.abandonAge) {
+        req.append('abandon_age', params.abandonAge.toString());
+    }
+
+    const { result } = await vtfetch(`/api/transactions/${params.clusterID}/${params.keyspace}?${req.toString()}`);
+
+    const err = vtctldata.GetUnresolvedTransactionsResponse.verify(result);
+    if (err) throw Error(err);
+
+    return vtctldata.GetUnresolvedTransactionsResponse.create(result);
+};
+
+export interface ConcludeTransactionParams {
+    clusterID: string;
+    dtid: string;
+}
+
+export const concludeTransaction = async (params: ConcludeTransactionParams) => {
+    const { result } = await vtfetch(
+        `/api/transaction/${params.clusterID}/${encodeURIComponent(params.dtid)}/conclude`,
+        {
+            method: 'put',
+        }
+    );
+
+    const err = vtctldata.ConcludeTransactionResponse.verify(result);
+    if (err) throw Error(err);
+
+    return vtctldata.ConcludeTransactionResponse.create(result);
+};
@@ -35,6 +35,7 @@ import { Tablet } from './routes/tablet/Tablet';
 import { Backups } from './routes/Backups';
 import { Shard } from './routes/shard/Shard';
 import { Vtctlds } from './routes/Vtctlds';
+import { Transactions } from './routes/Transactions';
 import { SnackbarContainer } from './Snackbar';
 import { isReadOnlyMode } from '../util/env';
 import { CreateKeyspace } from './routes/createKeyspace/CreateKeyspace';
@@ -97,6 +98,10 @@ export const App = () => {
                             <Tablet />
                         </Route>
 
+                        <Route path="/transactions">
+                            <Transactions />
+                        </Route>
+
                         <Route path="/vtctlds">
                             <Vtctlds />
                         </Route>
@@ -74,6 +74,9 @@ export const NavRail = () => {
                     <li>
                         <NavRailLink icon={Icons.topology} text="Topology" to="/topology" />
                     </li>
+                    <li>
+                        <NavRailLink icon={Icons.search} text="Transactions" to="/transactions" />
+                    </li>
                 </ul>
             </div>
         </div>
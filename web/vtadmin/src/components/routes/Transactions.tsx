@@ -0,0 +1,118 @@
+/**
+ * Copyright 2023 The Vitess Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+import React from 'react';
+import { orderBy } from 'lodash-es';
+
+import { vtadmin as pb, query } from '../../proto/vtadmin';
+import { useKeyspaces, useTransactions } from '../../hooks/api';
+import { useDocumentTitle } from '../../hooks/useDocumentTitle';
+import { formatDateTime, formatRelativeTime } from '../../util/time';
+import { DataCell } from '../dataTable/DataCell';
+import { DataTable } from '../dataTable/DataTable';
+import { Select } from '../inputs/Select';
+import { ContentContainer } from '../layout/ContentContainer';
+import { WorkspaceHeader } from '../layout/WorkspaceHeader';
+import { WorkspaceTitle } from '../layout/WorkspaceTitle';
+import { QueryLoadingPlaceholder } from '../placeholders/QueryLoadingPlaceholder';
+import { ReadOnlyGate } from '../ReadOnlyGate';
+import TransactionActions from './transactions/TransactionActions';
+
+const COLUMNS = ['Transaction ID', 'State', 'Participants', 'Created'];
+
+export const Transactions = () => {
+    useDocumentTitle('Transactions');
+
+    const { data: keyspaces = [] } = useKeyspaces();
+
+    const [clusterID, updateCluster] = React.useState<string>('');
+    const [keyspaceName, updateKeyspace] = React.useState<string>('');
+
+    const selectedKeyspace =
+        clusterID && keyspaceName
+            ? keyspaces?.find((k) => k.cluster?.id === clusterID && k.keyspace?.name === keyspaceName)
+            : null;
+
+    const transactionsQuery = useTransactions(
+        { clusterID, keyspace: keyspaceName },
+        { enabled: !!clusterID && !!keyspaceName }
+    );
+    const transactions = transactionsQuery.data?.transactions || [];
+
+    const onChangeKeyspace = (selectedKeyspace: pb.Keyspace | null | undefined) => {
+        updateCluster(selectedKeyspace?.cluster?.id || '');
+        updateKeyspace(selectedKeyspace?.keyspace?.name || '');
+    };
+
+    const renderRows = (rows: query.ITransactionMetadata[]) =>
+        rows.map((row) => {
+            // time_created is in nanoseconds since the epoch.
+            const created = Math.floor(Number(row.time_created) / 1e9);
+
+            return (
+                <tr key={row.dtid}>
+                    <DataCell className="font-bold">{row.dtid}</DataCell>
+                    <DataCell>{query.TransactionState[row.state || 0]}</DataCell>
+                    <DataCell>
+                        {(row.participants || []).map((p) => (
+                            <div key={`${p.keyspace}/${p.shard}`}>
+                                {p.keyspace}/{p.shard}
+                            </div>
+                        ))}
+                    </DataCell>
+                    <DataCell>
+                        {formatDateTime(created)}
+                        <div className="text-sm text-secondary">{formatRelativeTime(created)}</div>
+                    </DataCell>
+                    <ReadOnlyGate>
+                        <DataCell>
+                            <TransactionActions
+                                clusterID={clusterID}
+                                dtid={row.dtid as string}
+                                onConcluded={() => transactionsQuery.refetch()}
+                            />
+                        </DataCell>
+                    </ReadOnlyGate>
+                </tr>
+            );
+        });
+
+    return (
+        <div>
+            <WorkspaceHeader>
+                <WorkspaceTitle>Transactions</WorkspaceTitle>
+            </WorkspaceHeader>
+            <ContentContainer>
+                <div className="max-w-screen-sm mb-8">
+                    <Select
+                        itemToString={(keyspace) => keyspace?.keyspace?.name || ''}
+                        items={orderBy(keyspaces, ['keyspace.name', 'cluster.id'])}
+                        label="Keyspace"
+                        onChange={onChangeKeyspace}
+                        placeholder="Choose a keyspace"
+                        renderItem={(keyspace) => `${keyspace?.keyspace?.name} (${keyspace?.cluster?.id})`}
+                        selectedItem={selectedKeyspace || null}
+                    />
+                </div>
+                {selectedKeyspace && (
+                    <>
+                        <DataTable columns={COLUMNS} data={transactions} renderRows={renderRows} />
+                        <QueryLoadingPlaceholder query={transactionsQuery} />
+                    </>
+                )}
+            </ContentContainer>
+        </div>
+    );
+};
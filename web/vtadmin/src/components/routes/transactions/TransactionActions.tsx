@@ -0,0 +1,40 @@
+import React, { useState } from 'react';
+import Dropdown from '../../dropdown/Dropdown';
+import MenuItem from '../../dropdown/MenuItem';
+import { Icons } from '../../Icon';
+import { useConcludeTransaction } from '../../../hooks/api';
+import KeyspaceAction from '../keyspaces/KeyspaceAction';
+
+interface TransactionActionsProps {
+    clusterID: string;
+    dtid: string;
+    onConcluded?: () => void;
+}
+
+const TransactionActions: React.FC<TransactionActionsProps> = ({ clusterID, dtid, onConcluded }) => {
+    const [currentDialog, setCurrentDialog] = useState<string>('');
+    const closeDialog = () => setCurrentDialog('');
+
+    const concludeTransactionMutation = useConcludeTransaction({ clusterID, dtid }, { onSuccess: onConcluded });
+
+    return (
+        <div className="w-min inline-block">
+            <Dropdown dropdownButton={Icons.info} position="bottom-right">
+                <MenuItem onClick={() => setCurrentDialog('Conclude Transaction')}>Conclude Transaction</MenuItem>
+            </Dropdown>
+            <KeyspaceAction
+                title="Conclude Transaction"
+                description={`Commits or rolls back the distributed transaction "${dtid}" on all of its participants, depending on its state.`}
+                confirmText="Conclude"
+                loadingText="Concluding"
+                mutation={concludeTransactionMutation}
+                successText="Concluded transaction"
+                errorText="Error concluding transaction"
+                closeDialog={closeDialog}
+                isOpen={currentDialog === 'Conclude Transaction'}
+            />
+        </div>
+    );
+};
+
+export default TransactionActions;
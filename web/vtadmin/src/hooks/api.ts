@@ -78,6 +78,10 @@ import {
     GetFullStatusParams,
     validateVersionShard,
     ValidateVersionShardParams,
+    fetchTransactions,
+    FetchTransactionsParams,
+    concludeTransaction,
+    ConcludeTransactionParams,
 } from '../api/http';
 import { vtadmin as pb, vtctldata } from '../proto/vtadmin';
 import { formatAlias } from '../util/tablets';
@@ -619,3 +623,25 @@ export const useValidateVersionShard = (
         return validateVersionShard(params);
     }, options);
 };
+
+/**
+ * useTransactions is a query hook that fetches the unresolved distributed
+ * transactions of a keyspace.
+ */
+export const useTransactions = (
+    params: FetchTransactionsParams,
+    options?: UseQueryOptions<vtctldata.GetUnresolvedTransactionsResponse, Error> | undefined
+) => useQuery(['transactions', params], () => fetchTransactions(params), options);
+
+/**
+ * useConcludeTransaction is a mutate hook that resolves an unresolved
+ * distributed transaction.
+ */
+export const useConcludeTransaction = (
+    params: Parameters<typeof concludeTransaction>[0],
+    options?: UseMutationOptions<Awaited<ReturnType<typeof concludeTransaction>>, Error, ConcludeTransactionParams>
+) => {
+    return useMutation<Awaited<ReturnType<typeof concludeTransaction>>, Error, ConcludeTransactionParams>(() => {
+        return concludeTransaction(params);
+    }, options);
+};